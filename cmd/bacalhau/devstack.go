@@ -167,7 +167,10 @@ func runDevstack(cmd *cobra.Command, ODs *devstack.DevStackOptions, OS *ServeOpt
 		}
 	}
 
-	computeConfig := getComputeConfig(OS)
+	computeConfig, err := getComputeConfig(OS)
+	if err != nil {
+		return err
+	}
 	if ODs.LocalNetworkLotus {
 		cmd.Println("Note that starting up the Lotus node can take many minutes!")
 	}
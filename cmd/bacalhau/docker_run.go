@@ -45,24 +45,31 @@ var (
 
 // DockerRunOptions declares the arguments accepted by the `docker run` command
 type DockerRunOptions struct {
-	Engine           string   // Executor - executor.Executor
-	Verifier         string   // Verifier - verifier.Verifier
-	Publisher        string   // Publisher - publisher.Publisher
-	Inputs           []string // Array of input CIDs
-	InputUrls        []string // Array of input URLs (will be copied to IPFS)
-	InputVolumes     []string // Array of input volumes in 'CID:mount point' form
-	OutputVolumes    []string // Array of output volumes in 'name:mount point' form
-	Env              []string // Array of environment variables
-	IDOnly           bool     // Only print the job ID
-	Concurrency      int      // Number of concurrent jobs to run
-	Confidence       int      // Minimum number of nodes that must agree on a verification result
-	MinBids          int      // Minimum number of bids before they will be accepted (at random)
-	Timeout          float64  // Job execution timeout in seconds
+	Engine           string              // Executor - executor.Executor
+	Verifier         string              // Verifier - verifier.Verifier
+	Publisher        string              // Publisher - publisher.Publisher
+	Inputs           []string            // Array of input CIDs
+	InputUrls        []string            // Array of input URLs (will be copied to IPFS)
+	InputVolumes     []string            // Array of input volumes in 'CID:mount point' form
+	InputS3          []model.StorageSpec // Array of input objects of S3-compatible buckets
+	InputGit         []model.StorageSpec // Array of input commits of git repositories
+	OutputVolumes    []string            // Array of output volumes in 'name:mount point' form
+	Env              []string            // Array of environment variables
+	IDOnly           bool                // Only print the job ID
+	Concurrency      int                 // Number of concurrent jobs to run
+	Confidence       int                 // Minimum number of nodes that must agree on a verification result
+	MinBids          int                 // Minimum number of bids before they will be accepted (at random)
+	SpotCheck        float64             // Fraction of shards to run on an extra node to check the results
+	Timeout          float64             // Job execution timeout in seconds
+	Priority         int                 // Job priority relative to other jobs on the same compute node
 	CPU              string
 	Memory           string
 	GPU              string
 	Networking       model.Network
 	NetworkDomains   []string
+	ResultPackaging  model.ResultPackaging
+	OutputLimits     model.OutputLimitsConfig
+	Verification     model.VerificationRules
 	WorkingDirectory string   // Working directory for docker
 	Labels           []string // Labels for the job on the Bacalhau network (for searching)
 	NodeSelector     string   // Selector (label query) to filter nodes on which this job can be executed
@@ -93,6 +100,8 @@ func NewDockerRunOptions() *DockerRunOptions {
 		Inputs:             []string{},
 		InputUrls:          []string{},
 		InputVolumes:       []string{},
+		InputS3:            []model.StorageSpec{},
+		InputGit:           []model.StorageSpec{},
 		OutputVolumes:      []string{},
 		Env:                []string{},
 		Concurrency:        1,
@@ -158,6 +167,10 @@ func newDockerRunCmd() *cobra.Command { //nolint:funlen
 		&ODR.Publisher, "publisher", ODR.Publisher,
 		`What publisher engine to use to publish the job results`,
 	)
+	dockerRunCmd.PersistentFlags().Var(
+		ResultPackagingFlag(&ODR.ResultPackaging), "result-packaging",
+		`How to package the results before they are published (none, tar.gz, tar.zst or zip)`,
+	)
 	dockerRunCmd.PersistentFlags().StringSliceVarP(
 		&ODR.Inputs, "inputs", "i", ODR.Inputs,
 		`CIDs to use on the job. Mounts them at '/inputs' in the execution.`,
@@ -170,6 +183,12 @@ func newDockerRunCmd() *cobra.Command { //nolint:funlen
 		mounts 'bar.tar.gz' at '/inputs/bar.tar.gz'). URL accept any valid URL supported by the 'wget' command,
 		and supports both HTTP and HTTPS.`,
 	)
+	dockerRunCmd.PersistentFlags().Var(
+		NewS3StorageSpecArrayFlag(&ODR.InputS3), "input-s3", s3InputUsage,
+	)
+	dockerRunCmd.PersistentFlags().Var(
+		NewGitStorageSpecArrayFlag(&ODR.InputGit), "input-git", gitInputUsage,
+	)
 	dockerRunCmd.PersistentFlags().StringSliceVarP(
 		&ODR.InputVolumes, "input-volumes", "v", ODR.InputVolumes,
 		`CID:path of the input data volumes, if you need to set the path of the mounted data.`,
@@ -194,10 +213,26 @@ func newDockerRunCmd() *cobra.Command { //nolint:funlen
 		&ODR.MinBids, "min-bids", ODR.MinBids,
 		`Minimum number of bids that must be received before concurrency-many bids will be accepted (at random)`,
 	)
+	dockerRunCmd.PersistentFlags().Float64Var(
+		&ODR.SpotCheck, "spot-check-fraction", ODR.SpotCheck,
+		`The fraction of shards to run on an extra node to check the results (used with the spotcheck verifier)`,
+	)
 	dockerRunCmd.PersistentFlags().Float64Var(
 		&ODR.Timeout, "timeout", ODR.Timeout,
 		`Job execution timeout in seconds (e.g. 300 for 5 minutes and 0.1 for 100ms)`,
 	)
+	dockerRunCmd.PersistentFlags().IntVar(
+		&ODR.Priority, "priority", ODR.Priority,
+		`Job priority. Compute nodes ordering their queue by priority run jobs with higher priority first`,
+	)
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.OutputLimits.Volume, "output-limit-volume", ODR.OutputLimits.Volume,
+		`Size limit of each output volume (e.g. 500Mb, 2Gb). The job fails if an output volume grows over it.`,
+	)
+	dockerRunCmd.PersistentFlags().StringVar(
+		&ODR.OutputLimits.Total, "output-limit-total", ODR.OutputLimits.Total,
+		`Size limit of all the output volumes together (e.g. 500Mb, 2Gb). The job fails if the outputs grow over it.`,
+	)
 	dockerRunCmd.PersistentFlags().StringVar(
 		&ODR.CPU, "cpu", ODR.CPU,
 		`Job CPU cores (e.g. 500m, 2, 8).`,
@@ -265,6 +300,7 @@ func newDockerRunCmd() *cobra.Command { //nolint:funlen
 
 	dockerRunCmd.PersistentFlags().AddFlagSet(NewRunTimeSettingsFlags(&ODR.RunTimeSettings))
 	dockerRunCmd.PersistentFlags().AddFlagSet(NewIPFSDownloadFlags(&ODR.DownloadFlags))
+	dockerRunCmd.PersistentFlags().AddFlagSet(NewVerificationRulesFlags(&ODR.Verification))
 
 	return dockerRunCmd
 }
@@ -331,9 +367,18 @@ func CreateJob(cmdArgs []string, odr *DockerRunOptions) (*model.Job, error) {
 	}
 
 	odr.DownloadFlags = model.DownloaderSettings{
-		Timeout:        odr.DownloadFlags.Timeout,
-		OutputDir:      odr.DownloadFlags.OutputDir,
-		IPFSSwarmAddrs: swarmAddresses,
+		Timeout:            odr.DownloadFlags.Timeout,
+		OutputDir:          odr.DownloadFlags.OutputDir,
+		IPFSSwarmAddrs:     swarmAddresses,
+		S3Endpoint:         odr.DownloadFlags.S3Endpoint,
+		S3Region:           odr.DownloadFlags.S3Region,
+		HTTPUsername:       odr.DownloadFlags.HTTPUsername,
+		HTTPPassword:       odr.DownloadFlags.HTTPPassword,
+		LocalPublisherRoot: odr.DownloadFlags.LocalPublisherRoot,
+		Parallelism:        odr.DownloadFlags.Parallelism,
+		ShardIndexes:       odr.DownloadFlags.ShardIndexes,
+		OutputVolumeNames:  odr.DownloadFlags.OutputVolumeNames,
+		Merge:              odr.DownloadFlags.Merge,
 	}
 
 	engineType, err := model.ParseEngine(odr.Engine)
@@ -400,6 +445,13 @@ func CreateJob(cmdArgs []string, odr *DockerRunOptions) (*model.Job, error) {
 	if err != nil {
 		return &model.Job{}, errors.Wrap(err, "CreateJobSpecAndDeal")
 	}
+	j.Spec.Priority = odr.Priority
+	j.Spec.ResultPackaging = odr.ResultPackaging
+	j.Spec.OutputLimits = odr.OutputLimits
+	j.Spec.VerificationRules = odr.Verification
+	j.Spec.Deal.SpotCheckFraction = odr.SpotCheck
+	j.Spec.Inputs = append(j.Spec.Inputs, odr.InputS3...)
+	j.Spec.Inputs = append(j.Spec.Inputs, odr.InputGit...)
 
 	return j, nil
 }
@@ -2,7 +2,9 @@ package bacalhau
 
 import (
 	"fmt"
+	"path"
 	"strings"
+	"time"
 
 	"github.com/filecoin-project/bacalhau/pkg/job"
 	"github.com/filecoin-project/bacalhau/pkg/logger"
@@ -201,6 +203,121 @@ func NewURLStorageSpecArrayFlag(value *[]model.StorageSpec) *ArrayValueFlag[mode
 	}
 }
 
+const s3InputUsage = `Objects of an S3-compatible bucket to use on the job, in s3://bucket/key[:path] form. A key ending ` +
+	`with '/' or '*' selects all the objects under it (e.g. '--input-s3 s3://bucket/data/*' mounts 'data/a.csv' at ` +
+	`'/inputs/a.csv'). The compute nodes provide the credentials of the buckets.`
+
+// parseS3StorageSpec parses s3://bucket/key[:path], where a key ending with "/" or "*" is a prefix that
+// selects all the objects under it. Objects are mounted under '/inputs' unless a path is given.
+func parseS3StorageSpec(input string) (model.StorageSpec, error) {
+	if !strings.HasPrefix(input, "s3://") {
+		return model.StorageSpec{}, fmt.Errorf("%q should start with s3://", input)
+	}
+	location := strings.TrimPrefix(input, "s3://")
+	mountPath := ""
+	if i := strings.LastIndex(location, ":/"); i >= 0 {
+		location, mountPath = location[:i], location[i+1:]
+	}
+	bucket, key, _ := strings.Cut(location, "/")
+	if bucket == "" {
+		return model.StorageSpec{}, fmt.Errorf("%q should contain a bucket", input)
+	}
+
+	spec := &model.S3StorageSpec{Bucket: bucket}
+	if key == "" || strings.HasSuffix(key, "/") || strings.HasSuffix(key, "*") {
+		spec.Prefix = strings.TrimSuffix(key, "*")
+		if mountPath == "" {
+			mountPath = "/inputs"
+		}
+	} else {
+		spec.Key = key
+		if mountPath == "" {
+			mountPath = path.Join("/inputs", path.Base(key))
+		}
+	}
+	return model.StorageSpec{
+		StorageSource: model.StorageSourceS3,
+		Path:          mountPath,
+		S3:            spec,
+	}, nil
+}
+
+func storageSpecToS3URL(input *model.StorageSpec) string {
+	if input.S3 == nil {
+		return ""
+	}
+	key := input.S3.Key
+	if key == "" {
+		key = input.S3.Prefix + "*"
+	}
+	return fmt.Sprintf("s3://%s/%s:%s", input.S3.Bucket, key, input.Path)
+}
+
+func NewS3StorageSpecArrayFlag(value *[]model.StorageSpec) *ArrayValueFlag[model.StorageSpec] {
+	return &ArrayValueFlag[model.StorageSpec]{
+		value:    value,
+		parser:   parseS3StorageSpec,
+		stringer: storageSpecToS3URL,
+		typeStr:  "s3://bucket/key",
+	}
+}
+
+const gitInputUsage = `Commits of git repositories to use on the job, in REPOSITORY[#REF[:SUBDIRECTORY]] form, where REF is ` +
+	`a branch, tag or commit SHA and defaults to the default branch. The files are mounted at '/inputs/NAME', where ` +
+	`NAME is the last element of the subdirectory or the name of the repository (e.g. '--input-git ` +
+	`https://github.com/org/repo.git#v1.0:scripts' mounts the 'scripts' directory of the 'v1.0' tag at '/inputs/scripts').`
+
+// parseGitStorageSpec parses REPOSITORY[#REF[:SUBDIRECTORY]], which follows the syntax of git build contexts
+// of Docker. The files are mounted under '/inputs' at the name of the subdirectory or repository.
+func parseGitStorageSpec(input string) (model.StorageSpec, error) {
+	repository, fragment, _ := strings.Cut(input, "#")
+	if repository == "" {
+		return model.StorageSpec{}, fmt.Errorf("%q should contain a repository", input)
+	}
+	ref, subdirectory, _ := strings.Cut(fragment, ":")
+
+	name := path.Base(strings.TrimSuffix(strings.TrimSuffix(repository, "/"), ".git"))
+	if subdirectory != "" {
+		name = path.Base(subdirectory)
+	}
+	mountPath := "/inputs"
+	if name != "." && name != "/" {
+		mountPath = path.Join(mountPath, name)
+	}
+	return model.StorageSpec{
+		StorageSource: model.StorageSourceGit,
+		Path:          mountPath,
+		Git: &model.GitStorageSpec{
+			Repository:   repository,
+			Ref:          ref,
+			Subdirectory: subdirectory,
+		},
+	}, nil
+}
+
+func storageSpecToGitURL(input *model.StorageSpec) string {
+	if input.Git == nil {
+		return ""
+	}
+	url := input.Git.Repository
+	if input.Git.Ref != "" || input.Git.Subdirectory != "" {
+		url += "#" + input.Git.Ref
+	}
+	if input.Git.Subdirectory != "" {
+		url += ":" + input.Git.Subdirectory
+	}
+	return url
+}
+
+func NewGitStorageSpecArrayFlag(value *[]model.StorageSpec) *ArrayValueFlag[model.StorageSpec] {
+	return &ArrayValueFlag[model.StorageSpec]{
+		value:    value,
+		parser:   parseGitStorageSpec,
+		stringer: storageSpecToGitURL,
+		typeStr:  "repository#ref",
+	}
+}
+
 func VerifierFlag(value *model.Verifier) *ValueFlag[model.Verifier] {
 	return &ValueFlag[model.Verifier]{
 		value:    value,
@@ -228,6 +345,24 @@ func NetworkFlag(value *model.Network) *ValueFlag[model.Network] {
 	}
 }
 
+func ResultPackagingFlag(value *model.ResultPackaging) *ValueFlag[model.ResultPackaging] {
+	return &ValueFlag[model.ResultPackaging]{
+		value:    value,
+		parser:   model.ParseResultPackaging,
+		stringer: func(p *model.ResultPackaging) string { return p.String() },
+		typeStr:  "result-packaging",
+	}
+}
+
+func ScheduleOverlapPolicyFlag(value *model.ScheduleOverlapPolicy) *ValueFlag[model.ScheduleOverlapPolicy] {
+	return &ValueFlag[model.ScheduleOverlapPolicy]{
+		value:    value,
+		parser:   model.ParseScheduleOverlapPolicy,
+		stringer: func(p *model.ScheduleOverlapPolicy) string { return p.String() },
+		typeStr:  "overlap-policy",
+	}
+}
+
 func LoggingFlag(value *logger.LogMode) *ValueFlag[logger.LogMode] {
 	return &ValueFlag[logger.LogMode]{
 		value:    value,
@@ -277,3 +412,56 @@ func ExcludedTagFlag(value *[]model.ExcludedTag) *ArrayValueFlag[model.ExcludedT
 		typeStr:  "tag",
 	}
 }
+
+func JobStateTypeArrayFlag(value *[]model.JobStateType) *ArrayValueFlag[model.JobStateType] {
+	return &ArrayValueFlag[model.JobStateType]{
+		value:    value,
+		parser:   model.ParseJobStateType,
+		stringer: func(s *model.JobStateType) string { return s.String() },
+		typeStr:  "state",
+	}
+}
+
+func EngineArrayFlag(value *[]model.Engine) *ArrayValueFlag[model.Engine] {
+	return &ArrayValueFlag[model.Engine]{
+		value:    value,
+		parser:   model.ParseEngine,
+		stringer: func(e *model.Engine) string { return e.String() },
+		typeStr:  "engine",
+	}
+}
+
+func PublisherArrayFlag(value *[]model.Publisher) *ArrayValueFlag[model.Publisher] {
+	return &ArrayValueFlag[model.Publisher]{
+		value:    value,
+		parser:   model.ParsePublisher,
+		stringer: func(p *model.Publisher) string { return p.String() },
+		typeStr:  "publisher",
+	}
+}
+
+// parseTimeOrDuration parses either an RFC3339 timestamp, or a duration that is subtracted from the current time
+func parseTimeOrDuration(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC3339 time nor a duration", s)
+	}
+	return time.Now().Add(-d), nil
+}
+
+func TimeFlag(value *time.Time) *ValueFlag[time.Time] {
+	return &ValueFlag[time.Time]{
+		value:  value,
+		parser: parseTimeOrDuration,
+		stringer: func(t *time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.Format(time.RFC3339)
+		},
+		typeStr: "time",
+	}
+}
@@ -15,6 +15,9 @@ import (
 var (
 	getLong = templates.LongDesc(i18n.T(`
 		Get the results of the job, including stdout and stderr.
+
+		Results that were already downloaded to the output directory are skipped, so running the command
+		again after an interruption only downloads the missing results.
 `))
 
 	//nolint:lll // Documentation
@@ -24,6 +27,12 @@ var (
 
 		# Get the results of a job, with a short ID.
 		bacalhau get ebd9bf2f
+
+		# Get the outputs volume of the first two shards of a job, downloading 8 shards at a time.
+		bacalhau get ebd9bf2f --download-shards 0,1 --download-volumes outputs --download-parallelism 8
+
+		# Get the results of a sharded job, merged into a single tree.
+		bacalhau get ebd9bf2f --merge
 `))
 )
 
@@ -50,10 +50,14 @@ func testResultsFolderStructure(t *testing.T, baseFolder, hostID string) {
 
 	shortID := system.GetShortID(hostID)
 
-	// if you change the docker run command in any way we need to change this
-	resultsCID := "QmR92HM96X3seZEaRWXfRJDDFHKcprqbmQsEQ9uhbrA7MQ"
+	// the CID of the results changes with every run, as they include a manifest signed by the node
+	cidDirs, err := os.ReadDir(filepath.Join(baseFolder, model.DownloadCIDsFolderName))
+	require.NoError(t, err)
+	require.Len(t, cidDirs, 1)
+	resultsCID := cidDirs[0].Name()
 
 	expected := []string{
+		"/" + model.DownloadFilenameState,
 		"/" + model.DownloadVolumesFolderName,
 		"/" + model.DownloadVolumesFolderName + "/data",
 		"/" + model.DownloadVolumesFolderName + "/data/apples",
@@ -69,6 +73,7 @@ func testResultsFolderStructure(t *testing.T, baseFolder, hostID string) {
 		"/" + model.DownloadShardsFolderName + "/0_node_" + shortID + "/data/apples/file.txt",
 		"/" + model.DownloadShardsFolderName + "/0_node_" + shortID + "/data/file.txt",
 		"/" + model.DownloadShardsFolderName + "/0_node_" + shortID + "/exitCode",
+		"/" + model.DownloadShardsFolderName + "/0_node_" + shortID + "/" + model.DownloadFilenameManifest,
 		"/" + model.DownloadShardsFolderName + "/0_node_" + shortID + "/outputs",
 		"/" + model.DownloadShardsFolderName + "/0_node_" + shortID + "/stderr",
 		"/" + model.DownloadShardsFolderName + "/0_node_" + shortID + "/stdout",
@@ -79,6 +84,7 @@ func testResultsFolderStructure(t *testing.T, baseFolder, hostID string) {
 		"/" + model.DownloadCIDsFolderName + "/" + resultsCID + "/data/apples/file.txt",
 		"/" + model.DownloadCIDsFolderName + "/" + resultsCID + "/data/file.txt",
 		"/" + model.DownloadCIDsFolderName + "/" + resultsCID + "/exitCode",
+		"/" + model.DownloadCIDsFolderName + "/" + resultsCID + "/" + model.DownloadFilenameManifest,
 		"/" + model.DownloadCIDsFolderName + "/" + resultsCID + "/outputs",
 		"/" + model.DownloadCIDsFolderName + "/" + resultsCID + "/stderr",
 		"/" + model.DownloadCIDsFolderName + "/" + resultsCID + "/stdout",
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/filecoin-project/bacalhau/pkg/job"
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/requester/publicapi"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 	"github.com/filecoin-project/bacalhau/pkg/util/templates"
 	"github.com/jedib0t/go-pretty/v6/table"
@@ -25,7 +27,13 @@ var (
 		bacalhau list
 
 		# List jobs and output as json
-		bacalhau list --output json`))
+		bacalhau list --output json
+
+		# List failed Docker jobs created in the last day
+		bacalhau list --state Error --engine Docker --created-after 24h
+
+		# List jobs annotated with "project:foo" that ran on a given node
+		bacalhau list --selector project=foo --node QmXaXu9N5GNetatsvwnTfQqNtSeKAD6uCmarbh3LMRYAcF`))
 
 	// The tags that will be excluded by default, if the user does not pass any
 	// others to the list command.
@@ -35,17 +43,25 @@ var (
 )
 
 type ListOptions struct {
-	HideHeader   bool                // Hide the column headers
-	IDFilter     string              // Filter by Job List to IDs matching substring.
-	IncludeTags  []model.IncludedTag // Only return jobs with these annotations
-	ExcludeTags  []model.ExcludedTag // Only return jobs without these annotations
-	NoStyle      bool                // Remove all styling from table output.
-	MaxJobs      int                 // Print the first NUM jobs instead of the first 10.
-	OutputFormat string              // The output format for the list of jobs (json or text)
-	SortReverse  bool                // Reverse order of table - for time sorting, this will be newest first.
-	SortBy       ColumnEnum          // Sort by field, defaults to creation time, with newest first [Allowed "id", "created_at"].
-	OutputWide   bool                // Print full values in the table results
-	ReturnAll    bool                // Return all jobs, not just those that belong to the user
+	HideHeader    bool                 // Hide the column headers
+	IDFilter      string               // Filter by Job List to IDs matching substring.
+	IncludeTags   []model.IncludedTag  // Only return jobs with these annotations
+	ExcludeTags   []model.ExcludedTag  // Only return jobs without these annotations
+	NoStyle       bool                 // Remove all styling from table output.
+	MaxJobs       int                  // Print the first NUM jobs instead of the first 10.
+	OutputFormat  string               // The output format for the list of jobs (json or text)
+	SortReverse   bool                 // Reverse order of table - for time sorting, this will be newest first.
+	SortBy        ColumnEnum           // Sort by field, defaults to creation time, with newest first [Allowed "id", "created_at"].
+	OutputWide    bool                 // Print full values in the table results
+	ReturnAll     bool                 // Return all jobs, not just those that belong to the user
+	States        []model.JobStateType // Only return jobs in one of these states
+	CreatedAfter  time.Time            // Only return jobs created after this time
+	CreatedBefore time.Time            // Only return jobs created before this time
+	Engines       []model.Engine       // Only return jobs using one of these engines
+	Publishers    []model.Publisher    // Only return jobs using one of these publishers
+	NodeID        string               // Only return jobs executed by this node
+	Selector      string               // Only return jobs whose annotations match this selector
+	Cursor        string               // Return the page of jobs following a previous page
 }
 
 func NewListOptions() *ListOptions {
@@ -114,6 +130,23 @@ func newListCmd() *cobra.Command {
 		//nolint:lll // Documentation
 		`Fetch all jobs from the network (default is to filter those belonging to the user). This option may take a long time to return, please use with caution.`,
 	)
+	listCmd.PersistentFlags().Var(JobStateTypeArrayFlag(&OL.States), "state",
+		`Only return jobs in the given state. Can be repeated to match any of several states.`)
+	listCmd.PersistentFlags().Var(TimeFlag(&OL.CreatedAfter), "created-after",
+		`Only return jobs created after the given RFC3339 time, or duration ago (e.g. 24h).`)
+	listCmd.PersistentFlags().Var(TimeFlag(&OL.CreatedBefore), "created-before",
+		`Only return jobs created before the given RFC3339 time, or duration ago (e.g. 24h).`)
+	listCmd.PersistentFlags().Var(EngineArrayFlag(&OL.Engines), "engine",
+		`Only return jobs using the given engine. Can be repeated to match any of several engines.`)
+	listCmd.PersistentFlags().Var(PublisherArrayFlag(&OL.Publishers), "publisher",
+		`Only return jobs using the given publisher. Can be repeated to match any of several publishers.`)
+	listCmd.PersistentFlags().StringVar(&OL.NodeID, "node", OL.NodeID,
+		`Only return jobs that were executed by the given compute node.`)
+	listCmd.PersistentFlags().StringVar(&OL.Selector, "selector", OL.Selector,
+		//nolint:lll // Documentation
+		`Only return jobs whose annotations match the selector (e.g. 'project=foo,!canary'). Annotations of the form "key:value" are matched as a key and a value.`)
+	listCmd.PersistentFlags().StringVar(&OL.Cursor, "cursor", OL.Cursor,
+		`Return the page of jobs following the page that printed this cursor.`)
 
 	return listCmd
 }
@@ -159,19 +192,32 @@ func list(cmd *cobra.Command, OL *ListOptions) error {
 	log.Ctx(ctx).Debug().Msgf("Found no-style header flag set to: %t", OL.NoStyle)
 	log.Ctx(ctx).Debug().Msgf("Found output wide flag set to: %t", OL.OutputWide)
 
-	jobs, err := GetAPIClient().List(
-		ctx,
-		OL.IDFilter,
-		OL.IncludeTags,
-		OL.ExcludeTags,
-		OL.MaxJobs,
-		OL.ReturnAll,
-		OL.SortBy.String(),
-		OL.SortReverse,
-	)
+	annotationSelectors, err := job.ParseAnnotationSelector(OL.Selector)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error parsing selector: %s", err), 1)
+	}
+
+	res, err := GetAPIClient().ListPage(ctx, publicapi.ListRequest{
+		JobID:               OL.IDFilter,
+		IncludeTags:         OL.IncludeTags,
+		ExcludeTags:         OL.ExcludeTags,
+		MaxJobs:             OL.MaxJobs,
+		ReturnAll:           OL.ReturnAll,
+		SortBy:              OL.SortBy.String(),
+		SortReverse:         OL.SortReverse,
+		States:              OL.States,
+		CreatedAfter:        OL.CreatedAfter,
+		CreatedBefore:       OL.CreatedBefore,
+		Engines:             OL.Engines,
+		Publishers:          OL.Publishers,
+		NodeID:              OL.NodeID,
+		AnnotationSelectors: annotationSelectors,
+		Cursor:              OL.Cursor,
+	})
 	if err != nil {
 		Fatal(cmd, fmt.Sprintf("Error listing jobs: %s", err), 1)
 	}
+	jobs := res.Jobs
 
 	numberInTable := system.Min(OL.MaxJobs, len(jobs))
 	log.Ctx(ctx).Debug().Msgf("Number of jobs printing: %d", numberInTable)
@@ -229,6 +275,10 @@ func list(cmd *cobra.Command, OL *ListOptions) error {
 		tw.Render()
 	}
 
+	if res.NextCursor != "" && OL.OutputFormat != JSONFormat {
+		cmd.PrintErrf("\nTo list the next page of jobs, add: --cursor %s\n", res.NextCursor)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,109 @@
+package bacalhau
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/util/templates"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	//nolint:lll // Documentation
+	logsLong = templates.LongDesc(i18n.T(`
+		Print the stdout and stderr of a job shard, while it runs and after it completed. The logs are read from the latest execution of the shard. Short form and long form of the job id are accepted.
+`))
+
+	logsExample = templates.Examples(i18n.T(`
+		# Print the logs of a job
+		bacalhau logs 51225160-807e-48b8-88c9-28311c7899e1
+
+		# Follow the logs of the second shard of a job until it completes
+		bacalhau logs --follow --shard 1 51225160`))
+)
+
+type LogsOptions struct {
+	Follow       bool          // Keep printing new logs until the shard completes
+	ShardIndex   int           // The index of the shard to print the logs of
+	PollInterval time.Duration // How often to check for new logs when following
+}
+
+func NewLogsOptions() *LogsOptions {
+	return &LogsOptions{
+		Follow:       false,
+		ShardIndex:   0,
+		PollInterval: time.Second,
+	}
+}
+
+func newLogsCmd() *cobra.Command {
+	OL := NewLogsOptions()
+
+	logsCmd := &cobra.Command{
+		Use:     "logs [id]",
+		Short:   "Print the logs of a job",
+		Long:    logsLong,
+		Example: logsExample,
+		Args:    cobra.ExactArgs(1),
+		PreRun:  applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return logs(cmd, cmdArgs, OL)
+		},
+	}
+
+	logsCmd.PersistentFlags().BoolVarP(
+		&OL.Follow, "follow", "f", OL.Follow,
+		`Keep printing new logs until the shard completes`,
+	)
+	logsCmd.PersistentFlags().IntVar(
+		&OL.ShardIndex, "shard", OL.ShardIndex,
+		`The index of the shard to print the logs of`,
+	)
+
+	return logsCmd
+}
+
+func logs(cmd *cobra.Command, cmdArgs []string, OL *LogsOptions) error {
+	ctx := cmd.Context()
+	apiClient := GetAPIClient()
+
+	j, _, err := apiClient.Get(ctx, cmdArgs[0])
+	if err != nil {
+		if er, ok := err.(*bacerrors.ErrorResponse); ok {
+			Fatal(cmd, er.Message, 1)
+			return nil
+		}
+		Fatal(cmd, fmt.Sprintf("Error getting job %s: %s", cmdArgs[0], err), 1)
+		return nil
+	}
+	jobID := j.Job.Metadata.ID
+
+	offset := 0
+	for {
+		executionLogs, err := apiClient.GetLogs(ctx, jobID, OL.ShardIndex, offset)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error getting logs of job %s: %s", jobID, err), 1)
+			return nil
+		}
+		for _, entry := range executionLogs.Logs {
+			if entry.Stream == model.LogStreamStderr {
+				cmd.PrintErr(entry.Data)
+			} else {
+				cmd.Print(entry.Data)
+			}
+		}
+		offset = executionLogs.NextOffset
+
+		if !OL.Follow || executionLogs.Finished {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(OL.PollInterval):
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package bacalhau
+
+import (
+	"fmt"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/util/templates"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+)
+
+var (
+	//nolint:lll // Documentation
+	nodeReputationLong = templates.LongDesc(i18n.T(`
+		Show the reputation of compute nodes, as recorded by the requester node from the outcomes of their past
+		executions: verifications that passed or failed, executions that timed out or failed, and bids that were
+		accepted but never executed.
+
+		The score is between 0 and 1, where nodes that never ran a job score 0.5. The requester prefers nodes with
+		higher scores when asking for bids, and ignores nodes below its --min-node-reputation.
+
+		Only the operator of the requester node, using the same client identity as the node, can reset reputations.
+`))
+
+	//nolint:lll // Documentation
+	nodeReputationExample = templates.Examples(i18n.T(`
+		# Show the reputation of all compute nodes with recorded outcomes
+		bacalhau node reputation
+
+		# Show the reputation of a single compute node
+		bacalhau node reputation QmXaXu9N5GNetatsvwnTfQqNtSeKAD6uCmarbh3LMRYAcF
+
+		# Forget the recorded outcomes of a compute node
+		bacalhau node reputation --reset QmXaXu9N5GNetatsvwnTfQqNtSeKAD6uCmarbh3LMRYAcF
+`))
+)
+
+type NodeReputationOptions struct {
+	Reset      bool // Reset the reputation of the node
+	HideHeader bool // Hide the column headers
+	NoStyle    bool // Remove all styling from table output
+	OutputWide bool // Print full values in the table results
+}
+
+func NewNodeReputationOptions() *NodeReputationOptions {
+	return &NodeReputationOptions{}
+}
+
+func newNodeCmd() *cobra.Command {
+	nodeCmd := &cobra.Command{
+		Use:               "node",
+		Short:             "Inspect and manage the compute nodes known to the requester node",
+		PersistentPreRunE: checkVersion,
+	}
+
+	nodeCmd.AddCommand(newNodeReputationCmd())
+	return nodeCmd
+}
+
+func newNodeReputationCmd() *cobra.Command {
+	OR := NewNodeReputationOptions()
+
+	reputationCmd := &cobra.Command{
+		Use:     "reputation [id]",
+		Short:   "Show or reset the reputation of compute nodes",
+		Long:    nodeReputationLong,
+		Example: nodeReputationExample,
+		Args:    cobra.MaximumNArgs(1),
+		PreRun:  applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return nodeReputation(cmd, cmdArgs, OR)
+		},
+	}
+
+	reputationCmd.PersistentFlags().BoolVar(&OR.Reset, "reset", OR.Reset,
+		`Forget the recorded outcomes of the given node. Only allowed to the operator of the requester node.`,
+	)
+	reputationCmd.PersistentFlags().BoolVar(&OR.HideHeader, "hide-header", OR.HideHeader,
+		`do not print the column headers.`)
+	reputationCmd.PersistentFlags().BoolVar(&OR.NoStyle, "no-style", OR.NoStyle, `remove all styling from table output.`)
+	reputationCmd.PersistentFlags().BoolVar(&OR.OutputWide, "wide", OR.OutputWide, `Print full values in the table results`)
+	return reputationCmd
+}
+
+func nodeReputation(cmd *cobra.Command, cmdArgs []string, OR *NodeReputationOptions) error {
+	ctx := cmd.Context()
+
+	nodeID := ""
+	if len(cmdArgs) > 0 {
+		nodeID = cmdArgs[0]
+	}
+
+	var reputations []model.NodeReputation
+	if OR.Reset {
+		if nodeID == "" {
+			Fatal(cmd, "The id of the node to reset is required with --reset", 1)
+			return nil
+		}
+		reputation, err := GetAPIClient().ResetNodeReputation(ctx, nodeID)
+		if err != nil {
+			if er, ok := err.(*bacerrors.ErrorResponse); ok {
+				Fatal(cmd, er.Message, 1)
+				return nil
+			}
+			Fatal(cmd, fmt.Sprintf("Unknown error trying to reset the reputation of node %s: %+v", nodeID, err), 1)
+			return nil
+		}
+		reputations = append(reputations, reputation)
+	} else {
+		var err error
+		reputations, err = GetAPIClient().GetNodeReputations(ctx, nodeID)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error getting node reputations: %s", err), 1)
+			return nil
+		}
+	}
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(cmd.OutOrStdout())
+	if !OR.HideHeader {
+		tw.AppendHeader(table.Row{
+			"node", "score", "passed", "failed", "timeouts", "compute failures", "no shows", "updated",
+		})
+	}
+	for _, reputation := range reputations {
+		updated := ""
+		if !reputation.UpdateTime.IsZero() {
+			updated = shortenTime(OR.OutputWide, reputation.UpdateTime)
+		}
+		tw.AppendRow(table.Row{
+			shortID(OR.OutputWide, reputation.NodeID),
+			fmt.Sprintf("%.2f", reputation.Score()),
+			reputation.VerificationsPassed,
+			reputation.VerificationsFailed,
+			reputation.Timeouts,
+			reputation.ComputeFailures,
+			reputation.NoShows,
+			updated,
+		})
+	}
+
+	if OR.NoStyle {
+		tw.SetStyle(table.Style{
+			Name:   "StyleDefault",
+			Box:    table.StyleBoxDefault,
+			Color:  table.ColorOptionsDefault,
+			Format: table.FormatOptionsDefault,
+			HTML:   table.DefaultHTMLOptions,
+			Options: table.Options{
+				DrawBorder:      false,
+				SeparateColumns: false,
+				SeparateFooter:  false,
+				SeparateHeader:  false,
+				SeparateRows:    false,
+			},
+			Title: table.TitleOptionsDefault,
+		})
+	} else {
+		tw.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	}
+
+	tw.Render()
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 
 var apiHost string
 var apiPort int
+var apiToken string
 var doNotTrack bool
 
 var loggingMode = logger.LogModeDefault
@@ -106,12 +107,24 @@ func NewRootCmd() *cobra.Command {
 	// Get the results of a job
 	RootCmd.AddCommand(newGetCmd())
 
+	// Stream the logs of a job
+	RootCmd.AddCommand(newLogsCmd())
+
 	// Cancel a job
 	RootCmd.AddCommand(newCancelCmd())
 
 	// List jobs
 	RootCmd.AddCommand(newListCmd())
 
+	// Submit and manage workflows of jobs
+	RootCmd.AddCommand(newWorkflowCmd())
+
+	// Create and manage scheduled jobs
+	RootCmd.AddCommand(newScheduleCmd())
+
+	// Inspect and manage compute nodes
+	RootCmd.AddCommand(newNodeCmd())
+
 	// ====== Run a server
 
 	// Serve commands
@@ -129,6 +142,11 @@ Ignored if BACALHAU_API_HOST environment variable is set.`,
 		&apiPort, "api-port", defaultAPIPort,
 		`The port for the client and server to communicate on (via REST).
 Ignored if BACALHAU_API_PORT environment variable is set.`,
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&apiToken, "api-token", "",
+		`The bearer token sent to the requester API, if it requires one.
+Ignored if BACALHAU_API_TOKEN environment variable is set.`,
 	)
 	RootCmd.PersistentFlags().Var(
 		LoggingFlag(&loggingMode), "log-mode",
@@ -162,9 +180,15 @@ func Execute() {
 		log.Ctx(RootCmd.Context()).Fatal().Msgf("API_PORT was set, but could not bind.")
 	}
 
+	err = viper.BindEnv("API_TOKEN")
+	if err != nil {
+		log.Ctx(RootCmd.Context()).Fatal().Msgf("API_TOKEN was set, but could not bind.")
+	}
+
 	viper.AutomaticEnv()
 	envAPIHost := viper.Get("API_HOST")
 	envAPIPort := viper.Get("API_PORT")
+	envAPIToken := viper.Get("API_TOKEN")
 
 	if envAPIHost != nil && envAPIHost != "" {
 		apiHost = envAPIHost.(string)
@@ -178,6 +202,10 @@ func Execute() {
 		}
 	}
 
+	if envAPIToken != nil && envAPIToken != "" {
+		apiToken = envAPIToken.(string)
+	}
+
 	// Use stdout, not stderr for cmd.Print output, so that
 	// e.g. ID=$(bacalhau run) works
 	RootCmd.SetOut(system.Stdout)
@@ -0,0 +1,352 @@
+package bacalhau
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/util/templates"
+	"github.com/imdario/mergo"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	//nolint:lll // Documentation
+	scheduleCreateLong = templates.LongDesc(i18n.T(`
+		Create a schedule that submits a new job at each activation time of a cron expression, using a job
+		specification in a json or yaml file.
+
+		The cron expression has the minute, hour, day of month, month and day of week fields, and is evaluated in UTC.
+		The @yearly, @monthly, @weekly, @daily and @hourly descriptors are also supported.
+
+		The overlap policy defines what happens when a run is due while the job of the previous run is still in progress:
+		  Allow          - submit the new job anyway (default)
+		  Skip           - skip the new run
+		  Queue          - submit the new job once the previous one completes
+		  CancelPrevious - cancel the previous job and submit the new one
+`))
+
+	//nolint:lll // Documentation
+	scheduleCreateExample = templates.Examples(i18n.T(`
+		# Submit a job every hour, skipping a run if the job of the previous run is still in progress
+		cat > job.yaml <<EOF
+		Spec:
+		  Engine: Docker
+		  Docker:
+		    Image: ubuntu
+		    Entrypoint: ["echo", "hello"]
+		EOF
+		bacalhau schedule create --cron "0 * * * *" --overlap skip job.yaml
+
+		# List your schedules
+		bacalhau schedule list
+
+		# Describe a schedule and the jobs submitted by its most recent runs
+		bacalhau schedule describe 5a4b1c7e-8f3d-4d2a-b6c1-0e9f8a7b6c5d
+
+		# Delete a schedule. Jobs that were already submitted keep running
+		bacalhau schedule delete 5a4b1c7e-8f3d-4d2a-b6c1-0e9f8a7b6c5d
+`))
+)
+
+type ScheduleCreateOptions struct {
+	Cron          string                      // Cron expression of the schedule
+	OverlapPolicy model.ScheduleOverlapPolicy // What to do when a run is due while the previous job is in progress
+}
+
+func NewScheduleCreateOptions() *ScheduleCreateOptions {
+	return &ScheduleCreateOptions{
+		OverlapPolicy: model.ScheduleOverlapAllow,
+	}
+}
+
+type ScheduleListOptions struct {
+	ReturnAll  bool // List the schedules of all clients
+	HideHeader bool // Hide the column headers
+	NoStyle    bool // Remove all styling from table output
+	OutputWide bool // Print full values in the table results
+}
+
+func NewScheduleListOptions() *ScheduleListOptions {
+	return &ScheduleListOptions{}
+}
+
+type ScheduleDescribeOptions struct {
+	JSON bool // Print description as JSON
+}
+
+func NewScheduleDescribeOptions() *ScheduleDescribeOptions {
+	return &ScheduleDescribeOptions{
+		JSON: false,
+	}
+}
+
+func newScheduleCmd() *cobra.Command {
+	scheduleCmd := &cobra.Command{
+		Use:               "schedule",
+		Short:             "Create and manage schedules that submit jobs periodically",
+		PersistentPreRunE: checkVersion,
+	}
+
+	scheduleCmd.AddCommand(newScheduleCreateCmd())
+	scheduleCmd.AddCommand(newScheduleListCmd())
+	scheduleCmd.AddCommand(newScheduleDescribeCmd())
+	scheduleCmd.AddCommand(newScheduleDeleteCmd())
+	return scheduleCmd
+}
+
+func newScheduleCreateCmd() *cobra.Command {
+	OC := NewScheduleCreateOptions()
+
+	createCmd := &cobra.Command{
+		Use:     "create [file]",
+		Short:   "Create a schedule that submits a job using a json or yaml file",
+		Long:    scheduleCreateLong,
+		Example: scheduleCreateExample,
+		Args:    cobra.MaximumNArgs(1),
+		PreRun:  applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return scheduleCreate(cmd, cmdArgs, OC)
+		},
+	}
+
+	createCmd.PersistentFlags().StringVar(
+		&OC.Cron, "cron", OC.Cron,
+		`Cron expression of the schedule, in UTC (e.g. "0 * * * *" to run at the start of every hour).`,
+	)
+	createCmd.PersistentFlags().Var(
+		ScheduleOverlapPolicyFlag(&OC.OverlapPolicy), "overlap",
+		`What to do when a run is due while the previous job is still in progress. One of allow, skip, queue or cancelprevious.`,
+	)
+	_ = createCmd.MarkPersistentFlagRequired("cron")
+	return createCmd
+}
+
+func newScheduleListCmd() *cobra.Command {
+	OL := NewScheduleListOptions()
+
+	listCmd := &cobra.Command{
+		Use:    "list",
+		Short:  "List schedules",
+		Args:   cobra.NoArgs,
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return scheduleList(cmd, OL)
+		},
+	}
+
+	listCmd.PersistentFlags().BoolVar(&OL.ReturnAll, "all", OL.ReturnAll,
+		`Fetch all schedules from the network (default is to filter those belonging to the user).`,
+	)
+	listCmd.PersistentFlags().BoolVar(&OL.HideHeader, "hide-header", OL.HideHeader,
+		`do not print the column headers.`)
+	listCmd.PersistentFlags().BoolVar(&OL.NoStyle, "no-style", OL.NoStyle, `remove all styling from table output.`)
+	listCmd.PersistentFlags().BoolVar(&OL.OutputWide, "wide", OL.OutputWide, `Print full values in the table results`)
+	return listCmd
+}
+
+func newScheduleDescribeCmd() *cobra.Command {
+	OD := NewScheduleDescribeOptions()
+
+	describeCmd := &cobra.Command{
+		Use:    "describe [id]",
+		Short:  "Describe a schedule and the history of its runs",
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return scheduleDescribe(cmd, cmdArgs, OD)
+		},
+	}
+
+	describeCmd.PersistentFlags().BoolVar(
+		&OD.JSON, "json", OD.JSON,
+		`Output description as JSON (if not included will be outputted as YAML by default)`,
+	)
+	return describeCmd
+}
+
+func newScheduleDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:    "delete [id]",
+		Short:  "Delete a schedule. Jobs that were already submitted keep running",
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return scheduleDelete(cmd, cmdArgs)
+		},
+	}
+}
+
+func scheduleCreate(cmd *cobra.Command, cmdArgs []string, OC *ScheduleCreateOptions) error {
+	ctx := cmd.Context()
+
+	var byteResult []byte
+	var err error
+	if len(cmdArgs) == 0 {
+		byteResult, err = ReadFromStdinIfAvailable(cmd, cmdArgs)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Unknown error reading from file or stdin: %s\n", err), 1)
+			return err
+		}
+	} else {
+		var fileContent *os.File
+		fileContent, err = os.Open(cmdArgs[0])
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error opening file: %s", err), 1)
+			return err
+		}
+		defer fileContent.Close()
+
+		byteResult, err = io.ReadAll(fileContent)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error reading file: %s", err), 1)
+			return err
+		}
+	}
+
+	// the yaml parser supports both yaml & json
+	var j model.Job
+	if err = model.YAMLUnmarshalWithMax(byteResult, &j); err != nil {
+		Fatal(cmd, fmt.Sprintf("Error parsing job: %s", err), 1)
+		return err
+	}
+
+	// fill the fields that were not set in the job spec with the same defaults used when creating a single job
+	defaults, err := model.NewJobWithSaneProductionDefaults()
+	if err != nil {
+		return err
+	}
+	if err = mergo.Merge(&j.Spec, defaults.Spec); err != nil {
+		return err
+	}
+
+	schedule, err := GetAPIClient().CreateSchedule(ctx, &model.ScheduleSpec{
+		Cron:          OC.Cron,
+		OverlapPolicy: OC.OverlapPolicy,
+		JobSpec:       j.Spec,
+	})
+	if err != nil {
+		if er, ok := err.(*bacerrors.ErrorResponse); ok {
+			Fatal(cmd, er.Message, 1)
+			return nil
+		}
+		Fatal(cmd, fmt.Sprintf("Unknown error trying to create schedule: %+v", err), 1)
+		return nil
+	}
+
+	cmd.Printf("Schedule ID: %s\n", schedule.ID)
+	cmd.Printf("Next run: %s\n", schedule.NextRun.Format(time.RFC3339))
+	return nil
+}
+
+func scheduleList(cmd *cobra.Command, OL *ScheduleListOptions) error {
+	ctx := cmd.Context()
+
+	schedules, err := GetAPIClient().ListSchedules(ctx, OL.ReturnAll)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Error listing schedules: %s", err), 1)
+		return nil
+	}
+
+	tw := table.NewWriter()
+	tw.SetOutputMirror(cmd.OutOrStdout())
+	if !OL.HideHeader {
+		tw.AppendHeader(table.Row{"created", "id", "cron", "overlap", "next run", "last job"})
+	}
+	for _, s := range schedules {
+		lastJobID := ""
+		for i := len(s.Runs) - 1; i >= 0; i-- {
+			if s.Runs[i].JobID != "" {
+				lastJobID = s.Runs[i].JobID
+				break
+			}
+		}
+		tw.AppendRow(table.Row{
+			shortenTime(OL.OutputWide, s.CreateTime),
+			shortID(OL.OutputWide, s.ID),
+			s.Spec.Cron,
+			s.Spec.OverlapPolicy.String(),
+			s.NextRun.Format(time.RFC3339),
+			shortID(OL.OutputWide, lastJobID),
+		})
+	}
+
+	if OL.NoStyle {
+		tw.SetStyle(table.Style{
+			Name:   "StyleDefault",
+			Box:    table.StyleBoxDefault,
+			Color:  table.ColorOptionsDefault,
+			Format: table.FormatOptionsDefault,
+			HTML:   table.DefaultHTMLOptions,
+			Options: table.Options{
+				DrawBorder:      false,
+				SeparateColumns: false,
+				SeparateFooter:  false,
+				SeparateHeader:  false,
+				SeparateRows:    false,
+			},
+			Title: table.TitleOptionsDefault,
+		})
+	} else {
+		tw.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	}
+
+	tw.Render()
+	return nil
+}
+
+func scheduleDescribe(cmd *cobra.Command, cmdArgs []string, OD *ScheduleDescribeOptions) error {
+	ctx := cmd.Context()
+
+	scheduleID := cmdArgs[0]
+	schedule, err := GetAPIClient().GetSchedule(ctx, scheduleID)
+	if err != nil {
+		if er, ok := err.(*bacerrors.ErrorResponse); ok {
+			Fatal(cmd, er.Message, 1)
+			return nil
+		}
+		Fatal(cmd, fmt.Sprintf("Unknown error trying to get schedule (ID: %s): %+v", scheduleID, err), 1)
+		return nil
+	}
+
+	b, err := json.Marshal(schedule)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Failure marshaling schedule description '%s': %s\n", scheduleID, err), 1)
+	}
+
+	if !OD.JSON {
+		y, err := yaml.JSONToYAML(b)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Failure converting schedule description '%s' to YAML: %s\n", scheduleID, err), 1)
+		}
+		cmd.Print(string(y))
+	} else {
+		cmd.Print(string(b))
+	}
+	return nil
+}
+
+func scheduleDelete(cmd *cobra.Command, cmdArgs []string) error {
+	ctx := cmd.Context()
+
+	scheduleID := cmdArgs[0]
+	schedule, err := GetAPIClient().DeleteSchedule(ctx, scheduleID)
+	if err != nil {
+		if er, ok := err.(*bacerrors.ErrorResponse); ok {
+			Fatal(cmd, er.Message, 1)
+			return nil
+		}
+		Fatal(cmd, fmt.Sprintf("Unknown error trying to delete schedule (ID: %s): %+v", scheduleID, err), 1)
+		return nil
+	}
+
+	cmd.Printf("Schedule successfully deleted. Schedule ID: %s\n", schedule.ID)
+	return nil
+}
@@ -9,15 +9,30 @@ import (
 	"strings"
 	"time"
 
+	"github.com/c2h5oh/datasize"
+	"github.com/filecoin-project/bacalhau/pkg/compute"
 	"github.com/filecoin-project/bacalhau/pkg/compute/capacity"
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	compute_inmemory "github.com/filecoin-project/bacalhau/pkg/compute/store/inmemory"
+	compute_sqlite "github.com/filecoin-project/bacalhau/pkg/compute/store/sqlite"
+	"github.com/filecoin-project/bacalhau/pkg/config"
 	"github.com/filecoin-project/bacalhau/pkg/ipfs"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
 	"github.com/filecoin-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/postgres"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/sqlite"
 	"github.com/filecoin-project/bacalhau/pkg/libp2p"
 	"github.com/filecoin-project/bacalhau/pkg/libp2p/rcmgr"
 	"github.com/filecoin-project/bacalhau/pkg/logger"
 	"github.com/filecoin-project/bacalhau/pkg/model"
 	"github.com/filecoin-project/bacalhau/pkg/node"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
 	filecoinlotus "github.com/filecoin-project/bacalhau/pkg/publisher/filecoin_lotus"
+	httppublisher "github.com/filecoin-project/bacalhau/pkg/publisher/http"
+	localpublisher "github.com/filecoin-project/bacalhau/pkg/publisher/local"
+	s3publisher "github.com/filecoin-project/bacalhau/pkg/publisher/s3"
+	"github.com/filecoin-project/bacalhau/pkg/s3"
+	"github.com/filecoin-project/bacalhau/pkg/storage/cache"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 	"github.com/filecoin-project/bacalhau/pkg/util/templates"
 	"github.com/multiformats/go-multiaddr"
@@ -57,6 +72,14 @@ type ServeOptions struct {
 	IPFSConnect                           string            // The multiaddress to connect to for IPFS.
 	FilecoinUnsealedPath                  string            // Go template to turn a Filecoin CID into a local filepath with the unsealed data.
 	EstuaryAPIKey                         string            // The API key used when using the estuary API.
+	S3Endpoint                            string            // The endpoint of the S3-compatible service used for S3 inputs.
+	S3Region                              string            // The region of the buckets used for S3 inputs.
+	S3PublisherBucket                     string            // The bucket results are published to by the S3 publisher.
+	S3PublisherPrefix                     string            // The template of the prefix results are published under by the S3 publisher.
+	LocalPublisherRoot                    string            // The directory results are published to by the local publisher.
+	HTTPPublisherURL                      string            // The template of the URL results are uploaded to by the HTTP publisher.
+	InputCacheSize                        string            // The amount of disk space used to cache inputs across executions.
+	AllowLocalGitRepositories             bool              // Whether git inputs can be cloned from the local filesystem.
 	HostAddress                           string            // The host address to listen on.
 	SwarmPort                             int               // The host port for libp2p network.
 	JobSelectionDataLocality              string            // The data locality to use for job selection.
@@ -70,6 +93,19 @@ type ServeOptions struct {
 	LimitJobCPU                           string            // The amount of CPU the system can be using at one time for a single job.
 	LimitJobMemory                        string            // The amount of memory the system can be using at one time for a single job.
 	LimitJobGPU                           string            // The amount of GPU the system can be using at one time for a single job.
+	LimitJobOutputVolume                  string            // The size each output volume of a single job can grow to.
+	LimitJobOutputTotal                   string            // The total size the output volumes of a single job can grow to.
+	ResultQuota                           string            // The total size of the results the jobs of each client can store.
+	ClientResultQuotas                    map[string]string // The total size of the results the jobs of specific clients can store, by client ID.
+	MinNodeReputation                     float64           // The reputation score below which compute nodes are not asked to execute jobs.
+	ClientSubmissionsPerMinute            int               // The number of jobs each client can submit per minute.
+	ClientMaxInProgressJobs               int               // The number of jobs of each client that can be in progress at the same time.
+	ClientMaxCPU                          string            // The total CPU that the in progress jobs of each client can request.
+	ClientMaxMemory                       string            // The total memory that the in progress jobs of each client can request.
+	ClientLimitsFile                      string            // File with the limits on the jobs of specific clients, by client ID.
+	APIAllowedClients                     []string          // Client IDs or public keys of the clients allowed to use the requester API
+	APITokensFile                         string            // File with the bearer tokens required to use the requester API
+	APIPolicyFile                         string            // File with the policy of what clients can do with the requester API
 	LotusFilecoinStorageDuration          time.Duration     // How long deals should be for the Lotus Filecoin publisher
 	LotusFilecoinPathDirectory            string            // The location of the Lotus configuration directory which contains config.toml, etc
 	LotusFilecoinUploadDirectory          string            // Directory to put files when uploading to Lotus (optional)
@@ -78,6 +114,13 @@ type ServeOptions struct {
 	Labels                                map[string]string // Labels to apply to the node that can be used for node selection and filtering
 	IPFSSwarmAddresses                    []string          // IPFS multiaddresses that the in-process IPFS should connect to
 	PrivateInternalIPFS                   bool              // Whether the in-process IPFS should automatically discover other IPFS nodes
+	JobStore                              string            // The type of job store used by the requester node
+	JobStorePath                          string            // The location of the job store database, if persistent
+	ExecutionStore                        string            // The type of execution store used by the compute node
+	ExecutionStorePath                    string            // The location of the execution store database, if persistent
+	ExecutorBufferMode                    string            // The order in which the compute node runs enqueued jobs
+	ExecutorBufferAgingInterval           time.Duration     // How long an enqueued job waits to gain one level of priority
+	ExecutorBufferPreemption              bool              // Whether running jobs can be preempted by higher priority jobs
 }
 
 func NewServeOptions() *ServeOptions {
@@ -100,11 +143,106 @@ func NewServeOptions() *ServeOptions {
 		LimitJobCPU:                     "",
 		LimitJobMemory:                  "",
 		LimitJobGPU:                     "",
+		LimitJobOutputVolume:            "",
+		LimitJobOutputTotal:             "",
+		ResultQuota:                     "",
 		LotusFilecoinPathDirectory:      os.Getenv("LOTUS_PATH"),
 		LotusFilecoinMaximumPing:        2 * time.Second,
+		JobStore:                        jobStoreInMemory,
+		JobStorePath:                    "",
+		ExecutionStore:                  executionStoreInMemory,
+		ExecutionStorePath:              "",
+		ExecutorBufferMode:              string(node.DefaultComputeConfig.ExecutorBufferMode),
+		ExecutorBufferAgingInterval:     node.DefaultComputeConfig.ExecutorBufferAgingInterval,
+		ExecutorBufferPreemption:        false,
 	}
 }
 
+const (
+	jobStoreInMemory = "inmemory"
+	jobStoreSQLite   = "sqlite"
+	jobStorePostgres = "postgres"
+)
+
+func setupJobStoreCLIFlags(cmd *cobra.Command, OS *ServeOptions) {
+	cmd.PersistentFlags().StringVar(
+		&OS.JobStore, "job-store", OS.JobStore,
+		`The job store used by the requester node to persist jobs ("inmemory", "sqlite" or "postgres"). `+
+			`Requester nodes sharing a sqlite or postgres job store adopt the jobs of requester nodes that stopped.`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&OS.JobStorePath, "job-store-path", OS.JobStorePath,
+		`The database file used when --job-store is "sqlite" (defaults to a file in the bacalhau config directory), `+
+			`or the connection string used when --job-store is "postgres".`,
+	)
+}
+
+func getJobStore(OS *ServeOptions) (jobstore.Store, error) {
+	switch OS.JobStore {
+	case jobStoreInMemory:
+		return inmemory.NewJobStore(), nil
+	case jobStoreSQLite:
+		path := OS.JobStorePath
+		if path == "" {
+			path = config.GetJobStorePath()
+		}
+		return sqlite.NewSQLiteJobStore(path)
+	case jobStorePostgres:
+		if OS.JobStorePath == "" {
+			return nil, fmt.Errorf("--job-store-path must be set to a connection string when --job-store is '%s'", jobStorePostgres)
+		}
+		return postgres.NewPostgresJobStore(OS.JobStorePath)
+	default:
+		return nil, fmt.Errorf("--job-store must be one of '%s', '%s' or '%s'", jobStoreInMemory, jobStoreSQLite, jobStorePostgres)
+	}
+}
+
+const (
+	executionStoreInMemory = "inmemory"
+	executionStoreSQLite   = "sqlite"
+)
+
+func setupExecutionStoreCLIFlags(cmd *cobra.Command, OS *ServeOptions) {
+	cmd.PersistentFlags().StringVar(
+		&OS.ExecutionStore, "execution-store", OS.ExecutionStore,
+		`The execution store used by the compute node to persist executions ("inmemory" or "sqlite"). `+
+			`Compute nodes using a sqlite execution store recover their executions after a restart.`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&OS.ExecutionStorePath, "execution-store-path", OS.ExecutionStorePath,
+		`The database file used when --execution-store is "sqlite" (defaults to a file in the bacalhau config directory).`,
+	)
+}
+
+func getExecutionStore(OS *ServeOptions) (store.ExecutionStore, error) {
+	switch OS.ExecutionStore {
+	case executionStoreInMemory:
+		return compute_inmemory.NewStore(), nil
+	case executionStoreSQLite:
+		path := OS.ExecutionStorePath
+		if path == "" {
+			path = config.GetExecutionStorePath()
+		}
+		return compute_sqlite.NewStore(path)
+	default:
+		return nil, fmt.Errorf("--execution-store must be one of '%s' or '%s'", executionStoreInMemory, executionStoreSQLite)
+	}
+}
+
+func getInputCache(OS *ServeOptions, cm *system.CleanupManager) (*cache.Cache, error) {
+	if OS.InputCacheSize == "" {
+		return nil, nil
+	}
+	size, err := datasize.ParseString(OS.InputCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --input-cache-size %q: %w", OS.InputCacheSize, err)
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	return cache.NewCache(cm, cache.Params{MaxSize: size.Bytes()})
+}
+
 func setupJobSelectionCLIFlags(cmd *cobra.Command, OS *ServeOptions) {
 	cmd.PersistentFlags().StringVar(
 		&OS.JobSelectionDataLocality, "job-selection-data-locality", OS.JobSelectionDataLocality,
@@ -153,10 +291,31 @@ func setupCapacityManagerCLIFlags(cmd *cobra.Command, OS *ServeOptions) {
 		&OS.LimitJobGPU, "limit-job-gpu", OS.LimitJobGPU,
 		`Job GPU limit for single job (e.g. 1, 2, or 8).`,
 	)
+	cmd.PersistentFlags().StringVar(
+		&OS.LimitJobOutputVolume, "limit-job-output-volume", OS.LimitJobOutputVolume,
+		`Size limit of each output volume of a single job (e.g. 500Mb, 2Gb). Jobs exceeding it fail.`,
+	)
+	cmd.PersistentFlags().StringVar(
+		&OS.LimitJobOutputTotal, "limit-job-output-total", OS.LimitJobOutputTotal,
+		`Size limit of all the output volumes of a single job together (e.g. 500Mb, 2Gb). Jobs exceeding it fail.`,
+	)
 	cmd.PersistentFlags().StringSliceVar(
 		&OS.JobExecutionTimeoutClientIDBypassList, "job-execution-timeout-bypass-client-id", OS.JobExecutionTimeoutClientIDBypassList,
 		`List of IDs of clients that are allowed to bypass the job execution timeout check`,
 	)
+	cmd.PersistentFlags().StringVar(
+		&OS.ExecutorBufferMode, "executor-buffer-mode", OS.ExecutorBufferMode,
+		`The order in which enqueued jobs are run ("fifo" or "priority").`,
+	)
+	cmd.PersistentFlags().DurationVar(
+		&OS.ExecutorBufferAgingInterval, "executor-buffer-aging-interval", OS.ExecutorBufferAgingInterval,
+		`How long an enqueued job waits to gain one level of priority when --executor-buffer-mode is "priority" (0 to disable aging).`,
+	)
+	cmd.PersistentFlags().BoolVar(
+		&OS.ExecutorBufferPreemption, "executor-buffer-preemption", OS.ExecutorBufferPreemption,
+		`Cancel and requeue running jobs with lower priority when a higher priority job does not fit, `+
+			`when --executor-buffer-mode is "priority".`,
+	)
 }
 
 func setupLibp2pCLIFlags(cmd *cobra.Command, OS *ServeOptions) {
@@ -214,7 +373,23 @@ func getJobSelectionConfig(OS *ServeOptions) model.JobSelectionPolicy {
 	return jobSelectionPolicy
 }
 
-func getComputeConfig(OS *ServeOptions) node.ComputeConfig {
+func getComputeConfig(OS *ServeOptions) (node.ComputeConfig, error) {
+	executorBufferMode, err := compute.ParseExecutorBufferMode(OS.ExecutorBufferMode)
+	if err != nil {
+		return node.ComputeConfig{}, err
+	}
+	outputLimits := model.OutputLimitsConfig{
+		Volume: OS.LimitJobOutputVolume,
+		Total:  OS.LimitJobOutputTotal,
+	}
+	if err = capacity.ValidateOutputLimitsConfig(outputLimits); err != nil {
+		return node.ComputeConfig{}, err
+	}
+	// a zero interval disables aging on the command line, while the compute config replaces it with the default
+	agingInterval := OS.ExecutorBufferAgingInterval
+	if agingInterval <= 0 {
+		agingInterval = node.DisabledExecutorBufferAging
+	}
 	return node.NewComputeConfigWith(node.ComputeConfigParams{
 		JobSelectionPolicy: getJobSelectionConfig(OS),
 		TotalResourceLimits: capacity.ParseResourceUsageConfig(model.ResourceUsageConfig{
@@ -227,9 +402,105 @@ func getComputeConfig(OS *ServeOptions) node.ComputeConfig {
 			Memory: OS.LimitJobMemory,
 			GPU:    OS.LimitJobGPU,
 		}),
+		JobOutputLimits:                       capacity.ParseOutputLimitsConfig(outputLimits),
 		IgnorePhysicalResourceLimits:          os.Getenv("BACALHAU_CAPACITY_MANAGER_OVER_COMMIT") != "",
 		JobExecutionTimeoutClientIDBypassList: OS.JobExecutionTimeoutClientIDBypassList,
-	})
+		ExecutorBufferMode:                    executorBufferMode,
+		ExecutorBufferAgingInterval:           agingInterval,
+		ExecutorBufferPreemption:              OS.ExecutorBufferPreemption,
+	}), nil
+}
+
+func getRequesterConfig(OS *ServeOptions) (node.RequesterConfig, error) {
+	params := node.RequesterConfigParams{}
+	if OS.ResultQuota != "" {
+		quota, err := datasize.ParseString(OS.ResultQuota)
+		if err != nil {
+			return node.RequesterConfig{}, fmt.Errorf("invalid --result-quota %q: %w", OS.ResultQuota, err)
+		}
+		params.DefaultResultQuota = quota.Bytes()
+	}
+	if len(OS.ClientResultQuotas) > 0 {
+		params.ClientResultQuotas = make(map[string]uint64, len(OS.ClientResultQuotas))
+		for clientID, value := range OS.ClientResultQuotas {
+			quota, err := datasize.ParseString(value)
+			if err != nil {
+				return node.RequesterConfig{}, fmt.Errorf("invalid --result-quota-client %q for %s: %w", value, clientID, err)
+			}
+			params.ClientResultQuotas[clientID] = quota.Bytes()
+		}
+	}
+	if OS.MinNodeReputation < 0 || OS.MinNodeReputation > 1 {
+		return node.RequesterConfig{}, fmt.Errorf("invalid --min-node-reputation %v: must be between 0 and 1", OS.MinNodeReputation)
+	}
+	params.MinNodeReputation = OS.MinNodeReputation
+	clientLimits := model.ClientLimitsConfig{
+		SubmissionsPerMinute: OS.ClientSubmissionsPerMinute,
+		MaxInProgressJobs:    OS.ClientMaxInProgressJobs,
+		MaxResources:         model.ResourceUsageConfig{CPU: OS.ClientMaxCPU, Memory: OS.ClientMaxMemory},
+	}
+	if err := capacity.ValidateClientLimitsConfig(clientLimits); err != nil {
+		return node.RequesterConfig{}, fmt.Errorf("invalid client limits: %w", err)
+	}
+	params.DefaultClientLimits = capacity.ParseClientLimitsConfig(clientLimits)
+	if OS.ClientLimitsFile != "" {
+		clientLimits, err := loadClientLimitsFile(OS.ClientLimitsFile)
+		if err != nil {
+			return node.RequesterConfig{}, err
+		}
+		params.ClientLimits = clientLimits
+	}
+	return node.NewRequesterConfigWith(params), nil
+}
+
+// loadClientLimitsFile loads a YAML file mapping client IDs to the limits on their jobs
+func loadClientLimitsFile(path string) (map[string]model.ClientLimits, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client limits file: %w", err)
+	}
+	var configs map[string]model.ClientLimitsConfig
+	if err = model.YAMLUnmarshalWithMax(content, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse client limits file %s: %w", path, err)
+	}
+	clientLimits := make(map[string]model.ClientLimits, len(configs))
+	for clientID, config := range configs {
+		if err = capacity.ValidateClientLimitsConfig(config); err != nil {
+			return nil, fmt.Errorf("invalid limits of client %s in %s: %w", clientID, path, err)
+		}
+		clientLimits[clientID] = capacity.ParseClientLimitsConfig(config)
+	}
+	return clientLimits, nil
+}
+
+// getAPIAuthPolicy returns the policy deciding which clients can use the requester API, or nil if all clients can
+func getAPIAuthPolicy(OS *ServeOptions) (auth.Policy, error) {
+	var policies []auth.Policy
+	if len(OS.APIAllowedClients) > 0 {
+		policy, err := auth.NewAllowlistPolicy(OS.APIAllowedClients)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --api-allowed-clients: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+	if OS.APITokensFile != "" {
+		policy, err := auth.NewTokenPolicyFromFile(OS.APITokensFile)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	if OS.APIPolicyFile != "" {
+		policy, err := auth.NewFilePolicyFromFile(OS.APIPolicyFile)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	return auth.NewChain(policies...), nil
 }
 
 func newServeCmd() *cobra.Command {
@@ -267,6 +538,98 @@ func newServeCmd() *cobra.Command {
 		&OS.EstuaryAPIKey, "estuary-api-key", OS.EstuaryAPIKey,
 		`The API key used when using the estuary API.`,
 	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ResultQuota, "result-quota", OS.ResultQuota,
+		`The total size of the results that the jobs of each client can store (e.g. 100GB). Clients that reached it `+
+			`can't submit new jobs. Results are not limited if not set.`,
+	)
+	serveCmd.PersistentFlags().StringToStringVar(
+		&OS.ClientResultQuotas, "result-quota-client", OS.ClientResultQuotas,
+		`The total size of the results that the jobs of specific clients can store, overriding --result-quota `+
+			`(e.g. --result-quota-client clientID1=1TB,clientID2=0). 0 is unlimited.`,
+	)
+	serveCmd.PersistentFlags().Float64Var(
+		&OS.MinNodeReputation, "min-node-reputation", OS.MinNodeReputation,
+		`The reputation score between 0 and 1 below which compute nodes are not asked to execute jobs. `+
+			`Nodes that never ran a job score 0.5. Nodes are never excluded if not set.`,
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.ClientSubmissionsPerMinute, "client-submissions-per-minute", OS.ClientSubmissionsPerMinute,
+		`The number of jobs each client can submit per minute. Submissions are not limited if not set.`,
+	)
+	serveCmd.PersistentFlags().IntVar(
+		&OS.ClientMaxInProgressJobs, "client-max-in-progress-jobs", OS.ClientMaxInProgressJobs,
+		`The number of jobs of each client that can be in progress at the same time. Not limited if not set.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ClientMaxCPU, "client-max-cpu", OS.ClientMaxCPU,
+		`The total CPU that the in progress jobs of each client can request across all their executions (e.g. 500m, 2). `+
+			`Not limited if not set.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ClientMaxMemory, "client-max-memory", OS.ClientMaxMemory,
+		`The total memory that the in progress jobs of each client can request across all their executions (e.g. 16GB). `+
+			`Not limited if not set.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.ClientLimitsFile, "client-limits-file", OS.ClientLimitsFile,
+		`A YAML file mapping client IDs to limits on their jobs, with the fields SubmissionsPerMinute, `+
+			`MaxInProgressJobs and MaxResources. The limits of a client in the file replace the --client-* limits.`,
+	)
+	serveCmd.PersistentFlags().StringSliceVar(
+		&OS.APIAllowedClients, "api-allowed-clients", OS.APIAllowedClients,
+		`The client IDs or base64-encoded public keys of the only clients allowed to use the requester API. `+
+			`Combine with --api-tokens-file to authenticate clients of unsigned requests, such as list and results.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.APITokensFile, "api-tokens-file", OS.APITokensFile,
+		`A file with the bearer tokens required to use the requester API, one per line. A token can be followed by `+
+			`the client ID or public key of the only client allowed to use it.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.APIPolicyFile, "api-policy-file", OS.APIPolicyFile,
+		`A YAML file with rules granting clients permission to use the requester API, and restricting the engines, `+
+			`publishers, networking modes and resources their jobs can use.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.InputCacheSize, "input-cache-size", OS.InputCacheSize,
+		`The amount of disk space used to cache inputs downloaded from IPFS, URLs, S3 and git, so that executions using the same `+
+			`inputs don't download them again (e.g. 20GB). Inputs are not cached if not set.`,
+	)
+	serveCmd.PersistentFlags().BoolVar(
+		&OS.AllowLocalGitRepositories, "allow-local-git-repositories", OS.AllowLocalGitRepositories,
+		`Allow git inputs to be cloned from the filesystem of the compute node, such as file:// URLs. `+
+			`This lets jobs read any repository on the node, and is only meant for tests.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.S3Endpoint, "s3-endpoint", OS.S3Endpoint,
+		`The endpoint of the S3-compatible service that S3 inputs are downloaded from, otherwise AWS S3 is used. `+
+			`Credentials are read from the AWS environment variables or shared config.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.S3Region, "s3-region", OS.S3Region,
+		`The region of the buckets that S3 inputs are downloaded from, otherwise the region of the AWS environment is used.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.S3PublisherBucket, "s3-publisher-bucket", OS.S3PublisherBucket,
+		`The bucket that jobs using the S3 publisher publish their results to, otherwise the node does not run such jobs.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.S3PublisherPrefix, "s3-publisher-prefix", OS.S3PublisherPrefix,
+		`The Go template of the prefix that results are published under in the S3 publisher bucket. `+
+			`It can reference {{.JobID}}, {{.ShardIndex}}, {{.ExecutionID}} and {{.NodeID}}. Defaults to `+s3publisher.DefaultPrefix,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.LocalPublisherRoot, "local-publisher-root", OS.LocalPublisherRoot,
+		`The directory, such as a shared NFS mount, that jobs using the local publisher publish their results to, `+
+			`otherwise the node does not run such jobs.`,
+	)
+	serveCmd.PersistentFlags().StringVar(
+		&OS.HTTPPublisherURL, "http-publisher-url", OS.HTTPPublisherURL,
+		`The Go template of the URL that jobs using the HTTP publisher upload a tar.gz archive of their results to `+
+			`with a PUT request, otherwise the node does not run such jobs. `+
+			`It can reference {{.JobID}}, {{.ShardIndex}}, {{.ExecutionID}} and {{.NodeID}}.`,
+	)
 	serveCmd.PersistentFlags().DurationVar(
 		&OS.LotusFilecoinStorageDuration, "lotus-storage-duration", OS.LotusFilecoinStorageDuration,
 		"Duration to store data in Lotus Filecoin for.",
@@ -296,6 +659,8 @@ func newServeCmd() *cobra.Command {
 	setupLibp2pCLIFlags(serveCmd, OS)
 	setupJobSelectionCLIFlags(serveCmd, OS)
 	setupCapacityManagerCLIFlags(serveCmd, OS)
+	setupJobStoreCLIFlags(serveCmd, OS)
+	setupExecutionStoreCLIFlags(serveCmd, OS)
 
 	return serveCmd
 }
@@ -354,9 +719,29 @@ func serve(cmd *cobra.Command, OS *ServeOptions) error {
 		return err
 	}
 
-	datastore := inmemory.NewJobStore()
+	datastore, err := getJobStore(OS)
+	if err != nil {
+		return fmt.Errorf("error creating job store: %s", err)
+	}
+
+	executionStore, err := getExecutionStore(OS)
 	if err != nil {
-		return fmt.Errorf("error creating in memory datastore: %s", err)
+		return fmt.Errorf("error creating execution store: %s", err)
+	}
+
+	computeConfig, err := getComputeConfig(OS)
+	if err != nil {
+		return err
+	}
+
+	requesterConfig, err := getRequesterConfig(OS)
+	if err != nil {
+		return err
+	}
+
+	inputCache, err := getInputCache(OS, cm)
+	if err != nil {
+		return fmt.Errorf("error creating input cache: %s", err)
 	}
 
 	// Create node config from cmd arguments
@@ -364,16 +749,28 @@ func serve(cmd *cobra.Command, OS *ServeOptions) error {
 		IPFSClient:           ipfsClient,
 		CleanupManager:       cm,
 		JobStore:             datastore,
+		ExecutionStore:       executionStore,
+		InputCache:           inputCache,
 		Host:                 libp2pHost,
 		FilecoinUnsealedPath: OS.FilecoinUnsealedPath,
 		EstuaryAPIKey:        OS.EstuaryAPIKey,
-		HostAddress:          OS.HostAddress,
-		APIPort:              apiPort,
-		ComputeConfig:        getComputeConfig(OS),
-		RequesterNodeConfig:  node.NewRequesterConfigWithDefaults(),
-		IsComputeNode:        isComputeNode,
-		IsRequesterNode:      isRequesterNode,
-		Labels:               OS.Labels,
+		S3Config: s3.Config{
+			Endpoint: OS.S3Endpoint,
+			Region:   OS.S3Region,
+		},
+		HostAddress:         OS.HostAddress,
+		APIPort:             apiPort,
+		ComputeConfig:       computeConfig,
+		RequesterNodeConfig: requesterConfig,
+		IsComputeNode:       isComputeNode,
+		IsRequesterNode:     isRequesterNode,
+		Labels:              OS.Labels,
+
+		AllowLocalGitRepositories: OS.AllowLocalGitRepositories,
+	}
+	nodeConfig.APIServerConfig.AuthPolicy, err = getAPIAuthPolicy(OS)
+	if err != nil {
+		return err
 	}
 
 	if OS.LotusFilecoinStorageDuration != time.Duration(0) &&
@@ -387,6 +784,26 @@ func serve(cmd *cobra.Command, OS *ServeOptions) error {
 		}
 	}
 
+	if OS.S3PublisherBucket != "" {
+		nodeConfig.S3PublisherConfig = &s3publisher.PublisherConfig{
+			Client: nodeConfig.S3Config,
+			Bucket: OS.S3PublisherBucket,
+			Prefix: OS.S3PublisherPrefix,
+		}
+	}
+
+	if OS.LocalPublisherRoot != "" {
+		nodeConfig.LocalPublisherConfig = &localpublisher.PublisherConfig{
+			Root: OS.LocalPublisherRoot,
+		}
+	}
+
+	if OS.HTTPPublisherURL != "" {
+		nodeConfig.HTTPPublisherConfig = &httppublisher.PublisherConfig{
+			URL: OS.HTTPPublisherURL,
+		}
+	}
+
 	// Create node
 	standardNode, err := node.NewStandardNode(ctx, nodeConfig)
 	if err != nil {
@@ -112,7 +112,11 @@ func shortID(outputWide bool, id string) string {
 }
 
 func GetAPIClient() *publicapi.RequesterAPIClient {
-	return publicapi.NewRequesterAPIClient(fmt.Sprintf("http://%s:%d", apiHost, apiPort))
+	client := publicapi.NewRequesterAPIClient(fmt.Sprintf("http://%s:%d", apiHost, apiPort))
+	if apiToken != "" {
+		client.DefaultHeaders["Authorization"] = "Bearer " + apiToken
+	}
+	return client
 }
 
 // ensureValidVersion checks that the server version is the same or less than the client version
@@ -195,6 +199,31 @@ func NewIPFSDownloadFlags(settings *model.DownloaderSettings) *pflag.FlagSet {
 		settings.OutputDir, "Directory to write the output to.")
 	flags.StringVar(&settings.IPFSSwarmAddrs, "ipfs-swarm-addrs",
 		settings.IPFSSwarmAddrs, "Comma-separated list of IPFS nodes to connect to.")
+	flags.StringVar(&settings.S3Endpoint, "s3-endpoint",
+		settings.S3Endpoint, "Endpoint of the S3-compatible service to download results published to S3 from.")
+	flags.StringVar(&settings.S3Region, "s3-region",
+		settings.S3Region, "Region of the buckets to download results published to S3 from.")
+	flags.StringVar(&settings.HTTPUsername, "http-username",
+		settings.HTTPUsername, "Username to download results published to a HTTP server with, defaults to $BACALHAU_HTTP_USERNAME.")
+	// the password from the environment isn't used as the default value of the flag, so it isn't printed in the usage
+	httpPassword := settings.HTTPPassword
+	flags.StringVar(&settings.HTTPPassword, "http-password",
+		"", "Password to download results published to a HTTP server with, defaults to $BACALHAU_HTTP_PASSWORD.")
+	settings.HTTPPassword = httpPassword
+	flags.StringVar(&settings.LocalPublisherRoot, "local-publisher-root",
+		settings.LocalPublisherRoot, "Directory the results published by local publishers are mounted at, such as a shared NFS mount.")
+	flags.IntVar(&settings.Parallelism, "download-parallelism",
+		settings.Parallelism, "Number of shard results to download concurrently.")
+	flags.IntSliceVar(&settings.ShardIndexes, "download-shards",
+		settings.ShardIndexes, "Comma-separated list of the indexes of the shards to download the results of, otherwise all shards are downloaded.")
+	flags.StringSliceVar(&settings.OutputVolumeNames, "download-volumes",
+		settings.OutputVolumeNames, "Comma-separated list of the names of the output volumes to download, otherwise all output volumes are downloaded.")
+	flags.BoolVar(&settings.Merge, "merge",
+		settings.Merge, "Merge the results of all shards into a single tree, with the logs of each shard under a header, "+
+			"files produced by more than one shard renamed, and a summary of the exit code of each shard.")
+	flags.BoolVar(&settings.AllowMissingManifest, "allow-missing-manifest",
+		settings.AllowMissingManifest, "Download results that have no signed manifest, such as results published by older nodes. "+
+			"Their content can't be verified.")
 	return flags
 }
 
@@ -269,6 +298,21 @@ func NewRunTimeSettingsFlags(settings *RunTimeSettings) *pflag.FlagSet {
 	return flags
 }
 
+func NewVerificationRulesFlags(rules *model.VerificationRules) *pflag.FlagSet {
+	flags := pflag.NewFlagSet("Verification rules", pflag.ContinueOnError)
+	flags.StringSliceVar(&rules.IgnorePaths, "verify-ignore", rules.IgnorePaths,
+		`Globs of result paths the tolerant verifier does not compare (e.g. stderr, '*.log', outputs/timestamp.txt)`)
+	flags.StringSliceVar(&rules.OutputVolumes, "verify-volumes", rules.OutputVolumes,
+		`Names of the only output volumes the tolerant verifier compares (all output volumes if not set)`)
+	flags.BoolVar(&rules.NormalizeLineEndings, "verify-normalize-line-endings", rules.NormalizeLineEndings,
+		`Make the tolerant verifier ignore the difference between CRLF and LF line endings`)
+	flags.Float64Var(&rules.AbsoluteTolerance, "verify-tolerance", rules.AbsoluteTolerance,
+		`How much numbers in CSV and JSON results can differ for the tolerant verifier (e.g. 0.001)`)
+	flags.Float64Var(&rules.RelativeTolerance, "verify-relative-tolerance", rules.RelativeTolerance,
+		`How much numbers in CSV and JSON results can differ for the tolerant verifier, relative to their value (e.g. 0.01 for 1%)`)
+	return flags
+}
+
 func getCommandLineExecutable() string {
 	return os.Args[0]
 }
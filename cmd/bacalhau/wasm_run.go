@@ -78,6 +78,8 @@ func newRunWasmCmd() *cobra.Command {
 	downloadFlags := NewIPFSDownloadFlags(downloadSettings)
 	runWasmCommand.Flags().AddFlagSet(downloadFlags)
 
+	runWasmCommand.Flags().AddFlagSet(NewVerificationRulesFlags(&wasmJob.Spec.VerificationRules))
+
 	runWasmCommand.PersistentFlags().StringVarP(
 		&nodeSelector, "selector", "s", nodeSelector,
 		`Selector (label query) to filter nodes on which this job can be executed, supports '=', '==', and '!='.(e.g. -s key1=value1,key2=value2). Matching objects must satisfy all of the specified label constraints.`, //nolint:lll // Documentation, ok if long.
@@ -91,6 +93,10 @@ func newRunWasmCmd() *cobra.Command {
 		PublisherFlag(&wasmJob.Spec.Publisher), "publisher",
 		`What publisher engine to use to publish the job results`,
 	)
+	runWasmCommand.PersistentFlags().Var(
+		ResultPackagingFlag(&wasmJob.Spec.ResultPackaging), "result-packaging",
+		`How to package the results before they are published (none, tar.gz, tar.zst or zip)`,
+	)
 	runWasmCommand.PersistentFlags().IntVarP(
 		&wasmJob.Spec.Deal.Concurrency, "concurrency", "c", wasmJob.Spec.Deal.Concurrency,
 		`How many nodes should run the job`,
@@ -99,6 +105,10 @@ func newRunWasmCmd() *cobra.Command {
 		&wasmJob.Spec.Deal.Confidence, "confidence", wasmJob.Spec.Deal.Confidence,
 		`The minimum number of nodes that must agree on a verification result`,
 	)
+	runWasmCommand.PersistentFlags().Float64Var(
+		&wasmJob.Spec.Deal.SpotCheckFraction, "spot-check-fraction", wasmJob.Spec.Deal.SpotCheckFraction,
+		`The fraction of shards to run on an extra node to check the results (used with the spotcheck verifier)`,
+	)
 	runWasmCommand.PersistentFlags().IntVar(
 		&wasmJob.Spec.Deal.MinBids, "min-bids", wasmJob.Spec.Deal.MinBids,
 		`Minimum number of bids that must be received before concurrency-many bids will be accepted (at random)`,
@@ -107,6 +117,18 @@ func newRunWasmCmd() *cobra.Command {
 		&wasmJob.Spec.Timeout, "timeout", wasmJob.Spec.Timeout,
 		`Job execution timeout in seconds (e.g. 300 for 5 minutes and 0.1 for 100ms)`,
 	)
+	runWasmCommand.PersistentFlags().IntVar(
+		&wasmJob.Spec.Priority, "priority", wasmJob.Spec.Priority,
+		`Job priority. Compute nodes ordering their queue by priority run jobs with higher priority first`,
+	)
+	runWasmCommand.PersistentFlags().StringVar(
+		&wasmJob.Spec.OutputLimits.Volume, "output-limit-volume", wasmJob.Spec.OutputLimits.Volume,
+		`Size limit of each output volume (e.g. 500Mb, 2Gb). The job fails if an output volume grows over it.`,
+	)
+	runWasmCommand.PersistentFlags().StringVar(
+		&wasmJob.Spec.OutputLimits.Total, "output-limit-total", wasmJob.Spec.OutputLimits.Total,
+		`Size limit of all the output volumes together (e.g. 500Mb, 2Gb). The job fails if the outputs grow over it.`,
+	)
 	runWasmCommand.PersistentFlags().StringVar(
 		&wasmJob.Spec.Wasm.EntryPoint, "entry-point", wasmJob.Spec.Wasm.EntryPoint,
 		`The name of the WASM function in the entry module to call. This should be a zero-parameter zero-result function that
@@ -118,6 +140,12 @@ func newRunWasmCmd() *cobra.Command {
 		mounts 'bar.tar.gz' at '/inputs/bar.tar.gz'). URL accept any valid URL supported by the 'wget' command,
 		and supports both HTTP and HTTPS.`,
 	)
+	runWasmCommand.PersistentFlags().Var(
+		NewS3StorageSpecArrayFlag(&wasmJob.Spec.Inputs), "input-s3", s3InputUsage,
+	)
+	runWasmCommand.PersistentFlags().Var(
+		NewGitStorageSpecArrayFlag(&wasmJob.Spec.Inputs), "input-git", gitInputUsage,
+	)
 	runWasmCommand.PersistentFlags().VarP(
 		NewIPFSStorageSpecArrayFlag(&wasmJob.Spec.Inputs), "input-volumes", "v",
 		`CID:path of the input data volumes, if you need to set the path of the mounted data.`,
@@ -198,10 +226,11 @@ func runWasm(
 		wasmJob.Spec.Wasm.EntryModule = inlineData
 	}
 
-	// We can only use a Deterministic verifier if we have multiple nodes running the job
-	// If the user has selected a Deterministic verifier (or we are using it by default)
+	// We can only use a Deterministic or Tolerant verifier if we have multiple nodes running the job
+	// If the user has selected one of them (or we are using the Deterministic verifier by default)
 	// then switch back to a Noop Verifier if the concurrency is too low.
-	if wasmJob.Spec.Deal.Concurrency <= 1 && wasmJob.Spec.Verifier == model.VerifierDeterministic {
+	if wasmJob.Spec.Deal.Concurrency <= 1 &&
+		(wasmJob.Spec.Verifier == model.VerifierDeterministic || wasmJob.Spec.Verifier == model.VerifierTolerant) {
 		wasmJob.Spec.Verifier = model.VerifierNoop
 	}
 
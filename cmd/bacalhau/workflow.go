@@ -0,0 +1,243 @@
+package bacalhau
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/util/templates"
+	"github.com/imdario/mergo"
+	"github.com/spf13/cobra"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	//nolint:lll // Documentation
+	workflowSubmitLong = templates.LongDesc(i18n.T(`
+		Submit a workflow of jobs with dependencies between them, using a json or yaml file.
+
+		A job is only submitted to the network once all the jobs it depends on have completed. Inputs of a job can
+		reference the published result of a shard of an upstream job, which is resolved once the upstream job completes.
+		If a job fails or is canceled, the jobs that depend on it are failed or canceled as well.
+`))
+
+	//nolint:lll // Documentation
+	workflowSubmitExample = templates.Examples(i18n.T(`
+		# Submit a workflow where the "process" job reads the result of the first shard of the "fetch" job
+		cat > workflow.yaml <<EOF
+		Jobs:
+		  - Name: fetch
+		    Spec:
+		      Engine: Docker
+		      Docker:
+		        Image: ubuntu
+		        Entrypoint: ["bash", "-c", "echo hello > /outputs/hello.txt"]
+		      Outputs:
+		        - Name: outputs
+		          path: /outputs
+		  - Name: process
+		    Spec:
+		      Engine: Docker
+		      Docker:
+		        Image: ubuntu
+		        Entrypoint: ["cat", "/inputs/outputs/hello.txt"]
+		      Inputs:
+		        - path: /inputs
+		          Upstream:
+		            Job: fetch
+		            ShardIndex: 0
+		EOF
+		bacalhau workflow submit workflow.yaml
+
+		# Describe the state of a workflow and its jobs
+		bacalhau workflow describe 2b6b2b4e-5b6b-4c1a-9a6c-6e2f6c7a3b1d
+
+		# Cancel a workflow and all its jobs that did not complete yet
+		bacalhau workflow cancel 2b6b2b4e-5b6b-4c1a-9a6c-6e2f6c7a3b1d
+`))
+)
+
+type WorkflowDescribeOptions struct {
+	JSON bool // Print description as JSON
+}
+
+func NewWorkflowDescribeOptions() *WorkflowDescribeOptions {
+	return &WorkflowDescribeOptions{
+		JSON: false,
+	}
+}
+
+func newWorkflowCmd() *cobra.Command {
+	workflowCmd := &cobra.Command{
+		Use:               "workflow",
+		Short:             "Submit and manage workflows of jobs with dependencies between them",
+		PersistentPreRunE: checkVersion,
+	}
+
+	workflowCmd.AddCommand(newWorkflowSubmitCmd())
+	workflowCmd.AddCommand(newWorkflowDescribeCmd())
+	workflowCmd.AddCommand(newWorkflowCancelCmd())
+	return workflowCmd
+}
+
+func newWorkflowSubmitCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "submit [file]",
+		Short:   "Submit a workflow using a json or yaml file",
+		Long:    workflowSubmitLong,
+		Example: workflowSubmitExample,
+		Args:    cobra.MaximumNArgs(1),
+		PreRun:  applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return workflowSubmit(cmd, cmdArgs)
+		},
+	}
+}
+
+func newWorkflowDescribeCmd() *cobra.Command {
+	OD := NewWorkflowDescribeOptions()
+
+	describeCmd := &cobra.Command{
+		Use:    "describe [id]",
+		Short:  "Describe the state of a workflow and its jobs",
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return workflowDescribe(cmd, cmdArgs, OD)
+		},
+	}
+
+	describeCmd.PersistentFlags().BoolVar(
+		&OD.JSON, "json", OD.JSON,
+		`Output description as JSON (if not included will be outputted as YAML by default)`,
+	)
+	return describeCmd
+}
+
+func newWorkflowCancelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:    "cancel [id]",
+		Short:  "Cancel a workflow and all its jobs that did not complete yet",
+		Args:   cobra.ExactArgs(1),
+		PreRun: applyPorcelainLogLevel,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			return workflowCancel(cmd, cmdArgs)
+		},
+	}
+}
+
+func workflowSubmit(cmd *cobra.Command, cmdArgs []string) error {
+	ctx := cmd.Context()
+
+	var byteResult []byte
+	var err error
+	if len(cmdArgs) == 0 {
+		byteResult, err = ReadFromStdinIfAvailable(cmd, cmdArgs)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Unknown error reading from file or stdin: %s\n", err), 1)
+			return err
+		}
+	} else {
+		var fileContent *os.File
+		fileContent, err = os.Open(cmdArgs[0])
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error opening file: %s", err), 1)
+			return err
+		}
+		defer fileContent.Close()
+
+		byteResult, err = io.ReadAll(fileContent)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Error reading file: %s", err), 1)
+			return err
+		}
+	}
+
+	// the yaml parser supports both yaml & json
+	var spec model.WorkflowSpec
+	if err = model.YAMLUnmarshalWithMax(byteResult, &spec); err != nil {
+		Fatal(cmd, fmt.Sprintf("Error parsing workflow: %s", err), 1)
+		return err
+	}
+	if len(spec.Jobs) == 0 {
+		Fatal(cmd, "The workflow has no jobs", 1)
+		return nil
+	}
+
+	// fill the fields that were not set in the job specs with the same defaults used when creating a single job
+	defaults, err := model.NewJobWithSaneProductionDefaults()
+	if err != nil {
+		return err
+	}
+	for i := range spec.Jobs {
+		if err = mergo.Merge(&spec.Jobs[i].Spec, defaults.Spec); err != nil {
+			return err
+		}
+	}
+
+	state, err := GetAPIClient().SubmitWorkflow(ctx, &spec)
+	if err != nil {
+		if er, ok := err.(*bacerrors.ErrorResponse); ok {
+			Fatal(cmd, er.Message, 1)
+			return nil
+		}
+		Fatal(cmd, fmt.Sprintf("Unknown error trying to submit workflow: %+v", err), 1)
+		return nil
+	}
+
+	cmd.Printf("Workflow ID: %s\n", state.WorkflowID)
+	return nil
+}
+
+func workflowDescribe(cmd *cobra.Command, cmdArgs []string, OD *WorkflowDescribeOptions) error {
+	ctx := cmd.Context()
+
+	workflowID := cmdArgs[0]
+	state, err := GetAPIClient().GetWorkflowState(ctx, workflowID)
+	if err != nil {
+		if er, ok := err.(*bacerrors.ErrorResponse); ok {
+			Fatal(cmd, er.Message, 1)
+			return nil
+		}
+		Fatal(cmd, fmt.Sprintf("Unknown error trying to get workflow (ID: %s): %+v", workflowID, err), 1)
+		return nil
+	}
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		Fatal(cmd, fmt.Sprintf("Failure marshaling workflow description '%s': %s\n", workflowID, err), 1)
+	}
+
+	if !OD.JSON {
+		y, err := yaml.JSONToYAML(b)
+		if err != nil {
+			Fatal(cmd, fmt.Sprintf("Failure converting workflow description '%s' to YAML: %s\n", workflowID, err), 1)
+		}
+		cmd.Print(string(y))
+	} else {
+		cmd.Print(string(b))
+	}
+	return nil
+}
+
+func workflowCancel(cmd *cobra.Command, cmdArgs []string) error {
+	ctx := cmd.Context()
+
+	workflowID := cmdArgs[0]
+	state, err := GetAPIClient().CancelWorkflow(ctx, workflowID, "Canceled at user request")
+	if err != nil {
+		if er, ok := err.(*bacerrors.ErrorResponse); ok {
+			Fatal(cmd, er.Message, 1)
+			return nil
+		}
+		Fatal(cmd, fmt.Sprintf("Unknown error trying to cancel workflow (ID: %s): %+v", workflowID, err), 1)
+		return nil
+	}
+
+	cmd.Printf("Workflow successfully canceled. Workflow ID: %s\n", state.WorkflowID)
+	return nil
+}
@@ -0,0 +1,57 @@
+package bacerrors
+
+import (
+	"fmt"
+)
+
+type ClientLimitExceeded GenericError
+
+func NewClientLimitExceeded(clientID string, limit string, usage, max interface{}) *ClientLimitExceeded {
+	var e ClientLimitExceeded
+	e.Code = ErrorCodeClientLimitExceeded
+	e.Message = fmt.Sprintf(ErrorMessageClientLimitExceeded, clientID, limit, usage, max)
+	e.Details = map[string]interface{}{
+		"clientid": clientID,
+		"limit":    limit,
+		"usage":    usage,
+		"max":      max,
+	}
+	e.SetError(fmt.Errorf("%s", e.Message))
+	return &e
+}
+
+func (e *ClientLimitExceeded) GetMessage() string {
+	return e.Message
+}
+func (e *ClientLimitExceeded) SetMessage(s string) {
+	e.Message = s
+}
+
+func (e *ClientLimitExceeded) Error() string {
+	return e.GetError().Error()
+}
+func (e *ClientLimitExceeded) GetError() error {
+	return e.Err
+}
+func (e *ClientLimitExceeded) SetError(err error) {
+	e.Err = err
+}
+
+func (e *ClientLimitExceeded) GetCode() string {
+	return ErrorCodeClientLimitExceeded
+}
+func (e *ClientLimitExceeded) SetCode(string) {
+	e.Code = ErrorCodeClientLimitExceeded
+}
+
+func (e *ClientLimitExceeded) GetDetails() map[string]interface{} {
+	return e.Details
+}
+
+const (
+	ErrorCodeClientLimitExceeded = "error-client-limit-exceeded"
+
+	ErrorMessageClientLimitExceeded = "Client limit exceeded. Client %s would reach %s of %v, over its limit of %v"
+)
+
+var _ BacalhauErrorInterface = (*ClientLimitExceeded)(nil)
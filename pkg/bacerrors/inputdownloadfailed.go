@@ -0,0 +1,56 @@
+package bacerrors
+
+import (
+	"fmt"
+)
+
+type InputDownloadFailed GenericError
+
+// NewInputDownloadFailed returns the error of an input of a job that could not be downloaded, where the input is
+// the name or path of the input.
+func NewInputDownloadFailed(input string, err error) *InputDownloadFailed {
+	var e InputDownloadFailed
+	e.Code = ErrorCodeInputDownloadFailed
+	e.Message = fmt.Sprintf(ErrorMessageInputDownloadFailed, input, err)
+	e.Details = map[string]interface{}{
+		"input": input,
+	}
+	e.SetError(fmt.Errorf("%s", e.Message))
+	return &e
+}
+
+func (e *InputDownloadFailed) GetMessage() string {
+	return e.Message
+}
+func (e *InputDownloadFailed) SetMessage(s string) {
+	e.Message = s
+}
+
+func (e *InputDownloadFailed) Error() string {
+	return e.GetError().Error()
+}
+func (e *InputDownloadFailed) GetError() error {
+	return e.Err
+}
+func (e *InputDownloadFailed) SetError(err error) {
+	e.Err = err
+}
+
+func (e *InputDownloadFailed) GetCode() string {
+	return ErrorCodeInputDownloadFailed
+}
+func (e *InputDownloadFailed) SetCode(string) {
+	e.Code = ErrorCodeInputDownloadFailed
+}
+
+func (e *InputDownloadFailed) GetDetails() map[string]interface{} {
+	return e.Details
+}
+
+const (
+	ErrorCodeInputDownloadFailed = "error-input-download-failed"
+
+	ErrorMessageInputDownloadFailed = "Input download failed. Input: %s: %s"
+)
+
+var _ BacalhauErrorInterface = (*InputDownloadFailed)(nil)
@@ -0,0 +1,72 @@
+package bacerrors
+
+import (
+	"fmt"
+
+	"github.com/c2h5oh/datasize"
+)
+
+type OutputLimitExceeded GenericError
+
+// NewOutputLimitExceeded returns the error of results that grew over their size limit, where the volume is the
+// name of the output volume that exceeded its limit, or empty if the total size of the results exceeded it.
+func NewOutputLimitExceeded(volume string, size, limit uint64) *OutputLimitExceeded {
+	var e OutputLimitExceeded
+	e.Code = ErrorCodeOutputLimitExceeded
+	outputs := "Results"
+	if volume != "" {
+		outputs = fmt.Sprintf("Output volume %s", volume)
+	}
+	e.Message = fmt.Sprintf(ErrorMessageOutputLimitExceeded,
+		outputs, datasize.ByteSize(size).HumanReadable(), datasize.ByteSize(limit).HumanReadable())
+	e.Details = map[string]interface{}{
+		"volume": volume,
+		"size":   size,
+		"limit":  limit,
+	}
+	e.SetError(fmt.Errorf("%s", e.Message))
+	return &e
+}
+
+func (e *OutputLimitExceeded) GetMessage() string {
+	return e.Message
+}
+func (e *OutputLimitExceeded) SetMessage(s string) {
+	e.Message = s
+}
+
+func (e *OutputLimitExceeded) Error() string {
+	return e.GetError().Error()
+}
+func (e *OutputLimitExceeded) GetError() error {
+	return e.Err
+}
+func (e *OutputLimitExceeded) SetError(err error) {
+	e.Err = err
+}
+
+func (e *OutputLimitExceeded) GetCode() string {
+	return ErrorCodeOutputLimitExceeded
+}
+func (e *OutputLimitExceeded) SetCode(string) {
+	e.Code = ErrorCodeOutputLimitExceeded
+}
+
+func (e *OutputLimitExceeded) GetDetails() map[string]interface{} {
+	return e.Details
+}
+
+func (e *OutputLimitExceeded) GetVolume() string {
+	if volume, ok := e.Details["volume"]; ok {
+		return volume.(string)
+	}
+	return ""
+}
+
+const (
+	ErrorCodeOutputLimitExceeded = "error-output-limit-exceeded"
+
+	ErrorMessageOutputLimitExceeded = "Output limit exceeded. %s grew to %s, over the limit of %s"
+)
+
+var _ BacalhauErrorInterface = (*OutputLimitExceeded)(nil)
@@ -0,0 +1,59 @@
+package bacerrors
+
+import (
+	"fmt"
+
+	"github.com/c2h5oh/datasize"
+)
+
+type ResultQuotaExceeded GenericError
+
+func NewResultQuotaExceeded(clientID string, usage, quota uint64) *ResultQuotaExceeded {
+	var e ResultQuotaExceeded
+	e.Code = ErrorCodeResultQuotaExceeded
+	e.Message = fmt.Sprintf(ErrorMessageResultQuotaExceeded,
+		clientID, datasize.ByteSize(usage).HumanReadable(), datasize.ByteSize(quota).HumanReadable())
+	e.Details = map[string]interface{}{
+		"clientid": clientID,
+		"usage":    usage,
+		"quota":    quota,
+	}
+	e.SetError(fmt.Errorf("%s", e.Message))
+	return &e
+}
+
+func (e *ResultQuotaExceeded) GetMessage() string {
+	return e.Message
+}
+func (e *ResultQuotaExceeded) SetMessage(s string) {
+	e.Message = s
+}
+
+func (e *ResultQuotaExceeded) Error() string {
+	return e.GetError().Error()
+}
+func (e *ResultQuotaExceeded) GetError() error {
+	return e.Err
+}
+func (e *ResultQuotaExceeded) SetError(err error) {
+	e.Err = err
+}
+
+func (e *ResultQuotaExceeded) GetCode() string {
+	return ErrorCodeResultQuotaExceeded
+}
+func (e *ResultQuotaExceeded) SetCode(string) {
+	e.Code = ErrorCodeResultQuotaExceeded
+}
+
+func (e *ResultQuotaExceeded) GetDetails() map[string]interface{} {
+	return e.Details
+}
+
+const (
+	ErrorCodeResultQuotaExceeded = "error-result-quota-exceeded"
+
+	ErrorMessageResultQuotaExceeded = "Result quota exceeded. Client %s stores %s of results, over its quota of %s"
+)
+
+var _ BacalhauErrorInterface = (*ResultQuotaExceeded)(nil)
@@ -4,21 +4,26 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/filecoin-project/bacalhau/pkg/compute/capacity"
 	"github.com/filecoin-project/bacalhau/pkg/executor"
 	"github.com/filecoin-project/bacalhau/pkg/model"
 )
 
 type DiskUsageCalculatorParams struct {
 	Executors executor.ExecutorProvider
+	// OutputLimits are the output limits of the node, which apply on top of the output limits of the job
+	OutputLimits model.OutputLimits
 }
 
 type DiskUsageCalculator struct {
-	executors executor.ExecutorProvider
+	executors    executor.ExecutorProvider
+	outputLimits model.OutputLimits
 }
 
 func NewDiskUsageCalculator(params DiskUsageCalculatorParams) *DiskUsageCalculator {
 	return &DiskUsageCalculator{
-		executors: params.Executors,
+		executors:    params.Executors,
+		outputLimits: params.OutputLimits,
 	}
 }
 
@@ -47,8 +52,10 @@ func (c *DiskUsageCalculator) Calculate(
 	if totalShards == 0 {
 		totalShards = 1
 	}
-	// update the job requirements disk space with what we calculated
-	requirements.Disk = totalDiskRequirements / uint64(totalShards)
+	// update the job requirements disk space with what we calculated, and reserve space for the results if
+	// their size is limited
+	outputLimits := capacity.ParseOutputLimitsConfig(job.Spec.OutputLimits).Min(c.outputLimits)
+	requirements.Disk = totalDiskRequirements/uint64(totalShards) + outputLimits.Total
 
 	return requirements, nil
 }
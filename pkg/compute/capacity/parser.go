@@ -1,6 +1,7 @@
 package capacity
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -17,6 +18,58 @@ func ParseResourceUsageConfig(usage model.ResourceUsageConfig) model.ResourceUsa
 		GPU:    ConvertGPUString(usage.GPU),
 	}
 }
+
+func ParseOutputLimitsConfig(limits model.OutputLimitsConfig) model.OutputLimits {
+	return model.OutputLimits{
+		Volume: ConvertBytesString(limits.Volume),
+		Total:  ConvertBytesString(limits.Total),
+	}
+}
+
+// ValidateOutputLimitsConfig returns an error if a limit is set but is not a valid size.
+func ValidateOutputLimitsConfig(limits model.OutputLimitsConfig) error {
+	if _, err := convertBytesStringWithError(limits.Volume); err != nil {
+		return fmt.Errorf("invalid output volume limit %q: %w", limits.Volume, err)
+	}
+	if _, err := convertBytesStringWithError(limits.Total); err != nil {
+		return fmt.Errorf("invalid total output limit %q: %w", limits.Total, err)
+	}
+	return nil
+}
+
+func ParseClientLimitsConfig(limits model.ClientLimitsConfig) model.ClientLimits {
+	return model.ClientLimits{
+		SubmissionsPerMinute: limits.SubmissionsPerMinute,
+		MaxInProgressJobs:    limits.MaxInProgressJobs,
+		MaxResources:         ParseResourceUsageConfig(limits.MaxResources),
+	}
+}
+
+// ValidateClientLimitsConfig returns an error if a limit is negative, or is a resource that is set but is not valid.
+func ValidateClientLimitsConfig(limits model.ClientLimitsConfig) error {
+	if limits.SubmissionsPerMinute < 0 {
+		return fmt.Errorf("invalid submissions per minute limit %d", limits.SubmissionsPerMinute)
+	}
+	if limits.MaxInProgressJobs < 0 {
+		return fmt.Errorf("invalid in progress jobs limit %d", limits.MaxInProgressJobs)
+	}
+	if _, err := convertCPUStringWithError(limits.MaxResources.CPU); err != nil {
+		return fmt.Errorf("invalid CPU limit %q: %w", limits.MaxResources.CPU, err)
+	}
+	if _, err := convertBytesStringWithError(limits.MaxResources.Memory); err != nil {
+		return fmt.Errorf("invalid memory limit %q: %w", limits.MaxResources.Memory, err)
+	}
+	if _, err := convertBytesStringWithError(limits.MaxResources.Disk); err != nil {
+		return fmt.Errorf("invalid disk limit %q: %w", limits.MaxResources.Disk, err)
+	}
+	if limits.MaxResources.GPU != "" {
+		if _, err := strconv.ParseUint(limits.MaxResources.GPU, 10, 64); err != nil { //nolint:gomnd
+			return fmt.Errorf("invalid GPU limit %q: %w", limits.MaxResources.GPU, err)
+		}
+	}
+	return nil
+}
+
 func ConvertCPUString(val string) float64 {
 	ret, err := convertCPUStringWithError(val)
 	if err != nil {
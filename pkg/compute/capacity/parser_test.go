@@ -33,3 +33,28 @@ func TestResourceUsageConfigParser(t *testing.T) {
 		require.Equal(t, tc.expectedData, data)
 	}
 }
+
+func TestOutputLimitsConfigParser(t *testing.T) {
+	limits := ParseOutputLimitsConfig(model.OutputLimitsConfig{Volume: "10Mi", Total: "1GB"})
+	require.Equal(t, model.OutputLimits{Volume: 10 * 1024 * 1024, Total: 1024 * 1024 * 1024}, limits)
+
+	require.NoError(t, ValidateOutputLimitsConfig(model.OutputLimitsConfig{Volume: "10Mi"}))
+	require.Error(t, ValidateOutputLimitsConfig(model.OutputLimitsConfig{Total: "lots"}))
+}
+
+func TestClientLimitsConfigParser(t *testing.T) {
+	config := model.ClientLimitsConfig{
+		SubmissionsPerMinute: 10,
+		MaxInProgressJobs:    5,
+		MaxResources:         model.ResourceUsageConfig{CPU: "2", Memory: "1GB"},
+	}
+	require.NoError(t, ValidateClientLimitsConfig(config))
+	require.Equal(t, model.ClientLimits{
+		SubmissionsPerMinute: 10,
+		MaxInProgressJobs:    5,
+		MaxResources:         model.ResourceUsageData{CPU: 2, Memory: 1024 * 1024 * 1024},
+	}, ParseClientLimitsConfig(config))
+
+	require.Error(t, ValidateClientLimitsConfig(model.ClientLimitsConfig{MaxInProgressJobs: -1}))
+	require.Error(t, ValidateClientLimitsConfig(model.ClientLimitsConfig{MaxResources: model.ResourceUsageConfig{CPU: "lots"}}))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/filecoin-project/bacalhau/pkg/compute/bidstrategy"
 	"github.com/filecoin-project/bacalhau/pkg/compute/capacity"
 	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	"github.com/filecoin-project/bacalhau/pkg/executor"
 	"github.com/filecoin-project/bacalhau/pkg/model"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 	"github.com/google/uuid"
@@ -20,6 +21,8 @@ type BaseEndpointParams struct {
 	UsageCalculator capacity.UsageCalculator
 	BidStrategy     bidstrategy.BidStrategy
 	Executor        Executor
+	// Executors are used to read the logs of running executions
+	Executors executor.ExecutorProvider
 }
 
 // Base implementation of Endpoint
@@ -29,6 +32,7 @@ type BaseEndpoint struct {
 	usageCalculator capacity.UsageCalculator
 	bidStrategy     bidstrategy.BidStrategy
 	executor        Executor
+	executors       executor.ExecutorProvider
 }
 
 func NewBaseEndpoint(params BaseEndpointParams) BaseEndpoint {
@@ -38,6 +42,7 @@ func NewBaseEndpoint(params BaseEndpointParams) BaseEndpoint {
 		usageCalculator: params.UsageCalculator,
 		bidStrategy:     params.BidStrategy,
 		executor:        params.Executor,
+		executors:       params.Executors,
 	}
 }
 
@@ -273,5 +278,74 @@ func (s BaseEndpoint) CancelExecution(ctx context.Context, request CancelExecuti
 	}, nil
 }
 
+func (s BaseEndpoint) ExecutionState(ctx context.Context, request ExecutionStateRequest) (ExecutionStateResponse, error) {
+	log.Ctx(ctx).Debug().Msgf("asked for state of execution %s", request.ExecutionID)
+	execution, err := s.executionStore.GetExecution(ctx, request.ExecutionID)
+	if err != nil {
+		if _, notFound := err.(store.ErrExecutionNotFound); notFound {
+			return ExecutionStateResponse{
+				ExecutionMetadata: ExecutionMetadata{ExecutionID: request.ExecutionID},
+				Unknown:           true,
+			}, nil
+		}
+		return ExecutionStateResponse{}, err
+	}
+	if request.RedirectCallbacks && !execution.State.IsTerminal() && execution.RequesterNodeID != request.SourcePeerID {
+		err = s.executionStore.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+			ExecutionID:        execution.ID,
+			ExpectedState:      execution.State,
+			ExpectedVersion:    execution.Version,
+			NewState:           execution.State,
+			NewRequesterNodeID: request.SourcePeerID,
+			Comment:            fmt.Sprintf("callbacks redirected to requester %s", request.SourcePeerID),
+		})
+		if err != nil {
+			return ExecutionStateResponse{}, err
+		}
+	}
+	return ExecutionStateResponse{
+		ExecutionMetadata: NewExecutionMetadata(execution),
+		State:             execution.State,
+		PublishedResult:   execution.PublishedResult,
+		ResultSize:        execution.ResultSize,
+	}, nil
+}
+
+func (s BaseEndpoint) ExecutionLogs(ctx context.Context, request ExecutionLogsRequest) (ExecutionLogsResponse, error) {
+	log.Ctx(ctx).Trace().Msgf("asked for logs of execution %s since %d", request.ExecutionID, request.Offset)
+	execution, err := s.executionStore.GetExecution(ctx, request.ExecutionID)
+	if err != nil {
+		return ExecutionLogsResponse{}, err
+	}
+	response := ExecutionLogsResponse{
+		ExecutionMetadata: NewExecutionMetadata(execution),
+		NextOffset:        request.Offset,
+	}
+
+	// the execution did not start running yet
+	if execution.State == store.ExecutionStateCreated || execution.State == store.ExecutionStateBidAccepted {
+		response.Available = true
+		return response, nil
+	}
+
+	jobExecutor, err := s.executors.Get(ctx, execution.Shard.Job.Spec.Engine)
+	if err != nil {
+		return ExecutionLogsResponse{}, err
+	}
+	streamer, ok := jobExecutor.(executor.LogStreamer)
+	if !ok {
+		return ExecutionLogsResponse{}, fmt.Errorf("engine %s does not support streaming logs", execution.Shard.Job.Spec.Engine)
+	}
+	logs, found, err := streamer.GetShardLogs(ctx, execution.Shard, request.Offset)
+	if err != nil || !found {
+		return response, err
+	}
+	response.Logs = logs.Logs
+	response.NextOffset = logs.NextOffset
+	response.Finished = logs.Finished
+	response.Available = true
+	return response, nil
+}
+
 // Compile-time interface check:
 var _ Endpoint = (*BaseEndpoint)(nil)
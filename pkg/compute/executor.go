@@ -2,13 +2,20 @@ package compute
 
 import (
 	"context"
+	"errors"
+	"os"
 
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/compute/capacity"
 	"github.com/filecoin-project/bacalhau/pkg/compute/store"
 	"github.com/filecoin-project/bacalhau/pkg/executor"
+	"github.com/filecoin-project/bacalhau/pkg/manifest"
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/packaging"
 	"github.com/filecoin-project/bacalhau/pkg/publisher"
 	"github.com/filecoin-project/bacalhau/pkg/util/generic"
 	"github.com/filecoin-project/bacalhau/pkg/verifier"
+	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/rs/zerolog/log"
 )
 
@@ -20,6 +27,10 @@ type BaseExecutorParams struct {
 	Verifiers       verifier.VerifierProvider
 	Publishers      publisher.PublisherProvider
 	SimulatorConfig model.SimulatorConfigCompute
+	// SigningKey signs the manifests written alongside published results. No manifest is written if nil.
+	SigningKey crypto.PrivKey
+	// OutputLimits limit the size of the results of every execution, on top of the output limits of its job
+	OutputLimits model.OutputLimits
 }
 
 // BaseExecutor is the base implementation for backend service.
@@ -28,11 +39,13 @@ type BaseExecutor struct {
 	ID              string
 	callback        Callback
 	store           store.ExecutionStore
-	cancellers      generic.SyncMap[store.Execution, context.CancelFunc]
+	cancellers      generic.SyncMap[string, context.CancelFunc]
 	executors       executor.ExecutorProvider
 	verifiers       verifier.VerifierProvider
 	publishers      publisher.PublisherProvider
 	simulatorConfig model.SimulatorConfigCompute
+	signingKey      crypto.PrivKey
+	outputLimits    model.OutputLimits
 }
 
 func NewBaseExecutor(params BaseExecutorParams) *BaseExecutor {
@@ -44,9 +57,17 @@ func NewBaseExecutor(params BaseExecutorParams) *BaseExecutor {
 		verifiers:       params.Verifiers,
 		publishers:      params.Publishers,
 		simulatorConfig: params.SimulatorConfig,
+		signingKey:      params.SigningKey,
+		outputLimits:    params.OutputLimits,
 	}
 }
 
+// getOutputLimits returns the limits on the size of the results of an execution, which are the stricter of the
+// limits of its job and of this node.
+func (e *BaseExecutor) getOutputLimits(execution store.Execution) model.OutputLimits {
+	return capacity.ParseOutputLimitsConfig(execution.Shard.Job.Spec.OutputLimits).Min(e.outputLimits)
+}
+
 // Run the execution of a shard after it has been accepted, and propose a result to the requester to be verified.
 func (e *BaseExecutor) Run(ctx context.Context, execution store.Execution) (err error) {
 	ctx = log.Ctx(ctx).With().
@@ -54,19 +75,23 @@ func (e *BaseExecutor) Run(ctx context.Context, execution store.Execution) (err
 		Str("ExecutionID", execution.ID).
 		Logger().WithContext(ctx)
 
-	defer func() {
-		if err != nil {
-			e.handleFailure(ctx, execution, err, "Running")
-		}
-	}()
-
 	ctx, cancel := context.WithCancel(ctx)
-	e.cancellers.Put(execution, cancel)
+	e.cancellers.Put(execution.ID, cancel)
 	defer func() {
-		if cancel, found := e.cancellers.Get(execution); found {
-			e.cancellers.Delete(execution)
+		// a missing canceller means the execution was canceled through Cancel, in which case the caller that
+		// canceled the execution is responsible for updating its state instead of reporting a failure.
+		cancel, found := e.cancellers.Get(execution.ID)
+		if found {
+			e.cancellers.Delete(execution.ID)
 			cancel()
 		}
+		if err != nil {
+			if !found {
+				log.Ctx(ctx).Debug().Err(err).Msg("execution canceled while running")
+				return
+			}
+			e.handleFailure(ctx, execution, err, "Running")
+		}
 	}()
 
 	log.Ctx(ctx).Debug().Msg("Running execution")
@@ -97,7 +122,8 @@ func (e *BaseExecutor) Run(ctx context.Context, execution store.Execution) (err
 	var runCommandResult *model.RunCommandResult
 
 	if !e.simulatorConfig.IsBadActor {
-		runCommandResult, err = jobExecutor.RunShard(ctx, execution.Shard, resultFolder)
+		runCtx := executor.ContextWithOutputLimits(ctx, e.getOutputLimits(execution))
+		runCommandResult, err = jobExecutor.RunShard(runCtx, execution.Shard, resultFolder)
 		if err != nil {
 			jobsFailed.Add(ctx, 1)
 		} else {
@@ -128,7 +154,7 @@ func (e *BaseExecutor) Run(ctx context.Context, execution store.Execution) (err
 		ExecutionMetadata: NewExecutionMetadata(execution),
 		RoutingMetadata: RoutingMetadata{
 			SourcePeerID: e.ID,
-			TargetPeerID: execution.RequesterNodeID,
+			TargetPeerID: store.GetRequesterNodeID(ctx, e.store, execution),
 		},
 		ResultProposal:   shardProposal,
 		RunCommandResult: runCommandResult,
@@ -160,19 +186,52 @@ func (e *BaseExecutor) Publish(ctx context.Context, execution store.Execution) (
 	if err != nil {
 		return
 	}
+	// executors enforce the output limits while running, but not all of them can, so the result is checked
+	// again before it is published.
+	resultSize, err := executor.MeasureResults(resultFolder, execution.Shard.Job.Spec.Outputs)
+	if err != nil {
+		return
+	}
+	if err = resultSize.Check(execution.Shard.Job.Spec.Outputs, e.getOutputLimits(execution)); err != nil {
+		return
+	}
+	if e.signingKey != nil {
+		if err = manifest.Write(resultFolder, execution.ID, e.signingKey); err != nil {
+			return
+		}
+	}
+	if resultPackaging := execution.Shard.Job.Spec.ResultPackaging; resultPackaging != model.ResultPackagingNone {
+		var packagedFolder string
+		packagedFolder, err = os.MkdirTemp("", "bacalhau-packaged-result-*")
+		if err != nil {
+			return
+		}
+		defer func() {
+			if removeErr := os.RemoveAll(packagedFolder); removeErr != nil {
+				log.Ctx(ctx).Warn().Err(removeErr).Msgf("Failed to remove packaged result %s", packagedFolder)
+			}
+		}()
+		if _, err = packaging.Pack(ctx, resultPackaging, resultFolder, packagedFolder); err != nil {
+			return
+		}
+		resultFolder = packagedFolder
+	}
 	jobPublisher, err := e.publishers.Get(ctx, execution.Shard.Job.Spec.Publisher)
 	if err != nil {
 		return
 	}
-	publishedResult, err := jobPublisher.PublishShardResult(ctx, execution.Shard, e.ID, resultFolder)
+	publishedResult, err := jobPublisher.PublishShardResult(
+		publisher.ContextWithExecutionID(ctx, execution.ID), execution.Shard, e.ID, resultFolder)
 	if err != nil {
 		return
 	}
 
 	err = e.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
-		ExecutionID:   execution.ID,
-		ExpectedState: store.ExecutionStatePublishing,
-		NewState:      store.ExecutionStateCompleted,
+		ExecutionID:     execution.ID,
+		ExpectedState:   store.ExecutionStatePublishing,
+		NewState:        store.ExecutionStateCompleted,
+		PublishedResult: publishedResult,
+		ResultSize:      resultSize.Total,
 	})
 	if err != nil {
 		return
@@ -182,9 +241,10 @@ func (e *BaseExecutor) Publish(ctx context.Context, execution store.Execution) (
 		ExecutionMetadata: NewExecutionMetadata(execution),
 		RoutingMetadata: RoutingMetadata{
 			SourcePeerID: e.ID,
-			TargetPeerID: execution.RequesterNodeID,
+			TargetPeerID: store.GetRequesterNodeID(ctx, e.store, execution),
 		},
 		PublishResult: publishedResult,
+		ResultSize:    resultSize.Total,
 	})
 	return err
 }
@@ -198,8 +258,8 @@ func (e *BaseExecutor) Cancel(ctx context.Context, execution store.Execution) (e
 	}()
 
 	log.Ctx(ctx).Debug().Str("execution", execution.ID).Msg("Canceling execution")
-	if cancel, found := e.cancellers.Get(execution); found {
-		e.cancellers.Delete(execution)
+	if cancel, found := e.cancellers.Get(execution.ID); found {
+		e.cancellers.Delete(execution.ID)
 		cancel()
 	}
 
@@ -207,7 +267,7 @@ func (e *BaseExecutor) Cancel(ctx context.Context, execution store.Execution) (e
 		ExecutionMetadata: NewExecutionMetadata(execution),
 		RoutingMetadata: RoutingMetadata{
 			SourcePeerID: e.ID,
-			TargetPeerID: execution.RequesterNodeID,
+			TargetPeerID: store.GetRequesterNodeID(ctx, e.store, execution),
 		},
 	})
 	return err
@@ -228,12 +288,22 @@ func (e *BaseExecutor) handleFailure(ctx context.Context, execution store.Execut
 			ExecutionMetadata: NewExecutionMetadata(execution),
 			RoutingMetadata: RoutingMetadata{
 				SourcePeerID: e.ID,
-				TargetPeerID: execution.RequesterNodeID,
+				TargetPeerID: store.GetRequesterNodeID(ctx, e.store, execution),
 			},
-			Err: err.Error(),
+			Err:       err.Error(),
+			ErrorCode: errorCode(err),
 		})
 	}
 }
 
+// errorCode returns the code of the bacerrors error in the chain of an error, or an empty string if there is none.
+func errorCode(err error) string {
+	var bacErr bacerrors.BacalhauErrorInterface
+	if errors.As(err, &bacErr) {
+		return bacErr.GetCode()
+	}
+	return ""
+}
+
 // compile-time interface check
 var _ Executor = (*BaseExecutor)(nil)
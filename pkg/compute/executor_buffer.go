@@ -3,18 +3,52 @@ package compute
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	sync "github.com/bacalhau-project/golang-mutex-tracer"
 	"github.com/filecoin-project/bacalhau/pkg/compute/capacity"
 	"github.com/filecoin-project/bacalhau/pkg/compute/store"
 	"github.com/filecoin-project/bacalhau/pkg/logger"
+	"github.com/filecoin-project/bacalhau/pkg/model"
 	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/rs/zerolog/log"
 )
 
+// ExecutorBufferMode defines the order in which the ExecutorBuffer runs enqueued executions.
+type ExecutorBufferMode string
+
+const (
+	// ExecutorBufferModeFIFO runs executions in the order they were enqueued, while skipping executions that
+	// don't fit in the available capacity.
+	ExecutorBufferModeFIFO ExecutorBufferMode = "fifo"
+	// ExecutorBufferModePriority runs executions with higher job priority first, and older executions first
+	// within the same priority. Executions gain priority the longer they wait to prevent starvation.
+	ExecutorBufferModePriority ExecutorBufferMode = "priority"
+)
+
+// ExecutorBufferModes returns the list of supported buffer modes.
+func ExecutorBufferModes() []ExecutorBufferMode {
+	return []ExecutorBufferMode{ExecutorBufferModeFIFO, ExecutorBufferModePriority}
+}
+
+// ParseExecutorBufferMode returns the buffer mode matching the given name.
+func ParseExecutorBufferMode(name string) (ExecutorBufferMode, error) {
+	for _, mode := range ExecutorBufferModes() {
+		if string(mode) == name {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown executor buffer mode %q. Supported modes are %v", name, ExecutorBufferModes())
+}
+
 type bufferTask struct {
 	execution  store.Execution
 	enqueuedAt time.Time
+	startedAt  time.Time
+	// preempted is set when the running execution is canceled to free capacity for a higher priority execution,
+	// and is requeued once it stops running.
+	preempted bool
 }
 
 func newBufferTask(execution store.Execution) *bufferTask {
@@ -32,6 +66,17 @@ type ExecutorBufferParams struct {
 	EnqueuedCapacityTracker    capacity.Tracker
 	DefaultJobExecutionTimeout time.Duration
 	BackoffDuration            time.Duration
+	// Mode defines the order in which enqueued executions are run. Defaults to ExecutorBufferModeFIFO.
+	Mode ExecutorBufferMode
+	// AgingInterval is how long an execution has to wait to gain one level of priority in priority mode.
+	// Zero disables aging.
+	AgingInterval time.Duration
+	// Preemption allows canceling and requeuing running executions with lower priority when an execution
+	// with higher priority doesn't fit in the available capacity. Only used in priority mode.
+	Preemption bool
+	// Store is used to move preempted executions back to the BidAccepted state before requeuing them.
+	// Required when preemption is enabled.
+	Store store.ExecutionStore
 }
 
 // ExecutorBuffer is a backend.Executor implementation that buffers executions locally until enough capacity is
@@ -40,6 +85,11 @@ type ExecutorBufferParams struct {
 // they were enqueued. However, an execution with high resource usage requirements might be skipped if there are newer
 // jobs with lower resource usage requirements that can be executed immediately. This is done to improve utilization
 // of compute nodes, though it might result in starvation and should be re-evaluated in the future.
+//
+// Alternatively, the buffer can order executions by job priority and age, where executions gain priority the longer
+// they wait. In this mode, lower priority executions can only skip ahead if they fit in the capacity that is left
+// after reserving the capacity needed by higher priority executions, which prevents starving large executions.
+// Running executions with lower priority can also be preempted to make room for higher priority ones.
 type ExecutorBuffer struct {
 	ID                         string
 	runningCapacity            capacity.Tracker
@@ -52,6 +102,10 @@ type ExecutorBuffer struct {
 	defaultJobExecutionTimeout time.Duration
 	backoffDuration            time.Duration
 	backoffUntil               time.Time
+	mode                       ExecutorBufferMode
+	agingInterval              time.Duration
+	preemption                 bool
+	store                      store.ExecutionStore
 	mu                         sync.Mutex
 }
 
@@ -67,6 +121,13 @@ func NewExecutorBuffer(params ExecutorBufferParams) *ExecutorBuffer {
 		enqueuedList:               make([]string, 0),
 		defaultJobExecutionTimeout: params.DefaultJobExecutionTimeout,
 		backoffDuration:            params.BackoffDuration,
+		mode:                       params.Mode,
+		agingInterval:              params.AgingInterval,
+		preemption:                 params.Preemption,
+		store:                      params.Store,
+	}
+	if r.mode == "" {
+		r.mode = ExecutorBufferModeFIFO
 	}
 
 	r.mu.EnableTracerWithOpts(sync.Opts{
@@ -88,7 +149,7 @@ func (s *ExecutorBuffer) Run(ctx context.Context, execution store.Execution) (er
 				ExecutionMetadata: NewExecutionMetadata(execution),
 				RoutingMetadata: RoutingMetadata{
 					SourcePeerID: s.ID,
-					TargetPeerID: execution.RequesterNodeID,
+					TargetPeerID: store.GetRequesterNodeID(ctx, s.store, execution),
 				},
 				Err: err.Error(),
 			})
@@ -145,7 +206,7 @@ func (s *ExecutorBuffer) doRun(ctx context.Context, task *bufferTask) {
 			ExecutionMetadata: NewExecutionMetadata(task.execution),
 			RoutingMetadata: RoutingMetadata{
 				SourcePeerID: s.ID,
-				TargetPeerID: task.execution.RequesterNodeID,
+				TargetPeerID: store.GetRequesterNodeID(ctx, s.store, task.execution),
 			},
 			Err: fmt.Sprintf("execution timed out after %s", timeout),
 		})
@@ -158,9 +219,62 @@ func (s *ExecutorBuffer) doRun(ctx context.Context, task *bufferTask) {
 	defer s.mu.Unlock()
 	s.runningCapacity.Remove(ctx, task.execution.ResourceUsage)
 	delete(s.running, task.execution.ID)
+	if task.preempted {
+		s.requeue(ctx, task)
+	}
 	s.deque()
 }
 
+// requeue moves a preempted execution back to the queue to be run again when capacity is available.
+// It is called with the lock held.
+func (s *ExecutorBuffer) requeue(ctx context.Context, task *bufferTask) {
+	err := s.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID:   task.execution.ID,
+		ExpectedState: store.ExecutionStateRunning,
+		NewState:      store.ExecutionStateBidAccepted,
+		Comment:       "preempted by a higher priority execution",
+	})
+	if err != nil {
+		// the execution might have completed or failed before it was preempted
+		log.Ctx(ctx).Debug().Err(err).Msgf("not requeuing preempted execution %s", task.execution.ID)
+		return
+	}
+	execution, err := s.store.GetExecution(ctx, task.execution.ID)
+	if err == nil && !s.enqueuedCapacity.AddIfHasCapacity(ctx, execution.ResourceUsage) {
+		err = fmt.Errorf("not enough capacity to requeue preempted execution")
+	}
+	if err != nil {
+		s.failPreempted(ctx, task.execution, err)
+		return
+	}
+	log.Ctx(ctx).Info().Msgf("requeuing preempted execution %s", execution.ID)
+	s.enqueued[execution.ID] = &bufferTask{
+		execution:  execution,
+		enqueuedAt: task.enqueuedAt,
+	}
+	s.enqueuedList = append(s.enqueuedList, execution.ID)
+}
+
+func (s *ExecutorBuffer) failPreempted(ctx context.Context, execution store.Execution, err error) {
+	log.Ctx(ctx).Error().Err(err).Msgf("failed to requeue preempted execution %s", execution.ID)
+	updateErr := s.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID: execution.ID,
+		NewState:    store.ExecutionStateFailed,
+		Comment:     err.Error(),
+	})
+	if updateErr != nil {
+		log.Ctx(ctx).Error().Err(updateErr).Msgf("failed to update execution state to failed: %s", execution)
+	}
+	s.callback.OnComputeFailure(ctx, ComputeError{
+		ExecutionMetadata: NewExecutionMetadata(execution),
+		RoutingMetadata: RoutingMetadata{
+			SourcePeerID: s.ID,
+			TargetPeerID: store.GetRequesterNodeID(ctx, s.store, execution),
+		},
+		Err: err.Error(),
+	})
+}
+
 // deque tries to run the next execution in the queue if there is enough capacity.
 // It is called every time a job is finished or enqueued, where a lock is already held.
 func (s *ExecutorBuffer) deque() {
@@ -170,6 +284,11 @@ func (s *ExecutorBuffer) deque() {
 		return
 	}
 	ctx := context.Background()
+	if s.mode == ExecutorBufferModePriority {
+		s.dequeByPriority(ctx)
+		s.backoffUntil = time.Now().Add(s.backoffDuration)
+		return
+	}
 
 	// We are maintain the order of enqueued executions treat it as a FIFO queue, while allowing to skip over jobs
 	// that require more resources than the current capacity. This is done to improve utilization of compute nodes,
@@ -180,10 +299,7 @@ func (s *ExecutorBuffer) deque() {
 		task := s.enqueued[executionID]
 
 		if s.runningCapacity.AddIfHasCapacity(ctx, task.execution.ResourceUsage) {
-			s.enqueuedCapacity.Remove(ctx, task.execution.ResourceUsage)
-			delete(s.enqueued, executionID)
-			s.running[executionID] = task
-			go s.doRun(logger.ContextWithNodeIDLogger(context.Background(), s.ID), task)
+			s.startTask(task)
 		} else {
 			remainingEnqueuedList = append(remainingEnqueuedList, executionID)
 		}
@@ -192,6 +308,104 @@ func (s *ExecutorBuffer) deque() {
 	s.backoffUntil = time.Now().Add(s.backoffDuration)
 }
 
+// dequeByPriority runs enqueued executions ordered by their effective priority. An execution that doesn't fit
+// in the available capacity reserves the capacity it needs, so that executions with lower priority can only run
+// if they fit in what is left, and preempts running executions with lower priority if preemption is enabled.
+// It is called with the lock held.
+func (s *ExecutorBuffer) dequeByPriority(ctx context.Context) {
+	now := time.Now()
+	sort.SliceStable(s.enqueuedList, func(i, j int) bool {
+		taskI, taskJ := s.enqueued[s.enqueuedList[i]], s.enqueued[s.enqueuedList[j]]
+		priorityI, priorityJ := s.effectivePriority(taskI, now), s.effectivePriority(taskJ, now)
+		if priorityI != priorityJ {
+			return priorityI > priorityJ
+		}
+		return taskI.enqueuedAt.Before(taskJ.enqueuedAt)
+	})
+
+	var reserved model.ResourceUsageData
+	remainingEnqueuedList := make([]string, 0, len(s.enqueuedList))
+	for _, executionID := range s.enqueuedList {
+		task := s.enqueued[executionID]
+		usage := task.execution.ResourceUsage
+		if usage.Add(reserved).LessThanEq(s.runningCapacity.GetAvailableCapacity(ctx)) &&
+			s.runningCapacity.AddIfHasCapacity(ctx, usage) {
+			s.startTask(task)
+			continue
+		}
+		if s.preemption && reserved.IsZero() {
+			s.preemptFor(ctx, task)
+		}
+		reserved = reserved.Add(usage)
+		remainingEnqueuedList = append(remainingEnqueuedList, executionID)
+	}
+	s.enqueuedList = remainingEnqueuedList
+}
+
+// effectivePriority returns the job priority of the task, increased by one for every aging interval it has been waiting.
+func (s *ExecutorBuffer) effectivePriority(task *bufferTask, now time.Time) int {
+	priority := task.execution.Shard.Job.Spec.Priority
+	if s.agingInterval > 0 {
+		priority += int(now.Sub(task.enqueuedAt) / s.agingInterval)
+	}
+	return priority
+}
+
+// preemptFor cancels running executions with lower job priority than the given task if that frees enough
+// capacity to run it. Executions with the lowest priority, and then the most recently started, are preempted first.
+// Preempted executions are requeued once they stop running. It is called with the lock held.
+func (s *ExecutorBuffer) preemptFor(ctx context.Context, task *bufferTask) {
+	priority := task.execution.Shard.Job.Spec.Priority
+	freed := s.runningCapacity.GetAvailableCapacity(ctx)
+	var candidates []*bufferTask
+	for _, running := range s.running {
+		if running.preempted {
+			// capacity of executions that are already being preempted will be freed soon
+			freed = freed.Add(running.execution.ResourceUsage)
+		} else if running.execution.Shard.Job.Spec.Priority < priority {
+			candidates = append(candidates, running)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		priorityI := candidates[i].execution.Shard.Job.Spec.Priority
+		priorityJ := candidates[j].execution.Shard.Job.Spec.Priority
+		if priorityI != priorityJ {
+			return priorityI < priorityJ
+		}
+		return candidates[i].startedAt.After(candidates[j].startedAt)
+	})
+
+	var toPreempt []*bufferTask
+	for _, candidate := range candidates {
+		if task.execution.ResourceUsage.LessThanEq(freed) {
+			break
+		}
+		freed = freed.Add(candidate.execution.ResourceUsage)
+		toPreempt = append(toPreempt, candidate)
+	}
+	if !task.execution.ResourceUsage.LessThanEq(freed) {
+		// preempting all lower priority executions is not enough
+		return
+	}
+	for _, running := range toPreempt {
+		log.Ctx(ctx).Info().Msgf("preempting execution %s with priority %d to run execution %s with priority %d",
+			running.execution.ID, running.execution.Shard.Job.Spec.Priority, task.execution.ID, priority)
+		running.preempted = true
+		_ = s.Cancel(ctx, running.execution)
+	}
+}
+
+// startTask moves an enqueued task whose running capacity was already reserved to the running state.
+// It is called with the lock held.
+func (s *ExecutorBuffer) startTask(task *bufferTask) {
+	ctx := context.Background()
+	s.enqueuedCapacity.Remove(ctx, task.execution.ResourceUsage)
+	delete(s.enqueued, task.execution.ID)
+	task.startedAt = time.Now()
+	s.running[task.execution.ID] = task
+	go s.doRun(logger.ContextWithNodeIDLogger(context.Background(), s.ID), task)
+}
+
 func (s *ExecutorBuffer) Publish(_ context.Context, execution store.Execution) error {
 	// TODO: Enqueue publish tasks
 	go func() {
@@ -0,0 +1,248 @@
+package compute
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/compute/capacity"
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	"github.com/filecoin-project/bacalhau/pkg/compute/store/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// testExecutor is a delegate executor that keeps executions running until they are finished by the test or canceled.
+type testExecutor struct {
+	store     store.ExecutionStore
+	mu        sync.Mutex
+	started   []string
+	cancelled []string
+	running   map[string]context.CancelFunc
+	finish    map[string]chan struct{}
+}
+
+func newTestExecutor(executionStore store.ExecutionStore) *testExecutor {
+	return &testExecutor{
+		store:   executionStore,
+		running: make(map[string]context.CancelFunc),
+		finish:  make(map[string]chan struct{}),
+	}
+}
+
+func (e *testExecutor) Run(ctx context.Context, execution store.Execution) error {
+	err := e.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID:   execution.ID,
+		ExpectedState: store.ExecutionStateBidAccepted,
+		NewState:      store.ExecutionStateRunning,
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	finish := make(chan struct{})
+
+	e.mu.Lock()
+	e.started = append(e.started, execution.ID)
+	e.running[execution.ID] = cancel
+	e.finish[execution.ID] = finish
+	e.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-finish:
+		return e.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+			ExecutionID:   execution.ID,
+			ExpectedState: store.ExecutionStateRunning,
+			NewState:      store.ExecutionStateCompleted,
+		})
+	}
+}
+
+func (e *testExecutor) Publish(ctx context.Context, execution store.Execution) error {
+	return nil
+}
+
+func (e *testExecutor) Cancel(ctx context.Context, execution store.Execution) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cancelled = append(e.cancelled, execution.ID)
+	if cancel, ok := e.running[execution.ID]; ok {
+		delete(e.running, execution.ID)
+		cancel()
+	}
+	return nil
+}
+
+func (e *testExecutor) complete(executionID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.running, executionID)
+	close(e.finish[executionID])
+}
+
+func (e *testExecutor) getStarted() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string{}, e.started...)
+}
+
+func (e *testExecutor) getCancelled() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string{}, e.cancelled...)
+}
+
+type noopCallback struct{}
+
+func (noopCallback) OnRunComplete(context.Context, RunResult)         {}
+func (noopCallback) OnPublishComplete(context.Context, PublishResult) {}
+func (noopCallback) OnCancelComplete(context.Context, CancelResult)   {}
+func (noopCallback) OnComputeFailure(context.Context, ComputeError)   {}
+
+type ExecutorBufferPrioritySuite struct {
+	suite.Suite
+	ctx      context.Context
+	store    store.ExecutionStore
+	delegate *testExecutor
+}
+
+func TestExecutorBufferPrioritySuite(t *testing.T) {
+	suite.Run(t, new(ExecutorBufferPrioritySuite))
+}
+
+func (s *ExecutorBufferPrioritySuite) SetupTest() {
+	s.ctx = context.Background()
+	s.store = inmemory.NewStore()
+	s.delegate = newTestExecutor(s.store)
+}
+
+func (s *ExecutorBufferPrioritySuite) newBuffer(mode ExecutorBufferMode, agingInterval time.Duration, preemption bool) *ExecutorBuffer {
+	return NewExecutorBuffer(ExecutorBufferParams{
+		ID:               "compute",
+		DelegateExecutor: s.delegate,
+		Callback:         noopCallback{},
+		RunningCapacityTracker: capacity.NewLocalTracker(capacity.LocalTrackerParams{
+			MaxCapacity: model.ResourceUsageData{CPU: 2},
+		}),
+		EnqueuedCapacityTracker: capacity.NewLocalTracker(capacity.LocalTrackerParams{
+			MaxCapacity: model.ResourceUsageData{CPU: 10},
+		}),
+		DefaultJobExecutionTimeout: time.Minute,
+		Mode:                       mode,
+		AgingInterval:              agingInterval,
+		Preemption:                 preemption,
+		Store:                      s.store,
+	})
+}
+
+func (s *ExecutorBufferPrioritySuite) run(buffer *ExecutorBuffer, priority int, cpu float64) string {
+	execution := store.NewExecution(
+		uuid.NewString(),
+		model.JobShard{Job: &model.Job{Spec: model.Spec{Priority: priority}}},
+		"requester",
+		model.ResourceUsageData{CPU: cpu},
+	)
+	s.Require().NoError(s.store.CreateExecution(s.ctx, *execution))
+	s.Require().NoError(s.store.UpdateExecutionState(s.ctx, store.UpdateExecutionStateRequest{
+		ExecutionID: execution.ID,
+		NewState:    store.ExecutionStateBidAccepted,
+	}))
+	accepted, err := s.store.GetExecution(s.ctx, execution.ID)
+	s.Require().NoError(err)
+	s.Require().NoError(buffer.Run(s.ctx, accepted))
+	return execution.ID
+}
+
+func (s *ExecutorBufferPrioritySuite) waitForStarted(executionIDs ...string) {
+	s.Eventually(func() bool {
+		return assert.ObjectsAreEqual(executionIDs, s.delegate.getStarted())
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (s *ExecutorBufferPrioritySuite) TestHigherPriorityRunsFirst() {
+	buffer := s.newBuffer(ExecutorBufferModePriority, 0, false)
+	running := s.run(buffer, 0, 2)
+	low := s.run(buffer, 0, 2)
+	high := s.run(buffer, 5, 2)
+	s.waitForStarted(running)
+
+	s.delegate.complete(running)
+	s.waitForStarted(running, high)
+	s.delegate.complete(high)
+	s.waitForStarted(running, high, low)
+}
+
+func (s *ExecutorBufferPrioritySuite) TestFIFOIgnoresPriority() {
+	buffer := s.newBuffer(ExecutorBufferModeFIFO, 0, false)
+	running := s.run(buffer, 0, 2)
+	low := s.run(buffer, 0, 2)
+	s.run(buffer, 5, 2)
+	s.waitForStarted(running)
+
+	s.delegate.complete(running)
+	s.waitForStarted(running, low)
+}
+
+func (s *ExecutorBufferPrioritySuite) TestLowerPriorityCannotSkipBlockedExecution() {
+	buffer := s.newBuffer(ExecutorBufferModePriority, 0, false)
+	running := s.run(buffer, 0, 1)
+	large := s.run(buffer, 5, 2)
+	small := s.run(buffer, 0, 1)
+
+	// the small execution fits, but would delay the large execution with higher priority
+	time.Sleep(50 * time.Millisecond)
+	s.Equal([]string{running}, s.delegate.getStarted())
+
+	s.delegate.complete(running)
+	s.waitForStarted(running, large)
+	s.delegate.complete(large)
+	s.waitForStarted(running, large, small)
+}
+
+func (s *ExecutorBufferPrioritySuite) TestAgingPreventsStarvation() {
+	buffer := s.newBuffer(ExecutorBufferModePriority, 20*time.Millisecond, false)
+	running := s.run(buffer, 0, 2)
+	old := s.run(buffer, 0, 2)
+	time.Sleep(100 * time.Millisecond)
+	recent := s.run(buffer, 2, 2)
+
+	s.delegate.complete(running)
+	s.waitForStarted(running, old)
+	s.delegate.complete(old)
+	s.waitForStarted(running, old, recent)
+}
+
+func (s *ExecutorBufferPrioritySuite) TestPreemption() {
+	buffer := s.newBuffer(ExecutorBufferModePriority, 0, true)
+	low := s.run(buffer, 0, 2)
+	s.waitForStarted(low)
+
+	high := s.run(buffer, 5, 2)
+	s.waitForStarted(low, high)
+	s.Equal([]string{low}, s.delegate.getCancelled())
+
+	// the preempted execution is requeued and runs again once capacity is available
+	s.Eventually(func() bool {
+		execution, err := s.store.GetExecution(s.ctx, low)
+		return err == nil && execution.State == store.ExecutionStateBidAccepted
+	}, time.Second, 10*time.Millisecond)
+	s.delegate.complete(high)
+	s.waitForStarted(low, high, low)
+}
+
+func (s *ExecutorBufferPrioritySuite) TestNoPreemptionOfSamePriority() {
+	buffer := s.newBuffer(ExecutorBufferModePriority, 0, true)
+	running := s.run(buffer, 5, 2)
+	s.waitForStarted(running)
+
+	s.run(buffer, 5, 2)
+	time.Sleep(50 * time.Millisecond)
+	s.Equal([]string{running}, s.delegate.getStarted())
+	s.Empty(s.delegate.getCancelled())
+}
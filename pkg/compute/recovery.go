@@ -0,0 +1,101 @@
+package compute
+
+import (
+	"context"
+
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	"github.com/rs/zerolog/log"
+)
+
+// interruptedExecutionComment is the reason reported for executions that were running when the compute node stopped
+const interruptedExecutionComment = "execution was interrupted by a compute node restart"
+
+type RecoveryParams struct {
+	ID       string
+	Store    store.ExecutionStore
+	Executor Executor
+	Callback Callback
+}
+
+// Recovery resumes the executions of this compute node after a restart, when its execution store is persistent.
+// Executions that were enqueued or running are lost with the node, so they are failed and reported to their
+// requester, which can retry them on another node. Executions whose result was accepted are published again.
+// Executions waiting for the requester to accept their bid or result are left as they are.
+type Recovery struct {
+	id       string
+	store    store.ExecutionStore
+	executor Executor
+	callback Callback
+}
+
+func NewRecovery(params RecoveryParams) *Recovery {
+	return &Recovery{
+		id:       params.ID,
+		store:    params.Store,
+		executor: params.Executor,
+		callback: params.Callback,
+	}
+}
+
+// Recover reconciles all active executions in the store, and returns once all of them were processed.
+func (r *Recovery) Recover(ctx context.Context) {
+	executions, err := r.store.GetActiveExecutions(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[Recover] failed to get active executions")
+		return
+	}
+
+	for _, execution := range executions {
+		switch execution.State {
+		case store.ExecutionStateBidAccepted, store.ExecutionStateRunning:
+			r.failExecution(ctx, execution)
+		case store.ExecutionStatePublishing:
+			// move the execution back to ResultAccepted, as expected by Publish
+			err = r.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+				ExecutionID:     execution.ID,
+				ExpectedState:   store.ExecutionStatePublishing,
+				ExpectedVersion: execution.Version,
+				NewState:        store.ExecutionStateResultAccepted,
+				Comment:         "publishing was interrupted by a compute node restart",
+			})
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msgf("[Recover] failed to resume publishing execution %s", execution.ID)
+				continue
+			}
+			r.publish(ctx, execution)
+		case store.ExecutionStateResultAccepted:
+			r.publish(ctx, execution)
+		default:
+		}
+	}
+}
+
+func (r *Recovery) publish(ctx context.Context, execution store.Execution) {
+	log.Ctx(ctx).Info().Msgf("resuming publishing of execution %s", execution.ID)
+	if err := r.executor.Publish(ctx, execution); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[Recover] failed to publish execution %s", execution.ID)
+	}
+}
+
+func (r *Recovery) failExecution(ctx context.Context, execution store.Execution) {
+	log.Ctx(ctx).Info().Msgf("failing execution %s interrupted by a compute node restart", execution.ID)
+	err := r.store.UpdateExecutionState(ctx, store.UpdateExecutionStateRequest{
+		ExecutionID:     execution.ID,
+		ExpectedState:   execution.State,
+		ExpectedVersion: execution.Version,
+		NewState:        store.ExecutionStateFailed,
+		Comment:         interruptedExecutionComment,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[Recover] failed to update execution %s", execution.ID)
+		return
+	}
+	r.callback.OnComputeFailure(ctx, ComputeError{
+		ExecutionMetadata: NewExecutionMetadata(execution),
+		RoutingMetadata: RoutingMetadata{
+			SourcePeerID: r.id,
+			TargetPeerID: execution.RequesterNodeID,
+		},
+		Err: interruptedExecutionComment,
+	})
+}
@@ -0,0 +1,139 @@
+package compute
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	"github.com/filecoin-project/bacalhau/pkg/compute/store/sqlite"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+// recoveryExecutor records the executions it was asked to publish.
+type recoveryExecutor struct {
+	noopExecutor
+	mu        sync.Mutex
+	published []string
+}
+
+func (e *recoveryExecutor) Publish(_ context.Context, execution store.Execution) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.published = append(e.published, execution.ID)
+	return nil
+}
+
+type noopExecutor struct{}
+
+func (noopExecutor) Run(context.Context, store.Execution) error     { return nil }
+func (noopExecutor) Publish(context.Context, store.Execution) error { return nil }
+func (noopExecutor) Cancel(context.Context, store.Execution) error  { return nil }
+
+// recoveryCallback records the failures reported to requesters.
+type recoveryCallback struct {
+	noopCallback
+	mu       sync.Mutex
+	failures []ComputeError
+}
+
+func (c *recoveryCallback) OnComputeFailure(_ context.Context, err ComputeError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = append(c.failures, err)
+}
+
+type RecoverySuite struct {
+	suite.Suite
+	ctx      context.Context
+	path     string
+	executor *recoveryExecutor
+	callback *recoveryCallback
+}
+
+func TestRecoverySuite(t *testing.T) {
+	suite.Run(t, new(RecoverySuite))
+}
+
+func (s *RecoverySuite) SetupTest() {
+	s.ctx = context.Background()
+	s.path = filepath.Join(s.T().TempDir(), "executions.db")
+	s.executor = &recoveryExecutor{}
+	s.callback = &recoveryCallback{}
+}
+
+func (s *RecoverySuite) TestRecover() {
+	executionStore := s.openStore()
+	created := s.createExecution(executionStore)
+	bidAccepted := s.createExecution(executionStore, store.ExecutionStateBidAccepted)
+	running := s.createExecution(executionStore, store.ExecutionStateBidAccepted, store.ExecutionStateRunning)
+	waitingVerification := s.createExecution(executionStore,
+		store.ExecutionStateBidAccepted, store.ExecutionStateRunning, store.ExecutionStateWaitingVerification)
+	resultAccepted := s.createExecution(executionStore,
+		store.ExecutionStateBidAccepted, store.ExecutionStateRunning, store.ExecutionStateWaitingVerification,
+		store.ExecutionStateResultAccepted)
+	publishing := s.createExecution(executionStore,
+		store.ExecutionStateBidAccepted, store.ExecutionStateRunning, store.ExecutionStateWaitingVerification,
+		store.ExecutionStateResultAccepted, store.ExecutionStatePublishing)
+	completed := s.createExecution(executionStore, store.ExecutionStateCompleted)
+	s.Require().NoError(executionStore.Close())
+
+	// recover from a new store on the same database, as after a restart
+	executionStore = s.openStore()
+	NewRecovery(RecoveryParams{
+		ID:       "compute-node",
+		Store:    executionStore,
+		Executor: s.executor,
+		Callback: s.callback,
+	}).Recover(s.ctx)
+
+	s.Equal(store.ExecutionStateCreated, s.getState(executionStore, created))
+	s.Equal(store.ExecutionStateFailed, s.getState(executionStore, bidAccepted))
+	s.Equal(store.ExecutionStateFailed, s.getState(executionStore, running))
+	s.Equal(store.ExecutionStateWaitingVerification, s.getState(executionStore, waitingVerification))
+	s.Equal(store.ExecutionStateResultAccepted, s.getState(executionStore, resultAccepted))
+	s.Equal(store.ExecutionStateResultAccepted, s.getState(executionStore, publishing))
+	s.Equal(store.ExecutionStateCompleted, s.getState(executionStore, completed))
+
+	s.ElementsMatch([]string{resultAccepted.ID, publishing.ID}, s.executor.published)
+	s.Len(s.callback.failures, 2)
+	for _, failure := range s.callback.failures {
+		s.Contains([]string{bidAccepted.ID, running.ID}, failure.ExecutionID)
+		s.Equal("compute-node", failure.SourcePeerID)
+		s.Equal("requester-node", failure.TargetPeerID)
+		s.Equal(interruptedExecutionComment, failure.Err)
+	}
+}
+
+func (s *RecoverySuite) openStore() *sqlite.Store {
+	executionStore, err := sqlite.NewStore(s.path)
+	s.Require().NoError(err)
+	return executionStore
+}
+
+// createExecution creates an execution and moves it through the given states
+func (s *RecoverySuite) createExecution(executionStore store.ExecutionStore, states ...store.ExecutionState) store.Execution {
+	execution := *store.NewExecution(
+		uuid.NewString(),
+		model.JobShard{Job: &model.Job{Metadata: model.Metadata{ID: uuid.NewString()}}},
+		"requester-node",
+		model.ResourceUsageData{CPU: 1},
+	)
+	s.Require().NoError(executionStore.CreateExecution(s.ctx, execution))
+	for _, state := range states {
+		s.Require().NoError(executionStore.UpdateExecutionState(s.ctx, store.UpdateExecutionStateRequest{
+			ExecutionID: execution.ID,
+			NewState:    state,
+		}))
+	}
+	return execution
+}
+
+func (s *RecoverySuite) getState(executionStore store.ExecutionStore, execution store.Execution) store.ExecutionState {
+	stored, err := executionStore.GetExecution(s.ctx, execution.ID)
+	s.Require().NoError(err)
+	return stored.State
+}
@@ -55,6 +55,18 @@ func (s *Store) GetExecutions(ctx context.Context, shardID string) ([]store.Exec
 	return executions, nil
 }
 
+func (s *Store) GetActiveExecutions(ctx context.Context) ([]store.Execution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	executions := make([]store.Execution, 0)
+	for _, execution := range s.executionMap {
+		if execution.State.IsActive() {
+			executions = append(executions, execution)
+		}
+	}
+	return executions, nil
+}
+
 func (s *Store) GetExecutionHistory(ctx context.Context, id string) ([]store.ExecutionHistory, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -99,6 +111,13 @@ func (s *Store) UpdateExecutionState(ctx context.Context, request store.UpdateEx
 	}
 	previousState := execution.State
 	execution.State = request.NewState
+	if request.NewRequesterNodeID != "" {
+		execution.RequesterNodeID = request.NewRequesterNodeID
+	}
+	if request.NewState == store.ExecutionStateCompleted {
+		execution.PublishedResult = request.PublishedResult
+		execution.ResultSize = request.ResultSize
+	}
 	execution.Version += 1
 	execution.UpdateTime = time.Now()
 	s.executionMap[execution.ID] = execution
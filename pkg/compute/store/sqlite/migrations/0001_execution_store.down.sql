@@ -0,0 +1,2 @@
+drop table execution_history;
+drop table execution;
@@ -0,0 +1,22 @@
+create table execution (
+  id varchar(255) PRIMARY KEY,
+  shard_id varchar(255),
+  state integer,
+  version integer,
+  create_time bigint,
+  update_time bigint,
+  executiondata text default ''
+);
+CREATE INDEX idx_executionstore_execution_shard_id ON execution (shard_id);
+CREATE INDEX idx_executionstore_execution_state ON execution (state);
+
+create table execution_history (
+  execution_id varchar(255),
+  seq integer,
+  previous_state integer,
+  new_state integer,
+  new_version integer,
+  comment text default '',
+  time bigint,
+  PRIMARY KEY(execution_id, seq)
+);
@@ -0,0 +1,341 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/XSAM/otelsql"
+	sync "github.com/bacalhau-project/golang-mutex-tracer"
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	newExecutionComment = "Execution created"
+	// busyTimeoutPragma makes concurrent writers wait for the database lock instead of failing immediately
+	busyTimeoutPragma = "_pragma=busy_timeout(5000)"
+	// migrationsTable keeps the schema version of the execution store apart from the job store's, in case both
+	// share the same database file
+	migrationsTable = "execution_store_schema_migrations"
+)
+
+// sqlClient is so we can pass *sql.DB and *sql.Tx to the same functions
+type sqlClient interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Store is a store.ExecutionStore that persists executions and their history to a SQLite database, so that
+// a compute node can recover its executions after a restart.
+type Store struct {
+	mu       sync.RWMutex
+	filename string
+	db       *sql.DB
+}
+
+func NewStore(filename string) (*Store, error) {
+	db, err := otelsql.Open(
+		"sqlite",
+		fmt.Sprintf("%s?%s", filename, busyTimeoutPragma),
+		otelsql.WithAttributes(semconv.DBSystemSqlite, semconv.PeerService("sqlite")),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := otelsql.RegisterDBStatsMetrics(db, otelsql.WithAttributes(semconv.DBSystemSqlite)); err != nil { //nolint:govet
+		return nil, err
+	}
+	s := &Store{
+		filename: filename,
+		db:       db,
+	}
+	s.mu.EnableTracerWithOpts(sync.Opts{
+		Threshold: 10 * time.Millisecond,
+		Id:        "SQLiteExecutionStore.mu",
+	})
+	if err = s.MigrateUp(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) GetExecution(ctx context.Context, id string) (store.Execution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return getExecution(ctx, s.db, id)
+}
+
+func (s *Store) GetExecutions(ctx context.Context, shardID string) ([]store.Execution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	executions, err := queryExecutions(ctx, s.db, `
+SELECT executiondata, create_time, update_time FROM execution WHERE shard_id = $1 ORDER BY rowid`, shardID)
+	if err != nil {
+		return nil, err
+	}
+	if len(executions) == 0 {
+		return []store.Execution{}, store.NewErrExecutionsNotFound(shardID)
+	}
+	return executions, nil
+}
+
+func (s *Store) GetActiveExecutions(ctx context.Context) ([]store.Execution, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	executions, err := queryExecutions(ctx, s.db, `
+SELECT executiondata, create_time, update_time FROM execution WHERE state NOT IN ($1, $2, $3) ORDER BY rowid`,
+		store.ExecutionStateCompleted, store.ExecutionStateFailed, store.ExecutionStateCancelled)
+	if err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
+func (s *Store) GetExecutionHistory(ctx context.Context, id string) ([]store.ExecutionHistory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rows, err := s.db.QueryContext(ctx, `
+SELECT previous_state, new_state, new_version, comment, time FROM execution_history WHERE execution_id = $1 ORDER BY seq`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []store.ExecutionHistory
+	for rows.Next() {
+		entry := store.ExecutionHistory{ExecutionID: id}
+		var entryTime int64
+		if err = rows.Scan(&entry.PreviousState, &entry.NewState, &entry.NewVersion, &entry.Comment, &entryTime); err != nil {
+			return nil, err
+		}
+		entry.Time = time.Unix(0, entryTime)
+		history = append(history, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return history, store.NewErrExecutionHistoryNotFound(id)
+	}
+	return history, nil
+}
+
+func (s *Store) CreateExecution(ctx context.Context, execution store.Execution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	_, err = getExecution(ctx, tx, execution.ID)
+	if err == nil {
+		return store.NewErrExecutionAlreadyExists(execution.ID)
+	}
+	if _, notFound := err.(store.ErrExecutionNotFound); !notFound {
+		return err
+	}
+	if err = store.ValidateNewExecution(ctx, execution); err != nil {
+		return fmt.Errorf("CreateExecution failure: %w", err)
+	}
+
+	executionData, err := json.Marshal(execution)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO execution (id, shard_id, state, version, create_time, update_time, executiondata)
+VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		execution.ID,
+		execution.Shard.ID(),
+		execution.State,
+		execution.Version,
+		execution.CreateTime.UnixNano(),
+		execution.UpdateTime.UnixNano(),
+		string(executionData),
+	)
+	if err != nil {
+		return err
+	}
+	if err = appendHistory(ctx, tx, execution, store.ExecutionStateUndefined, newExecutionComment); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) UpdateExecutionState(ctx context.Context, request store.UpdateExecutionStateRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	execution, err := getExecution(ctx, tx, request.ExecutionID)
+	if err != nil {
+		return err
+	}
+	if request.ExpectedState != store.ExecutionStateUndefined && execution.State != request.ExpectedState {
+		return store.NewErrInvalidExecutionState(request.ExecutionID, execution.State, request.ExpectedState)
+	}
+	if request.ExpectedVersion != 0 && execution.Version != request.ExpectedVersion {
+		return store.NewErrInvalidExecutionVersion(request.ExecutionID, execution.Version, request.ExpectedVersion)
+	}
+	if execution.State.IsTerminal() {
+		return store.NewErrExecutionAlreadyTerminal(request.ExecutionID, execution.State, request.NewState)
+	}
+	previousState := execution.State
+	execution.State = request.NewState
+	if request.NewRequesterNodeID != "" {
+		execution.RequesterNodeID = request.NewRequesterNodeID
+	}
+	if request.NewState == store.ExecutionStateCompleted {
+		execution.PublishedResult = request.PublishedResult
+		execution.ResultSize = request.ResultSize
+	}
+	execution.Version += 1
+	execution.UpdateTime = time.Now()
+
+	executionData, err := json.Marshal(execution)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+UPDATE execution SET state = $1, version = $2, update_time = $3, executiondata = $4 WHERE id = $5`,
+		execution.State,
+		execution.Version,
+		execution.UpdateTime.UnixNano(),
+		string(executionData),
+		execution.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if err = appendHistory(ctx, tx, execution, previousState, request.Comment); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) DeleteExecution(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM execution_history WHERE execution_id = $1`, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM execution WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func getExecution(ctx context.Context, db sqlClient, id string) (store.Execution, error) {
+	executions, err := queryExecutions(ctx, db, `
+SELECT executiondata, create_time, update_time FROM execution WHERE id = $1`, id)
+	if err != nil {
+		return store.Execution{}, err
+	}
+	if len(executions) == 0 {
+		return store.Execution{}, store.NewErrExecutionNotFound(id)
+	}
+	return executions[0], nil
+}
+
+// queryExecutions returns the executions selected by a query on their data, create time and update time.
+// Times are stored as unix nanoseconds so that they are read back exactly as they were written.
+func queryExecutions(ctx context.Context, db sqlClient, query string, args ...any) ([]store.Execution, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []store.Execution
+	for rows.Next() {
+		var executionData string
+		var createTime, updateTime int64
+		if err = rows.Scan(&executionData, &createTime, &updateTime); err != nil {
+			return nil, err
+		}
+		var execution store.Execution
+		if err = json.Unmarshal([]byte(executionData), &execution); err != nil {
+			return nil, err
+		}
+		execution.CreateTime = time.Unix(0, createTime)
+		execution.UpdateTime = time.Unix(0, updateTime)
+		executions = append(executions, execution)
+	}
+	return executions, rows.Err()
+}
+
+func appendHistory(
+	ctx context.Context,
+	db sqlClient,
+	updatedExecution store.Execution,
+	previousState store.ExecutionState,
+	comment string,
+) error {
+	_, err := db.ExecContext(ctx, `
+INSERT INTO execution_history (execution_id, seq, previous_state, new_state, new_version, comment, time)
+VALUES ($1, (select count(*) + 1 from execution_history where execution_id = $1), $2, $3, $4, $5, $6)`,
+		updatedExecution.ID,
+		previousState,
+		updatedExecution.State,
+		updatedExecution.Version,
+		comment,
+		updatedExecution.UpdateTime.UnixNano(),
+	)
+	return err
+}
+
+//go:embed migrations/*.sql
+var fs embed.FS
+
+func (s *Store) MigrateUp() error {
+	files, err := iofs.New(fs, "migrations")
+	if err != nil {
+		return err
+	}
+	migrations, err := migrate.NewWithSourceInstance(
+		"iofs", files, fmt.Sprintf("sqlite://%s?x-migrations-table=%s", s.filename, migrationsTable))
+	if err != nil {
+		return err
+	}
+	err = migrations.Up()
+	if err != migrate.ErrNoChange {
+		return err
+	}
+	return nil
+}
+
+// compile-time check that we implement the interface ExecutionStore
+var _ store.ExecutionStore = (*Store)(nil)
@@ -0,0 +1,21 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	"github.com/filecoin-project/bacalhau/pkg/compute/store/test"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSQLiteExecutionStoreSuite(t *testing.T) {
+	testingSuite := new(test.StoreSuite)
+	testingSuite.SetupHandler = func() store.ExecutionStore {
+		executionStore, err := NewStore(filepath.Join(testingSuite.T().TempDir(), "executions.db"))
+		require.NoError(testingSuite.T(), err)
+		return executionStore
+	}
+	suite.Run(t, testingSuite)
+}
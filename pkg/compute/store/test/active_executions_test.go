@@ -6,7 +6,6 @@ import (
 
 	"github.com/filecoin-project/bacalhau/pkg/compute/store"
 	"github.com/filecoin-project/bacalhau/pkg/compute/store/inmemory"
-	"github.com/filecoin-project/bacalhau/pkg/model"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
 )
@@ -60,21 +59,3 @@ func (s *Suite) TestGetActiveExecution_DoestExist() {
 	_, err := store.GetActiveExecution(context.Background(), s.executionStore, s.execution.Shard.ID())
 	s.ErrorAs(err, &store.ErrExecutionsNotFoundForShard{})
 }
-
-func newExecution() store.Execution {
-	return *store.NewExecution(
-		uuid.NewString(),
-		model.JobShard{
-			Job: &model.Job{
-				Metadata: model.Metadata{
-					ID: uuid.NewString(),
-				},
-			},
-			Index: 1,
-		},
-		"nodeID-1",
-		model.ResourceUsageData{
-			CPU:    1,
-			Memory: 2,
-		})
-}
@@ -18,6 +18,10 @@ type Execution struct {
 	CreateTime      time.Time
 	UpdateTime      time.Time
 	LatestComment   string
+	// PublishedResult and ResultSize are the result published by the execution once it completed, which is kept
+	// so that requester nodes can recover results whose callback was lost
+	PublishedResult model.StorageSpec
+	ResultSize      uint64
 }
 
 func NewExecution(
@@ -75,6 +79,11 @@ type UpdateExecutionStateRequest struct {
 	ExpectedState   ExecutionState
 	ExpectedVersion int
 	Comment         string
+	// NewRequesterNodeID redirects the callbacks of the execution to another requester node, if set
+	NewRequesterNodeID string
+	// PublishedResult and ResultSize are stored with executions that are updated to the completed state
+	PublishedResult model.StorageSpec
+	ResultSize      uint64
 }
 
 // ExecutionStore A metadata store of job executions handled by the current compute node
@@ -83,6 +92,8 @@ type ExecutionStore interface {
 	GetExecution(ctx context.Context, id string) (Execution, error)
 	// GetExecutions returns all the executions for a given shard
 	GetExecutions(ctx context.Context, sharedID string) ([]Execution, error)
+	// GetActiveExecutions returns all the executions that are not in a terminal state
+	GetActiveExecutions(ctx context.Context) ([]Execution, error)
 	// GetExecutionHistory returns the history of an execution
 	GetExecutionHistory(ctx context.Context, id string) ([]ExecutionHistory, error)
 	// CreateExecution creates a new execution for a given shard
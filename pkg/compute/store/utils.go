@@ -33,6 +33,16 @@ func GetActiveExecution(ctx context.Context, s ExecutionStore, shardID string) (
 	return activeExecution, nil
 }
 
+// GetRequesterNodeID returns the requester node the callbacks of an execution are sent to, which might have changed
+// since the execution was read if its job was adopted by another requester node.
+func GetRequesterNodeID(ctx context.Context, s ExecutionStore, execution Execution) string {
+	latest, err := s.GetExecution(ctx, execution.ID)
+	if err != nil {
+		return execution.RequesterNodeID
+	}
+	return latest.RequesterNodeID
+}
+
 func ValidateNewExecution(_ context.Context, execution Execution) error {
 	if execution.State != ExecutionStateCreated {
 		return NewErrInvalidExecutionState(execution.ID, execution.State, ExecutionStateCreated)
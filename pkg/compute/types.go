@@ -25,6 +25,12 @@ type Endpoint interface {
 	ResultRejected(context.Context, ResultRejectedRequest) (ResultRejectedResponse, error)
 	// CancelExecution cancels a job for a given executionID.
 	CancelExecution(context.Context, CancelExecutionRequest) (CancelExecutionResponse, error)
+	// ExecutionState returns the current state of a given executionID, which is used by requesters to reconcile
+	// their view of the execution after a restart.
+	ExecutionState(context.Context, ExecutionStateRequest) (ExecutionStateResponse, error)
+	// ExecutionLogs returns the output of a given executionID since an offset, which is used by requesters to
+	// stream the logs of executions while they run.
+	ExecutionLogs(context.Context, ExecutionLogsRequest) (ExecutionLogsResponse, error)
 }
 
 // Executor Backend service that is responsible for running and publishing executions.
@@ -138,6 +144,42 @@ type CancelExecutionResponse struct {
 	ExecutionMetadata
 }
 
+type ExecutionStateRequest struct {
+	RoutingMetadata
+	ExecutionID string
+	// RedirectCallbacks sends the callbacks of the execution to the source of the request from now on, which is
+	// used by requester nodes that adopt the jobs of a requester node that stopped
+	RedirectCallbacks bool
+}
+
+type ExecutionStateResponse struct {
+	ExecutionMetadata
+	State store.ExecutionState
+	// Unknown is true when the compute node has no record of the execution, in which case asking again won't help
+	Unknown bool
+	// PublishedResult and ResultSize are the result published by the execution once it completed
+	PublishedResult model.StorageSpec
+	ResultSize      uint64
+}
+
+type ExecutionLogsRequest struct {
+	RoutingMetadata
+	ExecutionID string
+	// Offset is the number of log entries that were already read
+	Offset int
+}
+
+type ExecutionLogsResponse struct {
+	ExecutionMetadata
+	Logs       []model.LogEntry
+	NextOffset int
+	// Finished is true when the execution will not write any more logs
+	Finished bool
+	// Available is false when the compute node no longer has the logs of the execution, such as when it
+	// completed a while ago or the node restarted
+	Available bool
+}
+
 ///////////////////////////////////
 // Callback result models
 ///////////////////////////////////
@@ -155,6 +197,8 @@ type PublishResult struct {
 	RoutingMetadata
 	ExecutionMetadata
 	PublishResult model.StorageSpec
+	// ResultSize is the size in bytes of the published result, before it was packaged
+	ResultSize uint64
 }
 
 // CancelResult Result of a job cancel that is returned to the caller through a Callback.
@@ -167,6 +211,8 @@ type ComputeError struct {
 	RoutingMetadata
 	ExecutionMetadata
 	Err string
+	// ErrorCode is the code of the bacerrors error that caused the failure, if any
+	ErrorCode string
 }
 
 func (e ComputeError) Error() string {
@@ -109,6 +109,16 @@ func GetEventTracerPath() string {
 	return filepath.Join(configPath, "bacalhau-event-tracer.json")
 }
 
+func GetJobStorePath() string {
+	configPath := GetConfigPath()
+	return filepath.Join(configPath, "bacalhau-jobs.db")
+}
+
+func GetExecutionStorePath() string {
+	configPath := GetConfigPath()
+	return filepath.Join(configPath, "bacalhau-executions.db")
+}
+
 func GetConfigPath() string {
 	suffix := ".bacalhau"
 	env := os.Getenv("BACALHAU_PATH")
@@ -135,6 +135,23 @@ func (c *Client) FollowLogs(ctx context.Context, id string) (stdout, stderr io.R
 	return stdoutReader, stderrReader, nil
 }
 
+// StreamLogs copies the output of a container to the given writers as it is written, until the container
+// stops or the context is canceled.
+func (c *Client) StreamLogs(ctx context.Context, id string, stdout, stderr io.Writer) error {
+	logsReader, err := c.ContainerLogs(ctx, id, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to get container logs")
+	}
+	defer closer.CloseWithLogOnError("logsReader", logsReader)
+
+	_, err = stdcopy.StdCopy(stdout, stderr, logsReader)
+	return err
+}
+
 func (c *Client) RemoveContainer(ctx context.Context, id string) error {
 	log.Ctx(ctx).Debug().Str("id", id).Msgf("Container Stop")
 	timeout := time.Millisecond * 100
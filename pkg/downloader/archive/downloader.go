@@ -0,0 +1,171 @@
+package archive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/filecoin-project/bacalhau/pkg/util/closer"
+	"github.com/filecoin-project/bacalhau/pkg/util/targzip"
+	"github.com/rs/zerolog/log"
+)
+
+// partialArchiveSuffix is appended to the download path of a result for the path its archive is downloaded to
+const partialArchiveSuffix = ".tar.gz.part"
+
+// maxFileSize lifts the limit targzip applies to each file, as results can be of any size
+const maxFileSize = datasize.ByteSize(math.MaxInt64)
+
+// Downloader downloads results that were published as a tar.gz archive to a HTTP server.
+type Downloader struct {
+	Settings *model.DownloaderSettings
+	client   *http.Client
+}
+
+func NewArchiveDownloader(settings *model.DownloaderSettings) *Downloader {
+	return &Downloader{
+		Settings: settings,
+		client:   http.DefaultClient,
+	}
+}
+
+func (archiveDownloader *Downloader) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (archiveDownloader *Downloader) FetchResult(ctx context.Context, result model.PublishedResult, downloadPath string) error {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/downloader/archive.Downloader.FetchResult")
+	defer span.End()
+
+	if result.Data.URL == "" {
+		return fmt.Errorf("result %s has no URL", result.Data.Name)
+	}
+	displayURL := result.Data.URL
+	if u, err := url.Parse(result.Data.URL); err == nil {
+		displayURL = u.Redacted()
+	}
+
+	innerCtx, cancel := context.WithDeadline(ctx, time.Now().Add(archiveDownloader.Settings.Timeout))
+	defer cancel()
+
+	// the archive is downloaded next to the download path, and kept until it has been extracted, so that an
+	// interrupted download can be resumed
+	if err := os.MkdirAll(filepath.Dir(downloadPath), model.DownloadFolderPerm); err != nil {
+		return err
+	}
+	archivePath := downloadPath + partialArchiveSuffix
+	log.Ctx(ctx).Debug().Msgf("Downloading result archive %s to '%s'...", displayURL, archivePath)
+	if err := archiveDownloader.fetchArchive(innerCtx, result.Data.URL, displayURL, archivePath); err != nil {
+		return err
+	}
+
+	err := extractArchive(archivePath, downloadPath)
+	if err != nil {
+		// the archive may have been replaced while it was downloaded, so download it from scratch next time
+		_ = os.Remove(archivePath)
+		return fmt.Errorf("failed to extract result archive %s: %w", displayURL, err)
+	}
+	return os.Remove(archivePath)
+}
+
+// fetchArchive downloads the archive to the given path, resuming from the content of the file if the server
+// supports range requests.
+func (archiveDownloader *Downloader) fetchArchive(ctx context.Context, archiveURL, displayURL, archivePath string) error {
+	file, err := os.OpenFile(archivePath, os.O_CREATE|os.O_WRONLY, model.DownloadFilePerm)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError("archive", file)
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, archiveURL, nil)
+	if err != nil {
+		return err
+	}
+	if archiveDownloader.Settings.HTTPUsername != "" || archiveDownloader.Settings.HTTPPassword != "" {
+		req.SetBasicAuth(archiveDownloader.Settings.HTTPUsername, archiveDownloader.Settings.HTTPPassword)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	res, err := archiveDownloader.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download result archive %s: %w", displayURL, err)
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "response", res.Body)
+
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+		if !strings.HasPrefix(res.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			_ = os.Remove(archivePath)
+			return fmt.Errorf("failed to resume download of result archive %s: unexpected range %q",
+				displayURL, res.Header.Get("Content-Range"))
+		}
+		log.Ctx(ctx).Debug().Msgf("Resuming download of result archive %s from byte %d", displayURL, offset)
+	case http.StatusOK:
+		// the server doesn't support range requests, so download the whole archive again
+		if err = file.Truncate(0); err != nil {
+			return err
+		}
+		if _, err = file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the archive was already fully downloaded
+		return nil
+	default:
+		return fmt.Errorf("failed to download result archive %s: %s", displayURL, res.Status)
+	}
+
+	if _, err = io.Copy(file, res.Body); err != nil {
+		return fmt.Errorf("failed to download result archive %s: %w", displayURL, err)
+	}
+	return file.Sync()
+}
+
+// extractArchive extracts the archive next to the download path, then moves its single top-level directory
+// into place.
+func extractArchive(archivePath, downloadPath string) error {
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError("archive", archive)
+
+	tempDir, err := os.MkdirTemp(filepath.Dir(downloadPath), ".archive-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir) //nolint:errcheck
+
+	extractDir := filepath.Join(tempDir, "archive")
+	if err = targzip.DecompressWithMaxSize(archive, extractDir, maxFileSize); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(extractDir)
+	if err != nil {
+		return err
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return fmt.Errorf("archive should contain a single directory")
+	}
+
+	// replace what a previous download left behind
+	if err = os.RemoveAll(downloadPath); err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(extractDir, entries[0].Name()), downloadPath)
+}
@@ -0,0 +1,157 @@
+//go:build unit || !integration
+
+package archive
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/util/targzip"
+	"github.com/stretchr/testify/require"
+)
+
+// newArchiveServer serves the archive at /result.tar.gz, and at /ranged/result.tar.gz with support for range
+// requests, whose Range headers are sent to the returned channel.
+func newArchiveServer(t *testing.T, archive []byte) (*httptest.Server, chan string) {
+	ranges := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/result.tar.gz":
+			_, _ = w.Write(archive)
+		case "/ranged/result.tar.gz":
+			ranges <- r.Header.Get("Range")
+			http.ServeContent(w, r, "result.tar.gz", time.Time{}, bytes.NewReader(archive))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, ranges
+}
+
+func newTestArchive(t *testing.T) []byte {
+	resultPath := filepath.Join(t.TempDir(), "result")
+	require.NoError(t, os.MkdirAll(filepath.Join(resultPath, "outputs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, model.DownloadFilenameStdout), []byte("hello"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, "outputs", "data.csv"), []byte("1,2"), 0644))
+	var buf bytes.Buffer
+	require.NoError(t, targzip.Compress(context.Background(), resultPath, &buf))
+	return buf.Bytes()
+}
+
+func TestFetchResult(t *testing.T) {
+	server, _ := newArchiveServer(t, newTestArchive(t))
+	downloader := NewArchiveDownloader(&model.DownloaderSettings{Timeout: time.Minute})
+
+	// the download path may already exist
+	downloadPath := filepath.Join(t.TempDir(), "cid")
+	require.NoError(t, os.Mkdir(downloadPath, 0755))
+	err := downloader.FetchResult(context.Background(), model.PublishedResult{
+		Data: model.StorageSpec{StorageSource: model.StorageSourceURLDownload, URL: server.URL + "/result.tar.gz"},
+	}, downloadPath)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(downloadPath, "stdout"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(content))
+	content, err = os.ReadFile(filepath.Join(downloadPath, "outputs", "data.csv"))
+	require.NoError(t, err)
+	require.Equal(t, "1,2", string(content))
+	entries, err := os.ReadDir(filepath.Dir(downloadPath))
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "temporary directories should be removed")
+}
+
+func TestFetchResultSendsCredentials(t *testing.T) {
+	archive := newTestArchive(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write(archive)
+	}))
+	t.Cleanup(server.Close)
+	result := model.PublishedResult{
+		Data: model.StorageSpec{StorageSource: model.StorageSourceURLDownload, URL: server.URL + "/result.tar.gz"},
+	}
+
+	downloader := NewArchiveDownloader(&model.DownloaderSettings{Timeout: time.Minute})
+	require.Error(t, downloader.FetchResult(context.Background(), result, filepath.Join(t.TempDir(), "cid")))
+
+	downloader = NewArchiveDownloader(&model.DownloaderSettings{
+		Timeout:      time.Minute,
+		HTTPUsername: "user",
+		HTTPPassword: "secret",
+	})
+	downloadPath := filepath.Join(t.TempDir(), "cid")
+	require.NoError(t, downloader.FetchResult(context.Background(), result, downloadPath))
+	content, err := os.ReadFile(filepath.Join(downloadPath, "stdout"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(content))
+}
+
+func TestFetchResultResumes(t *testing.T) {
+	archive := newTestArchive(t)
+	server, ranges := newArchiveServer(t, archive)
+	downloader := NewArchiveDownloader(&model.DownloaderSettings{Timeout: time.Minute})
+
+	for _, tc := range []struct {
+		name          string
+		path          string
+		partial       []byte
+		expectedRange string
+	}{
+		{name: "resumed", path: "/ranged/result.tar.gz", partial: archive[:10], expectedRange: "bytes=10-"},
+		{name: "complete", path: "/ranged/result.tar.gz", partial: archive, expectedRange: fmt.Sprintf("bytes=%d-", len(archive))},
+		{name: "no partial archive", path: "/ranged/result.tar.gz", expectedRange: ""},
+		{name: "ranges not supported", path: "/result.tar.gz", partial: archive[:10]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			downloadPath := filepath.Join(t.TempDir(), "cid")
+			if tc.partial != nil {
+				require.NoError(t, os.WriteFile(downloadPath+partialArchiveSuffix, tc.partial, 0644))
+			}
+			err := downloader.FetchResult(context.Background(), model.PublishedResult{
+				Data: model.StorageSpec{StorageSource: model.StorageSourceURLDownload, URL: server.URL + tc.path},
+			}, downloadPath)
+			require.NoError(t, err)
+
+			content, err := os.ReadFile(filepath.Join(downloadPath, "outputs", "data.csv"))
+			require.NoError(t, err)
+			require.Equal(t, "1,2", string(content))
+			require.NoFileExists(t, downloadPath+partialArchiveSuffix)
+			if tc.path == "/ranged/result.tar.gz" {
+				require.Equal(t, tc.expectedRange, <-ranges)
+			}
+		})
+	}
+}
+
+func TestFetchResultErrors(t *testing.T) {
+	server, _ := newArchiveServer(t, []byte("not an archive"))
+	downloader := NewArchiveDownloader(&model.DownloaderSettings{Timeout: time.Minute})
+	for _, tc := range []struct {
+		name string
+		url  string
+	}{
+		{name: "no URL", url: ""},
+		{name: "not found", url: server.URL + "/missing.tar.gz"},
+		{name: "invalid archive", url: server.URL + "/result.tar.gz"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := downloader.FetchResult(context.Background(), model.PublishedResult{
+				Data: model.StorageSpec{StorageSource: model.StorageSourceURLDownload, URL: tc.url},
+			}, filepath.Join(t.TempDir(), "cid"))
+			require.Error(t, err)
+		})
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/filecoin-project/bacalhau/pkg/manifest"
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/packaging"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 	cp "github.com/n-marshall/go-cp"
 	"github.com/rs/zerolog/log"
+	"go.ptx.dk/multierrgroup"
 )
 
 // specialFiles - i.e. anything that is not a volume
@@ -19,18 +22,21 @@ var specialFiles = map[string]bool{
 	model.DownloadFilenameStdout:   true,
 	model.DownloadFilenameStderr:   true,
 	model.DownloadFilenameExitCode: false,
+	model.DownloadFilenameManifest: false,
 }
 
-// * make a temp dir
-// * download all cids into temp dir
 // * ensure top level output dir exists
-// * iterate over each shard
+// * load the download state of the output dir
+// * download the selected results that are missing into the raw dir, concurrently
+// * verify each downloaded result against its manifest and record it in the download state
+// * iterate over each downloaded shard
 // * make new folder for shard logs
 // * copy stdout, stderr, exitCode
 // * append stdout, stderr to global log
 // * iterate over each output volume
 // * make new folder for output volume
 // * iterate over each shard and merge files in output folder to results dir
+// * optionally merge the results of all shards into a single tree
 func DownloadJob( //nolint:funlen,gocyclo
 	ctx context.Context,
 	// these are the outputs named in the job spec
@@ -63,82 +69,246 @@ func DownloadJob( //nolint:funlen,gocyclo
 		return fmt.Errorf("output dir does not exist: %s", resultsOutputDir)
 	}
 
+	selectedResults, err := selectResults(outputVolumes, publishedShardResults, settings)
+	if err != nil {
+		return err
+	}
+
 	err = os.MkdirAll(filepath.Join(resultsOutputDir, model.DownloadCIDsFolderName), model.DownloadFolderPerm)
 	if err != nil {
 		return err
 	}
 
-	log.Ctx(ctx).Info().Msgf("Found %d Result shards, downloading to: %s.", len(publishedShardResults), resultsOutputDir)
+	// the download state of previous downloads to the output dir, so that we only download missing results
+	state, err := loadDownloadState(resultsOutputDir)
+	if err != nil {
+		return err
+	}
 
-	// each shard context understands the various folder paths
-	// and other data it needs to download and resolve itself
-	shardContexts := []shardCIDContext{}
-	// keep track of which cids we have downloaded to avoid
-	// downloading the same cid multiple times
-	downloadedCids := map[string]bool{}
+	log.Ctx(ctx).Info().Msgf("Found %d Result shards, downloading %d to: %s.",
+		len(publishedShardResults), len(selectedResults), resultsOutputDir)
 
 	// the base folder for globally merged volumes
 	volumeDir := filepath.Join(resultsOutputDir, model.DownloadVolumesFolderName)
-	err = os.Mkdir(volumeDir, model.DownloadFolderPerm)
+	err = os.MkdirAll(volumeDir, model.DownloadFolderPerm)
 	if err != nil {
 		return err
 	}
 
 	// ensure we have each of the top level merged volumes
 	for _, outputVolume := range outputVolumes {
+		if !settings.IncludesOutputVolume(outputVolume.Name) {
+			continue
+		}
 		err = os.MkdirAll(filepath.Join(volumeDir, outputVolume.Name), model.DownloadFolderPerm)
 		if err != nil {
 			return err
 		}
 	}
 
-	// loop over shard results - create their cid and shard folders
-	// then add to an array of contexts
+	// download each unique result (shards can share the same CID) with a limited number of concurrent downloads,
+	// and keep downloading the other results if one fails, so that downloading again only fetches the failed ones
+	parallelism := settings.Parallelism
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+	semaphore := make(chan struct{}, parallelism)
+	waitgroup := multierrgroup.Group{}
+	downloadedCids := map[string]bool{}
+	for _, shardResult := range selectedResults {
+		shardResult := shardResult
+		resultID := publishedResultID(shardResult)
+		if downloadedCids[resultID] {
+			continue
+		}
+		downloadedCids[resultID] = true
+
+		waitgroup.Go(func() error {
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			defer func() { <-semaphore }()
+
+			cidDownloadDir := filepath.Join(resultsOutputDir, model.DownloadCIDsFolderName, resultID)
+			return downloadResult(ctx, shardResult, cidDownloadDir, downloadProvider, settings, state)
+		})
+	}
+	downloadErr := waitgroup.Wait()
+
+	// now that we have downloaded the unique CIDs of the results
+	// we want to re-construct folders for each shard and volume,
+	// including the shards that were downloaded previously
+	err = resetGlobalLogs(volumeDir)
+	if err != nil {
+		return err
+	}
+	var downloadedShards []shardCIDContext
 	for _, shardResult := range publishedShardResults {
-		cidDownloadDir := filepath.Join(resultsOutputDir, model.DownloadCIDsFolderName, shardResult.Data.CID)
-		shardDir := filepath.Join(
-			resultsOutputDir,
-			model.DownloadShardsFolderName,
-			fmt.Sprintf("%d_node_%s", shardResult.ShardIndex, system.GetShortID(shardResult.NodeID)),
-		)
-
-		shardContexts = append(shardContexts, shardCIDContext{
+		resultID := publishedResultID(shardResult)
+		if !state.isDownloaded(resultID) {
+			continue
+		}
+		shardContext := shardCIDContext{
 			Result:         shardResult,
 			OutputVolumes:  outputVolumes,
 			RootDir:        resultsOutputDir,
-			CIDDownloadDir: cidDownloadDir,
-			ShardDir:       shardDir,
+			CIDDownloadDir: filepath.Join(resultsOutputDir, model.DownloadCIDsFolderName, resultID),
+			ShardDir:       filepath.Join(resultsOutputDir, model.DownloadShardsFolderName, shardName(shardResult)),
 			VolumeDir:      volumeDir,
-		})
+		}
+		err = moveShardData(ctx, shardContext, settings)
+		if err != nil {
+			return err
+		}
+		downloadedShards = append(downloadedShards, shardContext)
+	}
 
-		// get downloader for each shard and download it's CID
-		// (if we have not already done so)
-		downloader, err := downloadProvider.Get(ctx, shardResult.Data.StorageSource) //nolint
-		_, ok := downloadedCids[shardResult.Data.CID]
-		if !ok {
-			err = downloader.FetchResult(ctx, shardResult, cidDownloadDir)
-			if err != nil {
-				return err
+	if settings.Merge {
+		err = mergeResults(ctx, downloadedShards, settings)
+		if err != nil {
+			return err
+		}
+	}
+
+	return downloadErr
+}
+
+// selectResults returns the published results of the shards selected by the settings, and checks that the
+// selected output volumes exist.
+func selectResults(
+	outputVolumes []model.StorageSpec,
+	publishedShardResults []model.PublishedResult,
+	settings *model.DownloaderSettings,
+) ([]model.PublishedResult, error) {
+	for _, name := range settings.OutputVolumeNames {
+		found := false
+		for _, outputVolume := range outputVolumes {
+			found = found || outputVolume.Name == name
+		}
+		if !found {
+			return nil, fmt.Errorf("job has no output volume named %q", name)
+		}
+	}
+
+	if len(settings.ShardIndexes) == 0 {
+		return publishedShardResults, nil
+	}
+	var selected []model.PublishedResult
+	for _, shardIndex := range settings.ShardIndexes {
+		found := false
+		for _, shardResult := range publishedShardResults {
+			if shardResult.ShardIndex == shardIndex {
+				selected = append(selected, shardResult)
+				found = true
 			}
-			downloadedCids[shardResult.Data.CID] = true
+		}
+		if !found {
+			return nil, fmt.Errorf("job has no results for shard %d", shardIndex)
 		}
 	}
+	return selected, nil
+}
 
-	// now that we have downloaded the unique CIDs of the results
-	// we want to re-construct folders for each shard and volume
-	for _, shardContext := range shardContexts {
-		err = moveShardData(ctx, shardContext)
-		if err != nil {
+// downloadResult downloads a result to its folder, unless it was already downloaded and still matches its
+// manifest, and records it in the download state.
+func downloadResult(
+	ctx context.Context,
+	result model.PublishedResult,
+	downloadDir string,
+	downloadProvider DownloaderProvider,
+	settings *model.DownloaderSettings,
+	state *downloadState,
+) error {
+	resultID := publishedResultID(result)
+	if state.isComplete(resultID, settings) {
+		err := verifyResult(ctx, result, downloadDir, settings)
+		if err == nil {
+			log.Ctx(ctx).Debug().Msgf("Result %s of node %s was already downloaded", resultID, result.NodeID)
+			return nil
+		}
+		log.Ctx(ctx).Warn().Err(err).Msgf("Downloading result %s of node %s again", resultID, result.NodeID)
+	}
+
+	err := state.remove(resultID)
+	if err != nil {
+		return err
+	}
+	downloader, err := downloadProvider.Get(ctx, result.Data.StorageSource)
+	if err != nil {
+		return err
+	}
+	err = downloader.FetchResult(ctx, result, downloadDir)
+	if err != nil {
+		return fmt.Errorf("failed to download result %s of node %s: %w", resultID, result.NodeID, err)
+	}
+	unpacked, err := packaging.Unpack(downloadDir)
+	if err != nil {
+		return err
+	}
+	if unpacked {
+		log.Ctx(ctx).Debug().Msgf("Unpacked packaged result %s of node %s", resultID, result.NodeID)
+	}
+	err = verifyResult(ctx, result, downloadDir, settings)
+	if err != nil {
+		return err
+	}
+	return state.complete(resultID, result, settings)
+}
+
+// publishedResultID returns the name of the folder a published result is downloaded to. Results that are not
+// content addressed, such as results published to S3, are named after the shard and node that published them.
+func publishedResultID(result model.PublishedResult) string {
+	if result.Data.CID != "" {
+		return result.Data.CID
+	}
+	return result.Data.Name
+}
+
+// verifyResult checks a downloaded result against the manifest signed by the node that published it. Results
+// without a manifest are rejected, unless the settings allow them for results published by older nodes, in which
+// case they are only reported.
+func verifyResult(ctx context.Context, result model.PublishedResult, downloadDir string, settings *model.DownloaderSettings) error {
+	_, err := os.Stat(filepath.Join(downloadDir, model.DownloadFilenameManifest))
+	if os.IsNotExist(err) {
+		if _, err = os.Stat(downloadDir); err != nil {
 			return err
 		}
+		if !settings.AllowMissingManifest {
+			return fmt.Errorf("downloaded result %s of node %s has no manifest, its content can't be verified",
+				publishedResultID(result), result.NodeID)
+		}
+		log.Ctx(ctx).Warn().Msgf("Result %s of node %s has no manifest, its content can't be verified",
+			publishedResultID(result), result.NodeID)
+		return nil
+	}
+	if _, err = manifest.VerifySubset(downloadDir, result.NodeID, settings.IncludesPath); err != nil {
+		return fmt.Errorf("downloaded result %s of node %s doesn't match its manifest: %w",
+			publishedResultID(result), result.NodeID, err)
 	}
+	log.Ctx(ctx).Debug().Msgf("Verified result %s of node %s against its manifest", publishedResultID(result), result.NodeID)
+	return nil
+}
 
+// resetGlobalLogs removes the global logs, which are rebuilt from the logs of all downloaded shards.
+func resetGlobalLogs(volumeDir string) error {
+	for name, shouldAppendLogs := range specialFiles {
+		if !shouldAppendLogs {
+			continue
+		}
+		err := os.Remove(filepath.Join(volumeDir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
 	return nil
 }
 
 func moveShardData(
 	ctx context.Context,
 	shardContext shardCIDContext,
+	settings *model.DownloaderSettings,
 ) error {
 	err := os.MkdirAll(shardContext.ShardDir, model.DownloadFolderPerm)
 	if err != nil {
@@ -173,6 +343,11 @@ func moveShardData(
 		// are we dealing with a special case file?
 		shouldAppendLogs, isSpecialFile := specialFiles[basePath]
 
+		// skip the output volumes that are not downloaded
+		if d.IsDir() && filepath.Dir(basePath) == "." && !settings.IncludesOutputVolume(basePath) {
+			return filepath.SkipDir
+		}
+
 		if d.IsDir() {
 			err = os.MkdirAll(shardTargetPath, model.DownloadFolderPerm)
 			if err != nil {
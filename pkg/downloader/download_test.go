@@ -5,9 +5,11 @@ package downloader
 import (
 	"context"
 	"crypto/rand"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	ipfs2 "github.com/filecoin-project/bacalhau/pkg/downloader/ipfs"
@@ -15,6 +17,8 @@ import (
 
 	"github.com/filecoin-project/bacalhau/pkg/logger"
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/packaging"
+	"github.com/filecoin-project/bacalhau/pkg/storage/util"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -63,6 +67,8 @@ func (ds *DownloaderSuite) SetupTest() {
 		Timeout:        model.DefaultIPFSTimeout,
 		OutputDir:      testOutputDir,
 		IPFSSwarmAddrs: strings.Join(swarm, ","),
+		// the results added to IPFS by these tests are not published by a node, so they have no manifest
+		AllowMissingManifest: true,
 	}
 
 	ds.downloadProvider = model.NewMappedProvider(
@@ -317,3 +323,157 @@ func (ds *DownloaderSuite) TestCustomVolumeNames() {
 
 	requireFileExists(ds, model.DownloadVolumesFolderName, "secrets", "private.pem")
 }
+
+// fakeDownloader copies results from directories named after their CID, and counts the downloads of each result.
+type fakeDownloader struct {
+	mu        sync.Mutex
+	sources   map[string]string
+	downloads map[string]int
+	failing   map[string]bool
+}
+
+func (f *fakeDownloader) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeDownloader) FetchResult(_ context.Context, result model.PublishedResult, downloadPath string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.downloads[result.Data.CID]++
+	if f.failing[result.Data.CID] {
+		return fmt.Errorf("failed to download %s", result.Data.CID)
+	}
+	if err := os.RemoveAll(downloadPath); err != nil {
+		return err
+	}
+	return util.CopyDir(f.sources[result.Data.CID], downloadPath)
+}
+
+// newFakeResults creates results without a manifest, so they are only downloaded if the settings allow missing
+// manifests.
+func newFakeResults(t *testing.T, shards int) ([]model.PublishedResult, *fakeDownloader) {
+	downloader := &fakeDownloader{sources: map[string]string{}, downloads: map[string]int{}, failing: map[string]bool{}}
+	var results []model.PublishedResult
+	for i := 0; i < shards; i++ {
+		cid := fmt.Sprintf("cid-%d", i)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, model.DownloadFilenameStdout), []byte(fmt.Sprintf("stdout %d\n", i)), 0644))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "outputs"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "outputs", fmt.Sprintf("data%d.csv", i)), []byte("1,2"), 0644))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "logs"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "logs", fmt.Sprintf("run%d.log", i)), []byte("log"), 0644))
+		downloader.sources[cid] = dir
+		results = append(results, model.PublishedResult{
+			NodeID:     "testnode",
+			ShardIndex: i,
+			Data:       model.StorageSpec{StorageSource: model.StorageSourceIPFS, Name: fmt.Sprintf("shard-%d", i), CID: cid},
+		})
+	}
+	return results, downloader
+}
+
+var fakeOutputVolumes = []model.StorageSpec{
+	{StorageSource: model.StorageSourceIPFS, Name: "outputs", Path: "/outputs"},
+	{StorageSource: model.StorageSourceIPFS, Name: "logs", Path: "/logs"},
+}
+
+func TestDownloadJobResumes(t *testing.T) {
+	results, downloader := newFakeResults(t, 3)
+	provider := model.NewMappedProvider(map[model.StorageSourceType]Downloader{model.StorageSourceIPFS: downloader})
+	settings := &model.DownloaderSettings{OutputDir: t.TempDir(), Parallelism: 2, AllowMissingManifest: true}
+
+	// the first download fails for one shard, but downloads the others
+	downloader.failing["cid-1"] = true
+	err := DownloadJob(context.Background(), fakeOutputVolumes, results, provider, settings)
+	require.Error(t, err)
+	require.FileExists(t, filepath.Join(settings.OutputDir, model.DownloadVolumesFolderName, "outputs", "data0.csv"))
+	require.NoFileExists(t, filepath.Join(settings.OutputDir, model.DownloadVolumesFolderName, "outputs", "data1.csv"))
+
+	// downloading again only downloads the failed shard, and rebuilds the global logs in shard order
+	downloader.failing["cid-1"] = false
+	err = DownloadJob(context.Background(), fakeOutputVolumes, results, provider, settings)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"cid-0": 1, "cid-1": 2, "cid-2": 1}, downloader.downloads)
+	stdout, err := os.ReadFile(filepath.Join(settings.OutputDir, model.DownloadVolumesFolderName, model.DownloadFilenameStdout))
+	require.NoError(t, err)
+	require.Equal(t, "stdout 0\nstdout 1\nstdout 2\n", string(stdout))
+
+	// results that no longer match what was downloaded are downloaded again
+	require.NoError(t, os.RemoveAll(filepath.Join(settings.OutputDir, model.DownloadCIDsFolderName, "cid-2")))
+	err = DownloadJob(context.Background(), fakeOutputVolumes, results, provider, settings)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"cid-0": 1, "cid-1": 2, "cid-2": 2}, downloader.downloads)
+}
+
+func TestDownloadJobSelection(t *testing.T) {
+	results, downloader := newFakeResults(t, 3)
+	provider := model.NewMappedProvider(map[model.StorageSourceType]Downloader{model.StorageSourceIPFS: downloader})
+	settings := &model.DownloaderSettings{
+		OutputDir:            t.TempDir(),
+		ShardIndexes:         []int{0, 2},
+		OutputVolumeNames:    []string{"outputs"},
+		AllowMissingManifest: true,
+	}
+
+	err := DownloadJob(context.Background(), fakeOutputVolumes, results, provider, settings)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"cid-0": 1, "cid-2": 1}, downloader.downloads)
+	volumeDir := filepath.Join(settings.OutputDir, model.DownloadVolumesFolderName)
+	require.FileExists(t, filepath.Join(volumeDir, "outputs", "data0.csv"))
+	require.FileExists(t, filepath.Join(volumeDir, "outputs", "data2.csv"))
+	require.NoDirExists(t, filepath.Join(volumeDir, "logs"))
+
+	// selecting another output volume downloads the results again
+	settings.OutputVolumeNames = []string{"logs"}
+	err = DownloadJob(context.Background(), fakeOutputVolumes, results, provider, settings)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"cid-0": 2, "cid-2": 2}, downloader.downloads)
+	require.FileExists(t, filepath.Join(volumeDir, "logs", "run0.log"))
+
+	// unknown shards and volumes are rejected
+	settings.ShardIndexes = []int{3}
+	require.Error(t, DownloadJob(context.Background(), fakeOutputVolumes, results, provider, settings))
+	settings.ShardIndexes = nil
+	settings.OutputVolumeNames = []string{"missing"}
+	require.Error(t, DownloadJob(context.Background(), fakeOutputVolumes, results, provider, settings))
+}
+
+func TestDownloadJobUnpacksPackagedResults(t *testing.T) {
+	results, downloader := newFakeResults(t, 2)
+	for i, resultPackaging := range []model.ResultPackaging{model.ResultPackagingTarZst, model.ResultPackagingZip} {
+		cid := results[i].Data.CID
+		packagedDir := t.TempDir()
+		_, err := packaging.Pack(context.Background(), resultPackaging, downloader.sources[cid], packagedDir)
+		require.NoError(t, err)
+		downloader.sources[cid] = packagedDir
+	}
+	provider := model.NewMappedProvider(map[model.StorageSourceType]Downloader{model.StorageSourceIPFS: downloader})
+	settings := &model.DownloaderSettings{OutputDir: t.TempDir(), AllowMissingManifest: true}
+
+	err := DownloadJob(context.Background(), fakeOutputVolumes, results, provider, settings)
+	require.NoError(t, err)
+	volumeDir := filepath.Join(settings.OutputDir, model.DownloadVolumesFolderName)
+	require.FileExists(t, filepath.Join(volumeDir, "outputs", "data0.csv"))
+	require.FileExists(t, filepath.Join(volumeDir, "logs", "run1.log"))
+	stdout, err := os.ReadFile(filepath.Join(volumeDir, model.DownloadFilenameStdout))
+	require.NoError(t, err)
+	require.Equal(t, "stdout 0\nstdout 1\n", string(stdout))
+	require.NoFileExists(t, filepath.Join(settings.OutputDir, model.DownloadCIDsFolderName, "cid-0",
+		packaging.ArchiveName(model.ResultPackagingTarZst)))
+}
+
+func TestDownloadJobRequiresManifest(t *testing.T) {
+	results, downloader := newFakeResults(t, 1)
+	provider := model.NewMappedProvider(map[model.StorageSourceType]Downloader{model.StorageSourceIPFS: downloader})
+	settings := &model.DownloaderSettings{OutputDir: t.TempDir()}
+
+	err := DownloadJob(context.Background(), fakeOutputVolumes, results, provider, settings)
+	require.ErrorContains(t, err, "has no manifest")
+	require.NoFileExists(t, filepath.Join(settings.OutputDir, model.DownloadVolumesFolderName, "outputs", "data0.csv"))
+
+	// results without a manifest, such as results of older nodes, are downloaded if allowed
+	settings.AllowMissingManifest = true
+	err = DownloadJob(context.Background(), fakeOutputVolumes, results, provider, settings)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(settings.OutputDir, model.DownloadVolumesFolderName, "outputs", "data0.csv"))
+}
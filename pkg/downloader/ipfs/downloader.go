@@ -3,6 +3,7 @@ package ipfs
 import (
 	"context"
 	"errors"
+	"os"
 	"strings"
 	"time"
 
@@ -58,6 +59,10 @@ func (ipfsDownloader *Downloader) FetchResult(ctx context.Context, result model.
 		innerCtx, cancel := context.WithDeadline(ctx, time.Now().Add(ipfsDownloader.settings.Timeout))
 		defer cancel()
 
+		// IPFS downloads can't be resumed, so remove what an interrupted download left behind
+		if err := os.RemoveAll(downloadPath); err != nil {
+			return err
+		}
 		return ipfsClient.Get(innerCtx, result.Data.CID, downloadPath)
 	}()
 
@@ -0,0 +1,68 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/storage/util"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/rs/zerolog/log"
+)
+
+// Downloader copies results that were published to a local directory, such as a shared NFS mount, which must
+// be mounted on the client at the root configured in the downloader settings.
+type Downloader struct {
+	Settings *model.DownloaderSettings
+}
+
+func NewLocalDownloader(settings *model.DownloaderSettings) *Downloader {
+	return &Downloader{
+		Settings: settings,
+	}
+}
+
+func (localDownloader *Downloader) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (localDownloader *Downloader) FetchResult(ctx context.Context, result model.PublishedResult, downloadPath string) error {
+	_, span := system.NewSpan(ctx, system.GetTracer(), "pkg/downloader/local.Downloader.FetchResult")
+	defer span.End()
+
+	root := localDownloader.Settings.LocalPublisherRoot
+	if root == "" {
+		return fmt.Errorf("result %s was published to a local directory, but no local publisher root is set", result.Data.Name)
+	}
+	if result.Data.SourcePath == "" {
+		return fmt.Errorf("result %s has no source path", result.Data.Name)
+	}
+	source := filepath.Join(root, filepath.FromSlash(result.Data.SourcePath))
+	if relativePath, err := filepath.Rel(root, source); err != nil || relativePath == ".." ||
+		strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("result %s has source path %q outside of the local publisher root", result.Data.Name, result.Data.SourcePath)
+	}
+
+	log.Ctx(ctx).Debug().Msgf("Copying result '%s' to '%s'...", source, downloadPath)
+	entries, err := os.ReadDir(source)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(downloadPath, model.DownloadFolderPerm); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		// output volumes are the directories of the result
+		if entry.IsDir() && !localDownloader.Settings.IncludesOutputVolume(entry.Name()) {
+			continue
+		}
+		err = util.CopyDir(filepath.Join(source, entry.Name()), filepath.Join(downloadPath, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+//go:build unit || !integration
+
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchResult(t *testing.T) {
+	root := t.TempDir()
+	resultPath := filepath.Join(root, "job-id", "0", "node-id")
+	require.NoError(t, os.MkdirAll(filepath.Join(resultPath, "outputs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, model.DownloadFilenameStdout), []byte("hello"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, "outputs", "data.csv"), []byte("1,2"), 0644))
+
+	downloader := NewLocalDownloader(&model.DownloaderSettings{LocalPublisherRoot: root})
+	downloadPath := filepath.Join(t.TempDir(), "cid")
+	err := downloader.FetchResult(context.Background(), model.PublishedResult{
+		Data: model.StorageSpec{StorageSource: model.StorageSourceLocalDirectory, SourcePath: "job-id/0/node-id"},
+	}, downloadPath)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(downloadPath, "stdout"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(content))
+	content, err = os.ReadFile(filepath.Join(downloadPath, "outputs", "data.csv"))
+	require.NoError(t, err)
+	require.Equal(t, "1,2", string(content))
+}
+
+func TestFetchResultSelectedVolumes(t *testing.T) {
+	root := t.TempDir()
+	resultPath := filepath.Join(root, "job-id", "0", "node-id")
+	require.NoError(t, os.MkdirAll(filepath.Join(resultPath, "outputs"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(resultPath, "logs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, model.DownloadFilenameStdout), []byte("hello"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, "outputs", "data.csv"), []byte("1,2"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, "logs", "run.log"), []byte("log"), 0644))
+
+	downloader := NewLocalDownloader(&model.DownloaderSettings{LocalPublisherRoot: root, OutputVolumeNames: []string{"outputs"}})
+	downloadPath := filepath.Join(t.TempDir(), "cid")
+	err := downloader.FetchResult(context.Background(), model.PublishedResult{
+		Data: model.StorageSpec{StorageSource: model.StorageSourceLocalDirectory, SourcePath: "job-id/0/node-id"},
+	}, downloadPath)
+	require.NoError(t, err)
+
+	require.FileExists(t, filepath.Join(downloadPath, "stdout"))
+	require.FileExists(t, filepath.Join(downloadPath, "outputs", "data.csv"))
+	require.NoDirExists(t, filepath.Join(downloadPath, "logs"))
+}
+
+func TestFetchResultErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		root       string
+		sourcePath string
+	}{
+		{name: "no root", root: "", sourcePath: "job-id/0/node-id"},
+		{name: "no source path", root: t.TempDir(), sourcePath: ""},
+		{name: "outside root", root: t.TempDir(), sourcePath: "../job-id/0/node-id"},
+		{name: "missing", root: t.TempDir(), sourcePath: "job-id/0/node-id"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			downloader := NewLocalDownloader(&model.DownloaderSettings{LocalPublisherRoot: tc.root})
+			err := downloader.FetchResult(context.Background(), model.PublishedResult{
+				Data: model.StorageSpec{StorageSource: model.StorageSourceLocalDirectory, SourcePath: tc.sourcePath},
+			}, filepath.Join(t.TempDir(), "cid"))
+			require.Error(t, err)
+		})
+	}
+}
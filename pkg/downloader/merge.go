@@ -0,0 +1,265 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/filecoin-project/bacalhau/pkg/util/closer"
+	cp "github.com/n-marshall/go-cp"
+	"github.com/rs/zerolog/log"
+)
+
+// mergeSummary describes how the results of the shards of a job were merged.
+type mergeSummary struct {
+	Shards    []shardSummary  `json:"Shards"`
+	Conflicts []mergeConflict `json:"Conflicts,omitempty"`
+}
+
+type shardSummary struct {
+	ShardIndex int    `json:"ShardIndex"`
+	NodeID     string `json:"NodeID"`
+	// ExitCode is nil if the result has no exit code
+	ExitCode *int `json:"ExitCode"`
+}
+
+// mergeConflict is a file of an output volume that more than one shard produced with different content, where
+// the file of the later shard was renamed.
+type mergeConflict struct {
+	Path       string `json:"Path"`
+	ShardIndex int    `json:"ShardIndex"`
+	NodeID     string `json:"NodeID"`
+	RenamedTo  string `json:"RenamedTo"`
+}
+
+// mergeResults writes a single tree that combines the downloaded results of all shards, ordered by shard index:
+// * stdout and stderr are concatenated, with a header before the logs of each shard
+// * the output volumes are merged, and files that shards produced with different content are renamed after
+// the shard that produced them
+// * a summary lists the exit code of each shard and the renamed files
+// The tree is rebuilt from scratch, so that it includes the results of previous downloads.
+func mergeResults(ctx context.Context, shardContexts []shardCIDContext, settings *model.DownloaderSettings) error {
+	if len(shardContexts) == 0 {
+		return nil
+	}
+	mergedDir := filepath.Join(shardContexts[0].RootDir, model.DownloadMergedFolderName)
+	if err := os.RemoveAll(mergedDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(mergedDir, model.DownloadFolderPerm); err != nil {
+		return err
+	}
+
+	sorted := make([]shardCIDContext, len(shardContexts))
+	copy(sorted, shardContexts)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Result.ShardIndex != sorted[j].Result.ShardIndex {
+			return sorted[i].Result.ShardIndex < sorted[j].Result.ShardIndex
+		}
+		return sorted[i].Result.NodeID < sorted[j].Result.NodeID
+	})
+
+	summary := mergeSummary{}
+	// the shard that produced each merged file, by relative path
+	origins := map[string]shardCIDContext{}
+	for _, shardContext := range sorted {
+		for _, name := range []string{model.DownloadFilenameStdout, model.DownloadFilenameStderr} {
+			err := appendShardLog(shardContext, name, filepath.Join(mergedDir, name))
+			if err != nil {
+				return err
+			}
+		}
+
+		exitCode, err := readExitCode(shardContext.CIDDownloadDir)
+		if err != nil {
+			return err
+		}
+		summary.Shards = append(summary.Shards, shardSummary{
+			ShardIndex: shardContext.Result.ShardIndex,
+			NodeID:     shardContext.Result.NodeID,
+			ExitCode:   exitCode,
+		})
+
+		conflicts, err := mergeVolumes(shardContext, mergedDir, origins, settings)
+		if err != nil {
+			return err
+		}
+		summary.Conflicts = append(summary.Conflicts, conflicts...)
+	}
+
+	failed := 0
+	for _, shard := range summary.Shards {
+		if shard.ExitCode != nil && *shard.ExitCode != 0 {
+			failed++
+		}
+	}
+	if failed > 0 {
+		log.Ctx(ctx).Warn().Msgf("%d of %d shards exited with a non-zero exit code, see %s",
+			failed, len(summary.Shards), filepath.Join(mergedDir, model.DownloadFilenameSummary))
+	}
+	if len(summary.Conflicts) > 0 {
+		log.Ctx(ctx).Warn().Msgf("%d files were produced by more than one shard and were renamed, see %s",
+			len(summary.Conflicts), filepath.Join(mergedDir, model.DownloadFilenameSummary))
+	}
+
+	content, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(mergedDir, model.DownloadFilenameSummary), content, model.DownloadFilePerm)
+}
+
+// shardName names a shard result like its folder in the per shard folder.
+func shardName(result model.PublishedResult) string {
+	return fmt.Sprintf("%d_node_%s", result.ShardIndex, system.GetShortID(result.NodeID))
+}
+
+// appendShardLog appends a log of a shard to the merged log, after a header naming the shard.
+func appendShardLog(shardContext shardCIDContext, name, targetPath string) error {
+	content, err := os.ReadFile(filepath.Join(shardContext.CIDDownloadDir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sink, err := os.OpenFile(targetPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, model.DownloadFilePerm)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError("log", sink)
+
+	result := shardContext.Result
+	header := fmt.Sprintf("==> shard %d (node %s) <==\n", result.ShardIndex, system.GetShortID(result.NodeID))
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		content = append(content, '\n')
+	}
+	_, err = sink.Write(append([]byte(header), content...))
+	return err
+}
+
+func readExitCode(resultDir string) (*int, error) {
+	content, err := os.ReadFile(filepath.Join(resultDir, model.DownloadFilenameExitCode))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	exitCode, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid exit code in %s: %w", resultDir, err)
+	}
+	return &exitCode, nil
+}
+
+// mergeVolumes copies the files of the output volumes of a shard into the merged tree. Files that an earlier
+// shard already produced are skipped if they have the same content, and renamed after the shard otherwise.
+func mergeVolumes(
+	shardContext shardCIDContext,
+	mergedDir string,
+	origins map[string]shardCIDContext,
+	settings *model.DownloaderSettings,
+) ([]mergeConflict, error) {
+	var conflicts []mergeConflict
+	err := filepath.WalkDir(shardContext.CIDDownloadDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		basePath, err := filepath.Rel(shardContext.CIDDownloadDir, path)
+		if err != nil {
+			return err
+		}
+		topLevel := filepath.Dir(basePath) == "."
+		if basePath == "." {
+			return nil
+		}
+		if d.IsDir() {
+			if topLevel && !settings.IncludesOutputVolume(basePath) {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(mergedDir, basePath), model.DownloadFolderPerm)
+		}
+		if _, isSpecialFile := specialFiles[basePath]; isSpecialFile && topLevel {
+			return nil
+		}
+
+		relativePath := filepath.ToSlash(basePath)
+		origin, exists := origins[relativePath]
+		if !exists {
+			origins[relativePath] = shardContext
+			return cp.CopyFile(path, filepath.Join(mergedDir, basePath))
+		}
+		same, err := sameContent(path, filepath.Join(origin.CIDDownloadDir, basePath))
+		if err != nil || same {
+			return err
+		}
+
+		extension := filepath.Ext(basePath)
+		renamedPath := strings.TrimSuffix(basePath, extension) + "." + shardName(shardContext.Result) + extension
+		conflicts = append(conflicts, mergeConflict{
+			Path:       relativePath,
+			ShardIndex: shardContext.Result.ShardIndex,
+			NodeID:     shardContext.Result.NodeID,
+			RenamedTo:  filepath.ToSlash(renamedPath),
+		})
+		return cp.CopyFile(path, filepath.Join(mergedDir, renamedPath))
+	})
+	return conflicts, err
+}
+
+// sameContent returns whether two files have the same content.
+func sameContent(path, otherPath string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	otherInfo, err := os.Stat(otherPath)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() != otherInfo.Size() {
+		return false, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer closer.CloseWithLogOnError("file", file)
+	otherFile, err := os.Open(otherPath)
+	if err != nil {
+		return false, err
+	}
+	defer closer.CloseWithLogOnError("file", otherFile)
+
+	const chunkSize = 64 * 1024
+	chunk, otherChunk := make([]byte, chunkSize), make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(file, chunk)
+		otherN, otherErr := io.ReadFull(otherFile, otherChunk)
+		if !bytes.Equal(chunk[:n], otherChunk[:otherN]) {
+			return false, nil
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return otherErr == io.EOF || otherErr == io.ErrUnexpectedEOF, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if otherErr != nil {
+			return false, otherErr
+		}
+	}
+}
@@ -0,0 +1,85 @@
+//go:build unit || !integration
+
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadJobMerge(t *testing.T) {
+	results, downloader := newFakeResults(t, 3)
+	// shards 0 and 1 produce the same file with the same content, and shard 2 with different content
+	for cid, content := range map[string]string{"cid-0": "same", "cid-1": "same", "cid-2": "different"} {
+		require.NoError(t, os.WriteFile(filepath.Join(downloader.sources[cid], "outputs", "shared.txt"), []byte(content), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(downloader.sources["cid-0"], model.DownloadFilenameExitCode), []byte("0"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(downloader.sources["cid-1"], model.DownloadFilenameExitCode), []byte("1\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(downloader.sources["cid-2"], model.DownloadFilenameStdout), []byte("no newline"), 0644))
+	provider := model.NewMappedProvider(map[model.StorageSourceType]Downloader{model.StorageSourceIPFS: downloader})
+	settings := &model.DownloaderSettings{OutputDir: t.TempDir(), Merge: true, AllowMissingManifest: true}
+
+	// results are merged in shard order, regardless of the order they are listed in
+	results[0], results[2] = results[2], results[0]
+	err := DownloadJob(context.Background(), fakeOutputVolumes, results, provider, settings)
+	require.NoError(t, err)
+
+	mergedDir := filepath.Join(settings.OutputDir, model.DownloadMergedFolderName)
+	requireContent(t, filepath.Join(mergedDir, model.DownloadFilenameStdout),
+		"==> shard 0 (node testnode) <==\nstdout 0\n"+
+			"==> shard 1 (node testnode) <==\nstdout 1\n"+
+			"==> shard 2 (node testnode) <==\nno newline\n")
+	requireContent(t, filepath.Join(mergedDir, "outputs", "data0.csv"), "1,2")
+	requireContent(t, filepath.Join(mergedDir, "outputs", "data2.csv"), "1,2")
+	requireContent(t, filepath.Join(mergedDir, "logs", "run1.log"), "log")
+	requireContent(t, filepath.Join(mergedDir, "outputs", "shared.txt"), "same")
+	requireContent(t, filepath.Join(mergedDir, "outputs", "shared.2_node_testnode.txt"), "different")
+	require.NoFileExists(t, filepath.Join(mergedDir, "outputs", "shared.1_node_testnode.txt"))
+	require.NoFileExists(t, filepath.Join(mergedDir, model.DownloadFilenameExitCode))
+
+	content, err := os.ReadFile(filepath.Join(mergedDir, model.DownloadFilenameSummary))
+	require.NoError(t, err)
+	var summary mergeSummary
+	require.NoError(t, json.Unmarshal(content, &summary))
+	zero, one := 0, 1
+	require.Equal(t, mergeSummary{
+		Shards: []shardSummary{
+			{ShardIndex: 0, NodeID: "testnode", ExitCode: &zero},
+			{ShardIndex: 1, NodeID: "testnode", ExitCode: &one},
+			{ShardIndex: 2, NodeID: "testnode"},
+		},
+		Conflicts: []mergeConflict{
+			{Path: "outputs/shared.txt", ShardIndex: 2, NodeID: "testnode", RenamedTo: "outputs/shared.2_node_testnode.txt"},
+		},
+	}, summary)
+
+	// merging again rebuilds the merged tree
+	err = DownloadJob(context.Background(), fakeOutputVolumes, results, provider, settings)
+	require.NoError(t, err)
+	requireContent(t, filepath.Join(mergedDir, model.DownloadFilenameStdout),
+		"==> shard 0 (node testnode) <==\nstdout 0\n"+
+			"==> shard 1 (node testnode) <==\nstdout 1\n"+
+			"==> shard 2 (node testnode) <==\nno newline\n")
+}
+
+func TestDownloadJobWithoutMerge(t *testing.T) {
+	results, downloader := newFakeResults(t, 2)
+	provider := model.NewMappedProvider(map[model.StorageSourceType]Downloader{model.StorageSourceIPFS: downloader})
+	settings := &model.DownloaderSettings{OutputDir: t.TempDir(), AllowMissingManifest: true}
+
+	err := DownloadJob(context.Background(), fakeOutputVolumes, results, provider, settings)
+	require.NoError(t, err)
+	require.NoDirExists(t, filepath.Join(settings.OutputDir, model.DownloadMergedFolderName))
+}
+
+func requireContent(t *testing.T, path, expected string) {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(content))
+}
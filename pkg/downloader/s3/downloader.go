@@ -0,0 +1,68 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	s3helper "github.com/filecoin-project/bacalhau/pkg/s3"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/rs/zerolog/log"
+)
+
+// Downloader downloads results published to S3-compatible storage, using the credentials of the
+// AWS environment or shared config of the client.
+type Downloader struct {
+	Settings *model.DownloaderSettings
+}
+
+func NewS3Downloader(settings *model.DownloaderSettings) *Downloader {
+	return &Downloader{
+		Settings: settings,
+	}
+}
+
+func (s3Downloader *Downloader) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (s3Downloader *Downloader) FetchResult(ctx context.Context, result model.PublishedResult, downloadPath string) error {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/downloader/s3.Downloader.FetchResult")
+	defer span.End()
+
+	if result.Data.S3 == nil || result.Data.S3.Bucket == "" {
+		return fmt.Errorf("result %s has no S3 location", result.Data.Name)
+	}
+	bucket, prefix := result.Data.S3.Bucket, result.Data.S3.Prefix
+
+	innerCtx, cancel := context.WithDeadline(ctx, time.Now().Add(s3Downloader.Settings.Timeout))
+	defer cancel()
+
+	client, err := s3helper.NewClient(innerCtx, s3helper.Config{
+		Endpoint: s3Downloader.Settings.S3Endpoint,
+		Region:   s3Downloader.Settings.S3Region,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Debug().Msgf("Downloading result s3://%s/%s to '%s'...", bucket, prefix, downloadPath)
+	objects, err := s3helper.ListObjects(innerCtx, client, bucket, prefix)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if !s3Downloader.Settings.IncludesPath(object.RelativePath) {
+			continue
+		}
+		// objects that were downloaded before, such as by an interrupted download, are skipped or resumed
+		err = s3helper.ResumeObjectDownload(innerCtx, client, bucket, object,
+			filepath.Join(downloadPath, filepath.FromSlash(object.RelativePath)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
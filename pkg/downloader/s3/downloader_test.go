@@ -0,0 +1,93 @@
+//go:build unit || !integration
+
+package s3
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/s3/s3test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchResult(t *testing.T) {
+	server := s3test.NewServer(t, "bucket", map[string]string{
+		"job-id/0/execution-id/stdout":           "hello",
+		"job-id/0/execution-id/outputs/data.csv": "1,2",
+		"job-id/1/execution-id/stdout":           "other shard",
+	})
+	t.Setenv("AWS_ACCESS_KEY_ID", "access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret-key")
+
+	downloader := NewS3Downloader(&model.DownloaderSettings{
+		Timeout:    time.Minute,
+		S3Endpoint: server.URL,
+	})
+	downloadPath := t.TempDir()
+	err := downloader.FetchResult(context.Background(), model.PublishedResult{
+		Data: model.StorageSpec{
+			StorageSource: model.StorageSourceS3,
+			S3:            &model.S3StorageSpec{Bucket: "bucket", Prefix: "job-id/0/execution-id/"},
+		},
+	}, downloadPath)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(downloadPath, "stdout"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(content))
+	content, err = os.ReadFile(filepath.Join(downloadPath, "outputs", "data.csv"))
+	require.NoError(t, err)
+	require.Equal(t, "1,2", string(content))
+}
+
+func TestFetchResultResumesAndFilters(t *testing.T) {
+	server := s3test.NewServer(t, "bucket", map[string]string{
+		"job-id/0/execution-id/stdout":            "hello",
+		"job-id/0/execution-id/outputs/data.csv":  "1,2,3,4",
+		"job-id/0/execution-id/outputs/model.bin": "weights",
+		"job-id/0/execution-id/logs/run.log":      "log",
+	})
+	t.Setenv("AWS_ACCESS_KEY_ID", "access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret-key")
+
+	downloader := NewS3Downloader(&model.DownloaderSettings{
+		Timeout:           time.Minute,
+		S3Endpoint:        server.URL,
+		OutputVolumeNames: []string{"outputs"},
+	})
+	// an interrupted download left a partial file, a complete file, and a corrupt file that is larger than its object
+	downloadPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(downloadPath, "outputs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(downloadPath, "outputs", "data.csv"), []byte("1,2"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(downloadPath, "outputs", "model.bin"), []byte("weights"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(downloadPath, "stdout"), []byte("hello world"), 0644))
+
+	err := downloader.FetchResult(context.Background(), model.PublishedResult{
+		Data: model.StorageSpec{
+			StorageSource: model.StorageSourceS3,
+			S3:            &model.S3StorageSpec{Bucket: "bucket", Prefix: "job-id/0/execution-id/"},
+		},
+	}, downloadPath)
+	require.NoError(t, err)
+
+	for path, expected := range map[string]string{
+		"stdout":            "hello",
+		"outputs/data.csv":  "1,2,3,4",
+		"outputs/model.bin": "weights",
+	} {
+		content, err := os.ReadFile(filepath.Join(downloadPath, filepath.FromSlash(path)))
+		require.NoError(t, err)
+		require.Equal(t, expected, string(content), path)
+	}
+	require.NoDirExists(t, filepath.Join(downloadPath, "logs"), "output volumes that are not selected are skipped")
+}
+
+func TestFetchResultWithoutLocation(t *testing.T) {
+	downloader := NewS3Downloader(&model.DownloaderSettings{Timeout: time.Minute})
+	err := downloader.FetchResult(context.Background(), model.PublishedResult{}, t.TempDir())
+	require.Error(t, err)
+}
@@ -0,0 +1,118 @@
+package downloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+)
+
+// downloadState records the results that have been fully downloaded to an output directory, so that downloading
+// the results of a job again only fetches the results that are missing, such as after an interrupted download.
+// It is safe for concurrent use.
+type downloadState struct {
+	path string
+	mu   sync.Mutex
+	// Results are the downloaded results, by the name of the folder they were downloaded to
+	Results map[string]resultState `json:"Results"`
+}
+
+type resultState struct {
+	NodeID     string `json:"NodeID"`
+	ShardIndex int    `json:"ShardIndex"`
+	// OutputVolumes are the names of the output volumes that were downloaded, or empty if all of them were
+	OutputVolumes []string `json:"OutputVolumes,omitempty"`
+}
+
+// loadDownloadState reads the download state of an output directory, which is empty if nothing was downloaded yet.
+func loadDownloadState(outputDir string) (*downloadState, error) {
+	state := &downloadState{
+		path:    filepath.Join(outputDir, model.DownloadFilenameState),
+		Results: map[string]resultState{},
+	}
+	content, err := os.ReadFile(state.path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("invalid download state %s, remove it to download all results again: %w", state.path, err)
+	}
+	if state.Results == nil {
+		state.Results = map[string]resultState{}
+	}
+	return state, nil
+}
+
+// isComplete returns whether a result was downloaded, including all output volumes that are downloaded now.
+func (s *downloadState) isComplete(resultID string, settings *model.DownloaderSettings) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, ok := s.Results[resultID]
+	if !ok {
+		return false
+	}
+	if len(result.OutputVolumes) == 0 {
+		return true
+	}
+	if len(settings.OutputVolumeNames) == 0 {
+		return false
+	}
+	downloaded := model.DownloaderSettings{OutputVolumeNames: result.OutputVolumes}
+	for _, name := range settings.OutputVolumeNames {
+		if !downloaded.IncludesOutputVolume(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// isDownloaded returns whether any part of a result was downloaded.
+func (s *downloadState) isDownloaded(resultID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.Results[resultID]
+	return ok
+}
+
+// complete records that a result was downloaded with the output volumes of the settings.
+func (s *downloadState) complete(resultID string, result model.PublishedResult, settings *model.DownloaderSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Results[resultID] = resultState{
+		NodeID:        result.NodeID,
+		ShardIndex:    result.ShardIndex,
+		OutputVolumes: settings.OutputVolumeNames,
+	}
+	return s.save()
+}
+
+// remove records that a result is being downloaded again, and so is incomplete until the download succeeds.
+func (s *downloadState) remove(resultID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Results[resultID]; !ok {
+		return nil
+	}
+	delete(s.Results, resultID)
+	return s.save()
+}
+
+// save writes the state to a temporary file that then replaces the state file, so that an interrupted
+// download never leaves a partially written state behind.
+func (s *downloadState) save() error {
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tempPath := s.path + ".tmp"
+	if err = os.WriteFile(tempPath, content, model.DownloadFilePerm); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, s.path)
+}
@@ -0,0 +1,47 @@
+//go:build unit || !integration
+
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadState(t *testing.T) {
+	dir := t.TempDir()
+	state, err := loadDownloadState(dir)
+	require.NoError(t, err)
+	all := &model.DownloaderSettings{}
+	outputs := &model.DownloaderSettings{OutputVolumeNames: []string{"outputs"}}
+	logs := &model.DownloaderSettings{OutputVolumeNames: []string{"logs"}}
+
+	require.NoError(t, state.complete("cid-0", model.PublishedResult{NodeID: "node", ShardIndex: 0}, outputs))
+	require.NoError(t, state.complete("cid-1", model.PublishedResult{NodeID: "node", ShardIndex: 1}, all))
+
+	// the state is persisted
+	state, err = loadDownloadState(dir)
+	require.NoError(t, err)
+	require.True(t, state.isComplete("cid-0", outputs))
+	require.False(t, state.isComplete("cid-0", logs))
+	require.False(t, state.isComplete("cid-0", all))
+	require.True(t, state.isComplete("cid-1", outputs))
+	require.True(t, state.isComplete("cid-1", all))
+	require.False(t, state.isComplete("cid-2", all))
+
+	require.NoError(t, state.remove("cid-0"))
+	state, err = loadDownloadState(dir)
+	require.NoError(t, err)
+	require.False(t, state.isDownloaded("cid-0"))
+	require.True(t, state.isDownloaded("cid-1"))
+}
+
+func TestDownloadStateInvalid(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, model.DownloadFilenameState), []byte("{"), 0644))
+	_, err := loadDownloadState(dir)
+	require.Error(t, err)
+}
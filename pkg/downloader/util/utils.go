@@ -5,8 +5,11 @@ import (
 	"strings"
 
 	"github.com/filecoin-project/bacalhau/pkg/downloader"
+	"github.com/filecoin-project/bacalhau/pkg/downloader/archive"
 	"github.com/filecoin-project/bacalhau/pkg/downloader/estuary"
 	"github.com/filecoin-project/bacalhau/pkg/downloader/ipfs"
+	"github.com/filecoin-project/bacalhau/pkg/downloader/local"
+	"github.com/filecoin-project/bacalhau/pkg/downloader/s3"
 	"github.com/filecoin-project/bacalhau/pkg/model"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 )
@@ -17,12 +20,15 @@ func NewDownloadSettings() *model.DownloaderSettings {
 		// we leave this blank so the CLI will auto-create a job folder in pwd
 		OutputDir:      "",
 		IPFSSwarmAddrs: "",
+		Parallelism:    model.DefaultDownloadParallelism,
 	}
 	if os.Getenv("BACALHAU_IPFS_SWARM_ADDRESSES") != "" {
 		settings.IPFSSwarmAddrs = os.Getenv("BACALHAU_IPFS_SWARM_ADDRESSES")
 	} else {
 		settings.IPFSSwarmAddrs = strings.Join(system.Envs[system.GetEnvironment()].IPFSSwarmAddresses, ",")
 	}
+	settings.HTTPUsername = os.Getenv("BACALHAU_HTTP_USERNAME")
+	settings.HTTPPassword = os.Getenv("BACALHAU_HTTP_PASSWORD")
 	return &settings
 }
 
@@ -31,9 +37,15 @@ func NewStandardDownloaders(
 	settings *model.DownloaderSettings) downloader.DownloaderProvider {
 	ipfsDownloader := ipfs.NewIPFSDownloader(cm, settings)
 	estuaryDownloader := estuary.NewEstuaryDownloader(cm, settings)
+	s3Downloader := s3.NewS3Downloader(settings)
+	localDownloader := local.NewLocalDownloader(settings)
+	archiveDownloader := archive.NewArchiveDownloader(settings)
 
 	return model.NewMappedProvider(map[model.StorageSourceType]downloader.Downloader{
-		model.StorageSourceIPFS:    ipfsDownloader,
-		model.StorageSourceEstuary: estuaryDownloader,
+		model.StorageSourceIPFS:           ipfsDownloader,
+		model.StorageSourceEstuary:        estuaryDownloader,
+		model.StorageSourceS3:             s3Downloader,
+		model.StorageSourceLocalDirectory: localDownloader,
+		model.StorageSourceURLDownload:    archiveDownloader,
 	})
 }
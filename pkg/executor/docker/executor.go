@@ -11,6 +11,7 @@ import (
 	dockertypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
 	"github.com/filecoin-project/bacalhau/pkg/compute/capacity"
 	"github.com/filecoin-project/bacalhau/pkg/config"
 	"github.com/filecoin-project/bacalhau/pkg/docker"
@@ -29,6 +30,12 @@ import (
 
 const NanoCPUCoefficient = 1000000000
 
+// logsTimeout is how long to wait for the logs of a container after it stopped
+const logsTimeout = 3 * time.Second
+
+// outputLimitsCheckInterval is how often the results of a running container are checked against its output limits
+const outputLimitsCheckInterval = 5 * time.Second
+
 const (
 	labelExecutorName = "bacalhau-executor"
 	labelJobName      = "bacalhau-jobID"
@@ -42,6 +49,8 @@ type Executor struct {
 	StorageProvider storage.StorageProvider
 
 	client *docker.Client
+
+	logs *executor.LogBuffers
 }
 
 func NewExecutor(
@@ -59,6 +68,7 @@ func NewExecutor(
 		ID:              id,
 		StorageProvider: storageProvider,
 		client:          dockerClient,
+		logs:            executor.NewLogBuffers(),
 	}
 
 	cm.RegisterCallbackWithContext(de.cleanupAll)
@@ -179,9 +189,11 @@ func (e *Executor) RunShard(
 
 	if os.Getenv("SKIP_IMAGE_PULL") == "" {
 		if err := e.client.PullImage(ctx, shard.Job.Spec.Docker.Image); err != nil { //nolint:govet // ignore err shadowing
-			err = errors.Wrapf(err, `Could not pull image %q - could be due to repo/image not existing,
- or registry needing authorization`, shard.Job.Spec.Docker.Image)
-			return executor.FailResult(err)
+			imageErr := bacerrors.NewImageNotFound(shard.Job.Spec.Docker.Image)
+			imageErr.SetImageName(shard.Job.Spec.Docker.Image)
+			imageErr.SetError(errors.Wrapf(err, `Could not pull image %q - could be due to repo/image not existing,
+ or registry needing authorization`, shard.Job.Spec.Docker.Image))
+			return executor.FailResult(imageErr)
 		}
 	}
 
@@ -268,6 +280,22 @@ func (e *Executor) RunShard(
 		return executor.FailResult(internalContainerStartError)
 	}
 
+	// stream the output of the container while it runs, so that it can be read before the job completes
+	logs, finishLogs := e.logs.Open(shard)
+	defer finishLogs()
+	streamCtx, stopStreaming := context.WithCancel(ctx)
+	defer stopStreaming()
+	streamDone := make(chan struct{})
+	go func() {
+		defer close(streamDone)
+		streamErr := e.client.StreamLogs(streamCtx, jobContainer.ID, logs.Stdout(), logs.Stderr())
+		if streamErr != nil && !errors.Is(streamErr, context.Canceled) {
+			log.Ctx(ctx).Debug().Err(streamErr).Msg("failed to stream container logs")
+		}
+	}()
+
+	stopWatchingOutputs := e.watchOutputLimits(ctx, jobContainer.ID, jobResultsDir, shard.Job.Spec.Outputs)
+
 	// the idea here is even if the container errors
 	// we want to capture stdout, stderr and feed it back to the user
 	var containerError error
@@ -286,20 +314,82 @@ func (e *Executor) RunShard(
 			containerError = errors.New(exitStatus.Error.Message)
 		}
 	}
+	outputLimitError := stopWatchingOutputs()
+
+	// the log stream ends once the container stopped, but don't wait for it longer than for the final logs
+	select {
+	case <-streamDone:
+	case <-time.After(logsTimeout):
+	}
 
 	// Can't use the original context as it may have already been timed out
-	detachedContext, cancel := context.WithTimeout(telemetry.NewDetachedContext(ctx), 3*time.Second)
+	detachedContext, cancel := context.WithTimeout(telemetry.NewDetachedContext(ctx), logsTimeout)
 	defer cancel()
 	stdoutPipe, stderrPipe, logsErr := e.client.FollowLogs(detachedContext, jobContainer.ID)
 	log.Ctx(detachedContext).Debug().Err(logsErr).Msg("Captured stdout/stderr for container")
 
-	return executor.WriteJobResults(
+	result, err := executor.WriteJobResults(
 		jobResultsDir,
 		stdoutPipe,
 		stderrPipe,
 		int(containerExitStatusCode),
-		multierr.Combine(containerError, logsErr),
+		multierr.Combine(outputLimitError, containerError, logsErr),
 	)
+	result.InputVersions = executor.InputVersions(inputVolumes)
+	return result, err
+}
+
+// watchOutputLimits checks the results of a running container against the output limits of the context at every
+// interval, and stops the container once they exceed them. The returned function stops watching, and returns
+// the error of the exceeded limit if the container was stopped.
+func (e *Executor) watchOutputLimits(
+	ctx context.Context,
+	containerID string,
+	jobResultsDir string,
+	outputs []model.StorageSpec,
+) func() error {
+	limits := executor.OutputLimitsFromContext(ctx)
+	if limits.IsZero() {
+		return func() error { return nil }
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	exceeded := make(chan error, 1)
+	go func() {
+		defer close(exceeded)
+		ticker := time.NewTicker(outputLimitsCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			err := executor.CheckOutputLimits(jobResultsDir, outputs, limits)
+			var limitErr *bacerrors.OutputLimitExceeded
+			if !errors.As(err, &limitErr) {
+				if err != nil {
+					log.Ctx(ctx).Debug().Err(err).Msg("failed to check output limits")
+				}
+				continue
+			}
+			log.Ctx(ctx).Info().Err(err).Msg("Stopping container that exceeded its output limits")
+			timeout := time.Duration(0)
+			if stopErr := e.client.ContainerStop(ctx, containerID, &timeout); stopErr != nil {
+				log.Ctx(ctx).Warn().Err(stopErr).Msg("failed to stop container that exceeded its output limits")
+			}
+			exceeded <- err
+			return
+		}
+	}()
+	return func() error {
+		cancel()
+		return <-exceeded
+	}
+}
+
+func (e *Executor) GetShardLogs(ctx context.Context, shard model.JobShard, offset int) (executor.ShardLogs, bool, error) {
+	return e.logs.GetShardLogs(ctx, shard, offset)
 }
 
 func (e *Executor) cleanupJob(ctx context.Context, shard model.JobShard) {
@@ -353,3 +443,4 @@ func (e *Executor) labelJobValue(shard model.JobShard) string {
 
 // Compile-time interface check:
 var _ executor.Executor = (*Executor)(nil)
+var _ executor.LogStreamer = (*Executor)(nil)
@@ -14,8 +14,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
 	"github.com/filecoin-project/bacalhau/pkg/compute/capacity"
 	"github.com/filecoin-project/bacalhau/pkg/docker"
+	"github.com/filecoin-project/bacalhau/pkg/executor"
 	"github.com/filecoin-project/bacalhau/pkg/model"
 	"github.com/filecoin-project/bacalhau/pkg/storage"
 	"github.com/filecoin-project/bacalhau/pkg/system"
@@ -319,3 +321,21 @@ func (s *ExecutorTestSuite) TestTimesOutCorrectly() {
 	s.ErrorIs(err, context.DeadlineExceeded)
 	s.Truef(strings.HasPrefix(result.STDOUT, expected), "'%s' does not start with '%s'", result.STDOUT, expected)
 }
+
+func (s *ExecutorTestSuite) TestStopsContainerExceedingOutputLimits() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	ctx = executor.ContextWithOutputLimits(ctx, model.OutputLimits{Volume: 1024 * 1024})
+
+	_, err := s.runJobWithContext(ctx, model.Spec{
+		Engine: model.EngineDocker,
+		Docker: model.JobSpecDocker{
+			Image:      "ubuntu",
+			Entrypoint: []string{"bash", "-c", "head -c 2000000 /dev/zero > /outputs/data && sleep 60"},
+		},
+		Outputs: []model.StorageSpec{{Name: "outputs", Path: "/outputs"}},
+	})
+	var limitErr *bacerrors.OutputLimitExceeded
+	s.ErrorAs(err, &limitErr)
+	s.NoError(ctx.Err(), "the container should be stopped before it exits")
+}
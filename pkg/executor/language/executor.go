@@ -66,6 +66,19 @@ func (e *Executor) RunShard(
 	return executor.RunShard(ctx, shard, jobResultsDir)
 }
 
+// GetShardLogs returns the logs of the executor the shard was delegated to, if it can stream them.
+func (e *Executor) GetShardLogs(ctx context.Context, shard model.JobShard, offset int) (executor.ShardLogs, bool, error) {
+	delegate, err := e.getDelegateExecutor(ctx, shard)
+	if err != nil {
+		return executor.ShardLogs{}, false, err
+	}
+	streamer, ok := delegate.(executor.LogStreamer)
+	if !ok {
+		return executor.ShardLogs{}, false, nil
+	}
+	return streamer.GetShardLogs(ctx, shard, offset)
+}
+
 func (e *Executor) getDelegateExecutor(ctx context.Context, shard model.JobShard) (executor.Executor, error) {
 	requiredLang := LanguageSpec{
 		Language: shard.Job.Spec.Language.Language,
@@ -96,3 +109,4 @@ func (e *Executor) getDelegateExecutor(ctx context.Context, shard model.JobShard
 
 // Compile-time check that Executor implements the Executor interface.
 var _ executor.Executor = (*Executor)(nil)
+var _ executor.LogStreamer = (*Executor)(nil)
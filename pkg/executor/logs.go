@@ -0,0 +1,152 @@
+package executor
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/util/generic"
+)
+
+const (
+	// DefaultLogBufferSize is the number of bytes of the most recent output of a shard that are kept in memory
+	DefaultLogBufferSize = 1024 * 1024
+	// DefaultLogRetention is how long the output of a shard is kept in memory after the shard completed
+	DefaultLogRetention = 10 * time.Minute
+)
+
+// LogStreamer is implemented by executors that can return the output of shards while they are running.
+type LogStreamer interface {
+	// GetShardLogs returns the output of the shard since the given offset, and whether the output of the
+	// shard is known to the executor. The output of a shard is kept for a while after the shard completed.
+	GetShardLogs(ctx context.Context, shard model.JobShard, offset int) (ShardLogs, bool, error)
+}
+
+// ShardLogs is a page of the output of a shard.
+type ShardLogs struct {
+	Logs       []model.LogEntry
+	NextOffset int
+	Finished   bool
+}
+
+// LogBuffer keeps the most recent output of a shard in memory, so that it can be read while the shard runs.
+// Older entries are dropped once the buffer exceeds its size, and offsets keep counting the dropped entries.
+type LogBuffer struct {
+	mu       sync.Mutex
+	entries  []model.LogEntry
+	dropped  int
+	size     int
+	maxSize  int
+	finished bool
+}
+
+func NewLogBuffer(maxSize int) *LogBuffer {
+	return &LogBuffer{maxSize: maxSize}
+}
+
+// Stdout returns a writer that appends to the buffer as stdout
+func (b *LogBuffer) Stdout() io.Writer {
+	return logBufferWriter{buffer: b, stream: model.LogStreamStdout}
+}
+
+// Stderr returns a writer that appends to the buffer as stderr
+func (b *LogBuffer) Stderr() io.Writer {
+	return logBufferWriter{buffer: b, stream: model.LogStreamStderr}
+}
+
+func (b *LogBuffer) append(stream model.LogStreamType, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.finished {
+		return
+	}
+	b.entries = append(b.entries, model.LogEntry{Stream: stream, Data: string(data)})
+	b.size += len(data)
+	for b.size > b.maxSize && len(b.entries) > 1 {
+		b.size -= len(b.entries[0].Data)
+		b.entries = b.entries[1:]
+		b.dropped++
+	}
+}
+
+// Finish marks the end of the output, after which writes are ignored.
+func (b *LogBuffer) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.finished = true
+}
+
+// Read returns the entries since the given offset. Entries that were already dropped are skipped.
+func (b *LogBuffer) Read(offset int) ShardLogs {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	start := offset - b.dropped
+	if start < 0 {
+		start = 0
+	}
+	if start > len(b.entries) {
+		start = len(b.entries)
+	}
+	logs := make([]model.LogEntry, len(b.entries)-start)
+	copy(logs, b.entries[start:])
+	return ShardLogs{
+		Logs:       logs,
+		NextOffset: b.dropped + len(b.entries),
+		Finished:   b.finished,
+	}
+}
+
+type logBufferWriter struct {
+	buffer *LogBuffer
+	stream model.LogStreamType
+}
+
+func (w logBufferWriter) Write(p []byte) (int, error) {
+	w.buffer.append(w.stream, p)
+	return len(p), nil
+}
+
+// LogBuffers tracks the output of the shards run by an executor, and implements LogStreamer.
+type LogBuffers struct {
+	buffers   generic.SyncMap[string, *LogBuffer]
+	size      int
+	retention time.Duration
+}
+
+func NewLogBuffers() *LogBuffers {
+	return &LogBuffers{
+		size:      DefaultLogBufferSize,
+		retention: DefaultLogRetention,
+	}
+}
+
+// Open starts tracking the output of a shard, replacing the output of a previous run of the same shard.
+// The returned function must be called once the shard completed.
+func (l *LogBuffers) Open(shard model.JobShard) (*LogBuffer, func()) {
+	buffer := NewLogBuffer(l.size)
+	l.buffers.Put(shard.ID(), buffer)
+	return buffer, func() {
+		buffer.Finish()
+		time.AfterFunc(l.retention, func() {
+			if current, ok := l.buffers.Get(shard.ID()); ok && current == buffer {
+				l.buffers.Delete(shard.ID())
+			}
+		})
+	}
+}
+
+func (l *LogBuffers) GetShardLogs(_ context.Context, shard model.JobShard, offset int) (ShardLogs, bool, error) {
+	buffer, ok := l.buffers.Get(shard.ID())
+	if !ok {
+		return ShardLogs{}, false, nil
+	}
+	return buffer.Read(offset), true, nil
+}
+
+// compile-time check that LogBuffers implements LogStreamer
+var _ LogStreamer = (*LogBuffers)(nil)
@@ -0,0 +1,68 @@
+//go:build unit || !integration
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogBuffer(t *testing.T) {
+	buffer := NewLogBuffer(10)
+	_, _ = fmt.Fprint(buffer.Stdout(), "hello")
+	_, _ = fmt.Fprint(buffer.Stderr(), "oops")
+
+	logs := buffer.Read(0)
+	require.Equal(t, []model.LogEntry{
+		{Stream: model.LogStreamStdout, Data: "hello"},
+		{Stream: model.LogStreamStderr, Data: "oops"},
+	}, logs.Logs)
+	require.Equal(t, 2, logs.NextOffset)
+	require.False(t, logs.Finished)
+
+	// the oldest entries are dropped once the buffer is full, and offsets keep counting them
+	_, _ = fmt.Fprint(buffer.Stdout(), "world")
+	logs = buffer.Read(0)
+	require.Equal(t, []model.LogEntry{
+		{Stream: model.LogStreamStderr, Data: "oops"},
+		{Stream: model.LogStreamStdout, Data: "world"},
+	}, logs.Logs)
+	require.Equal(t, 3, logs.NextOffset)
+
+	logs = buffer.Read(2)
+	require.Equal(t, []model.LogEntry{{Stream: model.LogStreamStdout, Data: "world"}}, logs.Logs)
+
+	buffer.Finish()
+	_, _ = fmt.Fprint(buffer.Stdout(), "ignored")
+	logs = buffer.Read(3)
+	require.Empty(t, logs.Logs)
+	require.Equal(t, 3, logs.NextOffset)
+	require.True(t, logs.Finished)
+}
+
+func TestLogBuffersReplaceShardRuns(t *testing.T) {
+	ctx := context.Background()
+	buffers := NewLogBuffers()
+	shard := model.JobShard{Job: &model.Job{Metadata: model.Metadata{ID: "job"}}}
+
+	_, found, err := buffers.GetShardLogs(ctx, shard, 0)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	first, finish := buffers.Open(shard)
+	_, _ = fmt.Fprint(first.Stdout(), "first run")
+	finish()
+
+	second, _ := buffers.Open(shard)
+	_, _ = fmt.Fprint(second.Stdout(), "second run")
+
+	logs, found, err := buffers.GetShardLogs(ctx, shard, 0)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.False(t, logs.Finished)
+	require.Equal(t, []model.LogEntry{{Stream: model.LogStreamStdout, Data: "second run"}}, logs.Logs)
+}
@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+)
+
+type outputLimitsKey struct{}
+
+// ContextWithOutputLimits returns a context that carries the limits on the size of the results of the shard
+// that is run, for executors to enforce while the shard runs.
+func ContextWithOutputLimits(ctx context.Context, limits model.OutputLimits) context.Context {
+	return context.WithValue(ctx, outputLimitsKey{}, limits)
+}
+
+// OutputLimitsFromContext returns the limits on the size of the results of the shard that is run, which are
+// unlimited if the context does not carry any.
+func OutputLimitsFromContext(ctx context.Context) model.OutputLimits {
+	limits, _ := ctx.Value(outputLimitsKey{}).(model.OutputLimits)
+	return limits
+}
+
+// ResultSize is the size in bytes of the results of a shard.
+type ResultSize struct {
+	// Total is the size of all results, including stdout, stderr and all output volumes
+	Total uint64
+	// Volumes are the sizes of the output volumes, by name
+	Volumes map[string]uint64
+}
+
+// MeasureResults returns the size of the results in the results directory, where each output volume is the
+// folder named after it. Files that are removed while the results are measured, such as by a running job,
+// are ignored.
+func MeasureResults(resultsDir string, outputs []model.StorageSpec) (ResultSize, error) {
+	size := ResultSize{Volumes: make(map[string]uint64, len(outputs))}
+	for _, output := range outputs {
+		size.Volumes[output.Name] = 0
+	}
+	err := filepath.WalkDir(resultsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path != resultsDir {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fileSize := uint64(info.Size())
+		size.Total += fileSize
+		relativePath, err := filepath.Rel(resultsDir, path)
+		if err != nil {
+			return err
+		}
+		volume, _, isNested := strings.Cut(filepath.ToSlash(relativePath), "/")
+		if _, isVolume := size.Volumes[volume]; isVolume && isNested {
+			size.Volumes[volume] += fileSize
+		}
+		return nil
+	})
+	return size, err
+}
+
+// Check returns a *bacerrors.OutputLimitExceeded error if the results exceed the limits.
+func (s ResultSize) Check(outputs []model.StorageSpec, limits model.OutputLimits) error {
+	if limits.Volume > 0 {
+		for _, output := range outputs {
+			if volumeSize := s.Volumes[output.Name]; volumeSize > limits.Volume {
+				return bacerrors.NewOutputLimitExceeded(output.Name, volumeSize, limits.Volume)
+			}
+		}
+	}
+	if limits.Total > 0 && s.Total > limits.Total {
+		return bacerrors.NewOutputLimitExceeded("", s.Total, limits.Total)
+	}
+	return nil
+}
+
+// CheckOutputLimits returns a *bacerrors.OutputLimitExceeded error if the results in the results directory
+// exceed the limits.
+func CheckOutputLimits(resultsDir string, outputs []model.StorageSpec, limits model.OutputLimits) error {
+	if limits.IsZero() {
+		return nil
+	}
+	size, err := MeasureResults(resultsDir, outputs)
+	if err != nil {
+		return err
+	}
+	return size.Check(outputs, limits)
+}
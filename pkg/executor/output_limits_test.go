@@ -0,0 +1,46 @@
+//go:build unit || !integration
+
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputLimitsContext(t *testing.T) {
+	require.True(t, OutputLimitsFromContext(context.Background()).IsZero())
+
+	limits := model.OutputLimits{Volume: 10, Total: 20}
+	require.Equal(t, limits, OutputLimitsFromContext(ContextWithOutputLimits(context.Background(), limits)))
+}
+
+func TestCheckOutputLimits(t *testing.T) {
+	resultsDir := t.TempDir()
+	outputs := []model.StorageSpec{{Name: "outputs"}, {Name: "logs"}}
+	require.NoError(t, os.WriteFile(filepath.Join(resultsDir, model.DownloadFilenameStdout), make([]byte, 5), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(resultsDir, "outputs", "nested"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(resultsDir, "outputs", "nested", "data"), make([]byte, 10), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(resultsDir, "logs"), 0755))
+
+	size, err := MeasureResults(resultsDir, outputs)
+	require.NoError(t, err)
+	require.Equal(t, ResultSize{Total: 15, Volumes: map[string]uint64{"outputs": 10, "logs": 0}}, size)
+
+	require.NoError(t, CheckOutputLimits(resultsDir, outputs, model.OutputLimits{}))
+	require.NoError(t, CheckOutputLimits(resultsDir, outputs, model.OutputLimits{Volume: 10, Total: 15}))
+
+	var limitErr *bacerrors.OutputLimitExceeded
+	err = CheckOutputLimits(resultsDir, outputs, model.OutputLimits{Volume: 9})
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, "outputs", limitErr.GetVolume())
+
+	err = CheckOutputLimits(resultsDir, outputs, model.OutputLimits{Total: 14})
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, "", limitErr.GetVolume())
+}
@@ -83,5 +83,19 @@ func (e *Executor) RunShard(ctx context.Context, shard model.JobShard, resultsDi
 	return dockerExecutor.RunShard(ctx, shard, resultsDir)
 }
 
+// GetShardLogs returns the logs of the docker container that runs the shard.
+func (e *Executor) GetShardLogs(ctx context.Context, shard model.JobShard, offset int) (executor.ShardLogs, bool, error) {
+	dockerExecutor, err := e.executors.Get(ctx, model.EngineDocker)
+	if err != nil {
+		return executor.ShardLogs{}, false, err
+	}
+	streamer, ok := dockerExecutor.(executor.LogStreamer)
+	if !ok {
+		return executor.ShardLogs{}, false, nil
+	}
+	return streamer.GetShardLogs(ctx, shard, offset)
+}
+
 // Compile-time check that Executor implements the Executor interface.
 var _ executor.Executor = (*Executor)(nil)
+var _ executor.LogStreamer = (*Executor)(nil)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/c2h5oh/datasize"
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/storage"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 	"github.com/filecoin-project/bacalhau/pkg/util/closer"
 	"go.ptx.dk/multierrgroup"
@@ -132,6 +133,22 @@ func WriteJobResults(resultsDir string, stdout, stderr io.Reader, exitcode int,
 	return result, err
 }
 
+// InputVersions returns the versions of the prepared input volumes, by the path the inputs are mounted at,
+// so that the result records exactly which content the job ran against.
+func InputVersions(volumes map[*model.StorageSpec]storage.StorageVolume) map[string]string {
+	var versions map[string]string
+	for spec, volume := range volumes {
+		if volume.Version == "" {
+			continue
+		}
+		if versions == nil {
+			versions = make(map[string]string)
+		}
+		versions[spec.Path] = volume.Version
+	}
+	return versions
+}
+
 func FailResult(err error) (*model.RunCommandResult, error) {
 	return &model.RunCommandResult{ErrorMsg: err.Error()}, err
 }
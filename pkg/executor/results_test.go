@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/storage"
 
 	"github.com/c2h5oh/datasize"
 	"github.com/stretchr/testify/require"
@@ -125,3 +126,13 @@ func TestJobResult(t *testing.T) {
 		require.Equal(t, expectedContents, string(actualContents))
 	}
 }
+
+func TestInputVersions(t *testing.T) {
+	require.Nil(t, InputVersions(map[*model.StorageSpec]storage.StorageVolume{
+		{Path: "/inputs/data"}: {Source: "/data"},
+	}))
+	require.Equal(t, map[string]string{"/inputs/repo": "0123abc"}, InputVersions(map[*model.StorageSpec]storage.StorageVolume{
+		{Path: "/inputs/data"}: {Source: "/data"},
+		{Path: "/inputs/repo"}: {Source: "/repo", Version: "0123abc"},
+	}))
+}
@@ -11,12 +11,16 @@ import (
 	"github.com/filecoin-project/bacalhau/pkg/executor/wasm"
 	"github.com/filecoin-project/bacalhau/pkg/ipfs"
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/s3"
 	"github.com/filecoin-project/bacalhau/pkg/storage"
+	"github.com/filecoin-project/bacalhau/pkg/storage/cache"
 	"github.com/filecoin-project/bacalhau/pkg/storage/combo"
 	filecoinunsealed "github.com/filecoin-project/bacalhau/pkg/storage/filecoin_unsealed"
+	"github.com/filecoin-project/bacalhau/pkg/storage/git"
 	"github.com/filecoin-project/bacalhau/pkg/storage/inline"
 	ipfs_storage "github.com/filecoin-project/bacalhau/pkg/storage/ipfs"
 	noop_storage "github.com/filecoin-project/bacalhau/pkg/storage/noop"
+	s3_storage "github.com/filecoin-project/bacalhau/pkg/storage/s3"
 	"github.com/filecoin-project/bacalhau/pkg/storage/tracing"
 	"github.com/filecoin-project/bacalhau/pkg/storage/url/urldownload"
 	"github.com/filecoin-project/bacalhau/pkg/system"
@@ -26,6 +30,11 @@ type StandardStorageProviderOptions struct {
 	API                  ipfs.Client
 	FilecoinUnsealedPath string
 	DownloadPath         string
+	S3                   s3.Config
+	// InputCache keeps the inputs prepared by the IPFS, URL, S3 and git storages across executions, if set
+	InputCache *cache.Cache
+	// AllowLocalGitRepositories allows git inputs to be cloned from the local filesystem, which is only meant for tests
+	AllowLocalGitRepositories bool
 }
 
 type StandardExecutorOptions struct {
@@ -55,7 +64,18 @@ func NewStandardStorageProvider(
 
 	inlineStorage := inline.NewStorage()
 
-	var useIPFSDriver storage.Storage = ipfsAPICopyStorage
+	s3Storage, err := s3_storage.NewStorage(cm, options.S3)
+	if err != nil {
+		return nil, err
+	}
+
+	gitStorage, err := git.NewStorage(cm, git.Config{AllowLocalRepositories: options.AllowLocalGitRepositories})
+	if err != nil {
+		return nil, err
+	}
+
+	cachedIPFSStorage := cache.Wrap(ipfsAPICopyStorage, options.InputCache)
+	var useIPFSDriver storage.Storage = cachedIPFSStorage
 
 	// if we are using a FilecoinUnsealedPath then construct a combo
 	// driver that will give preference to the filecoin unsealed driver
@@ -66,22 +86,22 @@ func NewStandardStorageProvider(
 			func(ctx context.Context) ([]storage.Storage, error) {
 				return []storage.Storage{
 					filecoinUnsealedStorage,
-					ipfsAPICopyStorage,
+					cachedIPFSStorage,
 				}, nil
 			},
 			func(ctx context.Context, spec model.StorageSpec) (storage.Storage, error) {
 				filecoinUnsealedHasCid, err := filecoinUnsealedStorage.HasStorageLocally(ctx, spec)
 				if err != nil {
-					return ipfsAPICopyStorage, err
+					return cachedIPFSStorage, err
 				}
 				if filecoinUnsealedHasCid {
 					return filecoinUnsealedStorage, nil
 				} else {
-					return ipfsAPICopyStorage, nil
+					return cachedIPFSStorage, nil
 				}
 			},
 			func(ctx context.Context) (storage.Storage, error) {
-				return ipfsAPICopyStorage, nil
+				return cachedIPFSStorage, nil
 			},
 		)
 
@@ -94,9 +114,11 @@ func NewStandardStorageProvider(
 
 	return model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{
 		model.StorageSourceIPFS:             tracing.Wrap(useIPFSDriver),
-		model.StorageSourceURLDownload:      tracing.Wrap(urlDownloadStorage),
+		model.StorageSourceURLDownload:      tracing.Wrap(cache.Wrap(urlDownloadStorage, options.InputCache)),
 		model.StorageSourceFilecoinUnsealed: tracing.Wrap(filecoinUnsealedStorage),
 		model.StorageSourceInline:           tracing.Wrap(inlineStorage),
+		model.StorageSourceS3:               tracing.Wrap(cache.Wrap(s3Storage, options.InputCache)),
+		model.StorageSourceGit:              tracing.Wrap(cache.Wrap(gitStorage, options.InputCache)),
 	}), nil
 }
 
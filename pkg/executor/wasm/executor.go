@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
 
 	"github.com/c2h5oh/datasize"
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
 	"github.com/filecoin-project/bacalhau/pkg/executor"
 	"golang.org/x/exp/maps"
 
@@ -20,15 +22,19 @@ import (
 	"github.com/filecoin-project/bacalhau/pkg/system"
 	"github.com/filecoin-project/bacalhau/pkg/util/filefs"
 	"github.com/filecoin-project/bacalhau/pkg/util/mountfs"
+	"github.com/filecoin-project/bacalhau/pkg/util/quotafs"
 	"github.com/filecoin-project/bacalhau/pkg/util/touchfs"
 	"github.com/rs/zerolog/log"
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 	"github.com/tetratelabs/wazero/sys"
+	"go.uber.org/multierr"
 )
 
 type Executor struct {
 	StorageProvider storage.StorageProvider
+
+	logs *executor.LogBuffers
 }
 
 func NewExecutor(
@@ -37,6 +43,7 @@ func NewExecutor(
 ) (*Executor, error) {
 	return &Executor{
 		StorageProvider: storageProvider,
+		logs:            executor.NewLogBuffers(),
 	}, nil
 }
 
@@ -73,14 +80,27 @@ func (e *Executor) GetVolumeSize(ctx context.Context, volume model.StorageSpec)
 //
 //   - mount each input at the name specified by Path
 //   - make a directory in the job results directory for each output and mount that
-//     at the name specified by Name
-func (e *Executor) makeFsFromStorage(ctx context.Context, jobResultsDir string, inputs, outputs []model.StorageSpec) (fs.FS, error) {
+//     at the name specified by Name, limiting the writes to it to the output limits
+func (e *Executor) makeFsFromStorage(
+	ctx context.Context,
+	jobResultsDir string,
+	inputs, outputs []model.StorageSpec,
+	limits model.OutputLimits,
+) (fs.FS, map[*model.StorageSpec]storage.StorageVolume, []*quotafs.Quota, error) {
 	var err error
 	rootFs := mountfs.New()
 
 	volumes, err := storage.ParallelPrepareStorage(ctx, e.StorageProvider, inputs)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
+	}
+
+	var quotas, totalQuotas []*quotafs.Quota
+	if limits.Total > 0 {
+		totalQuotas = append(totalQuotas, quotafs.NewQuota(limits.Total, func(size uint64) error {
+			return bacerrors.NewOutputLimitExceeded("", size, limits.Total)
+		}))
+		quotas = append(quotas, totalQuotas...)
 	}
 
 	for input, volume := range volumes {
@@ -89,7 +109,7 @@ func (e *Executor) makeFsFromStorage(ctx context.Context, jobResultsDir string,
 		var stat os.FileInfo
 		stat, err = os.Stat(volume.Source)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 
 		var inputFs fs.FS
@@ -101,17 +121,17 @@ func (e *Executor) makeFsFromStorage(ctx context.Context, jobResultsDir string,
 
 		err = rootFs.Mount(input.Path, inputFs)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 	}
 
 	for _, output := range outputs {
 		if output.Name == "" {
-			return nil, fmt.Errorf("output volume has no name: %+v", output)
+			return nil, nil, nil, fmt.Errorf("output volume has no name: %+v", output)
 		}
 
 		if output.Path == "" {
-			return nil, fmt.Errorf("output volume has no path: %+v", output)
+			return nil, nil, nil, fmt.Errorf("output volume has no path: %+v", output)
 		}
 
 		srcd := filepath.Join(jobResultsDir, output.Name)
@@ -119,16 +139,26 @@ func (e *Executor) makeFsFromStorage(ctx context.Context, jobResultsDir string,
 
 		err = os.Mkdir(srcd, util.OS_ALL_R|util.OS_ALL_X|util.OS_USER_W)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
+		}
+
+		volumeQuotas := totalQuotas
+		if limits.Volume > 0 {
+			name := output.Name
+			volumeQuota := quotafs.NewQuota(limits.Volume, func(size uint64) error {
+				return bacerrors.NewOutputLimitExceeded(name, size, limits.Volume)
+			})
+			quotas = append(quotas, volumeQuota)
+			volumeQuotas = append([]*quotafs.Quota{volumeQuota}, totalQuotas...)
 		}
 
-		err = rootFs.Mount(output.Name, touchfs.New(srcd))
+		err = rootFs.Mount(output.Name, quotafs.New(touchfs.New(srcd), volumeQuotas...))
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 	}
 
-	return rootFs, nil
+	return rootFs, volumes, quotas, nil
 }
 
 //nolint:funlen  // Will be made shorter when we do more module linking
@@ -172,25 +202,29 @@ func (e *Executor) RunShard(
 		return executor.FailResult(err)
 	}
 
-	fs, err := e.makeFsFromStorage(ctx, jobResultsDir, shardStorageSpec, shard.Job.Spec.Outputs)
+	fs, inputVolumes, outputQuotas, err := e.makeFsFromStorage(
+		ctx, jobResultsDir, shardStorageSpec, shard.Job.Spec.Outputs, executor.OutputLimitsFromContext(ctx))
 	if err != nil {
 		return executor.FailResult(err)
 	}
 
 	// Configure the modules. We will write STDOUT and STDERR to a buffer so
-	// that we can later include them in the job results. We don't want to
+	// that we can later include them in the job results, and to the shard logs
+	// so that they can be streamed while the module runs. We don't want to
 	// execute any start functions automatically as we will do it manually
 	// later. Finally, add the filesystem which contains our input and output.
 	stdout := new(bytes.Buffer)
 	stderr := new(bytes.Buffer)
+	logs, finishLogs := e.logs.Open(shard)
+	defer finishLogs()
 
 	args := []string{module.Name()}
 	args = append(args, wasmSpec.Parameters...)
 
 	config := wazero.NewModuleConfig().
 		WithStartFunctions().
-		WithStdout(stdout).
-		WithStderr(stderr).
+		WithStdout(io.MultiWriter(stdout, logs.Stdout())).
+		WithStderr(io.MultiWriter(stderr, logs.Stderr())).
 		WithArgs(args...).
 		WithFS(fs)
 
@@ -258,8 +292,24 @@ func (e *Executor) RunShard(
 		}
 	}
 
-	return executor.WriteJobResults(jobResultsDir, stdout, stderr, exitCode, wasmErr)
+	// A module that exceeded an output limit usually fails on the failed write, so the exceeded limit is
+	// reported as the reason of the failure.
+	for _, quota := range outputQuotas {
+		if limitErr := quota.Err(); limitErr != nil {
+			wasmErr = multierr.Append(limitErr, wasmErr)
+			break
+		}
+	}
+
+	result, err := executor.WriteJobResults(jobResultsDir, stdout, stderr, exitCode, wasmErr)
+	result.InputVersions = executor.InputVersions(inputVolumes)
+	return result, err
+}
+
+func (e *Executor) GetShardLogs(ctx context.Context, shard model.JobShard, offset int) (executor.ShardLogs, bool, error) {
+	return e.logs.GetShardLogs(ctx, shard, offset)
 }
 
 // Compile-time check that Executor implements the Executor interface.
 var _ executor.Executor = (*Executor)(nil)
+var _ executor.LogStreamer = (*Executor)(nil)
@@ -0,0 +1,112 @@
+//go:build unit || !integration
+
+package wasm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/executor"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/storage"
+	"github.com/filecoin-project/bacalhau/pkg/storage/inline"
+	"github.com/filecoin-project/bacalhau/testdata/wasm/csv"
+	"github.com/filecoin-project/bacalhau/testdata/wasm/noop"
+	"github.com/stretchr/testify/require"
+	"github.com/vincent-petithory/dataurl"
+)
+
+func TestRunShardStreamsLogs(t *testing.T) {
+	ctx := context.Background()
+	storageProvider := model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{
+		model.StorageSourceInline: inline.NewStorage(),
+	})
+	executor, err := NewExecutor(ctx, storageProvider)
+	require.NoError(t, err)
+
+	shard := model.JobShard{
+		Job: &model.Job{
+			Metadata: model.Metadata{ID: "job-id"},
+			Spec: model.Spec{
+				Engine: model.EngineWasm,
+				Wasm: model.JobSpecWasm{
+					EntryPoint: "_start",
+					EntryModule: model.StorageSpec{
+						StorageSource: model.StorageSourceInline,
+						URL:           dataurl.EncodeBytes(noop.Program()),
+					},
+				},
+			},
+		},
+	}
+
+	_, found, err := executor.GetShardLogs(ctx, shard, 0)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	result, err := executor.RunShard(ctx, shard, t.TempDir())
+	require.NoError(t, err)
+	require.Empty(t, result.ErrorMsg)
+
+	logs, found, err := executor.GetShardLogs(ctx, shard, 0)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.True(t, logs.Finished)
+	require.Equal(t, []model.LogEntry{{Stream: model.LogStreamStdout, Data: "Hello, world!\n"}}, logs.Logs)
+	require.Equal(t, result.STDOUT, logs.Logs[0].Data)
+
+	logs, found, err = executor.GetShardLogs(ctx, shard, logs.NextOffset)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Empty(t, logs.Logs)
+}
+
+func TestRunShardEnforcesOutputLimits(t *testing.T) {
+	ctx := context.Background()
+	storageProvider := model.NewMappedProvider(map[model.StorageSourceType]storage.Storage{
+		model.StorageSourceInline: inline.NewStorage(),
+	})
+	wasmExecutor, err := NewExecutor(ctx, storageProvider)
+	require.NoError(t, err)
+
+	input, err := os.ReadFile("../../../testdata/wasm/csv/inputs/horses.csv")
+	require.NoError(t, err)
+	shard := model.JobShard{
+		Job: &model.Job{
+			Metadata: model.Metadata{ID: "job-id"},
+			Spec: model.Spec{
+				Engine: model.EngineWasm,
+				Wasm: model.JobSpecWasm{
+					EntryPoint: "_start",
+					EntryModule: model.StorageSpec{
+						StorageSource: model.StorageSourceInline,
+						URL:           dataurl.EncodeBytes(csv.Program()),
+					},
+					Parameters: []string{"inputs", "outputs/parents-children.csv"},
+				},
+				Inputs: []model.StorageSpec{{
+					StorageSource: model.StorageSourceInline,
+					URL:           dataurl.EncodeBytes(input),
+					Path:          "/inputs",
+				}},
+				Outputs: []model.StorageSpec{{Name: "outputs", Path: "/outputs"}},
+			},
+		},
+	}
+
+	limitedCtx := executor.ContextWithOutputLimits(ctx, model.OutputLimits{Volume: 1024})
+	_, err = wasmExecutor.RunShard(limitedCtx, shard, t.TempDir())
+	require.Error(t, err)
+	var limitErr *bacerrors.OutputLimitExceeded
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, "outputs", limitErr.GetVolume())
+
+	resultsDir := t.TempDir()
+	result, err := wasmExecutor.RunShard(ctx, shard, resultsDir)
+	require.NoError(t, err)
+	require.Equal(t, 0, result.ExitCode)
+	require.FileExists(t, filepath.Join(resultsDir, "outputs", "parents-children.csv"))
+}
@@ -27,6 +27,20 @@ func ParseNodeSelector(nodeSelector string) ([]model.LabelSelectorRequirement, e
 	return model.ToLabelSelectorRequirements(requirements...), nil
 }
 
+// ParseAnnotationSelector parses a label selector that matches job annotations, such as "project=foo,!canary".
+// Annotations of the form "key:value" are matched as a key and a value.
+func ParseAnnotationSelector(annotationSelector string) ([]model.LabelSelectorRequirement, error) {
+	selector := strings.TrimSpace(annotationSelector)
+	if len(selector) == 0 {
+		return []model.LabelSelectorRequirement{}, nil
+	}
+	requirements, err := labels.ParseToRequirements(selector)
+	if err != nil {
+		return []model.LabelSelectorRequirement{}, fmt.Errorf("failed to parse annotation selector: %w", err)
+	}
+	return model.ToLabelSelectorRequirements(requirements...), nil
+}
+
 func SafeStringStripper(s string) string {
 	rChars := SafeAnnotationRegex()
 	return rChars.ReplaceAllString(s, "")
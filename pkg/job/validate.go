@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/filecoin-project/bacalhau/pkg/compute/capacity"
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/util/cron"
 )
 
 // VerifyJobCreatePayload verifies the values in a job creation request are legal.
@@ -42,6 +44,22 @@ func VerifyJob(ctx context.Context, j *model.Job) error {
 		return fmt.Errorf("confidence must be >= 0")
 	}
 
+	if j.Spec.Deal.RetryPolicy.MaxAttempts < 0 {
+		return fmt.Errorf("retry max attempts must be >= 0")
+	}
+
+	if j.Spec.Deal.RetryPolicy.Backoff < 0 {
+		return fmt.Errorf("retry backoff must be >= 0")
+	}
+
+	if !(j.Spec.Deal.SpotCheckFraction >= 0 && j.Spec.Deal.SpotCheckFraction <= 1) {
+		return fmt.Errorf("spot check fraction must be between 0 and 1")
+	}
+
+	if j.Spec.Verifier == model.VerifierSpotCheck && j.Spec.Deal.SpotCheckFraction == 0 {
+		return fmt.Errorf("the spot check verifier requires a spot check fraction greater than 0")
+	}
+
 	if !model.IsValidEngine(j.Spec.Engine) {
 		return fmt.Errorf("invalid executor type: %s", j.Spec.Engine.String())
 	}
@@ -58,11 +76,28 @@ func VerifyJob(ctx context.Context, j *model.Job) error {
 		return err
 	}
 
+	if err := capacity.ValidateOutputLimitsConfig(j.Spec.OutputLimits); err != nil {
+		return err
+	}
+
+	if err := j.Spec.VerificationRules.IsValid(); err != nil {
+		return err
+	}
+
+	for _, name := range j.Spec.VerificationRules.OutputVolumes {
+		if !hasOutputVolume(j.Spec.Outputs, name) {
+			return fmt.Errorf("verification rules compare output volume %s, which the job does not have", name)
+		}
+	}
+
 	if j.Spec.Deal.Confidence > j.Spec.Deal.Concurrency {
 		return fmt.Errorf("the deal confidence cannot be higher than the concurrency")
 	}
 
 	for _, inputVolume := range j.Spec.Inputs {
+		if inputVolume.Upstream != nil {
+			return fmt.Errorf("input volume %s references an upstream result outside of a workflow", inputVolume.Path)
+		}
 		if !model.IsValidStorageSourceType(inputVolume.StorageSource) {
 			return fmt.Errorf("invalid input volume type: %s", inputVolume.StorageSource.String())
 		}
@@ -70,3 +105,127 @@ func VerifyJob(ctx context.Context, j *model.Job) error {
 
 	return nil
 }
+
+func hasOutputVolume(outputs []model.StorageSpec, name string) bool {
+	for _, output := range outputs {
+		if output.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyWorkflowCreatePayload verifies the values in a workflow creation request are legal, including that
+// the dependencies between its jobs exist and have no cycles.
+func VerifyWorkflowCreatePayload(ctx context.Context, wc *model.WorkflowCreatePayload) error {
+	if wc.ClientID == "" {
+		return fmt.Errorf("ClientID is empty")
+	}
+
+	if wc.APIVersion == "" {
+		return fmt.Errorf("APIVersion is empty")
+	}
+
+	if wc.Spec == nil || len(wc.Spec.Jobs) == 0 {
+		return fmt.Errorf("workflow has no jobs")
+	}
+
+	jobs := make(map[string]model.WorkflowJobSpec, len(wc.Spec.Jobs))
+	for _, workflowJob := range wc.Spec.Jobs {
+		if workflowJob.Name == "" {
+			return fmt.Errorf("workflow job name is empty")
+		}
+		if _, ok := jobs[workflowJob.Name]; ok {
+			return fmt.Errorf("duplicate workflow job name: %s", workflowJob.Name)
+		}
+		jobs[workflowJob.Name] = workflowJob
+	}
+
+	for _, workflowJob := range wc.Spec.Jobs {
+		for _, dependency := range workflowJob.Dependencies() {
+			if _, ok := jobs[dependency]; !ok {
+				return fmt.Errorf("workflow job %s depends on unknown job %s", workflowJob.Name, dependency)
+			}
+		}
+
+		// upstream inputs are only resolved once the upstream jobs complete, so we verify the rest of the job
+		spec := workflowJob.Spec
+		spec.Inputs = nil
+		for _, input := range workflowJob.Spec.Inputs {
+			if input.Upstream != nil {
+				if input.Upstream.ShardIndex < 0 {
+					return fmt.Errorf("workflow job %s references a negative shard index of job %s",
+						workflowJob.Name, input.Upstream.Job)
+				}
+				continue
+			}
+			spec.Inputs = append(spec.Inputs, input)
+		}
+		if err := VerifyJob(ctx, &model.Job{APIVersion: wc.APIVersion, Spec: spec}); err != nil {
+			return fmt.Errorf("workflow job %s is invalid: %w", workflowJob.Name, err)
+		}
+	}
+
+	return verifyWorkflowIsAcyclic(jobs)
+}
+
+// verifyWorkflowIsAcyclic returns an error if the dependencies between the workflow jobs form a cycle.
+func verifyWorkflowIsAcyclic(jobs map[string]model.WorkflowJobSpec) error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	marks := make(map[string]int, len(jobs))
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch marks[name] {
+		case visiting:
+			return fmt.Errorf("workflow has a dependency cycle through job %s", name)
+		case visited:
+			return nil
+		}
+		marks[name] = visiting
+		for _, dependency := range jobs[name].Dependencies() {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		marks[name] = visited
+		return nil
+	}
+	for name := range jobs {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyScheduleCreatePayload verifies the values in a schedule creation request are legal, including its cron
+// expression and the job that is submitted at each run.
+func VerifyScheduleCreatePayload(ctx context.Context, sc *model.ScheduleCreatePayload) error {
+	if sc.ClientID == "" {
+		return fmt.Errorf("ClientID is empty")
+	}
+
+	if sc.APIVersion == "" {
+		return fmt.Errorf("APIVersion is empty")
+	}
+
+	if sc.Spec == nil {
+		return fmt.Errorf("schedule spec is empty")
+	}
+
+	if _, err := cron.Parse(sc.Spec.Cron); err != nil {
+		return fmt.Errorf("invalid cron expression: %w", err)
+	}
+
+	if !model.IsValidScheduleOverlapPolicy(sc.Spec.OverlapPolicy) {
+		return fmt.Errorf("invalid overlap policy: %d", sc.Spec.OverlapPolicy)
+	}
+
+	if err := VerifyJob(ctx, &model.Job{APIVersion: sc.APIVersion, Spec: sc.Spec.JobSpec}); err != nil {
+		return fmt.Errorf("scheduled job is invalid: %w", err)
+	}
+	return nil
+}
@@ -32,6 +32,19 @@ func (e ErrJobAlreadyExists) Error() string {
 	return "job already exists: " + e.JobID
 }
 
+// ErrInvalidJobQuery is returned when a job query can't be run, such as when it sorts by an unknown field
+type ErrInvalidJobQuery struct {
+	Reason string
+}
+
+func NewErrInvalidJobQuery(reason string) ErrInvalidJobQuery {
+	return ErrInvalidJobQuery{Reason: reason}
+}
+
+func (e ErrInvalidJobQuery) Error() string {
+	return "invalid job query: " + e.Reason
+}
+
 // ErrInvalidJobState is returned when an job is in an invalid state.
 type ErrInvalidJobState struct {
 	JobID    string
@@ -81,6 +94,21 @@ func (e ErrJobAlreadyTerminal) Error() string {
 		e.JobID, e.Actual.String(), e.NewState.String())
 }
 
+// ErrInvalidJobRequester is returned when a job is not owned by the expected requester node.
+type ErrInvalidJobRequester struct {
+	JobID    string
+	Actual   string
+	Expected string
+}
+
+func NewErrInvalidJobRequester(id string, actual string, expected string) ErrInvalidJobRequester {
+	return ErrInvalidJobRequester{JobID: id, Actual: actual, Expected: expected}
+}
+
+func (e ErrInvalidJobRequester) Error() string {
+	return fmt.Sprintf("job %s is owned by requester %s but expected %s", e.JobID, e.Actual, e.Expected)
+}
+
 // ErrShardNotFound is returned when the Shard is not found
 type ErrShardNotFound struct {
 	ShardID model.ShardID
@@ -216,3 +244,82 @@ func (e ErrExecutionAlreadyTerminal) Error() string {
 	return fmt.Sprintf("execution %s is in terminal state %s and cannot transition to %s",
 		e.ExecutionID, e.Actual.String(), e.NewState.String())
 }
+
+// ErrLeaseNotFound is returned when no lease was ever acquired on a resource
+type ErrLeaseNotFound struct {
+	Name string
+}
+
+func NewErrLeaseNotFound(name string) ErrLeaseNotFound {
+	return ErrLeaseNotFound{Name: name}
+}
+
+func (e ErrLeaseNotFound) Error() string {
+	return "lease not found: " + e.Name
+}
+
+// ErrLeaseHeld is returned when a lease cannot be acquired because another holder has an unexpired lease
+type ErrLeaseHeld struct {
+	Name   string
+	Holder string
+}
+
+func NewErrLeaseHeld(name string, holder string) ErrLeaseHeld {
+	return ErrLeaseHeld{Name: name, Holder: holder}
+}
+
+func (e ErrLeaseHeld) Error() string {
+	return fmt.Sprintf("lease %s is held by %s", e.Name, e.Holder)
+}
+
+// ErrScheduleNotFound is returned when a schedule was not found
+type ErrScheduleNotFound struct {
+	ID string
+}
+
+func NewErrScheduleNotFound(id string) ErrScheduleNotFound {
+	return ErrScheduleNotFound{ID: id}
+}
+
+func (e ErrScheduleNotFound) Error() string {
+	return "schedule not found: " + e.ID
+}
+
+// ErrScheduleAlreadyExists is returned when a schedule already exists
+type ErrScheduleAlreadyExists struct {
+	ID string
+}
+
+func NewErrScheduleAlreadyExists(id string) ErrScheduleAlreadyExists {
+	return ErrScheduleAlreadyExists{ID: id}
+}
+
+func (e ErrScheduleAlreadyExists) Error() string {
+	return "schedule already exists: " + e.ID
+}
+
+// ErrWorkflowNotFound is returned when a workflow was not found
+type ErrWorkflowNotFound struct {
+	ID string
+}
+
+func NewErrWorkflowNotFound(id string) ErrWorkflowNotFound {
+	return ErrWorkflowNotFound{ID: id}
+}
+
+func (e ErrWorkflowNotFound) Error() string {
+	return "workflow not found: " + e.ID
+}
+
+// ErrWorkflowAlreadyExists is returned when a workflow already exists
+type ErrWorkflowAlreadyExists struct {
+	ID string
+}
+
+func NewErrWorkflowAlreadyExists(id string) ErrWorkflowAlreadyExists {
+	return ErrWorkflowAlreadyExists{ID: id}
+}
+
+func (e ErrWorkflowAlreadyExists) Error() string {
+	return "workflow already exists: " + e.ID
+}
@@ -20,19 +20,35 @@ const newJobComment = "Job created"
 
 type JobStore struct {
 	// we keep pointers to these things because we will update them partially
-	jobs       map[string]model.Job
-	states     map[string]model.JobState
-	history    map[string][]model.JobHistory
-	inprogress map[string]struct{}
-	mtx        sync.RWMutex
+	jobs        map[string]model.Job
+	states      map[string]model.JobState
+	history     map[string][]model.JobHistory
+	inprogress  map[string]struct{}
+	leases      map[string]jobstore.Lease
+	reputations map[string]model.NodeReputation
+	seeds       map[string]int64
+	schedules   map[string]model.Schedule
+	workflows   map[string]model.Workflow
+	// the total result size of the completed executions of each client
+	resultUsage map[string]uint64
+	// the total resources requested by the in progress jobs of each client
+	resourceUsage map[string]model.ResourceUsageData
+	mtx           sync.RWMutex
 }
 
 func NewJobStore() *JobStore {
 	res := &JobStore{
-		jobs:       make(map[string]model.Job),
-		states:     make(map[string]model.JobState),
-		history:    make(map[string][]model.JobHistory),
-		inprogress: make(map[string]struct{}),
+		jobs:          make(map[string]model.Job),
+		states:        make(map[string]model.JobState),
+		history:       make(map[string][]model.JobHistory),
+		inprogress:    make(map[string]struct{}),
+		leases:        make(map[string]jobstore.Lease),
+		reputations:   make(map[string]model.NodeReputation),
+		seeds:         make(map[string]int64),
+		schedules:     make(map[string]model.Schedule),
+		workflows:     make(map[string]model.Workflow),
+		resultUsage:   make(map[string]uint64),
+		resourceUsage: make(map[string]model.ResourceUsageData),
 	}
 	res.mtx.EnableTracerWithOpts(sync.Opts{
 		Threshold: 10 * time.Millisecond,
@@ -65,11 +81,19 @@ func (d *JobStore) GetJobs(ctx context.Context, query jobstore.JobQuery) ([]mode
 		return []model.Job{j}, nil
 	}
 
-	for _, j := range maps.Values(d.jobs) {
-		if query.Limit > 0 && len(result) == query.Limit {
-			break
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	var cursor *jobstore.JobCursor
+	if query.Cursor != "" {
+		parsed, err := jobstore.ParseJobCursor(query.Cursor)
+		if err != nil {
+			return nil, err
 		}
+		cursor = &parsed
+	}
 
+	for _, j := range maps.Values(d.jobs) {
 		if !query.ReturnAll && query.ClientID != "" && query.ClientID != j.Metadata.ClientID {
 			// Job is not for the requesting client, so ignore it.
 			continue
@@ -89,36 +113,70 @@ func (d *JobStore) GetJobs(ctx context.Context, query jobstore.JobQuery) ([]mode
 			}
 		}
 
-		if !included {
+		if !included || !d.matchesFilters(j, query) {
+			continue
+		}
+		if cursor != nil && !cursor.IsAfter(j, query.SortBy, query.SortReverse) {
 			continue
 		}
 
 		result = append(result, j)
 	}
 
+	// sort by creation time by default, and by ID for jobs created at the same time
 	listSorter := func(i, j int) bool {
-		switch query.SortBy {
-		case "id":
-			if query.SortReverse {
-				// what does it mean to sort by ID?
-				return result[i].Metadata.ID > result[j].Metadata.ID
-			} else {
-				return result[i].Metadata.ID < result[j].Metadata.ID
-			}
-		case "created_at":
-			if query.SortReverse {
-				return result[i].Metadata.CreatedAt.UTC().Unix() > result[j].Metadata.CreatedAt.UTC().Unix()
-			} else {
-				return result[i].Metadata.CreatedAt.UTC().Unix() < result[j].Metadata.CreatedAt.UTC().Unix()
-			}
-		default:
-			return false
-		}
+		return jobstore.JobCursorOf(result[i]).IsAfter(result[j], query.SortBy, query.SortReverse)
 	}
 	sort.Slice(result, listSorter)
+
+	if query.Offset > 0 {
+		if query.Offset >= len(result) {
+			return nil, nil
+		}
+		result = result[query.Offset:]
+	}
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
 	return result, nil
 }
 
+// matchesFilters returns true if the job matches the state, creation time, engine, publisher, node
+// and annotation filters of the query.
+func (d *JobStore) matchesFilters(j model.Job, query jobstore.JobQuery) bool {
+	state := d.states[j.Metadata.ID]
+	if len(query.States) > 0 && !slices.Contains(query.States, state.State) {
+		return false
+	}
+	if !query.CreatedAfter.IsZero() && !j.Metadata.CreatedAt.After(query.CreatedAfter) {
+		return false
+	}
+	if !query.CreatedBefore.IsZero() && !j.Metadata.CreatedAt.Before(query.CreatedBefore) {
+		return false
+	}
+	if len(query.Engines) > 0 && !slices.Contains(query.Engines, j.Spec.Engine) {
+		return false
+	}
+	if len(query.Publishers) > 0 && !slices.Contains(query.Publishers, j.Spec.Publisher) {
+		return false
+	}
+	if query.NodeID != "" && !executedOnNode(state, query.NodeID) {
+		return false
+	}
+	return jobstore.MatchesAnnotationSelectors(j.Spec.Annotations, query.AnnotationSelectors)
+}
+
+func executedOnNode(state model.JobState, nodeID string) bool {
+	for _, shard := range state.Shards {
+		for _, execution := range shard.Executions {
+			if execution.NodeID == nodeID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (d *JobStore) GetJobState(_ context.Context, jobID string) (model.JobState, error) {
 	d.mtx.RLock()
 	defer d.mtx.RUnlock()
@@ -156,6 +214,7 @@ func (d *JobStore) GetJobsCount(ctx context.Context, query jobstore.JobQuery) (i
 	useQuery := query
 	useQuery.Limit = 0
 	useQuery.Offset = 0
+	useQuery.Cursor = ""
 	jobs, err := d.GetJobs(ctx, useQuery)
 	if err != nil {
 		return 0, err
@@ -196,6 +255,7 @@ func (d *JobStore) CreateJob(_ context.Context, job model.Job) error {
 	}
 	d.states[job.Metadata.ID] = jobState
 	d.inprogress[job.Metadata.ID] = struct{}{}
+	d.resourceUsage[job.Metadata.ClientID] = d.resourceUsage[job.Metadata.ClientID].Add(jobstore.JobResources(job.Spec))
 	d.appendJobHistory(jobState, model.JobStateNew, newJobComment)
 	return nil
 }
@@ -254,6 +314,8 @@ func (d *JobStore) UpdateJobState(_ context.Context, request jobstore.UpdateJobS
 	d.states[request.JobID] = jobState
 	if request.NewState.IsTerminal() {
 		delete(d.inprogress, request.JobID)
+		job := d.jobs[request.JobID]
+		d.resourceUsage[job.Metadata.ClientID] = d.resourceUsage[job.Metadata.ClientID].Sub(jobstore.JobResources(job.Spec))
 	}
 	d.appendJobHistory(jobState, previousState, request.Comment)
 	return nil
@@ -298,6 +360,9 @@ func (d *JobStore) UpdateShardState(_ context.Context, request jobstore.UpdateSh
 	// update the shard state
 	previousState := shardState.State
 	shardState.State = request.NewState
+	if request.NewTrust != model.ShardTrustUnknown {
+		shardState.Trust = request.NewTrust
+	}
 	shardState.Version++
 	shardState.UpdateTime = time.Now()
 	jobState.Shards[request.ShardID.Index] = shardState
@@ -395,9 +460,270 @@ func (d *JobStore) UpdateExecution(_ context.Context, request jobstore.UpdateExe
 	jobState.Shards[newExecution.ShardIndex] = shardState
 	d.states[newExecution.JobID] = jobState
 	d.appendExecutionHistory(newExecution, previousState, request.Comment)
+	if newExecution.State == model.ExecutionStateCompleted {
+		d.resultUsage[d.jobs[newExecution.JobID].Metadata.ClientID] += newExecution.ResultSize
+	}
+	return nil
+}
+
+func (d *JobStore) UpdateJobRequester(_ context.Context, request jobstore.UpdateJobRequesterRequest) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	job, ok := d.jobs[request.JobID]
+	if !ok {
+		return jobstore.NewErrJobNotFound(request.JobID)
+	}
+	if job.Metadata.Requester.RequesterNodeID != request.ExpectedRequesterNodeID {
+		return jobstore.NewErrInvalidJobRequester(
+			request.JobID, job.Metadata.Requester.RequesterNodeID, request.ExpectedRequesterNodeID)
+	}
+
+	job.Metadata.Requester = request.NewRequester
+	d.jobs[request.JobID] = job
+	jobState := d.states[request.JobID]
+	d.appendJobHistory(jobState, jobState.State, request.Comment)
+	return nil
+}
+
+func (d *JobStore) AcquireLease(_ context.Context, request jobstore.AcquireLeaseRequest) (jobstore.Lease, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	now := time.Now()
+	existing, ok := d.leases[request.Name]
+	if ok && existing.Holder != request.Holder && !existing.IsExpired(now) {
+		return jobstore.Lease{}, jobstore.NewErrLeaseHeld(request.Name, existing.Holder)
+	}
+	lease := jobstore.Lease{
+		Name:      request.Name,
+		Holder:    request.Holder,
+		ExpiresAt: now.Add(request.Duration),
+	}
+	d.leases[request.Name] = lease
+	return lease, nil
+}
+
+func (d *JobStore) ReleaseLease(_ context.Context, name string, holder string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	existing, ok := d.leases[name]
+	if !ok {
+		return jobstore.NewErrLeaseNotFound(name)
+	}
+	if existing.Holder != holder {
+		return jobstore.NewErrLeaseHeld(name, existing.Holder)
+	}
+	delete(d.leases, name)
+	return nil
+}
+
+func (d *JobStore) GetLease(_ context.Context, name string) (jobstore.Lease, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	lease, ok := d.leases[name]
+	if !ok {
+		return jobstore.Lease{}, jobstore.NewErrLeaseNotFound(name)
+	}
+	return lease, nil
+}
+
+func (d *JobStore) RecordNodeOutcome(_ context.Context, nodeID string, outcome model.NodeOutcome) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	reputation, ok := d.reputations[nodeID]
+	if !ok {
+		reputation.NodeID = nodeID
+	}
+	reputation.Record(outcome)
+	reputation.UpdateTime = time.Now()
+	d.reputations[nodeID] = reputation
+	return nil
+}
+
+func (d *JobStore) GetNodeReputation(_ context.Context, nodeID string) (model.NodeReputation, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	reputation, ok := d.reputations[nodeID]
+	if !ok {
+		return model.NodeReputation{NodeID: nodeID}, nil
+	}
+	return reputation, nil
+}
+
+func (d *JobStore) GetNodeReputations(_ context.Context) ([]model.NodeReputation, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	reputations := maps.Values(d.reputations)
+	sort.Slice(reputations, func(i, j int) bool {
+		return reputations[i].NodeID < reputations[j].NodeID
+	})
+	return reputations, nil
+}
+
+func (d *JobStore) ResetNodeReputation(_ context.Context, nodeID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	delete(d.reputations, nodeID)
+	return nil
+}
+
+func (d *JobStore) SetSpotCheckSeed(_ context.Context, jobID string, seed int64) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	d.seeds[jobID] = seed
+	return nil
+}
+
+func (d *JobStore) GetSpotCheckSeed(_ context.Context, jobID string) (int64, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	return d.seeds[jobID], nil
+}
+
+func (d *JobStore) GetResultUsage(_ context.Context, clientID string) (uint64, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	return d.resultUsage[clientID], nil
+}
+
+func (d *JobStore) GetResourceUsage(_ context.Context, clientID string) (model.ResourceUsageData, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	return d.resourceUsage[clientID], nil
+}
+
+func (d *JobStore) CreateSchedule(_ context.Context, schedule model.Schedule) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.schedules[schedule.ID]; ok {
+		return jobstore.NewErrScheduleAlreadyExists(schedule.ID)
+	}
+	d.schedules[schedule.ID] = copySchedule(schedule)
 	return nil
 }
 
+func (d *JobStore) UpdateSchedule(_ context.Context, schedule model.Schedule) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.schedules[schedule.ID]; !ok {
+		return jobstore.NewErrScheduleNotFound(schedule.ID)
+	}
+	d.schedules[schedule.ID] = copySchedule(schedule)
+	return nil
+}
+
+func (d *JobStore) GetSchedule(_ context.Context, id string) (model.Schedule, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	schedule, ok := d.schedules[id]
+	if !ok {
+		return model.Schedule{}, jobstore.NewErrScheduleNotFound(id)
+	}
+	return copySchedule(schedule), nil
+}
+
+func (d *JobStore) GetSchedules(_ context.Context, requesterNodeID string) ([]model.Schedule, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	var schedules []model.Schedule
+	for _, schedule := range d.schedules {
+		if schedule.RequesterNodeID == requesterNodeID {
+			schedules = append(schedules, copySchedule(schedule))
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreateTime.Before(schedules[j].CreateTime)
+	})
+	return schedules, nil
+}
+
+func (d *JobStore) DeleteSchedule(_ context.Context, id string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.schedules[id]; !ok {
+		return jobstore.NewErrScheduleNotFound(id)
+	}
+	delete(d.schedules, id)
+	return nil
+}
+
+// copySchedule copies the run history of a schedule, so that callers can't modify the stored schedule
+func copySchedule(schedule model.Schedule) model.Schedule {
+	schedule.Runs = append([]model.ScheduleRun(nil), schedule.Runs...)
+	return schedule
+}
+
+func (d *JobStore) CreateWorkflow(_ context.Context, workflow model.Workflow) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.workflows[workflow.State.WorkflowID]; ok {
+		return jobstore.NewErrWorkflowAlreadyExists(workflow.State.WorkflowID)
+	}
+	d.workflows[workflow.State.WorkflowID] = copyWorkflow(workflow)
+	return nil
+}
+
+func (d *JobStore) UpdateWorkflow(_ context.Context, workflow model.Workflow) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if _, ok := d.workflows[workflow.State.WorkflowID]; !ok {
+		return jobstore.NewErrWorkflowNotFound(workflow.State.WorkflowID)
+	}
+	d.workflows[workflow.State.WorkflowID] = copyWorkflow(workflow)
+	return nil
+}
+
+func (d *JobStore) GetWorkflow(_ context.Context, id string) (model.Workflow, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	workflow, ok := d.workflows[id]
+	if !ok {
+		return model.Workflow{}, jobstore.NewErrWorkflowNotFound(id)
+	}
+	return copyWorkflow(workflow), nil
+}
+
+func (d *JobStore) GetInProgressWorkflows(_ context.Context, requesterNodeID string) ([]model.Workflow, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	var workflows []model.Workflow
+	for _, workflow := range d.workflows {
+		if workflow.RequesterNodeID == requesterNodeID && !workflow.State.State.IsTerminal() {
+			workflows = append(workflows, copyWorkflow(workflow))
+		}
+	}
+	sort.Slice(workflows, func(i, j int) bool {
+		return workflows[i].State.CreateTime.Before(workflows[j].State.CreateTime)
+	})
+	return workflows, nil
+}
+
+// copyWorkflow copies the job states of a workflow, so that callers can't modify the stored workflow
+func copyWorkflow(workflow model.Workflow) model.Workflow {
+	workflow.State.Jobs = append([]model.WorkflowJobState(nil), workflow.State.Jobs...)
+	return workflow
+}
+
 func (d *JobStore) appendJobHistory(updateJob model.JobState, previousState model.JobStateType, comment string) {
 	historyEntry := model.JobHistory{
 		Type:          model.JobHistoryTypeJobLevel,
@@ -0,0 +1,19 @@
+//go:build unit || !integration
+
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/test"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestInMemoryJobStoreSuite(t *testing.T) {
+	testingSuite := new(test.StoreSuite)
+	testingSuite.SetupHandler = func() jobstore.Store {
+		return NewJobStore()
+	}
+	suite.Run(t, testingSuite)
+}
@@ -0,0 +1,175 @@
+package jobstore
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+const (
+	// SortByID sorts jobs by their ID
+	SortByID = "id"
+	// SortByCreatedAt sorts jobs by their creation time, and is the default order
+	SortByCreatedAt = "created_at"
+
+	// AnnotationSeparator separates the key from the value of annotations matched by selectors
+	AnnotationSeparator = ":"
+
+	cursorSeparator = "/"
+)
+
+// JobCursor is the position of a job in a list of jobs, which is used to return the jobs that come after it.
+type JobCursor struct {
+	CreatedAt int64
+	ID        string
+}
+
+// JobCursorOf returns the position of the job
+func JobCursorOf(job model.Job) JobCursor {
+	return JobCursor{CreatedAt: CreatedAtNanos(job), ID: job.Metadata.ID}
+}
+
+// NewJobCursor returns an opaque cursor to list the jobs that come after the given job
+func NewJobCursor(job model.Job) string {
+	cursor := fmt.Sprintf("%d%s%s", CreatedAtNanos(job), cursorSeparator, job.Metadata.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor))
+}
+
+// NextJobCursor returns the cursor to list the next page of jobs, or an empty string if the query returned
+// its last page.
+func NextJobCursor(query JobQuery, jobs []model.Job) string {
+	if query.Limit <= 0 || len(jobs) < query.Limit {
+		return ""
+	}
+	return NewJobCursor(jobs[len(jobs)-1])
+}
+
+// ParseJobCursor decodes a cursor returned by NewJobCursor
+func ParseJobCursor(cursor string) (JobCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return JobCursor{}, fmt.Errorf("invalid cursor %q: %w", cursor, err)
+	}
+	createdAt, id, found := strings.Cut(string(decoded), cursorSeparator)
+	if !found {
+		return JobCursor{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	createdAtNanos, err := strconv.ParseInt(createdAt, 10, 64)
+	if err != nil {
+		return JobCursor{}, fmt.Errorf("invalid cursor %q: %w", cursor, err)
+	}
+	return JobCursor{CreatedAt: createdAtNanos, ID: id}, nil
+}
+
+// Validate returns an ErrInvalidJobQuery error if the query sorts by an unknown field, uses annotation selectors
+// that are not supported or has an invalid cursor.
+func (query JobQuery) Validate() error {
+	switch query.SortBy {
+	case SortByID, SortByCreatedAt, "":
+	default:
+		return NewErrInvalidJobQuery(fmt.Sprintf("invalid sort_by: %s", query.SortBy))
+	}
+	if err := ValidateAnnotationSelectors(query.AnnotationSelectors); err != nil {
+		return NewErrInvalidJobQuery(err.Error())
+	}
+	if query.Cursor != "" {
+		if _, err := ParseJobCursor(query.Cursor); err != nil {
+			return NewErrInvalidJobQuery(err.Error())
+		}
+	}
+	return nil
+}
+
+// IsAfter returns true if the job comes after the cursor, when sorting by the given field and order.
+// Jobs created at the same time are sorted by ID.
+func (c JobCursor) IsAfter(job model.Job, sortBy string, reverse bool) bool {
+	createdAt := CreatedAtNanos(job)
+	var cmp int
+	switch {
+	case sortBy == SortByID || createdAt == c.CreatedAt:
+		cmp = strings.Compare(job.Metadata.ID, c.ID)
+	case createdAt > c.CreatedAt:
+		cmp = 1
+	default:
+		cmp = -1
+	}
+	if reverse {
+		return cmp < 0
+	}
+	return cmp > 0
+}
+
+// CreatedAtNanos returns the creation time of the job in unix nanoseconds, or zero if it is not set.
+func CreatedAtNanos(job model.Job) int64 {
+	if job.Metadata.CreatedAt.IsZero() {
+		return 0
+	}
+	return job.Metadata.CreatedAt.UnixNano()
+}
+
+// ParseAnnotation splits an annotation into the key and value matched by annotation selectors.
+// Annotations of the form "key:value" are matched as a key and a value, and other annotations
+// are matched as a key with an empty value.
+func ParseAnnotation(annotation string) (key, value string) {
+	key, value, _ = strings.Cut(annotation, AnnotationSeparator)
+	return key, value
+}
+
+// ValidateAnnotationSelectors returns an error if the selectors use operators that are not supported for annotations
+func ValidateAnnotationSelectors(selectors []model.LabelSelectorRequirement) error {
+	for _, selector := range selectors {
+		switch selector.Operator {
+		case selection.Equals, selection.DoubleEquals, selection.In,
+			selection.NotEquals, selection.NotIn,
+			selection.Exists, selection.DoesNotExist:
+		default:
+			return fmt.Errorf("unsupported annotation selector operator %q for key %q", selector.Operator, selector.Key)
+		}
+	}
+	return nil
+}
+
+// MatchesAnnotationSelectors returns true if the annotations match all the selectors.
+// A selector matches if any annotation with its key matches, and negated selectors
+// match if no annotation with the key matches the values.
+func MatchesAnnotationSelectors(annotations []string, selectors []model.LabelSelectorRequirement) bool {
+	values := make(map[string][]string)
+	for _, annotation := range annotations {
+		key, value := ParseAnnotation(annotation)
+		values[key] = append(values[key], value)
+	}
+	hasValue := func(key string, expected []string) bool {
+		for _, value := range values[key] {
+			for _, e := range expected {
+				if value == e {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for _, selector := range selectors {
+		_, exists := values[selector.Key]
+		var matches bool
+		switch selector.Operator {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			matches = hasValue(selector.Key, selector.Values)
+		case selection.NotEquals, selection.NotIn:
+			matches = !hasValue(selector.Key, selector.Values)
+		case selection.Exists:
+			matches = exists
+		case selection.DoesNotExist:
+			matches = !exists
+		default:
+		}
+		if !matches {
+			return false
+		}
+	}
+	return true
+}
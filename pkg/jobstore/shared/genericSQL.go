@@ -0,0 +1,1749 @@
+package shared
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	sync "github.com/bacalhau-project/golang-mutex-tracer"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/imdario/mergo"
+	"k8s.io/apimachinery/pkg/selection"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	jobutils "github.com/filecoin-project/bacalhau/pkg/job"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+)
+
+const newJobComment = "Job created"
+
+// SQLClient is so we can pass *sql.DB and *sql.Tx to the same functions
+type SQLClient interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// GenericSQLJobStore is a jobstore.Store that persists jobs, their shards, executions and
+// history to a SQL database. Conditional updates are validated and applied within a single
+// transaction so that concurrent writers cannot overwrite each other's changes.
+type GenericSQLJobStore struct {
+	mtx              sync.RWMutex
+	connectionString string
+	db               *sql.DB
+}
+
+func NewGenericSQLJobStore(
+	db *sql.DB,
+	name string,
+	connectionString string,
+) (*GenericSQLJobStore, error) {
+	store := &GenericSQLJobStore{
+		connectionString: connectionString,
+		db:               db,
+	}
+	store.mtx.EnableTracerWithOpts(sync.Opts{
+		Threshold: 10 * time.Millisecond,
+		Id:        fmt.Sprintf("GenericSQLJobStore[%s].mtx", name),
+	})
+	return store, nil
+}
+
+func (d *GenericSQLJobStore) GetDB() *sql.DB {
+	return d.db
+}
+
+// Gets a job from the datastore.
+//
+// Errors:
+//
+//   - error-job-not-found        		  -- if the job is not found
+func (d *GenericSQLJobStore) GetJob(ctx context.Context, id string) (model.Job, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	return getJob(ctx, d.db, id)
+}
+
+func (d *GenericSQLJobStore) GetJobs(ctx context.Context, query jobstore.JobQuery) ([]model.Job, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	if query.ID != "" {
+		j, err := getJob(ctx, d.db, query.ID)
+		if err != nil {
+			return nil, err
+		}
+		return []model.Job{j}, nil
+	}
+
+	sqlQuery, args, err := getJobsSQL(query, false)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.Job
+	for rows.Next() {
+		var jobData string
+		if err = rows.Scan(&jobData); err != nil {
+			return nil, err
+		}
+		var j model.Job
+		if err = json.Unmarshal([]byte(jobData), &j); err != nil {
+			return nil, err
+		}
+		result = append(result, j)
+	}
+	return result, rows.Err()
+}
+
+func (d *GenericSQLJobStore) GetJobState(ctx context.Context, jobID string) (model.JobState, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	return getJobState(ctx, d.db, jobID)
+}
+
+func (d *GenericSQLJobStore) GetInProgressJobs(ctx context.Context) ([]model.JobWithInfo, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	var terminalStates []interface{}
+	var placeholders []string
+	for typ := model.JobStateNew; typ <= model.JobStateCompleted; typ++ {
+		if typ.IsTerminal() {
+			terminalStates = append(terminalStates, typ.String())
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(terminalStates)))
+		}
+	}
+
+	rows, err := d.db.QueryContext(ctx, fmt.Sprintf(
+		`select id from job where state not in (%s) order by created asc`, strings.Join(placeholders, ", ")),
+		terminalStates...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var jobIDs []string
+	for rows.Next() {
+		var jobID string
+		if err = rows.Scan(&jobID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		jobIDs = append(jobIDs, jobID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var result []model.JobWithInfo
+	for _, jobID := range jobIDs {
+		j, err := getJob(ctx, d.db, jobID)
+		if err != nil {
+			return nil, err
+		}
+		state, err := getJobState(ctx, d.db, jobID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, model.JobWithInfo{
+			Job:   j,
+			State: state,
+		})
+	}
+	return result, nil
+}
+
+func (d *GenericSQLJobStore) GetJobHistory(ctx context.Context, jobID string) ([]model.JobHistory, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	exists, err := jobExists(ctx, d.db, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, jobstore.NewErrJobNotFound(jobID)
+	}
+
+	rows, err := d.db.QueryContext(ctx, `select historydata from job_history where job_id = $1 order by seq asc`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []model.JobHistory
+	for rows.Next() {
+		var historyData string
+		if err = rows.Scan(&historyData); err != nil {
+			return nil, err
+		}
+		var entry model.JobHistory
+		if err = json.Unmarshal([]byte(historyData), &entry); err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+	return history, rows.Err()
+}
+
+func (d *GenericSQLJobStore) GetJobsCount(ctx context.Context, query jobstore.JobQuery) (int, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	if query.ID != "" {
+		_, err := getJob(ctx, d.db, query.ID)
+		if err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+
+	useQuery := query
+	useQuery.Limit = 0
+	useQuery.Offset = 0
+	useQuery.SortBy = ""
+	useQuery.Cursor = ""
+
+	sqlQuery, args, err := getJobsSQL(useQuery, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = d.db.QueryRowContext(ctx, sqlQuery, args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (d *GenericSQLJobStore) CreateJob(ctx context.Context, j model.Job) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	exists, err := jobExists(ctx, tx, j.Metadata.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return jobstore.NewErrJobAlreadyExists(j.Metadata.ID)
+	}
+
+	jobData, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	jobState := model.JobState{
+		JobID:      j.Metadata.ID,
+		State:      model.JobStateInProgress,
+		Version:    1,
+		CreateTime: now,
+		UpdateTime: now,
+	}
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO job (id, created, clientid, engine, publisher, jobdata, state, version, create_time, update_time)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		j.Metadata.ID,
+		toNanos(j.Metadata.CreatedAt),
+		j.Metadata.ClientID,
+		j.Spec.Engine.String(),
+		j.Spec.Publisher.String(),
+		string(jobData),
+		jobState.State.String(),
+		jobState.Version,
+		toNanos(jobState.CreateTime),
+		toNanos(jobState.UpdateTime),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, annotation := range j.Spec.Annotations {
+		_, err = tx.ExecContext(ctx, `INSERT INTO job_annotation (job_id, annotation) VALUES ($1, $2)`,
+			j.Metadata.ID, annotation)
+		if err != nil {
+			return err
+		}
+	}
+
+	// populate shard states
+	for i := 0; i < j.Spec.ExecutionPlan.TotalShards; i++ {
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO shard (job_id, shard_index, state, version, create_time, update_time)
+VALUES ($1, $2, $3, $4, $5, $6)`,
+			j.Metadata.ID,
+			i,
+			model.ShardStateInProgress.String(),
+			1,
+			toNanos(now),
+			toNanos(now),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = appendHistory(ctx, tx, model.JobHistory{
+		Type:          model.JobHistoryTypeJobLevel,
+		JobID:         jobState.JobID,
+		PreviousState: model.JobStateNew.String(),
+		NewState:      jobState.State.String(),
+		NewVersion:    jobState.Version,
+		Comment:       newJobComment,
+		Time:          jobState.UpdateTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	usage, err := getResourceUsage(ctx, tx, j.Metadata.ClientID)
+	if err != nil {
+		return err
+	}
+	err = setResourceUsage(ctx, tx, j.Metadata.ClientID, usage.Add(jobstore.JobResources(j.Spec)))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *GenericSQLJobStore) UpdateJobState(ctx context.Context, request jobstore.UpdateJobStateRequest) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	// get the existing job state
+	jobState, err := getJobStateRow(ctx, tx, request.JobID)
+	if err != nil {
+		if _, notFound := err.(*bacerrors.JobNotFound); notFound {
+			return jobstore.NewErrJobNotFound(request.JobID)
+		}
+		return err
+	}
+
+	// check the expected state
+	if err = request.Condition.Validate(jobState); err != nil {
+		return err
+	}
+	if jobState.State.IsTerminal() {
+		return jobstore.NewErrJobAlreadyTerminal(request.JobID, jobState.State, request.NewState)
+	}
+
+	// update the job state
+	previousState := jobState.State
+	jobState.State = request.NewState
+	jobState.Version++
+	jobState.UpdateTime = time.Now()
+	_, err = tx.ExecContext(ctx, `UPDATE job SET state = $1, version = $2, update_time = $3 WHERE id = $4`,
+		jobState.State.String(),
+		jobState.Version,
+		toNanos(jobState.UpdateTime),
+		request.JobID,
+	)
+	if err != nil {
+		return err
+	}
+
+	// jobs only become terminal once, so their resources are only released once
+	if jobState.State.IsTerminal() {
+		var j model.Job
+		j, err = getJob(ctx, tx, request.JobID)
+		if err != nil {
+			return err
+		}
+		var usage model.ResourceUsageData
+		usage, err = getResourceUsage(ctx, tx, j.Metadata.ClientID)
+		if err != nil {
+			return err
+		}
+		err = setResourceUsage(ctx, tx, j.Metadata.ClientID, usage.Sub(jobstore.JobResources(j.Spec)))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = appendHistory(ctx, tx, model.JobHistory{
+		Type:          model.JobHistoryTypeJobLevel,
+		JobID:         jobState.JobID,
+		PreviousState: previousState.String(),
+		NewState:      jobState.State.String(),
+		NewVersion:    jobState.Version,
+		Comment:       request.Comment,
+		Time:          jobState.UpdateTime,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *GenericSQLJobStore) GetShardState(ctx context.Context, shardID model.ShardID) (model.ShardState, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	exists, err := jobExists(ctx, d.db, shardID.JobID)
+	if err != nil {
+		return model.ShardState{}, err
+	}
+	if !exists {
+		return model.ShardState{}, jobstore.NewErrJobNotFound(shardID.JobID)
+	}
+
+	shardState, err := getShardStateRow(ctx, d.db, shardID)
+	if err != nil {
+		return model.ShardState{}, err
+	}
+	executions, err := getExecutions(ctx, d.db, shardID.JobID)
+	if err != nil {
+		return model.ShardState{}, err
+	}
+	shardState.Executions = executions[shardID.Index]
+	return shardState, nil
+}
+
+func (d *GenericSQLJobStore) UpdateShardState(ctx context.Context, request jobstore.UpdateShardStateRequest) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	// find the existing shard
+	exists, err := jobExists(ctx, tx, request.ShardID.JobID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return jobstore.NewErrJobNotFound(request.ShardID.JobID)
+	}
+	shardState, err := getShardStateRow(ctx, tx, request.ShardID)
+	if err != nil {
+		return err
+	}
+
+	// check the expected state
+	if err = request.Condition.Validate(shardState); err != nil {
+		return err
+	}
+	if shardState.State.IsTerminal() {
+		return jobstore.NewErrShardAlreadyTerminal(request.ShardID, shardState.State, request.NewState)
+	}
+
+	// update the shard state
+	previousState := shardState.State
+	shardState.State = request.NewState
+	if request.NewTrust != model.ShardTrustUnknown {
+		shardState.Trust = request.NewTrust
+	}
+	shardState.Version++
+	shardState.UpdateTime = time.Now()
+	_, err = tx.ExecContext(ctx, `
+UPDATE shard SET state = $1, trust = $2, version = $3, update_time = $4
+WHERE job_id = $5 AND shard_index = $6`,
+		shardState.State.String(),
+		shardState.Trust.String(),
+		shardState.Version,
+		toNanos(shardState.UpdateTime),
+		request.ShardID.JobID,
+		request.ShardID.Index,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = appendHistory(ctx, tx, model.JobHistory{
+		Type:          model.JobHistoryTypeShardLevel,
+		JobID:         shardState.JobID,
+		ShardIndex:    shardState.ShardIndex,
+		PreviousState: previousState.String(),
+		NewState:      shardState.State.String(),
+		NewVersion:    shardState.Version,
+		Comment:       request.Comment,
+		Time:          shardState.UpdateTime,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *GenericSQLJobStore) CreateExecution(ctx context.Context, execution model.ExecutionState) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	exists, err := jobExists(ctx, tx, execution.JobID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return jobstore.NewErrJobNotFound(execution.JobID)
+	}
+	if _, err = getShardStateRow(ctx, tx, execution.ShardID()); err != nil {
+		return err
+	}
+	_, err = getExecution(ctx, tx, execution.ID())
+	if err == nil {
+		return jobstore.NewErrExecutionAlreadyExists(execution.ID())
+	}
+	if _, notFound := err.(jobstore.ErrExecutionNotFound); !notFound {
+		return err
+	}
+
+	if execution.CreateTime.IsZero() {
+		execution.CreateTime = time.Now()
+	}
+	if execution.UpdateTime.IsZero() {
+		execution.UpdateTime = execution.CreateTime
+	}
+	if execution.Version == 0 {
+		execution.Version = 1
+	}
+
+	executionData, err := json.Marshal(execution)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO execution (job_id, shard_index, node_id, compute_reference, seq, state, version, executiondata)
+VALUES ($1, $2, $3, $4, (select count(*) + 1 from execution where job_id = $1 and shard_index = $2), $5, $6, $7)`,
+		execution.JobID,
+		execution.ShardIndex,
+		execution.NodeID,
+		execution.ComputeReference,
+		execution.State.String(),
+		execution.Version,
+		string(executionData),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = appendHistory(ctx, tx, newExecutionHistory(execution, model.ExecutionStateNew, ""))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *GenericSQLJobStore) UpdateExecution(ctx context.Context, request jobstore.UpdateExecutionRequest) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	// find the existing execution
+	exists, err := jobExists(ctx, tx, request.ExecutionID.JobID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return jobstore.NewErrJobNotFound(request.ExecutionID.JobID)
+	}
+	if _, err = getShardStateRow(ctx, tx, request.ExecutionID.ShardID()); err != nil {
+		return err
+	}
+	existingExecution, err := getExecution(ctx, tx, request.ExecutionID)
+	if err != nil {
+		return err
+	}
+
+	// check the expected state
+	if err = request.Condition.Validate(existingExecution); err != nil {
+		return err
+	}
+	if existingExecution.State.IsTerminal() {
+		return jobstore.NewErrExecutionAlreadyTerminal(request.ExecutionID, existingExecution.State, request.NewValues.State)
+	}
+
+	// populate default values
+	newExecution := request.NewValues
+	if newExecution.CreateTime.IsZero() {
+		newExecution.CreateTime = time.Now()
+	}
+	if newExecution.UpdateTime.IsZero() {
+		newExecution.UpdateTime = existingExecution.CreateTime
+	}
+	if newExecution.Version == 0 {
+		newExecution.Version = existingExecution.Version + 1
+	}
+
+	err = mergo.Merge(&newExecution, existingExecution)
+	if err != nil {
+		return err
+	}
+
+	// update the execution
+	executionData, err := json.Marshal(newExecution)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+UPDATE execution SET state = $1, version = $2, executiondata = $3
+WHERE job_id = $4 AND shard_index = $5 AND node_id = $6 AND compute_reference = $7`,
+		newExecution.State.String(),
+		newExecution.Version,
+		string(executionData),
+		request.ExecutionID.JobID,
+		request.ExecutionID.ShardIndex,
+		request.ExecutionID.NodeID,
+		request.ExecutionID.ExecutionID,
+	)
+	if err != nil {
+		return err
+	}
+
+	err = appendHistory(ctx, tx, newExecutionHistory(newExecution, existingExecution.State, request.Comment))
+	if err != nil {
+		return err
+	}
+	// executions only complete once, as completed is a terminal state
+	if newExecution.State == model.ExecutionStateCompleted {
+		_, err = tx.ExecContext(ctx, `
+INSERT INTO client_result_usage (client_id, result_size)
+SELECT clientid, $1 FROM job WHERE id = $2
+ON CONFLICT (client_id) DO UPDATE SET result_size = client_result_usage.result_size + excluded.result_size`,
+			int64(newExecution.ResultSize),
+			request.ExecutionID.JobID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (d *GenericSQLJobStore) UpdateJobRequester(ctx context.Context, request jobstore.UpdateJobRequesterRequest) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	//nolint:errcheck
+	defer tx.Rollback()
+
+	j, err := getJob(ctx, tx, request.JobID)
+	if err != nil {
+		if _, notFound := err.(*bacerrors.JobNotFound); notFound {
+			return jobstore.NewErrJobNotFound(request.JobID)
+		}
+		return err
+	}
+	if j.Metadata.Requester.RequesterNodeID != request.ExpectedRequesterNodeID {
+		return jobstore.NewErrInvalidJobRequester(
+			request.JobID, j.Metadata.Requester.RequesterNodeID, request.ExpectedRequesterNodeID)
+	}
+	jobState, err := getJobStateRow(ctx, tx, j.Metadata.ID)
+	if err != nil {
+		return err
+	}
+
+	j.Metadata.Requester = request.NewRequester
+	jobData, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE job SET jobdata = $1 WHERE id = $2`, string(jobData), j.Metadata.ID)
+	if err != nil {
+		return err
+	}
+
+	err = appendHistory(ctx, tx, model.JobHistory{
+		Type:          model.JobHistoryTypeJobLevel,
+		JobID:         jobState.JobID,
+		PreviousState: jobState.State.String(),
+		NewState:      jobState.State.String(),
+		NewVersion:    jobState.Version,
+		Comment:       request.Comment,
+		Time:          time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AcquireLease acquires or renews a lease with a single conditional update, so that concurrent nodes sharing the
+// database cannot both acquire the same lease.
+func (d *GenericSQLJobStore) AcquireLease(ctx context.Context, request jobstore.AcquireLeaseRequest) (jobstore.Lease, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	now := time.Now()
+	lease := jobstore.Lease{
+		Name:      request.Name,
+		Holder:    request.Holder,
+		ExpiresAt: now.Add(request.Duration),
+	}
+	result, err := d.db.ExecContext(ctx, `
+UPDATE lease SET holder = $1, expires_at = $2
+WHERE name = $3 AND (holder = $1 OR expires_at <= $4)`,
+		lease.Holder,
+		toNanos(lease.ExpiresAt),
+		lease.Name,
+		toNanos(now),
+	)
+	if err != nil {
+		return jobstore.Lease{}, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return jobstore.Lease{}, err
+	}
+	if updated > 0 {
+		return lease, nil
+	}
+
+	existing, err := getLease(ctx, d.db, request.Name)
+	if err == nil {
+		return jobstore.Lease{}, jobstore.NewErrLeaseHeld(request.Name, existing.Holder)
+	}
+	if _, notFound := err.(jobstore.ErrLeaseNotFound); !notFound {
+		return jobstore.Lease{}, err
+	}
+	_, err = d.db.ExecContext(ctx, `INSERT INTO lease (name, holder, expires_at) VALUES ($1, $2, $3)`,
+		lease.Name,
+		lease.Holder,
+		toNanos(lease.ExpiresAt),
+	)
+	if err != nil {
+		// another node inserted the lease first
+		if existing, getErr := getLease(ctx, d.db, request.Name); getErr == nil && existing.Holder != request.Holder {
+			return jobstore.Lease{}, jobstore.NewErrLeaseHeld(request.Name, existing.Holder)
+		}
+		return jobstore.Lease{}, err
+	}
+	return lease, nil
+}
+
+func (d *GenericSQLJobStore) ReleaseLease(ctx context.Context, name string, holder string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	result, err := d.db.ExecContext(ctx, `DELETE FROM lease WHERE name = $1 AND holder = $2`, name, holder)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		return nil
+	}
+	existing, err := getLease(ctx, d.db, name)
+	if err != nil {
+		return err
+	}
+	return jobstore.NewErrLeaseHeld(name, existing.Holder)
+}
+
+func (d *GenericSQLJobStore) GetLease(ctx context.Context, name string) (jobstore.Lease, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	return getLease(ctx, d.db, name)
+}
+
+// nodeOutcomeColumns maps each node outcome to the node_reputation column counting it
+var nodeOutcomeColumns = map[model.NodeOutcome]string{
+	model.NodeOutcomeVerificationPassed: "verifications_passed",
+	model.NodeOutcomeVerificationFailed: "verifications_failed",
+	model.NodeOutcomeTimeout:            "timeouts",
+	model.NodeOutcomeComputeFailure:     "compute_failures",
+	model.NodeOutcomeNoShow:             "no_shows",
+}
+
+// RecordNodeOutcome increments the counter of the outcome with a single upsert, so that concurrent nodes sharing
+// the database don't lose each other's updates.
+func (d *GenericSQLJobStore) RecordNodeOutcome(ctx context.Context, nodeID string, outcome model.NodeOutcome) error {
+	column, ok := nodeOutcomeColumns[outcome]
+	if !ok {
+		return fmt.Errorf("unknown node outcome %s", outcome)
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	//nolint:gosec // the column name is not user input
+	_, err := d.db.ExecContext(ctx, fmt.Sprintf(`
+INSERT INTO node_reputation (node_id, %[1]s, update_time) VALUES ($1, 1, $2)
+ON CONFLICT (node_id) DO UPDATE SET %[1]s = node_reputation.%[1]s + 1, update_time = $2`, column),
+		nodeID,
+		toNanos(time.Now()),
+	)
+	return err
+}
+
+func (d *GenericSQLJobStore) GetNodeReputation(ctx context.Context, nodeID string) (model.NodeReputation, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	reputation, err := scanNodeReputation(d.db.QueryRowContext(ctx, `
+select node_id, verifications_passed, verifications_failed, timeouts, compute_failures, no_shows, update_time
+from node_reputation where node_id = $1`, nodeID))
+	if err == sql.ErrNoRows {
+		return model.NodeReputation{NodeID: nodeID}, nil
+	}
+	return reputation, err
+}
+
+func (d *GenericSQLJobStore) GetNodeReputations(ctx context.Context) ([]model.NodeReputation, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	rows, err := d.db.QueryContext(ctx, `
+select node_id, verifications_passed, verifications_failed, timeouts, compute_failures, no_shows, update_time
+from node_reputation order by node_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reputations []model.NodeReputation
+	for rows.Next() {
+		reputation, scanErr := scanNodeReputation(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		reputations = append(reputations, reputation)
+	}
+	return reputations, rows.Err()
+}
+
+func (d *GenericSQLJobStore) ResetNodeReputation(ctx context.Context, nodeID string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	_, err := d.db.ExecContext(ctx, `DELETE FROM node_reputation WHERE node_id = $1`, nodeID)
+	return err
+}
+
+func (d *GenericSQLJobStore) SetSpotCheckSeed(ctx context.Context, jobID string, seed int64) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	_, err := d.db.ExecContext(ctx, `
+INSERT INTO spot_check_seed (job_id, seed) VALUES ($1, $2)
+ON CONFLICT (job_id) DO UPDATE SET seed = $2`,
+		jobID,
+		seed,
+	)
+	return err
+}
+
+func (d *GenericSQLJobStore) GetSpotCheckSeed(ctx context.Context, jobID string) (int64, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	var seed int64
+	err := d.db.QueryRowContext(ctx, `select seed from spot_check_seed where job_id = $1`, jobID).Scan(&seed)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return seed, err
+}
+
+func (d *GenericSQLJobStore) GetResultUsage(ctx context.Context, clientID string) (uint64, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	var usage int64
+	err := d.db.QueryRowContext(ctx, `select result_size from client_result_usage where client_id = $1`, clientID).Scan(&usage)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return uint64(usage), err
+}
+
+func (d *GenericSQLJobStore) GetResourceUsage(ctx context.Context, clientID string) (model.ResourceUsageData, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+	return getResourceUsage(ctx, d.db, clientID)
+}
+
+func (d *GenericSQLJobStore) CreateSchedule(ctx context.Context, schedule model.Schedule) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var count int
+	err := d.db.QueryRowContext(ctx, `select count(*) from schedule where id = $1`, schedule.ID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return jobstore.NewErrScheduleAlreadyExists(schedule.ID)
+	}
+
+	scheduleData, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.ExecContext(ctx, `
+INSERT INTO schedule (id, requester_node_id, create_time, scheduledata) VALUES ($1, $2, $3, $4)`,
+		schedule.ID,
+		schedule.RequesterNodeID,
+		toNanos(schedule.CreateTime),
+		string(scheduleData),
+	)
+	return err
+}
+
+func (d *GenericSQLJobStore) UpdateSchedule(ctx context.Context, schedule model.Schedule) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	scheduleData, err := json.Marshal(schedule)
+	if err != nil {
+		return err
+	}
+	result, err := d.db.ExecContext(ctx, `
+UPDATE schedule SET requester_node_id = $1, scheduledata = $2 WHERE id = $3`,
+		schedule.RequesterNodeID,
+		string(scheduleData),
+		schedule.ID,
+	)
+	if err != nil {
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return jobstore.NewErrScheduleNotFound(schedule.ID)
+	}
+	return nil
+}
+
+func (d *GenericSQLJobStore) GetSchedule(ctx context.Context, id string) (model.Schedule, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	var scheduleData string
+	err := d.db.QueryRowContext(ctx, `select scheduledata from schedule where id = $1`, id).Scan(&scheduleData)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.Schedule{}, jobstore.NewErrScheduleNotFound(id)
+		}
+		return model.Schedule{}, err
+	}
+	var schedule model.Schedule
+	err = json.Unmarshal([]byte(scheduleData), &schedule)
+	return schedule, err
+}
+
+func (d *GenericSQLJobStore) GetSchedules(ctx context.Context, requesterNodeID string) ([]model.Schedule, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	rows, err := d.db.QueryContext(ctx, `
+select scheduledata from schedule where requester_node_id = $1 order by create_time`, requesterNodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []model.Schedule
+	for rows.Next() {
+		var scheduleData string
+		if err = rows.Scan(&scheduleData); err != nil {
+			return nil, err
+		}
+		var schedule model.Schedule
+		if err = json.Unmarshal([]byte(scheduleData), &schedule); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, rows.Err()
+}
+
+func (d *GenericSQLJobStore) DeleteSchedule(ctx context.Context, id string) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	result, err := d.db.ExecContext(ctx, `DELETE FROM schedule WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return jobstore.NewErrScheduleNotFound(id)
+	}
+	return nil
+}
+
+func (d *GenericSQLJobStore) CreateWorkflow(ctx context.Context, workflow model.Workflow) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var count int
+	err := d.db.QueryRowContext(ctx, `select count(*) from workflow where id = $1`, workflow.State.WorkflowID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return jobstore.NewErrWorkflowAlreadyExists(workflow.State.WorkflowID)
+	}
+
+	workflowData, err := json.Marshal(workflow)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.ExecContext(ctx, `
+INSERT INTO workflow (id, requester_node_id, in_progress, create_time, workflowdata) VALUES ($1, $2, $3, $4, $5)`,
+		workflow.State.WorkflowID,
+		workflow.RequesterNodeID,
+		!workflow.State.State.IsTerminal(),
+		toNanos(workflow.State.CreateTime),
+		string(workflowData),
+	)
+	return err
+}
+
+func (d *GenericSQLJobStore) UpdateWorkflow(ctx context.Context, workflow model.Workflow) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	workflowData, err := json.Marshal(workflow)
+	if err != nil {
+		return err
+	}
+	result, err := d.db.ExecContext(ctx, `
+UPDATE workflow SET requester_node_id = $1, in_progress = $2, workflowdata = $3 WHERE id = $4`,
+		workflow.RequesterNodeID,
+		!workflow.State.State.IsTerminal(),
+		string(workflowData),
+		workflow.State.WorkflowID,
+	)
+	if err != nil {
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return jobstore.NewErrWorkflowNotFound(workflow.State.WorkflowID)
+	}
+	return nil
+}
+
+func (d *GenericSQLJobStore) GetWorkflow(ctx context.Context, id string) (model.Workflow, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	var workflowData string
+	err := d.db.QueryRowContext(ctx, `select workflowdata from workflow where id = $1`, id).Scan(&workflowData)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.Workflow{}, jobstore.NewErrWorkflowNotFound(id)
+		}
+		return model.Workflow{}, err
+	}
+	var workflow model.Workflow
+	err = json.Unmarshal([]byte(workflowData), &workflow)
+	return workflow, err
+}
+
+func (d *GenericSQLJobStore) GetInProgressWorkflows(ctx context.Context, requesterNodeID string) ([]model.Workflow, error) {
+	d.mtx.RLock()
+	defer d.mtx.RUnlock()
+
+	rows, err := d.db.QueryContext(ctx, `
+select workflowdata from workflow where requester_node_id = $1 and in_progress order by create_time`, requesterNodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var workflows []model.Workflow
+	for rows.Next() {
+		var workflowData string
+		if err = rows.Scan(&workflowData); err != nil {
+			return nil, err
+		}
+		var workflow model.Workflow
+		if err = json.Unmarshal([]byte(workflowData), &workflow); err != nil {
+			return nil, err
+		}
+		workflows = append(workflows, workflow)
+	}
+	return workflows, rows.Err()
+}
+
+func getJob(ctx context.Context, db SQLClient, id string) (model.Job, error) {
+	if len(id) < model.ShortIDLength {
+		return model.Job{}, bacerrors.NewJobNotFound(id)
+	}
+
+	// support for short job IDs
+	query := `select jobdata from job where id = $1`
+	if jobutils.ShortID(id) == id {
+		query = `select jobdata from job where id like $1 || '%' limit 1`
+	}
+
+	var jobData string
+	err := db.QueryRowContext(ctx, query, id).Scan(&jobData)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.Job{}, bacerrors.NewJobNotFound(id)
+		}
+		return model.Job{}, err
+	}
+
+	var j model.Job
+	if err = json.Unmarshal([]byte(jobData), &j); err != nil {
+		return model.Job{}, err
+	}
+	return j, nil
+}
+
+func getResourceUsage(ctx context.Context, db SQLClient, clientID string) (model.ResourceUsageData, error) {
+	var usage model.ResourceUsageData
+	var memory, disk, gpu int64
+	err := db.QueryRowContext(ctx, `select cpu, memory, disk, gpu from client_resource_usage where client_id = $1`,
+		clientID).Scan(&usage.CPU, &memory, &disk, &gpu)
+	if err == sql.ErrNoRows {
+		return model.ResourceUsageData{}, nil
+	}
+	usage.Memory, usage.Disk, usage.GPU = uint64(memory), uint64(disk), uint64(gpu)
+	return usage, err
+}
+
+func setResourceUsage(ctx context.Context, db SQLClient, clientID string, usage model.ResourceUsageData) error {
+	_, err := db.ExecContext(ctx, `
+INSERT INTO client_resource_usage (client_id, cpu, memory, disk, gpu) VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (client_id) DO UPDATE SET cpu = excluded.cpu, memory = excluded.memory, disk = excluded.disk, gpu = excluded.gpu`,
+		clientID,
+		usage.CPU,
+		int64(usage.Memory),
+		int64(usage.Disk),
+		int64(usage.GPU),
+	)
+	return err
+}
+
+func jobExists(ctx context.Context, db SQLClient, jobID string) (bool, error) {
+	var count int
+	err := db.QueryRowContext(ctx, `select count(*) from job where id = $1`, jobID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func getJobsSQL(query jobstore.JobQuery, countMode bool) (string, []interface{}, error) {
+	if err := query.Validate(); err != nil {
+		return "", nil, err
+	}
+	var args []interface{}
+	var clauses []string
+
+	nextArg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	hasAnnotation := func(tags []string) string {
+		placeholders := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			placeholders = append(placeholders, nextArg(tag))
+		}
+		return fmt.Sprintf(`exists (
+			select 1 from job_annotation
+			where job_annotation.job_id = job.id
+			and job_annotation.annotation in (%s)
+		)`, strings.Join(placeholders, ", "))
+	}
+
+	if !query.ReturnAll && query.ClientID != "" {
+		clauses = append(clauses, fmt.Sprintf("job.clientid = %s", nextArg(query.ClientID)))
+	}
+
+	// If we are not using include tags, by default every job is included.
+	// If a job is specifically included, that overrides it being excluded.
+	if len(query.IncludeTags) > 0 {
+		tags := make([]string, 0, len(query.IncludeTags))
+		for _, tag := range query.IncludeTags {
+			tags = append(tags, string(tag))
+		}
+		clauses = append(clauses, hasAnnotation(tags))
+	} else if len(query.ExcludeTags) > 0 {
+		tags := make([]string, 0, len(query.ExcludeTags))
+		for _, tag := range query.ExcludeTags {
+			tags = append(tags, string(tag))
+		}
+		clauses = append(clauses, "not "+hasAnnotation(tags))
+	}
+
+	filterClauses, err := getJobFiltersSQL(query, nextArg, hasAnnotation)
+	if err != nil {
+		return "", nil, err
+	}
+	clauses = append(clauses, filterClauses...)
+
+	order := "asc"
+	comparison := ">"
+	if query.SortReverse {
+		order = "desc"
+		comparison = "<"
+	}
+
+	var cursor jobstore.JobCursor
+	if query.Cursor != "" {
+		if cursor, err = jobstore.ParseJobCursor(query.Cursor); err != nil {
+			return "", nil, err
+		}
+	}
+
+	after := ""
+	switch query.SortBy {
+	case jobstore.SortByCreatedAt, "":
+		// jobs created at the same time are sorted by id, so that cursors are stable
+		after += fmt.Sprintf(" order by created %s, id %s", order, order)
+		if query.Cursor != "" {
+			clauses = append(clauses, fmt.Sprintf("(job.created %s %s or (job.created = %s and job.id %s %s))",
+				comparison, nextArg(cursor.CreatedAt), nextArg(cursor.CreatedAt), comparison, nextArg(cursor.ID)))
+		}
+	case jobstore.SortByID:
+		after += fmt.Sprintf(" order by id %s", order)
+		if query.Cursor != "" {
+			clauses = append(clauses, fmt.Sprintf("job.id %s %s", comparison, nextArg(cursor.ID)))
+		}
+	default:
+		return "", nil, fmt.Errorf("invalid sort_by: %s", query.SortBy)
+	}
+	if countMode {
+		after = ""
+	}
+
+	if query.Limit > 0 {
+		after += fmt.Sprintf(" limit %d", query.Limit)
+	}
+	if query.Offset > 0 {
+		if query.Limit <= 0 {
+			// sqlite does not support an offset without a limit
+			after += fmt.Sprintf(" limit %d", math.MaxInt64)
+		}
+		after += fmt.Sprintf(" offset %d", query.Offset)
+	}
+
+	where := ""
+	if len(clauses) > 0 {
+		where = "where " + strings.Join(clauses, " and ")
+	}
+
+	columns := "jobdata"
+	if countMode {
+		columns = "count(job.id)"
+	}
+	return fmt.Sprintf("select %s from job %s %s", columns, where, after), args, nil
+}
+
+// getJobFiltersSQL returns the clauses that filter jobs by state, creation time, engine, publisher, node and annotations
+func getJobFiltersSQL(
+	query jobstore.JobQuery,
+	nextArg func(value interface{}) string,
+	hasAnnotation func(tags []string) string,
+) ([]string, error) {
+	var clauses []string
+	in := func(column string, values []string) {
+		placeholders := make([]string, 0, len(values))
+		for _, value := range values {
+			placeholders = append(placeholders, nextArg(value))
+		}
+		clauses = append(clauses, fmt.Sprintf("%s in (%s)", column, strings.Join(placeholders, ", ")))
+	}
+
+	if len(query.States) > 0 {
+		states := make([]string, 0, len(query.States))
+		for _, state := range query.States {
+			states = append(states, state.String())
+		}
+		in("job.state", states)
+	}
+	if !query.CreatedAfter.IsZero() {
+		clauses = append(clauses, fmt.Sprintf("job.created > %s", nextArg(toNanos(query.CreatedAfter))))
+	}
+	if !query.CreatedBefore.IsZero() {
+		clauses = append(clauses, fmt.Sprintf("job.created < %s", nextArg(toNanos(query.CreatedBefore))))
+	}
+	if len(query.Engines) > 0 {
+		engines := make([]string, 0, len(query.Engines))
+		for _, engine := range query.Engines {
+			engines = append(engines, engine.String())
+		}
+		in("job.engine", engines)
+	}
+	if len(query.Publishers) > 0 {
+		publishers := make([]string, 0, len(query.Publishers))
+		for _, publisher := range query.Publishers {
+			publishers = append(publishers, publisher.String())
+		}
+		in("job.publisher", publishers)
+	}
+	if query.NodeID != "" {
+		clauses = append(clauses, fmt.Sprintf(`exists (
+			select 1 from execution
+			where execution.job_id = job.id
+			and execution.node_id = %s
+		)`, nextArg(query.NodeID)))
+	}
+
+	for _, selector := range query.AnnotationSelectors {
+		switch selector.Operator {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			clauses = append(clauses, hasAnnotation(selectorAnnotations(selector)))
+		case selection.NotEquals, selection.NotIn:
+			clauses = append(clauses, "not "+hasAnnotation(selectorAnnotations(selector)))
+		case selection.Exists, selection.DoesNotExist:
+			prefix := selector.Key + jobstore.AnnotationSeparator
+			clause := fmt.Sprintf(`exists (
+				select 1 from job_annotation
+				where job_annotation.job_id = job.id
+				and (job_annotation.annotation = %s or substr(job_annotation.annotation, 1, %s) = %s)
+			)`, nextArg(selector.Key), nextArg(len(prefix)), nextArg(prefix))
+			if selector.Operator == selection.DoesNotExist {
+				clause = "not " + clause
+			}
+			clauses = append(clauses, clause)
+		default:
+		}
+	}
+	return clauses, nil
+}
+
+// selectorAnnotations returns the annotations that match the key and one of the values of the selector.
+// See jobstore.ParseAnnotation.
+func selectorAnnotations(selector model.LabelSelectorRequirement) []string {
+	var annotations []string
+	for _, value := range selector.Values {
+		annotations = append(annotations, selector.Key+jobstore.AnnotationSeparator+value)
+		if value == "" {
+			annotations = append(annotations, selector.Key)
+		}
+	}
+	return annotations
+}
+
+// getJobStateRow returns the job level state without loading its shards and executions
+func getJobStateRow(ctx context.Context, db SQLClient, jobID string) (model.JobState, error) {
+	var state string
+	var version int
+	var createTime, updateTime, timeoutAt int64
+	err := db.QueryRowContext(ctx,
+		`select state, version, create_time, update_time, timeout_at from job where id = $1`, jobID,
+	).Scan(&state, &version, &createTime, &updateTime, &timeoutAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.JobState{}, bacerrors.NewJobNotFound(jobID)
+		}
+		return model.JobState{}, err
+	}
+
+	jobState := model.JobState{
+		JobID:      jobID,
+		Version:    version,
+		CreateTime: fromNanos(createTime),
+		UpdateTime: fromNanos(updateTime),
+		TimeoutAt:  fromNanos(timeoutAt),
+	}
+	if err = jobState.State.UnmarshalText([]byte(state)); err != nil {
+		return model.JobState{}, err
+	}
+	return jobState, nil
+}
+
+func getJobState(ctx context.Context, db SQLClient, jobID string) (model.JobState, error) {
+	jobState, err := getJobStateRow(ctx, db, jobID)
+	if err != nil {
+		return model.JobState{}, err
+	}
+
+	rows, err := db.QueryContext(ctx, `
+select shard_index, state, trust, version, create_time, update_time
+from shard where job_id = $1 order by shard_index asc`, jobID)
+	if err != nil {
+		return model.JobState{}, err
+	}
+	jobState.Shards = make(map[int]model.ShardState)
+	for rows.Next() {
+		shardState := model.ShardState{JobID: jobID}
+		shardState, err = scanShardState(rows, shardState)
+		if err != nil {
+			rows.Close()
+			return model.JobState{}, err
+		}
+		jobState.Shards[shardState.ShardIndex] = shardState
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return model.JobState{}, err
+	}
+
+	executions, err := getExecutions(ctx, db, jobID)
+	if err != nil {
+		return model.JobState{}, err
+	}
+	for shardIndex, shardExecutions := range executions {
+		shardState, ok := jobState.Shards[shardIndex]
+		if !ok {
+			continue
+		}
+		shardState.Executions = shardExecutions
+		jobState.Shards[shardIndex] = shardState
+	}
+	return jobState, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanShardState(row rowScanner, shardState model.ShardState) (model.ShardState, error) {
+	var state, trust string
+	var createTime, updateTime int64
+	err := row.Scan(&shardState.ShardIndex, &state, &trust, &shardState.Version, &createTime, &updateTime)
+	if err != nil {
+		return model.ShardState{}, err
+	}
+	if err = shardState.State.UnmarshalText([]byte(state)); err != nil {
+		return model.ShardState{}, err
+	}
+	if err = shardState.Trust.UnmarshalText([]byte(trust)); err != nil {
+		return model.ShardState{}, err
+	}
+	shardState.CreateTime = fromNanos(createTime)
+	shardState.UpdateTime = fromNanos(updateTime)
+	return shardState, nil
+}
+
+// getShardStateRow returns the shard level state without loading its executions
+func getShardStateRow(ctx context.Context, db SQLClient, shardID model.ShardID) (model.ShardState, error) {
+	row := db.QueryRowContext(ctx, `
+select shard_index, state, trust, version, create_time, update_time
+from shard where job_id = $1 and shard_index = $2`, shardID.JobID, shardID.Index)
+	shardState, err := scanShardState(row, model.ShardState{JobID: shardID.JobID})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.ShardState{}, jobstore.NewErrShardNotFound(shardID)
+		}
+		return model.ShardState{}, err
+	}
+	return shardState, nil
+}
+
+// getExecutions returns the executions of a job grouped by their shard index, in the order they were created
+func getExecutions(ctx context.Context, db SQLClient, jobID string) (map[int][]model.ExecutionState, error) {
+	rows, err := db.QueryContext(ctx,
+		`select executiondata from execution where job_id = $1 order by shard_index asc, seq asc`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	executions := make(map[int][]model.ExecutionState)
+	for rows.Next() {
+		var executionData string
+		if err = rows.Scan(&executionData); err != nil {
+			return nil, err
+		}
+		var execution model.ExecutionState
+		if err = json.Unmarshal([]byte(executionData), &execution); err != nil {
+			return nil, err
+		}
+		executions[execution.ShardIndex] = append(executions[execution.ShardIndex], execution)
+	}
+	return executions, rows.Err()
+}
+
+func getExecution(ctx context.Context, db SQLClient, executionID model.ExecutionID) (model.ExecutionState, error) {
+	var executionData string
+	err := db.QueryRowContext(ctx, `
+select executiondata from execution
+where job_id = $1 and shard_index = $2 and node_id = $3 and compute_reference = $4`,
+		executionID.JobID,
+		executionID.ShardIndex,
+		executionID.NodeID,
+		executionID.ExecutionID,
+	).Scan(&executionData)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.ExecutionState{}, jobstore.NewErrExecutionNotFound(executionID)
+		}
+		return model.ExecutionState{}, err
+	}
+
+	var execution model.ExecutionState
+	if err = json.Unmarshal([]byte(executionData), &execution); err != nil {
+		return model.ExecutionState{}, err
+	}
+	return execution, nil
+}
+
+func newExecutionHistory(
+	execution model.ExecutionState, previousState model.ExecutionStateType, comment string) model.JobHistory {
+	return model.JobHistory{
+		Type:             model.JobHistoryTypeExecutionLevel,
+		JobID:            execution.JobID,
+		ShardIndex:       execution.ShardIndex,
+		NodeID:           execution.NodeID,
+		ComputeReference: execution.ComputeReference,
+		PreviousState:    previousState.String(),
+		NewState:         execution.State.String(),
+		NewStateType:     execution.State,
+		NewVersion:       execution.Version,
+		Comment:          comment,
+		Time:             execution.UpdateTime,
+	}
+}
+
+func appendHistory(ctx context.Context, db SQLClient, entry model.JobHistory) error {
+	historyData, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, `
+INSERT INTO job_history (job_id, seq, historydata)
+VALUES ($1, (select count(*) + 1 from job_history where job_id = $1), $2)`,
+		entry.JobID,
+		string(historyData),
+	)
+	return err
+}
+
+func getLease(ctx context.Context, db SQLClient, name string) (jobstore.Lease, error) {
+	lease := jobstore.Lease{Name: name}
+	var expiresAt int64
+	err := db.QueryRowContext(ctx, `select holder, expires_at from lease where name = $1`, name).Scan(
+		&lease.Holder,
+		&expiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return jobstore.Lease{}, jobstore.NewErrLeaseNotFound(name)
+		}
+		return jobstore.Lease{}, err
+	}
+	lease.ExpiresAt = fromNanos(expiresAt)
+	return lease, nil
+}
+
+func scanNodeReputation(row rowScanner) (model.NodeReputation, error) {
+	var reputation model.NodeReputation
+	var updateTime int64
+	err := row.Scan(
+		&reputation.NodeID,
+		&reputation.VerificationsPassed,
+		&reputation.VerificationsFailed,
+		&reputation.Timeouts,
+		&reputation.ComputeFailures,
+		&reputation.NoShows,
+		&updateTime,
+	)
+	if err != nil {
+		return model.NodeReputation{}, err
+	}
+	reputation.UpdateTime = fromNanos(updateTime)
+	return reputation, nil
+}
+
+func toNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromNanos(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+//go:embed migrations/*.sql
+var fs embed.FS
+
+func (d *GenericSQLJobStore) GetMigrations() (*migrate.Migrate, error) {
+	files, err := iofs.New(fs, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := migrate.NewWithSourceInstance("iofs", files, d.connectionString)
+	if err != nil {
+		return nil, err
+	}
+	return migrations, nil
+}
+
+func (d *GenericSQLJobStore) MigrateUp() error {
+	migrations, err := d.GetMigrations()
+	if err != nil {
+		return err
+	}
+	err = migrations.Up()
+	if err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+	if err = d.backfillPublishers(context.Background()); err != nil {
+		return err
+	}
+	return d.backfillResourceUsage(context.Background())
+}
+
+// backfillPublishers sets the publisher column of jobs that were created before the column was added
+func (d *GenericSQLJobStore) backfillPublishers(ctx context.Context) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	rows, err := d.db.QueryContext(ctx, `select jobdata from job where publisher = ''`)
+	if err != nil {
+		return err
+	}
+	var jobs []model.Job
+	for rows.Next() {
+		var jobData string
+		if err = rows.Scan(&jobData); err != nil {
+			rows.Close()
+			return err
+		}
+		var j model.Job
+		if err = json.Unmarshal([]byte(jobData), &j); err != nil {
+			rows.Close()
+			return err
+		}
+		jobs = append(jobs, j)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, j := range jobs {
+		_, err = d.db.ExecContext(ctx, `update job set publisher = $1 where id = $2`, j.Spec.Publisher.String(), j.Metadata.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillResourceUsage sets the resource usage of clients with jobs that were in progress before the
+// client_resource_usage table was added. A usage is stored whenever a job is created, so the table is only empty
+// while no job was created since it was added.
+func (d *GenericSQLJobStore) backfillResourceUsage(ctx context.Context) error {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var count int
+	if err := d.db.QueryRowContext(ctx, `select count(*) from client_resource_usage`).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	rows, err := d.db.QueryContext(ctx, `select state, jobdata from job`)
+	if err != nil {
+		return err
+	}
+	usages := make(map[string]model.ResourceUsageData)
+	for rows.Next() {
+		var state, jobData string
+		if err = rows.Scan(&state, &jobData); err != nil {
+			rows.Close()
+			return err
+		}
+		var jobState model.JobStateType
+		if err = jobState.UnmarshalText([]byte(state)); err != nil {
+			rows.Close()
+			return err
+		}
+		if jobState.IsTerminal() {
+			continue
+		}
+		var j model.Job
+		if err = json.Unmarshal([]byte(jobData), &j); err != nil {
+			rows.Close()
+			return err
+		}
+		usages[j.Metadata.ClientID] = usages[j.Metadata.ClientID].Add(jobstore.JobResources(j.Spec))
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for clientID, usage := range usages {
+		if err = setResourceUsage(ctx, d.db, clientID, usage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *GenericSQLJobStore) MigrateDown() error {
+	migrations, err := d.GetMigrations()
+	if err != nil {
+		return err
+	}
+	err = migrations.Down()
+	if err != migrate.ErrNoChange {
+		return err
+	}
+	return nil
+}
+
+// Static check to ensure that GenericSQLJobStore implements jobstore.Store:
+var _ jobstore.Store = (*GenericSQLJobStore)(nil)
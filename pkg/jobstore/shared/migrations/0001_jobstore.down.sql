@@ -0,0 +1,5 @@
+drop table job_history;
+drop table execution;
+drop table shard;
+drop table job_annotation;
+drop table job;
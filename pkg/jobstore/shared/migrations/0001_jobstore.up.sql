@@ -0,0 +1,55 @@
+create table job (
+  id varchar(255) PRIMARY KEY,
+  created bigint,
+  clientid varchar(255),
+  engine varchar(255),
+  jobdata text default '',
+  state varchar(255),
+  version integer,
+  create_time bigint,
+  update_time bigint,
+  timeout_at bigint default 0
+);
+CREATE INDEX idx_jobstore_job_clientid ON job (clientid);
+CREATE INDEX idx_jobstore_job_state ON job (state);
+
+create table job_annotation (
+  job_id varchar(255),
+  annotation varchar(255),
+  FOREIGN KEY(job_id) REFERENCES job(id)
+);
+CREATE INDEX idx_jobstore_job_annotation ON job_annotation (annotation);
+CREATE INDEX idx_jobstore_job_annotation_job_id ON job_annotation (job_id);
+
+create table shard (
+  job_id varchar(255),
+  shard_index integer,
+  state varchar(255),
+  version integer,
+  create_time bigint,
+  update_time bigint,
+  PRIMARY KEY(job_id, shard_index),
+  FOREIGN KEY(job_id) REFERENCES job(id)
+);
+
+create table execution (
+  job_id varchar(255),
+  shard_index integer,
+  node_id varchar(255),
+  compute_reference varchar(255),
+  seq integer,
+  state varchar(255),
+  version integer,
+  executiondata text default '',
+  PRIMARY KEY(job_id, shard_index, node_id, compute_reference),
+  FOREIGN KEY(job_id) REFERENCES job(id)
+);
+CREATE INDEX idx_jobstore_execution_node_id ON execution (node_id);
+
+create table job_history (
+  job_id varchar(255),
+  seq integer,
+  historydata text default '',
+  PRIMARY KEY(job_id, seq),
+  FOREIGN KEY(job_id) REFERENCES job(id)
+);
@@ -0,0 +1,5 @@
+create table lease (
+  name varchar(255) PRIMARY KEY,
+  holder varchar(255),
+  expires_at bigint
+);
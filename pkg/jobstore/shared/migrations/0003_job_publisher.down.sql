@@ -0,0 +1,2 @@
+drop index idx_jobstore_job_publisher;
+alter table job drop column publisher;
@@ -0,0 +1,2 @@
+alter table job add column publisher varchar(255) default '';
+CREATE INDEX idx_jobstore_job_publisher ON job (publisher);
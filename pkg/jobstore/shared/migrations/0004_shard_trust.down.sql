@@ -0,0 +1 @@
+alter table shard drop column trust;
@@ -0,0 +1 @@
+alter table shard add column trust varchar(255) default '';
@@ -0,0 +1,9 @@
+create table node_reputation (
+  node_id varchar(255) PRIMARY KEY,
+  verifications_passed integer not null default 0,
+  verifications_failed integer not null default 0,
+  timeouts integer not null default 0,
+  compute_failures integer not null default 0,
+  no_shows integer not null default 0,
+  update_time bigint
+);
@@ -0,0 +1 @@
+drop table spot_check_seed;
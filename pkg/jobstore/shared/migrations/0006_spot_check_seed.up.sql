@@ -0,0 +1,4 @@
+create table spot_check_seed (
+  job_id varchar(255) PRIMARY KEY,
+  seed bigint not null
+);
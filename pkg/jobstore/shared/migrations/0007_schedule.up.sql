@@ -0,0 +1,7 @@
+create table schedule (
+  id varchar(255) PRIMARY KEY,
+  requester_node_id varchar(255) not null,
+  create_time bigint not null,
+  scheduledata text not null
+);
+CREATE INDEX idx_jobstore_schedule_requester_node_id ON schedule (requester_node_id);
@@ -0,0 +1,4 @@
+create table client_result_usage (
+  client_id varchar(255) PRIMARY KEY,
+  result_size bigint not null
+);
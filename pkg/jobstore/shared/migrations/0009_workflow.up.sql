@@ -0,0 +1,8 @@
+create table workflow (
+  id varchar(255) PRIMARY KEY,
+  requester_node_id varchar(255) not null,
+  in_progress boolean not null,
+  create_time bigint not null,
+  workflowdata text not null
+);
+CREATE INDEX idx_jobstore_workflow_requester_node_id ON workflow (requester_node_id, in_progress);
@@ -0,0 +1 @@
+drop table client_resource_usage;
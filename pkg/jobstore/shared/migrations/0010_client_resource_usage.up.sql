@@ -0,0 +1,7 @@
+create table client_resource_usage (
+  client_id varchar(255) PRIMARY KEY,
+  cpu double precision not null,
+  memory bigint not null,
+  disk bigint not null,
+  gpu bigint not null
+);
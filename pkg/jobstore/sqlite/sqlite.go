@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/XSAM/otelsql"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/shared"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	_ "modernc.org/sqlite"
+)
+
+// busyTimeoutPragma makes concurrent writers wait for the database lock instead of failing immediately
+const busyTimeoutPragma = "_pragma=busy_timeout(5000)"
+
+func NewSQLiteJobStore(filename string) (*shared.GenericSQLJobStore, error) {
+	db, err := otelsql.Open(
+		"sqlite",
+		fmt.Sprintf("%s?%s", filename, busyTimeoutPragma),
+		otelsql.WithAttributes(semconv.DBSystemSqlite, semconv.PeerService("sqlite")),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := otelsql.RegisterDBStatsMetrics(db, otelsql.WithAttributes(semconv.DBSystemSqlite)); err != nil { //nolint:govet
+		return nil, err
+	}
+	store, err := shared.NewGenericSQLJobStore(
+		db,
+		"sqlite",
+		fmt.Sprintf("sqlite://%s", filename),
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = store.MigrateUp()
+	if err != nil {
+		return nil, err
+	}
+	return store, err
+}
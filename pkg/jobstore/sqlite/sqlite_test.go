@@ -0,0 +1,23 @@
+//go:build unit || !integration
+
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/test"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSQLiteJobStoreSuite(t *testing.T) {
+	testingSuite := new(test.StoreSuite)
+	testingSuite.SetupHandler = func() jobstore.Store {
+		store, err := NewSQLiteJobStore(filepath.Join(testingSuite.T().TempDir(), "jobs.db"))
+		require.NoError(testingSuite.T(), err)
+		return store
+	}
+	suite.Run(t, testingSuite)
+}
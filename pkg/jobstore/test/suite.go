@@ -0,0 +1,916 @@
+//nolint:all
+package test
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+// StoreSuite is a behavioural test suite that every jobstore.Store implementation is expected to pass.
+// Implementations run it by providing a SetupHandler that returns a fresh, empty store.
+type StoreSuite struct {
+	suite.Suite
+	SetupHandler    func() jobstore.Store
+	TeardownHandler func()
+	store           jobstore.Store
+	ctx             context.Context
+}
+
+func (s *StoreSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.store = s.SetupHandler()
+}
+
+func (s *StoreSuite) TearDownTest() {
+	if s.TeardownHandler != nil {
+		s.TeardownHandler()
+	}
+}
+
+func (s *StoreSuite) TestCreateAndGetJob() {
+	j := newJob(2, "tag-a")
+	s.Require().NoError(s.store.CreateJob(s.ctx, j))
+
+	stored, err := s.store.GetJob(s.ctx, j.Metadata.ID)
+	s.Require().NoError(err)
+	s.Equal(j.Metadata.ID, stored.Metadata.ID)
+	s.Equal(j.Metadata.ClientID, stored.Metadata.ClientID)
+	s.Equal(j.Spec.Annotations, stored.Spec.Annotations)
+	s.Equal(j.Spec.ExecutionPlan.TotalShards, stored.Spec.ExecutionPlan.TotalShards)
+
+	// short ids are resolved to the full job id
+	stored, err = s.store.GetJob(s.ctx, j.Metadata.ID[:model.ShortIDLength])
+	s.Require().NoError(err)
+	s.Equal(j.Metadata.ID, stored.Metadata.ID)
+
+	state, err := s.store.GetJobState(s.ctx, j.Metadata.ID)
+	s.Require().NoError(err)
+	s.Equal(model.JobStateInProgress, state.State)
+	s.Equal(1, state.Version)
+	s.Len(state.Shards, 2)
+	for i := 0; i < 2; i++ {
+		s.Equal(model.ShardStateInProgress, state.Shards[i].State)
+		s.Equal(1, state.Shards[i].Version)
+		s.Equal(i, state.Shards[i].ShardIndex)
+	}
+
+	history, err := s.store.GetJobHistory(s.ctx, j.Metadata.ID)
+	s.Require().NoError(err)
+	s.Require().Len(history, 1)
+	s.Equal(model.JobHistoryTypeJobLevel, history[0].Type)
+	s.Equal(model.JobStateNew.String(), history[0].PreviousState)
+	s.Equal(model.JobStateInProgress.String(), history[0].NewState)
+}
+
+func (s *StoreSuite) TestCreateJob_AlreadyExists() {
+	j := newJob(1)
+	s.Require().NoError(s.store.CreateJob(s.ctx, j))
+	s.ErrorAs(s.store.CreateJob(s.ctx, j), &jobstore.ErrJobAlreadyExists{})
+}
+
+func (s *StoreSuite) TestGetJob_NotFound() {
+	_, err := s.store.GetJob(s.ctx, uuid.NewString())
+	var notFound *bacerrors.JobNotFound
+	s.ErrorAs(err, &notFound)
+
+	_, err = s.store.GetJobState(s.ctx, uuid.NewString())
+	s.ErrorAs(err, &notFound)
+
+	_, err = s.store.GetJobHistory(s.ctx, uuid.NewString())
+	s.ErrorAs(err, &jobstore.ErrJobNotFound{})
+}
+
+func (s *StoreSuite) TestGetJobs() {
+	clientA, clientB := uuid.NewString(), uuid.NewString()
+	jobs := []model.Job{
+		newJobForClient(clientA, "tag-a"),
+		newJobForClient(clientA, "tag-b"),
+		newJobForClient(clientB, "tag-a", "tag-c"),
+	}
+	for i := range jobs {
+		jobs[i].Metadata.CreatedAt = time.Now().Add(time.Duration(i) * time.Minute)
+		s.Require().NoError(s.store.CreateJob(s.ctx, jobs[i]))
+	}
+
+	for _, tc := range []struct {
+		name     string
+		query    jobstore.JobQuery
+		expected []model.Job
+	}{
+		{
+			name:     "all",
+			query:    jobstore.JobQuery{ReturnAll: true, SortBy: "created_at"},
+			expected: jobs,
+		},
+		{
+			name:     "by id",
+			query:    jobstore.JobQuery{ID: jobs[1].Metadata.ID},
+			expected: jobs[1:2],
+		},
+		{
+			name:     "by client",
+			query:    jobstore.JobQuery{ClientID: clientA, SortBy: "created_at"},
+			expected: jobs[:2],
+		},
+		{
+			name:     "include tags",
+			query:    jobstore.JobQuery{ReturnAll: true, IncludeTags: []model.IncludedTag{"tag-a"}, SortBy: "created_at"},
+			expected: []model.Job{jobs[0], jobs[2]},
+		},
+		{
+			name:     "exclude tags",
+			query:    jobstore.JobQuery{ReturnAll: true, ExcludeTags: []model.ExcludedTag{"tag-c"}, SortBy: "created_at"},
+			expected: jobs[:2],
+		},
+		{
+			name:     "sort reverse",
+			query:    jobstore.JobQuery{ReturnAll: true, SortBy: "created_at", SortReverse: true},
+			expected: []model.Job{jobs[2], jobs[1], jobs[0]},
+		},
+	} {
+		s.Run(tc.name, func() {
+			result, err := s.store.GetJobs(s.ctx, tc.query)
+			s.Require().NoError(err)
+			s.Equal(jobIDs(tc.expected), jobIDs(result))
+
+			count, err := s.store.GetJobsCount(s.ctx, tc.query)
+			s.Require().NoError(err)
+			s.Equal(len(tc.expected), count)
+		})
+	}
+}
+
+func (s *StoreSuite) TestGetJobs_Filters() {
+	now := time.Now()
+	jobs := []model.Job{
+		newJob(1, "team:ml", "gpu"),
+		newJob(1, "team:data"),
+		newJob(1, "team:ml"),
+	}
+	jobs[1].Spec.Engine = model.EngineDocker
+	jobs[1].Spec.Publisher = model.PublisherIpfs
+	jobs[2].Spec.Publisher = model.PublisherEstuary
+	for i := range jobs {
+		jobs[i].Metadata.CreatedAt = now.Add(time.Duration(i) * time.Minute)
+		s.Require().NoError(s.store.CreateJob(s.ctx, jobs[i]))
+	}
+	s.Require().NoError(s.store.UpdateJobState(s.ctx, jobstore.UpdateJobStateRequest{
+		JobID:    jobs[0].Metadata.ID,
+		NewState: model.JobStateCompleted,
+	}))
+	s.Require().NoError(s.store.CreateExecution(s.ctx, newExecution(jobs[2], 0, "node-a")))
+
+	selector := func(key string, operator selection.Operator, values ...string) []model.LabelSelectorRequirement {
+		return []model.LabelSelectorRequirement{{Key: key, Operator: operator, Values: values}}
+	}
+	for _, tc := range []struct {
+		name     string
+		query    jobstore.JobQuery
+		expected []model.Job
+	}{
+		{
+			name:     "by state",
+			query:    jobstore.JobQuery{States: []model.JobStateType{model.JobStateInProgress}},
+			expected: jobs[1:],
+		},
+		{
+			name:     "created after",
+			query:    jobstore.JobQuery{CreatedAfter: now},
+			expected: jobs[1:],
+		},
+		{
+			name:     "created before",
+			query:    jobstore.JobQuery{CreatedBefore: now.Add(time.Minute)},
+			expected: jobs[:1],
+		},
+		{
+			name:     "by engine",
+			query:    jobstore.JobQuery{Engines: []model.Engine{model.EngineDocker}},
+			expected: jobs[1:2],
+		},
+		{
+			name:     "by publisher",
+			query:    jobstore.JobQuery{Publishers: []model.Publisher{model.PublisherIpfs, model.PublisherEstuary}},
+			expected: jobs[1:],
+		},
+		{
+			name:     "by node",
+			query:    jobstore.JobQuery{NodeID: "node-a"},
+			expected: jobs[2:],
+		},
+		{
+			name:     "annotation equals",
+			query:    jobstore.JobQuery{AnnotationSelectors: selector("team", selection.Equals, "ml")},
+			expected: []model.Job{jobs[0], jobs[2]},
+		},
+		{
+			name:     "annotation not in",
+			query:    jobstore.JobQuery{AnnotationSelectors: selector("team", selection.NotIn, "ml", "web")},
+			expected: jobs[1:2],
+		},
+		{
+			name:     "annotation exists",
+			query:    jobstore.JobQuery{AnnotationSelectors: selector("gpu", selection.Exists)},
+			expected: jobs[:1],
+		},
+		{
+			name:     "annotation does not exist",
+			query:    jobstore.JobQuery{AnnotationSelectors: selector("gpu", selection.DoesNotExist)},
+			expected: jobs[1:],
+		},
+		{
+			name: "combined",
+			query: jobstore.JobQuery{
+				States:              []model.JobStateType{model.JobStateInProgress},
+				AnnotationSelectors: selector("team", selection.In, "ml"),
+			},
+			expected: jobs[2:],
+		},
+	} {
+		s.Run(tc.name, func() {
+			tc.query.ReturnAll = true
+			result, err := s.store.GetJobs(s.ctx, tc.query)
+			s.Require().NoError(err)
+			s.Equal(jobIDs(tc.expected), jobIDs(result))
+
+			count, err := s.store.GetJobsCount(s.ctx, tc.query)
+			s.Require().NoError(err)
+			s.Equal(len(tc.expected), count)
+		})
+	}
+
+	_, err := s.store.GetJobs(s.ctx, jobstore.JobQuery{AnnotationSelectors: selector("team", selection.GreaterThan, "1")})
+	s.ErrorAs(err, &jobstore.ErrInvalidJobQuery{})
+}
+
+func (s *StoreSuite) TestGetJobs_Cursor() {
+	now := time.Now()
+	var jobs []model.Job
+	for i := 0; i < 5; i++ {
+		j := newJob(1)
+		// jobs created at the same time are ordered by id
+		j.Metadata.CreatedAt = now.Add(time.Duration(i/2) * time.Minute)
+		s.Require().NoError(s.store.CreateJob(s.ctx, j))
+		jobs = append(jobs, j)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		sortBy  string
+		reverse bool
+	}{
+		{name: "created_at", sortBy: jobstore.SortByCreatedAt},
+		{name: "created_at reverse", sortBy: jobstore.SortByCreatedAt, reverse: true},
+		{name: "id", sortBy: jobstore.SortByID},
+		{name: "id reverse", sortBy: jobstore.SortByID, reverse: true},
+	} {
+		s.Run(tc.name, func() {
+			query := jobstore.JobQuery{ReturnAll: true, SortBy: tc.sortBy, SortReverse: tc.reverse}
+			all, err := s.store.GetJobs(s.ctx, query)
+			s.Require().NoError(err)
+			s.Len(all, len(jobs))
+
+			var paged []model.Job
+			query.Limit = 2
+			for {
+				page, err := s.store.GetJobs(s.ctx, query)
+				s.Require().NoError(err)
+				paged = append(paged, page...)
+				query.Cursor = jobstore.NextJobCursor(query, page)
+				if query.Cursor == "" {
+					break
+				}
+			}
+			s.Equal(jobIDs(all), jobIDs(paged))
+		})
+	}
+
+	_, err := s.store.GetJobs(s.ctx, jobstore.JobQuery{ReturnAll: true, Cursor: "not-a-cursor"})
+	s.ErrorAs(err, &jobstore.ErrInvalidJobQuery{})
+	_, err = s.store.GetJobs(s.ctx, jobstore.JobQuery{ReturnAll: true, SortBy: "unknown"})
+	s.ErrorAs(err, &jobstore.ErrInvalidJobQuery{})
+}
+
+func (s *StoreSuite) TestUpdateJobState() {
+	j := newJob(1)
+	s.Require().NoError(s.store.CreateJob(s.ctx, j))
+
+	// wrong expected state
+	err := s.store.UpdateJobState(s.ctx, jobstore.UpdateJobStateRequest{
+		JobID:     j.Metadata.ID,
+		Condition: jobstore.UpdateJobCondition{ExpectedState: model.JobStateError},
+		NewState:  model.JobStateCompleted,
+	})
+	s.ErrorAs(err, &jobstore.ErrInvalidJobState{})
+
+	// wrong expected version
+	err = s.store.UpdateJobState(s.ctx, jobstore.UpdateJobStateRequest{
+		JobID:     j.Metadata.ID,
+		Condition: jobstore.UpdateJobCondition{ExpectedVersion: 2},
+		NewState:  model.JobStateCompleted,
+	})
+	s.ErrorAs(err, &jobstore.ErrInvalidJobVersion{})
+
+	// unexpected state
+	err = s.store.UpdateJobState(s.ctx, jobstore.UpdateJobStateRequest{
+		JobID:     j.Metadata.ID,
+		Condition: jobstore.UpdateJobCondition{UnexpectedStates: []model.JobStateType{model.JobStateInProgress}},
+		NewState:  model.JobStateCompleted,
+	})
+	s.ErrorAs(err, &jobstore.ErrInvalidJobState{})
+
+	err = s.store.UpdateJobState(s.ctx, jobstore.UpdateJobStateRequest{
+		JobID:     j.Metadata.ID,
+		Condition: jobstore.UpdateJobCondition{ExpectedState: model.JobStateInProgress, ExpectedVersion: 1},
+		NewState:  model.JobStateCompleted,
+		Comment:   "done",
+	})
+	s.Require().NoError(err)
+
+	state, err := s.store.GetJobState(s.ctx, j.Metadata.ID)
+	s.Require().NoError(err)
+	s.Equal(model.JobStateCompleted, state.State)
+	s.Equal(2, state.Version)
+
+	// terminal states cannot be updated
+	err = s.store.UpdateJobState(s.ctx, jobstore.UpdateJobStateRequest{
+		JobID:    j.Metadata.ID,
+		NewState: model.JobStateError,
+	})
+	s.ErrorAs(err, &jobstore.ErrJobAlreadyTerminal{})
+
+	history, err := s.store.GetJobHistory(s.ctx, j.Metadata.ID)
+	s.Require().NoError(err)
+	s.Require().Len(history, 2)
+	s.Equal(model.JobStateInProgress.String(), history[1].PreviousState)
+	s.Equal(model.JobStateCompleted.String(), history[1].NewState)
+	s.Equal(2, history[1].NewVersion)
+	s.Equal("done", history[1].Comment)
+
+	err = s.store.UpdateJobState(s.ctx, jobstore.UpdateJobStateRequest{
+		JobID:    uuid.NewString(),
+		NewState: model.JobStateError,
+	})
+	s.ErrorAs(err, &jobstore.ErrJobNotFound{})
+}
+
+func (s *StoreSuite) TestGetInProgressJobs() {
+	inProgress := newJob(1)
+	completed := newJob(1)
+	s.Require().NoError(s.store.CreateJob(s.ctx, inProgress))
+	s.Require().NoError(s.store.CreateJob(s.ctx, completed))
+	s.Require().NoError(s.store.UpdateJobState(s.ctx, jobstore.UpdateJobStateRequest{
+		JobID:    completed.Metadata.ID,
+		NewState: model.JobStateCompleted,
+	}))
+
+	jobs, err := s.store.GetInProgressJobs(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Len(jobs, 1)
+	s.Equal(inProgress.Metadata.ID, jobs[0].Job.Metadata.ID)
+	s.Equal(inProgress.Metadata.ID, jobs[0].State.JobID)
+	s.Len(jobs[0].State.Shards, 1)
+}
+
+func (s *StoreSuite) TestUpdateShardState() {
+	j := newJob(2)
+	s.Require().NoError(s.store.CreateJob(s.ctx, j))
+	shardID := model.ShardID{JobID: j.Metadata.ID, Index: 1}
+
+	err := s.store.UpdateShardState(s.ctx, jobstore.UpdateShardStateRequest{
+		ShardID:   shardID,
+		Condition: jobstore.UpdateShardCondition{ExpectedState: model.ShardStateCompleted},
+		NewState:  model.ShardStateError,
+	})
+	s.ErrorAs(err, &jobstore.ErrInvalidShardState{})
+
+	err = s.store.UpdateShardState(s.ctx, jobstore.UpdateShardStateRequest{
+		ShardID:   shardID,
+		Condition: jobstore.UpdateShardCondition{ExpectedVersion: 3},
+		NewState:  model.ShardStateError,
+	})
+	s.ErrorAs(err, &jobstore.ErrInvalidShardVersion{})
+
+	err = s.store.UpdateShardState(s.ctx, jobstore.UpdateShardStateRequest{
+		ShardID:   shardID,
+		Condition: jobstore.UpdateShardCondition{ExpectedState: model.ShardStateInProgress, ExpectedVersion: 1},
+		NewState:  model.ShardStateCompleted,
+	})
+	s.Require().NoError(err)
+
+	shardState, err := s.store.GetShardState(s.ctx, shardID)
+	s.Require().NoError(err)
+	s.Equal(model.ShardStateCompleted, shardState.State)
+	s.Equal(2, shardState.Version)
+
+	// other shards are not affected
+	otherShard, err := s.store.GetShardState(s.ctx, model.ShardID{JobID: j.Metadata.ID, Index: 0})
+	s.Require().NoError(err)
+	s.Equal(model.ShardStateInProgress, otherShard.State)
+
+	err = s.store.UpdateShardState(s.ctx, jobstore.UpdateShardStateRequest{
+		ShardID:  shardID,
+		NewState: model.ShardStateError,
+	})
+	s.ErrorAs(err, &jobstore.ErrShardAlreadyTerminal{})
+
+	_, err = s.store.GetShardState(s.ctx, model.ShardID{JobID: j.Metadata.ID, Index: 5})
+	s.ErrorAs(err, &jobstore.ErrShardNotFound{})
+
+	_, err = s.store.GetShardState(s.ctx, model.ShardID{JobID: uuid.NewString(), Index: 0})
+	s.ErrorAs(err, &jobstore.ErrJobNotFound{})
+}
+
+func (s *StoreSuite) TestUpdateShardTrust() {
+	j := newJob(1)
+	s.Require().NoError(s.store.CreateJob(s.ctx, j))
+	shardID := model.ShardID{JobID: j.Metadata.ID, Index: 0}
+
+	shardState, err := s.store.GetShardState(s.ctx, shardID)
+	s.Require().NoError(err)
+	s.Equal(model.ShardTrustUnknown, shardState.Trust)
+
+	s.Require().NoError(s.store.UpdateShardState(s.ctx, jobstore.UpdateShardStateRequest{
+		ShardID:  shardID,
+		NewState: model.ShardStateInProgress,
+		NewTrust: model.ShardTrustMismatched,
+	}))
+	// an unknown trust leaves the trust unchanged
+	s.Require().NoError(s.store.UpdateShardState(s.ctx, jobstore.UpdateShardStateRequest{
+		ShardID:  shardID,
+		NewState: model.ShardStateCompleted,
+	}))
+
+	jobState, err := s.store.GetJobState(s.ctx, j.Metadata.ID)
+	s.Require().NoError(err)
+	s.Equal(model.ShardTrustMismatched, jobState.Shards[0].Trust)
+	s.Equal(model.ShardStateCompleted, jobState.Shards[0].State)
+}
+
+func (s *StoreSuite) TestCreateExecution() {
+	j := newJob(1)
+	s.Require().NoError(s.store.CreateJob(s.ctx, j))
+
+	first := newExecution(j, 0, "node-1")
+	second := newExecution(j, 0, "node-2")
+	s.Require().NoError(s.store.CreateExecution(s.ctx, first))
+	s.Require().NoError(s.store.CreateExecution(s.ctx, second))
+	s.ErrorAs(s.store.CreateExecution(s.ctx, first), &jobstore.ErrExecutionAlreadyExists{})
+	s.ErrorAs(s.store.CreateExecution(s.ctx, newExecution(j, 3, "node-1")), &jobstore.ErrShardNotFound{})
+
+	shardState, err := s.store.GetShardState(s.ctx, first.ShardID())
+	s.Require().NoError(err)
+	s.Require().Len(shardState.Executions, 2)
+	s.Equal(first.ID(), shardState.Executions[0].ID())
+	s.Equal(second.ID(), shardState.Executions[1].ID())
+	s.Equal(1, shardState.Executions[0].Version)
+	s.False(shardState.Executions[0].CreateTime.IsZero())
+
+	jobState, err := s.store.GetJobState(s.ctx, j.Metadata.ID)
+	s.Require().NoError(err)
+	s.Len(jobState.Shards[0].Executions, 2)
+}
+
+func (s *StoreSuite) TestUpdateExecution() {
+	j := newJob(1)
+	s.Require().NoError(s.store.CreateJob(s.ctx, j))
+	execution := newExecution(j, 0, "node-1")
+	s.Require().NoError(s.store.CreateExecution(s.ctx, execution))
+
+	err := s.store.UpdateExecution(s.ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: execution.ID(),
+		Condition:   jobstore.UpdateExecutionCondition{ExpectedState: model.ExecutionStateBidAccepted},
+		NewValues:   model.ExecutionState{State: model.ExecutionStateCompleted},
+	})
+	s.ErrorAs(err, &jobstore.ErrInvalidExecutionState{})
+
+	err = s.store.UpdateExecution(s.ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: execution.ID(),
+		Condition:   jobstore.UpdateExecutionCondition{ExpectedVersion: 4},
+		NewValues:   model.ExecutionState{State: model.ExecutionStateCompleted},
+	})
+	s.ErrorAs(err, &jobstore.ErrInvalidExecutionVersion{})
+
+	err = s.store.UpdateExecution(s.ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: execution.ID(),
+		Condition: jobstore.UpdateExecutionCondition{
+			ExpectedState:   model.ExecutionStateAskForBid,
+			ExpectedVersion: 1,
+		},
+		NewValues: model.ExecutionState{
+			State:  model.ExecutionStateBidAccepted,
+			Status: "running",
+		},
+		Comment: "bid accepted",
+	})
+	s.Require().NoError(err)
+
+	shardState, err := s.store.GetShardState(s.ctx, execution.ShardID())
+	s.Require().NoError(err)
+	s.Require().Len(shardState.Executions, 1)
+	updated := shardState.Executions[0]
+	s.Equal(model.ExecutionStateBidAccepted, updated.State)
+	s.Equal("running", updated.Status)
+	s.Equal(2, updated.Version)
+	// unset values are kept from the existing execution
+	s.Equal(execution.NodeID, updated.NodeID)
+	s.Equal(execution.ComputeReference, updated.ComputeReference)
+
+	err = s.store.UpdateExecution(s.ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: execution.ID(),
+		NewValues:   model.ExecutionState{State: model.ExecutionStateCanceled},
+	})
+	s.Require().NoError(err)
+
+	err = s.store.UpdateExecution(s.ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: execution.ID(),
+		NewValues:   model.ExecutionState{State: model.ExecutionStateCompleted},
+	})
+	s.ErrorAs(err, &jobstore.ErrExecutionAlreadyTerminal{})
+
+	missing := execution.ID()
+	missing.NodeID = "node-2"
+	err = s.store.UpdateExecution(s.ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: missing,
+		NewValues:   model.ExecutionState{State: model.ExecutionStateCompleted},
+	})
+	s.ErrorAs(err, &jobstore.ErrExecutionNotFound{})
+
+	history, err := s.store.GetJobHistory(s.ctx, j.Metadata.ID)
+	s.Require().NoError(err)
+	s.Require().Len(history, 4)
+	s.Equal(model.JobHistoryTypeExecutionLevel, history[2].Type)
+	s.Equal(model.ExecutionStateAskForBid.String(), history[2].PreviousState)
+	s.Equal(model.ExecutionStateBidAccepted.String(), history[2].NewState)
+	s.Equal(model.ExecutionStateBidAccepted, history[2].NewStateType)
+	s.Equal("bid accepted", history[2].Comment)
+	s.Equal(execution.NodeID, history[2].NodeID)
+}
+
+func (s *StoreSuite) TestCompleteShard() {
+	j := newJob(2)
+	s.Require().NoError(s.store.CreateJob(s.ctx, j))
+
+	s.Require().NoError(jobstore.CompleteShard(s.ctx, s.store, model.ShardID{JobID: j.Metadata.ID, Index: 0}))
+	state, err := s.store.GetJobState(s.ctx, j.Metadata.ID)
+	s.Require().NoError(err)
+	s.Equal(model.JobStateInProgress, state.State)
+
+	s.Require().NoError(jobstore.CompleteShard(s.ctx, s.store, model.ShardID{JobID: j.Metadata.ID, Index: 1}))
+	state, err = s.store.GetJobState(s.ctx, j.Metadata.ID)
+	s.Require().NoError(err)
+	s.Equal(model.JobStateCompleted, state.State)
+}
+
+func (s *StoreSuite) TestStopShard() {
+	j := newJob(1)
+	s.Require().NoError(s.store.CreateJob(s.ctx, j))
+	execution := newExecution(j, 0, "node-1")
+	s.Require().NoError(s.store.CreateExecution(s.ctx, execution))
+
+	cancelled, err := jobstore.StopShard(s.ctx, s.store, execution.ShardID(), "cancelled by user", true)
+	s.Require().NoError(err)
+	s.Require().Len(cancelled, 1)
+	s.Equal(execution.ID(), cancelled[0].ID())
+
+	state, err := s.store.GetJobState(s.ctx, j.Metadata.ID)
+	s.Require().NoError(err)
+	s.Equal(model.JobStateCancelled, state.State)
+	s.Equal(model.ShardStateCancelled, state.Shards[0].State)
+	s.Equal(model.ExecutionStateCanceled, state.Shards[0].Executions[0].State)
+}
+
+func (s *StoreSuite) TestUpdateJobRequester() {
+	j := newJob(1)
+	j.Metadata.Requester.RequesterNodeID = "requester-a"
+	s.Require().NoError(s.store.CreateJob(s.ctx, j))
+
+	// wrong expected requester
+	err := s.store.UpdateJobRequester(s.ctx, jobstore.UpdateJobRequesterRequest{
+		JobID:                   j.Metadata.ID,
+		ExpectedRequesterNodeID: "requester-b",
+		NewRequester:            model.JobRequester{RequesterNodeID: "requester-c"},
+	})
+	s.ErrorAs(err, &jobstore.ErrInvalidJobRequester{})
+
+	err = s.store.UpdateJobRequester(s.ctx, jobstore.UpdateJobRequesterRequest{
+		JobID:                   j.Metadata.ID,
+		ExpectedRequesterNodeID: "requester-a",
+		NewRequester:            model.JobRequester{RequesterNodeID: "requester-b"},
+		Comment:                 "adopted",
+	})
+	s.Require().NoError(err)
+
+	stored, err := s.store.GetJob(s.ctx, j.Metadata.ID)
+	s.Require().NoError(err)
+	s.Equal("requester-b", stored.Metadata.Requester.RequesterNodeID)
+
+	history, err := s.store.GetJobHistory(s.ctx, j.Metadata.ID)
+	s.Require().NoError(err)
+	s.Require().Len(history, 2)
+	s.Equal("adopted", history[1].Comment)
+
+	err = s.store.UpdateJobRequester(s.ctx, jobstore.UpdateJobRequesterRequest{JobID: uuid.NewString()})
+	s.ErrorAs(err, &jobstore.ErrJobNotFound{})
+}
+
+func (s *StoreSuite) TestLeases() {
+	_, err := s.store.GetLease(s.ctx, "lease")
+	s.ErrorAs(err, &jobstore.ErrLeaseNotFound{})
+
+	lease, err := s.store.AcquireLease(s.ctx, jobstore.AcquireLeaseRequest{Name: "lease", Holder: "a", Duration: time.Hour})
+	s.Require().NoError(err)
+	s.Equal("a", lease.Holder)
+	s.False(lease.IsExpired(time.Now()))
+
+	// another holder cannot acquire an unexpired lease
+	_, err = s.store.AcquireLease(s.ctx, jobstore.AcquireLeaseRequest{Name: "lease", Holder: "b", Duration: time.Hour})
+	s.ErrorAs(err, &jobstore.ErrLeaseHeld{})
+	s.ErrorAs(s.store.ReleaseLease(s.ctx, "lease", "b"), &jobstore.ErrLeaseHeld{})
+
+	// the holder can renew its lease
+	renewed, err := s.store.AcquireLease(s.ctx, jobstore.AcquireLeaseRequest{Name: "lease", Holder: "a", Duration: 2 * time.Hour})
+	s.Require().NoError(err)
+	s.True(renewed.ExpiresAt.After(lease.ExpiresAt))
+
+	stored, err := s.store.GetLease(s.ctx, "lease")
+	s.Require().NoError(err)
+	s.Equal("a", stored.Holder)
+	s.True(stored.ExpiresAt.Equal(renewed.ExpiresAt))
+
+	// another holder can acquire an expired lease
+	_, err = s.store.AcquireLease(s.ctx, jobstore.AcquireLeaseRequest{Name: "expiring", Holder: "a", Duration: time.Millisecond})
+	s.Require().NoError(err)
+	time.Sleep(5 * time.Millisecond)
+	lease, err = s.store.AcquireLease(s.ctx, jobstore.AcquireLeaseRequest{Name: "expiring", Holder: "b", Duration: time.Hour})
+	s.Require().NoError(err)
+	s.Equal("b", lease.Holder)
+
+	// a released lease can be acquired by another holder
+	s.Require().NoError(s.store.ReleaseLease(s.ctx, "lease", "a"))
+	_, err = s.store.GetLease(s.ctx, "lease")
+	s.ErrorAs(err, &jobstore.ErrLeaseNotFound{})
+	_, err = s.store.AcquireLease(s.ctx, jobstore.AcquireLeaseRequest{Name: "lease", Holder: "b", Duration: time.Hour})
+	s.Require().NoError(err)
+}
+
+func (s *StoreSuite) TestNodeReputations() {
+	reputation, err := s.store.GetNodeReputation(s.ctx, "node-a")
+	s.Require().NoError(err)
+	s.Equal(model.NodeReputation{NodeID: "node-a"}, reputation)
+
+	s.Require().NoError(s.store.RecordNodeOutcome(s.ctx, "node-b", model.NodeOutcomeVerificationFailed))
+	s.Require().NoError(s.store.RecordNodeOutcome(s.ctx, "node-a", model.NodeOutcomeVerificationPassed))
+	s.Require().NoError(s.store.RecordNodeOutcome(s.ctx, "node-a", model.NodeOutcomeVerificationPassed))
+	s.Require().NoError(s.store.RecordNodeOutcome(s.ctx, "node-a", model.NodeOutcomeTimeout))
+	s.Require().NoError(s.store.RecordNodeOutcome(s.ctx, "node-a", model.NodeOutcomeComputeFailure))
+	s.Require().NoError(s.store.RecordNodeOutcome(s.ctx, "node-a", model.NodeOutcomeNoShow))
+
+	reputation, err = s.store.GetNodeReputation(s.ctx, "node-a")
+	s.Require().NoError(err)
+	s.Equal(2, reputation.VerificationsPassed)
+	s.Equal(0, reputation.VerificationsFailed)
+	s.Equal(1, reputation.Timeouts)
+	s.Equal(1, reputation.ComputeFailures)
+	s.Equal(1, reputation.NoShows)
+	s.False(reputation.UpdateTime.IsZero())
+
+	reputations, err := s.store.GetNodeReputations(s.ctx)
+	s.Require().NoError(err)
+	s.Require().Len(reputations, 2)
+	s.Equal("node-a", reputations[0].NodeID)
+	s.Equal("node-b", reputations[1].NodeID)
+	s.Equal(1, reputations[1].VerificationsFailed)
+
+	s.Require().NoError(s.store.ResetNodeReputation(s.ctx, "node-a"))
+	reputation, err = s.store.GetNodeReputation(s.ctx, "node-a")
+	s.Require().NoError(err)
+	s.Equal(model.NodeReputation{NodeID: "node-a"}, reputation)
+	reputations, err = s.store.GetNodeReputations(s.ctx)
+	s.Require().NoError(err)
+	s.Len(reputations, 1)
+}
+
+func (s *StoreSuite) TestSpotCheckSeed() {
+	seed, err := s.store.GetSpotCheckSeed(s.ctx, "job-a")
+	s.Require().NoError(err)
+	s.Zero(seed)
+
+	s.Require().NoError(s.store.SetSpotCheckSeed(s.ctx, "job-a", 42))
+	s.Require().NoError(s.store.SetSpotCheckSeed(s.ctx, "job-b", -7))
+	seed, err = s.store.GetSpotCheckSeed(s.ctx, "job-a")
+	s.Require().NoError(err)
+	s.Equal(int64(42), seed)
+	seed, err = s.store.GetSpotCheckSeed(s.ctx, "job-b")
+	s.Require().NoError(err)
+	s.Equal(int64(-7), seed)
+}
+
+func (s *StoreSuite) TestSchedules() {
+	createTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := model.Schedule{
+		ID:              "schedule-a",
+		ClientID:        "client",
+		RequesterNodeID: "node-a",
+		Spec:            model.ScheduleSpec{Cron: "@hourly", OverlapPolicy: model.ScheduleOverlapQueue},
+		CreateTime:      createTime.Add(time.Minute),
+		NextRun:         createTime.Add(time.Hour),
+	}
+	second := first
+	second.ID = "schedule-b"
+	second.CreateTime = createTime
+	other := first
+	other.ID = "schedule-c"
+	other.RequesterNodeID = "node-b"
+	for _, schedule := range []model.Schedule{first, second, other} {
+		s.Require().NoError(s.store.CreateSchedule(s.ctx, schedule))
+	}
+	s.ErrorAs(s.store.CreateSchedule(s.ctx, first), &jobstore.ErrScheduleAlreadyExists{})
+
+	schedules, err := s.store.GetSchedules(s.ctx, "node-a")
+	s.Require().NoError(err)
+	s.Equal([]model.Schedule{second, first}, schedules)
+
+	first.NextRun = createTime.Add(2 * time.Hour)
+	first.Runs = []model.ScheduleRun{
+		{ScheduledAt: createTime.Add(time.Hour), State: model.ScheduleRunStateSubmitted, JobID: "job-a"},
+	}
+	s.Require().NoError(s.store.UpdateSchedule(s.ctx, first))
+	schedule, err := s.store.GetSchedule(s.ctx, first.ID)
+	s.Require().NoError(err)
+	s.Equal(first, schedule)
+
+	s.Require().NoError(s.store.DeleteSchedule(s.ctx, first.ID))
+	_, err = s.store.GetSchedule(s.ctx, first.ID)
+	s.ErrorAs(err, &jobstore.ErrScheduleNotFound{})
+	s.ErrorAs(s.store.UpdateSchedule(s.ctx, first), &jobstore.ErrScheduleNotFound{})
+	s.ErrorAs(s.store.DeleteSchedule(s.ctx, first.ID), &jobstore.ErrScheduleNotFound{})
+}
+
+func (s *StoreSuite) TestWorkflows() {
+	createTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := model.Workflow{
+		APIVersion:      model.APIVersionLatest().String(),
+		RequesterNodeID: "node-a",
+		Spec:            model.WorkflowSpec{Jobs: []model.WorkflowJobSpec{{Name: "a"}}},
+		State: model.WorkflowState{
+			WorkflowID: "workflow-a",
+			ClientID:   "client",
+			State:      model.WorkflowStateInProgress,
+			Jobs:       []model.WorkflowJobState{{Name: "a", State: model.WorkflowJobStatePending}},
+			CreateTime: createTime.Add(time.Minute),
+			UpdateTime: createTime.Add(time.Minute),
+		},
+	}
+	second := first
+	second.State.WorkflowID = "workflow-b"
+	second.State.CreateTime = createTime
+	other := first
+	other.State.WorkflowID = "workflow-c"
+	other.RequesterNodeID = "node-b"
+	for _, workflow := range []model.Workflow{first, second, other} {
+		s.Require().NoError(s.store.CreateWorkflow(s.ctx, workflow))
+	}
+	s.ErrorAs(s.store.CreateWorkflow(s.ctx, first), &jobstore.ErrWorkflowAlreadyExists{})
+
+	workflows, err := s.store.GetInProgressWorkflows(s.ctx, "node-a")
+	s.Require().NoError(err)
+	s.Equal([]model.Workflow{second, first}, workflows)
+
+	second.State.State = model.WorkflowStateCompleted
+	second.State.Jobs = []model.WorkflowJobState{{Name: "a", JobID: "job-a", State: model.WorkflowJobStateCompleted}}
+	s.Require().NoError(s.store.UpdateWorkflow(s.ctx, second))
+	workflow, err := s.store.GetWorkflow(s.ctx, second.State.WorkflowID)
+	s.Require().NoError(err)
+	s.Equal(second, workflow)
+
+	// finished workflows are kept, but are no longer in progress
+	workflows, err = s.store.GetInProgressWorkflows(s.ctx, "node-a")
+	s.Require().NoError(err)
+	s.Equal([]model.Workflow{first}, workflows)
+
+	_, err = s.store.GetWorkflow(s.ctx, "workflow-d")
+	s.ErrorAs(err, &jobstore.ErrWorkflowNotFound{})
+	unknown := first
+	unknown.State.WorkflowID = "workflow-d"
+	s.ErrorAs(s.store.UpdateWorkflow(s.ctx, unknown), &jobstore.ErrWorkflowNotFound{})
+}
+
+func (s *StoreSuite) TestResultUsage() {
+	complete := func(j model.Job, resultSize uint64) {
+		execution := newExecution(j, 0, uuid.NewString())
+		s.Require().NoError(s.store.CreateExecution(s.ctx, execution))
+		s.Require().NoError(s.store.UpdateExecution(s.ctx, jobstore.UpdateExecutionRequest{
+			ExecutionID: execution.ID(),
+			NewValues:   model.ExecutionState{State: model.ExecutionStateCompleted, ResultSize: resultSize},
+		}))
+	}
+	first := newJobForClient("client")
+	second := newJobForClient("client")
+	other := newJobForClient("other")
+	for _, j := range []model.Job{first, second, other} {
+		s.Require().NoError(s.store.CreateJob(s.ctx, j))
+	}
+
+	usage, err := s.store.GetResultUsage(s.ctx, "client")
+	s.Require().NoError(err)
+	s.Zero(usage)
+
+	complete(first, 100)
+	complete(second, 50)
+	complete(other, 1000)
+	failed := newExecution(first, 0, "node-failed")
+	s.Require().NoError(s.store.CreateExecution(s.ctx, failed))
+	s.Require().NoError(s.store.UpdateExecution(s.ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: failed.ID(),
+		NewValues:   model.ExecutionState{State: model.ExecutionStateFailed, ResultSize: 1000},
+	}))
+
+	usage, err = s.store.GetResultUsage(s.ctx, "client")
+	s.Require().NoError(err)
+	s.Equal(uint64(150), usage)
+	usage, err = s.store.GetResultUsage(s.ctx, "other")
+	s.Require().NoError(err)
+	s.Equal(uint64(1000), usage)
+}
+
+func (s *StoreSuite) TestResourceUsage() {
+	withResources := func(j model.Job, cpu string) model.Job {
+		j.Spec.Resources = model.ResourceUsageConfig{CPU: cpu, Memory: "1GB"}
+		return j
+	}
+	first := withResources(newJobForClient("client"), "1")
+	second := withResources(newJob(2), "500m")
+	second.Metadata.ClientID = "client"
+	other := withResources(newJobForClient("other"), "10")
+
+	usage, err := s.store.GetResourceUsage(s.ctx, "client")
+	s.Require().NoError(err)
+	s.True(usage.IsZero())
+
+	for _, j := range []model.Job{first, second, other} {
+		s.Require().NoError(s.store.CreateJob(s.ctx, j))
+	}
+	usage, err = s.store.GetResourceUsage(s.ctx, "client")
+	s.Require().NoError(err)
+	s.Equal(jobstore.JobResources(first.Spec).Add(jobstore.JobResources(second.Spec)), usage)
+
+	// resources are released once the job is terminal
+	s.Require().NoError(s.store.UpdateJobState(s.ctx, jobstore.UpdateJobStateRequest{
+		JobID:    first.Metadata.ID,
+		NewState: model.JobStateCompleted,
+	}))
+	usage, err = s.store.GetResourceUsage(s.ctx, "client")
+	s.Require().NoError(err)
+	s.Equal(jobstore.JobResources(second.Spec), usage)
+	usage, err = s.store.GetResourceUsage(s.ctx, "other")
+	s.Require().NoError(err)
+	s.Equal(jobstore.JobResources(other.Spec), usage)
+}
+
+func newJob(totalShards int, annotations ...string) model.Job {
+	return model.Job{
+		APIVersion: model.APIVersionLatest().String(),
+		Metadata: model.Metadata{
+			ID:        uuid.NewString(),
+			ClientID:  uuid.NewString(),
+			CreatedAt: time.Now(),
+		},
+		Spec: model.Spec{
+			Engine:        model.EngineNoop,
+			Annotations:   annotations,
+			ExecutionPlan: model.JobExecutionPlan{TotalShards: totalShards},
+		},
+	}
+}
+
+func newJobForClient(clientID string, annotations ...string) model.Job {
+	j := newJob(1, annotations...)
+	j.Metadata.ClientID = clientID
+	return j
+}
+
+func newExecution(j model.Job, shardIndex int, nodeID string) model.ExecutionState {
+	return model.ExecutionState{
+		JobID:            j.Metadata.ID,
+		ShardIndex:       shardIndex,
+		NodeID:           nodeID,
+		ComputeReference: uuid.NewString(),
+		State:            model.ExecutionStateAskForBid,
+	}
+}
+
+func jobIDs(jobs []model.Job) []string {
+	ids := make([]string, 0, len(jobs))
+	for _, j := range jobs {
+		ids = append(ids, j.Metadata.ID)
+	}
+	return ids
+}
@@ -2,6 +2,7 @@ package jobstore
 
 import (
 	"context"
+	"time"
 
 	"github.com/filecoin-project/bacalhau/pkg/model"
 )
@@ -16,6 +17,23 @@ type JobQuery struct {
 	ReturnAll   bool                `json:"return_all"`
 	SortBy      string              `json:"sort_by"`
 	SortReverse bool                `json:"sort_reverse"`
+	// States only returns jobs in one of the given states
+	States []model.JobStateType `json:"states"`
+	// CreatedAfter and CreatedBefore only return jobs created within the given time range, if set
+	CreatedAfter  time.Time `json:"created_after"`
+	CreatedBefore time.Time `json:"created_before"`
+	// Engines only returns jobs using one of the given engines
+	Engines []model.Engine `json:"engines"`
+	// Publishers only returns jobs using one of the given publishers
+	Publishers []model.Publisher `json:"publishers"`
+	// NodeID only returns jobs that were executed, or asked to be executed, by the given compute node
+	NodeID string `json:"node_id"`
+	// AnnotationSelectors only returns jobs whose annotations match all the selectors.
+	// See ParseAnnotation for how annotations are matched against selectors.
+	AnnotationSelectors []model.LabelSelectorRequirement `json:"annotation_selectors"`
+	// Cursor only returns jobs that come after the job the cursor was created from, in the order of the query.
+	// See NewJobCursor.
+	Cursor string `json:"cursor"`
 }
 
 // A Store will persist jobs and their state to the underlying storage.
@@ -36,8 +54,54 @@ type Store interface {
 	UpdateShardState(ctx context.Context, request UpdateShardStateRequest) error
 	// CreateExecution creates a new execution for a given job
 	CreateExecution(ctx context.Context, execution model.ExecutionState) error
-	// UpdateExecution updates the Job state
+	// UpdateExecution updates the Job state. Completing an execution adds its result size to the result usage of
+	// the client of the job.
 	UpdateExecution(ctx context.Context, request UpdateExecutionRequest) error
+	// UpdateJobRequester transfers the ownership of a job to another requester node
+	UpdateJobRequester(ctx context.Context, request UpdateJobRequesterRequest) error
+	// AcquireLease acquires or renews a lease on a named resource, unless another holder has an unexpired lease on it
+	AcquireLease(ctx context.Context, request AcquireLeaseRequest) (Lease, error)
+	// ReleaseLease releases a lease on a named resource if it is held by the given holder
+	ReleaseLease(ctx context.Context, name string, holder string) error
+	// GetLease returns the lease on a named resource, which might have expired
+	GetLease(ctx context.Context, name string) (Lease, error)
+	// RecordNodeOutcome counts an outcome of a compute node's execution towards the node's reputation
+	RecordNodeOutcome(ctx context.Context, nodeID string, outcome model.NodeOutcome) error
+	// GetNodeReputation returns the reputation of a compute node, which is empty if no outcome was recorded for it
+	GetNodeReputation(ctx context.Context, nodeID string) (model.NodeReputation, error)
+	// GetNodeReputations returns the reputations of all compute nodes with recorded outcomes, sorted by node ID
+	GetNodeReputations(ctx context.Context) ([]model.NodeReputation, error)
+	// ResetNodeReputation forgets the recorded outcomes of a compute node
+	ResetNodeReputation(ctx context.Context, nodeID string) error
+	// SetSpotCheckSeed stores the secret seed from which the spot checked shards of a job are sampled. It is kept
+	// apart from the job, which is sent to compute nodes, so that they can't know which shards are spot checked.
+	SetSpotCheckSeed(ctx context.Context, jobID string, seed int64) error
+	// GetSpotCheckSeed returns the spot check seed of a job, or zero if none was stored
+	GetSpotCheckSeed(ctx context.Context, jobID string) (int64, error)
+	// GetResultUsage returns the total size of the results of the completed executions of a client's jobs
+	GetResultUsage(ctx context.Context, clientID string) (uint64, error)
+	// GetResourceUsage returns the total resources requested by the in progress jobs of a client, as computed by
+	// JobResources when the jobs were created
+	GetResourceUsage(ctx context.Context, clientID string) (model.ResourceUsageData, error)
+	// CreateSchedule persists a new schedule
+	CreateSchedule(ctx context.Context, schedule model.Schedule) error
+	// UpdateSchedule replaces a schedule, including the history of its runs
+	UpdateSchedule(ctx context.Context, schedule model.Schedule) error
+	// GetSchedule returns a schedule by id
+	GetSchedule(ctx context.Context, id string) (model.Schedule, error)
+	// GetSchedules returns the schedules owned by a requester node, ordered by creation time
+	GetSchedules(ctx context.Context, requesterNodeID string) ([]model.Schedule, error)
+	// DeleteSchedule deletes a schedule
+	DeleteSchedule(ctx context.Context, id string) error
+	// CreateWorkflow persists a new workflow
+	CreateWorkflow(ctx context.Context, workflow model.Workflow) error
+	// UpdateWorkflow replaces a workflow, including the state of its jobs
+	UpdateWorkflow(ctx context.Context, workflow model.Workflow) error
+	// GetWorkflow returns a workflow by id
+	GetWorkflow(ctx context.Context, id string) (model.Workflow, error)
+	// GetInProgressWorkflows returns the workflows tracked by a requester node that are still in progress,
+	// ordered by creation time
+	GetInProgressWorkflows(ctx context.Context, requesterNodeID string) ([]model.Workflow, error)
 }
 
 type UpdateJobStateRequest struct {
@@ -51,7 +115,9 @@ type UpdateShardStateRequest struct {
 	ShardID   model.ShardID
 	Condition UpdateShardCondition
 	NewState  model.ShardStateType
-	Comment   string
+	// NewTrust is the new trust of the shard, which is left unchanged if it is ShardTrustUnknown
+	NewTrust model.ShardTrust
+	Comment  string
 }
 
 type UpdateExecutionRequest struct {
@@ -61,6 +127,33 @@ type UpdateExecutionRequest struct {
 	Comment     string
 }
 
+type UpdateJobRequesterRequest struct {
+	JobID string
+	// ExpectedRequesterNodeID is the requester node that must own the job for the ownership to be transferred
+	ExpectedRequesterNodeID string
+	NewRequester            model.JobRequester
+	Comment                 string
+}
+
+type AcquireLeaseRequest struct {
+	Name     string
+	Holder   string
+	Duration time.Duration
+}
+
+// Lease is a time bound ownership of a named resource, which is used to coordinate multiple nodes sharing the same
+// store. The holder has to renew the lease before it expires to keep it.
+type Lease struct {
+	Name      string
+	Holder    string
+	ExpiresAt time.Time
+}
+
+// IsExpired returns true if the lease expired at the given time
+func (l Lease) IsExpired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
 type UpdateJobCondition struct {
 	ExpectedState    model.JobStateType
 	UnexpectedStates []model.JobStateType
@@ -2,7 +2,9 @@ package jobstore
 
 import (
 	"context"
+	"math"
 
+	"github.com/filecoin-project/bacalhau/pkg/compute/capacity"
 	jobutils "github.com/filecoin-project/bacalhau/pkg/job"
 	"github.com/filecoin-project/bacalhau/pkg/model"
 	"github.com/rs/zerolog/log"
@@ -15,6 +17,14 @@ func GetStateResolver(db Store) *jobutils.StateResolver {
 	)
 }
 
+// JobResources returns the resources requested by all the executions of a job, which are one per shard and
+// concurrent execution, and one more for each spot checked shard.
+func JobResources(spec model.Spec) model.ResourceUsageData {
+	executions := math.Max(1, float64(spec.ExecutionPlan.TotalShards))*math.Max(1, float64(spec.Deal.Concurrency)) +
+		float64(spec.SpotCheckCount())
+	return capacity.ParseResourceUsageConfig(spec.Resources).Multi(executions)
+}
+
 // CompleteShard a helper function to complete a shard, and update the job state if all other shards are completed.
 func CompleteShard(ctx context.Context, db Store, shardID model.ShardID) error {
 	shardState, err := db.GetShardState(ctx, shardID)
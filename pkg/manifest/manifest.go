@@ -0,0 +1,197 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// Manifest lists the files of a shard result, and is signed by the compute node that produced them, so that
+// clients can detect results that were partially downloaded or tampered with.
+type Manifest struct {
+	ExecutionID string `json:"ExecutionID"`
+	NodeID      string `json:"NodeID"`
+	Files       []File `json:"Files"`
+	// PublicKey is the marshaled libp2p public key of the node, which must match the node ID
+	PublicKey []byte `json:"PublicKey"`
+	// Signature of the manifest without its signature by the private key of the node
+	Signature []byte `json:"Signature,omitempty"`
+}
+
+// File is a file of a shard result, with its slash separated path relative to the result directory.
+type File struct {
+	Path   string `json:"Path"`
+	Size   int64  `json:"Size"`
+	SHA256 string `json:"SHA256"`
+}
+
+// Write lists the files of the result directory in a manifest signed by the private key of the node, and writes
+// it to the result directory.
+func Write(resultDir, executionID string, privateKey crypto.PrivKey) error {
+	nodeID, err := peer.IDFromPrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+	publicKey, err := crypto.MarshalPublicKey(privateKey.GetPublic())
+	if err != nil {
+		return err
+	}
+	files, err := listFiles(resultDir)
+	if err != nil {
+		return err
+	}
+
+	manifest := Manifest{
+		ExecutionID: executionID,
+		NodeID:      nodeID.String(),
+		Files:       files,
+		PublicKey:   publicKey,
+	}
+	payload, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	manifest.Signature, err = privateKey.Sign(payload)
+	if err != nil {
+		return fmt.Errorf("failed to sign result manifest: %w", err)
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(resultDir, model.DownloadFilenameManifest), content, model.DownloadFilePerm)
+}
+
+// Verify checks that the manifest of the result directory was signed by the given node, and that the directory
+// contains exactly the files of the manifest.
+func Verify(resultDir, nodeID string) (*Manifest, error) {
+	return VerifySubset(resultDir, nodeID, func(string) bool { return true })
+}
+
+// VerifySubset is Verify for a result directory that was only partially downloaded, where files of the manifest
+// for which include returns false may be missing.
+func VerifySubset(resultDir, nodeID string, include func(path string) bool) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(resultDir, model.DownloadFilenameManifest))
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err = json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid result manifest: %w", err)
+	}
+
+	if manifest.NodeID != nodeID {
+		return nil, fmt.Errorf("result manifest is for node %s instead of %s", manifest.NodeID, nodeID)
+	}
+	publicKey, err := crypto.UnmarshalPublicKey(manifest.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key in result manifest: %w", err)
+	}
+	keyID, err := peer.IDFromPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	if keyID.String() != nodeID {
+		return nil, fmt.Errorf("result manifest public key belongs to %s instead of %s", keyID, nodeID)
+	}
+	unsigned := manifest
+	unsigned.Signature = nil
+	payload, err := json.Marshal(unsigned)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := publicKey.Verify(payload, manifest.Signature)
+	if err != nil || !valid {
+		return nil, fmt.Errorf("invalid signature of result manifest of node %s", nodeID)
+	}
+
+	files, err := listFiles(resultDir)
+	if err != nil {
+		return nil, err
+	}
+	expected := make(map[string]File, len(manifest.Files))
+	for _, file := range manifest.Files {
+		expected[file.Path] = file
+	}
+	for _, file := range files {
+		expectedFile, ok := expected[file.Path]
+		if !ok {
+			return nil, fmt.Errorf("file %s is not in the result manifest", file.Path)
+		}
+		if file != expectedFile {
+			return nil, fmt.Errorf("file %s does not match the result manifest: got %d bytes with SHA-256 %s, "+
+				"expected %d bytes with SHA-256 %s", file.Path, file.Size, file.SHA256, expectedFile.Size, expectedFile.SHA256)
+		}
+		delete(expected, file.Path)
+	}
+	var missing []string
+	for path := range expected {
+		if include(path) {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("files of the result manifest are missing: %v", missing)
+	}
+	return &manifest, nil
+}
+
+// listFiles returns the files of the result directory other than its manifest, sorted by path.
+func listFiles(resultDir string) ([]File, error) {
+	var files []File
+	err := filepath.WalkDir(resultDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		relativePath, err := filepath.Rel(resultDir, path)
+		if err != nil {
+			return err
+		}
+		if relativePath == model.DownloadFilenameManifest {
+			return nil
+		}
+		size, hash, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, File{
+			Path:   filepath.ToSlash(relativePath),
+			Size:   size,
+			SHA256: hash,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files, nil
+}
+
+func hashFile(path string) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,138 @@
+//go:build unit || !integration
+
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/suite"
+)
+
+type ManifestSuite struct {
+	suite.Suite
+	privateKey crypto.PrivKey
+	nodeID     string
+	resultDir  string
+}
+
+func TestManifestSuite(t *testing.T) {
+	suite.Run(t, new(ManifestSuite))
+}
+
+// Before each test
+func (s *ManifestSuite) SetupTest() {
+	var err error
+	s.privateKey, _, err = crypto.GenerateKeyPair(crypto.Ed25519, -1)
+	s.Require().NoError(err)
+	id, err := peer.IDFromPrivateKey(s.privateKey)
+	s.Require().NoError(err)
+	s.nodeID = id.String()
+
+	s.resultDir = s.T().TempDir()
+	s.writeFile(model.DownloadFilenameStdout, "hello")
+	s.writeFile(model.DownloadFilenameExitCode, "0")
+	s.writeFile("outputs/data/result.csv", "1,2,3")
+}
+
+func (s *ManifestSuite) TestWriteAndVerify() {
+	s.Require().NoError(Write(s.resultDir, "execution-1", s.privateKey))
+
+	manifest, err := Verify(s.resultDir, s.nodeID)
+	s.Require().NoError(err)
+	s.Equal("execution-1", manifest.ExecutionID)
+	s.Equal(s.nodeID, manifest.NodeID)
+	s.Equal([]File{
+		{Path: "exitCode", Size: 1, SHA256: sha256Hex("0")},
+		{Path: "outputs/data/result.csv", Size: 5, SHA256: sha256Hex("1,2,3")},
+		{Path: "stdout", Size: 5, SHA256: sha256Hex("hello")},
+	}, manifest.Files)
+
+	// writing the manifest again doesn't list the previous manifest
+	s.Require().NoError(Write(s.resultDir, "execution-1", s.privateKey))
+	_, err = Verify(s.resultDir, s.nodeID)
+	s.Require().NoError(err)
+}
+
+func (s *ManifestSuite) TestVerifyMismatch() {
+	for _, tc := range []struct {
+		name   string
+		change func()
+	}{
+		{name: "modified file", change: func() { s.writeFile("stdout", "HELLO") }},
+		{name: "truncated file", change: func() { s.writeFile("outputs/data/result.csv", "1,2") }},
+		{name: "missing file", change: func() { s.Require().NoError(os.Remove(filepath.Join(s.resultDir, "stdout"))) }},
+		{name: "extra file", change: func() { s.writeFile("outputs/extra.txt", "extra") }},
+		{name: "modified manifest", change: func() {
+			s.updateManifest(func(manifest *Manifest) { manifest.ExecutionID = "execution-2" })
+		}},
+		{name: "missing signature", change: func() {
+			s.updateManifest(func(manifest *Manifest) { manifest.Signature = nil })
+		}},
+		{name: "other node key", change: func() {
+			otherKey, _, err := crypto.GenerateKeyPair(crypto.Ed25519, -1)
+			s.Require().NoError(err)
+			s.Require().NoError(Write(s.resultDir, "execution-1", otherKey))
+		}},
+	} {
+		s.Run(tc.name, func() {
+			s.SetupTest()
+			s.Require().NoError(Write(s.resultDir, "execution-1", s.privateKey))
+			tc.change()
+			_, err := Verify(s.resultDir, s.nodeID)
+			s.Error(err)
+		})
+	}
+}
+
+func (s *ManifestSuite) TestVerifySubset() {
+	s.Require().NoError(Write(s.resultDir, "execution-1", s.privateKey))
+	s.Require().NoError(os.RemoveAll(filepath.Join(s.resultDir, "outputs")))
+	excludeOutputs := func(path string) bool { return !strings.HasPrefix(path, "outputs/") }
+
+	_, err := VerifySubset(s.resultDir, s.nodeID, excludeOutputs)
+	s.Require().NoError(err)
+	_, err = Verify(s.resultDir, s.nodeID)
+	s.Error(err, "excluded files are required by Verify")
+
+	// files that are present are still verified
+	s.writeFile("stdout", "HELLO")
+	_, err = VerifySubset(s.resultDir, s.nodeID, excludeOutputs)
+	s.Error(err)
+}
+
+func (s *ManifestSuite) TestVerifyMissingManifest() {
+	_, err := Verify(s.resultDir, s.nodeID)
+	s.Error(err)
+}
+
+func (s *ManifestSuite) writeFile(name, content string) {
+	path := filepath.Join(s.resultDir, filepath.FromSlash(name))
+	s.Require().NoError(os.MkdirAll(filepath.Dir(path), os.ModePerm))
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0600))
+}
+
+func (s *ManifestSuite) updateManifest(update func(*Manifest)) {
+	path := filepath.Join(s.resultDir, model.DownloadFilenameManifest)
+	content, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	var manifest Manifest
+	s.Require().NoError(json.Unmarshal(content, &manifest))
+	update(&manifest)
+	content, err = json.Marshal(manifest)
+	s.Require().NoError(err)
+	s.Require().NoError(os.WriteFile(path, content, 0600))
+}
+
+func sha256Hex(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(hash[:])
+}
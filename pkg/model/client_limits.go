@@ -0,0 +1,31 @@
+package model
+
+// ClientLimitsConfig is the configuration of ClientLimits, with resources in the same format as job specs
+type ClientLimitsConfig struct {
+	// SubmissionsPerMinute is the number of jobs the client can submit in any minute
+	SubmissionsPerMinute int `json:"SubmissionsPerMinute,omitempty"`
+	// MaxInProgressJobs is the number of jobs of the client that can be in progress at the same time
+	MaxInProgressJobs int `json:"MaxInProgressJobs,omitempty"`
+	// MaxResources is the total resources that the in progress jobs of the client can request, counting every
+	// execution of each shard
+	MaxResources ResourceUsageConfig `json:"MaxResources,omitempty"`
+}
+
+// ClientLimits limits the jobs that a client can submit to a requester node. Zero values are unlimited.
+type ClientLimits struct {
+	SubmissionsPerMinute int               `json:"SubmissionsPerMinute,omitempty"`
+	MaxInProgressJobs    int               `json:"MaxInProgressJobs,omitempty"`
+	MaxResources         ResourceUsageData `json:"MaxResources,omitempty"`
+}
+
+// ClientUsage is the usage of a client counted against its ClientLimits
+type ClientUsage struct {
+	ClientID string `json:"ClientID"`
+	// SubmissionsLastMinute is the number of jobs the client submitted in the last minute
+	SubmissionsLastMinute int `json:"SubmissionsLastMinute"`
+	// InProgressJobs is the number of jobs of the client that are not in a terminal state
+	InProgressJobs int `json:"InProgressJobs"`
+	// Resources is the total resources requested by the in progress jobs of the client
+	Resources ResourceUsageData `json:"Resources"`
+	Limits    ClientLimits      `json:"Limits"`
+}
@@ -18,6 +18,10 @@ type RunCommandResult struct {
 
 	// Runner error
 	ErrorMsg string `json:"runnerError"`
+
+	// versions of the inputs that were resolved when preparing them, such as the commit of a git branch,
+	// by the path they were mounted at
+	InputVersions map[string]string `json:"inputVersions,omitempty"`
 }
 
 func NewRunCommandResult() *RunCommandResult {
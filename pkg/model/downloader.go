@@ -1,21 +1,70 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 const (
-	DownloadFilenameStdout    = "stdout"
-	DownloadFilenameStderr    = "stderr"
-	DownloadFilenameExitCode  = "exitCode"
-	DownloadVolumesFolderName = "combined_results"
-	DownloadShardsFolderName  = "per_shard"
-	DownloadCIDsFolderName    = "raw"
-	DownloadFolderPerm        = 0755
-	DownloadFilePerm          = 0644
-	DefaultIPFSTimeout        = 5 * time.Minute
+	DownloadFilenameStdout     = "stdout"
+	DownloadFilenameStderr     = "stderr"
+	DownloadFilenameExitCode   = "exitCode"
+	DownloadFilenameManifest   = "manifest.json"
+	DownloadFilenameState      = ".bacalhau-download.json"
+	DownloadFilenameSummary    = "summary.json"
+	DownloadVolumesFolderName  = "combined_results"
+	DownloadShardsFolderName   = "per_shard"
+	DownloadCIDsFolderName     = "raw"
+	DownloadMergedFolderName   = "merged_results"
+	DownloadFolderPerm         = 0755
+	DownloadFilePerm           = 0644
+	DefaultIPFSTimeout         = 5 * time.Minute
+	DefaultDownloadParallelism = 4
 )
 
 type DownloaderSettings struct {
 	Timeout        time.Duration
 	OutputDir      string
 	IPFSSwarmAddrs string
+	// Endpoint and region of the S3-compatible service results were published to
+	S3Endpoint string
+	S3Region   string
+	// Basic auth credentials of the HTTP server results were published to as archives
+	HTTPUsername string
+	HTTPPassword string
+	// Directory the results published by local publishers are mounted at on the client
+	LocalPublisherRoot string
+	// Number of results downloaded concurrently
+	Parallelism int
+	// Indexes of the shards whose results are downloaded, otherwise the results of all shards are downloaded
+	ShardIndexes []int
+	// Names of the output volumes that are downloaded, otherwise all output volumes are downloaded
+	OutputVolumeNames []string
+	// Merge the results of all shards into a single tree with annotated logs, renamed conflicting files and a
+	// summary of the exit codes of the shards
+	Merge bool
+	// Accept results without a manifest, such as results published by nodes that predate manifests, whose content
+	// can't be verified. Otherwise such results fail to download.
+	AllowMissingManifest bool
+}
+
+// IncludesOutputVolume returns whether the output volume with the given name is downloaded.
+func (s *DownloaderSettings) IncludesOutputVolume(name string) bool {
+	if len(s.OutputVolumeNames) == 0 {
+		return true
+	}
+	for _, volumeName := range s.OutputVolumeNames {
+		if volumeName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IncludesPath returns whether the file with the given slash separated path relative to the result directory is
+// downloaded. Files at the top of the result directory, such as stdout, are always downloaded, while files in
+// output volumes are only downloaded if their output volume is.
+func (s *DownloaderSettings) IncludesPath(relativePath string) bool {
+	volume, _, nested := strings.Cut(relativePath, "/")
+	return !nested || s.IncludesOutputVolume(volume)
 }
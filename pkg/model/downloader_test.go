@@ -0,0 +1,28 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloaderSettings_IncludesPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		volumes  []string
+		path     string
+		included bool
+	}{
+		{name: "all-volumes", volumes: nil, path: "outputs/data.csv", included: true},
+		{name: "selected-volume", volumes: []string{"outputs"}, path: "outputs/nested/data.csv", included: true},
+		{name: "other-volume", volumes: []string{"outputs"}, path: "logs/run.log", included: false},
+		{name: "top-level-file", volumes: []string{"outputs"}, path: DownloadFilenameStdout, included: true},
+		{name: "volume-prefix", volumes: []string{"out"}, path: "outputs/data.csv", included: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := DownloaderSettings{OutputVolumeNames: tt.volumes}
+			require.Equal(t, tt.included, settings.IncludesPath(tt.path))
+		})
+	}
+}
@@ -103,6 +103,9 @@ type ExecutionState struct {
 	NodeID string `json:"NodeId"`
 	// Compute node reference for this shard execution
 	ComputeReference string `json:"ComputeReference"`
+	// Attempt is the scheduling attempt of the shard that created this execution, starting from 1.
+	// Executions created before retries were supported have no attempt, and are considered part of the first attempt.
+	Attempt int `json:"Attempt,omitempty"`
 	// State is the current state of the execution
 	State ExecutionStateType `json:"State"`
 	// an arbitrary status message
@@ -112,6 +115,9 @@ type ExecutionState struct {
 	VerificationProposal []byte             `json:"VerificationProposal,omitempty"`
 	VerificationResult   VerificationResult `json:"VerificationResult,omitempty"`
 	PublishedResult      StorageSpec        `json:"PublishedResults,omitempty"`
+	// ResultSize is the size in bytes of the published result, as reported by the compute node that published it.
+	// The requester trusts the reported size and doesn't verify it against the published result.
+	ResultSize uint64 `json:"ResultSize,omitempty"`
 
 	// RunOutput of the job
 	RunOutput *RunCommandResult `json:"RunOutput,omitempty"`
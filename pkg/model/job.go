@@ -1,6 +1,7 @@
 package model
 
 import (
+	"math"
 	"time"
 
 	"github.com/imdario/mergo"
@@ -136,6 +137,44 @@ type Deal struct {
 	// jobs will be spread evenly across the network (assuming that this value
 	// is some large proportion of the size of the network).
 	MinBids int `json:"MinBids,omitempty"`
+	// The policy used by the requester node to reschedule shards whose
+	// executions failed on other compute nodes.
+	RetryPolicy RetryPolicy `json:"RetryPolicy,omitempty"`
+	// The fraction of shards that the spot check verifier re-executes on
+	// another compute node to compare their results. The other shards are
+	// accepted without being verified.
+	SpotCheckFraction float64 `json:"SpotCheckFraction,omitempty"`
+}
+
+// RetryPolicy describes how many times and how often a shard can be rescheduled
+// on new compute nodes after its executions have failed.
+type RetryPolicy struct {
+	// The maximum number of times a shard will be scheduled, including the
+	// first attempt. Zero or one means failed shards are never retried.
+	MaxAttempts int `json:"MaxAttempts,omitempty"`
+	// The number of seconds to wait before asking new nodes to bid on a
+	// failed shard. The delay doubles with every subsequent attempt, up to
+	// MaxRetryBackoff.
+	Backoff float64 `json:"Backoff,omitempty"`
+	// Whether nodes that already failed to execute the shard should be
+	// excluded when rescheduling it.
+	ExcludeFailedNodes bool `json:"ExcludeFailedNodes,omitempty"`
+}
+
+// MaxRetryBackoff is the longest a shard waits before it is retried, however many attempts it failed.
+const MaxRetryBackoff = time.Hour
+
+// BackoffDuration returns how long to wait before the given attempt, where the first attempt is 1.
+func (r RetryPolicy) BackoffDuration(attempt int) time.Duration {
+	if attempt <= 1 || r.Backoff <= 0 {
+		return 0
+	}
+	// computed in floating point, so that large attempts and backoffs are clamped instead of overflowing
+	seconds := r.Backoff * math.Pow(2, float64(attempt-2)) //nolint:gomnd
+	if seconds >= MaxRetryBackoff.Seconds() {
+		return MaxRetryBackoff
+	}
+	return time.Duration(seconds * float64(time.Second))
 }
 
 // LabelSelectorRequirement A selector that contains values, a key, and an operator that relates the key and values.
@@ -162,9 +201,15 @@ type Spec struct {
 
 	Verifier Verifier `json:"Verifier,omitempty"`
 
+	// how the tolerant verifier compares the results of executions
+	VerificationRules VerificationRules `json:"VerificationRules,omitempty"`
+
 	// there can be multiple publishers for the job
 	Publisher Publisher `json:"Publisher,omitempty"`
 
+	// how the results are packaged before they are published, such as in a compressed archive
+	ResultPackaging ResultPackaging `json:"ResultPackaging,omitempty"`
+
 	// executor specific data
 	Docker   JobSpecDocker   `json:"Docker,omitempty"`
 	Language JobSpecLanguage `json:"Language,omitempty"`
@@ -173,6 +218,9 @@ type Spec struct {
 	// the compute (cpu, ram) resources this job requires
 	Resources ResourceUsageConfig `json:"Resources,omitempty"`
 
+	// the maximum size of the results of each shard
+	OutputLimits OutputLimitsConfig `json:"OutputLimits,omitempty"`
+
 	// The type of networking access that the job needs
 	Network NetworkConfig `json:"Network,omitempty"`
 
@@ -180,6 +228,11 @@ type Spec struct {
 	// This includes the time required to run, verify and publish results
 	Timeout float64 `json:"Timeout,omitempty"`
 
+	// The priority of the job relative to other jobs on the same compute node.
+	// Jobs with higher priority are run first when compute nodes order their
+	// queue by priority, and can preempt running jobs with lower priority.
+	Priority int `json:"Priority,omitempty"`
+
 	// the data volumes we will read in the job
 	// for example "read this ipfs cid"
 	// TODO: #667 Replace with "Inputs", "Outputs" (note the caps) for yaml/json when we update the n.js file
@@ -218,6 +271,19 @@ func (s *Spec) GetTimeout() time.Duration {
 	return time.Duration(s.Timeout * float64(time.Second))
 }
 
+// SpotCheckCount returns the number of shards that the spot check verifier re-executes on a second compute node
+func (s *Spec) SpotCheckCount() int {
+	totalShards := s.ExecutionPlan.TotalShards
+	if s.Verifier != VerifierSpotCheck || s.Deal.SpotCheckFraction <= 0 || totalShards <= 0 {
+		return 0
+	}
+	count := int(math.Ceil(s.Deal.SpotCheckFraction * float64(totalShards)))
+	if count > totalShards {
+		return totalShards
+	}
+	return count
+}
+
 // Return pointers to all the storage specs in the spec.
 func (s *Spec) AllStorageSpecs() []*StorageSpec {
 	storages := []*StorageSpec{
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -35,6 +36,15 @@ func (s JobStateType) IsTerminal() bool {
 	return s == JobStateCompleted || s == JobStateError || s == JobStateCancelled
 }
 
+func ParseJobStateType(str string) (JobStateType, error) {
+	for typ := JobStateNew; typ <= JobStateCompleted; typ++ {
+		if equal(typ.String(), str) {
+			return typ, nil
+		}
+	}
+	return JobStateNew, fmt.Errorf("unknown job state type '%s'", str)
+}
+
 func (s JobStateType) MarshalText() ([]byte, error) {
 	return []byte(s.String()), nil
 }
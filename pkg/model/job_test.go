@@ -0,0 +1,22 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyBackoffDuration(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 100, Backoff: 1.5}
+	require.Zero(t, policy.BackoffDuration(1))
+	require.Equal(t, 1500*time.Millisecond, policy.BackoffDuration(2))
+	require.Equal(t, 3*time.Second, policy.BackoffDuration(3))
+	require.Equal(t, 6*time.Second, policy.BackoffDuration(4))
+	require.Zero(t, RetryPolicy{MaxAttempts: 3}.BackoffDuration(3))
+
+	// the backoff is clamped instead of overflowing
+	require.Equal(t, MaxRetryBackoff, policy.BackoffDuration(14))
+	require.Equal(t, MaxRetryBackoff, policy.BackoffDuration(100))
+	require.Equal(t, MaxRetryBackoff, RetryPolicy{Backoff: 1e300}.BackoffDuration(2))
+}
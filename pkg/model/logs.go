@@ -0,0 +1,30 @@
+package model
+
+// LogStreamType is the output stream a log entry was written to.
+type LogStreamType string
+
+const (
+	LogStreamStdout LogStreamType = "stdout"
+	LogStreamStderr LogStreamType = "stderr"
+)
+
+// LogEntry is a chunk of output written by an execution to one of its streams.
+type LogEntry struct {
+	Stream LogStreamType `json:"Stream"`
+	Data   string        `json:"Data"`
+}
+
+// ExecutionLogs is the output of an execution since a given offset, where the offset is the number of
+// log entries that were already read.
+type ExecutionLogs struct {
+	// JobID, ShardIndex and NodeID identify the execution that produced the logs
+	JobID      string `json:"JobID"`
+	ShardIndex int    `json:"ShardIndex"`
+	NodeID     string `json:"NodeID,omitempty"`
+	// Logs are the entries written since the requested offset, in the order they were written
+	Logs []LogEntry `json:"Logs"`
+	// NextOffset is the offset to read the entries written after these logs
+	NextOffset int `json:"NextOffset"`
+	// Finished is true when the execution will not write any more logs
+	Finished bool `json:"Finished"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// NodeOutcome is the outcome of a compute node's execution that affects the node's reputation.
+//
+//go:generate stringer -type=NodeOutcome --trimprefix=NodeOutcome --output node_outcome_string.go
+type NodeOutcome int
+
+const (
+	nodeOutcomeUnknown NodeOutcome = iota // must be first
+	// The result of the node matched the results of other nodes.
+	NodeOutcomeVerificationPassed
+	// The result of the node didn't match the results of other nodes.
+	NodeOutcomeVerificationFailed
+	// The node was still executing the job when it timed out.
+	NodeOutcomeTimeout
+	// The node reported a failure while executing the job.
+	NodeOutcomeComputeFailure
+	// The node bid on the job, but couldn't be reached once its bid was accepted.
+	NodeOutcomeNoShow
+	nodeOutcomeDone // must be last
+)
+
+// verificationFailureWeight is how many other failures a failed verification counts as, as returning wrong results
+// is worse than not returning any.
+const verificationFailureWeight = 2
+
+// NodeReputation counts the outcomes of the executions of a compute node, which the requester uses to prefer
+// reliable nodes.
+type NodeReputation struct {
+	NodeID              string    `json:"NodeID"`
+	VerificationsPassed int       `json:"VerificationsPassed"`
+	VerificationsFailed int       `json:"VerificationsFailed"`
+	Timeouts            int       `json:"Timeouts"`
+	ComputeFailures     int       `json:"ComputeFailures"`
+	NoShows             int       `json:"NoShows"`
+	UpdateTime          time.Time `json:"UpdateTime,omitempty"`
+}
+
+// Record counts an outcome of the node
+func (r *NodeReputation) Record(outcome NodeOutcome) {
+	switch outcome {
+	case NodeOutcomeVerificationPassed:
+		r.VerificationsPassed++
+	case NodeOutcomeVerificationFailed:
+		r.VerificationsFailed++
+	case NodeOutcomeTimeout:
+		r.Timeouts++
+	case NodeOutcomeComputeFailure:
+		r.ComputeFailures++
+	case NodeOutcomeNoShow:
+		r.NoShows++
+	}
+}
+
+// Score returns the reputation of the node between 0 and 1, which is the smoothed ratio of passed verifications
+// to all outcomes. A node without any recorded outcome scores 0.5.
+func (r NodeReputation) Score() float64 {
+	failures := verificationFailureWeight*r.VerificationsFailed + r.Timeouts + r.ComputeFailures + r.NoShows
+	return float64(r.VerificationsPassed+1) / float64(r.VerificationsPassed+failures+2) //nolint:gomnd
+}
+
+// NodeReputationResetPayload is the signed payload of a request to reset the reputation of a compute node.
+type NodeReputationResetPayload struct {
+	// the id of the client that is resetting the reputation
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the id of the compute node whose reputation is reset
+	NodeID string `json:"NodeID,omitempty" validate:"required"`
+}
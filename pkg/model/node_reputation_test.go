@@ -0,0 +1,25 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeReputationScore(t *testing.T) {
+	require.Equal(t, 0.5, NodeReputation{}.Score())
+
+	var reputation NodeReputation
+	for i := 0; i < 3; i++ {
+		reputation.Record(NodeOutcomeVerificationPassed)
+	}
+	require.Equal(t, 0.8, reputation.Score())
+
+	// a failed verification weighs more than other failures
+	failedVerification := reputation
+	failedVerification.Record(NodeOutcomeVerificationFailed)
+	timedOut := reputation
+	timedOut.Record(NodeOutcomeTimeout)
+	require.Less(t, failedVerification.Score(), timedOut.Score())
+	require.Less(t, timedOut.Score(), reputation.Score())
+}
@@ -0,0 +1,34 @@
+package model
+
+// OutputLimitsConfig limits the size of the results of a job, as github.com/c2h5oh/datasize strings (e.g. 10GB).
+type OutputLimitsConfig struct {
+	// the maximum size of each output volume
+	Volume string `json:"Volume,omitempty"`
+	// the maximum total size of the results, including stdout, stderr and all output volumes
+	Total string `json:"Total,omitempty"`
+}
+
+// OutputLimits are the numeric values in bytes for OutputLimitsConfig, where zero means unlimited.
+type OutputLimits struct {
+	Volume uint64 `json:"Volume,omitempty"`
+	Total  uint64 `json:"Total,omitempty"`
+}
+
+// Min returns the stricter of both limits.
+func (l OutputLimits) Min(other OutputLimits) OutputLimits {
+	return OutputLimits{
+		Volume: minLimit(l.Volume, other.Volume),
+		Total:  minLimit(l.Total, other.Total),
+	}
+}
+
+func (l OutputLimits) IsZero() bool {
+	return l.Volume == 0 && l.Total == 0
+}
+
+func minLimit(a, b uint64) uint64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
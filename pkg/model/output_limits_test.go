@@ -0,0 +1,27 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputLimitsMin(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		limits   OutputLimits
+		other    OutputLimits
+		expected OutputLimits
+	}{
+		{name: "unlimited", expected: OutputLimits{}},
+		{name: "only one", limits: OutputLimits{Volume: 10}, other: OutputLimits{Total: 20}, expected: OutputLimits{Volume: 10, Total: 20}},
+		{name: "stricter", limits: OutputLimits{Volume: 10, Total: 5}, other: OutputLimits{Volume: 5, Total: 10}, expected: OutputLimits{Volume: 5, Total: 5}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.limits.Min(test.other))
+			require.Equal(t, test.expected, test.other.Min(test.limits))
+		})
+	}
+	require.True(t, OutputLimits{}.IsZero())
+	require.False(t, OutputLimits{Total: 1}.IsZero())
+}
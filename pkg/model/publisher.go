@@ -13,6 +13,9 @@ const (
 	PublisherIpfs
 	PublisherFilecoin
 	PublisherEstuary
+	PublisherS3
+	PublisherLocal
+	PublisherHTTP
 	publisherDone // must be last
 )
 
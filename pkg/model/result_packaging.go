@@ -0,0 +1,69 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+//go:generate stringer -type=ResultPackaging --trimprefix=ResultPackaging
+type ResultPackaging int
+
+const (
+	// ResultPackagingNone publishes the files of a result as they are.
+	ResultPackagingNone ResultPackaging = iota
+
+	// ResultPackagingTarGz publishes a result as a gzip compressed tar archive.
+	ResultPackagingTarGz
+
+	// ResultPackagingTarZst publishes a result as a zstd compressed tar archive, which compresses better and
+	// faster than gzip.
+	ResultPackagingTarZst
+
+	// ResultPackagingZip publishes a result as a zip archive, which can be opened without extra tools on most
+	// desktops.
+	ResultPackagingZip
+)
+
+// ParseResultPackaging parses the name of a result packaging, such as TarGz, or its file extension, such as tar.gz.
+func ParseResultPackaging(s string) (ResultPackaging, error) {
+	name := strings.ReplaceAll(strings.TrimPrefix(strings.TrimSpace(s), "."), ".", "")
+	for typ := ResultPackagingNone; typ <= ResultPackagingZip; typ++ {
+		if equal(typ.String(), name) {
+			return typ, nil
+		}
+	}
+
+	return ResultPackagingNone, fmt.Errorf("%T: unknown type '%s'", ResultPackagingNone, s)
+}
+
+func ResultPackagingTypes() []ResultPackaging {
+	var res []ResultPackaging
+	for typ := ResultPackagingNone; typ <= ResultPackagingZip; typ++ {
+		res = append(res, typ)
+	}
+	return res
+}
+
+// Extension returns the file extension of the archive of a result packaging, or an empty string for none.
+func (p ResultPackaging) Extension() string {
+	switch p {
+	case ResultPackagingTarGz:
+		return ".tar.gz"
+	case ResultPackagingTarZst:
+		return ".tar.zst"
+	case ResultPackagingZip:
+		return ".zip"
+	default:
+		return ""
+	}
+}
+
+func (p ResultPackaging) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *ResultPackaging) UnmarshalText(text []byte) (err error) {
+	name := string(text)
+	*p, err = ParseResultPackaging(name)
+	return
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResultPackaging(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ResultPackaging
+		wantErr bool
+	}{
+		{input: "none", want: ResultPackagingNone},
+		{input: "TarGz", want: ResultPackagingTarGz},
+		{input: "tar.gz", want: ResultPackagingTarGz},
+		{input: ".tar.zst", want: ResultPackagingTarZst},
+		{input: "zip", want: ResultPackagingZip},
+		{input: "rar", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseResultPackaging(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResultPackagingJSON(t *testing.T) {
+	spec := Spec{ResultPackaging: ResultPackagingTarZst}
+	content, err := json.Marshal(spec)
+	require.NoError(t, err)
+	require.Contains(t, string(content), `"ResultPackaging":"TarZst"`)
+
+	var decoded Spec
+	require.NoError(t, json.Unmarshal(content, &decoded))
+	require.Equal(t, ResultPackagingTarZst, decoded.ResultPackaging)
+
+	// specs without a result packaging publish results as they are
+	var empty Spec
+	require.NoError(t, json.Unmarshal([]byte(`{}`), &empty))
+	require.Equal(t, ResultPackagingNone, empty.ResultPackaging)
+}
@@ -0,0 +1,156 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScheduleOverlapPolicy defines what happens when a scheduled job is due while the job submitted by the
+// previous run of the same schedule is still in progress.
+//
+//go:generate stringer -type=ScheduleOverlapPolicy --trimprefix=ScheduleOverlap --output schedule_overlap_policy_string.go
+type ScheduleOverlapPolicy int
+
+const (
+	// Submit the new job while the previous one is still in progress
+	ScheduleOverlapAllow ScheduleOverlapPolicy = iota // must be first
+
+	// Skip the new run
+	ScheduleOverlapSkip
+
+	// Submit the new job once the previous one completes. At most one run is queued at a time.
+	ScheduleOverlapQueue
+
+	// Cancel the previous job and submit the new one
+	ScheduleOverlapCancelPrevious
+
+	scheduleOverlapDone // must be last
+)
+
+func IsValidScheduleOverlapPolicy(p ScheduleOverlapPolicy) bool {
+	return p >= ScheduleOverlapAllow && p < scheduleOverlapDone
+}
+
+func ParseScheduleOverlapPolicy(str string) (ScheduleOverlapPolicy, error) {
+	for typ := ScheduleOverlapAllow; typ < scheduleOverlapDone; typ++ {
+		if equal(typ.String(), str) {
+			return typ, nil
+		}
+	}
+	return ScheduleOverlapAllow, fmt.Errorf("unknown schedule overlap policy '%s'", str)
+}
+
+func ScheduleOverlapPolicies() []ScheduleOverlapPolicy {
+	var res []ScheduleOverlapPolicy
+	for typ := ScheduleOverlapAllow; typ < scheduleOverlapDone; typ++ {
+		res = append(res, typ)
+	}
+	return res
+}
+
+func (p ScheduleOverlapPolicy) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *ScheduleOverlapPolicy) UnmarshalText(text []byte) (err error) {
+	*p, err = ParseScheduleOverlapPolicy(string(text))
+	return
+}
+
+// ScheduleSpec describes a job that is submitted by the requester node on a recurring schedule.
+type ScheduleSpec struct {
+	// Cron expression with the minute, hour, day of month, month and day of week fields, in UTC.
+	Cron string `json:"Cron"`
+
+	// What to do when a run is due while the job of the previous run is still in progress.
+	OverlapPolicy ScheduleOverlapPolicy `json:"OverlapPolicy,omitempty"`
+
+	// The specification of the job that is submitted at each run.
+	JobSpec Spec `json:"JobSpec"`
+}
+
+type ScheduleCreatePayload struct {
+	// the id of the client that is creating the schedule
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	APIVersion string `json:"APIVersion,omitempty" example:"V1beta1" validate:"required"`
+
+	// The specification of this schedule.
+	Spec *ScheduleSpec `json:"Spec,omitempty" validate:"required"`
+}
+
+type ScheduleDeletePayload struct {
+	// the id of the client that is deleting the schedule
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the id of the schedule to be deleted
+	ScheduleID string `json:"ScheduleID,omitempty" validate:"required"`
+}
+
+// Schedule is a recurring job definition owned by a requester node, along with the history of its runs.
+type Schedule struct {
+	// ID is the unique identifier for the schedule
+	ID string `json:"ID"`
+	// ClientID is the id of the client that created the schedule
+	ClientID string `json:"ClientID"`
+	// RequesterNodeID is the id of the requester node that runs the schedule
+	RequesterNodeID string `json:"RequesterNodeID,omitempty"`
+	// APIVersion of the job template
+	APIVersion string `json:"APIVersion"`
+	// Spec is the specification of the schedule
+	Spec ScheduleSpec `json:"Spec"`
+	// CreateTime is the time when the schedule was created
+	CreateTime time.Time `json:"CreateTime"`
+	// NextRun is the time of the next run of the schedule
+	NextRun time.Time `json:"NextRun"`
+	// Runs is the history of the most recent runs, oldest first
+	Runs []ScheduleRun `json:"Runs,omitempty"`
+}
+
+// ScheduleRunStateType is the outcome of a run of a schedule.
+//
+//go:generate stringer -type=ScheduleRunStateType --trimprefix=ScheduleRunState --output schedule_run_state_string.go
+type ScheduleRunStateType int
+
+const (
+	ScheduleRunStateUndefined ScheduleRunStateType = iota // must be first
+
+	// The run is waiting for the job of the previous run to complete
+	ScheduleRunStateQueued
+
+	// The job of the run was submitted
+	ScheduleRunStateSubmitted
+
+	// The run was skipped because the job of the previous run was still in progress
+	ScheduleRunStateSkipped
+
+	// The job of the run failed to be submitted
+	ScheduleRunStateError
+)
+
+func (s ScheduleRunStateType) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *ScheduleRunStateType) UnmarshalText(text []byte) (err error) {
+	name := string(text)
+	for typ := ScheduleRunStateUndefined; typ <= ScheduleRunStateError; typ++ {
+		if equal(typ.String(), name) {
+			*s = typ
+			return
+		}
+	}
+	return
+}
+
+// ScheduleRun is a run of a schedule.
+type ScheduleRun struct {
+	// ScheduledAt is the time the run was due
+	ScheduledAt time.Time `json:"ScheduledAt"`
+	// State is the outcome of the run
+	State ScheduleRunStateType `json:"State"`
+	// JobID is the id of the job submitted by the run
+	JobID string `json:"JobID,omitempty"`
+	// Message explains the outcome of the run, such as why it was skipped
+	Message string `json:"Message,omitempty"`
+}
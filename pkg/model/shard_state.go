@@ -40,6 +40,37 @@ func (s *ShardStateType) UnmarshalText(text []byte) (err error) {
 	return
 }
 
+// ShardTrust represents how much the accepted result of a shard can be trusted, according to its verification.
+//
+//go:generate stringer -type=ShardTrust --trimprefix=ShardTrust --output shard_trust_string.go
+type ShardTrust int
+
+const (
+	// The shard was not verified yet.
+	ShardTrustUnknown ShardTrust = iota
+	// The results of independent executions of the shard matched.
+	ShardTrustVerified
+	// The result of the shard was accepted without being compared with the result of another execution.
+	ShardTrustUnverified
+	// The results of independent executions of the shard did not match.
+	ShardTrustMismatched
+)
+
+func (t ShardTrust) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *ShardTrust) UnmarshalText(text []byte) (err error) {
+	name := string(text)
+	for typ := ShardTrustUnknown; typ <= ShardTrustMismatched; typ++ {
+		if equal(typ.String(), name) {
+			*t = typ
+			return
+		}
+	}
+	return
+}
+
 // ShardID represents a unique identifier for a shard across all jobs.
 type ShardID struct {
 	JobID string `json:"JobID,omitempty"`
@@ -67,6 +98,8 @@ type ShardState struct {
 	Executions []ExecutionState `json:"Executions"`
 	// State is the current state of the shard
 	State ShardStateType `json:"State"`
+	// Trust is how much the accepted result of the shard can be trusted, according to its last verification
+	Trust ShardTrust `json:"Trust,omitempty"`
 	// Version is the version of the shard state. It is incremented every time the shard state is updated.
 	Version int `json:"Version"`
 	// CreateTime is the time when the shard was created, which is the same as the job creation time.
@@ -79,3 +112,14 @@ type ShardState struct {
 func (s ShardState) ID() ShardID {
 	return ShardID{JobID: s.JobID, Index: s.ShardIndex}
 }
+
+// Attempt returns the latest scheduling attempt of the shard, starting from 1.
+func (s ShardState) Attempt() int {
+	attempt := 1
+	for _, execution := range s.Executions {
+		if execution.Attempt > attempt {
+			attempt = execution.Attempt
+		}
+	}
+	return attempt
+}
@@ -21,6 +21,8 @@ const (
 	StorageSourceEstuary
 	StorageSourceInline
 	StorageSourceLocalDirectory
+	StorageSourceS3
+	StorageSourceGit
 	storageSourceDone // must be last
 )
 
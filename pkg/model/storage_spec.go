@@ -31,6 +31,46 @@ type StorageSpec struct {
 
 	// Additional properties specific to each driver
 	Metadata map[string]string `json:"Metadata,omitempty"`
+
+	// Reference to the published result of an upstream job of the same workflow. The requester node replaces
+	// it with the storage spec of the published result before submitting the job.
+	Upstream *UpstreamResult `json:"Upstream,omitempty"`
+
+	// The objects of an S3-compatible bucket to use, when the storage source is S3
+	S3 *S3StorageSpec `json:"S3,omitempty"`
+
+	// The commit of a git repository to use, when the storage source is Git
+	Git *GitStorageSpec `json:"Git,omitempty"`
+}
+
+// S3StorageSpec selects objects of an S3-compatible bucket. The endpoint and credentials used to access the
+// bucket are configured on the nodes rather than in the job.
+type S3StorageSpec struct {
+	Bucket string `json:"Bucket"`
+	// Key selects a single object, which is mounted at the path of the storage spec
+	Key string `json:"Key,omitempty"`
+	// Prefix selects all the objects whose key starts with it when no Key is set, and the objects are mounted
+	// in a directory at the path of the storage spec. An empty prefix selects all the objects of the bucket.
+	Prefix string `json:"Prefix,omitempty"`
+}
+
+// GitStorageSpec selects a commit of a git repository, whose files are mounted at the path of the storage spec.
+type GitStorageSpec struct {
+	// Repository is the URL of the repository to clone, such as https://github.com/org/repo.git or file:///path/to/repo
+	Repository string `json:"Repository"`
+	// Ref is the branch, tag or commit SHA to check out. The default branch of the repository is used if empty.
+	// A commit SHA is verified to match the commit that is checked out.
+	Ref string `json:"Ref,omitempty"`
+	// Subdirectory of the repository to mount instead of the whole repository. Only this subdirectory is checked out.
+	Subdirectory string `json:"Subdirectory,omitempty"`
+}
+
+// UpstreamResult references the published result of a shard of an upstream job within a workflow.
+type UpstreamResult struct {
+	// Name of the upstream job within the workflow
+	Job string `json:"Job"`
+	// Index of the shard whose published result is used
+	ShardIndex int `json:"ShardIndex,omitempty"`
 }
 
 // PublishedStorageSpec is a wrapper for a StorageSpec that has been published
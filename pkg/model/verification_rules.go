@@ -0,0 +1,51 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"path"
+
+	"go.uber.org/multierr"
+)
+
+// VerificationRules configure how the tolerant verifier compares the results of executions, so that results
+// that only differ in ways that don't matter, such as timestamps or float rounding, are verified.
+type VerificationRules struct {
+	// IgnorePaths are globs of paths in the results that are not compared, such as "stderr" or
+	// "outputs/*.log". A glob without a slash matches the names of files and directories at any depth.
+	IgnorePaths []string `json:"IgnorePaths,omitempty"`
+	// OutputVolumes are the names of the only output volumes that are compared. All output volumes are compared
+	// if it is empty.
+	OutputVolumes []string `json:"OutputVolumes,omitempty"`
+	// NormalizeLineEndings compares files as if their CRLF line endings were LF line endings
+	NormalizeLineEndings bool `json:"NormalizeLineEndings,omitempty"`
+	// AbsoluteTolerance and RelativeTolerance are how much the numbers in CSV and JSON files can differ. Two
+	// numbers are equal if their difference is at most the absolute tolerance, or at most the relative
+	// tolerance times the largest of their absolute values.
+	AbsoluteTolerance float64 `json:"AbsoluteTolerance,omitempty"`
+	RelativeTolerance float64 `json:"RelativeTolerance,omitempty"`
+}
+
+// HasNumericTolerance returns whether numbers in CSV and JSON files are compared with a tolerance.
+func (r VerificationRules) HasNumericTolerance() bool {
+	return r.AbsoluteTolerance > 0 || r.RelativeTolerance > 0
+}
+
+func (r VerificationRules) IsValid() (err error) {
+	for _, pattern := range r.IgnorePaths {
+		if _, matchErr := path.Match(pattern, ""); matchErr != nil || pattern == "" {
+			err = multierr.Append(err, fmt.Errorf("invalid ignored path glob %q", pattern))
+		}
+	}
+	if !isValidTolerance(r.AbsoluteTolerance) {
+		err = multierr.Append(err, fmt.Errorf("invalid absolute tolerance %v", r.AbsoluteTolerance))
+	}
+	if !isValidTolerance(r.RelativeTolerance) {
+		err = multierr.Append(err, fmt.Errorf("invalid relative tolerance %v", r.RelativeTolerance))
+	}
+	return
+}
+
+func isValidTolerance(tolerance float64) bool {
+	return tolerance >= 0 && !math.IsInf(tolerance, 0)
+}
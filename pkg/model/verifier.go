@@ -11,6 +11,8 @@ const (
 	verifierUnknown Verifier = iota // must be first
 	VerifierNoop
 	VerifierDeterministic
+	VerifierTolerant
+	VerifierSpotCheck
 	verifierDone // must be last
 )
 
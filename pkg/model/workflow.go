@@ -0,0 +1,193 @@
+package model
+
+import (
+	"time"
+)
+
+// WorkflowSpec describes a set of jobs with dependencies between them, that are submitted together to the
+// requester node. A job is only submitted to the network once all the jobs it depends on have completed.
+type WorkflowSpec struct {
+	// The jobs of the workflow.
+	Jobs []WorkflowJobSpec `json:"Jobs"`
+}
+
+// WorkflowJobSpec is a job of a workflow.
+type WorkflowJobSpec struct {
+	// Name of the job, which is unique within the workflow and used by other jobs to reference it.
+	Name string `json:"Name"`
+
+	// Names of the jobs that must complete before this job is submitted. Jobs whose results are
+	// referenced by the inputs of this job are implicit dependencies and don't need to be listed.
+	DependsOn []string `json:"DependsOn,omitempty"`
+
+	// The specification of the job. Inputs can reference the published results of upstream jobs.
+	Spec Spec `json:"Spec"`
+}
+
+// Dependencies returns the names of the jobs that must complete before this job is submitted, including
+// the jobs referenced by its inputs.
+func (j WorkflowJobSpec) Dependencies() []string {
+	var dependencies []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			dependencies = append(dependencies, name)
+		}
+	}
+	for _, name := range j.DependsOn {
+		add(name)
+	}
+	for _, input := range j.Spec.Inputs {
+		if input.Upstream != nil {
+			add(input.Upstream.Job)
+		}
+	}
+	return dependencies
+}
+
+type WorkflowCreatePayload struct {
+	// the id of the client that is submitting the workflow
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	APIVersion string `json:"APIVersion,omitempty" example:"V1beta1" validate:"required"`
+
+	// The specification of this workflow.
+	Spec *WorkflowSpec `json:"Spec,omitempty" validate:"required"`
+}
+
+type WorkflowCancelPayload struct {
+	// the id of the client that is canceling the workflow
+	ClientID string `json:"ClientID,omitempty" validate:"required"`
+
+	// the id of the workflow to be canceled
+	WorkflowID string `json:"WorkflowID,omitempty" validate:"required"`
+
+	// The reason that the workflow is being canceled
+	Reason string `json:"Reason,omitempty"`
+}
+
+// WorkflowStateType is the state of a workflow, which is an aggregate of the states of its jobs.
+//
+//go:generate stringer -type=WorkflowStateType --trimprefix=WorkflowState --output workflow_state_string.go
+type WorkflowStateType int
+
+const (
+	WorkflowStateNew WorkflowStateType = iota // must be first
+
+	// Some jobs are still waiting for their dependencies or running
+	WorkflowStateInProgress
+
+	// The workflow is canceled by the user, or one of its jobs was canceled
+	WorkflowStateCancelled
+
+	// One of the jobs has failed
+	WorkflowStateError
+
+	// All jobs have completed successfully
+	WorkflowStateCompleted
+)
+
+// IsTerminal returns true if the given workflow state signals the end of the lifecycle of the workflow.
+func (s WorkflowStateType) IsTerminal() bool {
+	return s == WorkflowStateCompleted || s == WorkflowStateError || s == WorkflowStateCancelled
+}
+
+func (s WorkflowStateType) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *WorkflowStateType) UnmarshalText(text []byte) (err error) {
+	name := string(text)
+	for typ := WorkflowStateNew; typ <= WorkflowStateCompleted; typ++ {
+		if equal(typ.String(), name) {
+			*s = typ
+			return
+		}
+	}
+	return
+}
+
+// WorkflowJobStateType is the state of a job within a workflow.
+//
+//go:generate stringer -type=WorkflowJobStateType --trimprefix=WorkflowJobState --output workflow_job_state_string.go
+type WorkflowJobStateType int
+
+const (
+	WorkflowJobStateUndefined WorkflowJobStateType = iota // must be first
+
+	// The job is waiting for its dependencies to complete
+	WorkflowJobStatePending
+
+	// The job was submitted to the network and is running
+	WorkflowJobStateSubmitted
+
+	// The job was canceled, or will never be submitted because the workflow was canceled
+	WorkflowJobStateCancelled
+
+	// The job failed, or will never be submitted because one of its dependencies failed
+	WorkflowJobStateError
+
+	// The job has completed successfully
+	WorkflowJobStateCompleted
+)
+
+// IsTerminal returns true if the given workflow job state signals that the job will not change anymore.
+func (s WorkflowJobStateType) IsTerminal() bool {
+	return s == WorkflowJobStateCompleted || s == WorkflowJobStateError || s == WorkflowJobStateCancelled
+}
+
+func (s WorkflowJobStateType) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *WorkflowJobStateType) UnmarshalText(text []byte) (err error) {
+	name := string(text)
+	for typ := WorkflowJobStateUndefined; typ <= WorkflowJobStateCompleted; typ++ {
+		if equal(typ.String(), name) {
+			*s = typ
+			return
+		}
+	}
+	return
+}
+
+// WorkflowState is the state of a workflow and its jobs.
+type WorkflowState struct {
+	// WorkflowID is the unique identifier for the workflow
+	WorkflowID string `json:"WorkflowID"`
+	// ClientID is the id of the client that submitted the workflow
+	ClientID string `json:"ClientID"`
+	// State is the current state of the workflow
+	State WorkflowStateType `json:"State"`
+	// Jobs is the state of each job of the workflow, in the order they were declared
+	Jobs []WorkflowJobState `json:"Jobs"`
+	// CreateTime is the time when the workflow was submitted
+	CreateTime time.Time `json:"CreateTime"`
+	// UpdateTime is the time when the workflow state was last updated
+	UpdateTime time.Time `json:"UpdateTime"`
+}
+
+// Workflow is a submitted workflow, as persisted by the requester node that tracks the progress of its jobs.
+type Workflow struct {
+	// APIVersion of the jobs of the workflow
+	APIVersion string `json:"APIVersion"`
+	// RequesterNodeID is the id of the requester node that tracks the workflow
+	RequesterNodeID string `json:"RequesterNodeID,omitempty"`
+	// Spec is the specification of the workflow
+	Spec WorkflowSpec `json:"Spec"`
+	// State is the state of the workflow and its jobs
+	State WorkflowState `json:"State"`
+}
+
+// WorkflowJobState is the state of a job within a workflow.
+type WorkflowJobState struct {
+	// Name of the job within the workflow
+	Name string `json:"Name"`
+	// JobID is the id of the job once it is submitted to the network
+	JobID string `json:"JobID,omitempty"`
+	// State is the current state of the job within the workflow
+	State WorkflowJobStateType `json:"State"`
+	// Message explains the current state, such as which dependency failed
+	Message string `json:"Message,omitempty"`
+}
@@ -12,11 +12,13 @@ import (
 	"github.com/filecoin-project/bacalhau/pkg/compute/store"
 	"github.com/filecoin-project/bacalhau/pkg/compute/store/inmemory"
 	"github.com/filecoin-project/bacalhau/pkg/executor"
+	"github.com/filecoin-project/bacalhau/pkg/logger"
 	"github.com/filecoin-project/bacalhau/pkg/model"
 	"github.com/filecoin-project/bacalhau/pkg/publicapi"
 	"github.com/filecoin-project/bacalhau/pkg/publisher"
 	"github.com/filecoin-project/bacalhau/pkg/simulator"
 	"github.com/filecoin-project/bacalhau/pkg/storage"
+	"github.com/filecoin-project/bacalhau/pkg/storage/cache"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 	"github.com/filecoin-project/bacalhau/pkg/transport/bprotocol"
 	simulator_protocol "github.com/filecoin-project/bacalhau/pkg/transport/simulator"
@@ -42,13 +44,17 @@ func NewComputeNode(
 	host host.Host,
 	apiServer *publicapi.APIServer,
 	config ComputeConfig,
+	executionStore store.ExecutionStore,
+	inputCache *cache.Cache,
 	simulatorNodeID string,
 	simulatorRequestHandler *simulator.RequestHandler,
 	storages storage.StorageProvider,
 	executors executor.ExecutorProvider,
 	verifiers verifier.VerifierProvider,
 	publishers publisher.PublisherProvider) (*Compute, error) {
-	executionStore := inmemory.NewStore()
+	if executionStore == nil {
+		executionStore = inmemory.NewStore()
+	}
 
 	// executor/backend
 	runningCapacityTracker := capacity.NewLocalTracker(capacity.LocalTrackerParams{
@@ -88,6 +94,8 @@ func NewComputeNode(
 		Verifiers:       verifiers,
 		Publishers:      publishers,
 		SimulatorConfig: config.SimulatorConfig,
+		SigningKey:      host.Peerstore().PrivKey(host.ID()),
+		OutputLimits:    config.JobOutputLimits,
 	})
 
 	bufferRunner := compute.NewExecutorBuffer(compute.ExecutorBufferParams{
@@ -98,6 +106,10 @@ func NewComputeNode(
 		EnqueuedCapacityTracker:    enqueuedCapacityTracker,
 		DefaultJobExecutionTimeout: config.DefaultJobExecutionTimeout,
 		BackoffDuration:            config.ExecutorBufferBackoffDuration,
+		Mode:                       config.ExecutorBufferMode,
+		AgingInterval:              config.ExecutorBufferAgingInterval,
+		Preemption:                 config.ExecutorBufferPreemption,
+		Store:                      executionStore,
 	})
 	runningInfoProvider := sensors.NewRunningExecutionsInfoProvider(sensors.RunningExecutionsInfoProviderParams{
 		Name:          "ActiveJobs",
@@ -123,7 +135,8 @@ func NewComputeNode(
 				Defaults: config.DefaultJobResourceLimits,
 			}),
 			disk.NewDiskUsageCalculator(disk.DiskUsageCalculatorParams{
-				Executors: executors,
+				Executors:    executors,
+				OutputLimits: config.JobOutputLimits,
 			}),
 		},
 	})
@@ -182,6 +195,7 @@ func NewComputeNode(
 		UsageCalculator: capacityCalculator,
 		BidStrategy:     biddingStrategy,
 		Executor:        bufferRunner,
+		Executors:       executors,
 	})
 
 	// if this node is the simulator, then we set the simulator request handler as the stream handler
@@ -201,6 +215,9 @@ func NewComputeNode(
 	debugInfoProviders := []model.DebugInfoProvider{
 		runningInfoProvider,
 	}
+	if inputCache != nil {
+		debugInfoProviders = append(debugInfoProviders, inputCache)
+	}
 
 	// register compute public http apis
 	computeAPIServer := compute_publicapi.NewComputeAPIServer(compute_publicapi.ComputeAPIServerParams{
@@ -212,9 +229,20 @@ func NewComputeNode(
 		return nil, err
 	}
 
+	// resume executions that were in progress before this node restarted, in case the execution store is persistent.
+	// Recovery runs in the background as reporting to requester nodes waits for them to be reachable.
+	recovery := compute.NewRecovery(compute.RecoveryParams{
+		ID:       host.ID().String(),
+		Store:    executionStore,
+		Executor: bufferRunner,
+		Callback: computeCallback,
+	})
+	recoveryCtx, cancelRecovery := context.WithCancel(logger.ContextWithNodeIDLogger(context.Background(), host.ID().String()))
+	go recovery.Recover(recoveryCtx)
+
 	// A single cleanup function to make sure the order of closing dependencies is correct
 	cleanupFunc := func(ctx context.Context) {
-		// pass
+		cancelRecovery()
 	}
 
 	return &Compute{
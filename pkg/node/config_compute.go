@@ -5,10 +5,15 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/filecoin-project/bacalhau/pkg/compute"
 	"github.com/filecoin-project/bacalhau/pkg/compute/capacity"
 	"github.com/filecoin-project/bacalhau/pkg/model"
 )
 
+// DisabledExecutorBufferAging is the executor buffer aging interval that disables aging, as a zero interval is
+// replaced with the default one.
+const DisabledExecutorBufferAging time.Duration = -1
+
 type ComputeConfigParams struct {
 	// Capacity config
 	TotalResourceLimits          model.ResourceUsageData
@@ -17,8 +22,13 @@ type ComputeConfigParams struct {
 	DefaultJobResourceLimits     model.ResourceUsageData
 	PhysicalResourcesProvider    capacity.Provider
 	IgnorePhysicalResourceLimits bool
+	JobOutputLimits              model.OutputLimits
 
 	ExecutorBufferBackoffDuration time.Duration
+	ExecutorBufferMode            compute.ExecutorBufferMode
+	// ExecutorBufferAgingInterval is replaced with the default if zero. Use DisabledExecutorBufferAging to disable aging.
+	ExecutorBufferAgingInterval time.Duration
+	ExecutorBufferPreemption    bool
 
 	// Timeout config
 	JobNegotiationTimeout      time.Duration
@@ -44,9 +54,17 @@ type ComputeConfig struct {
 	JobResourceLimits            model.ResourceUsageData
 	DefaultJobResourceLimits     model.ResourceUsageData
 	IgnorePhysicalResourceLimits bool
+	// JobOutputLimits limit the size of the results of each execution, on top of the output limits of its job
+	JobOutputLimits model.OutputLimits
 
 	// How long the buffer would backoff before polling the queue again for new jobs
 	ExecutorBufferBackoffDuration time.Duration
+	// The order in which the buffer runs enqueued executions
+	ExecutorBufferMode compute.ExecutorBufferMode
+	// How long an execution waits in the buffer to gain one level of priority, when ordering by priority
+	ExecutorBufferAgingInterval time.Duration
+	// Whether running executions can be preempted by higher priority ones, when ordering by priority
+	ExecutorBufferPreemption bool
 
 	// JobNegotiationTimeout default timeout value to hold a bid for a job
 	JobNegotiationTimeout time.Duration
@@ -103,6 +121,15 @@ func NewComputeConfigWith(params ComputeConfigParams) (config ComputeConfig) {
 	if params.ExecutorBufferBackoffDuration == 0 {
 		params.ExecutorBufferBackoffDuration = DefaultComputeConfig.ExecutorBufferBackoffDuration
 	}
+	if params.ExecutorBufferMode == "" {
+		params.ExecutorBufferMode = DefaultComputeConfig.ExecutorBufferMode
+	}
+	if params.ExecutorBufferAgingInterval == 0 {
+		params.ExecutorBufferAgingInterval = DefaultComputeConfig.ExecutorBufferAgingInterval
+	} else if params.ExecutorBufferAgingInterval < 0 {
+		// the executor buffer disables aging with a zero interval
+		params.ExecutorBufferAgingInterval = 0
+	}
 
 	// Get available physical resources in the host
 	physicalResourcesProvider := params.PhysicalResourcesProvider
@@ -139,7 +166,11 @@ func NewComputeConfigWith(params ComputeConfigParams) (config ComputeConfig) {
 		JobResourceLimits:             jobResourceLimits,
 		DefaultJobResourceLimits:      defaultJobResourceLimits,
 		IgnorePhysicalResourceLimits:  params.IgnorePhysicalResourceLimits,
+		JobOutputLimits:               params.JobOutputLimits,
 		ExecutorBufferBackoffDuration: params.ExecutorBufferBackoffDuration,
+		ExecutorBufferMode:            params.ExecutorBufferMode,
+		ExecutorBufferAgingInterval:   params.ExecutorBufferAgingInterval,
+		ExecutorBufferPreemption:      params.ExecutorBufferPreemption,
 
 		JobNegotiationTimeout:      params.JobNegotiationTimeout,
 		MinJobExecutionTimeout:     params.MinJobExecutionTimeout,
@@ -3,6 +3,7 @@ package node
 import (
 	"time"
 
+	"github.com/filecoin-project/bacalhau/pkg/compute"
 	"github.com/filecoin-project/bacalhau/pkg/compute/capacity/system"
 	"github.com/filecoin-project/bacalhau/pkg/model"
 )
@@ -14,6 +15,8 @@ var DefaultComputeConfig = ComputeConfigParams{
 		Memory: 100 * 1024 * 1024, // 100Mi
 	},
 	ExecutorBufferBackoffDuration: 50 * time.Millisecond,
+	ExecutorBufferMode:            compute.ExecutorBufferModeFIFO,
+	ExecutorBufferAgingInterval:   time.Minute,
 
 	JobNegotiationTimeout:      3 * time.Minute,
 	MinJobExecutionTimeout:     500 * time.Millisecond,
@@ -29,4 +32,12 @@ var DefaultRequesterConfig = RequesterConfigParams{
 
 	HousekeepingBackgroundTaskInterval: 30 * time.Second,
 	NodeRankRandomnessRange:            10,
+
+	RecoveryRetryInterval: 10 * time.Second,
+	RecoveryMaxAttempts:   6,
+
+	WorkflowCheckInterval: 5 * time.Second,
+	ScheduleCheckInterval: 5 * time.Second,
+
+	LeaseDuration: 30 * time.Second,
 }
@@ -15,6 +15,22 @@ type RequesterConfigParams struct {
 	HousekeepingBackgroundTaskInterval time.Duration
 	NodeRankRandomnessRange            int
 	SimulatorConfig                    model.SimulatorConfigRequester
+
+	RecoveryRetryInterval time.Duration
+	RecoveryMaxAttempts   int
+
+	WorkflowCheckInterval time.Duration
+	ScheduleCheckInterval time.Duration
+
+	LeaseDuration time.Duration
+
+	DefaultResultQuota uint64
+	ClientResultQuotas map[string]uint64
+
+	DefaultClientLimits model.ClientLimits
+	ClientLimits        map[string]model.ClientLimits
+
+	MinNodeReputation float64
 }
 
 type RequesterConfig struct {
@@ -30,6 +46,39 @@ type RequesterConfig struct {
 	// NodeRankRandomnessRange defines the range of randomness used to rank nodes
 	NodeRankRandomnessRange int
 	SimulatorConfig         model.SimulatorConfigRequester
+
+	// RecoveryRetryInterval interval between attempts to reach a compute node when recovering in progress jobs
+	// after a restart
+	RecoveryRetryInterval time.Duration
+	// RecoveryMaxAttempts number of attempts to reach a compute node when recovering in progress jobs before
+	// failing its executions
+	RecoveryMaxAttempts int
+
+	// WorkflowCheckInterval interval between checks of the jobs of in progress workflows, to submit the jobs
+	// whose dependencies completed
+	WorkflowCheckInterval time.Duration
+	// ScheduleCheckInterval interval between checks for due runs of scheduled jobs
+	ScheduleCheckInterval time.Duration
+
+	// LeaseDuration how long the leases of the requester node in the job store stay valid without being renewed.
+	// When the job store is shared, the jobs of a requester node are adopted by another node once its lease expires.
+	LeaseDuration time.Duration
+
+	// DefaultResultQuota total size in bytes of the results that the jobs of each client can store before the
+	// client can't submit new jobs. Zero is unlimited.
+	DefaultResultQuota uint64
+	// ClientResultQuotas overrides DefaultResultQuota for specific clients, by client ID
+	ClientResultQuotas map[string]uint64
+
+	// DefaultClientLimits limits the rate at which each client can submit jobs, and the number of and the resources
+	// requested by the in progress jobs of each client. Zero values are unlimited.
+	DefaultClientLimits model.ClientLimits
+	// ClientLimits replaces DefaultClientLimits for specific clients, by client ID
+	ClientLimits map[string]model.ClientLimits
+
+	// MinNodeReputation reputation score between 0 and 1 below which compute nodes are not asked to execute jobs.
+	// Nodes without any recorded outcome score 0.5. Zero never excludes nodes.
+	MinNodeReputation float64
 }
 
 func NewRequesterConfigWithDefaults() RequesterConfig {
@@ -57,6 +106,21 @@ func NewRequesterConfigWith(params RequesterConfigParams) (config RequesterConfi
 	if params.NodeRankRandomnessRange == 0 {
 		params.NodeRankRandomnessRange = DefaultRequesterConfig.NodeRankRandomnessRange
 	}
+	if params.RecoveryRetryInterval == 0 {
+		params.RecoveryRetryInterval = DefaultRequesterConfig.RecoveryRetryInterval
+	}
+	if params.RecoveryMaxAttempts == 0 {
+		params.RecoveryMaxAttempts = DefaultRequesterConfig.RecoveryMaxAttempts
+	}
+	if params.WorkflowCheckInterval == 0 {
+		params.WorkflowCheckInterval = DefaultRequesterConfig.WorkflowCheckInterval
+	}
+	if params.ScheduleCheckInterval == 0 {
+		params.ScheduleCheckInterval = DefaultRequesterConfig.ScheduleCheckInterval
+	}
+	if params.LeaseDuration == 0 {
+		params.LeaseDuration = DefaultRequesterConfig.LeaseDuration
+	}
 
 	config = RequesterConfig{
 		MinJobExecutionTimeout:     params.MinJobExecutionTimeout,
@@ -66,6 +130,22 @@ func NewRequesterConfigWith(params RequesterConfigParams) (config RequesterConfi
 
 		NodeRankRandomnessRange: params.NodeRankRandomnessRange,
 		SimulatorConfig:         params.SimulatorConfig,
+
+		RecoveryRetryInterval: params.RecoveryRetryInterval,
+		RecoveryMaxAttempts:   params.RecoveryMaxAttempts,
+
+		WorkflowCheckInterval: params.WorkflowCheckInterval,
+		ScheduleCheckInterval: params.ScheduleCheckInterval,
+
+		LeaseDuration: params.LeaseDuration,
+
+		DefaultResultQuota: params.DefaultResultQuota,
+		ClientResultQuotas: params.ClientResultQuotas,
+
+		DefaultClientLimits: params.DefaultClientLimits,
+		ClientLimits:        params.ClientLimits,
+
+		MinNodeReputation: params.MinNodeReputation,
 	}
 
 	return config
@@ -77,8 +77,11 @@ func (f *StandardStorageProvidersFactory) Get(
 		ctx,
 		nodeConfig.CleanupManager,
 		executor_util.StandardStorageProviderOptions{
-			API:                  nodeConfig.IPFSClient,
-			FilecoinUnsealedPath: nodeConfig.FilecoinUnsealedPath,
+			API:                       nodeConfig.IPFSClient,
+			FilecoinUnsealedPath:      nodeConfig.FilecoinUnsealedPath,
+			S3:                        nodeConfig.S3Config,
+			InputCache:                nodeConfig.InputCache,
+			AllowLocalGitRepositories: nodeConfig.AllowLocalGitRepositories,
 		},
 	)
 }
@@ -98,8 +101,11 @@ func (f *StandardExecutorsFactory) Get(
 		executor_util.StandardExecutorOptions{
 			DockerID: fmt.Sprintf("bacalhau-%s", nodeConfig.Host.ID().String()),
 			Storage: executor_util.StandardStorageProviderOptions{
-				API:                  nodeConfig.IPFSClient,
-				FilecoinUnsealedPath: nodeConfig.FilecoinUnsealedPath,
+				API:                       nodeConfig.IPFSClient,
+				FilecoinUnsealedPath:      nodeConfig.FilecoinUnsealedPath,
+				S3:                        nodeConfig.S3Config,
+				InputCache:                nodeConfig.InputCache,
+				AllowLocalGitRepositories: nodeConfig.AllowLocalGitRepositories,
 			},
 		},
 	)
@@ -138,6 +144,9 @@ func (f *StandardPublishersFactory) Get(
 		nodeConfig.IPFSClient,
 		nodeConfig.EstuaryAPIKey,
 		nodeConfig.LotusConfig,
+		nodeConfig.S3PublisherConfig,
+		nodeConfig.LocalPublisherConfig,
+		nodeConfig.HTTPPublisherConfig,
 	)
 }
 
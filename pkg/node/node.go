@@ -5,17 +5,23 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
 	"github.com/filecoin-project/bacalhau/pkg/config"
 	"github.com/filecoin-project/bacalhau/pkg/ipfs"
 	"github.com/filecoin-project/bacalhau/pkg/jobstore"
 	"github.com/filecoin-project/bacalhau/pkg/model"
 	"github.com/filecoin-project/bacalhau/pkg/publicapi"
 	filecoinlotus "github.com/filecoin-project/bacalhau/pkg/publisher/filecoin_lotus"
+	httppublisher "github.com/filecoin-project/bacalhau/pkg/publisher/http"
+	localpublisher "github.com/filecoin-project/bacalhau/pkg/publisher/local"
+	s3publisher "github.com/filecoin-project/bacalhau/pkg/publisher/s3"
 	"github.com/filecoin-project/bacalhau/pkg/pubsub"
 	"github.com/filecoin-project/bacalhau/pkg/pubsub/libp2p"
 	"github.com/filecoin-project/bacalhau/pkg/routing"
 	"github.com/filecoin-project/bacalhau/pkg/routing/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/s3"
 	"github.com/filecoin-project/bacalhau/pkg/simulator"
+	"github.com/filecoin-project/bacalhau/pkg/storage/cache"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 	"github.com/imdario/mergo"
 	libp2p_pubsub "github.com/libp2p/go-libp2p-pubsub"
@@ -35,20 +41,28 @@ type NodeConfig struct {
 	IPFSClient                ipfs.Client
 	CleanupManager            *system.CleanupManager
 	JobStore                  jobstore.Store
+	ExecutionStore            store.ExecutionStore
+	InputCache                *cache.Cache
 	Host                      host.Host
 	FilecoinUnsealedPath      string
 	EstuaryAPIKey             string
+	S3Config                  s3.Config
 	HostAddress               string
 	APIPort                   int
 	ComputeConfig             ComputeConfig
 	RequesterNodeConfig       RequesterConfig
 	APIServerConfig           publicapi.APIServerConfig
 	LotusConfig               *filecoinlotus.PublisherConfig
+	S3PublisherConfig         *s3publisher.PublisherConfig
+	LocalPublisherConfig      *localpublisher.PublisherConfig
+	HTTPPublisherConfig       *httppublisher.PublisherConfig
 	SimulatorNodeID           string
 	IsRequesterNode           bool
 	IsComputeNode             bool
 	Labels                    map[string]string
 	NodeInfoPublisherInterval time.Duration
+	// AllowLocalGitRepositories allows git inputs to be cloned from the local filesystem, which is only meant for tests
+	AllowLocalGitRepositories bool
 }
 
 // Lazy node dependency injector that generate instances of different
@@ -240,6 +254,8 @@ func NewNode(
 			routedHost,
 			apiServer,
 			config.ComputeConfig,
+			config.ExecutionStore,
+			config.InputCache,
 			config.SimulatorNodeID,
 			simulatorRequestHandler,
 			storageProviders,
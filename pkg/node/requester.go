@@ -7,6 +7,7 @@ import (
 	"github.com/filecoin-project/bacalhau/pkg/compute"
 	"github.com/filecoin-project/bacalhau/pkg/eventhandler"
 	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/logger"
 	"github.com/filecoin-project/bacalhau/pkg/model"
 	"github.com/filecoin-project/bacalhau/pkg/publicapi"
 	"github.com/filecoin-project/bacalhau/pkg/pubsub"
@@ -99,6 +100,12 @@ func NewRequesterNode(
 		ranking.NewLabelsNodeRanker(),
 		ranking.NewMaxUsageNodeRanker(),
 
+		// rankers that prefer nodes that ran previous jobs reliably
+		ranking.NewReputationNodeRanker(ranking.ReputationNodeRankerParams{
+			JobStore: jobStore,
+			MinScore: config.MinNodeReputation,
+		}),
+
 		// arbitrary rankers
 		ranking.NewRandomNodeRanker(ranking.RandomNodeRankerParams{
 			RandomnessRange: config.NodeRankRandomnessRange,
@@ -133,8 +140,24 @@ func NewRequesterNode(
 		StorageProviders:           storageProviders,
 		MinJobExecutionTimeout:     config.MinJobExecutionTimeout,
 		DefaultJobExecutionTimeout: config.DefaultJobExecutionTimeout,
+		JobStore:                   jobStore,
+		WorkflowCheckInterval:      config.WorkflowCheckInterval,
+		ScheduleCheckInterval:      config.ScheduleCheckInterval,
+		DefaultResultQuota:         config.DefaultResultQuota,
+		ClientResultQuotas:         config.ClientResultQuotas,
+		DefaultClientLimits:        config.DefaultClientLimits,
+		ClientLimits:               config.ClientLimits,
 	})
 
+	err = endpoint.ResumeSchedules(logger.ContextWithNodeIDLogger(ctx, host.ID().String()))
+	if err != nil {
+		return nil, err
+	}
+	err = endpoint.ResumeWorkflows(logger.ContextWithNodeIDLogger(ctx, host.ID().String()))
+	if err != nil {
+		return nil, err
+	}
+
 	housekeeping := requester.NewHousekeeping(requester.HousekeepingParams{
 		Endpoint: endpoint,
 		JobStore: jobStore,
@@ -210,10 +233,35 @@ func NewRequesterNode(
 		eventhandler.JobEventHandlerFunc(bufferedJobEventPubSub.Publish),
 	)
 
+	// resume jobs that were in progress before this node restarted. Recovery runs in the background as it waits
+	// for compute nodes to be reachable, and is stopped on cleanup.
+	recovery := requester.NewRecovery(requester.RecoveryParams{
+		Scheduler:     scheduler,
+		JobStore:      jobStore,
+		NodeID:        host.ID().String(),
+		RetryInterval: config.RecoveryRetryInterval,
+		MaxAttempts:   config.RecoveryMaxAttempts,
+	})
+	recoveryCtx, cancelRecovery := context.WithCancel(logger.ContextWithNodeIDLogger(context.Background(), host.ID().String()))
+	go recovery.Recover(recoveryCtx)
+
+	// coordinate with other requester nodes sharing the same job store, and adopt the jobs of the nodes that stopped
+	coordinator := requester.NewCoordinator(requester.CoordinatorParams{
+		JobStore:      jobStore,
+		Recovery:      recovery,
+		NodeID:        host.ID().String(),
+		PublicKey:     marshaledPublicKey,
+		LeaseDuration: config.LeaseDuration,
+	})
+
 	// A single cleanup function to make sure the order of closing dependencies is correct
 	cleanupFunc := func(ctx context.Context) {
-		// stop the housekeeping background task
+		// stop recovering in progress jobs, coordinating with other requester nodes, the housekeeping background task,
+		// and the retries waiting for their backoff
+		cancelRecovery()
+		coordinator.Stop()
 		housekeeping.Stop()
+		scheduler.Stop()
 
 		cleanupErr := bufferedJobEventPubSub.Close(ctx)
 		if cleanupErr != nil {
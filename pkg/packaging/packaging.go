@@ -0,0 +1,291 @@
+// Package packaging packs the result of a shard into an archive before it is published, and unpacks it again
+// after it is downloaded, according to the result packaging of the job.
+package packaging
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/util/closer"
+	"github.com/klauspost/compress/zstd"
+	"github.com/rs/zerolog/log"
+)
+
+// archiveBaseName is the name of the archive of a packaged result without its extension
+const archiveBaseName = "result"
+
+// ArchiveName returns the name of the file a result is packaged into, such as result.tar.gz.
+func ArchiveName(packaging model.ResultPackaging) string {
+	return archiveBaseName + packaging.Extension()
+}
+
+// Pack packs the files of the result directory into an archive in the target directory, and returns the path
+// of the archive. Files other than regular files and directories, such as symbolic links, are skipped.
+func Pack(ctx context.Context, packaging model.ResultPackaging, resultDir, targetDir string) (string, error) {
+	if packaging == model.ResultPackagingNone || packaging.Extension() == "" {
+		return "", fmt.Errorf("result packaging %s does not produce an archive", packaging)
+	}
+	archivePath := filepath.Join(targetDir, ArchiveName(packaging))
+	file, err := os.OpenFile(archivePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, model.DownloadFilePerm)
+	if err != nil {
+		return "", err
+	}
+	defer closer.CloseWithLogOnError("archive", file)
+
+	switch packaging {
+	case model.ResultPackagingTarGz:
+		err = packTar(ctx, resultDir, file, func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		})
+	case model.ResultPackagingTarZst:
+		err = packTar(ctx, resultDir, file, func(w io.Writer) (io.WriteCloser, error) {
+			return zstd.NewWriter(w)
+		})
+	case model.ResultPackagingZip:
+		err = packZip(ctx, resultDir, file)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to pack result as %s: %w", packaging, err)
+	}
+	return archivePath, file.Sync()
+}
+
+// Unpack extracts a packaged result in place, if the directory only contains the archive of a packaged result,
+// and returns whether it did.
+func Unpack(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !entries[0].Type().IsRegular() {
+		return false, err
+	}
+	var packaging model.ResultPackaging
+	for _, typ := range model.ResultPackagingTypes() {
+		if typ != model.ResultPackagingNone && entries[0].Name() == ArchiveName(typ) {
+			packaging = typ
+		}
+	}
+	if packaging == model.ResultPackagingNone {
+		return false, nil
+	}
+
+	archivePath := filepath.Join(dir, entries[0].Name())
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return false, err
+	}
+	defer closer.CloseWithLogOnError("archive", file)
+
+	switch packaging {
+	case model.ResultPackagingTarGz:
+		var reader *gzip.Reader
+		if reader, err = gzip.NewReader(file); err == nil {
+			err = unpackTar(reader, dir)
+		}
+	case model.ResultPackagingTarZst:
+		var reader *zstd.Decoder
+		if reader, err = zstd.NewReader(file); err == nil {
+			err = unpackTar(reader, dir)
+			reader.Close()
+		}
+	case model.ResultPackagingZip:
+		var info os.FileInfo
+		if info, err = file.Stat(); err == nil {
+			err = unpackZip(file, info.Size(), dir)
+		}
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to unpack result %s: %w", archivePath, err)
+	}
+	return true, os.Remove(archivePath)
+}
+
+// walkResult calls fn for the directories and regular files of the result directory other than the directory
+// itself, with their slash separated path relative to it.
+func walkResult(ctx context.Context, resultDir string, fn func(name, path string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(resultDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(resultDir, path)
+		if err != nil || relativePath == "." {
+			return err
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			log.Ctx(ctx).Warn().Msgf("Skipping %s when packing result, as it is not a regular file", relativePath)
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(relativePath), path, info)
+	})
+}
+
+func packTar(
+	ctx context.Context,
+	resultDir string,
+	w io.Writer,
+	compressor func(io.Writer) (io.WriteCloser, error),
+) error {
+	cw, err := compressor(w)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(cw)
+	err = walkResult(ctx, resultDir, func(name, path string, info fs.FileInfo) error {
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err = tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		return copyFrom(tw, path)
+	})
+	if err != nil {
+		return err
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return cw.Close()
+}
+
+func packZip(ctx context.Context, resultDir string, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	err := walkResult(ctx, resultDir, func(name, path string, info fs.FileInfo) error {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+		} else {
+			header.Method = zip.Deflate
+		}
+		fw, err := zw.CreateHeader(header)
+		if err != nil || info.IsDir() {
+			return err
+		}
+		return copyFrom(fw, path)
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func copyFrom(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError("file", file)
+	_, err = io.Copy(w, file)
+	return err
+}
+
+func unpackTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target, err := localPath(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, model.DownloadFolderPerm)
+		case tar.TypeReg:
+			err = writeFile(target, tr, header.FileInfo().Mode().Perm())
+		default:
+			// results only contain directories and regular files
+			err = fmt.Errorf("unsupported entry %s", header.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func unpackZip(r io.ReaderAt, size int64, dir string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, file := range zr.File {
+		target, err := localPath(dir, file.Name)
+		if err != nil {
+			return err
+		}
+		mode := file.Mode()
+		switch {
+		case mode.IsDir():
+			err = os.MkdirAll(target, model.DownloadFolderPerm)
+		case mode.IsRegular():
+			var reader io.ReadCloser
+			if reader, err = file.Open(); err == nil {
+				err = writeFile(target, reader, mode.Perm())
+				closer.CloseWithLogOnError("file", reader)
+			}
+		default:
+			err = fmt.Errorf("unsupported entry %s", file.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// localPath returns the path of an archive entry in the directory, and rejects entries outside of it.
+func localPath(dir, name string) (string, error) {
+	cleaned := path.Clean(strings.TrimSuffix(name, "/"))
+	if name == "" || path.IsAbs(name) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") ||
+		strings.Contains(name, `\`) {
+		return "", fmt.Errorf("invalid entry %q", name)
+	}
+	return filepath.Join(dir, filepath.FromSlash(cleaned)), nil
+}
+
+func writeFile(target string, r io.Reader, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), model.DownloadFolderPerm); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_EXCL, perm|0200)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(file, r); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
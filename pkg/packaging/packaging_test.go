@@ -0,0 +1,98 @@
+//go:build unit || !integration
+
+package packaging
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func writeResult(t *testing.T) string {
+	resultDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(resultDir, "stdout"), []byte("hello"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(resultDir, "outputs", "nested"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(resultDir, "outputs", "empty"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(resultDir, "outputs", "nested", "data.csv"), []byte("a,b\n1,2\n"), 0644))
+	require.NoError(t, os.Symlink("stdout", filepath.Join(resultDir, "link")))
+	return resultDir
+}
+
+func TestPackAndUnpack(t *testing.T) {
+	for _, packaging := range []model.ResultPackaging{
+		model.ResultPackagingTarGz,
+		model.ResultPackagingTarZst,
+		model.ResultPackagingZip,
+	} {
+		t.Run(packaging.String(), func(t *testing.T) {
+			resultDir := writeResult(t)
+			targetDir := t.TempDir()
+
+			archivePath, err := Pack(context.Background(), packaging, resultDir, targetDir)
+			require.NoError(t, err)
+			require.Equal(t, filepath.Join(targetDir, ArchiveName(packaging)), archivePath)
+
+			unpacked, err := Unpack(targetDir)
+			require.NoError(t, err)
+			require.True(t, unpacked)
+
+			require.NoFileExists(t, archivePath)
+			content, err := os.ReadFile(filepath.Join(targetDir, "stdout"))
+			require.NoError(t, err)
+			require.Equal(t, "hello", string(content))
+			content, err = os.ReadFile(filepath.Join(targetDir, "outputs", "nested", "data.csv"))
+			require.NoError(t, err)
+			require.Equal(t, "a,b\n1,2\n", string(content))
+			require.DirExists(t, filepath.Join(targetDir, "outputs", "empty"))
+			require.NoFileExists(t, filepath.Join(targetDir, "link"))
+		})
+	}
+}
+
+func TestPackNone(t *testing.T) {
+	_, err := Pack(context.Background(), model.ResultPackagingNone, writeResult(t), t.TempDir())
+	require.Error(t, err)
+}
+
+func TestUnpackIgnoresUnpackagedResults(t *testing.T) {
+	resultDir := writeResult(t)
+	unpacked, err := Unpack(resultDir)
+	require.NoError(t, err)
+	require.False(t, unpacked)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data.tar.gz"), []byte("not a result"), 0644))
+	unpacked, err = Unpack(dir)
+	require.NoError(t, err)
+	require.False(t, unpacked)
+}
+
+func TestUnpackRejectsEntriesOutsideTheResult(t *testing.T) {
+	dir := t.TempDir()
+	file, err := os.Create(filepath.Join(dir, ArchiveName(model.ResultPackagingTarGz)))
+	require.NoError(t, err)
+	gw := gzip.NewWriter(file)
+	tw := tar.NewWriter(gw)
+	content := []byte("escaped")
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name:     "../escaped",
+		Typeflag: tar.TypeReg,
+		Mode:     0644,
+		Size:     int64(len(content)),
+	}))
+	_, err = tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	require.NoError(t, file.Close())
+
+	_, err = Unpack(dir)
+	require.Error(t, err)
+	require.NoFileExists(t, filepath.Join(filepath.Dir(dir), "escaped"))
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/filecoin-project/bacalhau/pkg/system"
+)
+
+// AllowlistPolicy only allows requests from a fixed set of clients. The client ID of unsigned requests is only
+// claimed by the client, so the policy should be combined with TokenPolicy to restrict them.
+type AllowlistPolicy struct {
+	clientIDs map[string]bool
+}
+
+// NewAllowlistPolicy creates a policy allowing the given clients, which are identified by their client ID or by
+// their base64-encoded public key.
+func NewAllowlistPolicy(clients []string) (*AllowlistPolicy, error) {
+	clientIDs := make(map[string]bool, len(clients))
+	for _, client := range clients {
+		clientID, err := parseClient(client)
+		if err != nil {
+			return nil, err
+		}
+		clientIDs[clientID] = true
+	}
+	return &AllowlistPolicy{clientIDs: clientIDs}, nil
+}
+
+func (p *AllowlistPolicy) Authorize(_ context.Context, request Request) error {
+	if request.ClientID == "" {
+		return NewErrUnauthenticated("client ID is required")
+	}
+	if !p.clientIDs[request.ClientID] {
+		return NewErrForbidden(request.ClientID, request.Action, "client is not in the allowlist")
+	}
+	return nil
+}
+
+// parseClient returns the client ID of a client identified by its client ID or its base64-encoded public key.
+// Client IDs are hex encoded hashes, which are never valid public keys.
+func parseClient(client string) (string, error) {
+	client = strings.TrimSpace(client)
+	if client == "" {
+		return "", fmt.Errorf("empty client")
+	}
+	if isHex(client) {
+		return client, nil
+	}
+	clientID, err := system.ClientIDFromPublicKey(client)
+	if err != nil {
+		return "", fmt.Errorf("client %q is neither a client ID nor a public key: %w", client, err)
+	}
+	return clientID, nil
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
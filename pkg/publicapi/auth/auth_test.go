@@ -0,0 +1,165 @@
+//go:build unit || !integration
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	clientA = "aaaa"
+	clientB = "bbbb"
+)
+
+func requireUnauthenticated(t *testing.T, err error) {
+	require.ErrorAs(t, err, new(ErrUnauthenticated))
+}
+
+func requireForbidden(t *testing.T, err error) {
+	require.ErrorAs(t, err, new(ErrForbidden))
+}
+
+func generatePublicKey(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(x509.MarshalPKCS1PublicKey(&key.PublicKey))
+}
+
+func TestAllowlistPolicy(t *testing.T) {
+	ctx := context.Background()
+	publicKey := generatePublicKey(t)
+	keyClientID, err := system.ClientIDFromPublicKey(publicKey)
+	require.NoError(t, err)
+
+	policy, err := NewAllowlistPolicy([]string{clientA, publicKey})
+	require.NoError(t, err)
+
+	require.NoError(t, policy.Authorize(ctx, Request{Action: ActionList, ClientID: clientA}))
+	require.NoError(t, policy.Authorize(ctx, Request{Action: ActionSubmit, ClientID: keyClientID, Signed: true}))
+	requireForbidden(t, policy.Authorize(ctx, Request{Action: ActionList, ClientID: clientB}))
+	requireUnauthenticated(t, policy.Authorize(ctx, Request{Action: ActionList}))
+
+	_, err = NewAllowlistPolicy([]string{"not a client"})
+	require.Error(t, err)
+}
+
+func TestTokenPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy, err := ParseTokens(strings.NewReader(`
+# shared token
+shared-token
+bound-token ` + clientA + `
+`))
+	require.NoError(t, err)
+
+	require.NoError(t, policy.Authorize(ctx, Request{Action: ActionList, ClientID: clientB, Token: "shared-token"}))
+	require.NoError(t, policy.Authorize(ctx, Request{Action: ActionList, ClientID: clientA, Token: "bound-token"}))
+	requireForbidden(t, policy.Authorize(ctx, Request{Action: ActionList, ClientID: clientB, Token: "bound-token"}))
+	requireUnauthenticated(t, policy.Authorize(ctx, Request{Action: ActionList, ClientID: clientA, Token: "other-token"}))
+	requireUnauthenticated(t, policy.Authorize(ctx, Request{Action: ActionList, ClientID: clientA}))
+
+	_, err = ParseTokens(strings.NewReader("token client other"))
+	require.Error(t, err)
+}
+
+func TestFilePolicy(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+rules:
+  - client_ids: [`+clientA+`]
+    engines: [docker]
+    publishers: [ipfs]
+    networking: [none, http]
+    max_resources:
+      cpu: "2"
+      memory: 1gb
+  - client_ids: ["*"]
+    engines: [wasm]
+    networking: [none]
+`), 0600))
+	policy, err := NewFilePolicyFromFile(path)
+	require.NoError(t, err)
+
+	submit := func(clientID string, spec model.Spec) error {
+		return policy.Authorize(ctx, Request{Action: ActionSubmit, ClientID: clientID, Signed: true, Spec: &spec})
+	}
+	dockerSpec := model.Spec{
+		Engine:    model.EngineDocker,
+		Publisher: model.PublisherIpfs,
+		Network:   model.NetworkConfig{Type: model.NetworkHTTP},
+		Resources: model.ResourceUsageConfig{CPU: "1", Memory: "500mb"},
+	}
+	require.NoError(t, submit(clientA, dockerSpec))
+	requireForbidden(t, submit(clientB, dockerSpec))
+
+	tooMuchMemory := dockerSpec
+	tooMuchMemory.Resources.Memory = "2gb"
+	requireForbidden(t, submit(clientA, tooMuchMemory))
+
+	wrongPublisher := dockerSpec
+	wrongPublisher.Publisher = model.PublisherEstuary
+	requireForbidden(t, submit(clientA, wrongPublisher))
+
+	wasmSpec := model.Spec{Engine: model.EngineWasm, Network: model.NetworkConfig{Type: model.NetworkNone}}
+	require.NoError(t, submit(clientB, wasmSpec))
+	requireForbidden(t, submit(clientA, wasmSpec))
+	require.NoError(t, policy.Authorize(ctx, Request{Action: ActionList, ClientID: clientB}))
+}
+
+func TestFilePolicyWithoutWildcard(t *testing.T) {
+	policy, err := NewFilePolicy(PolicyFileContent{Rules: []PolicyRule{{ClientIDs: []string{clientA}}}})
+	require.NoError(t, err)
+
+	require.NoError(t, policy.Authorize(context.Background(), Request{Action: ActionCancel, ClientID: clientA}))
+	requireForbidden(t, policy.Authorize(context.Background(), Request{Action: ActionCancel, ClientID: clientB}))
+
+	_, err = NewFilePolicy(PolicyFileContent{Rules: []PolicyRule{{ClientIDs: []string{clientA}, Engines: []string{"nope"}}}})
+	require.Error(t, err)
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	allowlist, err := NewAllowlistPolicy([]string{clientA})
+	require.NoError(t, err)
+	tokens := NewTokenPolicy()
+	tokens.AddToken("token", "")
+	chain := NewChain(allowlist, tokens)
+
+	require.NoError(t, chain.Authorize(ctx, Request{Action: ActionResults, ClientID: clientA, Token: "token"}))
+	requireUnauthenticated(t, chain.Authorize(ctx, Request{Action: ActionResults, ClientID: clientA}))
+	requireForbidden(t, chain.Authorize(ctx, Request{Action: ActionResults, ClientID: clientB, Token: "token"}))
+}
+
+func TestMiddleware(t *testing.T) {
+	var token string
+	handler := Middleware(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		token = TokenFromContext(req.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/requester/list", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.Empty(t, token)
+
+	req.Header.Set("Authorization", "Bearer secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.Equal(t, "secret", token)
+
+	req.Header.Set("Authorization", "Basic secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.Empty(t, token)
+}
@@ -0,0 +1,31 @@
+package auth
+
+import "fmt"
+
+// ErrUnauthenticated is returned when the client making a request couldn't be identified
+type ErrUnauthenticated struct {
+	Reason string
+}
+
+func NewErrUnauthenticated(reason string) ErrUnauthenticated {
+	return ErrUnauthenticated{Reason: reason}
+}
+
+func (e ErrUnauthenticated) Error() string {
+	return fmt.Sprintf("unauthenticated: %s", e.Reason)
+}
+
+// ErrForbidden is returned when a client is not allowed to make a request
+type ErrForbidden struct {
+	ClientID string
+	Action   Action
+	Reason   string
+}
+
+func NewErrForbidden(clientID string, action Action, reason string) ErrForbidden {
+	return ErrForbidden{ClientID: clientID, Action: action, Reason: reason}
+}
+
+func (e ErrForbidden) Error() string {
+	return fmt.Sprintf("client %s is not allowed to %s: %s", e.ClientID, e.Action, e.Reason)
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type tokenContextKey struct{}
+
+const bearerPrefix = "Bearer "
+
+// Middleware passes the bearer token of requests, which is read from the Authorization header, to the handlers
+// through the request context. Handlers authorize the requests, as the identity of the client depends on the
+// request's payload.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if token := BearerToken(req); token != "" {
+			req = req.WithContext(context.WithValue(req.Context(), tokenContextKey{}, token))
+		}
+		next.ServeHTTP(res, req)
+	})
+}
+
+// BearerToken returns the bearer token of the Authorization header of a request, or an empty string if the
+// request has none. Raw handlers, which are not wrapped with Middleware, read the token with it.
+func BearerToken(req *http.Request) string {
+	header := req.Header.Get("Authorization")
+	if len(header) > len(bearerPrefix) && strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return strings.TrimSpace(header[len(bearerPrefix):])
+	}
+	return ""
+}
+
+// TokenFromContext returns the bearer token of the request, or an empty string if the request has none
+func TokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(tokenContextKey{}).(string)
+	return token
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/filecoin-project/bacalhau/pkg/compute/capacity"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"sigs.k8s.io/yaml"
+)
+
+// anyClient matches all clients in a policy file rule
+const anyClient = "*"
+
+// PolicyRule is a rule of a policy file, which grants clients permission to use the API. Empty lists of engines,
+// publishers and networking modes allow all of them, and unset resources are not limited.
+//
+// Example policy file:
+//
+//	rules:
+//	  - client_ids: ["4a1b..."]
+//	    engines: [docker, wasm]
+//	    publishers: [ipfs]
+//	    networking: [none, http]
+//	    max_resources:
+//	      cpu: "2"
+//	      memory: 4gb
+//	  - client_ids: ["*"]
+//	    engines: [wasm]
+//	    networking: [none]
+type PolicyRule struct {
+	ClientIDs    []string                  `json:"client_ids,omitempty"`
+	PublicKeys   []string                  `json:"public_keys,omitempty"`
+	Engines      []string                  `json:"engines,omitempty"`
+	Publishers   []string                  `json:"publishers,omitempty"`
+	Networking   []string                  `json:"networking,omitempty"`
+	MaxResources model.ResourceUsageConfig `json:"max_resources,omitempty"`
+}
+
+type PolicyFileContent struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+type policyRule struct {
+	engines      map[model.Engine]bool
+	publishers   map[model.Publisher]bool
+	networking   map[model.Network]bool
+	maxResources model.ResourceUsageData
+}
+
+// FilePolicy restricts what clients can do according to a policy file. Clients that are not matched by any rule
+// are not allowed to use the API. Each client is matched by the first rule that names it, or by the first
+// wildcard rule if none does.
+type FilePolicy struct {
+	rules    map[string]*policyRule
+	wildcard *policyRule
+}
+
+func NewFilePolicyFromFile(path string) (*FilePolicy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	var policyFile PolicyFileContent
+	if err = yaml.UnmarshalStrict(content, &policyFile); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", path, err)
+	}
+	policy, err := NewFilePolicy(policyFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+func NewFilePolicy(content PolicyFileContent) (*FilePolicy, error) {
+	policy := &FilePolicy{rules: make(map[string]*policyRule)}
+	for i, rule := range content.Rules {
+		parsed, err := parsePolicyRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		if len(rule.ClientIDs) == 0 && len(rule.PublicKeys) == 0 {
+			return nil, fmt.Errorf("rule %d: no client_ids or public_keys", i)
+		}
+		for _, client := range append(append([]string{}, rule.ClientIDs...), rule.PublicKeys...) {
+			if strings.TrimSpace(client) == anyClient {
+				if policy.wildcard == nil {
+					policy.wildcard = parsed
+				}
+				continue
+			}
+			clientID, err := parseClient(client)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i, err)
+			}
+			if _, ok := policy.rules[clientID]; !ok {
+				policy.rules[clientID] = parsed
+			}
+		}
+	}
+	return policy, nil
+}
+
+func parsePolicyRule(rule PolicyRule) (*policyRule, error) {
+	parsed := &policyRule{
+		engines:      make(map[model.Engine]bool, len(rule.Engines)),
+		publishers:   make(map[model.Publisher]bool, len(rule.Publishers)),
+		networking:   make(map[model.Network]bool, len(rule.Networking)),
+		maxResources: capacity.ParseResourceUsageConfig(rule.MaxResources),
+	}
+	for _, value := range rule.Engines {
+		engine, err := model.ParseEngine(value)
+		if err != nil {
+			return nil, err
+		}
+		parsed.engines[engine] = true
+	}
+	for _, value := range rule.Publishers {
+		publisher, err := model.ParsePublisher(value)
+		if err != nil {
+			return nil, err
+		}
+		parsed.publishers[publisher] = true
+	}
+	for _, value := range rule.Networking {
+		network, err := model.ParseNetwork(value)
+		if err != nil {
+			return nil, err
+		}
+		parsed.networking[network] = true
+	}
+	return parsed, nil
+}
+
+func (p *FilePolicy) Authorize(_ context.Context, request Request) error {
+	if request.ClientID == "" {
+		return NewErrUnauthenticated("client ID is required")
+	}
+	rule, ok := p.rules[request.ClientID]
+	if !ok {
+		rule = p.wildcard
+	}
+	if rule == nil {
+		return NewErrForbidden(request.ClientID, request.Action, "client is not allowed by the policy file")
+	}
+	if request.Action != ActionSubmit || request.Spec == nil {
+		return nil
+	}
+	return rule.checkSpec(request.ClientID, *request.Spec)
+}
+
+func (r *policyRule) checkSpec(clientID string, spec model.Spec) error {
+	forbidden := func(format string, args ...interface{}) error {
+		return NewErrForbidden(clientID, ActionSubmit, fmt.Sprintf(format, args...))
+	}
+	if len(r.engines) > 0 && !r.engines[spec.Engine] {
+		return forbidden("engine %s is not permitted", spec.Engine)
+	}
+	if len(r.publishers) > 0 && !r.publishers[spec.Publisher] {
+		return forbidden("publisher %s is not permitted", spec.Publisher)
+	}
+	if len(r.networking) > 0 && !r.networking[spec.Network.Type] {
+		return forbidden("networking mode %s is not permitted", spec.Network.Type)
+	}
+
+	// only check the resources that the rule limits
+	resources := capacity.ParseResourceUsageConfig(spec.Resources)
+	if r.maxResources.CPU > 0 && resources.CPU > r.maxResources.CPU {
+		return forbidden("requested CPU %f exceeds the limit of %f", resources.CPU, r.maxResources.CPU)
+	}
+	if r.maxResources.Memory > 0 && resources.Memory > r.maxResources.Memory {
+		return forbidden("requested memory %d exceeds the limit of %d", resources.Memory, r.maxResources.Memory)
+	}
+	if r.maxResources.Disk > 0 && resources.Disk > r.maxResources.Disk {
+		return forbidden("requested disk %d exceeds the limit of %d", resources.Disk, r.maxResources.Disk)
+	}
+	if r.maxResources.GPU > 0 && resources.GPU > r.maxResources.GPU {
+		return forbidden("requested GPU %d exceeds the limit of %d", resources.GPU, r.maxResources.GPU)
+	}
+	return nil
+}
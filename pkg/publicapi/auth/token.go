@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// TokenPolicy requires requests to carry one of a set of static bearer tokens. A token can be bound to a client,
+// in which case it can only be used to make requests on behalf of that client.
+type TokenPolicy struct {
+	// clientIDs maps the hashes of the tokens to the client they are bound to, or to an empty string for tokens
+	// that are not bound to a client. Only the hashes are kept in memory.
+	clientIDs map[[sha256.Size]byte]string
+}
+
+func NewTokenPolicy() *TokenPolicy {
+	return &TokenPolicy{clientIDs: make(map[[sha256.Size]byte]string)}
+}
+
+// NewTokenPolicyFromFile loads tokens from a file with one token per line, optionally followed by the client ID or
+// the base64-encoded public key of the client it is bound to. Empty lines and lines starting with # are ignored.
+func NewTokenPolicyFromFile(path string) (*TokenPolicy, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tokens file: %w", err)
+	}
+	defer file.Close()
+
+	policy, err := ParseTokens(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tokens file %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// ParseTokens loads tokens in the format of NewTokenPolicyFromFile
+func ParseTokens(reader io.Reader) (*TokenPolicy, error) {
+	policy := NewTokenPolicy()
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected a token optionally followed by a client", lineNumber)
+		}
+		clientID := ""
+		if len(fields) == 2 {
+			var err error
+			clientID, err = parseClient(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+		}
+		policy.AddToken(fields[0], clientID)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// AddToken allows requests with the given token. If clientID is not empty, the token is only valid for requests
+// made on behalf of that client.
+func (p *TokenPolicy) AddToken(token string, clientID string) {
+	p.clientIDs[sha256.Sum256([]byte(token))] = clientID
+}
+
+func (p *TokenPolicy) Authorize(_ context.Context, request Request) error {
+	if request.Token == "" {
+		return NewErrUnauthenticated("bearer token is required")
+	}
+	clientID, ok := p.clientIDs[sha256.Sum256([]byte(request.Token))]
+	if !ok {
+		return NewErrUnauthenticated("invalid bearer token")
+	}
+	if clientID != "" && clientID != request.ClientID {
+		return NewErrForbidden(request.ClientID, request.Action, "bearer token belongs to a different client")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+)
+
+// Action is an API operation that is subject to authorization
+type Action string
+
+const (
+	ActionSubmit     Action = "submit"
+	ActionCancel     Action = "cancel"
+	ActionList       Action = "list"
+	ActionResults    Action = "results"
+	ActionStates     Action = "states"
+	ActionEvents     Action = "events"
+	ActionLogs       Action = "logs"
+	ActionUsage      Action = "usage"
+	ActionReputation Action = "reputation"
+)
+
+// Request is an API request to authorize.
+type Request struct {
+	Action Action
+	// ClientID is the ID of the client making the request. It is verified against the request's signature when
+	// Signed is set, and is only claimed by the client otherwise.
+	ClientID string
+	Signed   bool
+	// Token is the bearer token sent with the request, if any
+	Token string
+	// Spec is the spec of the job to submit, for ActionSubmit requests
+	Spec *model.Spec
+}
+
+// Policy decides which clients are allowed to make API requests.
+type Policy interface {
+	// Authorize returns nil if the request is allowed, an ErrUnauthenticated error if the client couldn't be
+	// identified, or an ErrForbidden error if the client is not allowed to make the request.
+	Authorize(ctx context.Context, request Request) error
+}
+
+// Chain allows requests that are allowed by all its policies.
+type Chain struct {
+	policies []Policy
+}
+
+func NewChain(policies ...Policy) *Chain {
+	return &Chain{policies: policies}
+}
+
+// Add policies to the chain
+func (c *Chain) Add(policies ...Policy) {
+	c.policies = append(c.policies, policies...)
+}
+
+func (c *Chain) Authorize(ctx context.Context, request Request) error {
+	for _, policy := range c.policies {
+		if err := policy.Authorize(ctx, request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compile-time check that policies implement the Policy interface
+var (
+	_ Policy = (*Chain)(nil)
+	_ Policy = (*AllowlistPolicy)(nil)
+	_ Policy = (*TokenPolicy)(nil)
+	_ Policy = (*FilePolicy)(nil)
+)
@@ -14,6 +14,7 @@ import (
 	"github.com/filecoin-project/bacalhau/docs"
 	"github.com/filecoin-project/bacalhau/pkg/logger"
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
 	"github.com/filecoin-project/bacalhau/pkg/publicapi/handlerwrapper"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 	"github.com/filecoin-project/bacalhau/pkg/version"
@@ -53,6 +54,10 @@ type APIServerConfig struct {
 
 	// MaxBytesToReadInBody is used by safeHandlerFuncWrapper as the max size of body
 	MaxBytesToReadInBody datasize.ByteSize
+
+	// AuthPolicy decides which clients are allowed to make requests to handlers that authorize them.
+	// All requests are allowed if it is nil.
+	AuthPolicy auth.Policy
 }
 
 type APIServerParams struct {
@@ -199,6 +204,18 @@ func (apiServer *APIServer) RegisterHandlers(config ...HandlerConfig) error {
 	return nil
 }
 
+// Authorize checks whether the request is allowed by the server's auth policy. The bearer token of the
+// request is read from the context if the request doesn't have one.
+func (apiServer *APIServer) Authorize(ctx context.Context, request auth.Request) error {
+	if apiServer.config.AuthPolicy == nil {
+		return nil
+	}
+	if request.Token == "" {
+		request.Token = auth.TokenFromContext(ctx)
+	}
+	return apiServer.config.AuthPolicy.Authorize(ctx, request)
+}
+
 func (apiServer *APIServer) registerHandler(config HandlerConfig) error {
 	if _, ok := apiServer.handlers[config.URI]; ok {
 		return fmt.Errorf("handler already registered for %s", config.URI)
@@ -209,8 +226,11 @@ func (apiServer *APIServer) registerHandler(config HandlerConfig) error {
 
 	handler := config.Handler
 	if !config.Raw {
+		// auth handler, passing bearer tokens to the handlers
+		handler = auth.Middleware(handler)
+
 		// otel handler
-		handler = otelhttp.NewHandler(config.Handler, config.URI,
+		handler = otelhttp.NewHandler(handler, config.URI,
 			otelhttp.WithPublicEndpoint(),
 			otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
 				return fmt.Sprintf("%s %s", r.Method, operation)
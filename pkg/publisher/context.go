@@ -0,0 +1,18 @@
+package publisher
+
+import "context"
+
+type executionIDKey struct{}
+
+// ContextWithExecutionID returns a context that carries the ID of the execution whose result is published,
+// for publishers that name results after the execution.
+func ContextWithExecutionID(ctx context.Context, executionID string) context.Context {
+	return context.WithValue(ctx, executionIDKey{}, executionID)
+}
+
+// ExecutionIDFromContext returns the ID of the execution whose result is published, or an empty string
+// if the context does not carry one.
+func ExecutionIDFromContext(ctx context.Context) string {
+	executionID, _ := ctx.Value(executionIDKey{}).(string)
+	return executionID
+}
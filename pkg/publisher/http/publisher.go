@@ -0,0 +1,163 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"text/template"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/filecoin-project/bacalhau/pkg/job"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publisher"
+	"github.com/filecoin-project/bacalhau/pkg/util/closer"
+	"github.com/filecoin-project/bacalhau/pkg/util/targzip"
+	"github.com/rs/zerolog/log"
+)
+
+// maxFileSize lifts the limit targzip applies to each file, as results can be of any size
+const maxFileSize = datasize.ByteSize(math.MaxInt64)
+
+type PublisherConfig struct {
+	// URL is a Go template of the URL the tar.gz archive of the result of a shard is uploaded to with a PUT
+	// request, which can reference {{.JobID}}, {{.ShardIndex}}, {{.ExecutionID}} and {{.NodeID}}. Credentials
+	// can be passed in the user info of the URL, and are removed from the URL of the published result, so clients
+	// pass their own credentials to download it.
+	URL string
+}
+
+// urlData is the data the URL template is executed with
+type urlData struct {
+	JobID       string
+	ShardIndex  int
+	ExecutionID string
+	NodeID      string
+}
+
+// Publisher uploads the result of each shard as a tar.gz archive to a HTTP server, such as an artifact server.
+type Publisher struct {
+	url    *template.Template
+	client *http.Client
+}
+
+func NewPublisher(config PublisherConfig) (*Publisher, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("HTTP publisher requires a URL")
+	}
+	urlTemplate, err := template.New("url").Option("missingkey=error").Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTTP publisher URL %q: %w", config.URL, err)
+	}
+	return &Publisher{
+		url:    urlTemplate,
+		client: http.DefaultClient,
+	}, nil
+}
+
+// IsInstalled implements publisher.Publisher
+func (p *Publisher) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+// PublishShardResult implements publisher.Publisher
+func (p *Publisher) PublishShardResult(
+	ctx context.Context,
+	shard model.JobShard,
+	hostID string,
+	shardResultPath string,
+) (model.StorageSpec, error) {
+	resultURL, err := p.resultURL(ctx, shard, hostID)
+	if err != nil {
+		return model.StorageSpec{}, err
+	}
+
+	archive, err := os.CreateTemp("", "bacalhau-result-*.tar.gz")
+	if err != nil {
+		return model.StorageSpec{}, err
+	}
+	defer os.Remove(archive.Name()) //nolint:errcheck
+	defer closer.CloseWithLogOnError("archive", archive)
+
+	if err = targzip.CompressWithMaxSize(ctx, shardResultPath, archive, maxFileSize); err != nil {
+		return model.StorageSpec{}, fmt.Errorf("failed to archive result: %w", err)
+	}
+	if err = p.upload(ctx, resultURL, archive); err != nil {
+		return model.StorageSpec{}, err
+	}
+	log.Ctx(ctx).Debug().Str("url", redact(resultURL)).Msg("Published result archive")
+
+	spec := job.GetPublishedStorageSpec(shard, model.StorageSourceURLDownload, hostID, "")
+	spec.URL = withoutUserInfo(resultURL)
+	return spec, nil
+}
+
+func (p *Publisher) upload(ctx context.Context, resultURL string, archive *os.File) error {
+	info, err := archive.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err = archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, resultURL, archive)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/gzip")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload result to %s: %w", redact(resultURL), err)
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "response", res.Body)
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("failed to upload result to %s: %s", redact(resultURL), res.Status)
+	}
+	return nil
+}
+
+func (p *Publisher) resultURL(ctx context.Context, shard model.JobShard, hostID string) (string, error) {
+	var buf bytes.Buffer
+	err := p.url.Execute(&buf, urlData{
+		JobID:       shard.Job.Metadata.ID,
+		ShardIndex:  shard.Index,
+		ExecutionID: publisher.ExecutionIDFromContext(ctx),
+		NodeID:      hostID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render HTTP publisher URL: %w", err)
+	}
+	u, err := url.Parse(buf.String())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("HTTP publisher URL %q is not a valid HTTP URL", redact(buf.String()))
+	}
+	return u.String(), nil
+}
+
+// withoutUserInfo removes the credentials of a URL so that it can be published.
+func withoutUserInfo(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	u.User = nil
+	return u.String()
+}
+
+// redact removes the password of a URL so that it can be logged.
+func redact(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Redacted()
+}
+
+var _ publisher.Publisher = (*Publisher)(nil)
@@ -0,0 +1,97 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/filecoin-project/bacalhau/pkg/job"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publisher"
+	"github.com/filecoin-project/bacalhau/pkg/storage/util"
+	"github.com/rs/zerolog/log"
+)
+
+type PublisherConfig struct {
+	// Root is the directory results are written to, such as a shared NFS mount
+	Root string
+}
+
+// Publisher writes results to a local directory, where the result of each shard is written to
+// <root>/<job ID>/<shard index>/<node ID>.
+type Publisher struct {
+	root string
+}
+
+func NewPublisher(config PublisherConfig) (*Publisher, error) {
+	if config.Root == "" {
+		return nil, fmt.Errorf("local publisher requires a root directory")
+	}
+	root, err := filepath.Abs(config.Root)
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Str("root", root).Msg("Local publisher initialized")
+	return &Publisher{
+		root: root,
+	}, nil
+}
+
+// IsInstalled implements publisher.Publisher
+func (p *Publisher) IsInstalled(context.Context) (bool, error) {
+	info, err := os.Stat(p.root)
+	if err != nil {
+		return false, err
+	}
+	return info.IsDir(), nil
+}
+
+// PublishShardResult implements publisher.Publisher
+func (p *Publisher) PublishShardResult(
+	ctx context.Context,
+	shard model.JobShard,
+	hostID string,
+	shardResultPath string,
+) (model.StorageSpec, error) {
+	resultPath := ResultPath(shard, hostID)
+	target := filepath.Join(p.root, filepath.FromSlash(resultPath))
+	if err := os.MkdirAll(filepath.Dir(target), model.DownloadFolderPerm); err != nil {
+		return model.StorageSpec{}, err
+	}
+
+	// copy the result next to its target first, so that readers never see a partially written result
+	tempDir, err := os.MkdirTemp(filepath.Dir(target), ".publishing-*")
+	if err != nil {
+		return model.StorageSpec{}, err
+	}
+	defer os.RemoveAll(tempDir) //nolint:errcheck
+
+	err = util.CopyDir(shardResultPath, tempDir)
+	if err == nil {
+		err = os.Chmod(tempDir, model.DownloadFolderPerm)
+	}
+	if err == nil {
+		err = os.RemoveAll(target)
+	}
+	if err == nil {
+		err = os.Rename(tempDir, target)
+	}
+	if err != nil {
+		return model.StorageSpec{}, fmt.Errorf("failed to publish result to %s: %w", target, err)
+	}
+	log.Ctx(ctx).Debug().Str("path", target).Msg("Published result to local directory")
+
+	spec := job.GetPublishedStorageSpec(shard, model.StorageSourceLocalDirectory, hostID, "")
+	spec.SourcePath = resultPath
+	return spec, nil
+}
+
+// ResultPath returns the slash separated path of the result of a shard relative to the root directory.
+func ResultPath(shard model.JobShard, hostID string) string {
+	return path.Join(shard.Job.Metadata.ID, strconv.Itoa(shard.Index), hostID)
+}
+
+var _ publisher.Publisher = (*Publisher)(nil)
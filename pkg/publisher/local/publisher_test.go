@@ -0,0 +1,75 @@
+//go:build unit || !integration
+
+package local
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestResult(t *testing.T, stdout string) string {
+	resultPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, model.DownloadFilenameStdout), []byte(stdout), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(resultPath, "outputs", "nested"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, "outputs", "nested", "data.csv"), []byte("1,2"), 0644))
+	return resultPath
+}
+
+var testShard = model.JobShard{
+	Job:   &model.Job{Metadata: model.Metadata{ID: "job-id"}},
+	Index: 2,
+}
+
+func TestNewPublisherValidatesConfig(t *testing.T) {
+	_, err := NewPublisher(PublisherConfig{})
+	require.Error(t, err)
+}
+
+func TestIsInstalled(t *testing.T) {
+	p, err := NewPublisher(PublisherConfig{Root: t.TempDir()})
+	require.NoError(t, err)
+	installed, err := p.IsInstalled(context.Background())
+	require.NoError(t, err)
+	require.True(t, installed)
+
+	p, err = NewPublisher(PublisherConfig{Root: filepath.Join(t.TempDir(), "missing")})
+	require.NoError(t, err)
+	installed, err = p.IsInstalled(context.Background())
+	require.Error(t, err)
+	require.False(t, installed)
+}
+
+func TestPublishShardResult(t *testing.T) {
+	root := t.TempDir()
+	p, err := NewPublisher(PublisherConfig{Root: root})
+	require.NoError(t, err)
+
+	spec, err := p.PublishShardResult(context.Background(), testShard, "node-id", newTestResult(t, "hello"))
+	require.NoError(t, err)
+	require.Equal(t, model.StorageSourceLocalDirectory, spec.StorageSource)
+	require.Equal(t, "job-id/2/node-id", spec.SourcePath)
+	requireFile(t, filepath.Join(root, "job-id", "2", "node-id", "stdout"), "hello")
+	requireFile(t, filepath.Join(root, "job-id", "2", "node-id", "outputs", "nested", "data.csv"), "1,2")
+
+	// publishing the shard again replaces the previous result, and leaves no temporary directories behind
+	resultPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, model.DownloadFilenameStdout), []byte("again"), 0644))
+	_, err = p.PublishShardResult(context.Background(), testShard, "node-id", resultPath)
+	require.NoError(t, err)
+	requireFile(t, filepath.Join(root, "job-id", "2", "node-id", "stdout"), "again")
+	require.NoDirExists(t, filepath.Join(root, "job-id", "2", "node-id", "outputs"))
+	entries, err := os.ReadDir(filepath.Join(root, "job-id", "2"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func requireFile(t *testing.T, path, expected string) {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(content))
+}
@@ -0,0 +1,158 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/filecoin-project/bacalhau/pkg/job"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publisher"
+	s3helper "github.com/filecoin-project/bacalhau/pkg/s3"
+	"github.com/filecoin-project/bacalhau/pkg/util/closer"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultPrefix is the template of the prefix results are uploaded under when none is configured
+const DefaultPrefix = "{{.JobID}}/{{.ShardIndex}}/{{.ExecutionID}}"
+
+type PublisherConfig struct {
+	// Client configures how to access the S3-compatible service
+	Client s3helper.Config
+	// Bucket the results are uploaded to
+	Bucket string
+	// Prefix is a Go template of the prefix the results of a shard are uploaded under, which can reference
+	// {{.JobID}}, {{.ShardIndex}}, {{.ExecutionID}} and {{.NodeID}}
+	Prefix string
+}
+
+// prefixData is the data the prefix template is executed with
+type prefixData struct {
+	JobID       string
+	ShardIndex  int
+	ExecutionID string
+	NodeID      string
+}
+
+type Publisher struct {
+	client *awss3.Client
+	bucket string
+	prefix *template.Template
+}
+
+func NewPublisher(ctx context.Context, config PublisherConfig) (*Publisher, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("S3 publisher requires a bucket")
+	}
+	prefix := config.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	prefixTemplate, err := template.New("prefix").Option("missingkey=error").Parse(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 publisher prefix %q: %w", prefix, err)
+	}
+
+	client, err := s3helper.NewClient(ctx, config.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Debug().Str("bucket", config.Bucket).Str("prefix", prefix).Msg("S3 publisher initialized")
+	return &Publisher{
+		client: client,
+		bucket: config.Bucket,
+		prefix: prefixTemplate,
+	}, nil
+}
+
+// IsInstalled implements publisher.Publisher
+func (p *Publisher) IsInstalled(ctx context.Context) (bool, error) {
+	_, err := p.client.HeadBucket(ctx, &awss3.HeadBucketInput{
+		Bucket: aws.String(p.bucket),
+	})
+	return err == nil, err
+}
+
+// PublishShardResult implements publisher.Publisher
+func (p *Publisher) PublishShardResult(
+	ctx context.Context,
+	shard model.JobShard,
+	hostID string,
+	shardResultPath string,
+) (model.StorageSpec, error) {
+	prefix, err := p.resultPrefix(ctx, shard, hostID)
+	if err != nil {
+		return model.StorageSpec{}, err
+	}
+
+	err = filepath.WalkDir(shardResultPath, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		relativePath, err := filepath.Rel(shardResultPath, filePath)
+		if err != nil {
+			return err
+		}
+		return p.uploadFile(ctx, filePath, prefix+filepath.ToSlash(relativePath))
+	})
+	if err != nil {
+		return model.StorageSpec{}, err
+	}
+
+	spec := job.GetPublishedStorageSpec(shard, model.StorageSourceS3, hostID, "")
+	spec.S3 = &model.S3StorageSpec{
+		Bucket: p.bucket,
+		Prefix: prefix,
+	}
+	return spec, nil
+}
+
+// resultPrefix returns the prefix the result of the shard is uploaded under, which always ends with a slash
+// so that the results of different shards don't overlap.
+func (p *Publisher) resultPrefix(ctx context.Context, shard model.JobShard, hostID string) (string, error) {
+	var buf bytes.Buffer
+	err := p.prefix.Execute(&buf, prefixData{
+		JobID:       shard.Job.Metadata.ID,
+		ShardIndex:  shard.Index,
+		ExecutionID: publisher.ExecutionIDFromContext(ctx),
+		NodeID:      hostID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render S3 publisher prefix: %w", err)
+	}
+	prefix := strings.TrimPrefix(path.Clean("/"+buf.String()), "/")
+	if prefix == "" {
+		return "", fmt.Errorf("S3 publisher prefix %q is empty for shard %s", p.prefix.Root.String(), shard.ID())
+	}
+	return prefix + "/", nil
+}
+
+func (p *Publisher) uploadFile(ctx context.Context, filePath, key string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer closer.CloseWithLogOnError("file", f)
+
+	_, err = p.client.PutObject(ctx, &awss3.PutObjectInput{
+		Bucket: aws.String(p.bucket),
+		Key:    aws.String(key),
+		Body:   f,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s to bucket %s: %w", key, p.bucket, err)
+	}
+	log.Ctx(ctx).Debug().Str("bucket", p.bucket).Str("key", key).Msg("Uploaded result file")
+	return nil
+}
+
+var _ publisher.Publisher = (*Publisher)(nil)
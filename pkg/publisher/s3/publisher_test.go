@@ -0,0 +1,118 @@
+//go:build unit || !integration
+
+package s3
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publisher"
+	"github.com/filecoin-project/bacalhau/pkg/publisher/combo"
+	"github.com/filecoin-project/bacalhau/pkg/s3/s3test"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPublisher(t *testing.T, server *s3test.Server, bucket, prefix string) *Publisher {
+	p, err := NewPublisher(context.Background(), PublisherConfig{
+		Client: server.Config(),
+		Bucket: bucket,
+		Prefix: prefix,
+	})
+	require.NoError(t, err)
+	return p
+}
+
+func newTestResult(t *testing.T) string {
+	resultPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, model.DownloadFilenameStdout), []byte("hello"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(resultPath, "outputs", "nested"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(resultPath, "outputs", "nested", "data.csv"), []byte("1,2"), 0644))
+	return resultPath
+}
+
+var testShard = model.JobShard{
+	Job:   &model.Job{Metadata: model.Metadata{ID: "job-id"}},
+	Index: 2,
+}
+
+func TestNewPublisherValidatesConfig(t *testing.T) {
+	server := s3test.NewServer(t, "bucket", nil)
+	_, err := NewPublisher(context.Background(), PublisherConfig{Client: server.Config()})
+	require.Error(t, err)
+	_, err = NewPublisher(context.Background(), PublisherConfig{Client: server.Config(), Bucket: "bucket", Prefix: "{{.JobID"})
+	require.Error(t, err)
+}
+
+func TestIsInstalled(t *testing.T) {
+	server := s3test.NewServer(t, "bucket", nil)
+
+	installed, err := newTestPublisher(t, server, "bucket", "").IsInstalled(context.Background())
+	require.NoError(t, err)
+	require.True(t, installed)
+
+	installed, err = newTestPublisher(t, server, "missing", "").IsInstalled(context.Background())
+	require.Error(t, err)
+	require.False(t, installed)
+}
+
+func TestPublishShardResult(t *testing.T) {
+	server := s3test.NewServer(t, "bucket", nil)
+	p := newTestPublisher(t, server, "bucket", "")
+
+	ctx := publisher.ContextWithExecutionID(context.Background(), "execution-id")
+	spec, err := p.PublishShardResult(ctx, testShard, "node-id", newTestResult(t))
+	require.NoError(t, err)
+
+	require.Equal(t, model.StorageSourceS3, spec.StorageSource)
+	require.Equal(t, &model.S3StorageSpec{Bucket: "bucket", Prefix: "job-id/2/execution-id/"}, spec.S3)
+	require.Equal(t, map[string]string{
+		"job-id/2/execution-id/stdout":                  "hello",
+		"job-id/2/execution-id/outputs/nested/data.csv": "1,2",
+	}, server.Objects())
+}
+
+func TestPublishShardResultPrefixTemplate(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		prefix   string
+		expected string
+		fails    bool
+	}{
+		{name: "node", prefix: "results/{{.JobID}}-{{.ShardIndex}}/{{.NodeID}}", expected: "results/job-id-2/node-id/"},
+		{name: "cleaned", prefix: "/results//{{.JobID}}/", expected: "results/job-id/"},
+		{name: "empty", prefix: "{{.ExecutionID}}", fails: true},
+		{name: "unknown field", prefix: "{{.Unknown}}", fails: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := s3test.NewServer(t, "bucket", nil)
+			p := newTestPublisher(t, server, "bucket", tc.prefix)
+			spec, err := p.PublishShardResult(context.Background(), testShard, "node-id", newTestResult(t))
+			if tc.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, spec.S3.Prefix)
+			require.Contains(t, server.Objects(), tc.expected+"stdout")
+		})
+	}
+}
+
+func TestComposesWithFallbackPublisher(t *testing.T) {
+	server := s3test.NewServer(t, "bucket", nil)
+	fallback := combo.NewFallbackPublisher(
+		newTestPublisher(t, server, "missing", ""),
+		newTestPublisher(t, server, "bucket", ""),
+	)
+
+	installed, err := fallback.IsInstalled(context.Background())
+	require.NoError(t, err)
+	require.True(t, installed)
+
+	spec, err := fallback.PublishShardResult(context.Background(), testShard, "node-id", newTestResult(t))
+	require.NoError(t, err)
+	require.Equal(t, "bucket", spec.S3.Bucket)
+}
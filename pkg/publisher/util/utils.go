@@ -10,8 +10,11 @@ import (
 	"github.com/filecoin-project/bacalhau/pkg/publisher/combo"
 	"github.com/filecoin-project/bacalhau/pkg/publisher/estuary"
 	filecoinlotus "github.com/filecoin-project/bacalhau/pkg/publisher/filecoin_lotus"
+	"github.com/filecoin-project/bacalhau/pkg/publisher/http"
 	"github.com/filecoin-project/bacalhau/pkg/publisher/ipfs"
+	"github.com/filecoin-project/bacalhau/pkg/publisher/local"
 	"github.com/filecoin-project/bacalhau/pkg/publisher/noop"
+	"github.com/filecoin-project/bacalhau/pkg/publisher/s3"
 	"github.com/filecoin-project/bacalhau/pkg/publisher/tracing"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 )
@@ -22,6 +25,9 @@ func NewIPFSPublishers(
 	cl ipfsClient.Client,
 	estuaryAPIKey string,
 	lotusConfig *filecoinlotus.PublisherConfig,
+	s3Config *s3.PublisherConfig,
+	localConfig *local.PublisherConfig,
+	httpConfig *http.PublisherConfig,
 ) (publisher.PublisherProvider, error) {
 	defaultPriorityPublisherTimeout := time.Second * 2
 	noopPublisher := noop.NewNoopPublisher()
@@ -52,12 +58,39 @@ func NewIPFSPublishers(
 		}
 	}
 
-	return model.NewMappedProvider(map[model.Publisher]publisher.Publisher{
+	publishers := map[model.Publisher]publisher.Publisher{
 		model.PublisherNoop:     tracing.Wrap(noopPublisher),
 		model.PublisherIpfs:     tracing.Wrap(ipfsPublisher),
 		model.PublisherEstuary:  tracing.Wrap(estuaryPublisher),
 		model.PublisherFilecoin: combo.NewPiggybackedPublisher(tracing.Wrap(ipfsPublisher), tracing.Wrap(lotus)),
-	}), nil
+	}
+
+	// only nodes configured with a bucket publish to S3, so that other nodes don't bid on such jobs
+	if s3Config != nil {
+		s3Publisher, err := s3.NewPublisher(ctx, *s3Config)
+		if err != nil {
+			return nil, err
+		}
+		publishers[model.PublisherS3] = tracing.Wrap(s3Publisher)
+	}
+
+	if localConfig != nil {
+		localPublisher, err := local.NewPublisher(*localConfig)
+		if err != nil {
+			return nil, err
+		}
+		publishers[model.PublisherLocal] = tracing.Wrap(localPublisher)
+	}
+
+	if httpConfig != nil {
+		httpPublisher, err := http.NewPublisher(*httpConfig)
+		if err != nil {
+			return nil, err
+		}
+		publishers[model.PublisherHTTP] = tracing.Wrap(httpPublisher)
+	}
+
+	return model.NewMappedProvider(publishers), nil
 }
 
 func NewNoopPublishers(
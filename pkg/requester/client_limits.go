@@ -0,0 +1,165 @@
+package requester
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+)
+
+// submissionsWindow is the window over which submissions are counted against ClientLimits.SubmissionsPerMinute
+const submissionsWindow = time.Minute
+
+type ClientLimiterParams struct {
+	JobStore jobstore.Store
+	// DefaultLimits are the limits of each client
+	DefaultLimits model.ClientLimits
+	// ClientLimits replace the default limits of specific clients, by client ID
+	ClientLimits map[string]model.ClientLimits
+}
+
+// ClientLimiter limits the rate at which each client submits jobs, and the number of and the resources requested
+// by the in progress jobs of each client, using the counts and resource totals kept in the job store. The resources
+// of a job are those of all its executions, as returned by jobstore.JobResources. Jobs that would exceed a limit of
+// their client are rejected.
+type ClientLimiter struct {
+	jobStore      jobstore.Store
+	defaultLimits model.ClientLimits
+	clientLimits  map[string]model.ClientLimits
+	// inProgressStates are the job states of in progress jobs
+	inProgressStates []model.JobStateType
+
+	mu sync.Mutex
+	// locks serialize the submissions of each client, and are removed once no submission holds or waits for them
+	locks map[string]*clientLock
+}
+
+type clientLock struct {
+	sync.Mutex
+	holders int
+}
+
+func NewClientLimiter(params ClientLimiterParams) *ClientLimiter {
+	var inProgressStates []model.JobStateType
+	for state := model.JobStateNew; state <= model.JobStateCompleted; state++ {
+		if !state.IsTerminal() {
+			inProgressStates = append(inProgressStates, state)
+		}
+	}
+	return &ClientLimiter{
+		jobStore:         params.JobStore,
+		defaultLimits:    params.DefaultLimits,
+		clientLimits:     params.ClientLimits,
+		inProgressStates: inProgressStates,
+		locks:            make(map[string]*clientLock),
+	}
+}
+
+// Lock locks the submissions of a client with limits until the returned function is called, so that the limits
+// can be checked and the job created without concurrent submissions of the same client exceeding its limits.
+func (l *ClientLimiter) Lock(clientID string) (unlock func()) {
+	if l.Limits(clientID) == (model.ClientLimits{}) {
+		return func() {}
+	}
+	l.mu.Lock()
+	lock, ok := l.locks[clientID]
+	if !ok {
+		lock = &clientLock{}
+		l.locks[clientID] = lock
+	}
+	lock.holders++
+	l.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		lock.holders--
+		if lock.holders == 0 {
+			delete(l.locks, clientID)
+		}
+	}
+}
+
+// Limits returns the limits of a client
+func (l *ClientLimiter) Limits(clientID string) model.ClientLimits {
+	if limits, ok := l.clientLimits[clientID]; ok {
+		return limits
+	}
+	return l.defaultLimits
+}
+
+// Usage returns the usage of a client counted against its limits
+func (l *ClientLimiter) Usage(ctx context.Context, clientID string) (model.ClientUsage, error) {
+	submissions, err := l.jobStore.GetJobsCount(ctx, jobstore.JobQuery{
+		ClientID:     clientID,
+		CreatedAfter: time.Now().Add(-submissionsWindow),
+	})
+	if err != nil {
+		return model.ClientUsage{}, err
+	}
+	inProgressJobs, err := l.jobStore.GetJobsCount(ctx, jobstore.JobQuery{
+		ClientID: clientID,
+		States:   l.inProgressStates,
+	})
+	if err != nil {
+		return model.ClientUsage{}, err
+	}
+	resources, err := l.jobStore.GetResourceUsage(ctx, clientID)
+	if err != nil {
+		return model.ClientUsage{}, err
+	}
+	return model.ClientUsage{
+		ClientID:              clientID,
+		SubmissionsLastMinute: submissions,
+		InProgressJobs:        inProgressJobs,
+		Resources:             resources,
+		Limits:                l.Limits(clientID),
+	}, nil
+}
+
+// Check returns a *bacerrors.ClientLimitExceeded error if submitting a job with the given spec would exceed a
+// limit of the client.
+func (l *ClientLimiter) Check(ctx context.Context, clientID string, spec model.Spec) error {
+	limits := l.Limits(clientID)
+	if limits == (model.ClientLimits{}) {
+		return nil
+	}
+	usage, err := l.Usage(ctx, clientID)
+	if err != nil {
+		return err
+	}
+
+	if limits.SubmissionsPerMinute > 0 && usage.SubmissionsLastMinute+1 > limits.SubmissionsPerMinute {
+		return bacerrors.NewClientLimitExceeded(
+			clientID, "submissions per minute", usage.SubmissionsLastMinute+1, limits.SubmissionsPerMinute)
+	}
+	if limits.MaxInProgressJobs > 0 && usage.InProgressJobs+1 > limits.MaxInProgressJobs {
+		return bacerrors.NewClientLimitExceeded(
+			clientID, "in progress jobs", usage.InProgressJobs+1, limits.MaxInProgressJobs)
+	}
+
+	// only check the resources that are limited
+	requested := usage.Resources.Add(jobstore.JobResources(spec))
+	max := limits.MaxResources
+	if max.CPU > 0 && requested.CPU > max.CPU {
+		return bacerrors.NewClientLimitExceeded(clientID, "requested CPU", requested.CPU, max.CPU)
+	}
+	if max.Memory > 0 && requested.Memory > max.Memory {
+		return bacerrors.NewClientLimitExceeded(clientID, "requested memory",
+			datasize.ByteSize(requested.Memory).HumanReadable(), datasize.ByteSize(max.Memory).HumanReadable())
+	}
+	if max.Disk > 0 && requested.Disk > max.Disk {
+		return bacerrors.NewClientLimitExceeded(clientID, "requested disk",
+			datasize.ByteSize(requested.Disk).HumanReadable(), datasize.ByteSize(max.Disk).HumanReadable())
+	}
+	if max.GPU > 0 && requested.GPU > max.GPU {
+		return bacerrors.NewClientLimitExceeded(clientID, "requested GPU", requested.GPU, max.GPU)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type ClientLimiterSuite struct {
+	suite.Suite
+	ctx      context.Context
+	jobStore jobstore.Store
+}
+
+func TestClientLimiterSuite(t *testing.T) {
+	suite.Run(t, new(ClientLimiterSuite))
+}
+
+func (s *ClientLimiterSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.jobStore = inmemory.NewJobStore()
+}
+
+func (s *ClientLimiterSuite) TestUsage() {
+	s.createJob("client", time.Now(), model.JobStateInProgress, model.ResourceUsageConfig{CPU: "1", Memory: "1GB"})
+	s.createJob("client", time.Now(), model.JobStateNew, model.ResourceUsageConfig{CPU: "500m"})
+	s.createJob("client", time.Now().Add(-time.Hour), model.JobStateInProgress, model.ResourceUsageConfig{Memory: "1GB"})
+	s.createJob("client", time.Now(), model.JobStateCompleted, model.ResourceUsageConfig{CPU: "10"})
+	s.createJob("other", time.Now(), model.JobStateInProgress, model.ResourceUsageConfig{CPU: "10"})
+
+	limits := model.ClientLimits{SubmissionsPerMinute: 10}
+	limiter := NewClientLimiter(ClientLimiterParams{JobStore: s.jobStore, DefaultLimits: limits})
+	usage, err := limiter.Usage(s.ctx, "client")
+	s.Require().NoError(err)
+	s.Equal(model.ClientUsage{
+		ClientID:              "client",
+		SubmissionsLastMinute: 3,
+		InProgressJobs:        3,
+		Resources:             model.ResourceUsageData{CPU: 1.5, Memory: 2 * 1024 * 1024 * 1024},
+		Limits:                limits,
+	}, usage)
+}
+
+func (s *ClientLimiterSuite) TestCheck() {
+	s.createJob("client", time.Now(), model.JobStateInProgress, model.ResourceUsageConfig{CPU: "1", Memory: "1GB"})
+	s.createJob("client", time.Now().Add(-time.Hour), model.JobStateCompleted, model.ResourceUsageConfig{})
+	spec := model.Spec{Resources: model.ResourceUsageConfig{CPU: "1", Memory: "1GB"}}
+
+	for _, tc := range []struct {
+		name          string
+		defaultLimits model.ClientLimits
+		clientLimits  map[string]model.ClientLimits
+		exceeded      bool
+	}{
+		{name: "unlimited"},
+		{name: "under submissions limit", defaultLimits: model.ClientLimits{SubmissionsPerMinute: 2}},
+		{name: "reached submissions limit", defaultLimits: model.ClientLimits{SubmissionsPerMinute: 1}, exceeded: true},
+		{name: "under in progress limit", defaultLimits: model.ClientLimits{MaxInProgressJobs: 2}},
+		{name: "reached in progress limit", defaultLimits: model.ClientLimits{MaxInProgressJobs: 1}, exceeded: true},
+		{
+			name:          "under resources limit",
+			defaultLimits: model.ClientLimits{MaxResources: model.ResourceUsageData{CPU: 2, Memory: 2 * 1024 * 1024 * 1024}},
+		},
+		{
+			name:          "over CPU limit",
+			defaultLimits: model.ClientLimits{MaxResources: model.ResourceUsageData{CPU: 1.5}},
+			exceeded:      true,
+		},
+		{
+			name:          "over memory limit",
+			defaultLimits: model.ClientLimits{MaxResources: model.ResourceUsageData{Memory: 1024 * 1024 * 1024}},
+			exceeded:      true,
+		},
+		{
+			name:          "client limits replace defaults",
+			defaultLimits: model.ClientLimits{MaxInProgressJobs: 1},
+			clientLimits:  map[string]model.ClientLimits{"client": {MaxInProgressJobs: 10}},
+		},
+		{
+			name:         "reached client limit",
+			clientLimits: map[string]model.ClientLimits{"client": {SubmissionsPerMinute: 1}},
+			exceeded:     true,
+		},
+	} {
+		s.Run(tc.name, func() {
+			limiter := NewClientLimiter(ClientLimiterParams{
+				JobStore:      s.jobStore,
+				DefaultLimits: tc.defaultLimits,
+				ClientLimits:  tc.clientLimits,
+			})
+			err := limiter.Check(s.ctx, "client", spec)
+			if !tc.exceeded {
+				s.NoError(err)
+				return
+			}
+			s.Require().IsType(&bacerrors.ClientLimitExceeded{}, err)
+			s.Equal(bacerrors.ErrorCodeClientLimitExceeded, err.(*bacerrors.ClientLimitExceeded).Code)
+		})
+	}
+}
+
+func (s *ClientLimiterSuite) TestResourcesOfAllExecutionsAreCounted() {
+	limiter := NewClientLimiter(ClientLimiterParams{
+		JobStore:      s.jobStore,
+		DefaultLimits: model.ClientLimits{MaxResources: model.ResourceUsageData{CPU: 5}},
+	})
+	spec := model.Spec{
+		Resources:     model.ResourceUsageConfig{CPU: "1"},
+		ExecutionPlan: model.JobExecutionPlan{TotalShards: 2},
+		Deal:          model.Deal{Concurrency: 3},
+	}
+	s.Require().IsType(&bacerrors.ClientLimitExceeded{}, limiter.Check(s.ctx, "client", spec))
+
+	spec.Deal.Concurrency = 2
+	s.NoError(limiter.Check(s.ctx, "client", spec))
+
+	// spot checked shards are executed once more
+	spec.Verifier = model.VerifierSpotCheck
+	spec.Deal.SpotCheckFraction = 0.5
+	s.NoError(limiter.Check(s.ctx, "client", spec))
+
+	spec.Deal.SpotCheckFraction = 1
+	s.Require().IsType(&bacerrors.ClientLimitExceeded{}, limiter.Check(s.ctx, "client", spec))
+}
+
+func (s *ClientLimiterSuite) TestLockSerializesSubmissionsOfClient() {
+	limiter := NewClientLimiter(ClientLimiterParams{
+		JobStore:      s.jobStore,
+		DefaultLimits: model.ClientLimits{MaxInProgressJobs: 1},
+	})
+	unlock := limiter.Lock("client")
+	locked := make(chan struct{})
+	go func() {
+		defer close(locked)
+		limiter.Lock("client")()
+	}()
+	// submissions of other clients are not blocked
+	limiter.Lock("other")()
+
+	select {
+	case <-locked:
+		s.FailNow("second submission of the client was not blocked")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	<-locked
+	s.Empty(limiter.locks)
+}
+
+func (s *ClientLimiterSuite) createJob(
+	clientID string, createdAt time.Time, state model.JobStateType, resources model.ResourceUsageConfig) {
+	job := model.Job{
+		APIVersion: model.APIVersionLatest().String(),
+		Metadata:   model.Metadata{ID: uuid.NewString(), ClientID: clientID, CreatedAt: createdAt},
+		Spec: model.Spec{
+			Engine:        model.EngineNoop,
+			Resources:     resources,
+			ExecutionPlan: model.JobExecutionPlan{TotalShards: 1},
+			Deal:          model.Deal{Concurrency: 1},
+		},
+	}
+	s.Require().NoError(s.jobStore.CreateJob(s.ctx, job))
+	if state != model.JobStateNew {
+		s.Require().NoError(s.jobStore.UpdateJobState(s.ctx, jobstore.UpdateJobStateRequest{
+			JobID:    job.Metadata.ID,
+			NewState: state,
+		}))
+	}
+}
@@ -0,0 +1,209 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// leaderLeaseName is the lease held by the requester node that adopts the jobs of stopped requester nodes
+	leaderLeaseName = "requester/leader"
+	// leaseRenewalsPerDuration is the number of times leases are renewed during their duration, so that a lease
+	// is not lost when a renewal is delayed
+	leaseRenewalsPerDuration = 3
+)
+
+// requesterLeaseName is the lease held by a requester node as long as it is alive
+func requesterLeaseName(nodeID string) string {
+	return "requester/node/" + nodeID
+}
+
+type CoordinatorParams struct {
+	JobStore  jobstore.Store
+	Recovery  *Recovery
+	NodeID    string
+	PublicKey model.PublicKey
+	// LeaseDuration is how long the leases of this node stay valid if they are not renewed, such as when the node stops.
+	LeaseDuration time.Duration
+}
+
+// Coordinator allows multiple requester nodes to share the same job store. Each requester node holds a lease that
+// it renews while it is alive, and one of them is elected as leader by holding the leader lease. The leader adopts
+// the in progress jobs of requester nodes whose lease expired, by transferring the ownership of the jobs to itself
+// and resuming them.
+type Coordinator struct {
+	jobStore      jobstore.Store
+	recovery      *Recovery
+	nodeID        string
+	publicKey     model.PublicKey
+	leaseDuration time.Duration
+	isLeader      atomic.Bool
+
+	stopChannel chan struct{}
+	stopOnce    sync.Once
+}
+
+func NewCoordinator(params CoordinatorParams) *Coordinator {
+	c := &Coordinator{
+		jobStore:      params.JobStore,
+		recovery:      params.Recovery,
+		nodeID:        params.NodeID,
+		publicKey:     params.PublicKey,
+		leaseDuration: params.LeaseDuration,
+		stopChannel:   make(chan struct{}),
+	}
+
+	// acquire the lease of this node before it starts accepting jobs, so that the leader does not adopt them
+	c.renewRequesterLease(context.Background())
+	go c.coordinatorBackgroundTask()
+	return c
+}
+
+// IsLeader returns true if this node is currently the leader of the requester nodes sharing the job store
+func (c *Coordinator) IsLeader() bool {
+	return c.isLeader.Load()
+}
+
+func (c *Coordinator) coordinatorBackgroundTask() {
+	ctx := context.Background()
+	c.electLeader(ctx)
+	ticker := time.NewTicker(c.leaseDuration / leaseRenewalsPerDuration)
+	for {
+		select {
+		case <-ticker.C:
+			if c.renewRequesterLease(ctx) {
+				c.electLeader(ctx)
+			}
+		case <-c.stopChannel:
+			ticker.Stop()
+			c.releaseLeases(ctx)
+			log.Ctx(ctx).Debug().Msg("stopped coordinator task")
+			return
+		}
+	}
+}
+
+// renewRequesterLease acquires or renews the lease of this node, and returns true if it succeeded.
+func (c *Coordinator) renewRequesterLease(ctx context.Context) bool {
+	_, err := c.jobStore.AcquireLease(ctx, jobstore.AcquireLeaseRequest{
+		Name:     requesterLeaseName(c.nodeID),
+		Holder:   c.nodeID,
+		Duration: c.leaseDuration,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to renew requester lease")
+		return false
+	}
+	return true
+}
+
+// electLeader tries to acquire or renew the leader lease, and adopts orphaned jobs if this node is the leader.
+func (c *Coordinator) electLeader(ctx context.Context) {
+	_, err := c.jobStore.AcquireLease(ctx, jobstore.AcquireLeaseRequest{
+		Name:     leaderLeaseName,
+		Holder:   c.nodeID,
+		Duration: c.leaseDuration,
+	})
+	if err != nil {
+		if _, held := err.(jobstore.ErrLeaseHeld); !held {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to acquire leader lease")
+		}
+		if c.isLeader.Swap(false) {
+			log.Ctx(ctx).Info().Msgf("requester %s is no longer the leader", c.nodeID)
+		}
+		return
+	}
+	if !c.isLeader.Swap(true) {
+		log.Ctx(ctx).Info().Msgf("requester %s became the leader", c.nodeID)
+	}
+	c.adoptOrphanedJobs(ctx)
+}
+
+// adoptOrphanedJobs adopts the in progress jobs owned by requester nodes whose lease expired.
+func (c *Coordinator) adoptOrphanedJobs(ctx context.Context) {
+	jobs, err := c.jobStore.GetInProgressJobs(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to get in progress jobs")
+		return
+	}
+
+	now := time.Now()
+	alive := make(map[string]bool)
+	for _, jobDescription := range jobs {
+		owner := jobDescription.Job.Metadata.Requester.RequesterNodeID
+		if owner == c.nodeID {
+			continue
+		}
+		isAlive, ok := alive[owner]
+		if !ok {
+			isAlive = c.isAlive(ctx, owner, now)
+			alive[owner] = isAlive
+		}
+		if !isAlive {
+			c.adoptJob(ctx, jobDescription)
+		}
+	}
+}
+
+// isAlive returns true if the requester node holds an unexpired lease. Nodes are assumed to be alive if their lease
+// cannot be read, to avoid adopting jobs of a running node.
+func (c *Coordinator) isAlive(ctx context.Context, nodeID string, now time.Time) bool {
+	lease, err := c.jobStore.GetLease(ctx, requesterLeaseName(nodeID))
+	if err != nil {
+		if _, notFound := err.(jobstore.ErrLeaseNotFound); notFound {
+			return false
+		}
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get lease of requester %s", nodeID)
+		return true
+	}
+	return !lease.IsExpired(now)
+}
+
+func (c *Coordinator) adoptJob(ctx context.Context, jobDescription model.JobWithInfo) {
+	jobID := jobDescription.Job.Metadata.ID
+	previousOwner := jobDescription.Job.Metadata.Requester.RequesterNodeID
+	newRequester := model.JobRequester{
+		RequesterNodeID:    c.nodeID,
+		RequesterPublicKey: c.publicKey,
+	}
+	err := c.jobStore.UpdateJobRequester(ctx, jobstore.UpdateJobRequesterRequest{
+		JobID:                   jobID,
+		ExpectedRequesterNodeID: previousOwner,
+		NewRequester:            newRequester,
+		Comment:                 fmt.Sprintf("adopted by requester %s as requester %s stopped", c.nodeID, previousOwner),
+	})
+	if err != nil {
+		// the job might have been adopted by a previous leader, or completed in the meantime
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to adopt job %s of requester %s", jobID, previousOwner)
+		return
+	}
+
+	jobDescription.Job.Metadata.Requester = newRequester
+	go c.recovery.Adopt(ctx, jobDescription)
+}
+
+// releaseLeases releases the leases of this node so that another node can become the leader without waiting for the
+// leases to expire.
+func (c *Coordinator) releaseLeases(ctx context.Context) {
+	if c.isLeader.Swap(false) {
+		if err := c.jobStore.ReleaseLease(ctx, leaderLeaseName, c.nodeID); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to release leader lease")
+		}
+	}
+	if err := c.jobStore.ReleaseLease(ctx, requesterLeaseName(c.nodeID), c.nodeID); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to release requester lease")
+	}
+}
+
+func (c *Coordinator) Stop() {
+	c.stopOnce.Do(func() {
+		c.stopChannel <- struct{}{}
+	})
+}
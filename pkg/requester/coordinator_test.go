@@ -0,0 +1,190 @@
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+const testLeaseDuration = 60 * time.Millisecond
+
+type CoordinatorSuite struct {
+	suite.Suite
+	ctx             context.Context
+	jobStore        jobstore.Store
+	computeEndpoint *testComputeEndpoint
+	coordinators    []*Coordinator
+}
+
+func TestCoordinatorSuite(t *testing.T) {
+	suite.Run(t, new(CoordinatorSuite))
+}
+
+func (s *CoordinatorSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.jobStore = inmemory.NewJobStore()
+	s.computeEndpoint = &testComputeEndpoint{states: make(map[string]store.ExecutionState)}
+	s.coordinators = nil
+}
+
+func (s *CoordinatorSuite) TearDownTest() {
+	for _, c := range s.coordinators {
+		c.Stop()
+	}
+}
+
+func (s *CoordinatorSuite) TestSingleLeaderIsElected() {
+	a := s.startCoordinator("requester-a")
+	b := s.startCoordinator("requester-b")
+
+	s.Eventually(func() bool {
+		return a.IsLeader() != b.IsLeader()
+	}, time.Second, 10*time.Millisecond)
+
+	// the other node becomes the leader once the leader stops
+	leader, follower := a, b
+	if b.IsLeader() {
+		leader, follower = b, a
+	}
+	leader.Stop()
+	s.Eventually(follower.IsLeader, time.Second, 10*time.Millisecond)
+}
+
+func (s *CoordinatorSuite) TestJobsOfLiveRequestersAreNotAdopted() {
+	job := s.createJob("requester-b")
+	s.startCoordinator("requester-b")
+	s.startCoordinator("requester-a")
+
+	time.Sleep(3 * testLeaseDuration)
+	s.Equal("requester-b", s.getOwner(job))
+}
+
+func (s *CoordinatorSuite) TestJobsOfStoppedRequesterAreAdopted() {
+	job := s.createJob("requester-b")
+	running := s.createExecution(job, model.ExecutionStateBidAccepted)
+	s.computeEndpoint.states[running.ComputeReference] = store.ExecutionStateRunning
+	b := s.startCoordinator("requester-b")
+	s.startCoordinator("requester-a")
+
+	b.Stop()
+	s.Eventually(func() bool {
+		return s.getOwner(job) == "requester-a"
+	}, time.Second, 10*time.Millisecond)
+
+	// the callbacks of the running execution are redirected to the adopter, and it keeps running
+	s.Eventually(func() bool {
+		return s.computeEndpoint.getRedirect(running.ComputeReference) == "requester-a"
+	}, time.Second, 10*time.Millisecond)
+	s.Equal(model.ExecutionStateBidAccepted, s.getExecution(job, running).State)
+}
+
+func (s *CoordinatorSuite) TestUnreachableExecutionsOfAdoptedJobAreFailed() {
+	job := s.createJob("unknown-requester")
+	execution := s.createExecution(job, model.ExecutionStateBidAccepted)
+	s.startCoordinator("requester-a")
+
+	s.Eventually(func() bool {
+		return s.getShardState(job).State == model.ShardStateError
+	}, time.Second, 10*time.Millisecond)
+	s.Equal(model.ExecutionStateFailed, s.getExecution(job, execution).State)
+}
+
+func (s *CoordinatorSuite) TestInFlightAskForBidIsResentByAdopter() {
+	job := s.createJob("unknown-requester")
+	s.Require().NoError(s.jobStore.CreateExecution(s.ctx, model.ExecutionState{
+		JobID:      job.Metadata.ID,
+		ShardIndex: 0,
+		NodeID:     recoveryComputeID,
+		State:      model.ExecutionStateAskForBid,
+	}))
+	s.startCoordinator("requester-a")
+
+	s.Eventually(func() bool {
+		return len(s.computeEndpoint.getAskedForBids()) == 1
+	}, time.Second, 10*time.Millisecond)
+	request := s.computeEndpoint.getAskedForBids()[0]
+	s.Equal("requester-a", request.SourcePeerID)
+	s.Equal("requester-a", request.Job.Metadata.Requester.RequesterNodeID)
+}
+
+func (s *CoordinatorSuite) startCoordinator(nodeID string) *Coordinator {
+	scheduler := NewScheduler(SchedulerParams{
+		ID:              nodeID,
+		JobStore:        s.jobStore,
+		ComputeEndpoint: s.computeEndpoint,
+		EventEmitter:    noopEventEmitter(),
+	})
+	c := NewCoordinator(CoordinatorParams{
+		JobStore: s.jobStore,
+		Recovery: NewRecovery(RecoveryParams{
+			Scheduler:     scheduler,
+			JobStore:      s.jobStore,
+			NodeID:        nodeID,
+			RetryInterval: 10 * time.Millisecond,
+			MaxAttempts:   2,
+		}),
+		NodeID:        nodeID,
+		LeaseDuration: testLeaseDuration,
+	})
+	s.coordinators = append(s.coordinators, c)
+	return c
+}
+
+func (s *CoordinatorSuite) createJob(requesterNodeID string) model.Job {
+	job := model.Job{
+		APIVersion: model.APIVersionLatest().String(),
+		Metadata: model.Metadata{
+			ID:        uuid.NewString(),
+			CreatedAt: time.Now(),
+			Requester: model.JobRequester{RequesterNodeID: requesterNodeID},
+		},
+		Spec: model.Spec{
+			Engine:        model.EngineNoop,
+			ExecutionPlan: model.JobExecutionPlan{TotalShards: 1},
+			Deal:          model.Deal{Concurrency: 1},
+		},
+	}
+	s.Require().NoError(s.jobStore.CreateJob(s.ctx, job))
+	return job
+}
+
+func (s *CoordinatorSuite) createExecution(job model.Job, state model.ExecutionStateType) model.ExecutionState {
+	execution := model.ExecutionState{
+		JobID:            job.Metadata.ID,
+		ShardIndex:       0,
+		NodeID:           recoveryComputeID,
+		ComputeReference: "e-" + uuid.NewString(),
+		State:            state,
+	}
+	s.Require().NoError(s.jobStore.CreateExecution(s.ctx, execution))
+	return execution
+}
+
+func (s *CoordinatorSuite) getOwner(job model.Job) string {
+	stored, err := s.jobStore.GetJob(s.ctx, job.Metadata.ID)
+	s.Require().NoError(err)
+	return stored.Metadata.Requester.RequesterNodeID
+}
+
+func (s *CoordinatorSuite) getExecution(job model.Job, execution model.ExecutionState) model.ExecutionState {
+	for _, e := range s.getShardState(job).Executions {
+		if e.ComputeReference == execution.ComputeReference {
+			return e
+		}
+	}
+	s.FailNow("execution not found")
+	return model.ExecutionState{}
+}
+
+func (s *CoordinatorSuite) getShardState(job model.Job) model.ShardState {
+	shardState, err := s.jobStore.GetShardState(s.ctx, model.ShardID{JobID: job.Metadata.ID, Index: 0})
+	s.Require().NoError(err)
+	return shardState
+}
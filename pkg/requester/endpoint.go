@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
 	"github.com/filecoin-project/bacalhau/pkg/model"
 	"github.com/filecoin-project/bacalhau/pkg/requester/jobtransform"
 	"github.com/filecoin-project/bacalhau/pkg/storage"
@@ -23,6 +24,18 @@ type BaseEndpointParams struct {
 	StorageProviders           storage.StorageProvider
 	MinJobExecutionTimeout     time.Duration
 	DefaultJobExecutionTimeout time.Duration
+	// JobStore and WorkflowCheckInterval are used to track the jobs of workflows and submit their downstream jobs
+	JobStore              jobstore.Store
+	WorkflowCheckInterval time.Duration
+	// ScheduleCheckInterval is the interval between checks for due runs of scheduled jobs
+	ScheduleCheckInterval time.Duration
+	// DefaultResultQuota and ClientResultQuotas limit the total size of the results that the jobs of each client
+	// store, where zero is unlimited
+	DefaultResultQuota uint64
+	ClientResultQuotas map[string]uint64
+	// DefaultClientLimits and ClientLimits limit the rate at which each client submits jobs and its in progress jobs
+	DefaultClientLimits model.ClientLimits
+	ClientLimits        map[string]model.ClientLimits
 }
 
 // BaseEndpoint base implementation of requester Endpoint
@@ -30,6 +43,10 @@ type BaseEndpoint struct {
 	id         string
 	scheduler  *Scheduler
 	transforms []jobtransform.Transformer
+	workflows  *workflowManager
+	schedules  *scheduleManager
+	quota      *ResultQuota
+	limiter    *ClientLimiter
 }
 
 func NewBaseEndpoint(params *BaseEndpointParams) *BaseEndpoint {
@@ -40,14 +57,45 @@ func NewBaseEndpoint(params *BaseEndpointParams) *BaseEndpoint {
 		jobtransform.NewRequesterInfo(params.ID, params.PublicKey),
 	}
 
-	return &BaseEndpoint{
+	endpoint := &BaseEndpoint{
 		id:         params.ID,
 		scheduler:  params.Scheduler,
 		transforms: transforms,
+		quota: NewResultQuota(ResultQuotaParams{
+			JobStore:     params.JobStore,
+			DefaultQuota: params.DefaultResultQuota,
+			ClientQuotas: params.ClientResultQuotas,
+		}),
+		limiter: NewClientLimiter(ClientLimiterParams{
+			JobStore:      params.JobStore,
+			DefaultLimits: params.DefaultClientLimits,
+			ClientLimits:  params.ClientLimits,
+		}),
 	}
+	endpoint.workflows = newWorkflowManager(workflowManagerParams{
+		NodeID:   params.ID,
+		Jobs:     endpoint,
+		JobStore: params.JobStore,
+		Interval: params.WorkflowCheckInterval,
+	})
+	endpoint.schedules = newScheduleManager(scheduleManagerParams{
+		NodeID:   params.ID,
+		Jobs:     endpoint,
+		JobStore: params.JobStore,
+		Interval: params.ScheduleCheckInterval,
+	})
+	return endpoint
 }
 
 func (node *BaseEndpoint) SubmitJob(ctx context.Context, data model.JobCreatePayload) (*model.Job, error) {
+	if err := node.quota.Check(ctx, data.ClientID); err != nil {
+		return &model.Job{}, err
+	}
+	// the limits of the client are checked and the job created while holding the lock of the client, so that
+	// concurrent submissions can't exceed its limits
+	unlock := node.limiter.Lock(data.ClientID)
+	defer unlock()
+
 	jobUUID, err := uuid.NewRandom()
 	if err != nil {
 		return &model.Job{}, fmt.Errorf("error creating job id: %w", err)
@@ -91,6 +139,11 @@ func (node *BaseEndpoint) SubmitJob(ctx context.Context, data model.JobCreatePay
 		}
 	}
 
+	// the limits are checked after the transforms, which complete the execution plan of the job
+	if err = node.limiter.Check(ctx, data.ClientID, job.Spec); err != nil {
+		return &model.Job{}, err
+	}
+
 	err = node.scheduler.StartJob(jobCtx, StartJobRequest{
 		Job: *job,
 	})
@@ -105,5 +158,51 @@ func (node *BaseEndpoint) CancelJob(ctx context.Context, request CancelJobReques
 	return node.scheduler.CancelJob(ctx, request)
 }
 
+func (node *BaseEndpoint) SubmitWorkflow(ctx context.Context, data model.WorkflowCreatePayload) (model.WorkflowState, error) {
+	return node.workflows.submit(ctx, data)
+}
+
+func (node *BaseEndpoint) GetWorkflowState(ctx context.Context, workflowID string) (model.WorkflowState, error) {
+	return node.workflows.get(ctx, workflowID)
+}
+
+func (node *BaseEndpoint) CancelWorkflow(ctx context.Context, request CancelWorkflowRequest) (model.WorkflowState, error) {
+	return node.workflows.cancel(ctx, request)
+}
+
+func (node *BaseEndpoint) CreateSchedule(ctx context.Context, data model.ScheduleCreatePayload) (model.Schedule, error) {
+	return node.schedules.create(ctx, data)
+}
+
+func (node *BaseEndpoint) GetSchedule(ctx context.Context, scheduleID string) (model.Schedule, error) {
+	return node.schedules.get(scheduleID)
+}
+
+func (node *BaseEndpoint) ListSchedules(ctx context.Context, clientID string) ([]model.Schedule, error) {
+	return node.schedules.list(clientID), nil
+}
+
+func (node *BaseEndpoint) DeleteSchedule(ctx context.Context, scheduleID string) (model.Schedule, error) {
+	return node.schedules.delete(ctx, scheduleID)
+}
+
+// ResumeSchedules resumes running the schedules that this node created before it restarted
+func (node *BaseEndpoint) ResumeSchedules(ctx context.Context) error {
+	return node.schedules.resume(ctx)
+}
+
+// ResumeWorkflows resumes tracking the workflows in progress that were submitted to this node before it restarted
+func (node *BaseEndpoint) ResumeWorkflows(ctx context.Context) error {
+	return node.workflows.resume(ctx)
+}
+
+func (node *BaseEndpoint) GetLogs(ctx context.Context, request GetLogsRequest) (model.ExecutionLogs, error) {
+	return node.scheduler.GetLogs(ctx, request)
+}
+
+func (node *BaseEndpoint) GetClientUsage(ctx context.Context, clientID string) (model.ClientUsage, error) {
+	return node.limiter.Usage(ctx, clientID)
+}
+
 // Compile-time interface check:
 var _ Endpoint = (*BaseEndpoint)(nil)
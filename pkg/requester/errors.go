@@ -47,3 +47,29 @@ func NewErrJobAlreadyTerminal(jobID string) ErrJobAlreadyTerminal {
 func (e ErrJobAlreadyTerminal) Error() string {
 	return fmt.Errorf("job %s is already in a terminal state", e.JobID).Error()
 }
+
+// ErrWorkflowNotFound is returned when a workflow was not found
+type ErrWorkflowNotFound struct {
+	WorkflowID string
+}
+
+func NewErrWorkflowNotFound(workflowID string) ErrWorkflowNotFound {
+	return ErrWorkflowNotFound{WorkflowID: workflowID}
+}
+
+func (e ErrWorkflowNotFound) Error() string {
+	return fmt.Errorf("workflow not found: %s", e.WorkflowID).Error()
+}
+
+// ErrScheduleNotFound is returned when a schedule was not found
+type ErrScheduleNotFound struct {
+	ScheduleID string
+}
+
+func NewErrScheduleNotFound(scheduleID string) ErrScheduleNotFound {
+	return ErrScheduleNotFound{ScheduleID: scheduleID}
+}
+
+func (e ErrScheduleNotFound) Error() string {
+	return fmt.Errorf("schedule not found: %s", e.ScheduleID).Error()
+}
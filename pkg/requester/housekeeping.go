@@ -51,6 +51,11 @@ func (h *Housekeeping) housekeepingBackgroundTask() {
 				continue
 			}
 			now := time.Now()
+			// the jobs of this node might be adopted by another node while this node does not hold its lease
+			if !h.holdsLease(ctx, now) {
+				log.Ctx(ctx).Warn().Msg("skipping housekeeping as this node does not hold its lease")
+				continue
+			}
 			for _, jobDescription := range jobs {
 				// in case the job store is shared between multiple nodes, we only want to clean up jobs that are owned by this node
 				if jobDescription.Job.Metadata.Requester.RequesterNodeID != h.nodeID {
@@ -61,8 +66,9 @@ func (h *Housekeeping) housekeepingBackgroundTask() {
 					log.Ctx(ctx).Info().Msgf("job %s timed out. Canceling", jobDescription.Job.Metadata.ID)
 					go func(jobID string) {
 						_, innerErr := h.endpoint.CancelJob(ctx, CancelJobRequest{
-							JobID:  jobID,
-							Reason: "timed out",
+							JobID:    jobID,
+							Reason:   "timed out",
+							TimedOut: true,
 						})
 						if innerErr != nil {
 							log.Ctx(ctx).Err(innerErr).Msgf("failed to cancel job %s", jobID)
@@ -78,6 +84,17 @@ func (h *Housekeeping) housekeepingBackgroundTask() {
 	}
 }
 
+// holdsLease returns true if this node holds an unexpired requester lease, which means that no other node can adopt
+// its jobs.
+func (h *Housekeeping) holdsLease(ctx context.Context, now time.Time) bool {
+	lease, err := h.jobStore.GetLease(ctx, requesterLeaseName(h.nodeID))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to get requester lease")
+		return false
+	}
+	return lease.Holder == h.nodeID && !lease.IsExpired(now)
+}
+
 func (h *Housekeeping) Stop() {
 	h.stopOnce.Do(func() {
 		h.stopChannel <- struct{}{}
@@ -0,0 +1,94 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/filecoin-project/bacalhau/pkg/compute"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+// GetLogs returns the output of the latest execution of a shard since the given offset. The output is read from the
+// compute node running the execution, or from the run output of the execution once the compute node no longer has it.
+func (s *Scheduler) GetLogs(ctx context.Context, request GetLogsRequest) (model.ExecutionLogs, error) {
+	jobState, err := s.jobStore.GetJobState(ctx, request.JobID)
+	if err != nil {
+		return model.ExecutionLogs{}, err
+	}
+	shardState, ok := jobState.Shards[request.ShardIndex]
+	if !ok {
+		return model.ExecutionLogs{}, fmt.Errorf("job %s has no shard %d", request.JobID, request.ShardIndex)
+	}
+
+	result := model.ExecutionLogs{
+		JobID:      request.JobID,
+		ShardIndex: request.ShardIndex,
+		NextOffset: request.Offset,
+	}
+	execution, found := latestExecutionWithLogs(shardState)
+	if !found {
+		// no execution was started yet
+		result.Finished = shardState.State.IsTerminal()
+		return result, nil
+	}
+	result.NodeID = execution.NodeID
+
+	response, err := s.computeService.ExecutionLogs(ctx, compute.ExecutionLogsRequest{
+		RoutingMetadata: compute.RoutingMetadata{
+			SourcePeerID: s.id,
+			TargetPeerID: execution.NodeID,
+		},
+		ExecutionID: execution.ComputeReference,
+		Offset:      request.Offset,
+	})
+	if err == nil && response.Available {
+		result.Logs = response.Logs
+		result.NextOffset = response.NextOffset
+		result.Finished = response.Finished
+		return result, nil
+	}
+	if err != nil {
+		if !execution.State.IsTerminal() && execution.RunOutput == nil {
+			return model.ExecutionLogs{}, fmt.Errorf("failed to get logs of execution %s: %w", execution.ID(), err)
+		}
+		log.Ctx(ctx).Debug().Err(err).Msgf("failed to get logs of execution %s, using its run output", execution.ID())
+	}
+
+	// the compute node no longer has the logs, so fall back to the truncated output the execution reported
+	result.Finished = execution.RunOutput != nil || execution.State.IsTerminal()
+	if execution.RunOutput != nil && request.Offset == 0 {
+		for _, entry := range []model.LogEntry{
+			{Stream: model.LogStreamStdout, Data: execution.RunOutput.STDOUT},
+			{Stream: model.LogStreamStderr, Data: execution.RunOutput.STDERR},
+		} {
+			if entry.Data != "" {
+				result.Logs = append(result.Logs, entry)
+			}
+		}
+		result.NextOffset = len(result.Logs)
+	}
+	return result, nil
+}
+
+// latestExecutionWithLogs returns the most recently updated execution of the shard that was accepted to run,
+// preferring executions that did not fail.
+func latestExecutionWithLogs(shardState model.ShardState) (model.ExecutionState, bool) {
+	var latest model.ExecutionState
+	var found bool
+	for _, execution := range shardState.Executions {
+		switch execution.State {
+		case model.ExecutionStateNew, model.ExecutionStateAskForBid, model.ExecutionStateAskForBidAccepted,
+			model.ExecutionStateAskForBidRejected, model.ExecutionStateBidRejected:
+			continue
+		default:
+		}
+		if !found ||
+			(execution.State.IsActive() && !latest.State.IsActive()) ||
+			(execution.State.IsActive() == latest.State.IsActive() && execution.UpdateTime.After(latest.UpdateTime)) {
+			latest = execution
+			found = true
+		}
+	}
+	return latest, found
+}
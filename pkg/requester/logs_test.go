@@ -0,0 +1,117 @@
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/compute"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type LogsSuite struct {
+	suite.Suite
+	ctx             context.Context
+	jobStore        jobstore.Store
+	computeEndpoint *testComputeEndpoint
+	scheduler       *Scheduler
+	job             model.Job
+}
+
+func TestLogsSuite(t *testing.T) {
+	suite.Run(t, new(LogsSuite))
+}
+
+func (s *LogsSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.jobStore = inmemory.NewJobStore()
+	s.computeEndpoint = &testComputeEndpoint{logs: make(map[string]compute.ExecutionLogsResponse)}
+	s.scheduler = NewScheduler(SchedulerParams{
+		ID:              "requester",
+		JobStore:        s.jobStore,
+		ComputeEndpoint: s.computeEndpoint,
+		EventEmitter:    noopEventEmitter(),
+	})
+	s.job = model.Job{
+		APIVersion: model.APIVersionLatest().String(),
+		Metadata:   model.Metadata{ID: uuid.NewString(), CreatedAt: time.Now()},
+		Spec: model.Spec{
+			Engine:        model.EngineNoop,
+			ExecutionPlan: model.JobExecutionPlan{TotalShards: 1},
+			Deal:          model.Deal{Concurrency: 1},
+		},
+	}
+	s.Require().NoError(s.jobStore.CreateJob(s.ctx, s.job))
+}
+
+func (s *LogsSuite) TestNoExecutionYet() {
+	logs, err := s.scheduler.GetLogs(s.ctx, GetLogsRequest{JobID: s.job.Metadata.ID})
+	s.Require().NoError(err)
+	s.Empty(logs.Logs)
+	s.False(logs.Finished)
+}
+
+func (s *LogsSuite) TestUnknownShard() {
+	_, err := s.scheduler.GetLogs(s.ctx, GetLogsRequest{JobID: s.job.Metadata.ID, ShardIndex: 1})
+	s.Error(err)
+}
+
+func (s *LogsSuite) TestRunningExecution() {
+	discarded := s.createExecution(model.ExecutionStateBidRejected, nil)
+	running := s.createExecution(model.ExecutionStateBidAccepted, nil)
+	entries := []model.LogEntry{{Stream: model.LogStreamStdout, Data: "hello"}}
+	s.computeEndpoint.logs[running.ComputeReference] = compute.ExecutionLogsResponse{
+		Logs:       entries,
+		NextOffset: 3,
+		Available:  true,
+	}
+	s.computeEndpoint.logs[discarded.ComputeReference] = compute.ExecutionLogsResponse{Available: true, Finished: true}
+
+	logs, err := s.scheduler.GetLogs(s.ctx, GetLogsRequest{JobID: s.job.Metadata.ID, Offset: 2})
+	s.Require().NoError(err)
+	s.Equal(entries, logs.Logs)
+	s.Equal(3, logs.NextOffset)
+	s.Equal(running.NodeID, logs.NodeID)
+	s.False(logs.Finished)
+}
+
+func (s *LogsSuite) TestUnreachableRunningExecution() {
+	s.createExecution(model.ExecutionStateBidAccepted, nil)
+	_, err := s.scheduler.GetLogs(s.ctx, GetLogsRequest{JobID: s.job.Metadata.ID})
+	s.Error(err)
+}
+
+func (s *LogsSuite) TestFallBackToRunOutput() {
+	s.createExecution(model.ExecutionStateCompleted, &model.RunCommandResult{STDOUT: "out", STDERR: "err"})
+
+	logs, err := s.scheduler.GetLogs(s.ctx, GetLogsRequest{JobID: s.job.Metadata.ID})
+	s.Require().NoError(err)
+	s.Equal([]model.LogEntry{
+		{Stream: model.LogStreamStdout, Data: "out"},
+		{Stream: model.LogStreamStderr, Data: "err"},
+	}, logs.Logs)
+	s.Equal(2, logs.NextOffset)
+	s.True(logs.Finished)
+
+	logs, err = s.scheduler.GetLogs(s.ctx, GetLogsRequest{JobID: s.job.Metadata.ID, Offset: logs.NextOffset})
+	s.Require().NoError(err)
+	s.Empty(logs.Logs)
+	s.True(logs.Finished)
+}
+
+func (s *LogsSuite) createExecution(state model.ExecutionStateType, runOutput *model.RunCommandResult) model.ExecutionState {
+	execution := model.ExecutionState{
+		JobID:            s.job.Metadata.ID,
+		ShardIndex:       0,
+		NodeID:           "node-" + uuid.NewString(),
+		ComputeReference: "e-" + uuid.NewString(),
+		State:            state,
+		RunOutput:        runOutput,
+	}
+	s.Require().NoError(s.jobStore.CreateExecution(s.ctx, execution))
+	return execution
+}
@@ -54,8 +54,7 @@ func (apiClient *RequesterAPIClient) List(
 	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.List")
 	defer span.End()
 
-	req := listRequest{
-		ClientID:    system.GetClientID(),
+	res, err := apiClient.ListPage(ctx, ListRequest{
 		MaxJobs:     maxJobs,
 		JobID:       idFilter,
 		IncludeTags: includeTags,
@@ -63,15 +62,27 @@ func (apiClient *RequesterAPIClient) List(
 		ReturnAll:   returnAll,
 		SortBy:      sortBy,
 		SortReverse: sortReverse,
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	return res.Jobs, nil
+}
+
+// ListPage returns the page of jobs matching all the filters of the request, along with the cursor
+// of the next page if more jobs match. The client ID of the request is always set to the current client.
+func (apiClient *RequesterAPIClient) ListPage(ctx context.Context, req ListRequest) (ListResponse, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.ListPage")
+	defer span.End()
+
+	req.ClientID = system.GetClientID()
+
 	var res listResponse
 	if err := apiClient.Post(ctx, APIPrefix+"list", req, &res); err != nil {
-		e := err
-		return nil, e
+		return ListResponse{}, err
 	}
-
-	return res.Jobs, nil
+	return res, nil
 }
 
 // Cancel will request that the job with the specified ID is stopped. The JobInfo will be returned if the cancel
@@ -184,6 +195,31 @@ func (apiClient *RequesterAPIClient) GetJobState(ctx context.Context, jobID stri
 	return model.JobState{}, outerErr
 }
 
+// GetLogs returns the output of the latest execution of a job shard since the given offset, which is the
+// NextOffset of the previously returned logs, or zero to read the logs from the start.
+func (apiClient *RequesterAPIClient) GetLogs(
+	ctx context.Context, jobID string, shardIndex int, offset int) (model.ExecutionLogs, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.GetLogs")
+	defer span.End()
+
+	if jobID == "" {
+		return model.ExecutionLogs{}, fmt.Errorf("jobID must be non-empty in a GetLogs call")
+	}
+
+	req := logsRequest{
+		ClientID:   system.GetClientID(),
+		JobID:      jobID,
+		ShardIndex: shardIndex,
+		Offset:     offset,
+	}
+
+	var res logsResponse
+	if err := apiClient.Post(ctx, APIPrefix+"logs", req, &res); err != nil {
+		return model.ExecutionLogs{}, err
+	}
+	return res.Logs, nil
+}
+
 func (apiClient *RequesterAPIClient) GetJobStateResolver() *job.StateResolver {
 	jobLoader := func(ctx context.Context, jobID string) (model.Job, error) {
 		j, _, err := apiClient.Get(ctx, jobID)
@@ -296,6 +332,293 @@ func (apiClient *RequesterAPIClient) Submit(
 	return res.Job, nil
 }
 
+// SubmitWorkflow submits a workflow of jobs with dependencies between them.
+func (apiClient *RequesterAPIClient) SubmitWorkflow(ctx context.Context, spec *model.WorkflowSpec) (model.WorkflowState, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.SubmitWorkflow")
+	defer span.End()
+
+	data := model.WorkflowCreatePayload{
+		ClientID:   system.GetClientID(),
+		APIVersion: model.APIVersionLatest().String(),
+		Spec:       spec,
+	}
+
+	jsonData, err := model.JSONMarshalWithMax(data)
+	if err != nil {
+		return model.WorkflowState{}, err
+	}
+	jsonRaw := json.RawMessage(jsonData)
+
+	// sign the raw bytes representation of model.WorkflowCreatePayload
+	signature, err := system.SignForClient(jsonRaw)
+	if err != nil {
+		return model.WorkflowState{}, err
+	}
+
+	var res workflowStateResponse
+	req := workflowSubmitRequest{
+		WorkflowCreatePayload: &jsonRaw,
+		ClientSignature:       signature,
+		ClientPublicKey:       system.GetClientPublicKey(),
+	}
+
+	if err = apiClient.Post(ctx, APIPrefix+"workflow/submit", req, &res); err != nil {
+		return model.WorkflowState{}, err
+	}
+	return res.State, nil
+}
+
+// GetWorkflowState returns the state of a workflow and its jobs.
+func (apiClient *RequesterAPIClient) GetWorkflowState(ctx context.Context, workflowID string) (model.WorkflowState, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.GetWorkflowState")
+	defer span.End()
+
+	if workflowID == "" {
+		return model.WorkflowState{}, fmt.Errorf("workflowID must be non-empty in a GetWorkflowState call")
+	}
+
+	req := workflowStateRequest{
+		ClientID:   system.GetClientID(),
+		WorkflowID: workflowID,
+	}
+
+	var res workflowStateResponse
+	if err := apiClient.Post(ctx, APIPrefix+"workflow/state", req, &res); err != nil {
+		return model.WorkflowState{}, err
+	}
+	return res.State, nil
+}
+
+// CancelWorkflow cancels a workflow and all its jobs that did not complete yet.
+func (apiClient *RequesterAPIClient) CancelWorkflow(ctx context.Context, workflowID string, reason string) (model.WorkflowState, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.CancelWorkflow")
+	defer span.End()
+
+	if workflowID == "" {
+		return model.WorkflowState{}, fmt.Errorf("workflowID must be non-empty in a CancelWorkflow call")
+	}
+
+	payload := model.WorkflowCancelPayload{
+		ClientID:   system.GetClientID(),
+		WorkflowID: workflowID,
+		Reason:     reason,
+	}
+
+	jsonData, err := model.JSONMarshalWithMax(payload)
+	if err != nil {
+		return model.WorkflowState{}, err
+	}
+	rawPayloadJSON := json.RawMessage(jsonData)
+
+	// sign the raw bytes representation of model.WorkflowCancelPayload
+	signature, err := system.SignForClient(rawPayloadJSON)
+	if err != nil {
+		return model.WorkflowState{}, err
+	}
+
+	req := workflowCancelRequest{
+		WorkflowCancelPayload: &rawPayloadJSON,
+		ClientSignature:       signature,
+		ClientPublicKey:       system.GetClientPublicKey(),
+	}
+
+	var res workflowStateResponse
+	if err := apiClient.Post(ctx, APIPrefix+"workflow/cancel", req, &res); err != nil {
+		return model.WorkflowState{}, err
+	}
+	return res.State, nil
+}
+
+// CreateSchedule creates a schedule that submits a new job at each activation time of its cron expression.
+func (apiClient *RequesterAPIClient) CreateSchedule(ctx context.Context, spec *model.ScheduleSpec) (model.Schedule, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.CreateSchedule")
+	defer span.End()
+
+	data := model.ScheduleCreatePayload{
+		ClientID:   system.GetClientID(),
+		APIVersion: model.APIVersionLatest().String(),
+		Spec:       spec,
+	}
+
+	jsonData, err := model.JSONMarshalWithMax(data)
+	if err != nil {
+		return model.Schedule{}, err
+	}
+	jsonRaw := json.RawMessage(jsonData)
+
+	// sign the raw bytes representation of model.ScheduleCreatePayload
+	signature, err := system.SignForClient(jsonRaw)
+	if err != nil {
+		return model.Schedule{}, err
+	}
+
+	var res scheduleResponse
+	req := scheduleCreateRequest{
+		ScheduleCreatePayload: &jsonRaw,
+		ClientSignature:       signature,
+		ClientPublicKey:       system.GetClientPublicKey(),
+	}
+
+	if err = apiClient.Post(ctx, APIPrefix+"schedule/create", req, &res); err != nil {
+		return model.Schedule{}, err
+	}
+	return res.Schedule, nil
+}
+
+// GetSchedule returns a schedule and the history of its runs.
+func (apiClient *RequesterAPIClient) GetSchedule(ctx context.Context, scheduleID string) (model.Schedule, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.GetSchedule")
+	defer span.End()
+
+	if scheduleID == "" {
+		return model.Schedule{}, fmt.Errorf("scheduleID must be non-empty in a GetSchedule call")
+	}
+
+	req := scheduleGetRequest{
+		ClientID:   system.GetClientID(),
+		ScheduleID: scheduleID,
+	}
+
+	var res scheduleResponse
+	if err := apiClient.Post(ctx, APIPrefix+"schedule/get", req, &res); err != nil {
+		return model.Schedule{}, err
+	}
+	return res.Schedule, nil
+}
+
+// ListSchedules returns the schedules of the client, or of all clients if returnAll is set.
+func (apiClient *RequesterAPIClient) ListSchedules(ctx context.Context, returnAll bool) ([]model.Schedule, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.ListSchedules")
+	defer span.End()
+
+	req := scheduleListRequest{
+		ClientID:  system.GetClientID(),
+		ReturnAll: returnAll,
+	}
+
+	var res scheduleListResponse
+	if err := apiClient.Post(ctx, APIPrefix+"schedule/list", req, &res); err != nil {
+		return nil, err
+	}
+	return res.Schedules, nil
+}
+
+// DeleteSchedule deletes a schedule. Jobs that were already submitted by the schedule keep running.
+func (apiClient *RequesterAPIClient) DeleteSchedule(ctx context.Context, scheduleID string) (model.Schedule, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.DeleteSchedule")
+	defer span.End()
+
+	if scheduleID == "" {
+		return model.Schedule{}, fmt.Errorf("scheduleID must be non-empty in a DeleteSchedule call")
+	}
+
+	payload := model.ScheduleDeletePayload{
+		ClientID:   system.GetClientID(),
+		ScheduleID: scheduleID,
+	}
+
+	jsonData, err := model.JSONMarshalWithMax(payload)
+	if err != nil {
+		return model.Schedule{}, err
+	}
+	rawPayloadJSON := json.RawMessage(jsonData)
+
+	// sign the raw bytes representation of model.ScheduleDeletePayload
+	signature, err := system.SignForClient(rawPayloadJSON)
+	if err != nil {
+		return model.Schedule{}, err
+	}
+
+	req := scheduleDeleteRequest{
+		ScheduleDeletePayload: &rawPayloadJSON,
+		ClientSignature:       signature,
+		ClientPublicKey:       system.GetClientPublicKey(),
+	}
+
+	var res scheduleResponse
+	if err := apiClient.Post(ctx, APIPrefix+"schedule/delete", req, &res); err != nil {
+		return model.Schedule{}, err
+	}
+	return res.Schedule, nil
+}
+
+// GetNodeReputations returns the reputation of a compute node, or of all nodes with recorded outcomes if nodeID
+// is empty.
+func (apiClient *RequesterAPIClient) GetNodeReputations(ctx context.Context, nodeID string) ([]model.NodeReputation, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.GetNodeReputations")
+	defer span.End()
+
+	req := nodeReputationRequest{
+		ClientID: system.GetClientID(),
+		NodeID:   nodeID,
+	}
+
+	var res nodeReputationResponse
+	if err := apiClient.Post(ctx, APIPrefix+"nodes/reputation", req, &res); err != nil {
+		return nil, err
+	}
+	return res.Reputations, nil
+}
+
+// GetClientUsage returns the usage of the current client counted against its limits, and its limits.
+func (apiClient *RequesterAPIClient) GetClientUsage(ctx context.Context) (model.ClientUsage, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.GetClientUsage")
+	defer span.End()
+
+	req := usageRequest{
+		ClientID: system.GetClientID(),
+	}
+
+	var res usageResponse
+	if err := apiClient.Post(ctx, APIPrefix+"usage", req, &res); err != nil {
+		return model.ClientUsage{}, err
+	}
+	return res.Usage, nil
+}
+
+// ResetNodeReputation forgets the recorded outcomes of a compute node, which is only allowed to the operator of the
+// requester node.
+func (apiClient *RequesterAPIClient) ResetNodeReputation(ctx context.Context, nodeID string) (model.NodeReputation, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.ResetNodeReputation")
+	defer span.End()
+
+	if nodeID == "" {
+		return model.NodeReputation{}, fmt.Errorf("nodeID must be non-empty in a ResetNodeReputation call")
+	}
+
+	payload := model.NodeReputationResetPayload{
+		ClientID: system.GetClientID(),
+		NodeID:   nodeID,
+	}
+
+	jsonData, err := model.JSONMarshalWithMax(payload)
+	if err != nil {
+		return model.NodeReputation{}, err
+	}
+	rawPayloadJSON := json.RawMessage(jsonData)
+
+	// sign the raw bytes representation of model.NodeReputationResetPayload
+	signature, err := system.SignForClient(rawPayloadJSON)
+	if err != nil {
+		return model.NodeReputation{}, err
+	}
+
+	req := nodeReputationResetRequest{
+		NodeReputationResetPayload: &rawPayloadJSON,
+		ClientSignature:            signature,
+		ClientPublicKey:            system.GetClientPublicKey(),
+	}
+
+	var res nodeReputationResponse
+	if err := apiClient.Post(ctx, APIPrefix+"nodes/reputation/reset", req, &res); err != nil {
+		return model.NodeReputation{}, err
+	}
+	if len(res.Reputations) == 0 {
+		return model.NodeReputation{NodeID: nodeID}, nil
+	}
+	return res.Reputations[0], nil
+}
+
 func (apiClient *RequesterAPIClient) Debug(ctx context.Context) (map[string]model.DebugInfo, error) {
 	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester/publicapi.RequesterAPIClient.Debug")
 	defer span.End()
@@ -7,6 +7,7 @@ import (
 
 	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
 	"github.com/filecoin-project/bacalhau/pkg/publicapi/handlerwrapper"
 	"github.com/filecoin-project/bacalhau/pkg/requester"
 	"github.com/filecoin-project/bacalhau/pkg/system"
@@ -79,6 +80,10 @@ func (s *RequesterAPIServer) cancel(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	if !s.authorize(ctx, res, auth.Request{Action: auth.ActionCancel, ClientID: jobCancelPayload.ClientID, Signed: true}) {
+		return
+	}
+
 	ctx = system.AddJobIDToBaggage(ctx, jobCancelPayload.ClientID)
 
 	// Get the job, check it exists and check it belongs to the same client
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
 	"github.com/filecoin-project/bacalhau/pkg/publicapi/handlerwrapper"
 )
 
@@ -28,6 +29,8 @@ type eventsResponse struct {
 //	@Param					eventsRequest	body		eventsRequest	true	"Request must specify a `client_id`. To retrieve your `client_id`, you can do the following: (1) submit a dummy job to Bacalhau (or use one you created before), (2) run `bacalhau describe <job-id>` and fetch the `ClientID` field."
 //	@Success				200				{object}	eventsResponse
 //	@Failure				400				{object}	string
+//	@Failure				401				{object}	string
+//	@Failure				403				{object}	string
 //	@Failure				500				{object}	string
 //	@Router					/requester/events [post]
 //
@@ -43,6 +46,10 @@ func (s *RequesterAPIServer) events(res http.ResponseWriter, req *http.Request)
 	res.Header().Set(handlerwrapper.HTTPHeaderJobID, eventsReq.JobID)
 
 	ctx := req.Context()
+	if !s.authorize(ctx, res, auth.Request{Action: auth.ActionEvents, ClientID: eventsReq.ClientID}) {
+		return
+	}
+
 	events, err := s.jobStore.GetJobHistory(ctx, eventsReq.JobID)
 	if err != nil {
 		http.Error(res, err.Error(), http.StatusInternalServerError)
@@ -3,11 +3,14 @@ package publicapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
 	"github.com/filecoin-project/bacalhau/pkg/jobstore"
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
 	"github.com/filecoin-project/bacalhau/pkg/publicapi/handlerwrapper"
 	"github.com/rs/zerolog/log"
 )
@@ -21,12 +24,29 @@ type listRequest struct {
 	ReturnAll   bool                `json:"return_all" `
 	SortBy      string              `json:"sort_by" example:"created_at"`
 	SortReverse bool                `json:"sort_reverse"`
+	// States only returns jobs in one of the given states
+	States []model.JobStateType `json:"states,omitempty" example:"['InProgress']"`
+	// CreatedAfter and CreatedBefore only return jobs created within the given time range, if set
+	CreatedAfter  time.Time `json:"created_after,omitempty"`
+	CreatedBefore time.Time `json:"created_before,omitempty"`
+	// Engines only returns jobs using one of the given engines
+	Engines []model.Engine `json:"engines,omitempty" example:"['Docker']"`
+	// Publishers only returns jobs using one of the given publishers
+	Publishers []model.Publisher `json:"publishers,omitempty" example:"['IPFS']"`
+	// NodeID only returns jobs that were executed by the given compute node
+	NodeID string `json:"node_id,omitempty"`
+	// AnnotationSelectors only returns jobs whose annotations match all the selectors
+	AnnotationSelectors []model.LabelSelectorRequirement `json:"annotation_selectors,omitempty"`
+	// Cursor returns the page of jobs that follows the response that returned it as next_cursor
+	Cursor string `json:"cursor,omitempty"`
 }
 
 type ListRequest = listRequest
 
 type listResponse struct {
 	Jobs []*model.JobWithInfo `json:"jobs"`
+	// NextCursor is set when more jobs match the request, and returns them when passed as cursor
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type ListResponse = listResponse
@@ -42,6 +62,8 @@ type ListResponse = listResponse
 //	@Param					listRequest	body		listRequest	true	"Set `return_all` to `true` to return all jobs on the network (may degrade performance, use with care!)."
 //	@Success				200			{object}	listResponse
 //	@Failure				400			{object}	string
+//	@Failure				401			{object}	string
+//	@Failure				403			{object}	string
 //	@Failure				500			{object}	string
 //	@Router					/requester/list [post]
 //
@@ -56,13 +78,24 @@ func (s *RequesterAPIServer) list(res http.ResponseWriter, req *http.Request) {
 	res.Header().Set(handlerwrapper.HTTPHeaderClientID, listReq.ClientID)
 	res.Header().Set(handlerwrapper.HTTPHeaderJobID, listReq.JobID)
 
-	jobList, err := s.getJobsList(ctx, listReq)
+	if !s.authorize(ctx, res, auth.Request{Action: auth.ActionList, ClientID: listReq.ClientID}) {
+		return
+	}
+
+	jobList, nextCursor, err := s.getJobsList(ctx, listReq)
 	if err != nil {
 		_, ok := err.(*bacerrors.JobNotFound)
 		if ok {
 			http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
 			return
 		}
+		if errors.As(err, &jobstore.ErrInvalidJobQuery{}) {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Ctx(ctx).Error().Err(err).Msg("error listing jobs")
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	jobWithInfos := make([]*model.JobWithInfo, len(jobList))
@@ -70,7 +103,7 @@ func (s *RequesterAPIServer) list(res http.ResponseWriter, req *http.Request) {
 		jobState, innerErr := s.jobStore.GetJobState(ctx, job.Metadata.ID)
 		if innerErr != nil {
 			log.Ctx(ctx).Error().Err(innerErr).Msg("error getting job states")
-			http.Error(res, err.Error(), http.StatusInternalServerError)
+			http.Error(res, innerErr.Error(), http.StatusInternalServerError)
 			return
 		}
 		jobWithInfos[i] = &model.JobWithInfo{
@@ -80,7 +113,8 @@ func (s *RequesterAPIServer) list(res http.ResponseWriter, req *http.Request) {
 	}
 	res.WriteHeader(http.StatusOK)
 	err = json.NewEncoder(res).Encode(ListResponse{
-		Jobs: jobWithInfos,
+		Jobs:       jobWithInfos,
+		NextCursor: nextCursor,
 	})
 	if err != nil {
 		http.Error(res, err.Error(), http.StatusInternalServerError)
@@ -88,19 +122,28 @@ func (s *RequesterAPIServer) list(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func (s *RequesterAPIServer) getJobsList(ctx context.Context, listReq ListRequest) ([]model.Job, error) {
-	list, err := s.jobStore.GetJobs(ctx, jobstore.JobQuery{
-		ClientID:    listReq.ClientID,
-		ID:          listReq.JobID,
-		Limit:       listReq.MaxJobs,
-		IncludeTags: listReq.IncludeTags,
-		ExcludeTags: listReq.ExcludeTags,
-		ReturnAll:   listReq.ReturnAll,
-		SortBy:      listReq.SortBy,
-		SortReverse: listReq.SortReverse,
-	})
+func (s *RequesterAPIServer) getJobsList(ctx context.Context, listReq ListRequest) ([]model.Job, string, error) {
+	query := jobstore.JobQuery{
+		ClientID:            listReq.ClientID,
+		ID:                  listReq.JobID,
+		Limit:               listReq.MaxJobs,
+		IncludeTags:         listReq.IncludeTags,
+		ExcludeTags:         listReq.ExcludeTags,
+		ReturnAll:           listReq.ReturnAll,
+		SortBy:              listReq.SortBy,
+		SortReverse:         listReq.SortReverse,
+		States:              listReq.States,
+		CreatedAfter:        listReq.CreatedAfter,
+		CreatedBefore:       listReq.CreatedBefore,
+		Engines:             listReq.Engines,
+		Publishers:          listReq.Publishers,
+		NodeID:              listReq.NodeID,
+		AnnotationSelectors: listReq.AnnotationSelectors,
+		Cursor:              listReq.Cursor,
+	}
+	list, err := s.jobStore.GetJobs(ctx, query)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return list, nil
+	return list, jobstore.NextJobCursor(query, list), nil
 }
@@ -0,0 +1,73 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/handlerwrapper"
+	"github.com/filecoin-project/bacalhau/pkg/requester"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+)
+
+type logsRequest struct {
+	ClientID   string `json:"client_id" example:"ac13188e93c97a9c2e7cf8e86c7313156a73436036f30da1ececc2ce79f9ea51"`
+	JobID      string `json:"job_id" example:"9304c616-291f-41ad-b862-54e133c0149e"`
+	ShardIndex int    `json:"shard_index" example:"0"`
+	// Offset is the next_offset of the previous response, or zero to read the logs from the start
+	Offset int `json:"offset" example:"0"`
+}
+
+type logsResponse struct {
+	Logs model.ExecutionLogs `json:"logs"`
+}
+
+// logs godoc
+//
+//	@ID			pkg/requester/publicapi/logs
+//	@Summary	Returns the stdout and stderr of the latest execution of a job shard, while it runs.
+//	@Tags		Job
+//	@Accept		json
+//	@Produce	json
+//	@Param		logsRequest	body		logsRequest	true	" "
+//	@Success	200			{object}	logsResponse
+//	@Failure	400			{object}	string
+//	@Failure	401			{object}	string
+//	@Failure	403			{object}	string
+//	@Failure	500			{object}	string
+//	@Router		/requester/logs [post]
+func (s *RequesterAPIServer) logs(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var logsReq logsRequest
+	if err := json.NewDecoder(req.Body).Decode(&logsReq); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, logsReq.ClientID)
+	res.Header().Set(handlerwrapper.HTTPHeaderJobID, logsReq.JobID)
+	ctx = system.AddJobIDToBaggage(ctx, logsReq.JobID)
+
+	if !s.authorize(ctx, res, auth.Request{Action: auth.ActionLogs, ClientID: logsReq.ClientID}) {
+		return
+	}
+
+	logs, err := s.requester.GetLogs(ctx, requester.GetLogsRequest{
+		JobID:      logsReq.JobID,
+		ShardIndex: logsReq.ShardIndex,
+		Offset:     logsReq.Offset,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(logsResponse{
+		Logs: logs,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
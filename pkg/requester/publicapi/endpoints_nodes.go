@@ -0,0 +1,157 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/handlerwrapper"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+)
+
+type nodeReputationRequest struct {
+	ClientID string `json:"client_id" example:"ac13188e93c97a9c2e7cf8e86c7313156a73436036f30da1ececc2ce79f9ea51"`
+	// Only return the reputation of the given compute node, instead of all nodes with recorded outcomes
+	NodeID string `json:"node_id" example:"QmXaXu9N5GNetatsvwnTfQqNtSeKAD6uCmarbh3LMRYAcF"`
+}
+
+type nodeReputationResetRequest struct {
+	// The data needed to reset the reputation of a node
+	NodeReputationResetPayload *json.RawMessage `json:"node_reputation_reset_payload" validate:"required"`
+
+	// A base64-encoded signature of the data, signed by the client:
+	ClientSignature string `json:"signature" validate:"required"`
+
+	// The base64-encoded public key of the client:
+	ClientPublicKey string `json:"client_public_key" validate:"required"`
+}
+
+type nodeReputationResponse struct {
+	Reputations []model.NodeReputation `json:"reputations"`
+}
+
+// nodeReputation godoc
+//
+//	@ID			pkg/requester/publicapi/nodeReputation
+//	@Summary	Returns the reputation of compute nodes, based on the outcomes of their past executions.
+//	@Tags		Node
+//	@Accept		json
+//	@Produce	json
+//	@Param		nodeReputationRequest	body		nodeReputationRequest	true	" "
+//	@Success	200						{object}	nodeReputationResponse
+//	@Failure	400						{object}	string
+//	@Failure	401						{object}	string
+//	@Failure	403						{object}	string
+//	@Failure	500						{object}	string
+//	@Router		/requester/nodes/reputation [post]
+func (s *RequesterAPIServer) nodeReputation(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var reputationReq nodeReputationRequest
+	if err := json.NewDecoder(req.Body).Decode(&reputationReq); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, reputationReq.ClientID)
+
+	if !s.authorize(ctx, res, auth.Request{Action: auth.ActionReputation, ClientID: reputationReq.ClientID}) {
+		return
+	}
+
+	var reputations []model.NodeReputation
+	if reputationReq.NodeID != "" {
+		reputation, err := s.jobStore.GetNodeReputation(ctx, reputationReq.NodeID)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		reputations = append(reputations, reputation)
+	} else {
+		var err error
+		reputations, err = s.jobStore.GetNodeReputations(ctx)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err := json.NewEncoder(res).Encode(nodeReputationResponse{
+		Reputations: reputations,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// nodeReputationReset godoc
+//
+//	@ID			pkg/requester/publicapi/nodeReputationReset
+//	@Summary	Resets the reputation of a compute node. Only the operator of the requester node can reset reputations.
+//	@Tags		Node
+//	@Accept		json
+//	@Produce	json
+//	@Param		nodeReputationResetRequest	body		nodeReputationResetRequest	true	" "
+//	@Success	200							{object}	nodeReputationResponse
+//	@Failure	400							{object}	string
+//	@Failure	401							{object}	string
+//	@Failure	403							{object}	string
+//	@Failure	500							{object}	string
+//	@Router		/requester/nodes/reputation/reset [post]
+func (s *RequesterAPIServer) nodeReputationReset(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var resetReq nodeReputationResetRequest
+	if err := json.NewDecoder(req.Body).Decode(&resetReq); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	// first verify the signature on the raw bytes
+	if err := verifyRequestSignature(
+		*resetReq.NodeReputationResetPayload, resetReq.ClientSignature, resetReq.ClientPublicKey); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	// then decode the reset payload
+	var payload model.NodeReputationResetPayload
+	if err := json.Unmarshal(*resetReq.NodeReputationResetPayload, &payload); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	if err := verifySignedJobRequest(payload.ClientID, resetReq.ClientSignature, resetReq.ClientPublicKey); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusUnauthorized)
+		return
+	}
+
+	// only the client identity the requester node runs with, which is its operator's, can reset reputations
+	if payload.ClientID != system.GetClientID() {
+		err := fmt.Errorf("client %s is not allowed to reset node reputations", payload.ClientID)
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusForbidden)
+		return
+	}
+
+	if err := s.jobStore.ResetNodeReputation(ctx, payload.NodeID); err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	reputation, err := s.jobStore.GetNodeReputation(ctx, payload.NodeID)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(nodeReputationResponse{
+		Reputations: []model.NodeReputation{reputation},
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/filecoin-project/bacalhau/pkg/jobstore"
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
 	"github.com/filecoin-project/bacalhau/pkg/publicapi/handlerwrapper"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 	oteltrace "go.opentelemetry.io/otel/trace"
@@ -31,6 +32,8 @@ type resultsResponse struct {
 //	@Param					stateRequest	body		stateRequest	true	" "
 //	@Success				200				{object}	resultsResponse
 //	@Failure				400				{object}	string
+//	@Failure				401				{object}	string
+//	@Failure				403				{object}	string
 //	@Failure				500				{object}	string
 //	@Router					/requester/results [post]
 func (s *RequesterAPIServer) results(res http.ResponseWriter, req *http.Request) {
@@ -43,6 +46,10 @@ func (s *RequesterAPIServer) results(res http.ResponseWriter, req *http.Request)
 	res.Header().Set(handlerwrapper.HTTPHeaderClientID, stateReq.ClientID)
 	res.Header().Set(handlerwrapper.HTTPHeaderJobID, stateReq.JobID)
 
+	if !s.authorize(ctx, res, auth.Request{Action: auth.ActionResults, ClientID: stateReq.ClientID}) {
+		return
+	}
+
 	ctx = system.AddJobIDToBaggage(ctx, stateReq.JobID)
 	system.AddJobIDFromBaggageToSpan(ctx, oteltrace.SpanFromContext(ctx))
 
@@ -0,0 +1,302 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/job"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/handlerwrapper"
+	"github.com/filecoin-project/bacalhau/pkg/requester"
+	"github.com/rs/zerolog/log"
+)
+
+type scheduleCreateRequest struct {
+	// The data needed to create a schedule:
+	ScheduleCreatePayload *json.RawMessage `json:"schedule_create_payload" validate:"required"`
+
+	// A base64-encoded signature of the data, signed by the client:
+	ClientSignature string `json:"signature" validate:"required"`
+
+	// The base64-encoded public key of the client:
+	ClientPublicKey string `json:"client_public_key" validate:"required"`
+}
+
+type scheduleGetRequest struct {
+	ClientID   string `json:"client_id" example:"ac13188e93c97a9c2e7cf8e86c7313156a73436036f30da1ececc2ce79f9ea51"`
+	ScheduleID string `json:"schedule_id" example:"5a4b1c7e-8f3d-4d2a-b6c1-0e9f8a7b6c5d"`
+}
+
+type scheduleListRequest struct {
+	ClientID string `json:"client_id" example:"ac13188e93c97a9c2e7cf8e86c7313156a73436036f30da1ececc2ce79f9ea51"`
+	// List the schedules of all clients instead of only the schedules of the client
+	ReturnAll bool `json:"return_all"`
+}
+
+type scheduleDeleteRequest struct {
+	// The data needed to delete a schedule
+	ScheduleDeletePayload *json.RawMessage `json:"schedule_delete_payload" validate:"required"`
+
+	// A base64-encoded signature of the data, signed by the client:
+	ClientSignature string `json:"signature" validate:"required"`
+
+	// The base64-encoded public key of the client:
+	ClientPublicKey string `json:"client_public_key" validate:"required"`
+}
+
+type scheduleResponse struct {
+	Schedule model.Schedule `json:"schedule"`
+}
+
+type scheduleListResponse struct {
+	Schedules []model.Schedule `json:"schedules"`
+}
+
+// scheduleCreate godoc
+//
+//	@ID			pkg/requester/publicapi/scheduleCreate
+//	@Summary	Creates a schedule that submits a new job at each activation time of a cron expression.
+//	@Tags		Schedule
+//	@Accept		json
+//	@Produce	json
+//	@Param		scheduleCreateRequest	body		scheduleCreateRequest	true	" "
+//	@Success	200						{object}	scheduleResponse
+//	@Failure	400						{object}	string
+//	@Failure	401						{object}	string
+//	@Failure	403						{object}	string
+//	@Failure	500						{object}	string
+//	@Router		/requester/schedule/create [post]
+func (s *RequesterAPIServer) scheduleCreate(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var createReq scheduleCreateRequest
+	if err := json.NewDecoder(req.Body).Decode(&createReq); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	// first verify the signature on the raw bytes
+	if err := verifyRequestSignature(*createReq.ScheduleCreatePayload, createReq.ClientSignature, createReq.ClientPublicKey); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	// then decode the schedule create payload
+	var payload model.ScheduleCreatePayload
+	if err := json.Unmarshal(*createReq.ScheduleCreatePayload, &payload); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	if err := verifySignedJobRequest(payload.ClientID, createReq.ClientSignature, createReq.ClientPublicKey); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	// authorize the job submitted at each run as if it was submitted on its own
+	if payload.Spec != nil && !s.authorize(ctx, res, auth.Request{
+		Action:   auth.ActionSubmit,
+		ClientID: payload.ClientID,
+		Signed:   true,
+		Spec:     &payload.Spec.JobSpec,
+	}) {
+		return
+	}
+
+	if err := job.VerifyScheduleCreatePayload(ctx, &payload); err != nil {
+		log.Ctx(ctx).Debug().Msgf("====> VerifyScheduleCreatePayload error: %s", err)
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := s.requester.CreateSchedule(ctx, payload)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(scheduleResponse{
+		Schedule: schedule,
+	})
+	if err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// scheduleGet godoc
+//
+//	@ID			pkg/requester/publicapi/scheduleGet
+//	@Summary	Returns a schedule and the history of its runs.
+//	@Tags		Schedule
+//	@Accept		json
+//	@Produce	json
+//	@Param		scheduleGetRequest	body		scheduleGetRequest	true	" "
+//	@Success	200					{object}	scheduleResponse
+//	@Failure	400					{object}	string
+//	@Failure	401					{object}	string
+//	@Failure	403					{object}	string
+//	@Failure	404					{object}	string
+//	@Failure	500					{object}	string
+//	@Router		/requester/schedule/get [post]
+func (s *RequesterAPIServer) scheduleGet(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var getReq scheduleGetRequest
+	if err := json.NewDecoder(req.Body).Decode(&getReq); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, getReq.ClientID)
+
+	if !s.authorize(ctx, res, auth.Request{Action: auth.ActionList, ClientID: getReq.ClientID}) {
+		return
+	}
+
+	schedule, err := s.requester.GetSchedule(ctx, getReq.ScheduleID)
+	if err != nil {
+		http.Error(res, err.Error(), scheduleErrorStatus(err))
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(scheduleResponse{
+		Schedule: schedule,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// scheduleList godoc
+//
+//	@ID			pkg/requester/publicapi/scheduleList
+//	@Summary	Lists the schedules of a client, or of all clients.
+//	@Tags		Schedule
+//	@Accept		json
+//	@Produce	json
+//	@Param		scheduleListRequest	body		scheduleListRequest	true	" "
+//	@Success	200					{object}	scheduleListResponse
+//	@Failure	400					{object}	string
+//	@Failure	401					{object}	string
+//	@Failure	403					{object}	string
+//	@Failure	500					{object}	string
+//	@Router		/requester/schedule/list [post]
+func (s *RequesterAPIServer) scheduleList(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var listReq scheduleListRequest
+	if err := json.NewDecoder(req.Body).Decode(&listReq); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, listReq.ClientID)
+
+	if !s.authorize(ctx, res, auth.Request{Action: auth.ActionList, ClientID: listReq.ClientID}) {
+		return
+	}
+
+	clientID := listReq.ClientID
+	if listReq.ReturnAll {
+		clientID = ""
+	}
+	schedules, err := s.requester.ListSchedules(ctx, clientID)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(scheduleListResponse{
+		Schedules: schedules,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// scheduleDelete godoc
+//
+//	@ID			pkg/requester/publicapi/scheduleDelete
+//	@Summary	Deletes a schedule. Jobs that were already submitted by the schedule keep running.
+//	@Tags		Schedule
+//	@Accept		json
+//	@Produce	json
+//	@Param		scheduleDeleteRequest	body		scheduleDeleteRequest	true	" "
+//	@Success	200						{object}	scheduleResponse
+//	@Failure	400						{object}	string
+//	@Failure	401						{object}	string
+//	@Failure	403						{object}	string
+//	@Failure	404						{object}	string
+//	@Failure	500						{object}	string
+//	@Router		/requester/schedule/delete [post]
+func (s *RequesterAPIServer) scheduleDelete(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var deleteReq scheduleDeleteRequest
+	if err := json.NewDecoder(req.Body).Decode(&deleteReq); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	// first verify the signature on the raw bytes
+	if err := verifyRequestSignature(*deleteReq.ScheduleDeletePayload, deleteReq.ClientSignature, deleteReq.ClientPublicKey); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	// then decode the schedule delete payload
+	var payload model.ScheduleDeletePayload
+	if err := json.Unmarshal(*deleteReq.ScheduleDeletePayload, &payload); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	if err := verifySignedJobRequest(payload.ClientID, deleteReq.ClientSignature, deleteReq.ClientPublicKey); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusUnauthorized)
+		return
+	}
+
+	if !s.authorize(ctx, res, auth.Request{Action: auth.ActionCancel, ClientID: payload.ClientID, Signed: true}) {
+		return
+	}
+
+	// check the schedule exists and belongs to the same client
+	schedule, err := s.requester.GetSchedule(ctx, payload.ScheduleID)
+	if err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), scheduleErrorStatus(err))
+		return
+	}
+	if schedule.ClientID != payload.ClientID {
+		err = fmt.Errorf("mismatched client id: %s", payload.ClientID)
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusForbidden)
+		return
+	}
+
+	schedule, err = s.requester.DeleteSchedule(ctx, payload.ScheduleID)
+	if err != nil {
+		http.Error(res, err.Error(), scheduleErrorStatus(err))
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(scheduleResponse{
+		Schedule: schedule,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func scheduleErrorStatus(err error) int {
+	if errors.As(err, &requester.ErrScheduleNotFound{}) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
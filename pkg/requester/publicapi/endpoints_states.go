@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
 	"github.com/filecoin-project/bacalhau/pkg/publicapi/handlerwrapper"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 )
@@ -30,6 +31,8 @@ type stateResponse struct {
 //	@Param					stateRequest	body		stateRequest	true	" "
 //	@Success				200				{object}	stateResponse
 //	@Failure				400				{object}	string
+//	@Failure				401				{object}	string
+//	@Failure				403				{object}	string
 //	@Failure				500				{object}	string
 //	@Router					/requester/states [post]
 func (s *RequesterAPIServer) states(res http.ResponseWriter, req *http.Request) {
@@ -43,6 +46,10 @@ func (s *RequesterAPIServer) states(res http.ResponseWriter, req *http.Request)
 	res.Header().Set(handlerwrapper.HTTPHeaderJobID, stateReq.JobID)
 	ctx = system.AddJobIDToBaggage(ctx, stateReq.JobID)
 
+	if !s.authorize(ctx, res, auth.Request{Action: auth.ActionStates, ClientID: stateReq.ClientID}) {
+		return
+	}
+
 	js, err := getJobStateFromRequest(ctx, s, stateReq)
 	if err != nil {
 		http.Error(res, err.Error(), http.StatusInternalServerError)
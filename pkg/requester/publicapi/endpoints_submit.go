@@ -8,6 +8,7 @@ import (
 	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
 	"github.com/filecoin-project/bacalhau/pkg/job"
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
 	"github.com/filecoin-project/bacalhau/pkg/publicapi/handlerwrapper"
 	"github.com/filecoin-project/bacalhau/pkg/system"
 	"github.com/rs/zerolog/log"
@@ -40,6 +41,9 @@ type submitResponse struct {
 //	@Param					submitRequest	body		submitRequest	true	" "
 //	@Success				200				{object}	submitResponse
 //	@Failure				400				{object}	string
+//	@Failure				401				{object}	string
+//	@Failure				403				{object}	string
+//	@Failure				429				{object}	string
 //	@Failure				500				{object}	string
 //	@Router					/requester/submit [post]
 func (s *RequesterAPIServer) submit(res http.ResponseWriter, req *http.Request) {
@@ -82,6 +86,15 @@ func (s *RequesterAPIServer) submit(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	if !s.authorize(ctx, res, auth.Request{
+		Action:   auth.ActionSubmit,
+		ClientID: jobCreatePayload.ClientID,
+		Signed:   true,
+		Spec:     jobCreatePayload.Spec,
+	}) {
+		return
+	}
+
 	if err := job.VerifyJobCreatePayload(ctx, &jobCreatePayload); err != nil {
 		log.Ctx(ctx).Debug().Msgf("====> VerifyJobCreate error: %s", err)
 		errorResponse := bacerrors.ErrorToErrorResponse(err)
@@ -98,6 +111,14 @@ func (s *RequesterAPIServer) submit(res http.ResponseWriter, req *http.Request)
 	system.AddJobIDFromBaggageToSpan(ctx, oteltrace.SpanFromContext(ctx))
 
 	if err != nil {
+		if _, ok := err.(*bacerrors.ResultQuotaExceeded); ok {
+			http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusForbidden)
+			return
+		}
+		if _, ok := err.(*bacerrors.ClientLimitExceeded); ok {
+			http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusTooManyRequests)
+			return
+		}
 		http.Error(res, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,61 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/handlerwrapper"
+)
+
+type usageRequest struct {
+	ClientID string `json:"client_id" example:"ac13188e93c97a9c2e7cf8e86c7313156a73436036f30da1ececc2ce79f9ea51"`
+}
+
+type usageResponse struct {
+	Usage model.ClientUsage `json:"usage"`
+}
+
+// usage godoc
+//
+//	@ID			pkg/requester/publicapi/usage
+//	@Summary	Returns the usage of a client counted against its limits, and its limits.
+//	@Tags		Job
+//	@Accept		json
+//	@Produce	json
+//	@Param		usageRequest	body		usageRequest	true	" "
+//	@Success	200				{object}	usageResponse
+//	@Failure	400				{object}	string
+//	@Failure	401				{object}	string
+//	@Failure	403				{object}	string
+//	@Failure	500				{object}	string
+//	@Router		/requester/usage [post]
+func (s *RequesterAPIServer) usage(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var usageReq usageRequest
+	if err := json.NewDecoder(req.Body).Decode(&usageReq); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, usageReq.ClientID)
+
+	if !s.authorize(ctx, res, auth.Request{Action: auth.ActionUsage, ClientID: usageReq.ClientID}) {
+		return
+	}
+
+	usage, err := s.requester.GetClientUsage(ctx, usageReq.ClientID)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(usageResponse{
+		Usage: usage,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
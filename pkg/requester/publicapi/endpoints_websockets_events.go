@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
 	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog/log"
 )
@@ -16,6 +17,15 @@ var upgrader = websocket.Upgrader{
 
 // TODO: Godoc
 func (s *RequesterAPIServer) websocketJobEvents(res http.ResponseWriter, req *http.Request) {
+	// the handler is raw, so the bearer token is read from the request rather than from the context
+	if !s.authorize(req.Context(), res, auth.Request{
+		Action:   auth.ActionEvents,
+		ClientID: req.URL.Query().Get("client_id"),
+		Token:    auth.BearerToken(req),
+	}) {
+		return
+	}
+
 	conn, err := upgrader.Upgrade(res, req, nil)
 	if err != nil {
 		http.Error(res, err.Error(), http.StatusInternalServerError)
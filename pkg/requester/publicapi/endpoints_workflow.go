@@ -0,0 +1,252 @@
+package publicapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/job"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/handlerwrapper"
+	"github.com/filecoin-project/bacalhau/pkg/requester"
+	"github.com/rs/zerolog/log"
+)
+
+type workflowSubmitRequest struct {
+	// The data needed to submit a workflow of jobs to the network:
+	WorkflowCreatePayload *json.RawMessage `json:"workflow_create_payload" validate:"required"`
+
+	// A base64-encoded signature of the data, signed by the client:
+	ClientSignature string `json:"signature" validate:"required"`
+
+	// The base64-encoded public key of the client:
+	ClientPublicKey string `json:"client_public_key" validate:"required"`
+}
+
+type workflowStateRequest struct {
+	ClientID   string `json:"client_id" example:"ac13188e93c97a9c2e7cf8e86c7313156a73436036f30da1ececc2ce79f9ea51"`
+	WorkflowID string `json:"workflow_id" example:"2b6b2b4e-5b6b-4c1a-9a6c-6e2f6c7a3b1d"`
+}
+
+type workflowCancelRequest struct {
+	// The data needed to cancel a workflow
+	WorkflowCancelPayload *json.RawMessage `json:"workflow_cancel_payload" validate:"required"`
+
+	// A base64-encoded signature of the data, signed by the client:
+	ClientSignature string `json:"signature" validate:"required"`
+
+	// The base64-encoded public key of the client:
+	ClientPublicKey string `json:"client_public_key" validate:"required"`
+}
+
+type workflowStateResponse struct {
+	State model.WorkflowState `json:"state"`
+}
+
+// workflowSubmit godoc
+//
+//	@ID			pkg/requester/publicapi/workflowSubmit
+//	@Summary	Submits a workflow of jobs with dependencies between them.
+//	@Tags		Workflow
+//	@Accept		json
+//	@Produce	json
+//	@Param		workflowSubmitRequest	body		workflowSubmitRequest	true	" "
+//	@Success	200						{object}	workflowStateResponse
+//	@Failure	400						{object}	string
+//	@Failure	401						{object}	string
+//	@Failure	403						{object}	string
+//	@Failure	500						{object}	string
+//	@Router		/requester/workflow/submit [post]
+func (s *RequesterAPIServer) workflowSubmit(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var submitReq workflowSubmitRequest
+	if err := json.NewDecoder(req.Body).Decode(&submitReq); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	// first verify the signature on the raw bytes
+	if err := verifyRequestSignature(*submitReq.WorkflowCreatePayload, submitReq.ClientSignature, submitReq.ClientPublicKey); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	// then decode the workflow create payload
+	var payload model.WorkflowCreatePayload
+	if err := json.Unmarshal(*submitReq.WorkflowCreatePayload, &payload); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	if err := verifySignedJobRequest(payload.ClientID, submitReq.ClientSignature, submitReq.ClientPublicKey); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	// authorize each job of the workflow as if it was submitted on its own
+	if payload.Spec != nil {
+		for i := range payload.Spec.Jobs {
+			if !s.authorize(ctx, res, auth.Request{
+				Action:   auth.ActionSubmit,
+				ClientID: payload.ClientID,
+				Signed:   true,
+				Spec:     &payload.Spec.Jobs[i].Spec,
+			}) {
+				return
+			}
+		}
+	}
+
+	if err := job.VerifyWorkflowCreatePayload(ctx, &payload); err != nil {
+		log.Ctx(ctx).Debug().Msgf("====> VerifyWorkflowCreatePayload error: %s", err)
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	state, err := s.requester.SubmitWorkflow(ctx, payload)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(workflowStateResponse{
+		State: state,
+	})
+	if err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusInternalServerError)
+		return
+	}
+}
+
+// workflowState godoc
+//
+//	@ID			pkg/requester/publicapi/workflowState
+//	@Summary	Returns the state of a workflow and its jobs.
+//	@Tags		Workflow
+//	@Accept		json
+//	@Produce	json
+//	@Param		workflowStateRequest	body		workflowStateRequest	true	" "
+//	@Success	200						{object}	workflowStateResponse
+//	@Failure	400						{object}	string
+//	@Failure	401						{object}	string
+//	@Failure	403						{object}	string
+//	@Failure	404						{object}	string
+//	@Failure	500						{object}	string
+//	@Router		/requester/workflow/state [post]
+func (s *RequesterAPIServer) workflowState(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var stateReq workflowStateRequest
+	if err := json.NewDecoder(req.Body).Decode(&stateReq); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, stateReq.ClientID)
+
+	if !s.authorize(ctx, res, auth.Request{Action: auth.ActionStates, ClientID: stateReq.ClientID}) {
+		return
+	}
+
+	state, err := s.requester.GetWorkflowState(ctx, stateReq.WorkflowID)
+	if err != nil {
+		http.Error(res, err.Error(), workflowErrorStatus(err))
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(workflowStateResponse{
+		State: state,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// workflowCancel godoc
+//
+//	@ID			pkg/requester/publicapi/workflowCancel
+//	@Summary	Cancels a workflow and all its jobs that did not complete yet.
+//	@Tags		Workflow
+//	@Accept		json
+//	@Produce	json
+//	@Param		workflowCancelRequest	body		workflowCancelRequest	true	" "
+//	@Success	200						{object}	workflowStateResponse
+//	@Failure	400						{object}	string
+//	@Failure	401						{object}	string
+//	@Failure	403						{object}	string
+//	@Failure	404						{object}	string
+//	@Failure	500						{object}	string
+//	@Router		/requester/workflow/cancel [post]
+func (s *RequesterAPIServer) workflowCancel(res http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	var cancelReq workflowCancelRequest
+	if err := json.NewDecoder(req.Body).Decode(&cancelReq); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	// first verify the signature on the raw bytes
+	if err := verifyRequestSignature(*cancelReq.WorkflowCancelPayload, cancelReq.ClientSignature, cancelReq.ClientPublicKey); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+
+	// then decode the workflow cancel payload
+	var payload model.WorkflowCancelPayload
+	if err := json.Unmarshal(*cancelReq.WorkflowCancelPayload, &payload); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusBadRequest)
+		return
+	}
+	res.Header().Set(handlerwrapper.HTTPHeaderClientID, payload.ClientID)
+
+	if err := verifySignedJobRequest(payload.ClientID, cancelReq.ClientSignature, cancelReq.ClientPublicKey); err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusUnauthorized)
+		return
+	}
+
+	if !s.authorize(ctx, res, auth.Request{Action: auth.ActionCancel, ClientID: payload.ClientID, Signed: true}) {
+		return
+	}
+
+	// check the workflow exists and belongs to the same client
+	state, err := s.requester.GetWorkflowState(ctx, payload.WorkflowID)
+	if err != nil {
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), workflowErrorStatus(err))
+		return
+	}
+	if state.ClientID != payload.ClientID {
+		err = fmt.Errorf("mismatched client id: %s", payload.ClientID)
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusForbidden)
+		return
+	}
+
+	state, err = s.requester.CancelWorkflow(ctx, requester.CancelWorkflowRequest{
+		WorkflowID: payload.WorkflowID,
+		Reason:     payload.Reason,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), workflowErrorStatus(err))
+		return
+	}
+
+	res.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(res).Encode(workflowStateResponse{
+		State: state,
+	})
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func workflowErrorStatus(err error) int {
+	if errors.As(err, &requester.ErrWorkflowNotFound{}) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
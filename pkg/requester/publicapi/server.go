@@ -52,6 +52,17 @@ func (s *RequesterAPIServer) RegisterAllHandlers() error {
 		{URI: "/" + APIPrefix + "events", Handler: http.HandlerFunc(s.events)},
 		{URI: "/" + APIPrefix + "submit", Handler: http.HandlerFunc(s.submit)},
 		{URI: "/" + APIPrefix + "cancel", Handler: http.HandlerFunc(s.cancel)},
+		{URI: "/" + APIPrefix + "logs", Handler: http.HandlerFunc(s.logs)},
+		{URI: "/" + APIPrefix + "workflow/submit", Handler: http.HandlerFunc(s.workflowSubmit)},
+		{URI: "/" + APIPrefix + "workflow/state", Handler: http.HandlerFunc(s.workflowState)},
+		{URI: "/" + APIPrefix + "workflow/cancel", Handler: http.HandlerFunc(s.workflowCancel)},
+		{URI: "/" + APIPrefix + "schedule/create", Handler: http.HandlerFunc(s.scheduleCreate)},
+		{URI: "/" + APIPrefix + "schedule/get", Handler: http.HandlerFunc(s.scheduleGet)},
+		{URI: "/" + APIPrefix + "schedule/list", Handler: http.HandlerFunc(s.scheduleList)},
+		{URI: "/" + APIPrefix + "schedule/delete", Handler: http.HandlerFunc(s.scheduleDelete)},
+		{URI: "/" + APIPrefix + "usage", Handler: http.HandlerFunc(s.usage)},
+		{URI: "/" + APIPrefix + "nodes/reputation", Handler: http.HandlerFunc(s.nodeReputation)},
+		{URI: "/" + APIPrefix + "nodes/reputation/reset", Handler: http.HandlerFunc(s.nodeReputationReset)},
 		{URI: "/" + APIPrefix + "websocket/events", Handler: http.HandlerFunc(s.websocketJobEvents), Raw: true},
 		{URI: "/" + APIPrefix + "debug", Handler: http.HandlerFunc(s.debug)},
 	}
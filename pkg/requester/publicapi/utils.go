@@ -1,11 +1,16 @@
 package publicapi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/publicapi/auth"
 	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/rs/zerolog/log"
 )
 
 func verifyRequestSignature(msg json.RawMessage, clientSignature string, clientPubKey string) error {
@@ -38,3 +43,21 @@ func verifySignedJobRequest(reqClientID string, clientSig string, clientPubKey s
 	}
 	return nil
 }
+
+// authorize checks the request against the API server's auth policy, and writes an error response if the request
+// is not allowed. Returns whether the handler should proceed with the request.
+func (s *RequesterAPIServer) authorize(ctx context.Context, res http.ResponseWriter, request auth.Request) bool {
+	err := s.apiServer.Authorize(ctx, request)
+	if err == nil {
+		return true
+	}
+	log.Ctx(ctx).Debug().Msgf("====> Authorize %s error: %s", request.Action, err)
+	var unauthenticated auth.ErrUnauthenticated
+	if errors.As(err, &unauthenticated) {
+		res.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusUnauthorized)
+		return false
+	}
+	http.Error(res, bacerrors.ErrorToErrorResponse(err), http.StatusForbidden)
+	return false
+}
@@ -0,0 +1,62 @@
+package requester
+
+import (
+	"context"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+)
+
+type ResultQuotaParams struct {
+	JobStore jobstore.Store
+	// DefaultQuota is the total size in bytes of the results each client can store, where zero is unlimited
+	DefaultQuota uint64
+	// ClientQuotas override the default quota of specific clients, by client ID
+	ClientQuotas map[string]uint64
+}
+
+// ResultQuota limits the total size of the results that the jobs of each client store, as reported by the
+// compute nodes that published them. The requester doesn't verify the reported sizes, so the quota is only as
+// reliable as the compute nodes are. Clients that reached their quota can't submit new jobs.
+type ResultQuota struct {
+	jobStore     jobstore.Store
+	defaultQuota uint64
+	clientQuotas map[string]uint64
+}
+
+func NewResultQuota(params ResultQuotaParams) *ResultQuota {
+	return &ResultQuota{
+		jobStore:     params.JobStore,
+		defaultQuota: params.DefaultQuota,
+		clientQuotas: params.ClientQuotas,
+	}
+}
+
+// quota returns the quota of a client, which is zero if it is unlimited.
+func (q *ResultQuota) quota(clientID string) uint64 {
+	if quota, ok := q.clientQuotas[clientID]; ok {
+		return quota
+	}
+	return q.defaultQuota
+}
+
+// Usage returns the total size of the results that the jobs of a client published, as kept by the job store.
+func (q *ResultQuota) Usage(ctx context.Context, clientID string) (uint64, error) {
+	return q.jobStore.GetResultUsage(ctx, clientID)
+}
+
+// Check returns a *bacerrors.ResultQuotaExceeded error if the results of a client already reached its quota.
+func (q *ResultQuota) Check(ctx context.Context, clientID string) error {
+	quota := q.quota(clientID)
+	if quota == 0 {
+		return nil
+	}
+	usage, err := q.Usage(ctx, clientID)
+	if err != nil {
+		return err
+	}
+	if usage >= quota {
+		return bacerrors.NewResultQuotaExceeded(clientID, usage, quota)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type ResultQuotaSuite struct {
+	suite.Suite
+	ctx      context.Context
+	jobStore jobstore.Store
+}
+
+func TestResultQuotaSuite(t *testing.T) {
+	suite.Run(t, new(ResultQuotaSuite))
+}
+
+func (s *ResultQuotaSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.jobStore = inmemory.NewJobStore()
+}
+
+func (s *ResultQuotaSuite) TestUsageOnlyCountsCompletedExecutionsOfClient() {
+	job := s.createJob("client")
+	s.createExecution(job, model.ExecutionStateCompleted, 100)
+	s.createExecution(job, model.ExecutionStateCompleted, 50)
+	s.createExecution(job, model.ExecutionStateFailed, 1000)
+	s.createExecution(s.createJob("other"), model.ExecutionStateCompleted, 1000)
+
+	quota := NewResultQuota(ResultQuotaParams{JobStore: s.jobStore})
+	usage, err := quota.Usage(s.ctx, "client")
+	s.Require().NoError(err)
+	s.Equal(uint64(150), usage)
+}
+
+func (s *ResultQuotaSuite) TestCheck() {
+	s.createExecution(s.createJob("client"), model.ExecutionStateCompleted, 100)
+
+	for _, tc := range []struct {
+		name         string
+		defaultQuota uint64
+		clientQuotas map[string]uint64
+		exceeded     bool
+	}{
+		{name: "unlimited"},
+		{name: "under default quota", defaultQuota: 101},
+		{name: "reached default quota", defaultQuota: 100, exceeded: true},
+		{name: "client quota overrides default", defaultQuota: 10, clientQuotas: map[string]uint64{"client": 1000}},
+		{name: "unlimited client quota", defaultQuota: 10, clientQuotas: map[string]uint64{"client": 0}},
+		{name: "reached client quota", clientQuotas: map[string]uint64{"client": 50}, exceeded: true},
+	} {
+		s.Run(tc.name, func() {
+			quota := NewResultQuota(ResultQuotaParams{
+				JobStore:     s.jobStore,
+				DefaultQuota: tc.defaultQuota,
+				ClientQuotas: tc.clientQuotas,
+			})
+			err := quota.Check(s.ctx, "client")
+			if !tc.exceeded {
+				s.NoError(err)
+				return
+			}
+			s.Require().IsType(&bacerrors.ResultQuotaExceeded{}, err)
+			s.Equal(bacerrors.ErrorCodeResultQuotaExceeded, err.(*bacerrors.ResultQuotaExceeded).Code)
+		})
+	}
+}
+
+func (s *ResultQuotaSuite) createJob(clientID string) model.Job {
+	job := model.Job{
+		APIVersion: model.APIVersionLatest().String(),
+		Metadata:   model.Metadata{ID: uuid.NewString(), ClientID: clientID, CreatedAt: time.Now()},
+		Spec: model.Spec{
+			Engine:        model.EngineNoop,
+			ExecutionPlan: model.JobExecutionPlan{TotalShards: 1},
+			Deal:          model.Deal{Concurrency: 1},
+		},
+	}
+	s.Require().NoError(s.jobStore.CreateJob(s.ctx, job))
+	return job
+}
+
+func (s *ResultQuotaSuite) createExecution(job model.Job, state model.ExecutionStateType, resultSize uint64) {
+	execution := model.ExecutionState{
+		JobID:            job.Metadata.ID,
+		NodeID:           "node-" + uuid.NewString(),
+		ComputeReference: "e-" + uuid.NewString(),
+		State:            model.ExecutionStateResultAccepted,
+	}
+	s.Require().NoError(s.jobStore.CreateExecution(s.ctx, execution))
+	s.Require().NoError(s.jobStore.UpdateExecution(s.ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: execution.ID(),
+		NewValues:   model.ExecutionState{State: state, ResultSize: resultSize},
+	}))
+}
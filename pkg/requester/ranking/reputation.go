@@ -0,0 +1,63 @@
+package ranking
+
+import (
+	"context"
+	"math"
+
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/requester"
+	"github.com/rs/zerolog/log"
+)
+
+// maxReputationRank is the rank of a node with a perfect reputation score
+const maxReputationRank = 10
+
+type ReputationNodeRankerParams struct {
+	JobStore jobstore.Store
+	// MinScore is the reputation score below which nodes are not considered for job execution.
+	// Zero never filters nodes.
+	MinScore float64
+}
+
+// ReputationNodeRanker ranks nodes based on the outcomes of their past executions, as recorded by the requester.
+type ReputationNodeRanker struct {
+	jobStore jobstore.Store
+	minScore float64
+}
+
+func NewReputationNodeRanker(params ReputationNodeRankerParams) *ReputationNodeRanker {
+	return &ReputationNodeRanker{
+		jobStore: params.JobStore,
+		minScore: params.MinScore,
+	}
+}
+
+// RankNodes ranks nodes based on their reputation score:
+// - Rank 0-10: The node's reputation score scaled to 10, where nodes without any recorded outcome rank 5.
+// - Rank -1: The node's reputation score is lower than the minimum score.
+func (s *ReputationNodeRanker) RankNodes(ctx context.Context, job model.Job, nodes []model.NodeInfo) ([]requester.NodeRank, error) {
+	reputations, err := s.jobStore.GetNodeReputations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reputationsMap := make(map[string]model.NodeReputation, len(reputations))
+	for _, reputation := range reputations {
+		reputationsMap[reputation.NodeID] = reputation
+	}
+
+	ranks := make([]requester.NodeRank, len(nodes))
+	for i, node := range nodes {
+		score := reputationsMap[node.PeerInfo.ID.String()].Score()
+		rank := int(math.Round(score * maxReputationRank))
+		if score < s.minScore {
+			log.Ctx(ctx).Trace().Msgf("filtering node %s with reputation score %.2f", node.PeerInfo.ID, score)
+			rank = -1
+		}
+		ranks[i] = requester.NodeRank{
+			NodeInfo: node,
+			Rank:     rank,
+		}
+	}
+	return ranks, nil
+}
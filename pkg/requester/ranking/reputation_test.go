@@ -0,0 +1,55 @@
+package ranking
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReputationNodeRankerSuite struct {
+	suite.Suite
+	jobStore *inmemory.JobStore
+	nodes    []model.NodeInfo
+}
+
+func (s *ReputationNodeRankerSuite) SetupTest() {
+	ctx := context.Background()
+	s.jobStore = inmemory.NewJobStore()
+	s.nodes = []model.NodeInfo{
+		{PeerInfo: peer.AddrInfo{ID: peer.ID("new")}},
+		{PeerInfo: peer.AddrInfo{ID: peer.ID("reliable")}},
+		{PeerInfo: peer.AddrInfo{ID: peer.ID("unreliable")}},
+	}
+	for i := 0; i < 3; i++ {
+		s.Require().NoError(s.jobStore.RecordNodeOutcome(ctx, peer.ID("reliable").String(), model.NodeOutcomeVerificationPassed))
+		s.Require().NoError(s.jobStore.RecordNodeOutcome(ctx, peer.ID("unreliable").String(), model.NodeOutcomeVerificationFailed))
+	}
+}
+
+func TestReputationNodeRankerSuite(t *testing.T) {
+	suite.Run(t, new(ReputationNodeRankerSuite))
+}
+
+func (s *ReputationNodeRankerSuite) TestRankNodes() {
+	ranker := NewReputationNodeRanker(ReputationNodeRankerParams{JobStore: s.jobStore})
+	ranks, err := ranker.RankNodes(context.Background(), model.Job{}, s.nodes)
+	s.NoError(err)
+	s.Equal(len(s.nodes), len(ranks))
+	assertEquals(s.T(), ranks, "new", 5)
+	assertEquals(s.T(), ranks, "reliable", 8)
+	assertEquals(s.T(), ranks, "unreliable", 1)
+}
+
+func (s *ReputationNodeRankerSuite) TestRankNodes_MinScore() {
+	ranker := NewReputationNodeRanker(ReputationNodeRankerParams{JobStore: s.jobStore, MinScore: 0.5})
+	ranks, err := ranker.RankNodes(context.Background(), model.Job{}, s.nodes)
+	s.NoError(err)
+	s.Equal(len(s.nodes), len(ranks))
+	assertEquals(s.T(), ranks, "new", 5)
+	assertEquals(s.T(), ranks, "reliable", 8)
+	assertEquals(s.T(), ranks, "unreliable", -1)
+}
@@ -0,0 +1,334 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/compute"
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/rs/zerolog/log"
+)
+
+type RecoveryParams struct {
+	Scheduler *Scheduler
+	JobStore  jobstore.Store
+	NodeID    string
+	// RetryInterval is the timeout of each attempt to reach a compute node, and the delay between attempts
+	RetryInterval time.Duration
+	// MaxAttempts is the number of attempts to reach a compute node before timing out its executions
+	MaxAttempts int
+}
+
+// Recovery resumes the jobs owned by this requester node after a restart. It walks the in progress jobs
+// in the job store, reconciles each execution with the compute node running it, and re-drives the
+// scheduler's state machine so that the jobs can complete instead of hanging until housekeeping cancels them.
+type Recovery struct {
+	scheduler     *Scheduler
+	jobStore      jobstore.Store
+	nodeID        string
+	retryInterval time.Duration
+	maxAttempts   int
+}
+
+func NewRecovery(params RecoveryParams) *Recovery {
+	return &Recovery{
+		scheduler:     params.Scheduler,
+		jobStore:      params.JobStore,
+		nodeID:        params.NodeID,
+		retryInterval: params.RetryInterval,
+		maxAttempts:   params.MaxAttempts,
+	}
+}
+
+// Recover reconciles all in progress jobs owned by this node, and returns once all of them were processed.
+func (r *Recovery) Recover(ctx context.Context) {
+	jobs, err := r.jobStore.GetInProgressJobs(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[Recover] failed to get in progress jobs")
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, jobDescription := range jobs {
+		// in case the job store is shared between multiple nodes, we only want to recover jobs that are owned by this node
+		if jobDescription.Job.Metadata.Requester.RequesterNodeID != r.nodeID {
+			continue
+		}
+		log.Ctx(ctx).Info().Msgf("recovering in progress job %s", jobDescription.Job.Metadata.ID)
+		wg.Add(1)
+		go func(jobDescription model.JobWithInfo) {
+			defer wg.Done()
+			r.recoverJob(ctx, jobDescription, false)
+		}(jobDescription)
+	}
+	wg.Wait()
+}
+
+// Adopt resumes a job that was owned by another requester node that stopped, once its ownership was transferred to
+// this node. Compute nodes send the callbacks of an execution to the requester node that asked for its bid, so the
+// callbacks of the executions of the job are first redirected to this node, before the job is recovered the same
+// way as the jobs of this node after a restart.
+func (r *Recovery) Adopt(ctx context.Context, jobDescription model.JobWithInfo) {
+	log.Ctx(ctx).Info().Msgf("adopting in progress job %s", jobDescription.Job.Metadata.ID)
+	r.recoverJob(ctx, jobDescription, true)
+}
+
+// recoverJob reconciles the executions of a job and re-drives its state machine. When adopting the job of another
+// requester node, the callbacks of all its executions that reached a compute node are redirected to this node.
+func (r *Recovery) recoverJob(ctx context.Context, jobDescription model.JobWithInfo, adopt bool) {
+	job := jobDescription.Job
+
+	// executions that never received a response to ask for bid, grouped by node to ask each node only once
+	pendingAskForBids := make(map[string][]int)
+	var pendingBidsShards []model.ShardID
+	var pendingVerificationShards []model.ShardID
+	// shards that no node was asked to bid on, such as when the requester stopped right after creating the job
+	var unscheduledShards []int
+	// executions waiting for a callback from their compute node that might have been lost
+	var awaitingCallbacks []model.ExecutionState
+	// executions waiting for a decision of the requester, whose callbacks are redirected when adopting the job
+	var awaitingRequester []model.ExecutionState
+
+	for _, shardState := range jobDescription.State.Shards {
+		if shardState.State.IsTerminal() {
+			continue
+		}
+		shardID := shardState.ID()
+		if len(shardState.Executions) == 0 {
+			unscheduledShards = append(unscheduledShards, shardState.ShardIndex)
+			continue
+		}
+		hasPendingBids := false
+		hasPendingVerifications := false
+		for _, execution := range shardState.Executions {
+			switch execution.State {
+			case model.ExecutionStateAskForBid:
+				pendingAskForBids[execution.NodeID] = append(pendingAskForBids[execution.NodeID], execution.ShardIndex)
+			case model.ExecutionStateAskForBidAccepted:
+				hasPendingBids = true
+				awaitingRequester = append(awaitingRequester, execution)
+			case model.ExecutionStateBidAccepted, model.ExecutionStateResultAccepted:
+				awaitingCallbacks = append(awaitingCallbacks, execution)
+			case model.ExecutionStateResultProposed:
+				// the proposed results are in the job store, so they are verified again by this node
+				hasPendingVerifications = true
+				awaitingRequester = append(awaitingRequester, execution)
+			default:
+			}
+		}
+		if hasPendingBids {
+			pendingBidsShards = append(pendingBidsShards, shardID)
+		}
+		if hasPendingVerifications {
+			pendingVerificationShards = append(pendingVerificationShards, shardID)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, execution := range awaitingCallbacks {
+		wg.Add(1)
+		go func(execution model.ExecutionState) {
+			defer wg.Done()
+			r.reconcileExecution(ctx, execution, adopt)
+		}(execution)
+	}
+	if adopt {
+		for _, execution := range awaitingRequester {
+			wg.Add(1)
+			go func(execution model.ExecutionState) {
+				defer wg.Done()
+				if _, err := r.getComputeState(ctx, execution, true); err != nil {
+					r.failExecution(ctx, execution, fmt.Sprintf("compute node unreachable while adopting job: %s", err))
+				}
+			}(execution)
+		}
+	}
+
+	// ask again for bids that were in flight when the requester stopped
+	for nodeID, shardIndexes := range pendingAskForBids {
+		wg.Add(1)
+		go func(nodeID string, shardIndexes []int) {
+			defer wg.Done()
+			r.scheduler.askForBid(ctx, &job, nodeID, shardIndexes)
+		}(nodeID, shardIndexes)
+	}
+	wg.Wait()
+
+	for _, shardID := range pendingBidsShards {
+		r.scheduler.startAcceptingBidsIfPossible(ctx, shardID)
+	}
+	for _, shardID := range pendingVerificationShards {
+		r.scheduler.startVerificationIfPossible(ctx, shardID)
+	}
+	if len(unscheduledShards) > 0 {
+		r.restartShards(ctx, job, unscheduledShards)
+	}
+}
+
+// restartShards selects nodes and asks them to bid on shards that have no executions, and retries or fails
+// the shards if that is not possible.
+func (r *Recovery) restartShards(ctx context.Context, job model.Job, shardIndexes []int) {
+	log.Ctx(ctx).Info().Msgf("asking for bids on %d shards of job %s that no node was asked to bid on",
+		len(shardIndexes), job.Metadata.ID)
+	err := r.scheduler.restartShards(ctx, job, shardIndexes)
+	if err == nil {
+		return
+	}
+	log.Ctx(ctx).Error().Err(err).Msgf("failed to ask for bids on shards of job %s during requester recovery", job.Metadata.ID)
+	for _, shardIndex := range shardIndexes {
+		r.scheduler.failIfRecoveryIsNotPossible(ctx, model.ShardID{JobID: job.Metadata.ID, Index: shardIndex},
+			fmt.Errorf("failed to ask for bids during requester recovery: %w", err))
+	}
+}
+
+// reconcileExecution compares the state of an execution that is waiting for a compute node callback with
+// the state reported by the compute node, and decides whether to keep waiting, resend a lost notification
+// or fail the execution. The callbacks of the execution are redirected to this node when adopting its job.
+func (r *Recovery) reconcileExecution(ctx context.Context, execution model.ExecutionState, redirect bool) {
+	computeResponse, err := r.getComputeState(ctx, execution, redirect)
+	if err != nil {
+		r.failExecution(ctx, execution, fmt.Sprintf("failed to get execution state during requester recovery: %s", err))
+		return
+	}
+	computeState := computeResponse.State
+	log.Ctx(ctx).Debug().Msgf("execution %s is %s in requester and %s in compute node", execution, execution.State, computeState)
+
+	routingMetadata := compute.RoutingMetadata{
+		SourcePeerID: r.nodeID,
+		TargetPeerID: execution.NodeID,
+	}
+	switch execution.State {
+	case model.ExecutionStateBidAccepted:
+		switch computeState {
+		case store.ExecutionStateCreated:
+			// the compute node never received the bid acceptance
+			request := compute.BidAcceptedRequest{
+				ExecutionID:     execution.ComputeReference,
+				RoutingMetadata: routingMetadata,
+			}
+			response, notifyErr := r.scheduler.computeService.BidAccepted(ctx, request)
+			if notifyErr != nil {
+				r.failExecution(ctx, execution, fmt.Sprintf("failed to notify BidAccepted during requester recovery: %s", notifyErr))
+				return
+			}
+			r.scheduler.eventEmitter.EmitBidAccepted(ctx, request, response)
+		case store.ExecutionStateBidAccepted, store.ExecutionStateRunning:
+			// still running. OnRunComplete will be called when done
+		case store.ExecutionStateWaitingVerification:
+			r.cancelAndFailExecution(ctx, execution, "result proposal was lost while the requester was restarting")
+		default:
+			r.failExecution(ctx, execution, fmt.Sprintf("execution is %s in compute node", computeState))
+		}
+	case model.ExecutionStateResultAccepted:
+		switch computeState {
+		case store.ExecutionStateWaitingVerification:
+			// the compute node never received the result acceptance
+			request := compute.ResultAcceptedRequest{
+				ExecutionID:     execution.ComputeReference,
+				RoutingMetadata: routingMetadata,
+			}
+			response, notifyErr := r.scheduler.computeService.ResultAccepted(ctx, request)
+			if notifyErr != nil {
+				r.failExecution(ctx, execution, fmt.Sprintf("failed to notify ResultAccepted during requester recovery: %s", notifyErr))
+				return
+			}
+			r.scheduler.eventEmitter.EmitResultAccepted(ctx, request, response)
+		case store.ExecutionStateResultAccepted, store.ExecutionStatePublishing:
+			// still publishing. OnPublishComplete will be called when done
+		case store.ExecutionStateCompleted:
+			// the callback with the published result was lost, so complete the execution from the result kept by
+			// the compute node. Nodes that don't report their published result are failed instead.
+			if !model.IsValidStorageSourceType(computeResponse.PublishedResult.StorageSource) {
+				r.failExecution(ctx, execution, "published result was lost while the requester was restarting")
+				return
+			}
+			r.scheduler.OnPublishComplete(ctx, compute.PublishResult{
+				RoutingMetadata: compute.RoutingMetadata{
+					SourcePeerID: execution.NodeID,
+					TargetPeerID: r.nodeID,
+				},
+				ExecutionMetadata: compute.ExecutionMetadata{
+					ExecutionID: execution.ComputeReference,
+					JobID:       execution.JobID,
+					ShardIndex:  execution.ShardIndex,
+				},
+				PublishResult: computeResponse.PublishedResult,
+				ResultSize:    computeResponse.ResultSize,
+			})
+		default:
+			r.failExecution(ctx, execution, fmt.Sprintf("execution is %s in compute node", computeState))
+		}
+	default:
+	}
+}
+
+// getComputeState asks the compute node for the state of the execution, and retries in case the node
+// is not reachable yet, such as when the requester is still discovering and connecting to its peers. Executions
+// the compute node doesn't know about are not retried. If redirect is true, the compute node sends the callbacks
+// of the execution to this node from now on.
+func (r *Recovery) getComputeState(
+	ctx context.Context, execution model.ExecutionState, redirect bool) (compute.ExecutionStateResponse, error) {
+	request := compute.ExecutionStateRequest{
+		ExecutionID:       execution.ComputeReference,
+		RedirectCallbacks: redirect,
+		RoutingMetadata: compute.RoutingMetadata{
+			SourcePeerID: r.nodeID,
+			TargetPeerID: execution.NodeID,
+		},
+	}
+	var err error
+	for attempt := 1; attempt <= r.maxAttempts; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, r.retryInterval)
+		var response compute.ExecutionStateResponse
+		response, err = r.scheduler.computeService.ExecutionState(attemptCtx, request)
+		cancel()
+		if err == nil {
+			if response.Unknown {
+				return response, fmt.Errorf("execution %s is unknown to compute node %s", execution.ComputeReference, execution.NodeID)
+			}
+			return response, nil
+		}
+		log.Ctx(ctx).Debug().Err(err).Msgf("attempt %d to get state of execution %s failed", attempt, execution)
+		if attempt == r.maxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return compute.ExecutionStateResponse{}, ctx.Err()
+		case <-time.After(r.retryInterval):
+		}
+	}
+	return compute.ExecutionStateResponse{}, err
+}
+
+func (r *Recovery) cancelAndFailExecution(ctx context.Context, execution model.ExecutionState, reason string) {
+	r.scheduler.notifyCancel(ctx, reason, execution)
+	r.failExecution(ctx, execution, reason)
+}
+
+func (r *Recovery) failExecution(ctx context.Context, execution model.ExecutionState, reason string) {
+	log.Ctx(ctx).Info().Msgf("failing execution %s during requester recovery: %s", execution, reason)
+	err := r.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: execution.ID(),
+		Condition: jobstore.UpdateExecutionCondition{
+			ExpectedState:   execution.State,
+			ExpectedVersion: execution.Version,
+		},
+		NewValues: model.ExecutionState{
+			State:  model.ExecutionStateFailed,
+			Status: reason,
+		},
+	})
+	if err != nil {
+		// the execution might have progressed through a callback while we were reconciling it
+		log.Ctx(ctx).Error().Err(err).Msgf("[failExecution] failed to update execution")
+		return
+	}
+
+	r.scheduler.failIfRecoveryIsNotPossible(ctx, execution.ShardID(), errors.New(reason))
+}
@@ -0,0 +1,270 @@
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/verifier"
+	"github.com/google/uuid"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	recoveryRequesterID = "requester"
+	recoveryComputeID   = "compute"
+)
+
+type RecoverySuite struct {
+	suite.Suite
+	ctx             context.Context
+	jobStore        jobstore.Store
+	computeEndpoint *testComputeEndpoint
+	nodeDiscoverer  *testNodeDiscoverer
+	recovery        *Recovery
+}
+
+func TestRecoverySuite(t *testing.T) {
+	suite.Run(t, new(RecoverySuite))
+}
+
+func (s *RecoverySuite) SetupTest() {
+	s.ctx = context.Background()
+	s.jobStore = inmemory.NewJobStore()
+	s.computeEndpoint = &testComputeEndpoint{states: make(map[string]store.ExecutionState)}
+	s.nodeDiscoverer = &testNodeDiscoverer{nodeIDs: []string{recoveryComputeID}}
+	scheduler := NewScheduler(SchedulerParams{
+		ID:              recoveryRequesterID,
+		JobStore:        s.jobStore,
+		NodeDiscoverer:  s.nodeDiscoverer,
+		NodeRanker:      s.nodeDiscoverer,
+		ComputeEndpoint: s.computeEndpoint,
+		Verifiers: model.NewMappedProvider(map[model.Verifier]verifier.Verifier{
+			model.VerifierNoop: &testVerifier{},
+		}),
+		EventEmitter: noopEventEmitter(),
+	})
+	s.recovery = NewRecovery(RecoveryParams{
+		Scheduler:     scheduler,
+		JobStore:      s.jobStore,
+		NodeID:        recoveryRequesterID,
+		RetryInterval: 10 * time.Millisecond,
+		MaxAttempts:   2,
+	})
+}
+
+func (s *RecoverySuite) TestRunningExecutionIsKept() {
+	job := s.createJob(recoveryRequesterID)
+	execution := s.createExecution(job, model.ExecutionStateBidAccepted)
+	s.computeEndpoint.states[execution.ComputeReference] = store.ExecutionStateRunning
+
+	s.recovery.Recover(s.ctx)
+	s.Equal(model.ExecutionStateBidAccepted, s.getExecution(execution).State)
+	s.Equal(model.ShardStateInProgress, s.getShardState(job).State)
+}
+
+func (s *RecoverySuite) TestLostBidAcceptanceIsResent() {
+	job := s.createJob(recoveryRequesterID)
+	execution := s.createExecution(job, model.ExecutionStateBidAccepted)
+	s.computeEndpoint.states[execution.ComputeReference] = store.ExecutionStateCreated
+
+	s.recovery.Recover(s.ctx)
+	s.Equal([]string{execution.ComputeReference}, s.computeEndpoint.bidsAccepted)
+	s.Equal(model.ExecutionStateBidAccepted, s.getExecution(execution).State)
+}
+
+func (s *RecoverySuite) TestUnreachableExecutionTimesOut() {
+	job := s.createJob(recoveryRequesterID)
+	execution := s.createExecution(job, model.ExecutionStateBidAccepted)
+
+	s.recovery.Recover(s.ctx)
+	s.Equal(model.ExecutionStateFailed, s.getExecution(execution).State)
+	s.Equal(model.ShardStateError, s.getShardState(job).State)
+}
+
+func (s *RecoverySuite) TestLostRunResultFailsExecution() {
+	job := s.createJob(recoveryRequesterID)
+	execution := s.createExecution(job, model.ExecutionStateBidAccepted)
+	s.computeEndpoint.states[execution.ComputeReference] = store.ExecutionStateWaitingVerification
+
+	s.recovery.Recover(s.ctx)
+	s.Equal(model.ExecutionStateFailed, s.getExecution(execution).State)
+	s.Equal(model.ShardStateError, s.getShardState(job).State)
+}
+
+func (s *RecoverySuite) TestLostPublishedResultCompletesExecution() {
+	job := s.createJob(recoveryRequesterID)
+	execution := s.createExecution(job, model.ExecutionStateResultAccepted)
+	result := model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmResult"}
+	s.computeEndpoint.states[execution.ComputeReference] = store.ExecutionStateCompleted
+	s.computeEndpoint.results = map[string]model.StorageSpec{execution.ComputeReference: result}
+
+	s.recovery.Recover(s.ctx)
+	recovered := s.getExecution(execution)
+	s.Equal(model.ExecutionStateCompleted, recovered.State)
+	s.Equal(result.CID, recovered.PublishedResult.CID)
+	s.Equal(model.ShardStateCompleted, s.getShardState(job).State)
+}
+
+func (s *RecoverySuite) TestCompletedExecutionWithoutResultFails() {
+	job := s.createJob(recoveryRequesterID)
+	execution := s.createExecution(job, model.ExecutionStateResultAccepted)
+	s.computeEndpoint.states[execution.ComputeReference] = store.ExecutionStateCompleted
+
+	s.recovery.Recover(s.ctx)
+	s.Equal(model.ExecutionStateFailed, s.getExecution(execution).State)
+}
+
+func (s *RecoverySuite) TestUnknownExecutionIsNotRetried() {
+	job := s.createJob(recoveryRequesterID)
+	execution := s.createExecution(job, model.ExecutionStateBidAccepted)
+	s.computeEndpoint.unknown = map[string]bool{execution.ComputeReference: true}
+
+	s.recovery.Recover(s.ctx)
+	s.Equal(1, s.computeEndpoint.stateRequests)
+	s.Equal(model.ExecutionStateFailed, s.getExecution(execution).State)
+}
+
+func (s *RecoverySuite) TestInFlightAskForBidIsResent() {
+	job := s.createJob(recoveryRequesterID)
+	s.NoError(s.jobStore.CreateExecution(s.ctx, model.ExecutionState{
+		JobID:      job.Metadata.ID,
+		ShardIndex: 0,
+		NodeID:     recoveryComputeID,
+		State:      model.ExecutionStateAskForBid,
+	}))
+
+	s.recovery.Recover(s.ctx)
+	askedForBids := s.computeEndpoint.getAskedForBids()
+	s.Require().Len(askedForBids, 1)
+	s.Equal([]int{0}, askedForBids[0].ShardIndexes)
+
+	shardState := s.getShardState(job)
+	s.Require().Len(shardState.Executions, 1)
+	s.Equal(model.ExecutionStateBidAccepted, shardState.Executions[0].State)
+}
+
+func (s *RecoverySuite) TestUnreachableNodeFailsAskForBid() {
+	job := s.createJob(recoveryRequesterID)
+	s.NoError(s.jobStore.CreateExecution(s.ctx, model.ExecutionState{
+		JobID:      job.Metadata.ID,
+		ShardIndex: 0,
+		NodeID:     recoveryComputeID,
+		State:      model.ExecutionStateAskForBid,
+	}))
+	s.computeEndpoint.unreachableNodes = map[string]bool{recoveryComputeID: true}
+
+	s.recovery.Recover(s.ctx)
+	shardState := s.getShardState(job)
+	s.Require().Len(shardState.Executions, 1)
+	s.Equal(model.ExecutionStateFailed, shardState.Executions[0].State)
+	s.Equal(model.ShardStateError, shardState.State)
+}
+
+func (s *RecoverySuite) TestShardWithoutExecutionsIsAskedForBids() {
+	job := s.createJob(recoveryRequesterID)
+
+	s.recovery.Recover(s.ctx)
+	s.Eventually(func() bool {
+		shardState := s.getShardState(job)
+		return len(shardState.Executions) == 1 && shardState.Executions[0].State == model.ExecutionStateBidAccepted
+	}, time.Second, 10*time.Millisecond)
+	askedForBids := s.computeEndpoint.getAskedForBids()
+	s.Require().Len(askedForBids, 1)
+	s.Equal(peer.ID(recoveryComputeID).String(), askedForBids[0].TargetPeerID)
+}
+
+func (s *RecoverySuite) TestShardWithoutExecutionsFailsWithoutNodes() {
+	s.nodeDiscoverer.nodeIDs = nil
+	job := s.createJob(recoveryRequesterID)
+
+	s.recovery.Recover(s.ctx)
+	s.Equal(model.ShardStateError, s.getShardState(job).State)
+	s.Empty(s.computeEndpoint.getAskedForBids())
+}
+
+func (s *RecoverySuite) TestAdoptRedirectsRunningExecutions() {
+	job := s.createJob(recoveryRequesterID)
+	execution := s.createExecution(job, model.ExecutionStateBidAccepted)
+	s.computeEndpoint.states[execution.ComputeReference] = store.ExecutionStateRunning
+
+	s.recovery.Adopt(s.ctx, s.getJobWithInfo(job))
+	s.Equal(recoveryRequesterID, s.computeEndpoint.getRedirect(execution.ComputeReference))
+	s.Equal(model.ExecutionStateBidAccepted, s.getExecution(execution).State)
+}
+
+func (s *RecoverySuite) TestAdoptVerifiesProposedResults() {
+	job := s.createJob(recoveryRequesterID)
+	execution := s.createExecution(job, model.ExecutionStateResultProposed)
+	s.computeEndpoint.states[execution.ComputeReference] = store.ExecutionStateWaitingVerification
+
+	s.recovery.Adopt(s.ctx, s.getJobWithInfo(job))
+	s.Equal(recoveryRequesterID, s.computeEndpoint.getRedirect(execution.ComputeReference))
+	s.Equal(model.ExecutionStateResultAccepted, s.getExecution(execution).State)
+}
+
+func (s *RecoverySuite) TestJobsOfOtherRequestersAreIgnored() {
+	job := s.createJob("other-requester")
+	execution := s.createExecution(job, model.ExecutionStateBidAccepted)
+
+	s.recovery.Recover(s.ctx)
+	s.Equal(model.ExecutionStateBidAccepted, s.getExecution(execution).State)
+}
+
+func (s *RecoverySuite) createJob(requesterNodeID string) model.Job {
+	job := model.Job{
+		APIVersion: model.APIVersionLatest().String(),
+		Metadata: model.Metadata{
+			ID:        uuid.NewString(),
+			CreatedAt: time.Now(),
+			Requester: model.JobRequester{RequesterNodeID: requesterNodeID},
+		},
+		Spec: model.Spec{
+			Engine:        model.EngineNoop,
+			Verifier:      model.VerifierNoop,
+			ExecutionPlan: model.JobExecutionPlan{TotalShards: 1},
+			Deal:          model.Deal{Concurrency: 1},
+		},
+	}
+	s.Require().NoError(s.jobStore.CreateJob(s.ctx, job))
+	return job
+}
+
+func (s *RecoverySuite) createExecution(job model.Job, state model.ExecutionStateType) model.ExecutionState {
+	execution := model.ExecutionState{
+		JobID:            job.Metadata.ID,
+		ShardIndex:       0,
+		NodeID:           recoveryComputeID,
+		ComputeReference: "e-" + uuid.NewString(),
+		State:            state,
+	}
+	s.Require().NoError(s.jobStore.CreateExecution(s.ctx, execution))
+	return execution
+}
+
+func (s *RecoverySuite) getExecution(execution model.ExecutionState) model.ExecutionState {
+	for _, e := range s.getShardState(model.Job{Metadata: model.Metadata{ID: execution.JobID}}).Executions {
+		if e.ComputeReference == execution.ComputeReference {
+			return e
+		}
+	}
+	s.FailNow("execution not found")
+	return model.ExecutionState{}
+}
+
+func (s *RecoverySuite) getJobWithInfo(job model.Job) model.JobWithInfo {
+	jobState, err := s.jobStore.GetJobState(s.ctx, job.Metadata.ID)
+	s.Require().NoError(err)
+	return model.JobWithInfo{Job: job, State: jobState}
+}
+
+func (s *RecoverySuite) getShardState(job model.Job) model.ShardState {
+	shardState, err := s.jobStore.GetShardState(s.ctx, model.ShardID{JobID: job.Metadata.ID, Index: 0})
+	s.Require().NoError(err)
+	return shardState
+}
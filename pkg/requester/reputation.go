@@ -0,0 +1,63 @@
+package requester
+
+import (
+	"context"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/compute"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/verifier"
+	"github.com/rs/zerolog/log"
+)
+
+// recordNodeOutcome counts an outcome towards the reputation of a compute node, which is used to rank the node
+// for future jobs.
+func recordNodeOutcome(ctx context.Context, jobStore jobstore.Store, nodeID string, outcome model.NodeOutcome) {
+	err := jobStore.RecordNodeOutcome(ctx, nodeID, outcome)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to record outcome %s of node %s", outcome, nodeID)
+	}
+}
+
+// recordVerificationOutcomes counts the verification results of a shard towards the reputation of the nodes that
+// executed it. Results that were not compared with the results of other nodes say nothing about the nodes, and are
+// not counted.
+func recordVerificationOutcomes(
+	ctx context.Context, jobStore jobstore.Store, trust model.ShardTrust, results []verifier.VerifierResult) {
+	if trust != model.ShardTrustVerified && trust != model.ShardTrustMismatched {
+		return
+	}
+	for _, result := range results {
+		outcome := model.NodeOutcomeVerificationFailed
+		if result.Verified {
+			outcome = model.NodeOutcomeVerificationPassed
+		}
+		recordNodeOutcome(ctx, jobStore, result.Execution.NodeID, outcome)
+	}
+}
+
+// recordTimeoutOutcomes counts a timeout towards the reputation of the nodes that were still executing a job when
+// their executions were canceled as it timed out.
+func recordTimeoutOutcomes(ctx context.Context, jobStore jobstore.Store, cancelledExecutions []model.ExecutionState) {
+	for _, execution := range cancelledExecutions {
+		if execution.State == model.ExecutionStateBidAccepted {
+			recordNodeOutcome(ctx, jobStore, execution.NodeID, model.NodeOutcomeTimeout)
+		}
+	}
+}
+
+// isNodeFailure returns true if the compute node is responsible for a failure it reported, as opposed to failures
+// caused by the job itself, such as images or executables that don't exist, inputs that can't be downloaded or
+// results that exceed the output limits, which would fail on any node.
+func isNodeFailure(failure compute.ComputeError) bool {
+	switch failure.ErrorCode {
+	case bacerrors.ErrorCodeImageNotFound,
+		bacerrors.ErrorCodeExecutableNotFound,
+		bacerrors.ErrorCodeInputDownloadFailed,
+		bacerrors.ErrorCodeOutputLimitExceeded:
+		return false
+	default:
+		return true
+	}
+}
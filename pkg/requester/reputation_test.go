@@ -0,0 +1,93 @@
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
+	"github.com/filecoin-project/bacalhau/pkg/compute"
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordTimeoutOutcomes(t *testing.T) {
+	ctx := context.Background()
+	jobStore := inmemory.NewJobStore()
+
+	recordTimeoutOutcomes(ctx, jobStore, []model.ExecutionState{
+		{NodeID: "running", State: model.ExecutionStateBidAccepted},
+		{NodeID: "proposed", State: model.ExecutionStateResultProposed},
+		{NodeID: "running", State: model.ExecutionStateBidAccepted},
+		{NodeID: "asked", State: model.ExecutionStateAskForBidAccepted},
+	})
+
+	reputations, err := jobStore.GetNodeReputations(ctx)
+	require.NoError(t, err)
+	require.Len(t, reputations, 1)
+	require.Equal(t, "running", reputations[0].NodeID)
+	require.Equal(t, 2, reputations[0].Timeouts)
+}
+
+func TestTimeoutsAreRecordedOnce(t *testing.T) {
+	ctx := context.Background()
+	jobStore := inmemory.NewJobStore()
+	scheduler := NewScheduler(SchedulerParams{
+		ID:              "requester",
+		JobStore:        jobStore,
+		ComputeEndpoint: &testComputeEndpoint{states: make(map[string]store.ExecutionState)},
+		EventEmitter:    noopEventEmitter(),
+	})
+	job := model.Job{
+		APIVersion: model.APIVersionLatest().String(),
+		Metadata:   model.Metadata{ID: uuid.NewString(), CreatedAt: time.Now()},
+		Spec: model.Spec{
+			Engine:        model.EngineNoop,
+			ExecutionPlan: model.JobExecutionPlan{TotalShards: 1},
+			Deal:          model.Deal{Concurrency: 1},
+		},
+	}
+	require.NoError(t, jobStore.CreateJob(ctx, job))
+	require.NoError(t, jobStore.CreateExecution(ctx, model.ExecutionState{
+		JobID:            job.Metadata.ID,
+		NodeID:           "running",
+		ComputeReference: "e-" + uuid.NewString(),
+		State:            model.ExecutionStateBidAccepted,
+	}))
+
+	// housekeeping might cancel a timed out job more than once before the first cancellation completes
+	request := CancelJobRequest{JobID: job.Metadata.ID, Reason: "timed out", TimedOut: true}
+	_, err := scheduler.CancelJob(ctx, request)
+	require.NoError(t, err)
+	_, err = scheduler.CancelJob(ctx, request)
+	require.Error(t, err)
+
+	reputations, err := jobStore.GetNodeReputations(ctx)
+	require.NoError(t, err)
+	require.Len(t, reputations, 1)
+	require.Equal(t, 1, reputations[0].Timeouts)
+}
+
+func TestIsNodeFailure(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		err         error
+		nodeFailure bool
+	}{
+		{name: "image not found", err: bacerrors.NewImageNotFound("image")},
+		{name: "output limit exceeded", err: bacerrors.NewOutputLimitExceeded("", 2, 1)},
+		{name: "input download failed", err: bacerrors.NewInputDownloadFailed("input", context.DeadlineExceeded)},
+		{name: "other error", err: context.DeadlineExceeded, nodeFailure: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			failure := compute.ComputeError{Err: tc.err.Error()}
+			if bacErr, ok := tc.err.(bacerrors.BacalhauErrorInterface); ok {
+				failure.ErrorCode = bacErr.GetCode()
+			}
+			require.Equal(t, tc.nodeFailure, isNodeFailure(failure))
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package requester
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/util/cron"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// maxScheduleRuns is the number of most recent runs kept in the history of a schedule.
+const maxScheduleRuns = 100
+
+type scheduleManagerParams struct {
+	// NodeID is the id of the requester node, which only runs the schedules it created
+	NodeID   string
+	Jobs     workflowJobHandler
+	JobStore jobstore.Store
+	// Interval between checks for due schedules and completion of the jobs of queued runs
+	Interval time.Duration
+}
+
+type schedule struct {
+	cron  cron.Schedule
+	state model.Schedule
+}
+
+// scheduleSubmission is a job to submit for a run of a schedule. The background task collects them while holding
+// the lock, and submits them after releasing it.
+type scheduleSubmission struct {
+	scheduleID  string
+	scheduledAt time.Time
+	payload     model.JobCreatePayload
+	// the job of the previous run that is canceled before submitting, for the cancel previous overlap policy
+	cancelJobID string
+}
+
+// scheduleManager submits a new job at each activation time of the cron expression of a schedule, applies the
+// overlap policy when the job of the previous run is still in progress, and keeps a history of the runs.
+// Schedules and their runs are persisted in the job store, and resumed by the requester node that created them
+// when it restarts. The background task that runs them only runs while some schedules exist.
+// Runs that are missed, for example because the requester was busy or down, are not caught up.
+type scheduleManager struct {
+	nodeID    string
+	jobs      workflowJobHandler
+	jobStore  jobstore.Store
+	interval  time.Duration
+	schedules map[string]*schedule
+	running   bool
+	mu        sync.Mutex
+}
+
+func newScheduleManager(params scheduleManagerParams) *scheduleManager {
+	return &scheduleManager{
+		nodeID:    params.NodeID,
+		jobs:      params.Jobs,
+		jobStore:  params.JobStore,
+		interval:  params.Interval,
+		schedules: make(map[string]*schedule),
+	}
+}
+
+// resume loads the schedules of this node from the job store, and starts running them.
+func (m *scheduleManager) resume(ctx context.Context) error {
+	states, err := m.jobStore.GetSchedules(ctx, m.nodeID)
+	if err != nil {
+		return fmt.Errorf("failed to load schedules: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, state := range states {
+		cronSchedule, err := cron.Parse(state.Spec.Cron)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("failed to resume schedule %s", state.ID)
+			continue
+		}
+		m.schedules[state.ID] = &schedule{cron: cronSchedule, state: state}
+	}
+	if len(m.schedules) > 0 {
+		m.startBackgroundTask()
+	}
+	log.Ctx(ctx).Debug().Msgf("resumed %d schedules", len(m.schedules))
+	return nil
+}
+
+func (m *scheduleManager) create(ctx context.Context, payload model.ScheduleCreatePayload) (model.Schedule, error) {
+	cronSchedule, err := cron.Parse(payload.Spec.Cron)
+	if err != nil {
+		return model.Schedule{}, fmt.Errorf("invalid cron expression: %w", err)
+	}
+	scheduleID, err := uuid.NewRandom()
+	if err != nil {
+		return model.Schedule{}, fmt.Errorf("error creating schedule id: %w", err)
+	}
+
+	now := time.Now().UTC()
+	nextRun := cronSchedule.Next(now)
+	if nextRun.IsZero() {
+		return model.Schedule{}, fmt.Errorf("cron expression %s never runs", payload.Spec.Cron)
+	}
+	s := &schedule{
+		cron: cronSchedule,
+		state: model.Schedule{
+			ID:              scheduleID.String(),
+			ClientID:        payload.ClientID,
+			RequesterNodeID: m.nodeID,
+			APIVersion:      payload.APIVersion,
+			Spec:            *payload.Spec,
+			CreateTime:      now,
+			NextRun:         nextRun,
+		},
+	}
+	if err = m.jobStore.CreateSchedule(ctx, s.state); err != nil {
+		return model.Schedule{}, fmt.Errorf("failed to store schedule: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.schedules[s.state.ID] = s
+	m.startBackgroundTask()
+	log.Ctx(ctx).Debug().Msgf("created schedule %s with cron expression %s", s.state.ID, s.state.Spec.Cron)
+	return copySchedule(s.state), nil
+}
+
+func (m *scheduleManager) get(scheduleID string) (model.Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.schedules[scheduleID]
+	if !ok {
+		return model.Schedule{}, NewErrScheduleNotFound(scheduleID)
+	}
+	return copySchedule(s.state), nil
+}
+
+// list returns the schedules of a client, or all schedules if the client id is empty, ordered by creation time.
+func (m *scheduleManager) list(clientID string) []model.Schedule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := make([]model.Schedule, 0, len(m.schedules))
+	for _, s := range m.schedules {
+		if clientID == "" || s.state.ClientID == clientID {
+			res = append(res, copySchedule(s.state))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreateTime.Before(res[j].CreateTime)
+	})
+	return res
+}
+
+// delete removes a schedule so that no more runs are started. Jobs that were already submitted keep running.
+func (m *scheduleManager) delete(ctx context.Context, scheduleID string) (model.Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.schedules[scheduleID]
+	if !ok {
+		return model.Schedule{}, NewErrScheduleNotFound(scheduleID)
+	}
+	if err := m.jobStore.DeleteSchedule(ctx, scheduleID); err != nil {
+		return model.Schedule{}, fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	delete(m.schedules, scheduleID)
+	return copySchedule(s.state), nil
+}
+
+// startBackgroundTask starts the background task unless it is already running. It is called with the lock held.
+func (m *scheduleManager) startBackgroundTask() {
+	if !m.running {
+		m.running = true
+		go m.backgroundTask()
+	}
+}
+
+// backgroundTask periodically starts the due runs of schedules, and stops once no schedules are left.
+func (m *scheduleManager) backgroundTask() {
+	ctx := context.Background()
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.mu.Lock()
+		if len(m.schedules) == 0 {
+			m.running = false
+			m.mu.Unlock()
+			return
+		}
+		now := time.Now().UTC()
+		var submissions []scheduleSubmission
+		for _, s := range m.schedules {
+			submissions = append(submissions, m.progress(ctx, s, now)...)
+		}
+		m.mu.Unlock()
+
+		for _, submission := range submissions {
+			m.submit(ctx, submission)
+		}
+	}
+}
+
+// progress returns the submission of the queued run of a schedule if the previous job completed, and starts a new
+// run if one is due. It is called with the lock held, and the returned submissions are submitted after releasing it.
+func (m *scheduleManager) progress(ctx context.Context, s *schedule, now time.Time) []scheduleSubmission {
+	var submissions []scheduleSubmission
+	submittingQueued := false
+	if queued := s.queuedRun(); queued != nil && !m.isInProgress(ctx, s.lastJobID()) {
+		submissions = append(submissions, s.submission(queued.ScheduledAt, ""))
+		submittingQueued = true
+	}
+
+	if now.Before(s.state.NextRun) {
+		return submissions
+	}
+	run := model.ScheduleRun{ScheduledAt: s.state.NextRun}
+	// compute the next run from now rather than from the due run, so that missed runs are skipped
+	s.state.NextRun = s.cron.Next(now)
+	s.addRun(run)
+	if submission, ok := m.startRun(ctx, s, &s.state.Runs[len(s.state.Runs)-1], submittingQueued); ok {
+		submissions = append(submissions, submission)
+	}
+	m.save(ctx, s)
+	return submissions
+}
+
+// startRun applies the overlap policy of a schedule to a new run, and returns the submission of its job if it can be
+// started. submittingQueued is set when the queued run of the schedule is about to be submitted, in which case its
+// job counts as in progress.
+func (m *scheduleManager) startRun(
+	ctx context.Context, s *schedule, run *model.ScheduleRun, submittingQueued bool) (scheduleSubmission, bool) {
+	lastJobID := s.lastJobID()
+	if !submittingQueued && !m.isInProgress(ctx, lastJobID) {
+		return s.submission(run.ScheduledAt, ""), true
+	}
+
+	switch s.state.Spec.OverlapPolicy {
+	case model.ScheduleOverlapSkip:
+		run.State = model.ScheduleRunStateSkipped
+		run.Message = fmt.Sprintf("previous job %s is still in progress", lastJobID)
+	case model.ScheduleOverlapQueue:
+		if submittingQueued {
+			run.State = model.ScheduleRunStateQueued
+			run.Message = "waiting for the job of the previous run to complete"
+			return scheduleSubmission{}, false
+		}
+		for i := range s.state.Runs {
+			if &s.state.Runs[i] != run && s.state.Runs[i].State == model.ScheduleRunStateQueued {
+				run.State = model.ScheduleRunStateSkipped
+				run.Message = "a run is already queued"
+				return scheduleSubmission{}, false
+			}
+		}
+		run.State = model.ScheduleRunStateQueued
+		run.Message = fmt.Sprintf("waiting for previous job %s to complete", lastJobID)
+	case model.ScheduleOverlapCancelPrevious:
+		return s.submission(run.ScheduledAt, lastJobID), true
+	default:
+		return s.submission(run.ScheduledAt, ""), true
+	}
+	return scheduleSubmission{}, false
+}
+
+// submit cancels the previous job if needed and submits the job of a run, then records the outcome in the run.
+// It is called without holding the lock, as submitting a job can take a while.
+func (m *scheduleManager) submit(ctx context.Context, submission scheduleSubmission) {
+	if submission.cancelJobID != "" {
+		_, err := m.jobs.CancelJob(ctx, CancelJobRequest{
+			JobID:         submission.cancelJobID,
+			Reason:        fmt.Sprintf("canceled by a new run of schedule %s", submission.scheduleID),
+			UserTriggered: true,
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf(
+				"failed to cancel job %s of schedule %s", submission.cancelJobID, submission.scheduleID)
+		}
+	}
+	job, err := m.jobs.SubmitJob(ctx, submission.payload)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.schedules[submission.scheduleID]
+	if !ok {
+		// the schedule was deleted while the job was being submitted
+		return
+	}
+	run := s.run(submission.scheduledAt)
+	if run == nil {
+		return
+	}
+	if err != nil {
+		run.State = model.ScheduleRunStateError
+		run.Message = fmt.Sprintf("failed to submit job: %s", err)
+	} else {
+		log.Ctx(ctx).Debug().Msgf("submitted job %s for schedule %s", job.Metadata.ID, s.state.ID)
+		run.State = model.ScheduleRunStateSubmitted
+		run.JobID = job.Metadata.ID
+		run.Message = ""
+	}
+	m.save(ctx, s)
+}
+
+// save persists the state of a schedule. It is called with the lock held.
+func (m *scheduleManager) save(ctx context.Context, s *schedule) {
+	if err := m.jobStore.UpdateSchedule(ctx, s.state); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to store schedule %s", s.state.ID)
+	}
+}
+
+// isInProgress returns whether a job submitted by a schedule did not reach a final state yet.
+func (m *scheduleManager) isInProgress(ctx context.Context, jobID string) bool {
+	if jobID == "" {
+		return false
+	}
+	state, err := m.jobStore.GetJobState(ctx, jobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get state of scheduled job %s", jobID)
+		return false
+	}
+	return !state.State.IsTerminal() && state.State != model.JobStatePartialError
+}
+
+// submission returns the submission of a fresh job from the job spec of a schedule, for the run due at the given time.
+func (s *schedule) submission(scheduledAt time.Time, cancelJobID string) scheduleSubmission {
+	spec := copyJobSpec(s.state.Spec.JobSpec)
+	return scheduleSubmission{
+		scheduleID:  s.state.ID,
+		scheduledAt: scheduledAt,
+		payload: model.JobCreatePayload{
+			ClientID:   s.state.ClientID,
+			APIVersion: s.state.APIVersion,
+			Spec:       &spec,
+		},
+		cancelJobID: cancelJobID,
+	}
+}
+
+// lastJobID returns the job submitted by the most recent run, which is used to apply the overlap policy.
+func (s *schedule) lastJobID() string {
+	for i := len(s.state.Runs) - 1; i >= 0; i-- {
+		if s.state.Runs[i].JobID != "" {
+			return s.state.Runs[i].JobID
+		}
+	}
+	return ""
+}
+
+// run returns the run of a schedule that was due at the given time, or nil if it was dropped from the history.
+func (s *schedule) run(scheduledAt time.Time) *model.ScheduleRun {
+	for i := range s.state.Runs {
+		if s.state.Runs[i].ScheduledAt.Equal(scheduledAt) {
+			return &s.state.Runs[i]
+		}
+	}
+	return nil
+}
+
+func (s *schedule) queuedRun() *model.ScheduleRun {
+	for i := range s.state.Runs {
+		if s.state.Runs[i].State == model.ScheduleRunStateQueued {
+			return &s.state.Runs[i]
+		}
+	}
+	return nil
+}
+
+// addRun appends a run to the history of a schedule, and drops the oldest runs beyond the history limit.
+func (s *schedule) addRun(run model.ScheduleRun) {
+	s.state.Runs = append(s.state.Runs, run)
+	if len(s.state.Runs) > maxScheduleRuns {
+		s.state.Runs = append([]model.ScheduleRun{}, s.state.Runs[len(s.state.Runs)-maxScheduleRuns:]...)
+	}
+}
+
+// copyJobSpec copies the storage specs of a job spec, as job transformers modify them in place.
+func copyJobSpec(spec model.Spec) model.Spec {
+	spec.Contexts = append([]model.StorageSpec(nil), spec.Contexts...)
+	spec.Inputs = append([]model.StorageSpec(nil), spec.Inputs...)
+	spec.Outputs = append([]model.StorageSpec(nil), spec.Outputs...)
+	return spec
+}
+
+func copySchedule(state model.Schedule) model.Schedule {
+	state.Runs = append([]model.ScheduleRun{}, state.Runs...)
+	return state
+}
@@ -0,0 +1,217 @@
+package requester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/suite"
+)
+
+type ScheduleSuite struct {
+	suite.Suite
+	ctx      context.Context
+	jobStore jobstore.Store
+	handler  *testWorkflowJobHandler
+	manager  *scheduleManager
+}
+
+func TestScheduleSuite(t *testing.T) {
+	suite.Run(t, new(ScheduleSuite))
+}
+
+func (s *ScheduleSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.jobStore = inmemory.NewJobStore()
+	s.handler = &testWorkflowJobHandler{
+		jobStore:  s.jobStore,
+		submitted: make(map[string]model.Job),
+	}
+	s.manager = newScheduleManager(scheduleManagerParams{
+		NodeID:   "node",
+		Jobs:     s.handler,
+		JobStore: s.jobStore,
+		Interval: time.Hour,
+	})
+}
+
+func (s *ScheduleSuite) TestRunSubmitsJob() {
+	sched := s.create(model.ScheduleOverlapAllow)
+	now := sched.state.NextRun
+
+	s.progress(sched, now)
+	s.Require().Len(sched.state.Runs, 1)
+	run := sched.state.Runs[0]
+	s.Equal(model.ScheduleRunStateSubmitted, run.State)
+	s.Equal(now, run.ScheduledAt)
+	s.Equal(now.Add(time.Minute), sched.state.NextRun)
+	s.Equal("client", s.handler.getSubmitted(run.JobID).Metadata.ClientID)
+}
+
+func (s *ScheduleSuite) TestRunIsNotStartedBeforeDue() {
+	sched := s.create(model.ScheduleOverlapAllow)
+
+	s.progress(sched, sched.state.NextRun.Add(-time.Second))
+	s.Empty(sched.state.Runs)
+}
+
+func (s *ScheduleSuite) TestOverlapAllow() {
+	sched := s.create(model.ScheduleOverlapAllow)
+
+	s.progress(sched, sched.state.NextRun)
+	s.progress(sched, sched.state.NextRun)
+	s.Require().Len(sched.state.Runs, 2)
+	s.Equal(model.ScheduleRunStateSubmitted, sched.state.Runs[1].State)
+	s.NotEqual(sched.state.Runs[0].JobID, sched.state.Runs[1].JobID)
+}
+
+func (s *ScheduleSuite) TestOverlapSkip() {
+	sched := s.create(model.ScheduleOverlapSkip)
+
+	s.progress(sched, sched.state.NextRun)
+	s.progress(sched, sched.state.NextRun)
+	s.Require().Len(sched.state.Runs, 2)
+	s.Equal(model.ScheduleRunStateSkipped, sched.state.Runs[1].State)
+
+	// once the previous job completes, the next run is submitted
+	s.updateJobState(sched.state.Runs[0].JobID, model.JobStateCompleted)
+	s.progress(sched, sched.state.NextRun)
+	s.Require().Len(sched.state.Runs, 3)
+	s.Equal(model.ScheduleRunStateSubmitted, sched.state.Runs[2].State)
+}
+
+func (s *ScheduleSuite) TestOverlapQueue() {
+	sched := s.create(model.ScheduleOverlapQueue)
+
+	s.progress(sched, sched.state.NextRun)
+	s.progress(sched, sched.state.NextRun)
+	s.progress(sched, sched.state.NextRun)
+	s.Require().Len(sched.state.Runs, 3)
+	s.Equal(model.ScheduleRunStateQueued, sched.state.Runs[1].State)
+	s.Equal(model.ScheduleRunStateSkipped, sched.state.Runs[2].State)
+
+	// the queued run is submitted once the previous job completes, without waiting for the next run
+	s.updateJobState(sched.state.Runs[0].JobID, model.JobStateCompleted)
+	s.progress(sched, sched.state.NextRun.Add(-time.Second))
+	s.Equal(model.ScheduleRunStateSubmitted, sched.state.Runs[1].State)
+	s.NotEmpty(sched.state.Runs[1].JobID)
+}
+
+func (s *ScheduleSuite) TestOverlapCancelPrevious() {
+	sched := s.create(model.ScheduleOverlapCancelPrevious)
+
+	s.progress(sched, sched.state.NextRun)
+	s.progress(sched, sched.state.NextRun)
+	s.Require().Len(sched.state.Runs, 2)
+	s.Equal(model.ScheduleRunStateSubmitted, sched.state.Runs[1].State)
+	s.Equal([]string{sched.state.Runs[0].JobID}, s.handler.cancelled)
+}
+
+func (s *ScheduleSuite) TestHistoryIsBounded() {
+	sched := s.create(model.ScheduleOverlapAllow)
+
+	for i := 0; i < maxScheduleRuns+5; i++ {
+		s.progress(sched, sched.state.NextRun)
+	}
+	s.Len(sched.state.Runs, maxScheduleRuns)
+}
+
+func (s *ScheduleSuite) TestListAndDelete() {
+	first := s.create(model.ScheduleOverlapAllow)
+	_, err := s.manager.create(s.ctx, model.ScheduleCreatePayload{
+		ClientID:   "other",
+		APIVersion: model.APIVersionLatest().String(),
+		Spec:       &model.ScheduleSpec{Cron: "@hourly"},
+	})
+	s.Require().NoError(err)
+
+	s.Len(s.manager.list(""), 2)
+	s.Len(s.manager.list("client"), 1)
+
+	deleted, err := s.manager.delete(s.ctx, first.state.ID)
+	s.Require().NoError(err)
+	s.Equal(first.state.ID, deleted.ID)
+	s.Len(s.manager.list(""), 1)
+
+	_, err = s.manager.get(first.state.ID)
+	s.ErrorAs(err, &ErrScheduleNotFound{})
+	_, err = s.jobStore.GetSchedule(s.ctx, first.state.ID)
+	s.ErrorAs(err, &jobstore.ErrScheduleNotFound{})
+}
+
+func (s *ScheduleSuite) TestResumeFromJobStore() {
+	sched := s.create(model.ScheduleOverlapQueue)
+	s.progress(sched, sched.state.NextRun)
+	s.progress(sched, sched.state.NextRun)
+	s.Require().NoError(s.jobStore.CreateSchedule(s.ctx, model.Schedule{
+		ID:              "other",
+		RequesterNodeID: "other-node",
+		Spec:            model.ScheduleSpec{Cron: "@hourly"},
+	}))
+
+	// a restarted node resumes its schedules along with their runs, and only its own schedules
+	restarted := newScheduleManager(scheduleManagerParams{
+		NodeID:   "node",
+		Jobs:     s.handler,
+		JobStore: s.jobStore,
+		Interval: time.Hour,
+	})
+	s.Require().NoError(restarted.resume(s.ctx))
+	schedules := restarted.list("")
+	s.Require().Len(schedules, 1)
+	s.Equal(sched.state.ID, schedules[0].ID)
+	s.Require().Len(schedules[0].Runs, 2)
+	s.Equal(model.ScheduleRunStateSubmitted, schedules[0].Runs[0].State)
+	s.Equal(model.ScheduleRunStateQueued, schedules[0].Runs[1].State)
+
+	// the overlap policy still applies to the job submitted before the restart
+	resumed := restarted.schedules[sched.state.ID]
+	s.Equal(sched.state.Runs[0].JobID, resumed.lastJobID())
+}
+
+func (s *ScheduleSuite) TestJobsAreSubmittedWithoutLock() {
+	s.handler.onSubmit = func() {
+		s.True(s.manager.mu.TryLock(), "job submitted while holding the lock")
+		s.manager.mu.Unlock()
+	}
+	sched := s.create(model.ScheduleOverlapAllow)
+
+	s.progress(sched, sched.state.NextRun)
+	s.Require().Len(sched.state.Runs, 1)
+	s.Equal(model.ScheduleRunStateSubmitted, sched.state.Runs[0].State)
+}
+
+func (s *ScheduleSuite) create(policy model.ScheduleOverlapPolicy) *schedule {
+	created, err := s.manager.create(s.ctx, model.ScheduleCreatePayload{
+		ClientID:   "client",
+		APIVersion: model.APIVersionLatest().String(),
+		Spec: &model.ScheduleSpec{
+			Cron:          "* * * * *",
+			OverlapPolicy: policy,
+		},
+	})
+	s.Require().NoError(err)
+	s.manager.mu.Lock()
+	defer s.manager.mu.Unlock()
+	return s.manager.schedules[created.ID]
+}
+
+// progress runs a check of the schedule at the given time, as the background task would.
+func (s *ScheduleSuite) progress(sched *schedule, now time.Time) {
+	s.manager.mu.Lock()
+	submissions := s.manager.progress(s.ctx, sched, now)
+	s.manager.mu.Unlock()
+	for _, submission := range submissions {
+		s.manager.submit(s.ctx, submission)
+	}
+}
+
+func (s *ScheduleSuite) updateJobState(jobID string, newState model.JobStateType) {
+	s.Require().NoError(s.jobStore.UpdateJobState(s.ctx, jobstore.UpdateJobStateRequest{
+		JobID:    jobID,
+		NewState: newState,
+	}))
+}
@@ -46,6 +46,10 @@ type Scheduler struct {
 	storageProviders storage.StorageProvider
 	eventEmitter     EventEmitter
 	mu               sync.Mutex
+
+	// ctx is canceled when the scheduler stops, which abandons the retries that are waiting for their backoff
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func NewScheduler(params SchedulerParams) *Scheduler {
@@ -60,6 +64,7 @@ func NewScheduler(params SchedulerParams) *Scheduler {
 		storageProviders: params.StorageProviders,
 		eventEmitter:     params.EventEmitter,
 	}
+	res.ctx, res.cancel = context.WithCancel(logger.ContextWithNodeIDLogger(context.Background(), params.ID))
 
 	// TODO: replace with job level lock
 	res.mu.EnableTracerWithOpts(sync.Opts{
@@ -69,17 +74,83 @@ func NewScheduler(params SchedulerParams) *Scheduler {
 	return res
 }
 
+// Stop abandons the retries of failed shards that are waiting for their backoff.
+func (s *Scheduler) Stop() {
+	s.cancel()
+}
+
 func (s *Scheduler) StartJob(ctx context.Context, req StartJobRequest) error {
-	nodeIDs, err := s.nodeDiscoverer.FindNodes(ctx, req.Job)
+	rankedNodes, err := s.rankNodesForJob(ctx, req.Job)
 	if err != nil {
 		return err
 	}
-	log.Ctx(ctx).Debug().Msgf("found %d nodes for job %s", len(nodeIDs), req.Job.Metadata.ID)
+	// spot checked shards are executed by one more node
+	var spotChecked map[int]bool
+	if req.Job.Spec.SpotCheckCount() > 0 {
+		// the seed is stored before the job, so that the job is never sampled without it
+		seed, seedErr := newSpotCheckSeed()
+		if seedErr != nil {
+			return seedErr
+		}
+		if seedErr = s.jobStore.SetSpotCheckSeed(ctx, req.Job.Metadata.ID, seed); seedErr != nil {
+			return fmt.Errorf("error saving spot check seed: %w", seedErr)
+		}
+		spotChecked = spotCheckedShards(req.Job, seed)
+	}
 
-	rankedNodes, err := s.nodeRanker.RankNodes(ctx, req.Job, nodeIDs)
+	err = s.jobStore.CreateJob(ctx, req.Job)
+	if err != nil {
+		return fmt.Errorf("error saving job id: %w", err)
+	}
+	s.eventEmitter.EmitJobCreated(ctx, req.Job)
+
+	// TODO: ask to bid on certain shards rather than asking all compute nodes to bid on all shards
+	shardIndexes := make([]int, req.Job.Spec.ExecutionPlan.TotalShards)
+	for i := 0; i < req.Job.Spec.ExecutionPlan.TotalShards; i++ {
+		shardIndexes[i] = i
+	}
+	s.askForBids(ctx, &req.Job, rankedNodes, shardIndexes, spotChecked)
+	return nil
+}
+
+// restartShards asks for bids again on shards of a job that have no executions, such as when the requester
+// stopped before asking any node to bid on them.
+func (s *Scheduler) restartShards(ctx context.Context, job model.Job, shardIndexes []int) error {
+	rankedNodes, err := s.rankNodesForJob(ctx, job)
 	if err != nil {
 		return err
 	}
+	var spotChecked map[int]bool
+	if job.Spec.SpotCheckCount() > 0 {
+		seed, seedErr := s.spotCheckSeed(ctx, job)
+		if seedErr != nil {
+			return fmt.Errorf("failed to get spot check seed of job %s: %w", job.Metadata.ID, seedErr)
+		}
+		sampled := spotCheckedShards(job, seed)
+		spotChecked = make(map[int]bool)
+		for _, shardIndex := range shardIndexes {
+			if sampled[shardIndex] {
+				spotChecked[shardIndex] = true
+			}
+		}
+	}
+	s.askForBids(ctx, &job, rankedNodes, shardIndexes, spotChecked)
+	return nil
+}
+
+// rankNodesForJob finds and ranks the nodes that can run a job, best first, and returns an error if there are
+// not enough of them to receive the bids the job needs.
+func (s *Scheduler) rankNodesForJob(ctx context.Context, job model.Job) ([]NodeRank, error) {
+	nodeIDs, err := s.nodeDiscoverer.FindNodes(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Debug().Msgf("found %d nodes for job %s", len(nodeIDs), job.Metadata.ID)
+
+	rankedNodes, err := s.nodeRanker.RankNodes(ctx, job, nodeIDs)
+	if err != nil {
+		return nil, err
+	}
 
 	// filter nodes with rank below 0
 	var filteredNodes []NodeRank
@@ -89,29 +160,39 @@ func (s *Scheduler) StartJob(ctx context.Context, req StartJobRequest) error {
 		}
 	}
 	rankedNodes = filteredNodes
-	log.Ctx(ctx).Debug().Msgf("ranked %d nodes for job %s", len(rankedNodes), req.Job.Metadata.ID)
+	log.Ctx(ctx).Debug().Msgf("ranked %d nodes for job %s", len(rankedNodes), job.Metadata.ID)
 
-	minBids := system.Max(req.Job.Spec.Deal.MinBids, req.Job.Spec.Deal.Concurrency)
+	minBids := system.Max(job.Spec.Deal.MinBids, job.Spec.Deal.Concurrency)
 	if len(rankedNodes) < minBids {
-		return NewErrNotEnoughNodes(minBids, len(rankedNodes))
+		return nil, NewErrNotEnoughNodes(minBids, len(rankedNodes))
+	}
+	if job.Spec.SpotCheckCount() > 0 && len(rankedNodes) < job.Spec.Deal.Concurrency+1 {
+		return nil, NewErrNotEnoughNodes(job.Spec.Deal.Concurrency+1, len(rankedNodes))
 	}
 
 	sort.Slice(rankedNodes, func(i, j int) bool {
 		return rankedNodes[i].Rank > rankedNodes[j].Rank
 	})
+	return rankedNodes, nil
+}
 
-	err = s.jobStore.CreateJob(ctx, req.Job)
-	if err != nil {
-		return fmt.Errorf("error saving job id: %w", err)
-	}
-	s.eventEmitter.EmitJobCreated(ctx, req.Job)
-
-	for _, nodeRank := range rankedNodes[:system.Min(len(rankedNodes), minBids*OverAskForBidsFactor)] {
+// askForBids asks the best ranked nodes to bid on the given shards of a job, and extra nodes to bid on the spot
+// checked shards only.
+func (s *Scheduler) askForBids(
+	ctx context.Context, job *model.Job, rankedNodes []NodeRank, shardIndexes []int, spotChecked map[int]bool) {
+	minBids := system.Max(job.Spec.Deal.MinBids, job.Spec.Deal.Concurrency)
+	askedNodes := rankedNodes[:system.Min(len(rankedNodes), minBids*OverAskForBidsFactor)]
+	for _, nodeRank := range askedNodes {
 		// create a new space linked to request context, but call noitfyAskForBid with a new context
 		// as the request context will be canceled the request returns
-		go s.notifyAskForBid(logger.ContextWithNodeIDLogger(context.Background(), s.id), trace.LinkFromContext(ctx), &req.Job, nodeRank.NodeInfo)
+		go s.notifyAskForBid(logger.ContextWithNodeIDLogger(context.Background(), s.id), trace.LinkFromContext(ctx), job,
+			nodeRank.NodeInfo.PeerInfo.ID.String(), shardIndexes)
+	}
+	// extra bids are only asked for the spot checked shards
+	for nodeID, spotCheckedIndexes := range spotCheckBidRequests(spotChecked, rankedNodes[len(askedNodes):]) {
+		go s.notifyAskForBid(logger.ContextWithNodeIDLogger(context.Background(), s.id), trace.LinkFromContext(ctx), job,
+			nodeID, spotCheckedIndexes)
 	}
-	return nil
 }
 
 func (s *Scheduler) CancelJob(ctx context.Context, request CancelJobRequest) (CancelJobResult, error) {
@@ -135,7 +216,10 @@ func (s *Scheduler) CancelJob(ctx context.Context, request CancelJobRequest) (Ca
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	for _, shardID := range shardIDs {
-		s.stopShard(ctx, shardID, request.Reason, request.UserTriggered)
+		cancelledExecutions := s.stopShard(ctx, shardID, request.Reason, request.UserTriggered)
+		if request.TimedOut {
+			recordTimeoutOutcomes(ctx, s.jobStore, cancelledExecutions)
+		}
 	}
 	return CancelJobResult{}, nil
 }
@@ -144,7 +228,7 @@ func (s *Scheduler) CancelJob(ctx context.Context, request CancelJobRequest) (Ca
 //    Shard fsm handlers    //
 //////////////////////////////
 
-func (s *Scheduler) notifyAskForBid(ctx context.Context, link trace.Link, job *model.Job, nodeInfo model.NodeInfo) {
+func (s *Scheduler) notifyAskForBid(ctx context.Context, link trace.Link, job *model.Job, nodeID string, shardIndexes []int) {
 	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/requester.Scheduler.StartJob",
 		trace.WithLinks(link), // link to any api traces
 		trace.WithSpanKind(trace.SpanKindInternal),
@@ -154,19 +238,15 @@ func (s *Scheduler) notifyAskForBid(ctx context.Context, link trace.Link, job *m
 		),
 	)
 	defer span.End()
-	// TODO: ask to bid on certain shards rather than asking all compute nodes to bid on all shards
-	shardIndexes := make([]int, job.Spec.ExecutionPlan.TotalShards)
-	for i := 0; i < job.Spec.ExecutionPlan.TotalShards; i++ {
-		shardIndexes[i] = i
-	}
 
 	// persist the intent to ask the node for a bid, which is helpful to avoid asking an unresponsive node again during retries
 	for _, shardIndex := range shardIndexes {
 		err := s.jobStore.CreateExecution(ctx, model.ExecutionState{
 			JobID:      job.Metadata.ID,
 			ShardIndex: shardIndex,
-			NodeID:     nodeInfo.PeerInfo.ID.String(),
+			NodeID:     nodeID,
 			State:      model.ExecutionStateAskForBid,
+			Attempt:    1,
 		})
 		if err != nil {
 			log.Ctx(ctx).Error().Err(err).Msg("error creating execution")
@@ -174,18 +254,25 @@ func (s *Scheduler) notifyAskForBid(ctx context.Context, link trace.Link, job *m
 		}
 	}
 
-	// do ask for bid
+	s.askForBid(ctx, job, nodeID, shardIndexes)
+}
+
+// askForBid asks a compute node to bid on the given shards, whose executions must already be persisted in the
+// AskForBid state, and handles the responses.
+func (s *Scheduler) askForBid(ctx context.Context, job *model.Job, nodeID string, shardIndexes []int) {
 	request := compute.AskForBidRequest{
 		Job:          *job,
 		ShardIndexes: shardIndexes,
 		RoutingMetadata: compute.RoutingMetadata{
 			SourcePeerID: s.id,
-			TargetPeerID: nodeInfo.PeerInfo.ID.String(),
+			TargetPeerID: nodeID,
 		},
 	}
 	bid, err := s.computeService.AskForBid(ctx, request)
 	if err != nil {
 		log.Ctx(ctx).Error().Err(err).Msgf("failed to ask for bid: %+v", request)
+		s.failAskForBid(ctx, job, nodeID, shardIndexes, fmt.Errorf("failed to ask node %s for bid: %w", nodeID, err))
+		return
 	}
 
 	// handle responses
@@ -194,6 +281,29 @@ func (s *Scheduler) notifyAskForBid(ctx context.Context, link trace.Link, job *m
 	}
 }
 
+// failAskForBid fails the executions of a node that couldn't be asked to bid, such as when the node is
+// unreachable, so that they don't wait for a response forever, and retries or fails their shards if needed.
+func (s *Scheduler) failAskForBid(ctx context.Context, job *model.Job, nodeID string, shardIndexes []int, failure error) {
+	for _, shardIndex := range shardIndexes {
+		executionID := model.ExecutionID{JobID: job.Metadata.ID, ShardIndex: shardIndex, NodeID: nodeID}
+		err := s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+			ExecutionID: executionID,
+			Condition: jobstore.UpdateExecutionCondition{
+				ExpectedState: model.ExecutionStateAskForBid,
+			},
+			NewValues: model.ExecutionState{
+				State:  model.ExecutionStateFailed,
+				Status: failure.Error(),
+			},
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("[failAskForBid] failed to update execution")
+			continue
+		}
+		s.failIfRecoveryIsNotPossible(ctx, executionID.ShardID(), failure)
+	}
+}
+
 func (s *Scheduler) notifyBidAccepted(ctx context.Context, execution model.ExecutionState) {
 	log.Ctx(ctx).Debug().Msgf("Requester node %s responding with BidAccepted for bid: %s", s.id, execution.ComputeReference)
 	err := s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
@@ -220,6 +330,9 @@ func (s *Scheduler) notifyBidAccepted(ctx context.Context, execution model.Execu
 			response, notifyErr := s.computeService.BidAccepted(ctx, request)
 			if notifyErr != nil {
 				log.Ctx(ctx).Error().Err(notifyErr).Msgf("failed to notify BidAccepted for bid: %s", execution.ComputeReference)
+				recordNodeOutcome(ctx, s.jobStore, execution.NodeID, model.NodeOutcomeNoShow)
+				s.failExecution(ctx, execution.ID(), fmt.Errorf("failed to notify BidAccepted: %w", notifyErr))
+				return
 			}
 			s.eventEmitter.EmitBidAccepted(ctx, request, response)
 		}()
@@ -367,6 +480,9 @@ func (s *Scheduler) verifyShard(
 	if err != nil {
 		return nil, err
 	}
+	trust := verifier.GetShardTrust(shard.Job.Spec.Verifier, verificationResults)
+	s.updateShardTrust(ctx, shard.ShardID(), trust)
+	recordVerificationOutcomes(ctx, s.jobStore, trust, verificationResults)
 
 	// we don't fail on first error from the bid queue to avoid a poison pill blocking any progress
 	var verifiedResults []verifier.VerifierResult
@@ -383,6 +499,31 @@ func (s *Scheduler) verifyShard(
 	return verifiedResults, nil
 }
 
+// updateShardTrust records how much the result of a shard can be trusted after it was verified.
+func (s *Scheduler) updateShardTrust(ctx context.Context, shardID model.ShardID, trust model.ShardTrust) {
+	if trust == model.ShardTrustUnknown {
+		return
+	}
+	shardState, err := s.jobStore.GetShardState(ctx, shardID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[updateShardTrust] failed to get shard state")
+		return
+	}
+	err = s.jobStore.UpdateShardState(ctx, jobstore.UpdateShardStateRequest{
+		ShardID: shardID,
+		Condition: jobstore.UpdateShardCondition{
+			ExpectedState:   shardState.State,
+			ExpectedVersion: shardState.Version,
+		},
+		NewState: shardState.State,
+		NewTrust: trust,
+		Comment:  fmt.Sprintf("shard verified with trust %s", trust),
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[updateShardTrust] failed to update shard state")
+	}
+}
+
 // /////////////////////////////
 // Compute callback handlers //
 // /////////////////////////////
@@ -420,10 +561,8 @@ func (s *Scheduler) handleAskForBidResponse(ctx context.Context,
 	// we only notify if we've already received more than MinBids
 	if shardResponse.Accepted {
 		s.eventEmitter.EmitBidReceived(ctx, request, shardResponse)
-		s.startAcceptingBidsIfPossible(ctx, shardResponse)
+		s.startAcceptingBidsIfPossible(ctx, model.ShardID{JobID: shardResponse.JobID, Index: shardResponse.ShardIndex})
 	} else {
-		s.mu.Lock()
-		defer s.mu.Unlock()
 		s.failIfRecoveryIsNotPossible(ctx, model.ShardID{JobID: shardResponse.JobID, Index: shardResponse.ShardIndex},
 			errors.New("not enough bids received"))
 	}
@@ -431,10 +570,9 @@ func (s *Scheduler) handleAskForBidResponse(ctx context.Context,
 
 // startAcceptingBidsIfPossible is called when a compute node has accepted a bid
 // If we have received more than MinBids, we start accepting/rejecting bids, and notify the compute node of the decision
-func (s *Scheduler) startAcceptingBidsIfPossible(ctx context.Context, shardResponse compute.AskForBidShardResponse) {
+func (s *Scheduler) startAcceptingBidsIfPossible(ctx context.Context, shardID model.ShardID) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	shardID := model.ShardID{JobID: shardResponse.JobID, Index: shardResponse.ShardIndex}
 	shardState, err := s.jobStore.GetShardState(ctx, shardID)
 	if err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("[startAcceptingBidsIfPossible] failed to get shard state")
@@ -459,16 +597,21 @@ func (s *Scheduler) startAcceptingBidsIfPossible(ctx context.Context, shardRespo
 		}
 	}
 
-	job, err := s.jobStore.GetJob(ctx, shardResponse.JobID)
+	job, err := s.jobStore.GetJob(ctx, shardID.JobID)
 	if err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("[startAcceptingBidsIfPossible] failed to get job")
 		return
 	}
+	concurrency, err := s.shardConcurrency(ctx, job, shardID.Index)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[startAcceptingBidsIfPossible] failed to get shard concurrency")
+		return
+	}
 	// if we have more than MinBids, we start selecting the best bids and notify the compute nodes
 	if receivedBidsCount >= job.Spec.Deal.MinBids {
 		// TODO: we should verify a bid acceptance was received by the compute node before rejecting other bids
 		for _, candidate := range pendingBids {
-			if activeExecutionsCount < job.Spec.Deal.Concurrency {
+			if activeExecutionsCount < concurrency {
 				s.notifyBidAccepted(ctx, candidate)
 				activeExecutionsCount++
 			} else {
@@ -509,13 +652,21 @@ func (s *Scheduler) OnRunComplete(ctx context.Context, result compute.RunResult)
 }
 
 func (s *Scheduler) startVerificationIfPossible(ctx context.Context, shardID model.ShardID) {
+	if failure := s.verifyShardIfPossible(ctx, shardID); failure != nil {
+		s.failIfRecoveryIsNotPossible(ctx, shardID, failure)
+	}
+}
+
+// verifyShardIfPossible verifies the results of a shard once enough were proposed, and returns why the
+// verification failed, if it did.
+func (s *Scheduler) verifyShardIfPossible(ctx context.Context, shardID model.ShardID) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	// check if we gathered enough results to start verification
 	shardState, err := s.jobStore.GetShardState(ctx, shardID)
 	if err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("[startVerificationIfPossible] failed to get shard state")
-		return
+		return nil
 	}
 
 	var pendingVerifications []model.ExecutionState
@@ -530,22 +681,26 @@ func (s *Scheduler) startVerificationIfPossible(ctx context.Context, shardID mod
 	shard := model.JobShard{Job: &job, Index: shardID.Index}
 	if err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("[startAcceptingBidsIfPossible] failed to get job")
-		return
+		return nil
+	}
+	concurrency, err := s.shardConcurrency(ctx, job, shardID.Index)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[startVerificationIfPossible] failed to get shard concurrency")
+		return nil
 	}
 	// TODO: technically we can start verifying if we have enough results compared to deal's confidence
 	//  and concurrency. Though we will have ot handle the case where verification fails, but can still
 	//  succeed if we wait for more results.
-	if len(pendingVerifications) >= job.Spec.Deal.Concurrency {
+	if len(pendingVerifications) >= concurrency {
 		verifiedResults, verificationErr := s.verifyShard(ctx, shard, pendingVerifications)
 		if verificationErr != nil {
-			s.failIfRecoveryIsNotPossible(ctx, shardID, fmt.Errorf("failed to verify shard %s: %w", shardID, verificationErr))
-			return
+			return fmt.Errorf("failed to verify shard %s: %w", shardID, verificationErr)
 		}
 		if len(verifiedResults) == 0 {
-			s.failIfRecoveryIsNotPossible(ctx, shardID, fmt.Errorf("failed to verify shard %s: no verified results", shardID))
-			return
+			return fmt.Errorf("failed to verify shard %s: no verified results", shardID)
 		}
 	}
+	return nil
 }
 
 func (s *Scheduler) OnPublishComplete(ctx context.Context, result compute.PublishResult) {
@@ -568,6 +723,7 @@ func (s *Scheduler) OnPublishComplete(ctx context.Context, result compute.Publis
 		},
 		NewValues: model.ExecutionState{
 			PublishedResult: result.PublishResult,
+			ResultSize:      result.ResultSize,
 			State:           model.ExecutionStateCompleted,
 		},
 	})
@@ -632,16 +788,207 @@ func (s *Scheduler) OnComputeFailure(ctx context.Context, result compute.Compute
 	}
 
 	s.eventEmitter.EmitComputeFailure(ctx, result)
+	if isNodeFailure(result) {
+		recordNodeOutcome(ctx, s.jobStore, result.SourcePeerID, model.NodeOutcomeComputeFailure)
+	}
+	s.failIfRecoveryIsNotPossible(ctx, model.ShardID{JobID: result.JobID, Index: result.ShardIndex}, result)
+}
+
+// failExecution marks an execution that was accepted by the requester as failed, such as when the compute
+// node couldn't be notified of the bid acceptance, and fails or retries the shard if needed.
+func (s *Scheduler) failExecution(ctx context.Context, executionID model.ExecutionID, failure error) {
+	err := s.jobStore.UpdateExecution(ctx, jobstore.UpdateExecutionRequest{
+		ExecutionID: executionID,
+		Condition: jobstore.UpdateExecutionCondition{
+			ExpectedState: model.ExecutionStateBidAccepted,
+		},
+		NewValues: model.ExecutionState{
+			State:  model.ExecutionStateFailed,
+			Status: failure.Error(),
+		},
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[failExecution] failed to update execution")
+		return
+	}
+
+	s.failIfRecoveryIsNotPossible(ctx, executionID.ShardID(), failure)
+}
+
+// failIfRecoveryIsNotPossible retries a shard that no longer has enough active executions if the job's retry
+// policy allows it, or stops the shard otherwise. It acquires the lock itself, and releases it while looking for
+// nodes to retry the shard on, so make sure to call it without the lock held.
+func (s *Scheduler) failIfRecoveryIsNotPossible(ctx context.Context, shardID model.ShardID, failure error) {
+	s.mu.Lock()
+	if s.isRecoveryStillPossible(ctx, shardID) {
+		s.mu.Unlock()
+		return
+	}
+	job, canRetry := s.canRetry(ctx, shardID)
+	s.mu.Unlock()
+
+	// finding and ranking nodes can take a while, so it is done without holding the lock, and the shard is checked
+	// again once the lock is acquired again
+	var rankedNodes []NodeRank
+	if canRetry {
+		rankedNodes = s.rankNodesForRetry(ctx, job, shardID)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.failIfRecoveryIsNotPossible(ctx, model.ShardID{JobID: result.JobID, Index: result.ShardIndex}, result)
+	if s.isRecoveryStillPossible(ctx, shardID) {
+		// another execution of the shard was started while the lock was released, such as by a concurrent retry
+		return
+	}
+	if len(rankedNodes) > 0 && s.retryIfPossible(ctx, job, shardID, rankedNodes, failure) {
+		return
+	}
+	s.stopShard(ctx, shardID, failure.Error(), false)
 }
 
+// canRetry returns the job of a shard, and whether the shard has attempts left according to the job's retry policy.
 // make sure to call this function with the lock held
-func (s *Scheduler) failIfRecoveryIsNotPossible(ctx context.Context, shardID model.ShardID, failure error) {
-	if !s.isRecoveryStillPossible(ctx, shardID) {
-		s.stopShard(ctx, shardID, failure.Error(), false)
+func (s *Scheduler) canRetry(ctx context.Context, shardID model.ShardID) (model.Job, bool) {
+	job, err := s.jobStore.GetJob(ctx, shardID.JobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[canRetry] failed to get job")
+		return model.Job{}, false
+	}
+	shardState, err := s.jobStore.GetShardState(ctx, shardID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[canRetry] failed to get shard state")
+		return model.Job{}, false
+	}
+	return job, !shardState.State.IsTerminal() && shardState.Attempt()+1 <= job.Spec.Deal.RetryPolicy.MaxAttempts
+}
+
+// rankNodesForRetry finds and ranks the nodes that could run a failed shard.
+// It is called without holding the lock, as node discovery and ranking can query other nodes.
+func (s *Scheduler) rankNodesForRetry(ctx context.Context, job model.Job, shardID model.ShardID) []NodeRank {
+	nodeIDs, err := s.nodeDiscoverer.FindNodes(ctx, job)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[rankNodesForRetry] failed to find nodes for shard %s", shardID)
+		return nil
+	}
+	rankedNodes, err := s.nodeRanker.RankNodes(ctx, job, nodeIDs)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[rankNodesForRetry] failed to rank nodes for shard %s", shardID)
+		return nil
+	}
+	return rankedNodes
+}
+
+// retryIfPossible reschedules a failed shard on the ranked nodes if the job's retry policy allows it.
+// The new executions are persisted right away so that the shard is not retried twice by concurrent failures,
+// and the nodes are asked to bid after the policy's backoff.
+// make sure to call this function with the lock held
+func (s *Scheduler) retryIfPossible(
+	ctx context.Context, job model.Job, shardID model.ShardID, rankedNodes []NodeRank, failure error) bool {
+	// the shard is read again, as it might have changed while the nodes were ranked without holding the lock
+	shardState, err := s.jobStore.GetShardState(ctx, shardID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[retryIfPossible] failed to get shard state")
+		return false
+	}
+	if shardState.State.IsTerminal() {
+		return false
+	}
+	retryPolicy := job.Spec.Deal.RetryPolicy
+	attempt := shardState.Attempt() + 1
+	if attempt > retryPolicy.MaxAttempts {
+		return false
+	}
+
+	activeExecutions := 0
+	excludedNodes := make(map[string]bool)
+	for _, execution := range shardState.Executions {
+		if !execution.State.IsDiscarded() {
+			activeExecutions++
+			excludedNodes[execution.NodeID] = true
+		}
+		// nodes that never accepted to bid are not asked again, as they are likely to reject the shard again
+		if !execution.HasAcceptedAskForBid() {
+			excludedNodes[execution.NodeID] = true
+		}
+		if retryPolicy.ExcludeFailedNodes && execution.State == model.ExecutionStateFailed {
+			excludedNodes[execution.NodeID] = true
+		}
 	}
+
+	var candidates []NodeRank
+	for _, node := range rankedNodes {
+		if node.Rank >= 0 && !excludedNodes[node.NodeInfo.PeerInfo.ID.String()] {
+			candidates = append(candidates, node)
+		}
+	}
+	concurrency, err := s.shardConcurrency(ctx, job, shardID.Index)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[retryIfPossible] failed to get concurrency of shard %s", shardID)
+		return false
+	}
+	requiredBids := concurrency - activeExecutions
+	if len(candidates) < requiredBids {
+		log.Ctx(ctx).Debug().Msgf("not enough nodes to retry shard %s. Found %d, required %d",
+			shardID, len(candidates), requiredBids)
+		return false
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Rank > candidates[j].Rank
+	})
+	candidates = candidates[:system.Min(len(candidates), requiredBids*OverAskForBidsFactor)]
+
+	// record the attempt in the shard's history
+	err = s.jobStore.UpdateShardState(ctx, jobstore.UpdateShardStateRequest{
+		ShardID: shardID,
+		Condition: jobstore.UpdateShardCondition{
+			ExpectedState:   shardState.State,
+			ExpectedVersion: shardState.Version,
+		},
+		NewState: shardState.State,
+		Comment: fmt.Sprintf("retrying shard on %d nodes (attempt %d of %d) due to: %s",
+			len(candidates), attempt, retryPolicy.MaxAttempts, failure),
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("[retryIfPossible] failed to update shard state")
+		return false
+	}
+
+	var askedNodes []string
+	for _, node := range candidates {
+		nodeID := node.NodeInfo.PeerInfo.ID.String()
+		err = s.jobStore.CreateExecution(ctx, model.ExecutionState{
+			JobID:      shardID.JobID,
+			ShardIndex: shardID.Index,
+			NodeID:     nodeID,
+			State:      model.ExecutionStateAskForBid,
+			Attempt:    attempt,
+		})
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("[retryIfPossible] error creating execution")
+			continue
+		}
+		askedNodes = append(askedNodes, nodeID)
+	}
+	if len(askedNodes) == 0 {
+		return false
+	}
+
+	log.Ctx(ctx).Info().Msgf("retrying shard %s on %d nodes (attempt %d of %d)",
+		shardID, len(askedNodes), attempt, retryPolicy.MaxAttempts)
+	go func() {
+		timer := time.NewTimer(retryPolicy.BackoffDuration(attempt))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-s.ctx.Done():
+			log.Ctx(s.ctx).Debug().Msgf("abandoned retry of shard %s as the scheduler stopped", shardID)
+			return
+		}
+		for _, nodeID := range askedNodes {
+			go s.askForBid(s.ctx, &job, nodeID, []int{shardID.Index})
+		}
+	}()
+	return true
 }
 
 func (s *Scheduler) isRecoveryStillPossible(ctx context.Context, shardID model.ShardID) bool {
@@ -663,11 +1010,18 @@ func (s *Scheduler) isRecoveryStillPossible(ctx context.Context, shardID model.S
 		}
 	}
 
-	return activeExecutions >= job.Spec.Deal.Concurrency
+	concurrency, err := s.shardConcurrency(ctx, job, shardID.Index)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[isRecoveryStillPossible] failed to get shard concurrency")
+		return false
+	}
+	return activeExecutions >= concurrency
 }
 
 // make sure to call this function with the lock held
-func (s *Scheduler) stopShard(ctx context.Context, shardID model.ShardID, reason string, userRequested bool) {
+// stopShard cancels or fails a shard, and returns the executions that were canceled as a result.
+func (s *Scheduler) stopShard(
+	ctx context.Context, shardID model.ShardID, reason string, userRequested bool) []model.ExecutionState {
 	if userRequested {
 		log.Ctx(ctx).Info().Msgf("stopping shard %s because the user requested it", shardID)
 	} else {
@@ -694,6 +1048,7 @@ func (s *Scheduler) stopShard(ctx context.Context, shardID model.ShardID, reason
 		EventName:    eventName,
 		EventTime:    time.Now(),
 	})
+	return cancelledExecutions
 }
 
 // compile-time check that BackendCallback implements the expected interfaces
@@ -0,0 +1,240 @@
+package requester
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/compute"
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/suite"
+)
+
+var (
+	schedulerTestNode1 = peer.ID("node-1").String()
+	schedulerTestNode2 = peer.ID("node-2").String()
+)
+
+type SchedulerRetrySuite struct {
+	suite.Suite
+	ctx             context.Context
+	jobStore        jobstore.Store
+	computeEndpoint *testComputeEndpoint
+	nodeDiscoverer  *testNodeDiscoverer
+	scheduler       *Scheduler
+}
+
+func TestSchedulerRetrySuite(t *testing.T) {
+	suite.Run(t, new(SchedulerRetrySuite))
+}
+
+func (s *SchedulerRetrySuite) SetupTest() {
+	s.ctx = context.Background()
+	s.jobStore = inmemory.NewJobStore()
+	s.computeEndpoint = &testComputeEndpoint{
+		states:       make(map[string]store.ExecutionState),
+		failingNodes: make(map[string]bool),
+	}
+	s.nodeDiscoverer = &testNodeDiscoverer{nodeIDs: []string{"node-1", "node-2"}}
+	s.scheduler = NewScheduler(SchedulerParams{
+		ID:              "requester",
+		JobStore:        s.jobStore,
+		NodeDiscoverer:  s.nodeDiscoverer,
+		NodeRanker:      s.nodeDiscoverer,
+		ComputeEndpoint: s.computeEndpoint,
+		EventEmitter:    noopEventEmitter(),
+	})
+}
+
+func (s *SchedulerRetrySuite) TestNoRetryPolicy() {
+	job := s.createJob(model.RetryPolicy{})
+	execution := s.createExecution(job, schedulerTestNode1, model.ExecutionStateBidAccepted)
+
+	s.failExecution(execution)
+	s.Equal(model.ShardStateError, s.getShardState(job).State)
+	s.Empty(s.computeEndpoint.getAskedForBids())
+
+	reputation, err := s.jobStore.GetNodeReputation(s.ctx, schedulerTestNode1)
+	s.Require().NoError(err)
+	s.Equal(1, reputation.ComputeFailures)
+}
+
+func (s *SchedulerRetrySuite) TestRetryOnOtherNode() {
+	job := s.createJob(model.RetryPolicy{MaxAttempts: 2, ExcludeFailedNodes: true})
+	execution := s.createExecution(job, schedulerTestNode1, model.ExecutionStateBidAccepted)
+
+	s.failExecution(execution)
+	s.Eventually(func() bool {
+		return s.activeExecution(job).State == model.ExecutionStateBidAccepted
+	}, time.Second, 10*time.Millisecond)
+
+	retried := s.activeExecution(job)
+	s.Equal(schedulerTestNode2, retried.NodeID)
+	s.Equal(2, retried.Attempt)
+	s.Equal(model.ShardStateInProgress, s.getShardState(job).State)
+
+	history, err := s.jobStore.GetJobHistory(s.ctx, job.Metadata.ID)
+	s.Require().NoError(err)
+	s.True(containsComment(history, "attempt 2 of 2"))
+
+	// no more attempts left
+	s.failExecution(retried)
+	s.Equal(model.ShardStateError, s.getShardState(job).State)
+}
+
+func (s *SchedulerRetrySuite) TestRetryOnSameNode() {
+	s.nodeDiscoverer.nodeIDs = []string{"node-1"}
+	job := s.createJob(model.RetryPolicy{MaxAttempts: 2})
+	execution := s.createExecution(job, schedulerTestNode1, model.ExecutionStateBidAccepted)
+
+	s.failExecution(execution)
+	s.Eventually(func() bool {
+		return s.activeExecution(job).State == model.ExecutionStateBidAccepted
+	}, time.Second, 10*time.Millisecond)
+	s.Equal(schedulerTestNode1, s.activeExecution(job).NodeID)
+}
+
+func (s *SchedulerRetrySuite) TestNoNodesLeftToRetry() {
+	s.nodeDiscoverer.nodeIDs = []string{"node-1"}
+	job := s.createJob(model.RetryPolicy{MaxAttempts: 2, ExcludeFailedNodes: true})
+	execution := s.createExecution(job, schedulerTestNode1, model.ExecutionStateBidAccepted)
+
+	s.failExecution(execution)
+	s.Equal(model.ShardStateError, s.getShardState(job).State)
+	s.Empty(s.computeEndpoint.getAskedForBids())
+}
+
+func (s *SchedulerRetrySuite) TestRetryAfterBidAcceptanceFailure() {
+	s.computeEndpoint.failingNodes[schedulerTestNode1] = true
+	job := s.createJob(model.RetryPolicy{MaxAttempts: 2, ExcludeFailedNodes: true})
+	s.createExecution(job, schedulerTestNode1, model.ExecutionStateAskForBidAccepted)
+
+	s.scheduler.startAcceptingBidsIfPossible(s.ctx, model.ShardID{JobID: job.Metadata.ID})
+	s.Eventually(func() bool {
+		execution := s.activeExecution(job)
+		return execution.NodeID == schedulerTestNode2 && execution.State == model.ExecutionStateBidAccepted
+	}, time.Second, 10*time.Millisecond)
+
+	reputation, err := s.jobStore.GetNodeReputation(s.ctx, schedulerTestNode1)
+	s.Require().NoError(err)
+	s.Equal(1, reputation.NoShows)
+}
+
+func (s *SchedulerRetrySuite) TestRetryBackoff() {
+	job := s.createJob(model.RetryPolicy{MaxAttempts: 2, Backoff: 60})
+	execution := s.createExecution(job, schedulerTestNode1, model.ExecutionStateBidAccepted)
+
+	s.failExecution(execution)
+	s.Equal(model.ShardStateInProgress, s.getShardState(job).State)
+	s.Equal(model.ExecutionStateAskForBid, s.activeExecution(job).State)
+	s.Empty(s.computeEndpoint.getAskedForBids())
+}
+
+func (s *SchedulerRetrySuite) TestStopAbandonsRetryBackoff() {
+	job := s.createJob(model.RetryPolicy{MaxAttempts: 2, Backoff: 0.1})
+	execution := s.createExecution(job, schedulerTestNode1, model.ExecutionStateBidAccepted)
+
+	s.failExecution(execution)
+	s.Equal(model.ExecutionStateAskForBid, s.activeExecution(job).State)
+	s.scheduler.Stop()
+	s.Never(func() bool {
+		return len(s.computeEndpoint.getAskedForBids()) > 0
+	}, 300*time.Millisecond, 10*time.Millisecond)
+}
+
+func (s *SchedulerRetrySuite) TestNodesAreFoundWithoutLock() {
+	s.nodeDiscoverer.onFindNodes = func() {
+		locked := make(chan struct{})
+		go func() {
+			s.scheduler.mu.Lock()
+			defer s.scheduler.mu.Unlock()
+			close(locked)
+		}()
+		select {
+		case <-locked:
+		case <-time.After(time.Second):
+			s.Fail("nodes found while holding the lock")
+		}
+	}
+	job := s.createJob(model.RetryPolicy{MaxAttempts: 2})
+	execution := s.createExecution(job, schedulerTestNode1, model.ExecutionStateBidAccepted)
+
+	s.failExecution(execution)
+	s.Eventually(func() bool {
+		return s.activeExecution(job).State == model.ExecutionStateBidAccepted
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (s *SchedulerRetrySuite) createJob(retryPolicy model.RetryPolicy) model.Job {
+	job := model.Job{
+		APIVersion: model.APIVersionLatest().String(),
+		Metadata: model.Metadata{
+			ID:        uuid.NewString(),
+			CreatedAt: time.Now(),
+		},
+		Spec: model.Spec{
+			Engine:        model.EngineNoop,
+			ExecutionPlan: model.JobExecutionPlan{TotalShards: 1},
+			Deal:          model.Deal{Concurrency: 1, RetryPolicy: retryPolicy},
+		},
+	}
+	s.Require().NoError(s.jobStore.CreateJob(s.ctx, job))
+	return job
+}
+
+func (s *SchedulerRetrySuite) createExecution(
+	job model.Job, nodeID string, state model.ExecutionStateType) model.ExecutionState {
+	execution := model.ExecutionState{
+		JobID:            job.Metadata.ID,
+		NodeID:           nodeID,
+		ComputeReference: "e-" + uuid.NewString(),
+		State:            state,
+		Attempt:          1,
+	}
+	s.Require().NoError(s.jobStore.CreateExecution(s.ctx, execution))
+	return execution
+}
+
+func (s *SchedulerRetrySuite) failExecution(execution model.ExecutionState) {
+	s.scheduler.OnComputeFailure(s.ctx, compute.ComputeError{
+		RoutingMetadata: compute.RoutingMetadata{
+			SourcePeerID: execution.NodeID,
+		},
+		ExecutionMetadata: compute.ExecutionMetadata{
+			ExecutionID: execution.ComputeReference,
+			JobID:       execution.JobID,
+			ShardIndex:  execution.ShardIndex,
+		},
+		Err: "execution failed",
+	})
+}
+
+func (s *SchedulerRetrySuite) activeExecution(job model.Job) model.ExecutionState {
+	for _, execution := range s.getShardState(job).Executions {
+		if !execution.State.IsDiscarded() {
+			return execution
+		}
+	}
+	return model.ExecutionState{}
+}
+
+func (s *SchedulerRetrySuite) getShardState(job model.Job) model.ShardState {
+	shardState, err := s.jobStore.GetShardState(s.ctx, model.ShardID{JobID: job.Metadata.ID})
+	s.Require().NoError(err)
+	return shardState
+}
+
+func containsComment(history []model.JobHistory, substr string) bool {
+	for _, entry := range history {
+		if strings.Contains(entry.Comment, substr) {
+			return true
+		}
+	}
+	return false
+}
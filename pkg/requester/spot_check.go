@@ -0,0 +1,87 @@
+package requester
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	mathrand "math/rand"
+	"sort"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+)
+
+// newSpotCheckSeed returns a random seed to sample the spot checked shards of a job from. It is only known to the
+// requester nodes, so that compute nodes can't predict which shards are spot checked and cheat on the others.
+func newSpotCheckSeed() (int64, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 0, fmt.Errorf("failed to generate spot check seed: %w", err)
+	}
+	return int64(binary.LittleEndian.Uint64(b[:])), nil
+}
+
+// spotCheckedShards returns the indexes of the shards of a job that the spot check verifier re-executes on a
+// second compute node. The shards are sampled from the job's secret seed, which is stored in the job store so that
+// the same shards are sampled after a restart and by other requester nodes sharing the job store.
+func spotCheckedShards(job model.Job, seed int64) map[int]bool {
+	count := job.Spec.SpotCheckCount()
+	if count == 0 {
+		return nil
+	}
+	random := mathrand.New(mathrand.NewSource(seed)) //nolint:gosec // the seed is secret and random
+	sampled := make(map[int]bool, count)
+	for _, shardIndex := range random.Perm(job.Spec.ExecutionPlan.TotalShards)[:count] {
+		sampled[shardIndex] = true
+	}
+	return sampled
+}
+
+// shardConcurrency returns the number of executions of a shard, which includes an extra execution for shards that
+// are spot checked.
+func shardConcurrency(job model.Job, seed int64, shardIndex int) int {
+	if spotCheckedShards(job, seed)[shardIndex] {
+		return job.Spec.Deal.Concurrency + 1
+	}
+	return job.Spec.Deal.Concurrency
+}
+
+// spotCheckSeed returns the secret spot check seed of a job, without reading the job store for jobs that are not
+// spot checked.
+func (s *Scheduler) spotCheckSeed(ctx context.Context, job model.Job) (int64, error) {
+	if job.Spec.SpotCheckCount() == 0 {
+		return 0, nil
+	}
+	return s.jobStore.GetSpotCheckSeed(ctx, job.Metadata.ID)
+}
+
+// shardConcurrency returns the number of executions of a shard of a job, reading the job's spot check seed
+func (s *Scheduler) shardConcurrency(ctx context.Context, job model.Job, shardIndex int) (int, error) {
+	seed, err := s.spotCheckSeed(ctx, job)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get spot check seed of job %s: %w", job.Metadata.ID, err)
+	}
+	return shardConcurrency(job, seed, shardIndex), nil
+}
+
+// spotCheckBidRequests picks the nodes that are asked to bid on the spot checked shards of a job, in addition to
+// the nodes that are asked to bid on all shards. They are picked at random rather than by rank, so that the node
+// that re-executes a shard is chosen independently of the node that executes it first.
+// It returns the indexes of the shards each node is asked to bid on, by node.
+func spotCheckBidRequests(spotChecked map[int]bool, candidates []NodeRank) map[string][]int {
+	if len(spotChecked) == 0 || len(candidates) == 0 {
+		return nil
+	}
+	requests := make(map[string][]int)
+	for shardIndex := range spotChecked {
+		for _, i := range mathrand.Perm(len(candidates))[:system.Min(len(candidates), OverAskForBidsFactor)] { //nolint:gosec
+			nodeID := candidates[i].NodeInfo.PeerInfo.ID.String()
+			requests[nodeID] = append(requests[nodeID], shardIndex)
+		}
+	}
+	for _, shardIndexes := range requests {
+		sort.Ints(shardIndexes)
+	}
+	return requests
+}
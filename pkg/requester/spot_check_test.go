@@ -0,0 +1,248 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/verifier"
+	"github.com/google/uuid"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSpotCheckedShards(t *testing.T) {
+	job := model.Job{
+		Metadata: model.Metadata{ID: uuid.NewString()},
+		Spec: model.Spec{
+			Verifier:      model.VerifierSpotCheck,
+			ExecutionPlan: model.JobExecutionPlan{TotalShards: 8},
+			Deal:          model.Deal{Concurrency: 1, SpotCheckFraction: 0.3},
+		},
+	}
+	seed, err := newSpotCheckSeed()
+	require.NoError(t, err)
+	sampled := spotCheckedShards(job, seed)
+	require.Len(t, sampled, 3)
+	require.Equal(t, sampled, spotCheckedShards(job, seed), "sampling is not deterministic")
+	for shardIndex := 0; shardIndex < 8; shardIndex++ {
+		if sampled[shardIndex] {
+			require.Equal(t, 2, shardConcurrency(job, seed, shardIndex))
+		} else {
+			require.Equal(t, 1, shardConcurrency(job, seed, shardIndex))
+		}
+	}
+
+	// the sample only depends on the secret seed, and not on the job ID that compute nodes know
+	otherJob := job
+	otherJob.Metadata.ID = uuid.NewString()
+	require.Equal(t, sampled, spotCheckedShards(otherJob, seed))
+	samples := make(map[string]bool)
+	for i := int64(0); i < 10; i++ {
+		samples[fmt.Sprint(spotCheckedShards(job, i))] = true
+	}
+	require.Greater(t, len(samples), 1, "the sample doesn't depend on the seed")
+
+	job.Spec.Deal.SpotCheckFraction = 1
+	require.Len(t, spotCheckedShards(job, seed), 8)
+
+	job.Spec.Verifier = model.VerifierDeterministic
+	require.Empty(t, spotCheckedShards(job, seed))
+}
+
+type SpotCheckSuite struct {
+	suite.Suite
+	ctx             context.Context
+	jobStore        jobstore.Store
+	computeEndpoint *testComputeEndpoint
+	nodeDiscoverer  *testNodeDiscoverer
+	scheduler       *Scheduler
+}
+
+func TestSpotCheckSuite(t *testing.T) {
+	suite.Run(t, new(SpotCheckSuite))
+}
+
+func (s *SpotCheckSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.jobStore = inmemory.NewJobStore()
+	s.computeEndpoint = &testComputeEndpoint{
+		states:       make(map[string]store.ExecutionState),
+		failingNodes: make(map[string]bool),
+	}
+	s.nodeDiscoverer = &testNodeDiscoverer{}
+	for i := 1; i <= 5; i++ {
+		s.nodeDiscoverer.nodeIDs = append(s.nodeDiscoverer.nodeIDs, fmt.Sprintf("node-%d", i))
+	}
+	s.scheduler = NewScheduler(SchedulerParams{
+		ID:              "requester",
+		JobStore:        s.jobStore,
+		NodeDiscoverer:  s.nodeDiscoverer,
+		NodeRanker:      s.nodeDiscoverer,
+		ComputeEndpoint: s.computeEndpoint,
+		Verifiers: model.NewMappedProvider(map[model.Verifier]verifier.Verifier{
+			model.VerifierSpotCheck: &testVerifier{},
+		}),
+		EventEmitter: noopEventEmitter(),
+	})
+}
+
+func (s *SpotCheckSuite) TestExtraBidsOnlyForSpotCheckedShards() {
+	job := s.newJob(4, 0.25)
+	s.Require().NoError(s.scheduler.StartJob(s.ctx, StartJobRequest{Job: job}))
+	seed, err := s.jobStore.GetSpotCheckSeed(s.ctx, job.Metadata.ID)
+	s.Require().NoError(err)
+	s.NotZero(seed, "the spot check seed was not stored")
+	sampled := spotCheckedShards(job, seed)
+	s.Require().Len(sampled, 1)
+
+	s.Eventually(func() bool {
+		jobState, err := s.jobStore.GetJobState(s.ctx, job.Metadata.ID)
+		s.Require().NoError(err)
+		for shardIndex, shardState := range jobState.Shards {
+			accepted := 0
+			for _, execution := range shardState.Executions {
+				if execution.State == model.ExecutionStateBidAccepted {
+					accepted++
+				}
+			}
+			if accepted != shardConcurrency(job, seed, shardIndex) {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	// the nodes that are asked to bid on all shards are asked first, then the extra nodes are asked to bid on
+	// the spot checked shards only
+	for _, request := range s.computeEndpoint.getAskedForBids() {
+		if len(request.ShardIndexes) != 4 {
+			s.Len(request.ShardIndexes, 1)
+			s.True(sampled[request.ShardIndexes[0]])
+		}
+	}
+	s.Len(s.computeEndpoint.getAskedForBids(), 5)
+}
+
+func (s *SpotCheckSuite) TestNotEnoughNodesToSpotCheck() {
+	s.nodeDiscoverer.nodeIDs = []string{"node-1"}
+	err := s.scheduler.StartJob(s.ctx, StartJobRequest{Job: s.newJob(1, 1)})
+	s.ErrorAs(err, &ErrNotEnoughNodes{})
+}
+
+func (s *SpotCheckSuite) TestShardTrust() {
+	for _, tc := range []struct {
+		name      string
+		proposals []string
+		trust     model.ShardTrust
+		state     model.ShardStateType
+		outcome   model.NodeOutcome
+	}{
+		{name: "verified", proposals: []string{"a", "a"}, trust: model.ShardTrustVerified, state: model.ShardStateInProgress,
+			outcome: model.NodeOutcomeVerificationPassed},
+		{name: "mismatched", proposals: []string{"a", "b"}, trust: model.ShardTrustMismatched, state: model.ShardStateError,
+			outcome: model.NodeOutcomeVerificationFailed},
+	} {
+		s.Run(tc.name, func() {
+			job := s.newJob(1, 1)
+			s.Require().NoError(s.jobStore.CreateJob(s.ctx, job))
+			for i, proposal := range tc.proposals {
+				s.Require().NoError(s.jobStore.CreateExecution(s.ctx, model.ExecutionState{
+					JobID:                job.Metadata.ID,
+					NodeID:               peer.ID(fmt.Sprintf("node-%d", i)).String(),
+					ComputeReference:     "e-" + uuid.NewString(),
+					State:                model.ExecutionStateResultProposed,
+					VerificationProposal: []byte(proposal),
+				}))
+			}
+
+			s.scheduler.startVerificationIfPossible(s.ctx, model.ShardID{JobID: job.Metadata.ID})
+			shardState, err := s.jobStore.GetShardState(s.ctx, model.ShardID{JobID: job.Metadata.ID})
+			s.Require().NoError(err)
+			s.Equal(tc.trust, shardState.Trust)
+			s.Equal(tc.state, shardState.State)
+
+			// the outcome of the verification is recorded for each node
+			for i := range tc.proposals {
+				reputation, reputationErr := s.jobStore.GetNodeReputation(s.ctx, peer.ID(fmt.Sprintf("node-%d", i)).String())
+				s.Require().NoError(reputationErr)
+				expected := model.NodeReputation{NodeID: reputation.NodeID}
+				expected.Record(tc.outcome)
+				s.Equal(expected.VerificationsPassed, reputation.VerificationsPassed)
+				s.Equal(expected.VerificationsFailed, reputation.VerificationsFailed)
+				s.Require().NoError(s.jobStore.ResetNodeReputation(s.ctx, reputation.NodeID))
+			}
+		})
+	}
+
+	job := s.newJob(2, 0.5)
+	s.Require().NoError(s.jobStore.CreateJob(s.ctx, job))
+	for shardIndex := 0; shardIndex < 2; shardIndex++ {
+		// the job was not started by the scheduler, so it has no stored seed
+		if spotCheckedShards(job, 0)[shardIndex] {
+			continue
+		}
+		s.Require().NoError(s.jobStore.CreateExecution(s.ctx, model.ExecutionState{
+			JobID:            job.Metadata.ID,
+			ShardIndex:       shardIndex,
+			NodeID:           peer.ID("node-1").String(),
+			ComputeReference: "e-" + uuid.NewString(),
+			State:            model.ExecutionStateResultProposed,
+		}))
+		shardID := model.ShardID{JobID: job.Metadata.ID, Index: shardIndex}
+		s.scheduler.startVerificationIfPossible(s.ctx, shardID)
+		shardState, err := s.jobStore.GetShardState(s.ctx, shardID)
+		s.Require().NoError(err)
+		s.Equal(model.ShardTrustUnverified, shardState.Trust)
+	}
+
+	// results that were not compared with other results are not counted towards the reputation of the node
+	reputations, err := s.jobStore.GetNodeReputations(s.ctx)
+	s.Require().NoError(err)
+	s.Empty(reputations)
+}
+
+func (s *SpotCheckSuite) newJob(totalShards int, spotCheckFraction float64) model.Job {
+	return model.Job{
+		APIVersion: model.APIVersionLatest().String(),
+		Metadata: model.Metadata{
+			ID:        uuid.NewString(),
+			CreatedAt: time.Now(),
+		},
+		Spec: model.Spec{
+			Engine:        model.EngineNoop,
+			Verifier:      model.VerifierSpotCheck,
+			ExecutionPlan: model.JobExecutionPlan{TotalShards: totalShards},
+			Deal:          model.Deal{Concurrency: 1, SpotCheckFraction: spotCheckFraction},
+		},
+	}
+}
+
+// testVerifier verifies executions when all of them proposed the same result, like the spot check verifier.
+type testVerifier struct {
+	verifier.Verifier
+}
+
+func (v *testVerifier) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (v *testVerifier) VerifyShard(
+	_ context.Context, _ model.JobShard, executions []model.ExecutionState) ([]verifier.VerifierResult, error) {
+	matched := true
+	for _, execution := range executions {
+		matched = matched && bytes.Equal(execution.VerificationProposal, executions[0].VerificationProposal)
+	}
+	results := make([]verifier.VerifierResult, 0, len(executions))
+	for _, execution := range executions {
+		results = append(results, verifier.VerifierResult{Execution: execution, Verified: matched})
+	}
+	return results, nil
+}
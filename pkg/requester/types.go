@@ -12,6 +12,25 @@ type Endpoint interface {
 	SubmitJob(context.Context, model.JobCreatePayload) (*model.Job, error)
 	// CancelJob cancels an existing job.
 	CancelJob(context.Context, CancelJobRequest) (CancelJobResult, error)
+	// SubmitWorkflow submits a set of jobs with dependencies between them. Each job is held by the requester
+	// until the jobs it depends on complete.
+	SubmitWorkflow(context.Context, model.WorkflowCreatePayload) (model.WorkflowState, error)
+	// GetWorkflowState returns the state of a workflow and its jobs.
+	GetWorkflowState(context.Context, string) (model.WorkflowState, error)
+	// CancelWorkflow cancels a workflow and all its jobs that did not complete yet.
+	CancelWorkflow(context.Context, CancelWorkflowRequest) (model.WorkflowState, error)
+	// CreateSchedule creates a schedule that submits a new job at each activation time of its cron expression.
+	CreateSchedule(context.Context, model.ScheduleCreatePayload) (model.Schedule, error)
+	// GetSchedule returns a schedule and the history of its runs.
+	GetSchedule(context.Context, string) (model.Schedule, error)
+	// ListSchedules returns the schedules of a client, or all schedules if the client id is empty.
+	ListSchedules(context.Context, string) ([]model.Schedule, error)
+	// DeleteSchedule deletes a schedule. Jobs that were already submitted by the schedule keep running.
+	DeleteSchedule(context.Context, string) (model.Schedule, error)
+	// GetLogs returns the output of the latest execution of a job shard, while it runs and after it completed.
+	GetLogs(context.Context, GetLogsRequest) (model.ExecutionLogs, error)
+	// GetClientUsage returns the usage of a client counted against its limits.
+	GetClientUsage(context.Context, string) (model.ClientUsage, error)
 }
 
 // NodeDiscoverer discovers nodes in the network that are suitable to execute a job.
@@ -40,7 +59,22 @@ type CancelJobRequest struct {
 	JobID         string
 	Reason        string
 	UserTriggered bool
+	// TimedOut is true when the job is canceled because it timed out, which counts towards the reputation of the
+	// nodes that were still executing it
+	TimedOut bool
 }
 
 type CancelJobResult struct {
 }
+
+type GetLogsRequest struct {
+	JobID      string
+	ShardIndex int
+	// Offset is the number of log entries that were already read
+	Offset int
+}
+
+type CancelWorkflowRequest struct {
+	WorkflowID string
+	Reason     string
+}
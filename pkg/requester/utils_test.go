@@ -0,0 +1,164 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/filecoin-project/bacalhau/pkg/compute"
+	"github.com/filecoin-project/bacalhau/pkg/compute/store"
+	"github.com/filecoin-project/bacalhau/pkg/eventhandler"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// testComputeEndpoint is a compute endpoint that reports fixed execution states, and records the requests it receives.
+type testComputeEndpoint struct {
+	compute.Endpoint
+	mu           sync.Mutex
+	states       map[string]store.ExecutionState
+	logs         map[string]compute.ExecutionLogsResponse
+	bidsAccepted []string
+	askedForBids []compute.AskForBidRequest
+	// results published by completed executions, and executions the compute node doesn't know about
+	results       map[string]model.StorageSpec
+	unknown       map[string]bool
+	stateRequests int
+	// requester nodes the callbacks of executions were redirected to
+	redirects map[string]string
+	// nodes that fail to accept bids, and nodes that can't be asked to bid
+	failingNodes     map[string]bool
+	unreachableNodes map[string]bool
+}
+
+func (e *testComputeEndpoint) ExecutionState(
+	_ context.Context, request compute.ExecutionStateRequest) (compute.ExecutionStateResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stateRequests++
+	if e.unknown[request.ExecutionID] {
+		return compute.ExecutionStateResponse{Unknown: true}, nil
+	}
+	state, ok := e.states[request.ExecutionID]
+	if !ok {
+		return compute.ExecutionStateResponse{}, errors.New("unreachable")
+	}
+	if request.RedirectCallbacks {
+		if e.redirects == nil {
+			e.redirects = make(map[string]string)
+		}
+		e.redirects[request.ExecutionID] = request.SourcePeerID
+	}
+	return compute.ExecutionStateResponse{State: state, PublishedResult: e.results[request.ExecutionID]}, nil
+}
+
+func (e *testComputeEndpoint) ExecutionLogs(
+	_ context.Context, request compute.ExecutionLogsRequest) (compute.ExecutionLogsResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	logs, ok := e.logs[request.ExecutionID]
+	if !ok {
+		return compute.ExecutionLogsResponse{}, errors.New("unreachable")
+	}
+	return logs, nil
+}
+
+func (e *testComputeEndpoint) AskForBid(
+	_ context.Context, request compute.AskForBidRequest) (compute.AskForBidResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.askedForBids = append(e.askedForBids, request)
+	if e.unreachableNodes[request.TargetPeerID] {
+		return compute.AskForBidResponse{}, errors.New("unreachable")
+	}
+	var response compute.AskForBidResponse
+	for _, shardIndex := range request.ShardIndexes {
+		response.ShardResponse = append(response.ShardResponse, compute.AskForBidShardResponse{
+			ExecutionMetadata: compute.ExecutionMetadata{
+				ExecutionID: "e-" + uuid.NewString(),
+				JobID:       request.Job.Metadata.ID,
+				ShardIndex:  shardIndex,
+			},
+			Accepted: true,
+		})
+	}
+	return response, nil
+}
+
+func (e *testComputeEndpoint) BidAccepted(
+	_ context.Context, request compute.BidAcceptedRequest) (compute.BidAcceptedResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failingNodes[request.TargetPeerID] {
+		return compute.BidAcceptedResponse{}, errors.New("bid acceptance failed")
+	}
+	e.bidsAccepted = append(e.bidsAccepted, request.ExecutionID)
+	return compute.BidAcceptedResponse{}, nil
+}
+
+func (e *testComputeEndpoint) BidRejected(
+	_ context.Context, _ compute.BidRejectedRequest) (compute.BidRejectedResponse, error) {
+	return compute.BidRejectedResponse{}, nil
+}
+
+func (e *testComputeEndpoint) CancelExecution(
+	_ context.Context, _ compute.CancelExecutionRequest) (compute.CancelExecutionResponse, error) {
+	return compute.CancelExecutionResponse{}, nil
+}
+
+func (e *testComputeEndpoint) ResultAccepted(
+	_ context.Context, _ compute.ResultAcceptedRequest) (compute.ResultAcceptedResponse, error) {
+	return compute.ResultAcceptedResponse{}, nil
+}
+
+func (e *testComputeEndpoint) ResultRejected(
+	_ context.Context, _ compute.ResultRejectedRequest) (compute.ResultRejectedResponse, error) {
+	return compute.ResultRejectedResponse{}, nil
+}
+
+func (e *testComputeEndpoint) getAskedForBids() []compute.AskForBidRequest {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]compute.AskForBidRequest{}, e.askedForBids...)
+}
+
+func (e *testComputeEndpoint) getRedirect(executionID string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.redirects[executionID]
+}
+
+// testNodeDiscoverer discovers a fixed set of nodes, and ranks all of them equally.
+type testNodeDiscoverer struct {
+	nodeIDs []string
+	// onFindNodes is called when nodes are looked up, if set
+	onFindNodes func()
+}
+
+func (d *testNodeDiscoverer) FindNodes(context.Context, model.Job) ([]model.NodeInfo, error) {
+	if d.onFindNodes != nil {
+		d.onFindNodes()
+	}
+	nodes := make([]model.NodeInfo, 0, len(d.nodeIDs))
+	for _, nodeID := range d.nodeIDs {
+		nodes = append(nodes, model.NodeInfo{PeerInfo: peer.AddrInfo{ID: peer.ID(nodeID)}})
+	}
+	return nodes, nil
+}
+
+func (d *testNodeDiscoverer) RankNodes(_ context.Context, _ model.Job, nodes []model.NodeInfo) ([]NodeRank, error) {
+	ranks := make([]NodeRank, 0, len(nodes))
+	for _, node := range nodes {
+		ranks = append(ranks, NodeRank{NodeInfo: node, Rank: 1})
+	}
+	return ranks, nil
+}
+
+func noopEventEmitter() EventEmitter {
+	return NewEventEmitter(EventEmitterParams{
+		EventConsumer: eventhandler.JobEventHandlerFunc(func(ctx context.Context, event model.JobEvent) error {
+			return nil
+		}),
+	})
+}
@@ -0,0 +1,483 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// workflowJobHandler submits and cancels the jobs of workflows.
+type workflowJobHandler interface {
+	SubmitJob(context.Context, model.JobCreatePayload) (*model.Job, error)
+	CancelJob(context.Context, CancelJobRequest) (CancelJobResult, error)
+}
+
+type workflowManagerParams struct {
+	// NodeID is the id of the requester node, which only tracks the workflows that were submitted to it
+	NodeID   string
+	Jobs     workflowJobHandler
+	JobStore jobstore.Store
+	// Interval between checks of the state of submitted jobs
+	Interval time.Duration
+}
+
+// workflow is a workflow in progress that the manager tracks.
+type workflow struct {
+	model.Workflow
+	// submitting holds the names of the jobs that are being submitted without holding the lock
+	submitting map[string]bool
+}
+
+// workflowSubmission is a job of a workflow whose dependencies completed. Jobs are collected while holding the
+// lock, and submitted after releasing it.
+type workflowSubmission struct {
+	workflowID string
+	jobName    string
+	payload    model.JobCreatePayload
+}
+
+// workflowManager holds the jobs of workflows until the jobs they depend on complete, resolves their inputs that
+// reference the results of upstream jobs, and propagates failures and cancellations to downstream jobs.
+// Workflows are persisted in the job store, and the requester node they were submitted to resumes tracking the
+// ones in progress when it restarts. Only workflows in progress are kept in memory, and the background task that
+// tracks their progress only runs while some of them exist.
+type workflowManager struct {
+	nodeID    string
+	jobs      workflowJobHandler
+	jobStore  jobstore.Store
+	interval  time.Duration
+	workflows map[string]*workflow
+	running   bool
+	mu        sync.Mutex
+}
+
+func newWorkflowManager(params workflowManagerParams) *workflowManager {
+	return &workflowManager{
+		nodeID:    params.NodeID,
+		jobs:      params.Jobs,
+		jobStore:  params.JobStore,
+		interval:  params.Interval,
+		workflows: make(map[string]*workflow),
+	}
+}
+
+// resume loads the workflows of this node that are still in progress from the job store, and resumes tracking them.
+func (m *workflowManager) resume(ctx context.Context) error {
+	workflows, err := m.jobStore.GetInProgressWorkflows(ctx, m.nodeID)
+	if err != nil {
+		return fmt.Errorf("failed to load workflows: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, stored := range workflows {
+		m.workflows[stored.State.WorkflowID] = &workflow{Workflow: stored, submitting: make(map[string]bool)}
+	}
+	if len(m.workflows) > 0 {
+		m.startBackgroundTask()
+	}
+	log.Ctx(ctx).Debug().Msgf("resumed %d workflows", len(m.workflows))
+	return nil
+}
+
+func (m *workflowManager) submit(ctx context.Context, payload model.WorkflowCreatePayload) (model.WorkflowState, error) {
+	workflowID, err := uuid.NewRandom()
+	if err != nil {
+		return model.WorkflowState{}, fmt.Errorf("error creating workflow id: %w", err)
+	}
+
+	now := time.Now()
+	w := &workflow{
+		Workflow: model.Workflow{
+			APIVersion:      payload.APIVersion,
+			RequesterNodeID: m.nodeID,
+			Spec:            *payload.Spec,
+			State: model.WorkflowState{
+				WorkflowID: workflowID.String(),
+				ClientID:   payload.ClientID,
+				State:      model.WorkflowStateInProgress,
+				Jobs:       make([]model.WorkflowJobState, len(payload.Spec.Jobs)),
+				CreateTime: now,
+				UpdateTime: now,
+			},
+		},
+		submitting: make(map[string]bool),
+	}
+	for i, workflowJob := range payload.Spec.Jobs {
+		w.State.Jobs[i] = model.WorkflowJobState{
+			Name:  workflowJob.Name,
+			State: model.WorkflowJobStatePending,
+		}
+	}
+	if err = m.jobStore.CreateWorkflow(ctx, w.Workflow); err != nil {
+		return model.WorkflowState{}, fmt.Errorf("failed to store workflow: %w", err)
+	}
+
+	m.mu.Lock()
+	changed, submissions := m.progress(ctx, w)
+	if changed {
+		m.save(ctx, w)
+	}
+	if !w.State.State.IsTerminal() {
+		m.workflows[w.State.WorkflowID] = w
+		m.startBackgroundTask()
+	}
+	m.mu.Unlock()
+
+	for _, submission := range submissions {
+		m.submitJob(ctx, submission)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return copyWorkflowState(w.State), nil
+}
+
+func (m *workflowManager) get(ctx context.Context, workflowID string) (model.WorkflowState, error) {
+	w, err := m.load(ctx, workflowID)
+	if err != nil {
+		return model.WorkflowState{}, err
+	}
+	return w.State, nil
+}
+
+// cancel cancels the pending jobs of a workflow and records it as canceled while holding the lock, then cancels
+// its submitted jobs after releasing it.
+func (m *workflowManager) cancel(ctx context.Context, request CancelWorkflowRequest) (model.WorkflowState, error) {
+	m.mu.Lock()
+	w, ok := m.workflows[request.WorkflowID]
+	if !ok {
+		m.mu.Unlock()
+		// workflows that are not in memory either finished, or are tracked by another requester node
+		stored, err := m.load(ctx, request.WorkflowID)
+		if err != nil {
+			return model.WorkflowState{}, err
+		}
+		if !stored.State.State.IsTerminal() {
+			return model.WorkflowState{}, fmt.Errorf("workflow %s is tracked by requester node %s",
+				request.WorkflowID, stored.RequesterNodeID)
+		}
+		return stored.State, nil
+	}
+
+	message := "workflow canceled"
+	if request.Reason != "" {
+		message = fmt.Sprintf("workflow canceled: %s", request.Reason)
+	}
+	var jobIDs []string
+	for i := range w.State.Jobs {
+		jobState := &w.State.Jobs[i]
+		switch jobState.State {
+		case model.WorkflowJobStatePending:
+			// jobs that are being submitted are canceled once their submission returns
+			jobState.State = model.WorkflowJobStateCancelled
+			jobState.Message = message
+		case model.WorkflowJobStateSubmitted:
+			jobIDs = append(jobIDs, jobState.JobID)
+			jobState.State = model.WorkflowJobStateCancelled
+			jobState.Message = message
+		default:
+		}
+	}
+	w.State.State = model.WorkflowStateCancelled
+	w.State.UpdateTime = time.Now()
+	m.save(ctx, w)
+	delete(m.workflows, w.State.WorkflowID)
+	state := copyWorkflowState(w.State)
+	m.mu.Unlock()
+
+	for _, jobID := range jobIDs {
+		m.cancelJob(ctx, w.State.WorkflowID, jobID, message)
+	}
+	return state, nil
+}
+
+// load returns a workflow from the job store.
+func (m *workflowManager) load(ctx context.Context, workflowID string) (model.Workflow, error) {
+	w, err := m.jobStore.GetWorkflow(ctx, workflowID)
+	if err != nil {
+		if errors.As(err, &jobstore.ErrWorkflowNotFound{}) {
+			return model.Workflow{}, NewErrWorkflowNotFound(workflowID)
+		}
+		return model.Workflow{}, err
+	}
+	return w, nil
+}
+
+// save stores the state of a workflow in the job store. It is called with the lock held.
+func (m *workflowManager) save(ctx context.Context, w *workflow) {
+	if err := m.jobStore.UpdateWorkflow(ctx, w.Workflow); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to store workflow %s", w.State.WorkflowID)
+	}
+}
+
+// startBackgroundTask starts the background task if it is not running. It is called with the lock held.
+func (m *workflowManager) startBackgroundTask() {
+	if !m.running {
+		m.running = true
+		go m.backgroundTask()
+	}
+}
+
+// backgroundTask periodically checks the progress of workflows, and stops once none of them is in progress.
+func (m *workflowManager) backgroundTask() {
+	ctx := context.Background()
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.mu.Lock()
+		var submissions []workflowSubmission
+		for id, w := range m.workflows {
+			changed, workflowSubmissions := m.progress(ctx, w)
+			if changed {
+				m.save(ctx, w)
+			}
+			if w.State.State.IsTerminal() {
+				delete(m.workflows, id)
+			}
+			submissions = append(submissions, workflowSubmissions...)
+		}
+		if len(m.workflows) == 0 {
+			m.running = false
+			m.mu.Unlock()
+			return
+		}
+		m.mu.Unlock()
+
+		for _, submission := range submissions {
+			m.submitJob(ctx, submission)
+		}
+	}
+}
+
+// progress updates the state of the submitted jobs of a workflow, fails or cancels the pending jobs whose
+// dependencies failed or were canceled, and returns the submissions of the pending jobs whose dependencies
+// completed. It also returns whether the state of any job changed. It is called with the lock held, and the
+// returned submissions are submitted after releasing it.
+func (m *workflowManager) progress(ctx context.Context, w *workflow) (bool, []workflowSubmission) {
+	indexes := make(map[string]int, len(w.Spec.Jobs))
+	for i, workflowJob := range w.Spec.Jobs {
+		indexes[workflowJob.Name] = i
+	}
+
+	changed := false
+	for i := range w.State.Jobs {
+		if w.State.Jobs[i].State == model.WorkflowJobStateSubmitted {
+			changed = m.updateSubmittedJob(ctx, &w.State.Jobs[i]) || changed
+		}
+	}
+
+	// keep going while jobs change state, as failures and cancellations propagate through the whole graph at once
+	var submissions []workflowSubmission
+	for progressed := true; progressed; {
+		progressed = false
+		for i, workflowJob := range w.Spec.Jobs {
+			jobState := &w.State.Jobs[i]
+			if jobState.State != model.WorkflowJobStatePending || w.submitting[workflowJob.Name] {
+				continue
+			}
+			ready := true
+			for _, dependency := range workflowJob.Dependencies() {
+				dependencyState := w.State.Jobs[indexes[dependency]].State
+				if dependencyState == model.WorkflowJobStateError {
+					jobState.State = model.WorkflowJobStateError
+					jobState.Message = fmt.Sprintf("dependency %s failed", dependency)
+					break
+				}
+				if dependencyState == model.WorkflowJobStateCancelled {
+					jobState.State = model.WorkflowJobStateCancelled
+					jobState.Message = fmt.Sprintf("dependency %s was canceled", dependency)
+					break
+				}
+				ready = ready && dependencyState == model.WorkflowJobStateCompleted
+			}
+			if jobState.State == model.WorkflowJobStatePending && ready {
+				if submission, ok := m.submission(ctx, w, workflowJob, jobState); ok {
+					w.submitting[workflowJob.Name] = true
+					submissions = append(submissions, submission)
+				}
+			}
+			if jobState.State != model.WorkflowJobStatePending {
+				progressed = true
+				changed = true
+			}
+		}
+	}
+
+	if changed {
+		w.State.UpdateTime = time.Now()
+	}
+	w.State.State = aggregateWorkflowState(w.State.Jobs)
+	return changed, submissions
+}
+
+// updateSubmittedJob updates the state of a submitted job from the job store, and returns whether it changed.
+func (m *workflowManager) updateSubmittedJob(ctx context.Context, jobState *model.WorkflowJobState) bool {
+	state, err := m.jobStore.GetJobState(ctx, jobState.JobID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to get state of workflow job %s", jobState.JobID)
+		return false
+	}
+	switch state.State {
+	case model.JobStateCompleted:
+		jobState.State = model.WorkflowJobStateCompleted
+	case model.JobStateError:
+		jobState.State = model.WorkflowJobStateError
+		jobState.Message = "job failed"
+	case model.JobStatePartialError:
+		jobState.State = model.WorkflowJobStateError
+		jobState.Message = "some shards of the job failed"
+	case model.JobStateCancelled:
+		jobState.State = model.WorkflowJobStateCancelled
+		jobState.Message = "job was canceled"
+	default:
+		return false
+	}
+	return true
+}
+
+// submission resolves the upstream inputs of a job whose dependencies completed, and returns its submission.
+// The job fails if its inputs can't be resolved. It is called with the lock held.
+func (m *workflowManager) submission(
+	ctx context.Context, w *workflow, workflowJob model.WorkflowJobSpec, jobState *model.WorkflowJobState) (workflowSubmission, bool) {
+	spec := workflowJob.Spec
+	spec.Inputs = make([]model.StorageSpec, len(workflowJob.Spec.Inputs))
+	for i, input := range workflowJob.Spec.Inputs {
+		if input.Upstream == nil {
+			spec.Inputs[i] = input
+			continue
+		}
+		resolved, err := m.resolveUpstreamResult(ctx, w, input)
+		if err != nil {
+			jobState.State = model.WorkflowJobStateError
+			jobState.Message = err.Error()
+			return workflowSubmission{}, false
+		}
+		spec.Inputs[i] = resolved
+	}
+	return workflowSubmission{
+		workflowID: w.State.WorkflowID,
+		jobName:    workflowJob.Name,
+		payload: model.JobCreatePayload{
+			ClientID:   w.State.ClientID,
+			APIVersion: w.APIVersion,
+			Spec:       &spec,
+		},
+	}, true
+}
+
+// submitJob submits a job of a workflow to the network, then records the outcome in the state of the job.
+// It is called without holding the lock, as submitting a job can take a while.
+func (m *workflowManager) submitJob(ctx context.Context, submission workflowSubmission) {
+	job, err := m.jobs.SubmitJob(ctx, submission.payload)
+
+	m.mu.Lock()
+	w, ok := m.workflows[submission.workflowID]
+	var jobState *model.WorkflowJobState
+	if ok {
+		delete(w.submitting, submission.jobName)
+		for i := range w.State.Jobs {
+			if w.State.Jobs[i].Name == submission.jobName {
+				jobState = &w.State.Jobs[i]
+			}
+		}
+	}
+	if jobState == nil || jobState.State != model.WorkflowJobStatePending {
+		// the workflow was canceled while the job was being submitted
+		m.mu.Unlock()
+		if err == nil {
+			m.cancelJob(ctx, submission.workflowID, job.Metadata.ID, "workflow canceled")
+		}
+		return
+	}
+	if err != nil {
+		jobState.State = model.WorkflowJobStateError
+		jobState.Message = fmt.Sprintf("failed to submit job: %s", err)
+	} else {
+		log.Ctx(ctx).Debug().Msgf("submitted job %s of workflow %s as %s", submission.jobName, submission.workflowID, job.Metadata.ID)
+		jobState.JobID = job.Metadata.ID
+		jobState.State = model.WorkflowJobStateSubmitted
+	}
+	w.State.UpdateTime = time.Now()
+	w.State.State = aggregateWorkflowState(w.State.Jobs)
+	m.save(ctx, w)
+	if w.State.State.IsTerminal() {
+		delete(m.workflows, submission.workflowID)
+	}
+	m.mu.Unlock()
+}
+
+// cancelJob cancels a submitted job of a workflow. It is called without holding the lock.
+func (m *workflowManager) cancelJob(ctx context.Context, workflowID string, jobID string, reason string) {
+	_, err := m.jobs.CancelJob(ctx, CancelJobRequest{
+		JobID:         jobID,
+		Reason:        reason,
+		UserTriggered: true,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msgf("failed to cancel job %s of workflow %s", jobID, workflowID)
+	}
+}
+
+// resolveUpstreamResult replaces an input that references the result of an upstream job with the storage spec
+// of the published result, while keeping the path where the input is mounted.
+func (m *workflowManager) resolveUpstreamResult(
+	ctx context.Context, w *workflow, input model.StorageSpec) (model.StorageSpec, error) {
+	var upstreamJobID string
+	for _, jobState := range w.State.Jobs {
+		if jobState.Name == input.Upstream.Job {
+			upstreamJobID = jobState.JobID
+		}
+	}
+	shardState, err := m.jobStore.GetShardState(ctx, model.ShardID{JobID: upstreamJobID, Index: input.Upstream.ShardIndex})
+	if err != nil {
+		return model.StorageSpec{}, fmt.Errorf("failed to get shard %d of upstream job %s: %w",
+			input.Upstream.ShardIndex, input.Upstream.Job, err)
+	}
+	for _, execution := range shardState.Executions {
+		if execution.State == model.ExecutionStateCompleted && model.IsValidStorageSourceType(execution.PublishedResult.StorageSource) {
+			resolved := execution.PublishedResult
+			resolved.Path = input.Path
+			if input.Name != "" {
+				resolved.Name = input.Name
+			}
+			return resolved, nil
+		}
+	}
+	return model.StorageSpec{}, fmt.Errorf("shard %d of upstream job %s has no published result",
+		input.Upstream.ShardIndex, input.Upstream.Job)
+}
+
+func aggregateWorkflowState(jobs []model.WorkflowJobState) model.WorkflowStateType {
+	hasError, hasCancelled := false, false
+	for _, jobState := range jobs {
+		switch jobState.State {
+		case model.WorkflowJobStateError:
+			hasError = true
+		case model.WorkflowJobStateCancelled:
+			hasCancelled = true
+		case model.WorkflowJobStateCompleted:
+		default:
+			return model.WorkflowStateInProgress
+		}
+	}
+	if hasError {
+		return model.WorkflowStateError
+	}
+	if hasCancelled {
+		return model.WorkflowStateCancelled
+	}
+	return model.WorkflowStateCompleted
+}
+
+func copyWorkflowState(state model.WorkflowState) model.WorkflowState {
+	state.Jobs = append([]model.WorkflowJobState{}, state.Jobs...)
+	return state
+}
@@ -0,0 +1,331 @@
+package requester
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/bacalhau/pkg/jobstore"
+	"github.com/filecoin-project/bacalhau/pkg/jobstore/inmemory"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+// testWorkflowJobHandler creates submitted jobs directly in the job store, so that tests can control their state.
+type testWorkflowJobHandler struct {
+	jobStore  jobstore.Store
+	mu        sync.Mutex
+	submitted map[string]model.Job
+	cancelled []string
+	// onSubmit and onCancel are called when a job is submitted or canceled, if set
+	onSubmit func()
+	onCancel func()
+}
+
+func (h *testWorkflowJobHandler) SubmitJob(ctx context.Context, payload model.JobCreatePayload) (*model.Job, error) {
+	if h.onSubmit != nil {
+		h.onSubmit()
+	}
+	job := model.Job{
+		APIVersion: payload.APIVersion,
+		Metadata: model.Metadata{
+			ID:        uuid.NewString(),
+			ClientID:  payload.ClientID,
+			CreatedAt: time.Now(),
+		},
+		Spec: *payload.Spec,
+	}
+	job.Spec.ExecutionPlan.TotalShards = 1
+	if err := h.jobStore.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.submitted[job.Metadata.ID] = job
+	return &job, nil
+}
+
+func (h *testWorkflowJobHandler) CancelJob(ctx context.Context, request CancelJobRequest) (CancelJobResult, error) {
+	if h.onCancel != nil {
+		h.onCancel()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.cancelled = append(h.cancelled, request.JobID)
+	return CancelJobResult{}, nil
+}
+
+func (h *testWorkflowJobHandler) getCancelled() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.cancelled...)
+}
+
+func (h *testWorkflowJobHandler) getSubmitted(jobID string) model.Job {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.submitted[jobID]
+}
+
+type WorkflowSuite struct {
+	suite.Suite
+	ctx      context.Context
+	jobStore jobstore.Store
+	handler  *testWorkflowJobHandler
+	manager  *workflowManager
+}
+
+func TestWorkflowSuite(t *testing.T) {
+	suite.Run(t, new(WorkflowSuite))
+}
+
+func (s *WorkflowSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.jobStore = inmemory.NewJobStore()
+	s.handler = &testWorkflowJobHandler{
+		jobStore:  s.jobStore,
+		submitted: make(map[string]model.Job),
+	}
+	s.manager = s.newManager()
+}
+
+func (s *WorkflowSuite) newManager() *workflowManager {
+	return newWorkflowManager(workflowManagerParams{
+		NodeID:   "node",
+		Jobs:     s.handler,
+		JobStore: s.jobStore,
+		Interval: 10 * time.Millisecond,
+	})
+}
+
+func (s *WorkflowSuite) TestDownstreamJobWaitsForUpstream() {
+	state := s.submit(
+		model.WorkflowJobSpec{Name: "a"},
+		model.WorkflowJobSpec{Name: "b", DependsOn: []string{"a"}},
+	)
+	s.Equal(model.WorkflowJobStateSubmitted, state.Jobs[0].State)
+	s.Equal(model.WorkflowJobStatePending, state.Jobs[1].State)
+
+	s.completeJob(state.Jobs[0].JobID, model.StorageSpec{})
+	state = s.waitForJobState(state.WorkflowID, 1, model.WorkflowJobStateSubmitted)
+	s.Equal(model.WorkflowJobStateCompleted, state.Jobs[0].State)
+
+	s.completeJob(state.Jobs[1].JobID, model.StorageSpec{})
+	s.Eventually(func() bool {
+		state, err := s.manager.get(s.ctx, state.WorkflowID)
+		return err == nil && state.State == model.WorkflowStateCompleted
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (s *WorkflowSuite) TestUpstreamResultIsResolved() {
+	state := s.submit(
+		model.WorkflowJobSpec{Name: "a"},
+		model.WorkflowJobSpec{Name: "b", Spec: model.Spec{Inputs: []model.StorageSpec{
+			{Path: "/inputs", Upstream: &model.UpstreamResult{Job: "a", ShardIndex: 0}},
+		}}},
+	)
+	result := model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmResult", Name: "outputs"}
+	s.completeJob(state.Jobs[0].JobID, result)
+
+	state = s.waitForJobState(state.WorkflowID, 1, model.WorkflowJobStateSubmitted)
+	inputs := s.handler.getSubmitted(state.Jobs[1].JobID).Spec.Inputs
+	s.Require().Len(inputs, 1)
+	s.Equal(model.StorageSpec{StorageSource: model.StorageSourceIPFS, CID: "QmResult", Name: "outputs", Path: "/inputs"}, inputs[0])
+}
+
+func (s *WorkflowSuite) TestMissingUpstreamResultFailsJob() {
+	state := s.submit(
+		model.WorkflowJobSpec{Name: "a"},
+		model.WorkflowJobSpec{Name: "b", Spec: model.Spec{Inputs: []model.StorageSpec{
+			{Path: "/inputs", Upstream: &model.UpstreamResult{Job: "a", ShardIndex: 0}},
+		}}},
+	)
+	s.completeJob(state.Jobs[0].JobID, model.StorageSpec{})
+
+	state = s.waitForJobState(state.WorkflowID, 1, model.WorkflowJobStateError)
+	s.Equal(model.WorkflowStateError, state.State)
+}
+
+func (s *WorkflowSuite) TestFailurePropagatesDownstream() {
+	state := s.submit(
+		model.WorkflowJobSpec{Name: "a"},
+		model.WorkflowJobSpec{Name: "b", DependsOn: []string{"a"}},
+		model.WorkflowJobSpec{Name: "c", DependsOn: []string{"b"}},
+		model.WorkflowJobSpec{Name: "d"},
+	)
+	s.updateJobState(state.Jobs[0].JobID, model.JobStateError)
+
+	state = s.waitForJobState(state.WorkflowID, 2, model.WorkflowJobStateError)
+	s.Equal(model.WorkflowJobStateError, state.Jobs[1].State)
+	s.Equal("dependency b failed", state.Jobs[2].Message)
+	// independent jobs keep running
+	s.Equal(model.WorkflowJobStateSubmitted, state.Jobs[3].State)
+	s.Equal(model.WorkflowStateInProgress, state.State)
+}
+
+func (s *WorkflowSuite) TestCancellationPropagatesDownstream() {
+	state := s.submit(
+		model.WorkflowJobSpec{Name: "a"},
+		model.WorkflowJobSpec{Name: "b", DependsOn: []string{"a"}},
+	)
+	s.updateJobState(state.Jobs[0].JobID, model.JobStateCancelled)
+
+	state = s.waitForJobState(state.WorkflowID, 1, model.WorkflowJobStateCancelled)
+	s.Equal(model.WorkflowStateCancelled, state.State)
+}
+
+func (s *WorkflowSuite) TestCancelWorkflow() {
+	state := s.submit(
+		model.WorkflowJobSpec{Name: "a"},
+		model.WorkflowJobSpec{Name: "b", DependsOn: []string{"a"}},
+	)
+
+	state, err := s.manager.cancel(s.ctx, CancelWorkflowRequest{WorkflowID: state.WorkflowID})
+	s.Require().NoError(err)
+	s.Equal(model.WorkflowStateCancelled, state.State)
+	s.Equal(model.WorkflowJobStateCancelled, state.Jobs[0].State)
+	s.Equal(model.WorkflowJobStateCancelled, state.Jobs[1].State)
+	s.Equal([]string{state.Jobs[0].JobID}, s.handler.cancelled)
+}
+
+func (s *WorkflowSuite) TestJobsAreSubmittedAndCanceledWithoutLock() {
+	checkLock := func() {
+		s.True(s.manager.mu.TryLock(), "job submitted or canceled while holding the lock")
+		s.manager.mu.Unlock()
+	}
+	s.handler.onSubmit = checkLock
+	s.handler.onCancel = checkLock
+	state := s.submit(
+		model.WorkflowJobSpec{Name: "a"},
+		model.WorkflowJobSpec{Name: "b", DependsOn: []string{"a"}},
+	)
+	s.completeJob(state.Jobs[0].JobID, model.StorageSpec{})
+	state = s.waitForJobState(state.WorkflowID, 1, model.WorkflowJobStateSubmitted)
+
+	_, err := s.manager.cancel(s.ctx, CancelWorkflowRequest{WorkflowID: state.WorkflowID})
+	s.Require().NoError(err)
+	s.Equal([]string{state.Jobs[1].JobID}, s.handler.getCancelled())
+}
+
+func (s *WorkflowSuite) TestJobSubmittedWhileCancelingIsCanceled() {
+	state := s.submit(
+		model.WorkflowJobSpec{Name: "a"},
+		model.WorkflowJobSpec{Name: "b", DependsOn: []string{"a"}},
+	)
+	submitting, release := make(chan struct{}), make(chan struct{})
+	s.handler.onSubmit = func() {
+		close(submitting)
+		<-release
+	}
+	s.completeJob(state.Jobs[0].JobID, model.StorageSpec{})
+
+	// the workflow can be canceled while the downstream job is being submitted
+	<-submitting
+	state, err := s.manager.cancel(s.ctx, CancelWorkflowRequest{WorkflowID: state.WorkflowID})
+	s.Require().NoError(err)
+	s.Equal(model.WorkflowStateCancelled, state.State)
+	s.Equal(model.WorkflowJobStateCancelled, state.Jobs[1].State)
+	close(release)
+
+	s.Eventually(func() bool {
+		return len(s.handler.getCancelled()) == 1
+	}, time.Second, 10*time.Millisecond)
+	s.Len(s.handler.submitted, 2)
+	state, err = s.manager.get(s.ctx, state.WorkflowID)
+	s.Require().NoError(err)
+	s.Equal(model.WorkflowJobStateCancelled, state.Jobs[1].State)
+}
+
+func (s *WorkflowSuite) TestFinishedWorkflowsAreOnlyKeptInJobStore() {
+	state := s.submit(model.WorkflowJobSpec{Name: "a"})
+	s.completeJob(state.Jobs[0].JobID, model.StorageSpec{})
+	s.Eventually(func() bool {
+		s.manager.mu.Lock()
+		defer s.manager.mu.Unlock()
+		return len(s.manager.workflows) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	state, err := s.manager.get(s.ctx, state.WorkflowID)
+	s.Require().NoError(err)
+	s.Equal(model.WorkflowStateCompleted, state.State)
+
+	// canceling a finished workflow doesn't change it
+	state, err = s.manager.cancel(s.ctx, CancelWorkflowRequest{WorkflowID: state.WorkflowID})
+	s.Require().NoError(err)
+	s.Equal(model.WorkflowStateCompleted, state.State)
+}
+
+func (s *WorkflowSuite) TestResumeFromJobStore() {
+	// the requester that stops never checks the progress of the workflow
+	s.manager.interval = time.Hour
+	state := s.submit(
+		model.WorkflowJobSpec{Name: "a"},
+		model.WorkflowJobSpec{Name: "b", DependsOn: []string{"a"}},
+	)
+
+	// a restarted requester resumes the workflows in progress that were submitted to it
+	s.manager = s.newManager()
+	s.Require().NoError(s.manager.resume(s.ctx))
+	s.completeJob(state.Jobs[0].JobID, model.StorageSpec{})
+	state = s.waitForJobState(state.WorkflowID, 1, model.WorkflowJobStateSubmitted)
+	s.Equal(model.WorkflowJobStateCompleted, state.Jobs[0].State)
+
+	// other requesters can read the workflow, but don't track it
+	other := newWorkflowManager(workflowManagerParams{
+		NodeID:   "other",
+		Jobs:     s.handler,
+		JobStore: s.jobStore,
+		Interval: 10 * time.Millisecond,
+	})
+	s.Require().NoError(other.resume(s.ctx))
+	s.Empty(other.workflows)
+	_, err := other.cancel(s.ctx, CancelWorkflowRequest{WorkflowID: state.WorkflowID})
+	s.Error(err)
+}
+
+func (s *WorkflowSuite) TestUnknownWorkflow() {
+	_, err := s.manager.get(s.ctx, "unknown")
+	s.ErrorAs(err, &ErrWorkflowNotFound{})
+}
+
+func (s *WorkflowSuite) submit(jobs ...model.WorkflowJobSpec) model.WorkflowState {
+	state, err := s.manager.submit(s.ctx, model.WorkflowCreatePayload{
+		ClientID:   "client",
+		APIVersion: model.APIVersionLatest().String(),
+		Spec:       &model.WorkflowSpec{Jobs: jobs},
+	})
+	s.Require().NoError(err)
+	return state
+}
+
+func (s *WorkflowSuite) completeJob(jobID string, result model.StorageSpec) {
+	execution := model.ExecutionState{
+		JobID:            jobID,
+		NodeID:           "node",
+		ComputeReference: uuid.NewString(),
+		State:            model.ExecutionStateCompleted,
+		PublishedResult:  result,
+	}
+	s.Require().NoError(s.jobStore.CreateExecution(s.ctx, execution))
+	s.updateJobState(jobID, model.JobStateCompleted)
+}
+
+func (s *WorkflowSuite) updateJobState(jobID string, newState model.JobStateType) {
+	s.Require().NoError(s.jobStore.UpdateJobState(s.ctx, jobstore.UpdateJobStateRequest{
+		JobID:    jobID,
+		NewState: newState,
+	}))
+}
+
+func (s *WorkflowSuite) waitForJobState(
+	workflowID string, jobIndex int, expected model.WorkflowJobStateType) model.WorkflowState {
+	var state model.WorkflowState
+	s.Require().Eventually(func() bool {
+		var err error
+		state, err = s.manager.get(s.ctx, workflowID)
+		return err == nil && state.Jobs[jobIndex].State == expected
+	}, time.Second, 10*time.Millisecond)
+	return state
+}
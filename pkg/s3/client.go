@@ -0,0 +1,179 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/filecoin-project/bacalhau/pkg/util/closer"
+)
+
+// Config configures how to access S3-compatible storage. Jobs only reference buckets and objects, so that
+// credentials never end up in job specs.
+type Config struct {
+	// Endpoint of an S3-compatible service, e.g. a MinIO server. Uses AWS S3 when empty.
+	Endpoint string
+	// Region of the buckets. Uses the region of the AWS environment or shared config when empty.
+	Region string
+	// Credentials used to access the buckets. Uses the AWS environment or shared config when empty.
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// NewClient returns a client of the S3-compatible service of the config.
+func NewClient(ctx context.Context, config Config) (*awss3.Client, error) {
+	var loadOptions []func(*awsconfig.LoadOptions) error
+	if config.Region != "" {
+		loadOptions = append(loadOptions, awsconfig.WithRegion(config.Region))
+	}
+	if config.AccessKeyID != "" {
+		loadOptions = append(loadOptions, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(config.AccessKeyID, config.SecretAccessKey, "")))
+	}
+	awsConfig, err := awsconfig.LoadDefaultConfig(ctx, loadOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load S3 config: %w", err)
+	}
+	if awsConfig.Region == "" {
+		// the SDK requires a region, which S3-compatible services usually ignore
+		awsConfig.Region = "us-east-1"
+	}
+
+	return awss3.NewFromConfig(awsConfig, func(options *awss3.Options) {
+		if config.Endpoint != "" {
+			options.EndpointResolver = awss3.EndpointResolverFromURL(config.Endpoint)
+			// S3-compatible services don't usually support virtual-hosted buckets
+			options.UsePathStyle = true
+		}
+	}), nil
+}
+
+// DownloadObject writes the content of an object to the file, creating its parent directories.
+func DownloadObject(ctx context.Context, client *awss3.Client, bucket, key, filePath string) error {
+	res, err := client.GetObject(ctx, &awss3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to download object %s of bucket %s: %w", key, bucket, err)
+	}
+	defer closer.CloseWithLogOnError("object", res.Body)
+
+	if err = os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return err
+	}
+	w, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", filePath, err)
+	}
+	defer closer.CloseWithLogOnError("file", w)
+
+	// stream the object to the file without fully loading it into memory
+	if _, err = io.Copy(w, res.Body); err != nil {
+		return fmt.Errorf("failed to write to file %s: %w", filePath, err)
+	}
+	return w.Sync()
+}
+
+// ResumeObjectDownload downloads a listed object to a file like DownloadObject, but skips files that were already
+// fully downloaded, and continues files that were partially downloaded if the object didn't change since.
+func ResumeObjectDownload(ctx context.Context, client *awss3.Client, bucket string, object Object, filePath string) error {
+	info, err := os.Stat(filePath)
+	if err != nil || info.Size() > object.Size {
+		return DownloadObject(ctx, client, bucket, object.Key, filePath)
+	}
+	if info.Size() == object.Size {
+		return nil
+	}
+
+	res, err := client.GetObject(ctx, &awss3.GetObjectInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(object.Key),
+		Range:   aws.String(fmt.Sprintf("bytes=%d-", info.Size())),
+		IfMatch: aws.String(object.ETag),
+	})
+	if err != nil {
+		// the object may have changed, so download it again
+		return DownloadObject(ctx, client, bucket, object.Key, filePath)
+	}
+	defer closer.CloseWithLogOnError("object", res.Body)
+
+	w, err := os.OpenFile(filePath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer closer.CloseWithLogOnError("file", w)
+	if _, err = io.Copy(w, res.Body); err != nil {
+		return fmt.Errorf("failed to write to file %s: %w", filePath, err)
+	}
+	return w.Sync()
+}
+
+// Object is an object listed under a prefix.
+type Object struct {
+	Key string
+	// RelativePath is the slash separated path of the object relative to the last directory of the prefix
+	RelativePath string
+	Size         int64
+	ETag         string
+}
+
+// ListObjects returns the objects whose key starts with the prefix, skipping directory markers.
+func ListObjects(ctx context.Context, client *awss3.Client, bucket, prefix string) ([]Object, error) {
+	dir := PrefixDir(prefix)
+
+	var objects []Object
+	paginator := awss3.NewListObjectsV2Paginator(client, &awss3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects of bucket %s: %w", bucket, err)
+		}
+		for _, content := range page.Contents {
+			key := aws.ToString(content.Key)
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+			relativePath := strings.TrimPrefix(key, dir)
+			if !isLocalPath(relativePath) {
+				return nil, fmt.Errorf("object key %s of bucket %s can not be mapped to a file", key, bucket)
+			}
+			objects = append(objects, Object{
+				Key:          key,
+				RelativePath: relativePath,
+				Size:         content.Size,
+				ETag:         aws.ToString(content.ETag),
+			})
+		}
+	}
+	return objects, nil
+}
+
+// PrefixDir returns the prefix up to its last directory. Objects are mapped to files relative to it, so that
+// a prefix like "data/2023-" maps "data/2023-01.csv" to "2023-01.csv".
+func PrefixDir(prefix string) string {
+	return prefix[:strings.LastIndex(prefix, "/")+1]
+}
+
+// isLocalPath returns whether the slash separated path stays within the directory it is relative to.
+func isLocalPath(path string) bool {
+	if path == "" || strings.HasPrefix(path, "/") {
+		return false
+	}
+	for _, part := range strings.Split(path, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
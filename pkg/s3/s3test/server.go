@@ -0,0 +1,139 @@
+// Package s3test provides an in-memory stand-in for an S3-compatible service, to test code that reads and
+// writes buckets without a real service.
+package s3test
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	s3helper "github.com/filecoin-project/bacalhau/pkg/s3"
+)
+
+// Server serves the objects of a single bucket with the subset of the S3 API used by bacalhau.
+type Server struct {
+	URL    string
+	Bucket string
+
+	mu      sync.Mutex
+	objects map[string]string
+}
+
+// NewServer starts a server for the bucket with the given objects, which is closed at the end of the test.
+func NewServer(t testing.TB, bucket string, objects map[string]string) *Server {
+	s := &Server{
+		Bucket:  bucket,
+		objects: map[string]string{},
+	}
+	for key, content := range objects {
+		s.objects[key] = content
+	}
+	server := httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(server.Close)
+	s.URL = server.URL
+	return s
+}
+
+// Config returns the config of a client of the server.
+func (s *Server) Config() s3helper.Config {
+	return s3helper.Config{
+		Endpoint:        s.URL,
+		Region:          "us-east-1",
+		AccessKeyID:     "access-key",
+		SecretAccessKey: "secret-key",
+	}
+}
+
+// Objects returns a copy of the objects of the bucket.
+func (s *Server) Objects() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	objects := make(map[string]string, len(s.objects))
+	for key, content := range s.objects {
+		objects[key] = content
+	}
+	return objects
+}
+
+type listBucketResult struct {
+	XMLName     xml.Name           `xml:"ListBucketResult"`
+	Name        string             `xml:"Name"`
+	Prefix      string             `xml:"Prefix"`
+	KeyCount    int                `xml:"KeyCount"`
+	IsTruncated bool               `xml:"IsTruncated"`
+	Contents    []listBucketObject `xml:"Contents"`
+}
+
+type listBucketObject struct {
+	Key  string `xml:"Key"`
+	Size int    `xml:"Size"`
+	ETag string `xml:"ETag"`
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s.Bucket {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case key == "" && r.URL.Query().Get("list-type") == "2":
+		s.list(w, r.URL.Query().Get("prefix"))
+	case r.Method == http.MethodPut:
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.objects[key] = string(content)
+		w.Header().Set("ETag", etag(string(content)))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		content, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// serve the content with support for range and conditional requests
+		w.Header().Set("ETag", etag(content))
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(content))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, prefix string) {
+	result := listBucketResult{Name: s.Bucket, Prefix: prefix}
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		result.Contents = append(result.Contents, listBucketObject{Key: key, Size: len(s.objects[key]), ETag: etag(s.objects[key])})
+	}
+	result.KeyCount = len(keys)
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func etag(content string) string {
+	hash := md5.Sum([]byte(content)) //nolint:gosec // S3 ETags are MD5 hashes
+	return `"` + hex.EncodeToString(hash[:]) + `"`
+}
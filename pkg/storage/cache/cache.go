@@ -0,0 +1,297 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/filecoin-project/bacalhau/pkg/config"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/storage"
+	"github.com/filecoin-project/bacalhau/pkg/storage/util"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/rs/zerolog/log"
+)
+
+type Params struct {
+	// MaxSize is the number of bytes of prepared inputs kept on disk. Inputs used by running executions are
+	// never evicted, so the cache can temporarily exceed it.
+	MaxSize uint64
+}
+
+// Cache keeps the volumes prepared by storages on the disk of the node, so that executions using the same
+// inputs don't prepare them again. Volumes are shared by concurrent executions, and the least recently used
+// volumes that are not used by any execution are evicted once the cache exceeds its size.
+type Cache struct {
+	dir     string
+	maxSize uint64
+
+	mu       sync.Mutex
+	entries  map[string]*entry
+	lru      *list.List // of *entry, most recently used first
+	inflight map[string]*fill
+	size     uint64
+	hits     uint64
+	misses   uint64
+}
+
+type entry struct {
+	id     string
+	key    string
+	source string
+	// the target of the volume relative to the path of the storage spec it was prepared for
+	targetSuffix string
+	volumeType   storage.StorageVolumeConnectorType
+	version      string
+	size         uint64
+	refs         int
+	element      *list.Element
+}
+
+// fill is a volume being prepared, that other executions of the same input wait for
+type fill struct {
+	done chan struct{}
+	err  error
+}
+
+// Stats reports the usage of the cache.
+type Stats struct {
+	Entries int    `json:"Entries"`
+	Size    uint64 `json:"Size"`
+	MaxSize uint64 `json:"MaxSize"`
+	Hits    uint64 `json:"Hits"`
+	Misses  uint64 `json:"Misses"`
+}
+
+func NewCache(cm *system.CleanupManager, params Params) (*Cache, error) {
+	dir, err := os.MkdirTemp(config.GetStoragePath(), "bacalhau-cache")
+	if err != nil {
+		return nil, err
+	}
+	cm.RegisterCallback(func() error {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("unable to remove cache folder: %w", err)
+		}
+		return nil
+	})
+	return newCache(dir, params), nil
+}
+
+func newCache(dir string, params Params) *Cache {
+	return &Cache{
+		dir:      dir,
+		maxSize:  params.MaxSize,
+		entries:  make(map[string]*entry),
+		lru:      list.New(),
+		inflight: make(map[string]*fill),
+	}
+}
+
+// prepare returns the cached volume of the key for the given path, or prepares it with the given function and
+// moves it into the cache. The volume must be released once the execution is done with it. Volumes that can't
+// be moved into the cache are returned as prepared.
+func (c *Cache) prepare(
+	ctx context.Context,
+	key string,
+	path string,
+	prepareFn func() (storage.StorageVolume, error),
+	cleanupFn func(storage.StorageVolume) error,
+) (storage.StorageVolume, error) {
+	id := entryID(key)
+	for {
+		c.mu.Lock()
+		if e, ok := c.entries[id]; ok {
+			e.refs++
+			c.lru.MoveToFront(e.element)
+			c.hits++
+			c.mu.Unlock()
+			log.Ctx(ctx).Debug().Str("key", key).Msg("Input cache hit")
+			return e.volume(path), nil
+		}
+		if f, ok := c.inflight[id]; ok {
+			// another execution is preparing the same input, so wait for it and look it up again
+			c.mu.Unlock()
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return storage.StorageVolume{}, ctx.Err()
+			}
+			if f.err != nil {
+				return storage.StorageVolume{}, f.err
+			}
+			continue
+		}
+		f := &fill{done: make(chan struct{})}
+		c.inflight[id] = f
+		c.misses++
+		c.mu.Unlock()
+
+		log.Ctx(ctx).Debug().Str("key", key).Msg("Input cache miss")
+		volume, err := prepareFn()
+		var e *entry
+		if err == nil {
+			e = c.store(ctx, id, key, path, volume, cleanupFn)
+		}
+
+		c.mu.Lock()
+		delete(c.inflight, id)
+		if e != nil {
+			e.refs = 1
+			e.element = c.lru.PushFront(e)
+			c.entries[id] = e
+			c.size += e.size
+			c.evictLocked(ctx)
+		}
+		f.err = err
+		close(f.done)
+		c.mu.Unlock()
+
+		if err != nil {
+			return storage.StorageVolume{}, err
+		}
+		if e == nil {
+			return volume, nil
+		}
+		return e.volume(path), nil
+	}
+}
+
+// store moves a prepared volume into the cache, and returns nil if it couldn't.
+func (c *Cache) store(
+	ctx context.Context,
+	id, key, path string,
+	volume storage.StorageVolume,
+	cleanupFn func(storage.StorageVolume) error,
+) *entry {
+	if !strings.HasPrefix(volume.Target, path) {
+		// the volume can't be shared with executions mounting the input at other paths
+		return nil
+	}
+	entryDir := filepath.Join(c.dir, id)
+	source := filepath.Join(entryDir, filepath.Base(volume.Source))
+	err := os.MkdirAll(entryDir, os.ModePerm)
+	if err == nil {
+		err = os.Rename(volume.Source, source)
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("Failed to move input into the cache, not caching it")
+		_ = os.RemoveAll(entryDir)
+		return nil
+	}
+
+	// let the storage remove what is left of the volume, such as the folder it was prepared in
+	if err = cleanupFn(volume); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("Failed to clean up input after moving it into the cache")
+	}
+
+	size, err := util.DirSize(source)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("Failed to get the size of cached input")
+	}
+	return &entry{
+		id:           id,
+		key:          key,
+		source:       source,
+		targetSuffix: strings.TrimPrefix(volume.Target, path),
+		volumeType:   volume.Type,
+		version:      volume.Version,
+		size:         size,
+	}
+}
+
+// contains returns whether a volume of the key is cached, and its size.
+func (c *Cache) contains(key string) (uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[entryID(key)]
+	if !ok {
+		return 0, false
+	}
+	return e.size, true
+}
+
+// owns returns whether the volume was returned by the cache.
+func (c *Cache) owns(volume storage.StorageVolume) bool {
+	relativePath, err := filepath.Rel(c.dir, volume.Source)
+	return err == nil && relativePath != "." && !strings.HasPrefix(relativePath, "..")
+}
+
+// release marks a volume returned by the cache as no longer used by an execution.
+func (c *Cache) release(ctx context.Context, volume storage.StorageVolume) {
+	relativePath, _ := filepath.Rel(c.dir, volume.Source)
+	id := strings.Split(relativePath, string(filepath.Separator))[0]
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[id]
+	if !ok {
+		return
+	}
+	if e.refs > 0 {
+		e.refs--
+	}
+	c.evictLocked(ctx)
+}
+
+// evictLocked removes the least recently used volumes that are not used by any execution
+// until the cache fits its size.
+func (c *Cache) evictLocked(ctx context.Context) {
+	for element := c.lru.Back(); element != nil && c.size > c.maxSize; {
+		e := element.Value.(*entry)
+		element = element.Prev()
+		if e.refs > 0 {
+			continue
+		}
+		c.lru.Remove(e.element)
+		delete(c.entries, e.id)
+		c.size -= e.size
+		if err := os.RemoveAll(filepath.Join(c.dir, e.id)); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("key", e.key).Msg("Failed to remove evicted input")
+		}
+		log.Ctx(ctx).Debug().Str("key", e.key).Uint64("size", e.size).Msg("Evicted input from the cache")
+	}
+}
+
+// Stats returns the usage of the cache.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{
+		Entries: len(c.entries),
+		Size:    c.size,
+		MaxSize: c.maxSize,
+		Hits:    c.hits,
+		Misses:  c.misses,
+	}
+}
+
+// GetDebugInfo implements model.DebugInfoProvider
+func (c *Cache) GetDebugInfo() (model.DebugInfo, error) {
+	return model.DebugInfo{
+		Component: "InputCache",
+		Info:      c.Stats(),
+	}, nil
+}
+
+func (e *entry) volume(path string) storage.StorageVolume {
+	return storage.StorageVolume{
+		Type:    e.volumeType,
+		Source:  e.source,
+		Target:  path + e.targetSuffix,
+		Version: e.version,
+	}
+}
+
+func entryID(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// compile-time check that Cache implements model.DebugInfoProvider
+var _ model.DebugInfoProvider = (*Cache)(nil)
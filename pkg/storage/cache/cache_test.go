@@ -0,0 +1,220 @@
+//go:build unit || !integration
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/logger"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/storage"
+	"github.com/filecoin-project/bacalhau/pkg/storage/noop"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeStorage prepares a file of the size set in the metadata of the storage spec, and is cacheable
+// when the storage spec has a CID.
+type fakeStorage struct {
+	noop.NoopStorage
+	dir      string
+	prepared atomic.Int32
+	cleaned  atomic.Int32
+}
+
+func (f *fakeStorage) CacheKey(_ context.Context, spec model.StorageSpec) (string, bool, error) {
+	return spec.CID, spec.CID != "", nil
+}
+
+func (f *fakeStorage) HasStorageLocally(context.Context, model.StorageSpec) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeStorage) GetVolumeSize(context.Context, model.StorageSpec) (uint64, error) {
+	return 0, nil
+}
+
+func (f *fakeStorage) PrepareStorage(_ context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+	f.prepared.Add(1)
+	dir, err := os.MkdirTemp(f.dir, "*")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+	var size int
+	_, _ = fmt.Sscan(spec.Metadata["size"], &size)
+	source := filepath.Join(dir, "file")
+	if err = os.WriteFile(source, make([]byte, size), 0600); err != nil {
+		return storage.StorageVolume{}, err
+	}
+	return storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: source,
+		Target: spec.Path,
+	}, nil
+}
+
+func (f *fakeStorage) CleanupStorage(_ context.Context, _ model.StorageSpec, volume storage.StorageVolume) error {
+	f.cleaned.Add(1)
+	return os.RemoveAll(filepath.Dir(volume.Source))
+}
+
+type CacheSuite struct {
+	suite.Suite
+	delegate *fakeStorage
+	cache    *Cache
+	storage  storage.Storage
+}
+
+func TestCacheSuite(t *testing.T) {
+	suite.Run(t, new(CacheSuite))
+}
+
+// Before each test
+func (s *CacheSuite) SetupTest() {
+	logger.ConfigureTestLogging(s.T())
+	s.delegate = &fakeStorage{dir: s.T().TempDir()}
+	s.cache = newCache(s.T().TempDir(), Params{MaxSize: 100})
+	s.storage = Wrap(s.delegate, s.cache)
+}
+
+func (s *CacheSuite) TestWrapNotCacheable() {
+	delegate := &noop.NoopStorage{}
+	s.Same(delegate, Wrap(delegate, s.cache))
+	s.Same(s.delegate, Wrap(s.delegate, nil))
+}
+
+func (s *CacheSuite) TestPrepareHit() {
+	ctx := context.Background()
+	spec := fakeSpec("a", 10, "/inputs/a")
+
+	first, err := s.storage.PrepareStorage(ctx, spec)
+	s.Require().NoError(err)
+	s.FileExists(first.Source)
+	s.Equal("/inputs/a", first.Target)
+
+	hasLocally, err := s.storage.HasStorageLocally(ctx, spec)
+	s.Require().NoError(err)
+	s.True(hasLocally)
+
+	size, err := s.storage.GetVolumeSize(ctx, spec)
+	s.Require().NoError(err)
+	s.Equal(uint64(10), size)
+
+	// the same content mounted at another path is served from the cache
+	second, err := s.storage.PrepareStorage(ctx, fakeSpec("a", 10, "/other"))
+	s.Require().NoError(err)
+	s.Equal(first.Source, second.Source)
+	s.Equal("/other", second.Target)
+
+	s.Equal(int32(1), s.delegate.prepared.Load())
+	s.Equal(Stats{Entries: 1, Size: 10, MaxSize: 100, Hits: 1, Misses: 1}, s.cache.Stats())
+
+	// cleaning up only releases the cached volume
+	s.Require().NoError(s.storage.CleanupStorage(ctx, spec, first))
+	s.Require().NoError(s.storage.CleanupStorage(ctx, spec, second))
+	s.FileExists(first.Source)
+	s.Equal(int32(1), s.delegate.cleaned.Load())
+}
+
+func (s *CacheSuite) TestPrepareNotCacheable() {
+	ctx := context.Background()
+	spec := fakeSpec("", 10, "/inputs")
+
+	volume, err := s.storage.PrepareStorage(ctx, spec)
+	s.Require().NoError(err)
+	_, err = s.storage.PrepareStorage(ctx, spec)
+	s.Require().NoError(err)
+	s.Equal(int32(2), s.delegate.prepared.Load())
+	s.Equal(0, s.cache.Stats().Entries)
+
+	s.Require().NoError(s.storage.CleanupStorage(ctx, spec, volume))
+	s.NoFileExists(volume.Source)
+}
+
+func (s *CacheSuite) TestConcurrentPrepare() {
+	ctx := context.Background()
+	spec := fakeSpec("a", 10, "/inputs")
+
+	var wg sync.WaitGroup
+	sources := make([]string, 10)
+	for i := range sources {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			volume, err := s.storage.PrepareStorage(ctx, spec)
+			s.NoError(err)
+			sources[i] = volume.Source
+		}(i)
+	}
+	wg.Wait()
+
+	s.Equal(int32(1), s.delegate.prepared.Load())
+	for _, source := range sources {
+		s.Equal(sources[0], source)
+	}
+	s.Equal(Stats{Entries: 1, Size: 10, MaxSize: 100, Hits: 9, Misses: 1}, s.cache.Stats())
+}
+
+func (s *CacheSuite) TestEvictLeastRecentlyUsed() {
+	ctx := context.Background()
+	volumes := make(map[string]storage.StorageVolume)
+	for _, cid := range []string{"a", "b", "c"} {
+		spec := fakeSpec(cid, 40, "/inputs")
+		volume, err := s.storage.PrepareStorage(ctx, spec)
+		s.Require().NoError(err)
+		s.Require().NoError(s.storage.CleanupStorage(ctx, spec, volume))
+		volumes[cid] = volume
+
+		if cid == "b" {
+			// use a again, so that b is the least recently used
+			volume, err = s.storage.PrepareStorage(ctx, fakeSpec("a", 40, "/inputs"))
+			s.Require().NoError(err)
+			s.Require().NoError(s.storage.CleanupStorage(ctx, spec, volume))
+		}
+	}
+
+	s.FileExists(volumes["a"].Source)
+	s.NoFileExists(volumes["b"].Source)
+	s.FileExists(volumes["c"].Source)
+	s.Equal(uint64(80), s.cache.Stats().Size)
+
+	hasLocally, err := s.storage.HasStorageLocally(ctx, fakeSpec("b", 40, "/inputs"))
+	s.Require().NoError(err)
+	s.False(hasLocally)
+}
+
+func (s *CacheSuite) TestNoEvictionWhileInUse() {
+	ctx := context.Background()
+	specA := fakeSpec("a", 80, "/inputs")
+	volumeA, err := s.storage.PrepareStorage(ctx, specA)
+	s.Require().NoError(err)
+
+	specB := fakeSpec("b", 80, "/inputs")
+	volumeB, err := s.storage.PrepareStorage(ctx, specB)
+	s.Require().NoError(err)
+
+	// both volumes are in use, so the cache exceeds its size
+	s.FileExists(volumeA.Source)
+	s.FileExists(volumeB.Source)
+	s.Equal(uint64(160), s.cache.Stats().Size)
+
+	// a is evicted once released
+	s.Require().NoError(s.storage.CleanupStorage(ctx, specA, volumeA))
+	s.NoFileExists(volumeA.Source)
+	s.FileExists(volumeB.Source)
+	s.Equal(Stats{Entries: 1, Size: 80, MaxSize: 100, Misses: 2}, s.cache.Stats())
+}
+
+func fakeSpec(cid string, size int, path string) model.StorageSpec {
+	return model.StorageSpec{
+		StorageSource: model.StorageSourceIPFS,
+		CID:           cid,
+		Path:          path,
+		Metadata:      map[string]string{"size": fmt.Sprint(size)},
+	}
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// cachingStorage serves the volumes of a storage from the cache of the node, when the storage can
+// identify the content of its storage specs.
+type cachingStorage struct {
+	delegate  storage.Storage
+	cacheable storage.Cacheable
+	cache     *Cache
+}
+
+// Wrap returns a storage that caches the volumes prepared by the delegate, if it implements storage.Cacheable.
+// Otherwise, the delegate is returned as is.
+func Wrap(delegate storage.Storage, cache *Cache) storage.Storage {
+	cacheable, ok := delegate.(storage.Cacheable)
+	if !ok || cache == nil {
+		return delegate
+	}
+	return &cachingStorage{
+		delegate:  delegate,
+		cacheable: cacheable,
+		cache:     cache,
+	}
+}
+
+func (s *cachingStorage) IsInstalled(ctx context.Context) (bool, error) {
+	return s.delegate.IsInstalled(ctx)
+}
+
+// HasStorageLocally reports cached inputs as local, so that nodes that already have an input prefer jobs using it.
+func (s *cachingStorage) HasStorageLocally(ctx context.Context, spec model.StorageSpec) (bool, error) {
+	if key, ok := s.cacheKey(ctx, spec); ok {
+		if _, found := s.cache.contains(key); found {
+			return true, nil
+		}
+	}
+	return s.delegate.HasStorageLocally(ctx, spec)
+}
+
+func (s *cachingStorage) GetVolumeSize(ctx context.Context, spec model.StorageSpec) (uint64, error) {
+	if key, ok := s.cacheKey(ctx, spec); ok {
+		if size, found := s.cache.contains(key); found {
+			return size, nil
+		}
+	}
+	return s.delegate.GetVolumeSize(ctx, spec)
+}
+
+func (s *cachingStorage) PrepareStorage(ctx context.Context, spec model.StorageSpec) (storage.StorageVolume, error) {
+	key, ok := s.cacheKey(ctx, spec)
+	if !ok {
+		return s.delegate.PrepareStorage(ctx, spec)
+	}
+	return s.cache.prepare(ctx, key, spec.Path,
+		func() (storage.StorageVolume, error) {
+			return s.delegate.PrepareStorage(ctx, spec)
+		},
+		func(volume storage.StorageVolume) error {
+			return s.delegate.CleanupStorage(ctx, spec, volume)
+		},
+	)
+}
+
+// CleanupStorage releases cached volumes, which are only removed once evicted from the cache.
+func (s *cachingStorage) CleanupStorage(ctx context.Context, spec model.StorageSpec, volume storage.StorageVolume) error {
+	if s.cache.owns(volume) {
+		s.cache.release(ctx, volume)
+		return nil
+	}
+	return s.delegate.CleanupStorage(ctx, spec, volume)
+}
+
+func (s *cachingStorage) Upload(ctx context.Context, localPath string) (model.StorageSpec, error) {
+	return s.delegate.Upload(ctx, localPath)
+}
+
+func (s *cachingStorage) Explode(ctx context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+	return s.delegate.Explode(ctx, spec)
+}
+
+func (s *cachingStorage) cacheKey(ctx context.Context, spec model.StorageSpec) (string, bool) {
+	key, ok, err := s.cacheable.CacheKey(ctx, spec)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Failed to get the cache key of input, not caching it")
+		return "", false
+	}
+	return spec.StorageSource.String() + "/" + key, ok
+}
+
+var _ storage.Storage = (*cachingStorage)(nil)
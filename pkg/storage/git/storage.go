@@ -0,0 +1,256 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/filecoin-project/bacalhau/pkg/config"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/storage"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/rs/zerolog/log"
+)
+
+// a storage driver that clones a commit of a git repository to a local
+// directory in preparation for a job to run - it will remove the folder
+// once complete
+
+// commitSHA matches full and abbreviated SHA-1 and SHA-256 commit hashes
+var commitSHA = regexp.MustCompile(`^[0-9a-fA-F]{7,64}$`)
+
+// remoteProtocols are the transports repositories can be cloned with. They only fetch content, unlike transports
+// such as ext that run commands. Local repositories are only allowed if configured, as they would let jobs read
+// repositories from the disk of the compute node.
+const remoteProtocols = "git:http:https:ssh"
+
+type Config struct {
+	// AllowLocalRepositories allows cloning repositories from the local filesystem, such as file:// URLs, which is
+	// only meant for tests
+	AllowLocalRepositories bool
+}
+
+type StorageProvider struct {
+	localDir string
+	// the value of GIT_ALLOW_PROTOCOL that git commands run with
+	allowedProtocols string
+}
+
+func NewStorage(cm *system.CleanupManager, gitConfig Config) (*StorageProvider, error) {
+	dir, err := os.MkdirTemp(config.GetStoragePath(), "bacalhau-git")
+	if err != nil {
+		return nil, err
+	}
+
+	cm.RegisterCallback(func() error {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("unable to remove storage folder: %w", err)
+		}
+		return nil
+	})
+
+	log.Debug().Str("dir", dir).Msg("Git driver created with output dir")
+
+	return newStorage(dir, gitConfig), nil
+}
+
+func newStorage(dir string, gitConfig Config) *StorageProvider {
+	allowedProtocols := remoteProtocols
+	if gitConfig.AllowLocalRepositories {
+		allowedProtocols = "file:" + allowedProtocols
+	}
+	return &StorageProvider{
+		localDir:         dir,
+		allowedProtocols: allowedProtocols,
+	}
+}
+
+// IsInstalled checks that the git command is available on the node.
+func (sp *StorageProvider) IsInstalled(context.Context) (bool, error) {
+	_, err := exec.LookPath("git")
+	return err == nil, nil
+}
+
+func (sp *StorageProvider) HasStorageLocally(context.Context, model.StorageSpec) (bool, error) {
+	return false, nil
+}
+
+// GetVolumeSize returns 0, as the size of a commit is only known once it is cloned.
+func (sp *StorageProvider) GetVolumeSize(_ context.Context, storageSpec model.StorageSpec) (uint64, error) {
+	return 0, validate(storageSpec)
+}
+
+// PrepareStorage clones the repository and checks out the commit the ref of the storage spec resolves to, or
+// only the subdirectory of the storage spec. The volume only contains the files of the commit, and its version
+// is the SHA of the commit.
+func (sp *StorageProvider) PrepareStorage(ctx context.Context, storageSpec model.StorageSpec) (storage.StorageVolume, error) {
+	if err := validate(storageSpec); err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	outputPath, err := os.MkdirTemp(sp.localDir, "*")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	repoPath := filepath.Join(outputPath, "repo")
+	commit, err := sp.checkout(ctx, storageSpec.Git, repoPath)
+	if err == nil {
+		err = os.RemoveAll(filepath.Join(repoPath, ".git"))
+	}
+
+	source := repoPath
+	if err == nil && storageSpec.Git.Subdirectory != "" {
+		source = filepath.Join(repoPath, filepath.FromSlash(path.Clean(storageSpec.Git.Subdirectory)))
+		if _, err = os.Stat(source); os.IsNotExist(err) {
+			err = fmt.Errorf("subdirectory %s does not exist at commit %s of %s",
+				storageSpec.Git.Subdirectory, commit, storageSpec.Git.Repository)
+		}
+	}
+	if err != nil {
+		_ = os.RemoveAll(outputPath)
+		return storage.StorageVolume{}, err
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("repository", storageSpec.Git.Repository).
+		Str("ref", storageSpec.Git.Ref).
+		Str("commit", commit).
+		Str("source", source).
+		Msg("Checked out repository")
+
+	return storage.StorageVolume{
+		Type:    storage.StorageVolumeConnectorBind,
+		Source:  source,
+		Target:  storageSpec.Path,
+		Version: commit,
+	}, nil
+}
+
+func (sp *StorageProvider) CleanupStorage(
+	ctx context.Context,
+	_ model.StorageSpec,
+	volume storage.StorageVolume,
+) error {
+	// remove the whole folder the repository was cloned in, not only the subdirectory that was mounted
+	relativePath, err := filepath.Rel(sp.localDir, volume.Source)
+	if err != nil || strings.HasPrefix(relativePath, "..") {
+		return fmt.Errorf("volume %s was not prepared by the git storage", volume.Source)
+	}
+	pathToCleanup := filepath.Join(sp.localDir, strings.Split(relativePath, string(filepath.Separator))[0])
+	log.Ctx(ctx).Debug().Str("Path", pathToCleanup).Msg("Cleaning up")
+	return os.RemoveAll(pathToCleanup)
+}
+
+// CacheKey implements storage.Cacheable. Only storage specs whose ref is a full commit SHA are cached, as
+// branches and tags can move.
+func (sp *StorageProvider) CacheKey(_ context.Context, storageSpec model.StorageSpec) (string, bool, error) {
+	if err := validate(storageSpec); err != nil {
+		return "", false, err
+	}
+	ref := strings.ToLower(storageSpec.Git.Ref)
+	if !commitSHA.MatchString(ref) || (len(ref) != 40 && len(ref) != 64) {
+		return "", false, nil
+	}
+	return storageSpec.Git.Repository + "#" + ref + ":" + storageSpec.Git.Subdirectory, true, nil
+}
+
+func (sp *StorageProvider) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, fmt.Errorf("not implemented")
+}
+
+func (sp *StorageProvider) Explode(_ context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+	// a repository is always mounted as a single item at the path specified in the spec
+	return []model.StorageSpec{spec}, nil
+}
+
+func validate(storageSpec model.StorageSpec) error {
+	if storageSpec.Git == nil || storageSpec.Git.Repository == "" {
+		return fmt.Errorf("git storage spec must have a repository")
+	}
+	if strings.HasPrefix(storageSpec.Git.Repository, "-") || strings.HasPrefix(storageSpec.Git.Ref, "-") {
+		return fmt.Errorf("invalid git repository %s or ref %s", storageSpec.Git.Repository, storageSpec.Git.Ref)
+	}
+	if subdirectory := storageSpec.Git.Subdirectory; subdirectory != "" {
+		cleaned := path.Clean(subdirectory)
+		if path.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return fmt.Errorf("git subdirectory %s must be a relative path within the repository", subdirectory)
+		}
+	}
+	return nil
+}
+
+// checkout clones the repository into dir and checks out the commit the ref resolves to, whose SHA it returns.
+func (sp *StorageProvider) checkout(ctx context.Context, spec *model.GitStorageSpec, dir string) (string, error) {
+	isSHA := commitSHA.MatchString(spec.Ref)
+
+	args := []string{"clone", "--quiet", "--no-checkout"}
+	if !isSHA {
+		// branches and tags can be cloned without their history
+		args = append(args, "--depth", "1")
+		if spec.Ref != "" {
+			args = append(args, "--branch", spec.Ref)
+		}
+	}
+	args = append(args, "--", spec.Repository, dir)
+	if _, err := sp.git(ctx, "", args...); err != nil {
+		return "", fmt.Errorf("failed to clone %s: %w", spec.Repository, err)
+	}
+
+	if spec.Subdirectory != "" {
+		if _, err := sp.git(ctx, dir, "sparse-checkout", "set", path.Clean(spec.Subdirectory)); err != nil {
+			return "", err
+		}
+	}
+
+	rev := "HEAD"
+	if isSHA {
+		rev = spec.Ref
+	}
+	commit, err := sp.git(ctx, dir, "rev-parse", "--verify", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s of %s: %w", rev, spec.Repository, err)
+	}
+	if isSHA && !strings.HasPrefix(commit, strings.ToLower(spec.Ref)) {
+		return "", fmt.Errorf("ref %s of %s resolved to commit %s", spec.Ref, spec.Repository, commit)
+	}
+
+	if _, err = sp.git(ctx, dir, "checkout", "--quiet", "--detach", commit); err != nil {
+		return "", err
+	}
+	head, err := sp.git(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if head != commit {
+		return "", fmt.Errorf("checked out commit %s of %s instead of %s", head, spec.Repository, commit)
+	}
+	return commit, nil
+}
+
+// git runs a git command in dir and returns its trimmed output.
+func (sp *StorageProvider) git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		// only allow the configured transports, and never wait for credentials to be typed in
+		"GIT_ALLOW_PROTOCOL="+sp.allowedProtocols,
+		"GIT_TERMINAL_PROMPT=0",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+var _ storage.Storage = (*StorageProvider)(nil)
+var _ storage.Cacheable = (*StorageProvider)(nil)
@@ -0,0 +1,190 @@
+//go:build unit || !integration
+
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/logger"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/stretchr/testify/suite"
+)
+
+type StorageSuite struct {
+	suite.Suite
+	repository string
+	// the commits of the test repository, oldest first
+	commits []string
+	sp      *StorageProvider
+}
+
+func TestStorageSuite(t *testing.T) {
+	suite.Run(t, new(StorageSuite))
+}
+
+// Before each test
+func (s *StorageSuite) SetupTest() {
+	logger.ConfigureTestLogging(s.T())
+	s.Require().NoError(system.InitConfigForTesting(s.T()))
+	if _, err := exec.LookPath("git"); err != nil {
+		s.T().Skip("git is not installed")
+	}
+
+	// a repository with a tagged commit on main, followed by a commit on main and a commit on a branch
+	dir := s.T().TempDir()
+	s.commits = nil
+	s.gitCommand(dir, "init", "--quiet", "--initial-branch=main")
+	s.writeFile(dir, "README.md", "v1")
+	s.writeFile(dir, "scripts/run.sh", "echo v1")
+	s.writeFile(dir, "docs/guide.md", "guide")
+	s.commit(dir, "v1")
+	s.gitCommand(dir, "tag", "v1.0")
+	s.writeFile(dir, "README.md", "v2")
+	s.commit(dir, "v2")
+	s.gitCommand(dir, "checkout", "--quiet", "-b", "feature")
+	s.writeFile(dir, "scripts/run.sh", "echo feature")
+	s.commit(dir, "feature")
+	s.gitCommand(dir, "checkout", "--quiet", "main")
+
+	s.repository = "file://" + dir
+	// the test repository is local, so local repositories are allowed
+	s.sp = newStorage(s.T().TempDir(), Config{AllowLocalRepositories: true})
+}
+
+func (s *StorageSuite) TestNewStorageProvider() {
+	cm := system.NewCleanupManager()
+	sp, err := NewStorage(cm, Config{})
+	s.Require().NoError(err, "failed to create storage provider")
+	s.DirExists(sp.localDir)
+	cm.Cleanup(context.Background())
+	s.NoDirExists(sp.localDir)
+}
+
+func (s *StorageSuite) TestPrepareStorage() {
+	for _, tc := range []struct {
+		name   string
+		ref    string
+		commit string
+		readme string
+		script string
+	}{
+		{name: "default branch", ref: "", commit: s.commits[1], readme: "v2", script: "echo v1"},
+		{name: "branch", ref: "feature", commit: s.commits[2], readme: "v2", script: "echo feature"},
+		{name: "tag", ref: "v1.0", commit: s.commits[0], readme: "v1", script: "echo v1"},
+		{name: "commit", ref: s.commits[2], commit: s.commits[2], readme: "v2", script: "echo feature"},
+		{name: "short commit", ref: s.commits[0][:8], commit: s.commits[0], readme: "v1", script: "echo v1"},
+	} {
+		s.Run(tc.name, func() {
+			spec := gitSpec(model.GitStorageSpec{Repository: s.repository, Ref: tc.ref})
+			volume, err := s.sp.PrepareStorage(context.Background(), spec)
+			s.Require().NoError(err)
+			s.Equal("/inputs/repo", volume.Target)
+			s.Equal(tc.commit, volume.Version)
+			s.fileContains(filepath.Join(volume.Source, "README.md"), tc.readme)
+			s.fileContains(filepath.Join(volume.Source, "scripts", "run.sh"), tc.script)
+			s.NoDirExists(filepath.Join(volume.Source, ".git"))
+
+			s.Require().NoError(s.sp.CleanupStorage(context.Background(), spec, volume))
+			s.NoDirExists(filepath.Dir(volume.Source))
+		})
+	}
+}
+
+func (s *StorageSuite) TestPrepareStorageSubdirectory() {
+	spec := gitSpec(model.GitStorageSpec{Repository: s.repository, Ref: "feature", Subdirectory: "scripts/"})
+	volume, err := s.sp.PrepareStorage(context.Background(), spec)
+	s.Require().NoError(err)
+	s.Equal(s.commits[2], volume.Version)
+	s.fileContains(filepath.Join(volume.Source, "run.sh"), "echo feature")
+	// other directories are not checked out
+	s.NoDirExists(filepath.Join(volume.Source, "..", "docs"))
+
+	s.Require().NoError(s.sp.CleanupStorage(context.Background(), spec, volume))
+	s.NoDirExists(filepath.Dir(filepath.Dir(volume.Source)))
+}
+
+func (s *StorageSuite) TestPrepareStorageErrors() {
+	for _, tc := range []struct {
+		name string
+		spec model.GitStorageSpec
+	}{
+		{name: "no repository", spec: model.GitStorageSpec{}},
+		{name: "missing repository", spec: model.GitStorageSpec{Repository: "file:///does/not/exist"}},
+		{name: "missing branch", spec: model.GitStorageSpec{Repository: s.repository, Ref: "missing"}},
+		{name: "missing commit", spec: model.GitStorageSpec{Repository: s.repository, Ref: "0123456789abcdef"}},
+		{name: "missing subdirectory", spec: model.GitStorageSpec{Repository: s.repository, Subdirectory: "missing"}},
+		{name: "subdirectory outside", spec: model.GitStorageSpec{Repository: s.repository, Subdirectory: "../other"}},
+		{name: "option as ref", spec: model.GitStorageSpec{Repository: s.repository, Ref: "--upload-pack=touch"}},
+	} {
+		s.Run(tc.name, func() {
+			_, err := s.sp.PrepareStorage(context.Background(), gitSpec(tc.spec))
+			s.Error(err)
+		})
+	}
+	entries, err := os.ReadDir(s.sp.localDir)
+	s.Require().NoError(err)
+	s.Empty(entries, "failed checkouts should be removed")
+}
+
+func (s *StorageSuite) TestLocalRepositoriesAreNotAllowedByDefault() {
+	sp := newStorage(s.T().TempDir(), Config{})
+	for _, repository := range []string{s.repository, strings.TrimPrefix(s.repository, "file://")} {
+		_, err := sp.PrepareStorage(context.Background(), gitSpec(model.GitStorageSpec{Repository: repository}))
+		s.Error(err, repository)
+	}
+}
+
+func (s *StorageSuite) TestCacheKey() {
+	key, ok, err := s.sp.CacheKey(context.Background(), gitSpec(model.GitStorageSpec{
+		Repository:   s.repository,
+		Ref:          s.commits[0],
+		Subdirectory: "scripts",
+	}))
+	s.Require().NoError(err)
+	s.True(ok)
+	s.Equal(s.repository+"#"+s.commits[0]+":scripts", key)
+
+	for _, ref := range []string{"", "main", "v1.0", s.commits[0][:8]} {
+		_, ok, err = s.sp.CacheKey(context.Background(), gitSpec(model.GitStorageSpec{Repository: s.repository, Ref: ref}))
+		s.Require().NoError(err)
+		s.False(ok, "ref %q should not be cached", ref)
+	}
+}
+
+func (s *StorageSuite) commit(dir, message string) {
+	s.gitCommand(dir, "add", "--all")
+	s.gitCommand(dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", message)
+	s.commits = append(s.commits, s.gitCommand(dir, "rev-parse", "HEAD"))
+}
+
+func (s *StorageSuite) gitCommand(dir string, args ...string) string {
+	output, err := newStorage("", Config{AllowLocalRepositories: true}).git(context.Background(), dir, args...)
+	s.Require().NoError(err)
+	return output
+}
+
+func (s *StorageSuite) writeFile(dir, name, content string) {
+	path := filepath.Join(dir, name)
+	s.Require().NoError(os.MkdirAll(filepath.Dir(path), os.ModePerm))
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0600))
+}
+
+func (s *StorageSuite) fileContains(path, expected string) {
+	content, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Equal(expected, string(content))
+}
+
+func gitSpec(spec model.GitStorageSpec) model.StorageSpec {
+	return model.StorageSpec{
+		StorageSource: model.StorageSourceGit,
+		Path:          "/inputs/repo",
+		Git:           &spec,
+	}
+}
@@ -88,6 +88,11 @@ func (s *StorageProvider) CleanupStorage(_ context.Context, storageSpec model.St
 	return os.RemoveAll(filepath.Join(s.localDir, storageSpec.CID))
 }
 
+// CacheKey implements storage.Cacheable, as CIDs identify their content
+func (s *StorageProvider) CacheKey(_ context.Context, storageSpec model.StorageSpec) (string, bool, error) {
+	return storageSpec.CID, storageSpec.CID != "", nil
+}
+
 func (s *StorageProvider) Upload(ctx context.Context, localPath string) (model.StorageSpec, error) {
 	cid, err := s.ipfsClient.Put(ctx, localPath)
 	if err != nil {
@@ -160,3 +165,4 @@ func (s *StorageProvider) getFileFromIPFS(ctx context.Context, storageSpec model
 
 // Compile time interface check:
 var _ storage.Storage = (*StorageProvider)(nil)
+var _ storage.Cacheable = (*StorageProvider)(nil)
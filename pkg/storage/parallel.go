@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 
+	"github.com/filecoin-project/bacalhau/pkg/bacerrors"
 	"github.com/filecoin-project/bacalhau/pkg/model"
 	"github.com/filecoin-project/bacalhau/pkg/util/generic"
 	"go.ptx.dk/multierrgroup"
@@ -32,7 +33,7 @@ func ParallelPrepareStorage(
 
 			volumeMount, err = storageProvider.PrepareStorage(ctx, spec)
 			if err != nil {
-				return err
+				return bacerrors.NewInputDownloadFailed(inputName(spec), err)
 			}
 
 			volumes.Put(&spec, volumeMount)
@@ -51,3 +52,11 @@ func ParallelPrepareStorage(
 	})
 	return returnMap, err
 }
+
+// inputName returns the name of an input for error messages, or its path if it has no name.
+func inputName(spec model.StorageSpec) string {
+	if spec.Name != "" {
+		return spec.Name
+	}
+	return spec.Path
+}
@@ -0,0 +1,243 @@
+package s3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/filecoin-project/bacalhau/pkg/config"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	s3helper "github.com/filecoin-project/bacalhau/pkg/s3"
+	"github.com/filecoin-project/bacalhau/pkg/storage"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/rs/zerolog/log"
+)
+
+// a storage driver that downloads objects from an S3-compatible bucket
+// to a local directory in preparation for a job to run - it will remove
+// the folder once complete
+
+type StorageProvider struct {
+	localDir string
+	client   *awss3.Client
+}
+
+func NewStorage(cm *system.CleanupManager, storageConfig s3helper.Config) (*StorageProvider, error) {
+	dir, err := os.MkdirTemp(config.GetStoragePath(), "bacalhau-s3")
+	if err != nil {
+		return nil, err
+	}
+
+	cm.RegisterCallback(func() error {
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("unable to remove storage folder: %w", err)
+		}
+		return nil
+	})
+
+	client, err := s3helper.NewClient(context.Background(), storageConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug().Str("dir", dir).Str("endpoint", storageConfig.Endpoint).Msg("S3 driver created with output dir")
+
+	return newStorage(dir, client), nil
+}
+
+func newStorage(dir string, client *awss3.Client) *StorageProvider {
+	return &StorageProvider{
+		localDir: dir,
+		client:   client,
+	}
+}
+
+func (sp *StorageProvider) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (sp *StorageProvider) HasStorageLocally(context.Context, model.StorageSpec) (bool, error) {
+	return false, nil
+}
+
+func (sp *StorageProvider) GetVolumeSize(ctx context.Context, storageSpec model.StorageSpec) (uint64, error) {
+	objects, err := sp.listObjects(ctx, storageSpec)
+	if err != nil {
+		return 0, err
+	}
+	var size uint64
+	for _, object := range objects {
+		size += uint64(object.Size)
+	}
+	return size, nil
+}
+
+// PrepareStorage will download the selected objects of the bucket. A single object is mounted at the path of
+// the storage spec, and the objects under a prefix are mounted in a directory at the path of the storage spec.
+func (sp *StorageProvider) PrepareStorage(ctx context.Context, storageSpec model.StorageSpec) (storage.StorageVolume, error) {
+	objects, err := sp.listObjects(ctx, storageSpec)
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	outputPath, err := os.MkdirTemp(sp.localDir, "*")
+	if err != nil {
+		return storage.StorageVolume{}, err
+	}
+
+	source := outputPath
+	if storageSpec.S3.Key != "" {
+		source = filepath.Join(outputPath, filepath.Base(storageSpec.S3.Key))
+		err = s3helper.DownloadObject(ctx, sp.client, storageSpec.S3.Bucket, storageSpec.S3.Key, source)
+	} else {
+		for _, object := range objects {
+			if err = s3helper.DownloadObject(ctx, sp.client, storageSpec.S3.Bucket, object.Key,
+				filepath.Join(outputPath, filepath.FromSlash(object.RelativePath))); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		_ = os.RemoveAll(outputPath)
+		return storage.StorageVolume{}, err
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("bucket", storageSpec.S3.Bucket).
+		Str("key", storageSpec.S3.Key).
+		Str("prefix", storageSpec.S3.Prefix).
+		Int("objects", len(objects)).
+		Str("source", source).
+		Msg("Downloaded objects")
+
+	return storage.StorageVolume{
+		Type:   storage.StorageVolumeConnectorBind,
+		Source: source,
+		Target: storageSpec.Path,
+	}, nil
+}
+
+func (sp *StorageProvider) CleanupStorage(
+	ctx context.Context,
+	storageSpec model.StorageSpec,
+	volume storage.StorageVolume,
+) error {
+	pathToCleanup := volume.Source
+	if storageSpec.S3 != nil && storageSpec.S3.Key != "" {
+		pathToCleanup = filepath.Dir(volume.Source)
+	}
+	log.Ctx(ctx).Debug().Str("Path", pathToCleanup).Msg("Cleaning up")
+	return os.RemoveAll(pathToCleanup)
+}
+
+// CacheKey implements storage.Cacheable. Objects are identified by their ETag, and prefixes by the keys
+// and ETags of the objects under them.
+func (sp *StorageProvider) CacheKey(ctx context.Context, storageSpec model.StorageSpec) (string, bool, error) {
+	objects, err := sp.listObjects(ctx, storageSpec)
+	if err != nil {
+		return "", false, err
+	}
+	hash := sha256.New()
+	for _, object := range objects {
+		if object.ETag == "" {
+			return "", false, nil
+		}
+		fmt.Fprintf(hash, "%s\x00%s\x00", object.Key, object.ETag)
+	}
+	location := storageSpec.S3.Bucket + "/" + storageSpec.S3.Key + storageSpec.S3.Prefix
+	return location + "#" + hex.EncodeToString(hash.Sum(nil)), true, nil
+}
+
+func (sp *StorageProvider) Upload(context.Context, string) (model.StorageSpec, error) {
+	return model.StorageSpec{}, fmt.Errorf("not implemented")
+}
+
+// Explode returns the storage spec itself, the directories under its prefix and each of its objects, so that
+// sharding glob patterns can match object keys relative to the prefix.
+func (sp *StorageProvider) Explode(ctx context.Context, spec model.StorageSpec) ([]model.StorageSpec, error) {
+	objects, err := sp.listObjects(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	if spec.S3.Key != "" {
+		return []model.StorageSpec{spec}, nil
+	}
+
+	prefix := s3helper.PrefixDir(spec.S3.Prefix)
+	specs := map[string]model.StorageSpec{
+		spec.Path: spec,
+	}
+	for _, object := range objects {
+		// add the directories leading to the object, so that patterns can select whole directories
+		parts := strings.Split(object.RelativePath, "/")
+		for i := 1; i < len(parts); i++ {
+			dir := strings.Join(parts[:i], "/")
+			dirPath := filepath.Join(spec.Path, dir)
+			if _, ok := specs[dirPath]; !ok {
+				specs[dirPath] = model.StorageSpec{
+					Name:          spec.Name,
+					StorageSource: model.StorageSourceS3,
+					Path:          dirPath,
+					S3: &model.S3StorageSpec{
+						Bucket: spec.S3.Bucket,
+						Prefix: prefix + dir + "/",
+					},
+				}
+			}
+		}
+		objectPath := filepath.Join(spec.Path, object.RelativePath)
+		specs[objectPath] = model.StorageSpec{
+			Name:          spec.Name,
+			StorageSource: model.StorageSourceS3,
+			Path:          objectPath,
+			S3: &model.S3StorageSpec{
+				Bucket: spec.S3.Bucket,
+				Key:    object.Key,
+			},
+		}
+	}
+
+	result := make([]model.StorageSpec, 0, len(specs))
+	for _, s := range specs {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result, nil
+}
+
+// listObjects returns the objects selected by the storage spec.
+func (sp *StorageProvider) listObjects(ctx context.Context, storageSpec model.StorageSpec) ([]s3helper.Object, error) {
+	if storageSpec.S3 == nil || storageSpec.S3.Bucket == "" {
+		return nil, fmt.Errorf("S3 storage spec must have a bucket")
+	}
+	if storageSpec.S3.Key == "" {
+		return s3helper.ListObjects(ctx, sp.client, storageSpec.S3.Bucket, storageSpec.S3.Prefix)
+	}
+
+	head, err := sp.client.HeadObject(ctx, &awss3.HeadObjectInput{
+		Bucket: aws.String(storageSpec.S3.Bucket),
+		Key:    aws.String(storageSpec.S3.Key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %s of bucket %s: %w", storageSpec.S3.Key, storageSpec.S3.Bucket, err)
+	}
+	return []s3helper.Object{{
+		Key:          storageSpec.S3.Key,
+		RelativePath: path.Base(storageSpec.S3.Key),
+		Size:         head.ContentLength,
+		ETag:         aws.ToString(head.ETag),
+	}}, nil
+}
+
+var _ storage.Storage = (*StorageProvider)(nil)
+var _ storage.Cacheable = (*StorageProvider)(nil)
@@ -0,0 +1,155 @@
+//go:build unit || !integration
+
+package s3
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/logger"
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	s3helper "github.com/filecoin-project/bacalhau/pkg/s3"
+	"github.com/filecoin-project/bacalhau/pkg/s3/s3test"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/stretchr/testify/suite"
+)
+
+type StorageSuite struct {
+	suite.Suite
+	server *s3test.Server
+	sp     *StorageProvider
+}
+
+func TestStorageSuite(t *testing.T) {
+	suite.Run(t, new(StorageSuite))
+}
+
+var testObjects = map[string]string{
+	"data/a.txt":         "hello",
+	"data/b.csv":         "1,2,3",
+	"data/nested/c.txt":  "world!",
+	"data/nested/":       "",
+	"other/ignored.txt":  "ignored",
+	"data-other/nope.md": "nope",
+}
+
+// Before each test
+func (s *StorageSuite) SetupTest() {
+	logger.ConfigureTestLogging(s.T())
+	s.Require().NoError(system.InitConfigForTesting(s.T()))
+
+	s.server = s3test.NewServer(s.T(), "bucket", testObjects)
+	client, err := s3helper.NewClient(context.Background(), s.server.Config())
+	s.Require().NoError(err)
+	s.sp = newStorage(s.T().TempDir(), client)
+}
+
+func (s *StorageSuite) TestNewStorageProvider() {
+	cm := system.NewCleanupManager()
+	sp, err := NewStorage(cm, s.server.Config())
+	s.Require().NoError(err, "failed to create storage provider")
+	s.DirExists(sp.localDir)
+	cm.Cleanup(context.Background())
+	s.NoDirExists(sp.localDir)
+}
+
+func (s *StorageSuite) TestGetVolumeSize() {
+	for _, tc := range []struct {
+		name     string
+		spec     model.S3StorageSpec
+		expected uint64
+	}{
+		{name: "key", spec: model.S3StorageSpec{Bucket: "bucket", Key: "data/a.txt"}, expected: 5},
+		{name: "prefix", spec: model.S3StorageSpec{Bucket: "bucket", Prefix: "data/"}, expected: 16},
+		{name: "partial prefix", spec: model.S3StorageSpec{Bucket: "bucket", Prefix: "data/nested/c"}, expected: 6},
+	} {
+		s.Run(tc.name, func() {
+			size, err := s.sp.GetVolumeSize(context.Background(), s3Spec(tc.spec, "/inputs"))
+			s.Require().NoError(err)
+			s.Equal(tc.expected, size)
+		})
+	}
+}
+
+func (s *StorageSuite) TestPrepareStorageKey() {
+	spec := s3Spec(model.S3StorageSpec{Bucket: "bucket", Key: "data/nested/c.txt"}, "/inputs/c.txt")
+	volume, err := s.sp.PrepareStorage(context.Background(), spec)
+	s.Require().NoError(err)
+	s.Equal("/inputs/c.txt", volume.Target)
+
+	content, err := os.ReadFile(volume.Source)
+	s.Require().NoError(err)
+	s.Equal("world!", string(content))
+
+	s.Require().NoError(s.sp.CleanupStorage(context.Background(), spec, volume))
+	s.NoFileExists(volume.Source)
+	s.NoDirExists(filepath.Dir(volume.Source))
+}
+
+func (s *StorageSuite) TestPrepareStoragePrefix() {
+	spec := s3Spec(model.S3StorageSpec{Bucket: "bucket", Prefix: "data/"}, "/inputs")
+	volume, err := s.sp.PrepareStorage(context.Background(), spec)
+	s.Require().NoError(err)
+	s.Equal("/inputs", volume.Target)
+
+	var files []string
+	err = filepath.Walk(volume.Source, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relativePath, err := filepath.Rel(volume.Source, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		files = append(files, filepath.ToSlash(relativePath)+"="+string(content))
+		return err
+	})
+	s.Require().NoError(err)
+	s.Equal([]string{"a.txt=hello", "b.csv=1,2,3", "nested/c.txt=world!"}, files)
+
+	s.Require().NoError(s.sp.CleanupStorage(context.Background(), spec, volume))
+	s.NoDirExists(volume.Source)
+}
+
+func (s *StorageSuite) TestPrepareStorageMissingKey() {
+	spec := s3Spec(model.S3StorageSpec{Bucket: "bucket", Key: "data/missing.txt"}, "/inputs")
+	_, err := s.sp.PrepareStorage(context.Background(), spec)
+	s.Error(err)
+}
+
+func (s *StorageSuite) TestExplode() {
+	spec := s3Spec(model.S3StorageSpec{Bucket: "bucket", Prefix: "data/"}, "/inputs")
+	specs, err := s.sp.Explode(context.Background(), spec)
+	s.Require().NoError(err)
+
+	var exploded []string
+	for _, explodedSpec := range specs {
+		s.Equal(model.StorageSourceS3, explodedSpec.StorageSource)
+		exploded = append(exploded, explodedSpec.Path+"="+explodedSpec.S3.Key+explodedSpec.S3.Prefix)
+	}
+	s.Equal([]string{
+		"/inputs=data/",
+		"/inputs/a.txt=data/a.txt",
+		"/inputs/b.csv=data/b.csv",
+		"/inputs/nested=data/nested/",
+		"/inputs/nested/c.txt=data/nested/c.txt",
+	}, exploded)
+}
+
+func (s *StorageSuite) TestExplodeKey() {
+	spec := s3Spec(model.S3StorageSpec{Bucket: "bucket", Key: "data/a.txt"}, "/inputs/a.txt")
+	specs, err := s.sp.Explode(context.Background(), spec)
+	s.Require().NoError(err)
+	s.Equal([]model.StorageSpec{spec}, specs)
+}
+
+func s3Spec(spec model.S3StorageSpec, path string) model.StorageSpec {
+	return model.StorageSpec{
+		StorageSource: model.StorageSourceS3,
+		Path:          path,
+		S3:            &spec,
+	}
+}
@@ -31,6 +31,14 @@ type Storage interface {
 	Explode(context.Context, model.StorageSpec) ([]model.StorageSpec, error)
 }
 
+// Cacheable is implemented by storages whose prepared volumes only depend on content that can be identified
+// before preparing them, so that a prepared volume can be cached and shared between executions.
+type Cacheable interface {
+	// CacheKey returns a key that changes whenever the content of the storage spec changes, or false if the
+	// content can't be identified, in which case it must not be cached.
+	CacheKey(context.Context, model.StorageSpec) (string, bool, error)
+}
+
 // a storage entity that is consumed are produced by a job
 // input storage specs are turned into storage volumes by drivers
 // for example - the input storage spec might be ipfs cid XXX
@@ -43,4 +51,7 @@ type StorageVolume struct {
 	Type   StorageVolumeConnectorType `json:"type"`
 	Source string                     `json:"source"`
 	Target string                     `json:"target"`
+	// Version identifies the content that was prepared when the storage spec references content that can
+	// change over time, such as the commit a git branch resolved to
+	Version string `json:"version,omitempty"`
 }
@@ -181,6 +181,31 @@ func (sp *StorageProvider) CleanupStorage(
 	return os.RemoveAll(pathToCleanup)
 }
 
+// CacheKey implements storage.Cacheable. URLs are identified by the ETag the server returns for them,
+// and URLs without an ETag are not cached as their content might change.
+func (sp *StorageProvider) CacheKey(ctx context.Context, storageSpec model.StorageSpec) (string, bool, error) {
+	u, err := IsURLSupported(storageSpec.URL)
+	if err != nil {
+		return "", false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil)
+	if err != nil {
+		return "", false, err
+	}
+	// don't retry, as this is only an optimization and the download will retry anyway
+	res, err := sp.client.HTTPClient.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer closer.DrainAndCloseWithLogOnError(ctx, "response", res.Body)
+
+	etag := res.Header.Get("ETag")
+	if res.StatusCode != http.StatusOK || etag == "" {
+		return "", false, nil
+	}
+	return u.String() + "#" + etag, true, nil
+}
+
 func (sp *StorageProvider) Upload(context.Context, string) (model.StorageSpec, error) {
 	// we don't "upload" anything to a URL
 	return model.StorageSpec{}, fmt.Errorf("not implemented")
@@ -221,6 +246,7 @@ func IsURLSupported(rawURL string) (*url.URL, error) {
 }
 
 var _ storage.Storage = (*StorageProvider)(nil)
+var _ storage.Cacheable = (*StorageProvider)(nil)
 
 var _ retryablehttp.LeveledLogger = retryLogger{}
 
@@ -188,6 +188,16 @@ func PublicKeyMatchesID(publicKey, clientID string) (bool, error) {
 	return clientID == convertToClientID(pkey), nil
 }
 
+// ClientIDFromPublicKey returns the client ID corresponding to the given base64-encoded public key:
+func ClientIDFromPublicKey(publicKey string) (string, error) {
+	pkey, err := decodePublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode public key: %w", err)
+	}
+
+	return convertToClientID(pkey), nil
+}
+
 // ensureDefaultConfigDir ensures that a bacalhau config dir exists.
 func ensureConfigDir() (string, error) {
 	configDir := os.Getenv("BACALHAU_DIR")
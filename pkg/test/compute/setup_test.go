@@ -78,6 +78,8 @@ func (s *ComputeSuite) setupNode() {
 		host,
 		apiServer,
 		s.config,
+		nil,
+		nil,
 		"",
 		nil,
 		model.NewNoopProvider[model.StorageSourceType, storage.Storage](noopstorage),
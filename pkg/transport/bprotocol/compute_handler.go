@@ -36,6 +36,8 @@ func NewComputeHandler(params ComputeHandlerParams) *ComputeHandler {
 	handler.host.SetStreamHandler(ResultAcceptedProtocolID, handler.onResultAccepted)
 	handler.host.SetStreamHandler(ResultRejectedProtocolID, handler.onResultRejected)
 	handler.host.SetStreamHandler(CancelProtocolID, handler.onCancelJob)
+	handler.host.SetStreamHandler(ExecutionStateProtocolID, handler.onExecutionState)
+	handler.host.SetStreamHandler(ExecutionLogsProtocolID, handler.onExecutionLogs)
 	log.Debug().Msgf("ComputeHandler started on host %s", handler.host.ID().String())
 	return handler
 }
@@ -70,6 +72,16 @@ func (h *ComputeHandler) onCancelJob(stream network.Stream) {
 	handleStream[compute.CancelExecutionRequest, compute.CancelExecutionResponse](ctx, stream, h.computeEndpoint.CancelExecution)
 }
 
+func (h *ComputeHandler) onExecutionState(stream network.Stream) {
+	ctx := logger.ContextWithNodeIDLogger(context.Background(), h.host.ID().String())
+	handleStream[compute.ExecutionStateRequest, compute.ExecutionStateResponse](ctx, stream, h.computeEndpoint.ExecutionState)
+}
+
+func (h *ComputeHandler) onExecutionLogs(stream network.Stream) {
+	ctx := logger.ContextWithNodeIDLogger(context.Background(), h.host.ID().String())
+	handleStream[compute.ExecutionLogsRequest, compute.ExecutionLogsResponse](ctx, stream, h.computeEndpoint.ExecutionLogs)
+}
+
 //nolint:errcheck
 func handleStream[Request any, Response any](
 	ctx context.Context,
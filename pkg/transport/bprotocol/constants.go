@@ -8,6 +8,8 @@ const (
 	ResultAcceptedProtocolID = "/bacalhau/compute/result_accepted/1.0.0"
 	ResultRejectedProtocolID = "/bacalhau/compute/result_rejected/1.0.0"
 	CancelProtocolID         = "/bacalhau/compute/cancel/1.0.0"
+	ExecutionStateProtocolID = "/bacalhau/compute/execution_state/1.0.0"
+	ExecutionLogsProtocolID  = "/bacalhau/compute/execution_logs/1.0.0"
 
 	CallbackServiceName = "bacalhau.callback"
 	OnRunComplete       = "/bacalhau/callback/on_run_complete/1.0.0"
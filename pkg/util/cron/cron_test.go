@@ -0,0 +1,61 @@
+//go:build unit || !integration
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNext(t *testing.T) {
+	from := time.Date(2023, time.March, 15, 10, 30, 20, 0, time.UTC) // a wednesday
+	for _, tc := range []struct {
+		expr     string
+		expected time.Time
+	}{
+		{expr: "* * * * *", expected: time.Date(2023, time.March, 15, 10, 31, 0, 0, time.UTC)},
+		{expr: "@hourly", expected: time.Date(2023, time.March, 15, 11, 0, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", expected: time.Date(2023, time.March, 15, 10, 45, 0, 0, time.UTC)},
+		{expr: "5,10 9-11 * * *", expected: time.Date(2023, time.March, 15, 11, 5, 0, 0, time.UTC)},
+		{expr: "0 0 * * *", expected: time.Date(2023, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 12 * * mon-fri", expected: time.Date(2023, time.March, 15, 12, 0, 0, 0, time.UTC)},
+		{expr: "0 0 * * 7", expected: time.Date(2023, time.March, 19, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 1 jan *", expected: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		// day of month and day of week match when either of them matches
+		{expr: "0 0 20 * mon", expected: time.Date(2023, time.March, 20, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 17 * mon", expected: time.Date(2023, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", expected: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 30 2 *", expected: time.Time{}},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			schedule, err := Parse(tc.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, schedule.Next(from))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"@every",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Parse(expr)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// quotafs implements an fs.FS that limits how many bytes can be written to the
+// files opened through it.
+//
+// Our WASM implementation writes the outputs of a job through an fs.FS, so this
+// is where the size of the outputs can be limited while the job runs. Each
+// filesystem can be limited by more than one quota, and a quota can be shared
+// between filesystems, such that e.g. each output volume can have its own quota
+// as well as a quota for all output volumes together.
+//
+// Only writes that grow a file are counted, and writes that would exceed a
+// quota fail without writing anything.
+
+package quotafs
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// mu guards the usage of all quotas, so that a write is counted against all of
+// its quotas at once.
+var mu sync.Mutex
+
+// Quota is a number of bytes that can be written through one or more
+// filesystems.
+type Quota struct {
+	limit    uint64
+	used     uint64
+	newError func(size uint64) error
+	err      error
+}
+
+// NewQuota returns a quota of the given number of bytes. The first write that
+// would exceed it fails, and the quota records the error returned by newError
+// for the size the write would have grown the files to.
+func NewQuota(limit uint64, newError func(size uint64) error) *Quota {
+	return &Quota{limit: limit, newError: newError}
+}
+
+// Used returns how many bytes were written against the quota.
+func (q *Quota) Used() uint64 {
+	mu.Lock()
+	defer mu.Unlock()
+	return q.used
+}
+
+// Err returns the error of the first write that exceeded the quota, or nil.
+func (q *Quota) Err() error {
+	mu.Lock()
+	defer mu.Unlock()
+	return q.err
+}
+
+// charge counts the growth of a file against all quotas, unless that would
+// exceed one of them.
+func charge(quotas []*Quota, growth uint64) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, q := range quotas {
+		if q.used+growth > q.limit {
+			if q.err == nil {
+				q.err = q.newError(q.used + growth)
+			}
+			return false
+		}
+	}
+	for _, q := range quotas {
+		q.used += growth
+	}
+	return true
+}
+
+type quotaFS struct {
+	fs     fs.FS
+	quotas []*Quota
+}
+
+// New returns an fs.FS that opens files through fsys, and limits the writes to
+// them to all of the quotas.
+func New(fsys fs.FS, quotas ...*Quota) fs.FS {
+	return quotaFS{fs: fsys, quotas: quotas}
+}
+
+func (q quotaFS) Open(name string) (fs.File, error) {
+	file, err := q.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if osFile, ok := file.(*os.File); ok {
+		return &quotaFile{File: osFile, quotas: q.quotas}, nil
+	}
+	return file, nil
+}
+
+// quotaFile is a file whose writes are counted against quotas. Embedding the
+// *os.File keeps the optional interfaces of the file, such as io.Seeker and
+// fs.ReadDirFile, that wazero relies on.
+type quotaFile struct {
+	*os.File
+	quotas []*Quota
+}
+
+func (f *quotaFile) Write(p []byte) (int, error) {
+	offset, err := f.File.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	if err = f.reserve(offset, len(p)); err != nil {
+		return 0, err
+	}
+	return f.File.Write(p)
+}
+
+func (f *quotaFile) WriteAt(p []byte, offset int64) (int, error) {
+	if err := f.reserve(offset, len(p)); err != nil {
+		return 0, err
+	}
+	return f.File.WriteAt(p, offset)
+}
+
+func (f *quotaFile) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+// reserve counts the bytes a write grows the file by against the quotas.
+func (f *quotaFile) reserve(offset int64, length int) error {
+	info, err := f.File.Stat()
+	if err != nil {
+		return err
+	}
+	growth := offset + int64(length) - info.Size()
+	if growth <= 0 {
+		return nil
+	}
+	if !charge(f.quotas, uint64(growth)) {
+		return &fs.PathError{Op: "write", Path: f.File.Name(), Err: syscall.EDQUOT}
+	}
+	return nil
+}
+
+// ReadFrom copies through Write, as the ReadFrom of *os.File would bypass the
+// quotas when the file is the destination of io.Copy.
+func (f *quotaFile) ReadFrom(r io.Reader) (int64, error) {
+	return io.Copy(struct{ io.Writer }{f}, r)
+}
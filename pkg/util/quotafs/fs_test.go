@@ -0,0 +1,80 @@
+//go:build unit || !integration
+
+package quotafs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/util/touchfs"
+	"github.com/stretchr/testify/require"
+)
+
+func newQuota(name string, limit uint64) *Quota {
+	return NewQuota(limit, func(size uint64) error {
+		return fmt.Errorf("%s grew to %d bytes", name, size)
+	})
+}
+
+func writeTo(t *testing.T, quotas []*Quota, dir, name, content string) error {
+	t.Helper()
+	file, err := New(touchfs.New(dir), quotas...).Open(name)
+	require.NoError(t, err)
+	defer file.Close()
+	_, err = io.Copy(file.(io.Writer), strings.NewReader(content))
+	return err
+}
+
+func TestQuotaLimitsWrites(t *testing.T) {
+	dir := t.TempDir()
+	quota := newQuota("outputs", 10)
+
+	require.NoError(t, writeTo(t, []*Quota{quota}, dir, "first.txt", "hello"))
+	require.Equal(t, uint64(5), quota.Used())
+	require.NoError(t, quota.Err())
+
+	err := writeTo(t, []*Quota{quota}, dir, "second.txt", "goodbye")
+	require.True(t, errors.Is(err, syscall.EDQUOT))
+	require.EqualError(t, quota.Err(), "outputs grew to 12 bytes")
+	require.Equal(t, uint64(5), quota.Used())
+
+	content, err := os.ReadFile(filepath.Join(dir, "second.txt"))
+	require.NoError(t, err)
+	require.Empty(t, content)
+}
+
+func TestQuotaCountsOnlyGrowth(t *testing.T) {
+	dir := t.TempDir()
+	quota := newQuota("outputs", 8)
+
+	file, err := New(touchfs.New(dir), quota).Open("data.bin")
+	require.NoError(t, err)
+	defer file.Close()
+	writerAt := file.(io.WriterAt)
+
+	_, err = writerAt.WriteAt([]byte("12345678"), 0)
+	require.NoError(t, err)
+	_, err = writerAt.WriteAt([]byte("abcd"), 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(8), quota.Used())
+
+	_, err = writerAt.WriteAt([]byte("9"), 8)
+	require.Error(t, err)
+	require.Error(t, quota.Err())
+}
+
+func TestSharedQuota(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	total := newQuota("results", 6)
+
+	require.NoError(t, writeTo(t, []*Quota{newQuota("first", 5), total}, first, "a.txt", "abcd"))
+	err := writeTo(t, []*Quota{newQuota("second", 5), total}, second, "b.txt", "efg")
+	require.Error(t, err)
+	require.EqualError(t, total.Err(), "results grew to 7 bytes")
+}
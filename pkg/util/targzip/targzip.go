@@ -29,6 +29,16 @@ func Decompress(src io.Reader, dst string) error {
 	return decompress(src, dst, MaximumContextSize)
 }
 
+// CompressWithMaxSize is Compress with a custom limit on the size of each file.
+func CompressWithMaxSize(ctx context.Context, src string, buf io.Writer, max datasize.ByteSize) error {
+	return compress(ctx, src, buf, max)
+}
+
+// DecompressWithMaxSize is Decompress with a custom limit on the size of each file.
+func DecompressWithMaxSize(src io.Reader, dst string, max datasize.ByteSize) error {
+	return decompress(src, dst, max)
+}
+
 func UncompressedSize(src io.Reader) (datasize.ByteSize, error) {
 	var size datasize.ByteSize
 	zr, err := gzip.NewReader(src)
@@ -98,9 +108,13 @@ func compress(ctx context.Context, src string, buf io.Writer, max datasize.ByteS
 				return err
 			}
 
-			// must provide real name
+			// must provide real name, relative to the parent of src so that the archive can be extracted anywhere
 			// (see https://golang.org/src/archive/tar/common.go?#L626)
-			header.Name = filepath.ToSlash(file)
+			name, err := filepath.Rel(filepath.Dir(src), file)
+			if err != nil {
+				return err
+			}
+			header.Name = filepath.ToSlash(name)
 
 			// write header
 			if err = tw.WriteHeader(header); err != nil { //nolint:gocritic
@@ -146,3 +146,24 @@ func TestUncompressedSize(t *testing.T) {
 		}
 	}
 }
+
+func TestRoundTripAbsoluteDir(t *testing.T) {
+	srcDir := filepath.Join(t.TempDir(), "results")
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "outputs"), worldReadOwnerWritePermission))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "outputs", "data.txt"), []byte("data"), 0600))
+
+	tgzFile, err := os.Create(filepath.Join(t.TempDir(), "data.tgz"))
+	require.NoError(t, err)
+	defer tgzFile.Close()
+
+	err = CompressWithMaxSize(context.Background(), srcDir, tgzFile, datasize.KB)
+	require.NoError(t, err)
+
+	_, err = tgzFile.Seek(0, 0)
+	require.NoError(t, err)
+
+	outputDir := filepath.Join(t.TempDir(), "outdir")
+	err = DecompressWithMaxSize(tgzFile, outputDir, datasize.KB)
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(outputDir, "results", "outputs", "data.txt"))
+}
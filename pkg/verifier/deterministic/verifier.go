@@ -73,13 +73,10 @@ func (deterministicVerifier *DeterministicVerifier) GetShardProposal(
 func (deterministicVerifier *DeterministicVerifier) getHashGroups(
 	ctx context.Context,
 	executionStates []model.ExecutionState,
-) map[string][]*verifier.VerifierResult {
-	// group the verifier results by their reported hash
-	// then pick the largest group and verify all of those
-	// caveats:
-	//  * if there is only 1 group - there must be > 1 result
-	//  * there cannot be a draw between the top 2 groups
-	hashGroups := map[string][]*verifier.VerifierResult{}
+) []verifier.ExecutionGroup {
+	// group the executions by their reported hash, in the order the hashes were first reported
+	var hashGroups []verifier.ExecutionGroup
+	groupIndexes := map[string]int{}
 
 	for _, executionState := range executionStates { //nolint:gocritic
 		hash := ""
@@ -97,14 +94,14 @@ func (deterministicVerifier *DeterministicVerifier) getHashGroups(
 			}
 		}
 
-		existingArray, ok := hashGroups[hash]
+		index, ok := groupIndexes[hash]
 		if !ok {
-			existingArray = []*verifier.VerifierResult{}
+			index = len(hashGroups)
+			groupIndexes[hash] = index
+			// the winning hash must not be empty string
+			hashGroups = append(hashGroups, verifier.ExecutionGroup{Valid: hash != ""})
 		}
-		hashGroups[hash] = append(existingArray, &verifier.VerifierResult{
-			Execution: executionState,
-			Verified:  false,
-		})
+		hashGroups[index].Executions = append(hashGroups[index].Executions, executionState)
 	}
 
 	return hashGroups
@@ -122,58 +119,13 @@ func (deterministicVerifier *DeterministicVerifier) VerifyShard(
 	if err != nil {
 		return nil, err
 	}
-	confidence := shard.Job.Spec.Deal.Confidence
 
-	largestGroupHash := ""
-	largestGroupSize := 0
-	isVoidResult := false
-	groupSizeCounts := map[int]int{}
+	// pick the largest group and verify all of those
+	// caveats:
+	//  * if there is only 1 group - there must be > 1 result
+	//  * there cannot be a draw between the top 2 groups
 	hashGroups := deterministicVerifier.getHashGroups(ctx, executionStates)
-
-	for hash, group := range hashGroups {
-		if len(group) > largestGroupSize {
-			largestGroupSize = len(group)
-			largestGroupHash = hash
-		}
-		groupSizeCounts[len(group)]++
-	}
-
-	// this means there is a draw for the largest group size
-	if groupSizeCounts[largestGroupSize] > 1 {
-		isVoidResult = true
-	}
-
-	// this means there is only a single result
-	if len(hashGroups) == 1 && largestGroupSize == 1 {
-		isVoidResult = true
-	}
-
-	// this means that the winning group size does not
-	// meet the confidence threshold
-	if confidence > 0 && largestGroupSize < confidence {
-		isVoidResult = true
-	}
-
-	// the winning hash must not be empty string
-	if largestGroupHash == "" {
-		isVoidResult = true
-	}
-
-	if !isVoidResult {
-		for _, passedVerificationResult := range hashGroups[largestGroupHash] {
-			passedVerificationResult.Verified = true
-		}
-	}
-
-	var allResults []verifier.VerifierResult
-
-	for _, verificationResultList := range hashGroups {
-		for _, verificationResult := range verificationResultList {
-			allResults = append(allResults, *verificationResult)
-		}
-	}
-
-	return allResults, nil
+	return verifier.VerifyGroups(hashGroups, shard.Job.Spec.Deal.Confidence), nil
 }
 
 // Compile-time check that deterministicVerifier implements the correct interface:
@@ -0,0 +1,76 @@
+package verifier
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+)
+
+// proposalKeySize is the size of the random AES-256 key that seals a proposal
+const proposalKeySize = 32
+
+// SealProposal encrypts a proposal of any size for the requester. The encrypter can only encrypt payloads smaller
+// than its key, so the proposal is encrypted with a random key, which is encrypted with the encrypter and
+// prepended to the sealed proposal.
+func SealProposal(ctx context.Context, encrypter EncrypterFunction, proposal, publicKey []byte) ([]byte, error) {
+	key := make([]byte, proposalKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	encryptedKey, err := encrypter(ctx, key, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newProposalCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := binary.BigEndian.AppendUint16(nil, uint16(len(encryptedKey)))
+	sealed = append(sealed, encryptedKey...)
+	sealed = append(sealed, nonce...)
+	return aead.Seal(sealed, nonce, proposal, nil), nil
+}
+
+// OpenProposal decrypts a proposal sealed with SealProposal.
+func OpenProposal(ctx context.Context, decrypter DecrypterFunction, sealed []byte) ([]byte, error) {
+	if len(sealed) < 2 {
+		return nil, fmt.Errorf("sealed proposal is too short")
+	}
+	keyLength := int(binary.BigEndian.Uint16(sealed))
+	sealed = sealed[2:]
+	if len(sealed) < keyLength {
+		return nil, fmt.Errorf("sealed proposal is too short")
+	}
+	key, err := decrypter(ctx, sealed[:keyLength])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newProposalCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed = sealed[keyLength:]
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("sealed proposal is too short")
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+}
+
+func newProposalCipher(key []byte) (cipher.AEAD, error) {
+	if len(key) != proposalKeySize {
+		return nil, fmt.Errorf("invalid proposal key size %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,49 @@
+//go:build unit || !integration
+
+package verifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEncrypter(t *testing.T) (Encrypter, []byte) {
+	privateKey, publicKey, err := crypto.GenerateKeyPairWithReader(crypto.RSA, 2048, rand.Reader)
+	require.NoError(t, err)
+	publicKeyBytes, err := crypto.MarshalPublicKey(publicKey)
+	require.NoError(t, err)
+	return NewEncrypter(privateKey), publicKeyBytes
+}
+
+func TestSealProposal(t *testing.T) {
+	ctx := context.Background()
+	encrypter, publicKey := newTestEncrypter(t)
+	otherEncrypter, _ := newTestEncrypter(t)
+
+	// much larger than what the encrypter can encrypt on its own
+	proposal := bytes.Repeat([]byte("proposal"), 10_000)
+	sealed, err := SealProposal(ctx, encrypter.Encrypt, proposal, publicKey)
+	require.NoError(t, err)
+
+	opened, err := OpenProposal(ctx, encrypter.Decrypt, sealed)
+	require.NoError(t, err)
+	require.Equal(t, proposal, opened)
+
+	_, err = OpenProposal(ctx, otherEncrypter.Decrypt, sealed)
+	require.Error(t, err, "opened with another key")
+
+	tampered := append([]byte{}, sealed...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = OpenProposal(ctx, encrypter.Decrypt, tampered)
+	require.Error(t, err, "opened a tampered proposal")
+
+	for _, truncated := range [][]byte{nil, sealed[:1], sealed[:10], sealed[:len(sealed)-len(proposal)-20]} {
+		_, err = OpenProposal(ctx, encrypter.Decrypt, truncated)
+		require.Error(t, err, "opened a truncated proposal")
+	}
+}
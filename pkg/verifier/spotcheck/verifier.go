@@ -0,0 +1,53 @@
+package spotcheck
+
+import (
+	"context"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/filecoin-project/bacalhau/pkg/verifier"
+	"github.com/filecoin-project/bacalhau/pkg/verifier/tolerant"
+)
+
+// SpotCheckVerifier verifies the shards that the requester re-executes on a second compute node, according to
+// the job's Deal.SpotCheckFraction, and accepts the result of the other shards without verifying it. Results
+// are compared like the tolerant verifier does, so they must be equal unless the job's verification rules say
+// otherwise.
+type SpotCheckVerifier struct {
+	*tolerant.TolerantVerifier
+}
+
+func NewSpotCheckVerifier(
+	ctx context.Context, cm *system.CleanupManager,
+	encrypter verifier.EncrypterFunction,
+	decrypter verifier.DecrypterFunction,
+) (*SpotCheckVerifier, error) {
+	tolerantVerifier, err := tolerant.NewTolerantVerifier(ctx, cm, encrypter, decrypter)
+	if err != nil {
+		return nil, err
+	}
+	return &SpotCheckVerifier{TolerantVerifier: tolerantVerifier}, nil
+}
+
+func (spotCheckVerifier *SpotCheckVerifier) VerifyShard(
+	ctx context.Context,
+	shard model.JobShard,
+	executionStates []model.ExecutionState,
+) ([]verifier.VerifierResult, error) {
+	ctx, span := system.NewSpan(ctx, system.GetTracer(), "pkg/verifier.SpotCheckVerifier.VerifyShard")
+	defer span.End()
+
+	err := verifier.ValidateExecutions(shard, executionStates)
+	if err != nil {
+		return nil, err
+	}
+
+	// shards that were not sampled have a single execution, whose result is accepted as is
+	if len(executionStates) == 1 {
+		return []verifier.VerifierResult{{Execution: executionStates[0], Verified: true}}, nil
+	}
+	return spotCheckVerifier.TolerantVerifier.VerifyShard(ctx, shard, executionStates)
+}
+
+// Compile-time check that spotCheckVerifier implements the correct interface:
+var _ verifier.Verifier = (*SpotCheckVerifier)(nil)
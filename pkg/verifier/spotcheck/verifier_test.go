@@ -0,0 +1,99 @@
+//go:build unit || !integration
+
+package spotcheck
+
+import (
+	"context"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/filecoin-project/bacalhau/pkg/verifier"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func writeResult(t *testing.T, content string) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stdout"), []byte(content), os.ModePerm))
+	return dir
+}
+
+func TestVerifyShard(t *testing.T) {
+	ctx := context.Background()
+	cm := system.NewCleanupManager()
+	t.Cleanup(func() { cm.Cleanup(ctx) })
+
+	privateKey, publicKey, err := crypto.GenerateKeyPairWithReader(crypto.RSA, 2048, rand.Reader)
+	require.NoError(t, err)
+	publicKeyBytes, err := crypto.MarshalPublicKey(publicKey)
+	require.NoError(t, err)
+	encrypter := verifier.NewEncrypter(privateKey)
+
+	spotCheckVerifier, err := NewSpotCheckVerifier(ctx, cm, encrypter.Encrypt, encrypter.Decrypt)
+	require.NoError(t, err)
+
+	job := &model.Job{
+		Metadata: model.Metadata{
+			ID:        "job",
+			Requester: model.JobRequester{RequesterPublicKey: publicKeyBytes},
+		},
+		Spec: model.Spec{
+			Verifier: model.VerifierSpotCheck,
+			Deal:     model.Deal{Concurrency: 1, SpotCheckFraction: 0.5},
+		},
+	}
+	shard := model.JobShard{Job: job}
+
+	for _, tc := range []struct {
+		name     string
+		results  []string
+		verified map[string]bool
+		trust    model.ShardTrust
+	}{
+		{
+			name:     "single execution is accepted without verification",
+			results:  []string{"hello"},
+			verified: map[string]bool{"a": true},
+			trust:    model.ShardTrustUnverified,
+		},
+		{
+			name:     "matching executions are verified",
+			results:  []string{"hello", "hello"},
+			verified: map[string]bool{"a": true, "b": true},
+			trust:    model.ShardTrustVerified,
+		},
+		{
+			name:     "mismatching executions are rejected",
+			results:  []string{"hello", "goodbye"},
+			verified: map[string]bool{"a": false, "b": false},
+			trust:    model.ShardTrustMismatched,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var executions []model.ExecutionState
+			for i, result := range tc.results {
+				proposal, err := spotCheckVerifier.GetShardProposal(ctx, shard, writeResult(t, result))
+				require.NoError(t, err)
+				executions = append(executions, model.ExecutionState{
+					JobID:                job.Metadata.ID,
+					NodeID:               string(rune('a' + i)),
+					State:                model.ExecutionStateResultProposed,
+					VerificationProposal: proposal,
+				})
+			}
+
+			results, err := spotCheckVerifier.VerifyShard(ctx, shard, executions)
+			require.NoError(t, err)
+			verified := map[string]bool{}
+			for _, result := range results {
+				verified[result.Execution.NodeID] = result.Verified
+			}
+			require.Equal(t, tc.verified, verified)
+			require.Equal(t, tc.trust, verifier.GetShardTrust(job.Spec.Verifier, results))
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package tolerant
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/util/closer"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// maxNumericFileSize is the size of the largest CSV or JSON file whose numbers are compared with a tolerance.
+	// Larger files are compared exactly.
+	maxNumericFileSize = 8 * 1024 * 1024
+	// maxDigestNumbers is the number of numbers a digest can include, so that proposals stay small. The files
+	// whose numbers would go over it are compared exactly.
+	maxDigestNumbers = 100_000
+	// numberPlaceholder replaces the numbers of files whose numbers are compared with a tolerance
+	numberPlaceholder = "\x00"
+)
+
+var numberRegex = regexp.MustCompile(`-?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?`)
+
+// Digest is the proposal of the tolerant verifier, with a digest of each file of a result that is compared.
+type Digest struct {
+	Files []FileDigest `json:"Files"`
+}
+
+type FileDigest struct {
+	// Path is the slash separated path of the file in the result
+	Path string `json:"Path"`
+	// Hash is the hex encoded SHA-256 hash of the normalized content of the file, where the numbers are replaced
+	// by placeholders if they are compared with a tolerance
+	Hash string `json:"Hash"`
+	// Numbers are the numbers of the file in order, if they are compared with a tolerance
+	Numbers []float64 `json:"Numbers,omitempty"`
+}
+
+// NewDigest returns the digest of the files of a result directory that the rules compare, ordered by path.
+func NewDigest(resultDir string, rules model.VerificationRules) (Digest, error) {
+	digest := Digest{Files: []FileDigest{}}
+	numbers := 0
+	err := filepath.WalkDir(resultDir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(resultDir, filePath)
+		if err != nil || relativePath == "." {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		if isIgnored(rules, relativePath, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fileDigest := FileDigest{Path: relativePath}
+		if rules.HasNumericTolerance() && isNumericFile(relativePath) && info.Size() <= maxNumericFileSize {
+			fileDigest, err = numericFileDigest(filePath, relativePath, rules.NormalizeLineEndings)
+			if err == nil && numbers+len(fileDigest.Numbers) > maxDigestNumbers {
+				log.Warn().Msgf("Comparing %s exactly, as results can't include more than %d numbers compared with a tolerance",
+					relativePath, maxDigestNumbers)
+				fileDigest = FileDigest{Path: relativePath}
+				fileDigest.Hash, err = fileHash(filePath, rules.NormalizeLineEndings)
+			}
+			numbers += len(fileDigest.Numbers)
+		} else {
+			fileDigest.Hash, err = fileHash(filePath, rules.NormalizeLineEndings)
+		}
+		if err != nil {
+			return err
+		}
+		digest.Files = append(digest.Files, fileDigest)
+		return nil
+	})
+	return digest, err
+}
+
+// Matches returns whether two digests are of results that the rules consider equal.
+func (d Digest) Matches(other Digest, rules model.VerificationRules) bool {
+	if len(d.Files) != len(other.Files) {
+		return false
+	}
+	for i, file := range d.Files {
+		otherFile := other.Files[i]
+		if file.Path != otherFile.Path || file.Hash != otherFile.Hash || len(file.Numbers) != len(otherFile.Numbers) {
+			return false
+		}
+		for j, number := range file.Numbers {
+			if !numbersMatch(number, otherFile.Numbers[j], rules) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func numbersMatch(a, b float64, rules model.VerificationRules) bool {
+	difference := math.Abs(a - b)
+	return a == b ||
+		difference <= rules.AbsoluteTolerance ||
+		difference <= rules.RelativeTolerance*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// isIgnored returns whether a file or directory of a result is not compared, either because it matches an
+// ignored path glob, or because it is an output volume that is not compared.
+func isIgnored(rules model.VerificationRules, relativePath string, isDir bool) bool {
+	for _, pattern := range rules.IgnorePaths {
+		name := relativePath
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(relativePath)
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	// output volumes are the directories at the root of the result
+	if !isDir || len(rules.OutputVolumes) == 0 || strings.Contains(relativePath, "/") {
+		return false
+	}
+	for _, volume := range rules.OutputVolumes {
+		if volume == relativePath {
+			return false
+		}
+	}
+	return true
+}
+
+func isNumericFile(relativePath string) bool {
+	switch strings.ToLower(path.Ext(relativePath)) {
+	case ".csv", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+// numericFileDigest returns the digest of a file whose numbers are compared with a tolerance.
+func numericFileDigest(filePath, relativePath string, normalizeLineEndings bool) (FileDigest, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return FileDigest{}, err
+	}
+	if normalizeLineEndings {
+		content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	}
+
+	var numbers []float64
+	skeleton := numberRegex.ReplaceAllFunc(content, func(match []byte) []byte {
+		number, err := strconv.ParseFloat(string(match), 64)
+		if err != nil || math.IsInf(number, 0) {
+			// numbers that overflow are compared as text
+			return match
+		}
+		numbers = append(numbers, number)
+		return []byte(numberPlaceholder)
+	})
+	hash := sha256.Sum256(skeleton)
+	return FileDigest{Path: relativePath, Hash: hex.EncodeToString(hash[:]), Numbers: numbers}, nil
+}
+
+func fileHash(filePath string, normalizeLineEndings bool) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer closer.CloseWithLogOnError("file", file)
+
+	hash := sha256.New()
+	if !normalizeLineEndings {
+		_, err = io.Copy(hash, file)
+	} else {
+		normalizer := &lineEndingNormalizer{w: hash}
+		if _, err = io.Copy(normalizer, file); err == nil {
+			err = normalizer.Flush()
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// lineEndingNormalizer replaces CRLF line endings with LF line endings in what is written to it.
+type lineEndingNormalizer struct {
+	w io.Writer
+	// pendingCR is whether the last byte written was a CR, which is dropped if the next byte is a LF
+	pendingCR bool
+}
+
+func (n *lineEndingNormalizer) Write(p []byte) (int, error) {
+	normalized := make([]byte, 0, len(p)+1)
+	for _, b := range p {
+		if n.pendingCR && b != '\n' {
+			normalized = append(normalized, '\r')
+		}
+		n.pendingCR = b == '\r'
+		if !n.pendingCR {
+			normalized = append(normalized, b)
+		}
+	}
+	if _, err := n.w.Write(normalized); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes the last CR, if it was not followed by anything.
+func (n *lineEndingNormalizer) Flush() error {
+	if !n.pendingCR {
+		return nil
+	}
+	n.pendingCR = false
+	_, err := n.w.Write([]byte{'\r'})
+	return err
+}
@@ -0,0 +1,167 @@
+//go:build unit || !integration
+
+package tolerant
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func writeResult(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		require.NoError(t, os.WriteFile(path, []byte(content), os.ModePerm))
+	}
+	return dir
+}
+
+func TestDigestMatches(t *testing.T) {
+	base := map[string]string{
+		"stdout":               "hello\n",
+		"outputs/data.csv":     "name,value\na,1.0001\nb,2e3\n",
+		"outputs/run.log":      "started at 10:00\n",
+		"outputs/model.bin":    "weights",
+		"other/timestamp.txt":  "1676000000",
+		"outputs/metrics.json": `{"loss": 0.25, "epochs": 10}`,
+	}
+	with := func(changes map[string]string) map[string]string {
+		files := map[string]string{}
+		for name, content := range base {
+			files[name] = content
+		}
+		for name, content := range changes {
+			if content == "" {
+				delete(files, name)
+			} else {
+				files[name] = content
+			}
+		}
+		return files
+	}
+	tolerance := model.VerificationRules{AbsoluteTolerance: 0.01}
+
+	for _, tc := range []struct {
+		name    string
+		other   map[string]string
+		rules   model.VerificationRules
+		matches bool
+	}{
+		{name: "same", other: base, matches: true},
+		{name: "different file", other: with(map[string]string{"outputs/model.bin": "other"})},
+		{name: "missing file", other: with(map[string]string{"outputs/model.bin": ""})},
+		{
+			name:    "ignored glob",
+			other:   with(map[string]string{"outputs/run.log": "started at 10:01\n"}),
+			rules:   model.VerificationRules{IgnorePaths: []string{"*.log"}},
+			matches: true,
+		},
+		{
+			name:    "ignored path",
+			other:   with(map[string]string{"outputs/run.log": "started at 10:01\n"}),
+			rules:   model.VerificationRules{IgnorePaths: []string{"outputs/run.log"}},
+			matches: true,
+		},
+		{
+			name:  "ignored glob does not cross directories",
+			other: with(map[string]string{"outputs/run.log": "started at 10:01\n"}),
+			rules: model.VerificationRules{IgnorePaths: []string{"*/*/run.log"}},
+		},
+		{
+			name:    "unselected output volume",
+			other:   with(map[string]string{"other/timestamp.txt": "1676000001"}),
+			rules:   model.VerificationRules{OutputVolumes: []string{"outputs"}},
+			matches: true,
+		},
+		{
+			name:  "selected output volume",
+			other: with(map[string]string{"other/timestamp.txt": "1676000001"}),
+			rules: model.VerificationRules{OutputVolumes: []string{"other"}},
+		},
+		{
+			name:    "normalized line endings",
+			other:   with(map[string]string{"stdout": "hello\r\n", "outputs/data.csv": "name,value\r\na,1.0001\r\nb,2e3\r\n"}),
+			rules:   model.VerificationRules{NormalizeLineEndings: true, AbsoluteTolerance: 0.01},
+			matches: true,
+		},
+		{
+			name:  "different line endings",
+			other: with(map[string]string{"stdout": "hello\r\n"}),
+		},
+		{
+			name:    "numbers within absolute tolerance",
+			other:   with(map[string]string{"outputs/data.csv": "name,value\na,1.005\nb,2000.001\n"}),
+			rules:   tolerance,
+			matches: true,
+		},
+		{
+			name:  "numbers outside absolute tolerance",
+			other: with(map[string]string{"outputs/data.csv": "name,value\na,1.1\nb,2000\n"}),
+			rules: tolerance,
+		},
+		{
+			name:    "numbers within relative tolerance",
+			other:   with(map[string]string{"outputs/metrics.json": `{"loss": 0.2501, "epochs": 10}`}),
+			rules:   model.VerificationRules{RelativeTolerance: 0.001},
+			matches: true,
+		},
+		{
+			name:  "numbers without tolerance",
+			other: with(map[string]string{"outputs/data.csv": "name,value\na,1.0002\nb,2e3\n"}),
+		},
+		{
+			name:  "text around numbers",
+			other: with(map[string]string{"outputs/data.csv": "name,value\nc,1.0001\nb,2e3\n"}),
+			rules: tolerance,
+		},
+		{
+			name:  "numbers in other files",
+			other: with(map[string]string{"outputs/run.log": "started at 10:00:01\n"}),
+			rules: tolerance,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			digest, err := NewDigest(writeResult(t, base), tc.rules)
+			require.NoError(t, err)
+			otherDigest, err := NewDigest(writeResult(t, tc.other), tc.rules)
+			require.NoError(t, err)
+			require.Equal(t, tc.matches, digest.Matches(otherDigest, tc.rules))
+			require.Equal(t, tc.matches, otherDigest.Matches(digest, tc.rules))
+		})
+	}
+}
+
+func TestLineEndingNormalizer(t *testing.T) {
+	for _, chunks := range [][]string{
+		{"a\r\nb\r\n"},
+		{"a\r", "\nb\r", "\n"},
+	} {
+		var normalized []byte
+		w := &lineEndingNormalizer{w: writerFunc(func(p []byte) { normalized = append(normalized, p...) })}
+		for _, chunk := range chunks {
+			_, err := w.Write([]byte(chunk))
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Flush())
+		require.Equal(t, "a\nb\n", string(normalized))
+	}
+
+	var normalized []byte
+	w := &lineEndingNormalizer{w: writerFunc(func(p []byte) { normalized = append(normalized, p...) })}
+	_, err := w.Write([]byte("a\rb\r"))
+	require.NoError(t, err)
+	require.NoError(t, w.Flush())
+	require.Equal(t, "a\rb\r", string(normalized), "lone CRs are kept")
+}
+
+type writerFunc func(p []byte)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	f(p)
+	return len(p), nil
+}
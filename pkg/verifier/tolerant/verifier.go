@@ -0,0 +1,150 @@
+package tolerant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/filecoin-project/bacalhau/pkg/verifier"
+	"github.com/filecoin-project/bacalhau/pkg/verifier/results"
+)
+
+// TolerantVerifier verifies results that are equal according to the verification rules of the job, so that
+// results of near-deterministic jobs that differ in timestamps, logs or float rounding can be verified.
+type TolerantVerifier struct {
+	results   *results.Results
+	encrypter verifier.EncrypterFunction
+	decrypter verifier.DecrypterFunction
+}
+
+func NewTolerantVerifier(
+	_ context.Context, cm *system.CleanupManager,
+	encrypter verifier.EncrypterFunction,
+	decrypter verifier.DecrypterFunction,
+) (*TolerantVerifier, error) {
+	results, err := results.NewResults()
+	if err != nil {
+		return nil, err
+	}
+
+	cm.RegisterCallback(func() error {
+		if err := results.Close(); err != nil {
+			return fmt.Errorf("unable to remove results folder: %w", err)
+		}
+		return nil
+	})
+	return &TolerantVerifier{
+		results:   results,
+		encrypter: encrypter,
+		decrypter: decrypter,
+	}, nil
+}
+
+func (tolerantVerifier *TolerantVerifier) IsInstalled(context.Context) (bool, error) {
+	return true, nil
+}
+
+func (tolerantVerifier *TolerantVerifier) GetShardResultPath(
+	_ context.Context,
+	shard model.JobShard,
+) (string, error) {
+	return tolerantVerifier.results.EnsureShardResultsDir(shard.Job.Metadata.ID, shard.Index)
+}
+
+func (tolerantVerifier *TolerantVerifier) GetShardProposal(
+	ctx context.Context,
+	shard model.JobShard,
+	shardResultPath string,
+) ([]byte, error) {
+	if len(shard.Job.Metadata.Requester.RequesterPublicKey) == 0 {
+		return nil, fmt.Errorf("no RequesterPublicKey found in the job")
+	}
+	digest, err := NewDigest(shardResultPath, shard.Job.Spec.VerificationRules)
+	if err != nil {
+		return nil, err
+	}
+	proposal, err := json.Marshal(digest)
+	if err != nil {
+		return nil, err
+	}
+	// digests are larger than what the encrypter can encrypt on its own
+	return verifier.SealProposal(ctx, tolerantVerifier.encrypter, proposal, shard.Job.Metadata.Requester.RequesterPublicKey)
+}
+
+// getDigestGroups groups the executions whose digests match the digest of the first execution of a group,
+// in the order of the executions. Executions whose digest can't be decrypted are grouped in an invalid group.
+func (tolerantVerifier *TolerantVerifier) getDigestGroups(
+	ctx context.Context,
+	rules model.VerificationRules,
+	executionStates []model.ExecutionState,
+) []verifier.ExecutionGroup {
+	var groups []verifier.ExecutionGroup
+	var groupDigests []Digest
+	invalidGroup := verifier.ExecutionGroup{Valid: false}
+
+	for _, executionState := range executionStates { //nolint:gocritic
+		digest, err := tolerantVerifier.decryptDigest(ctx, executionState.VerificationProposal)
+		if err != nil {
+			// like the deterministic verifier, executions that couldn't submit a correctly encrypted
+			// proposal complete the verification without passing it
+			invalidGroup.Executions = append(invalidGroup.Executions, executionState)
+			continue
+		}
+
+		matched := false
+		for i, groupDigest := range groupDigests {
+			if groupDigest.Matches(digest, rules) {
+				groups[i].Executions = append(groups[i].Executions, executionState)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			groups = append(groups, verifier.ExecutionGroup{
+				Executions: []model.ExecutionState{executionState},
+				Valid:      true,
+			})
+			groupDigests = append(groupDigests, digest)
+		}
+	}
+
+	if len(invalidGroup.Executions) > 0 {
+		groups = append(groups, invalidGroup)
+	}
+	return groups
+}
+
+func (tolerantVerifier *TolerantVerifier) decryptDigest(ctx context.Context, proposal []byte) (Digest, error) {
+	if len(proposal) == 0 {
+		return Digest{}, fmt.Errorf("empty proposal")
+	}
+	decrypted, err := verifier.OpenProposal(ctx, tolerantVerifier.decrypter, proposal)
+	if err != nil {
+		return Digest{}, err
+	}
+	var digest Digest
+	err = json.Unmarshal(decrypted, &digest)
+	return digest, err
+}
+
+func (tolerantVerifier *TolerantVerifier) VerifyShard(
+	ctx context.Context,
+	shard model.JobShard,
+	executionStates []model.ExecutionState,
+) ([]verifier.VerifierResult, error) {
+	_, span := system.NewSpan(ctx, system.GetTracer(), "pkg/verifier.TolerantVerifier.VerifyShard")
+	defer span.End()
+
+	err := verifier.ValidateExecutions(shard, executionStates)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := tolerantVerifier.getDigestGroups(ctx, shard.Job.Spec.VerificationRules, executionStates)
+	return verifier.VerifyGroups(groups, shard.Job.Spec.Deal.Confidence), nil
+}
+
+// Compile-time check that tolerantVerifier implements the correct interface:
+var _ verifier.Verifier = (*TolerantVerifier)(nil)
@@ -0,0 +1,75 @@
+//go:build unit || !integration
+
+package tolerant
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/filecoin-project/bacalhau/pkg/system"
+	"github.com/filecoin-project/bacalhau/pkg/verifier"
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyShard(t *testing.T) {
+	ctx := context.Background()
+	cm := system.NewCleanupManager()
+	t.Cleanup(func() { cm.Cleanup(ctx) })
+
+	privateKey, publicKey, err := crypto.GenerateKeyPairWithReader(crypto.RSA, 2048, rand.Reader)
+	require.NoError(t, err)
+	publicKeyBytes, err := crypto.MarshalPublicKey(publicKey)
+	require.NoError(t, err)
+	encrypter := verifier.NewEncrypter(privateKey)
+
+	tolerantVerifier, err := NewTolerantVerifier(ctx, cm, encrypter.Encrypt, encrypter.Decrypt)
+	require.NoError(t, err)
+
+	job := &model.Job{
+		Metadata: model.Metadata{
+			ID:        "job",
+			Requester: model.JobRequester{RequesterPublicKey: publicKeyBytes},
+		},
+		Spec: model.Spec{
+			Verifier: model.VerifierTolerant,
+			VerificationRules: model.VerificationRules{
+				IgnorePaths:       []string{"stderr"},
+				AbsoluteTolerance: 0.01,
+			},
+			Deal: model.Deal{Concurrency: 4, Confidence: 2},
+		},
+	}
+	shard := model.JobShard{Job: job}
+
+	var executions []model.ExecutionState
+	for i, files := range []map[string]string{
+		{"outputs/result.csv": "x,1.000\n", "stderr": "took 1s"},
+		{"outputs/result.csv": "x,1.001\n", "stderr": "took 2s"},
+		{"outputs/result.csv": "x,1.500\n", "stderr": "took 1s"},
+		nil,
+	} {
+		execution := model.ExecutionState{
+			JobID:  job.Metadata.ID,
+			NodeID: string(rune('a' + i)),
+			State:  model.ExecutionStateResultProposed,
+		}
+		if files != nil {
+			execution.VerificationProposal, err = tolerantVerifier.GetShardProposal(ctx, shard, writeResult(t, files))
+			require.NoError(t, err)
+		} else {
+			execution.VerificationProposal = []byte("not a proposal")
+		}
+		executions = append(executions, execution)
+	}
+
+	results, err := tolerantVerifier.VerifyShard(ctx, shard, executions)
+	require.NoError(t, err)
+	verified := map[string]bool{}
+	for _, result := range results {
+		verified[result.Execution.NodeID] = result.Verified
+	}
+	require.Equal(t, map[string]bool{"a": true, "b": true, "c": false, "d": false}, verified)
+}
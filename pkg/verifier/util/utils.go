@@ -8,6 +8,8 @@ import (
 	"github.com/filecoin-project/bacalhau/pkg/verifier"
 	"github.com/filecoin-project/bacalhau/pkg/verifier/deterministic"
 	"github.com/filecoin-project/bacalhau/pkg/verifier/noop"
+	"github.com/filecoin-project/bacalhau/pkg/verifier/spotcheck"
+	"github.com/filecoin-project/bacalhau/pkg/verifier/tolerant"
 )
 
 func NewStandardVerifiers(
@@ -34,9 +36,31 @@ func NewStandardVerifiers(
 		return nil, err
 	}
 
+	tolerantVerifier, err := tolerant.NewTolerantVerifier(
+		ctx,
+		cm,
+		encrypter,
+		decrypter,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	spotCheckVerifier, err := spotcheck.NewSpotCheckVerifier(
+		ctx,
+		cm,
+		encrypter,
+		decrypter,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return model.NewMappedProvider(map[model.Verifier]verifier.Verifier{
 		model.VerifierNoop:          noopVerifier,
 		model.VerifierDeterministic: deterministicVerifier,
+		model.VerifierTolerant:      tolerantVerifier,
+		model.VerifierSpotCheck:     spotCheckVerifier,
 	}), nil
 }
 
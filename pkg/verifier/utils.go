@@ -25,3 +25,67 @@ func ValidateExecutions(shard model.JobShard, executions []model.ExecutionState)
 
 	return nil
 }
+
+// ExecutionGroup is a group of executions that proposed the same result.
+type ExecutionGroup struct {
+	Executions []model.ExecutionState
+	// Valid is false if the proposal of the group can't be verified, such as when it could not be decrypted
+	Valid bool
+}
+
+// VerifyGroups verifies the executions of the largest group, and rejects the others. All executions are
+// rejected if there is a draw for the largest group, if there is only a single execution, if the largest group
+// does not meet the confidence threshold or if its proposal is invalid.
+func VerifyGroups(groups []ExecutionGroup, confidence int) []VerifierResult {
+	largestGroup := -1
+	largestGroupSize := 0
+	groupSizeCounts := map[int]int{}
+	for i, group := range groups {
+		if len(group.Executions) > largestGroupSize {
+			largestGroupSize = len(group.Executions)
+			largestGroup = i
+		}
+		groupSizeCounts[len(group.Executions)]++
+	}
+
+	isVoidResult := largestGroup == -1 ||
+		// this means there is a draw for the largest group size
+		groupSizeCounts[largestGroupSize] > 1 ||
+		// this means there is only a single result
+		(len(groups) == 1 && largestGroupSize == 1) ||
+		// this means that the winning group size does not meet the confidence threshold
+		(confidence > 0 && largestGroupSize < confidence) ||
+		!groups[largestGroup].Valid
+
+	var allResults []VerifierResult
+	for i, group := range groups {
+		for _, execution := range group.Executions {
+			allResults = append(allResults, VerifierResult{
+				Execution: execution,
+				Verified:  !isVoidResult && i == largestGroup,
+			})
+		}
+	}
+	return allResults
+}
+
+// GetShardTrust returns how much the accepted result of a shard can be trusted after its executions were verified.
+// A result is only trusted if it matched the result of another execution, which the noop verifier never checks.
+func GetShardTrust(verifierType model.Verifier, results []VerifierResult) model.ShardTrust {
+	verified := 0
+	for _, result := range results {
+		if result.Verified {
+			verified++
+		}
+	}
+	switch {
+	case verified == 0 && len(results) > 1:
+		return model.ShardTrustMismatched
+	case verified == 0:
+		return model.ShardTrustUnknown
+	case verified == 1 || verifierType == model.VerifierNoop:
+		return model.ShardTrustUnverified
+	default:
+		return model.ShardTrustVerified
+	}
+}
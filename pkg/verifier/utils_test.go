@@ -0,0 +1,68 @@
+//go:build unit || !integration
+
+package verifier
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/bacalhau/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyGroups(t *testing.T) {
+	group := func(valid bool, nodeIDs ...string) ExecutionGroup {
+		executionGroup := ExecutionGroup{Valid: valid}
+		for _, nodeID := range nodeIDs {
+			executionGroup.Executions = append(executionGroup.Executions, model.ExecutionState{NodeID: nodeID})
+		}
+		return executionGroup
+	}
+
+	for _, tc := range []struct {
+		name       string
+		groups     []ExecutionGroup
+		confidence int
+		verified   []string
+	}{
+		{name: "largest group", groups: []ExecutionGroup{group(true, "a", "b"), group(true, "c")}, verified: []string{"a", "b"}},
+		{name: "draw", groups: []ExecutionGroup{group(true, "a", "b"), group(true, "c", "d")}},
+		{name: "single execution", groups: []ExecutionGroup{group(true, "a")}},
+		{name: "confidence not met", groups: []ExecutionGroup{group(true, "a", "b"), group(true, "c")}, confidence: 3},
+		{name: "invalid largest group", groups: []ExecutionGroup{group(false, "a", "b"), group(true, "c")}},
+		{name: "no executions"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var verified []string
+			results := VerifyGroups(tc.groups, tc.confidence)
+			for _, result := range results {
+				if result.Verified {
+					verified = append(verified, result.Execution.NodeID)
+				}
+			}
+			require.Equal(t, tc.verified, verified)
+		})
+	}
+}
+
+func TestGetShardTrust(t *testing.T) {
+	result := func(verified bool) VerifierResult {
+		return VerifierResult{Verified: verified}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		verifier model.Verifier
+		results  []VerifierResult
+		trust    model.ShardTrust
+	}{
+		{name: "agreeing executions", verifier: model.VerifierSpotCheck, results: []VerifierResult{result(true), result(true)}, trust: model.ShardTrustVerified},
+		{name: "disagreeing executions", verifier: model.VerifierSpotCheck, results: []VerifierResult{result(false), result(false)}, trust: model.ShardTrustMismatched},
+		{name: "single execution", verifier: model.VerifierSpotCheck, results: []VerifierResult{result(true)}, trust: model.ShardTrustUnverified},
+		{name: "single rejected execution", verifier: model.VerifierSpotCheck, results: []VerifierResult{result(false)}, trust: model.ShardTrustUnknown},
+		{name: "noop verifier", verifier: model.VerifierNoop, results: []VerifierResult{result(true), result(true)}, trust: model.ShardTrustUnverified},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.trust, GetShardTrust(tc.verifier, tc.results))
+		})
+	}
+}